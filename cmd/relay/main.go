@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/config"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/logging"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/payloadsig"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/relay"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage/postgres"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage/redis"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+	"github.com/kelseyhightower/envconfig"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func main() {
+	// Load the dotenv if exists
+	_ = godotenv.Load()
+
+	var env config.Relay
+	err := envconfig.Process("", &env)
+	if err != nil {
+		log.Fatal("Cannot load env:", err)
+	}
+
+	// Setup structured logging
+	if _, err := logging.Setup(env.Logging); err != nil {
+		log.Fatal(err)
+	}
+
+	slog.Info("Starting Task Queue Outbox Relay")
+
+	if err := postgres.ValidateTablePrefix(env.Database.TablePrefix); err != nil {
+		log.Fatal("Invalid DB_TABLE_PREFIX:", err)
+	}
+
+	// The outbox and its checkpoints always live in PostgreSQL
+	dbPool, err := postgres.NewPool(context.Background(), env.Database.ToDbConnectionUri(), postgres.PoolConfig{
+		LogQueries:         env.Database.LogQueries,
+		SlowQueryThreshold: time.Duration(env.Database.SlowQueryMs) * time.Millisecond,
+		ConnectTimeout:     time.Duration(env.Database.ConnectTimeout) * time.Second,
+	})
+	if err != nil {
+		log.Fatal("Failed to create database pool:", err)
+	}
+	defer dbPool.Close()
+	slog.Info("Database connection established")
+
+	var store storage.Store
+	switch env.Storage.Backend {
+	case config.StorageBackendPostgres:
+		var historyPool *pgxpool.Pool
+		if env.Database.HistoryURL != "" {
+			historyPool, err = postgres.NewPool(context.Background(), env.Database.HistoryURL, postgres.PoolConfig{
+				LogQueries:         env.Database.LogQueries,
+				SlowQueryThreshold: time.Duration(env.Database.SlowQueryMs) * time.Millisecond,
+				ConnectTimeout:     time.Duration(env.Database.ConnectTimeout) * time.Second,
+			})
+			if err != nil {
+				log.Fatal("Failed to create history database pool:", err)
+			}
+			defer historyPool.Close()
+		}
+
+		store = postgres.NewStore(dbPool, postgres.Config{
+			TransactionPooling: env.Database.PgBouncer,
+
+			ShortQueryTimeout: time.Duration(env.Database.QueryTimeout) * time.Second,
+			LongQueryTimeout:  time.Duration(env.Database.LongQueryTimeout) * time.Second,
+
+			TablePrefix: env.Database.TablePrefix,
+			HistoryPool: historyPool,
+		})
+
+	case config.StorageBackendRedis:
+		opts, err := goredis.ParseURL(env.Storage.RedisURL)
+		if err != nil {
+			log.Fatal("Invalid REDIS_URL:", err)
+		}
+
+		client := goredis.NewClient(opts)
+		defer func() { _ = client.Close() }()
+
+		if err := client.Ping(context.Background()).Err(); err != nil {
+			log.Fatal("Failed to ping Redis:", err)
+		}
+		if err := redis.CheckServer(context.Background(), client); err != nil {
+			log.Fatal("Unsupported Redis deployment:", err)
+		}
+		slog.Info("Redis connection established")
+
+		store = redis.NewStore(client, redis.Config{
+			KeyPrefix:   env.Storage.RedisKeyPrefix,
+			FinishedTTL: time.Duration(env.Storage.RedisFinishedTTL) * time.Second,
+		})
+
+	default:
+		log.Fatal("Invalid STORAGE_BACKEND:", env.Storage.Backend)
+	}
+
+	r := relay.NewRelay(dbPool, store, relay.Config{
+		Table:        env.Table,
+		BatchSize:    env.BatchSize,
+		PollInterval: time.Duration(env.PollInterval) * time.Second,
+		GapTimeout:   time.Duration(env.GapTimeout) * time.Second,
+		TablePrefix:  env.Database.TablePrefix,
+
+		PayloadSigner: payloadsig.New(env.Signing.Key),
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := r.Run(ctx); err != nil && err != context.Canceled {
+		slog.Error("Outbox relay stopped with error", "error", err)
+	}
+	slog.Info("Outbox relay stopped gracefully")
+}
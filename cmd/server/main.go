@@ -12,15 +12,25 @@ import (
 	"time"
 
 	"github.com/amitbasuri/taskqueue-runner-go/db"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/alerting"
 	"github.com/amitbasuri/taskqueue-runner-go/internal/api"
 	"github.com/amitbasuri/taskqueue-runner-go/internal/config"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/logging"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/maintenance"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/metrics"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/remote"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
 	"github.com/amitbasuri/taskqueue-runner-go/internal/storage/postgres"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage/redis"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/tlsconfig"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
+	goredis "github.com/redis/go-redis/v9"
 
 	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
@@ -37,81 +47,358 @@ func main() {
 	}
 
 	// Setup structured logging
-	h := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo})
-	slog.SetDefault(slog.New(h))
+	logLevel, err := logging.Setup(env.Logging)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	slog.Info("Starting Task Queue API Server (Producer)")
 
-	// Run database migrations
-	d, err := iofs.New(db.Migrations, "migrations")
-	if err != nil {
-		log.Fatal("Failed to load migrations:", err)
+	// Metrics served on /metrics
+	metricsRegistry := metrics.NewRegistry()
+
+	// Remote workers' retries are decided here, with the settings local workers apply
+	remoteJitter := models.JitterMode(env.Remote.RetryJitter)
+	if env.Remote.Port != "" && !remoteJitter.IsValid() {
+		log.Fatal("Invalid WORKER_RETRY_JITTER:", env.Remote.RetryJitter)
 	}
 
-	m, err := migrate.NewWithSourceInstance("iofs", d, env.Database.ToMigrationUri())
-	if err != nil {
-		log.Fatal("Failed to create migrate instance:", err)
+	// Initialize storage layer
+	var store storage.Store
+	var remoteStore storage.Store // the same backend, configured as for workers
+	var maintenanceJobs []maintenance.Job
+	var schemaVersion uint
+	switch env.Storage.Backend {
+	case config.StorageBackendPostgres:
+		if err := postgres.ValidateTablePrefix(env.Database.TablePrefix); err != nil {
+			log.Fatal("Invalid DB_TABLE_PREFIX:", err)
+		}
+
+		// Initialize database connection pool
+		dbPool, err := postgres.NewPool(context.Background(), env.Database.ToDbConnectionUri(), postgres.PoolConfig{
+			LogQueries:         env.Database.LogQueries,
+			SlowQueryThreshold: time.Duration(env.Database.SlowQueryMs) * time.Millisecond,
+			ConnectTimeout:     time.Duration(env.Database.ConnectTimeout) * time.Second,
+		})
+		if err != nil {
+			log.Fatal("Failed to create database pool:", err)
+		}
+		defer dbPool.Close()
+		slog.Info("Database connection established")
+
+		if err := postgres.EnsureSchema(context.Background(), dbPool, env.Database.Schema); err != nil {
+			log.Fatal("Failed to create schema:", err)
+		}
+
+		// Run database migrations once the database is reachable
+		d, err := iofs.New(db.Migrations, "migrations")
+		if err != nil {
+			log.Fatal("Failed to load migrations:", err)
+		}
+
+		m, err := migrate.NewWithSourceInstance("iofs", postgres.PrefixMigrations(d, env.Database.TablePrefix), env.Database.ToMigrationUri())
+		if err != nil {
+			log.Fatal("Failed to create migrate instance:", err)
+		}
+
+		if err := m.Up(); err != nil {
+			if !errors.Is(err, migrate.ErrNoChange) {
+				log.Fatal("Failed to run migrations:", err)
+			}
+		}
+		slog.Info("Migrations ran successfully")
+
+		// Readiness requires the database to stay at the version this build embeds
+		schemaVersion, err = db.LatestVersion(db.Migrations, "migrations")
+		if err != nil {
+			log.Fatal("Failed to read migration version:", err)
+		}
+
+		// task_history may live in a database of its own with its own migrations
+		var historyPool *pgxpool.Pool
+		if env.Database.HistoryURL != "" {
+			historyPool, err = postgres.NewPool(context.Background(), env.Database.HistoryURL, postgres.PoolConfig{
+				LogQueries:         env.Database.LogQueries,
+				SlowQueryThreshold: time.Duration(env.Database.SlowQueryMs) * time.Millisecond,
+				ConnectTimeout:     time.Duration(env.Database.ConnectTimeout) * time.Second,
+			})
+			if err != nil {
+				log.Fatal("Failed to create history database pool:", err)
+			}
+			defer historyPool.Close()
+			slog.Info("History database connection established")
+
+			historyURI, err := env.Database.ToHistoryMigrationUri()
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			hd, err := iofs.New(db.HistoryMigrations, "history_migrations")
+			if err != nil {
+				log.Fatal("Failed to load history migrations:", err)
+			}
+
+			hm, err := migrate.NewWithSourceInstance("iofs", postgres.PrefixMigrations(hd, env.Database.TablePrefix), historyURI)
+			if err != nil {
+				log.Fatal("Failed to create history migrate instance:", err)
+			}
+
+			if err := hm.Up(); err != nil {
+				if !errors.Is(err, migrate.ErrNoChange) {
+					log.Fatal("Failed to run history migrations:", err)
+				}
+			}
+			slog.Info("History migrations ran successfully")
+		}
+
+		pgConfig := postgres.Config{
+			StatsEstimateAbove: env.StatsEstimateAbove,
+			TransactionPooling: env.Database.PgBouncer,
+
+			ShortQueryTimeout: time.Duration(env.Database.QueryTimeout) * time.Second,
+			LongQueryTimeout:  time.Duration(env.Database.LongQueryTimeout) * time.Second,
+
+			TablePrefix: env.Database.TablePrefix,
+			HistoryPool: historyPool,
+		}
+		pgStore := postgres.NewStore(dbPool, pgConfig)
+		store = pgStore
+
+		workerConfig := pgConfig
+		workerConfig.JitterMode = remoteJitter
+		workerConfig.MaxBackoff = time.Duration(env.Remote.MaxBackoff) * time.Second
+		workerConfig.QuarantineThreshold = env.Remote.QuarantineAfter
+		remoteStore = postgres.NewStore(dbPool, workerConfig)
+
+		maintenanceJobs = append(maintenanceJobs,
+			maintenance.HistoryPartitions(pgStore, time.Duration(env.HistoryRetentionDays)*24*time.Hour),
+		)
+		if env.MaintenanceAnalyze {
+			maintenanceJobs = append(maintenanceJobs, maintenance.AnalyzeTables(pgStore, metricsRegistry, env.MaintenanceBloatWarnPct/100))
+		}
+
+	case config.StorageBackendRedis:
+		opts, err := goredis.ParseURL(env.Storage.RedisURL)
+		if err != nil {
+			log.Fatal("Invalid REDIS_URL:", err)
+		}
+
+		client := goredis.NewClient(opts)
+		defer func() { _ = client.Close() }()
+
+		if err := client.Ping(context.Background()).Err(); err != nil {
+			log.Fatal("Failed to ping Redis:", err)
+		}
+		if err := redis.CheckServer(context.Background(), client); err != nil {
+			log.Fatal("Unsupported Redis deployment:", err)
+		}
+		slog.Info("Redis connection established")
+
+		redisConfig := redis.Config{
+			KeyPrefix:   env.Storage.RedisKeyPrefix,
+			FinishedTTL: time.Duration(env.Storage.RedisFinishedTTL) * time.Second,
+		}
+		store = redis.NewStore(client, redisConfig)
+
+		workerConfig := redisConfig
+		workerConfig.JitterMode = remoteJitter
+		workerConfig.MaxBackoff = time.Duration(env.Remote.MaxBackoff) * time.Second
+		workerConfig.QuarantineThreshold = env.Remote.QuarantineAfter
+		remoteStore = redis.NewStore(client, workerConfig)
+
+	default:
+		log.Fatal("Invalid STORAGE_BACKEND:", env.Storage.Backend)
 	}
 
-	if err := m.Up(); err != nil {
-		if !errors.Is(err, migrate.ErrNoChange) {
-			log.Fatal("Failed to run migrations:", err)
+	// Periodic housekeeping, stopped on shutdown
+	maintenanceCtx, stopMaintenance := context.WithCancel(context.Background())
+	defer stopMaintenance()
+	go maintenance.NewRunner(time.Duration(env.MaintenanceInterval)*time.Second, maintenanceJobs...).Run(maintenanceCtx)
+
+	// Queue depth and success rate gauges need a much shorter interval than housekeeping
+	if env.QueueMetricsInterval > 0 {
+		queueJobs := []maintenance.Job{maintenance.QueueDepth(store, metricsRegistry)}
+		if a, ok := store.(storage.TaskAnalytics); ok && env.SuccessRateWindow > 0 {
+			queueJobs = append(queueJobs, maintenance.SuccessRate(a, metricsRegistry, time.Duration(env.SuccessRateWindow)*time.Minute))
 		}
+		go maintenance.NewRunner(time.Duration(env.QueueMetricsInterval)*time.Second, queueJobs...).Run(maintenanceCtx)
+	}
+
+	// Built-in alert rules, evaluated on their own interval
+	if env.AlertInterval > 0 {
+		go maintenance.NewRunner(time.Duration(env.AlertInterval)*time.Second, maintenance.AlertRules(alerting.NewEvaluator(store, nil))).Run(maintenanceCtx)
 	}
-	slog.Info("Migrations ran successfully")
 
-	// Initialize database connection pool
-	dbPool, err := pgxpool.New(context.Background(), env.Database.ToDbConnectionUri())
+	typeMaxQueued, err := config.TypeMaxQueued(os.Environ())
 	if err != nil {
-		log.Fatal("Failed to create database pool:", err)
+		log.Fatal("Invalid per-type queued-task cap:", err)
 	}
-	defer dbPool.Close()
 
-	// Test database connection
-	if err := dbPool.Ping(context.Background()); err != nil {
-		log.Fatal("Failed to ping database:", err)
+	switch env.UnknownTaskTypes {
+	case api.UnknownTaskTypesAccept, api.UnknownTaskTypesWarn, api.UnknownTaskTypesReject:
+	default:
+		log.Fatal("Invalid API_UNKNOWN_TASK_TYPES:", env.UnknownTaskTypes)
 	}
-	slog.Info("Database connection established")
 
-	// Initialize storage layer
-	store := postgres.NewStore(dbPool)
+	adminNetworks, err := api.ParseAllowlist(env.Network.AdminAllowedCIDRs)
+	if err != nil {
+		log.Fatal("Invalid API_ADMIN_ALLOWED_CIDRS:", err)
+	}
 
 	// Initialize API handler
-	apiHandler := api.NewHandler(store)
+	apiHandler := api.NewHandler(store, api.Config{
+		StatsCacheTTL: time.Duration(env.StatsCacheTTL) * time.Second,
+		SchemaVersion: schemaVersion,
+
+		MaxBodyBytes:    env.MaxBodyBytes,
+		MaxPayloadBytes: env.MaxPayloadBytes,
+
+		PayloadSigningKey: env.Signing.Key,
+
+		MaxQueued:     env.MaxQueued,
+		TypeMaxQueued: typeMaxQueued,
+		ReadOnly:      env.ReadOnly,
+		AuditPayloads: env.AuditPayloads,
+
+		IntegerTaskIDs: env.IntegerTaskIDs,
+
+		UnknownTaskTypes: env.UnknownTaskTypes,
+
+		Auth:      env.Auth.Enabled,
+		AdminKey:  env.Auth.AdminKey,
+		JWTSecret: env.Auth.JWTSecret,
+		JWTIssuer: env.Auth.JWTIssuer,
+
+		CreateRate:  env.RateLimit.CreateRate,
+		CreateBurst: env.RateLimit.CreateBurst,
+		Read: api.BudgetConfig{
+			Rate:        env.RateLimit.ReadRate,
+			Burst:       env.RateLimit.ReadBurst,
+			Concurrency: env.RateLimit.ReadConcurrency,
+		},
+		Write: api.BudgetConfig{
+			Rate:        env.RateLimit.WriteRate,
+			Burst:       env.RateLimit.WriteBurst,
+			Concurrency: env.RateLimit.WriteConcurrency,
+		},
+		CORS: api.CORSConfig{
+			AllowedOrigins:   env.CORS.AllowedOrigins,
+			AllowedMethods:   env.CORS.AllowedMethods,
+			AllowedHeaders:   env.CORS.AllowedHeaders,
+			AllowCredentials: env.CORS.AllowCredentials,
+			MaxAge:           time.Duration(env.CORS.MaxAge) * time.Second,
+		},
+		AdminNetworks: adminNetworks,
+	})
+	if env.ReadOnly {
+		slog.Warn("API server starting in read-only mode")
+	}
+	if env.Auth.Enabled {
+		slog.Info("API authentication enabled", "admin_key", env.Auth.AdminKey != "", "jwt", env.Auth.JWTSecret != "")
+	}
 
 	// Setup HTTP routes
 	r := gin.Default()
+	// Only proxies listed here may set the client address through X-Forwarded-For
+	if err := r.SetTrustedProxies(env.Network.TrustedProxies); err != nil {
+		log.Fatal("Invalid API_TRUSTED_PROXIES:", err)
+	}
+	r.Use(api.RequestID())
+	// On the engine so preflights reach it for every route, before authentication
+	r.Use(apiHandler.CORS())
+	r.Use(apiHandler.LimitBodySize())
+	r.Use(apiHandler.RejectWritesWhenReadOnly())
 
 	// Register API routes
 	apiHandler.RegisterRoutes(r)
 
-	// Health check endpoints
-	r.GET("/readiness", func(c *gin.Context) {
-		// Check database connection
-		if err := dbPool.Ping(c.Request.Context()); err != nil {
-			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "error": "database unavailable"})
-			return
-		}
-		c.JSON(http.StatusOK, gin.H{"status": "ready"})
-	})
+	// Health check endpoints (/readiness is registered by the API handler)
 	r.GET("/liveness", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "alive"})
 	})
+	r.GET("/metrics", gin.WrapH(metricsRegistry.Handler()))
+
+	// Runtime log level, e.g. debug during an incident
+	admin := r.Group("/api/log-level", apiHandler.Backpressure(), apiHandler.Authenticate(), apiHandler.RequireScope(models.APIKeyScopeAdmin))
+	admin.GET("", gin.WrapH(logging.LevelHandler(logLevel)))
+	admin.PUT("", gin.WrapH(logging.LevelHandler(logLevel)))
 
 	// Task API endpoints
-	r.POST("/tasks", apiHandler.CreateTask)
-	r.GET("/tasks/:id", apiHandler.GetTask)
-	r.GET("/tasks/:id/history", apiHandler.GetTaskHistory)
+	tasks := r.Group("/tasks", apiHandler.Backpressure(), apiHandler.Authenticate())
+	tasks.POST("", apiHandler.RequireScope(models.APIKeyScopeWrite), apiHandler.LimitTaskCreation(), apiHandler.CreateTask)
+	tasks.GET("/:id", apiHandler.RequireScope(models.APIKeyScopeRead), apiHandler.GetTask)
+	tasks.GET("/:id/history", apiHandler.RequireScope(models.APIKeyScopeRead), apiHandler.GetTaskHistory)
 
 	srv := &http.Server{
 		Addr:    ":" + env.ServerPort,
 		Handler: r,
 	}
 
+	// Native HTTPS; certificates are reloaded on SIGHUP and when the files change, without dropping connections
+	if env.TLS.CertFile != "" {
+		certs, err := tlsconfig.NewReloader(tlsconfig.Files{
+			CertFile: env.TLS.CertFile,
+			KeyFile:  env.TLS.KeyFile,
+			CAFile:   env.TLS.ClientCAFile,
+		})
+		if err != nil {
+			log.Fatal("Invalid API TLS certificate:", err)
+		}
+		srv.TLSConfig = certs.ServerConfig()
+
+		if env.TLS.WatchInterval > 0 {
+			go certs.Watch(maintenanceCtx, time.Duration(env.TLS.WatchInterval)*time.Second)
+		}
+		reloadOnSIGHUP(certs, env.TLS.CertFile)
+	}
+
+	// Remote workers claim, heartbeat and complete tasks on a mutual TLS listener of their own
+	var remoteSrv *http.Server
+	if env.Remote.Port != "" {
+		if env.Remote.CertFile == "" || env.Remote.ClientCAFile == "" {
+			log.Fatal("REMOTE_WORKER_TLS_CERT_FILE, REMOTE_WORKER_TLS_KEY_FILE and REMOTE_WORKER_TLS_CLIENT_CA_FILE are required with REMOTE_WORKER_PORT")
+		}
+		certs, err := tlsconfig.NewReloader(tlsconfig.Files{
+			CertFile: env.Remote.CertFile,
+			KeyFile:  env.Remote.KeyFile,
+			CAFile:   env.Remote.ClientCAFile,
+		})
+		if err != nil {
+			log.Fatal("Invalid remote worker TLS certificate:", err)
+		}
+		if env.Remote.WatchInterval > 0 {
+			go certs.Watch(maintenanceCtx, time.Duration(env.Remote.WatchInterval)*time.Second)
+		}
+		reloadOnSIGHUP(certs, env.Remote.CertFile)
+
+		remoteRouter := gin.Default()
+		remoteRouter.Use(api.RequestID())
+		remote.NewServer(remoteStore).RegisterRoutes(remoteRouter)
+
+		remoteSrv = &http.Server{
+			Addr:      ":" + env.Remote.Port,
+			Handler:   remoteRouter,
+			TLSConfig: certs.ServerConfig(),
+		}
+		go func() {
+			slog.Info("Remote worker listener started", "port", env.Remote.Port)
+			if err := remoteSrv.ListenAndServeTLS("", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatal("Remote worker listener error:", err)
+			}
+		}()
+	}
+
 	// Start HTTP server in goroutine
 	go func() {
-		slog.Info("HTTP server listening", "port", env.ServerPort)
-		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		var err error
+		if srv.TLSConfig != nil {
+			slog.Info("HTTPS server listening", "port", env.ServerPort, "client_certificates", env.TLS.ClientCAFile != "")
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			slog.Info("HTTP server listening", "port", env.ServerPort)
+			err = srv.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatal("HTTP server error:", err)
 		}
 	}()
@@ -122,6 +409,7 @@ func main() {
 	<-quit
 
 	slog.Info("Shutting down API server...")
+	stopMaintenance()
 
 	// Shutdown HTTP server with timeout
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -130,6 +418,26 @@ func main() {
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		log.Fatal("Server forced to shutdown:", err)
 	}
+	if remoteSrv != nil {
+		if err := remoteSrv.Shutdown(shutdownCtx); err != nil {
+			log.Fatal("Remote worker listener forced to shutdown:", err)
+		}
+	}
 
 	slog.Info("API server exited gracefully")
 }
+
+// reloadOnSIGHUP reloads certs whenever the process receives SIGHUP
+func reloadOnSIGHUP(certs *tlsconfig.Reloader, certFile string) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if err := certs.Reload(); err != nil {
+				slog.Error("Failed to reload TLS certificate", "cert_file", certFile, "error", err)
+				continue
+			}
+			slog.Info("TLS certificate reloaded", "cert_file", certFile)
+		}
+	}()
+}
@@ -2,25 +2,50 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"log/slog"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/url"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/amitbasuri/taskqueue-runner-go/internal/api"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/blobstore"
 	"github.com/amitbasuri/taskqueue-runner-go/internal/config"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/email"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/errorreport"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/kube"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/logging"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/payloadsig"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/plugins"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/remote"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/report"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/secrets"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/sidecar"
 	"github.com/amitbasuri/taskqueue-runner-go/internal/storage/postgres"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage/redis"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/tlsconfig"
 	"github.com/amitbasuri/taskqueue-runner-go/internal/worker"
 	"github.com/amitbasuri/taskqueue-runner-go/internal/worker/handlers"
+	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
+	goredis "github.com/redis/go-redis/v9"
 
 	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
 func main() {
 	// Load the dotenv if exists
 	_ = godotenv.Load()
@@ -32,45 +57,469 @@ func main() {
 	}
 
 	// Setup structured logging
-	h := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo})
-	slog.SetDefault(slog.New(h))
+	logLevel, err := logging.Setup(env.Logging)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	slog.Info("Starting Task Queue Worker (Consumer)")
 
-	// Initialize database connection pool
-	dbPool, err := pgxpool.New(context.Background(), env.Database.ToDbConnectionUri())
-	if err != nil {
-		log.Fatal("Failed to create database pool:", err)
+	// Initialize storage layer
+	jitterMode := models.JitterMode(env.RetryJitter)
+	if !jitterMode.IsValid() {
+		log.Fatal("Invalid WORKER_RETRY_JITTER:", env.RetryJitter)
 	}
-	defer dbPool.Close()
 
-	// Test database connection
-	if err := dbPool.Ping(context.Background()); err != nil {
-		log.Fatal("Failed to ping database:", err)
+	var store worker.Store
+	switch env.Storage.Backend {
+	case config.StorageBackendPostgres:
+		if err := postgres.ValidateTablePrefix(env.Database.TablePrefix); err != nil {
+			log.Fatal("Invalid DB_TABLE_PREFIX:", err)
+		}
+
+		// Initialize database connection pool
+		dbPool, err := postgres.NewPool(context.Background(), env.Database.ToDbConnectionUri(), postgres.PoolConfig{
+			LogQueries:         env.Database.LogQueries,
+			SlowQueryThreshold: time.Duration(env.Database.SlowQueryMs) * time.Millisecond,
+			ConnectTimeout:     time.Duration(env.Database.ConnectTimeout) * time.Second,
+		})
+		if err != nil {
+			log.Fatal("Failed to create database pool:", err)
+		}
+		defer dbPool.Close()
+		slog.Info("Database connection established")
+
+		var historyPool *pgxpool.Pool
+		if env.Database.HistoryURL != "" {
+			historyPool, err = postgres.NewPool(context.Background(), env.Database.HistoryURL, postgres.PoolConfig{
+				LogQueries:         env.Database.LogQueries,
+				SlowQueryThreshold: time.Duration(env.Database.SlowQueryMs) * time.Millisecond,
+				ConnectTimeout:     time.Duration(env.Database.ConnectTimeout) * time.Second,
+			})
+			if err != nil {
+				log.Fatal("Failed to create history database pool:", err)
+			}
+			defer historyPool.Close()
+			slog.Info("History database connection established")
+		}
+
+		store = postgres.NewStore(dbPool, postgres.Config{
+			JitterMode: jitterMode,
+			MaxBackoff: time.Duration(env.MaxBackoff) * time.Second,
+
+			QuarantineThreshold: env.QuarantineAfter,
+			TransactionPooling:  env.Database.PgBouncer,
+
+			ShortQueryTimeout: time.Duration(env.Database.QueryTimeout) * time.Second,
+			LongQueryTimeout:  time.Duration(env.Database.LongQueryTimeout) * time.Second,
+
+			TablePrefix: env.Database.TablePrefix,
+			HistoryPool: historyPool,
+		})
+
+	case config.StorageBackendRedis:
+		opts, err := goredis.ParseURL(env.Storage.RedisURL)
+		if err != nil {
+			log.Fatal("Invalid REDIS_URL:", err)
+		}
+
+		client := goredis.NewClient(opts)
+		defer func() { _ = client.Close() }()
+
+		if err := client.Ping(context.Background()).Err(); err != nil {
+			log.Fatal("Failed to ping Redis:", err)
+		}
+		if err := redis.CheckServer(context.Background(), client); err != nil {
+			log.Fatal("Unsupported Redis deployment:", err)
+		}
+		slog.Info("Redis connection established")
+
+		store = redis.NewStore(client, redis.Config{
+			KeyPrefix:  env.Storage.RedisKeyPrefix,
+			JitterMode: jitterMode,
+			MaxBackoff: time.Duration(env.MaxBackoff) * time.Second,
+
+			QuarantineThreshold: env.QuarantineAfter,
+			FinishedTTL:         time.Duration(env.Storage.RedisFinishedTTL) * time.Second,
+		})
+
+	case config.StorageBackendRemote:
+		// No database credentials: the API server's remote worker listener acts on the store, and
+		// applies its own retry settings
+		remoteURL, err := url.Parse(env.Remote.URL)
+		if err != nil || remoteURL.Scheme != "https" || remoteURL.Host == "" {
+			log.Fatal("Invalid REMOTE_URL, an https URL is required:", env.Remote.URL)
+		}
+		certs, err := tlsconfig.NewReloader(tlsconfig.Files{
+			CertFile: env.Remote.CertFile,
+			KeyFile:  env.Remote.KeyFile,
+			CAFile:   env.Remote.CAFile,
+		})
+		if err != nil {
+			log.Fatal("Invalid remote worker TLS certificate:", err)
+		}
+		// New connections present the rotated certificate and trust the rotated CA bundle
+		if env.Remote.WatchInterval > 0 {
+			go certs.Watch(context.Background(), time.Duration(env.Remote.WatchInterval)*time.Second)
+		}
+
+		// The listener only admits the certificate's own name, optionally with a suffix
+		if env.ID == "" {
+			env.ID = certs.CommonName()
+		}
+
+		client := remote.NewClient(env.Remote.URL, certs.ClientConfig(remoteURL.Hostname()), time.Duration(env.Remote.Timeout)*time.Second)
+		if err := client.Ping(context.Background()); err != nil {
+			log.Fatal("Failed to reach the remote worker listener:", err)
+		}
+		slog.Info("Remote worker listener reachable", "url", env.Remote.URL, "worker_id", env.ID)
+		store = client
+
+	default:
+		log.Fatal("Invalid STORAGE_BACKEND:", env.Storage.Backend)
 	}
-	slog.Info("Database connection established")
 
-	// Initialize storage layer
-	store := postgres.NewStore(dbPool)
+	// Email delivery for send_email tasks
+	if env.Email.Provider != config.EmailProviderSimulate {
+		if _, err := mail.ParseAddress(env.Email.From); err != nil {
+			log.Fatal("Invalid EMAIL_FROM:", err)
+		}
+	}
+
+	var emailSender email.Sender
+	switch env.Email.Provider {
+	case config.EmailProviderSMTP:
+		client, err := email.NewSMTP(email.SMTPConfig{
+			Host:     env.Email.SMTPHost,
+			Port:     env.Email.SMTPPort,
+			Username: env.Email.SMTPUsername,
+			Password: env.Email.SMTPPassword,
+			TLS:      env.Email.SMTPTLS,
+			Timeout:  time.Duration(env.Email.SMTPTimeout) * time.Second,
+			PoolSize: env.Email.SMTPPoolSize,
+		})
+		if err != nil {
+			log.Fatal("Invalid SMTP configuration:", err)
+		}
+		defer func() { _ = client.Close() }()
+		emailSender = client
+	case config.EmailProviderSES:
+		emailSender, err = email.NewSES(context.Background(), env.Email.SESRegion, env.Email.SESConfigurationSet)
+		if err != nil {
+			log.Fatal("Failed to set up SES:", err)
+		}
+	case config.EmailProviderSendGrid:
+		if env.Email.SendGridAPIKey == "" {
+			log.Fatal("SENDGRID_API_KEY is required with EMAIL_PROVIDER=sendgrid")
+		}
+		emailSender = email.NewSendGrid(env.Email.SendGridAPIKey)
+	case config.EmailProviderMailgun:
+		if env.Email.MailgunAPIKey == "" || env.Email.MailgunDomain == "" {
+			log.Fatal("MAILGUN_API_KEY and MAILGUN_DOMAIN are required with EMAIL_PROVIDER=mailgun")
+		}
+		emailSender = email.NewMailgun(env.Email.MailgunAPIKey, env.Email.MailgunDomain, env.Email.MailgunAPIBase)
+	case config.EmailProviderSimulate:
+		emailSender = email.NewSimulated()
+		slog.Warn("Email delivery is simulated, no messages are sent")
+	default:
+		log.Fatal("Invalid EMAIL_PROVIDER:", env.Email.Provider)
+	}
+	slog.Info("Email delivery configured", "provider", env.Email.Provider)
+
+	var emailTemplates *email.Templates
+	if env.Email.TemplateDir != "" {
+		emailTemplates, err = email.LoadTemplates(env.Email.TemplateDir)
+		if err != nil {
+			log.Fatal("Failed to load email templates:", err)
+		}
+		slog.Info("Email templates loaded", "dir", env.Email.TemplateDir, "templates", emailTemplates.Names())
+	}
 
 	// Initialize handler registry with task handlers
 	handlerRegistry := worker.NewHandlerRegistry()
-	handlerRegistry.Register(handlers.NewSendEmailHandler())
-	handlerRegistry.Register(handlers.NewRunQueryHandler())
+	handlerRegistry.Register(handlers.NewSendEmailHandler(emailSender, env.Email.From, emailTemplates))
+	httpAllowedNetworks, err := api.ParseAllowlist(env.HTTPAllowedNets)
+	if err != nil {
+		log.Fatal("Invalid HTTP_REQUEST_ALLOWED_NETWORKS:", err)
+	}
+	handlerRegistry.Register(handlers.NewHTTPRequestHandler(handlers.HTTPRequestConfig{
+		AllowedHosts:    env.HTTPAllowedHosts,
+		AllowedNetworks: httpAllowedNetworks,
+	}))
+	if len(env.HTTPAllowedHosts) == 0 {
+		slog.Warn("HTTP_REQUEST_ALLOWED_HOSTS is not set, http_request tasks may call any public host")
+	}
+
+	if len(env.Shell.AllowedCommands) > 0 {
+		commands, err := handlers.ResolveShellCommands(env.Shell.AllowedCommands)
+		if err != nil {
+			log.Fatal("Invalid SHELL_ALLOWED_COMMANDS:", err)
+		}
+		allowedEnv, err := handlers.ResolveShellEnv(env.Shell.AllowedEnv)
+		if err != nil {
+			log.Fatal("Invalid SHELL_ALLOWED_ENV:", err)
+		}
+		handlerRegistry.Register(handlers.NewShellHandler(handlers.ShellConfig{
+			Commands:   commands,
+			Env:        allowedEnv,
+			WorkDir:    env.Shell.WorkDir,
+			CPUSeconds: env.Shell.CPUSeconds,
+			MemoryMB:   env.Shell.MemoryMB,
+			Timeout:    time.Duration(env.Shell.Timeout) * time.Second,
+		}))
+		slog.Info("Shell commands allowed", "commands", commands)
+	}
+
+	if env.KubernetesJobs.Enabled {
+		if env.KubernetesJobs.PollInterval < 1 {
+			log.Fatal("K8S_JOB_POLL_INTERVAL must be at least 1")
+		}
+		client, err := kube.InCluster(env.KubernetesJobs.Namespace)
+		if err != nil {
+			log.Fatal("Failed to create Kubernetes client:", err)
+		}
+		limits := map[string]string{}
+		if env.KubernetesJobs.CPULimit != "" {
+			limits["cpu"] = env.KubernetesJobs.CPULimit
+		}
+		if env.KubernetesJobs.MemoryLimit != "" {
+			limits["memory"] = env.KubernetesJobs.MemoryLimit
+		}
+		handlerRegistry.Register(handlers.NewKubernetesJobHandler(client, handlers.KubernetesJobConfig{
+			AllowedImages:    env.KubernetesJobs.AllowedImages,
+			ServiceAccount:   env.KubernetesJobs.ServiceAccount,
+			PollInterval:     time.Duration(env.KubernetesJobs.PollInterval) * time.Second,
+			TTLAfterFinished: time.Duration(env.KubernetesJobs.TTL) * time.Second,
+			DefaultLimits:    limits,
+		}))
+		slog.Info("Kubernetes jobs enabled", "namespace", client.Namespace(), "allowed_images", env.KubernetesJobs.AllowedImages)
+	}
+
+	var blobStore blobstore.Store
+	switch env.Blobstore.Provider {
+	case "":
+	case config.BlobstoreProviderS3:
+		blobStore, err = blobstore.NewS3(context.Background(), blobstore.S3Config{
+			Bucket:    env.Blobstore.Bucket,
+			Region:    env.Blobstore.S3Region,
+			Endpoint:  env.Blobstore.S3Endpoint,
+			PathStyle: env.Blobstore.S3PathStyle,
+		})
+	case config.BlobstoreProviderGCS:
+		blobStore, err = blobstore.NewGCS(blobstore.GCSConfig{Bucket: env.Blobstore.Bucket, Endpoint: env.Blobstore.GCSEndpoint})
+	case config.BlobstoreProviderFile:
+		blobStore, err = blobstore.NewDir(env.Blobstore.Dir)
+	default:
+		log.Fatal("Invalid BLOBSTORE_PROVIDER:", env.Blobstore.Provider)
+	}
+	if err != nil {
+		log.Fatal("Failed to set up the object store:", err)
+	}
+	if blobStore != nil {
+		blobStore = blobstore.WithPrefix(blobStore, env.Blobstore.Prefix)
+		handlerRegistry.Register(handlers.NewProcessFileHandler(blobStore, handlers.ProcessFileConfig{
+			MaxBytes: env.ProcessFile.MaxBytes,
+			TempDir:  env.ProcessFile.TempDir,
+		}))
+		slog.Info("Object store configured", "provider", env.Blobstore.Provider, "transforms", handlers.FileTransforms())
+	}
+
+	var queryPool *pgxpool.Pool
+	switch {
+	case env.RunQuery.Simulate:
+		handlerRegistry.Register(handlers.NewSimulatedRunQueryHandler())
+		slog.Warn("Query execution is simulated, no queries are run")
+	case env.RunQuery.DatabaseURL != "":
+		if env.RunQuery.MaxRows < 1 || env.RunQuery.StatementTimeout < 1 {
+			log.Fatal("RUN_QUERY_MAX_ROWS and RUN_QUERY_STATEMENT_TIMEOUT must be at least 1")
+		}
+
+		queryPool, err = postgres.NewPool(context.Background(), env.RunQuery.DatabaseURL, postgres.PoolConfig{
+			LogQueries:         env.Database.LogQueries,
+			SlowQueryThreshold: time.Duration(env.Database.SlowQueryMs) * time.Millisecond,
+			ConnectTimeout:     time.Duration(env.Database.ConnectTimeout) * time.Second,
+		})
+		if err != nil {
+			log.Fatal("Failed to create query database pool:", err)
+		}
+		defer queryPool.Close()
+
+		handlerRegistry.Register(handlers.NewRunQueryHandler(queryPool, handlers.RunQueryConfig{
+			StatementTimeout: time.Duration(env.RunQuery.StatementTimeout) * time.Second,
+			MaxRows:          env.RunQuery.MaxRows,
+		}))
+		slog.Info("Query database connection established")
+	default:
+		slog.Warn("RUN_QUERY_DATABASE_URL is not set, run_query tasks are not handled")
+	}
+
+	if env.Reports.Dir != "" {
+		if queryPool == nil || blobStore == nil {
+			log.Fatal("REPORT_DIR needs RUN_QUERY_DATABASE_URL and BLOBSTORE_PROVIDER")
+		}
+		if env.Reports.MaxRows < 1 {
+			log.Fatal("REPORT_MAX_ROWS must be at least 1")
+		}
+		queries, err := report.LoadQueries(env.Reports.Dir)
+		if err != nil {
+			log.Fatal("Failed to load reports:", err)
+		}
+		handlerRegistry.Register(handlers.NewRunReportHandler(queryPool, queries, blobStore, handlers.RunReportConfig{
+			StatementTimeout: time.Duration(env.RunQuery.StatementTimeout) * time.Second,
+			MaxRows:          env.Reports.MaxRows,
+		}))
+		slog.Info("Reports loaded", "dir", env.Reports.Dir, "reports", queries.Names())
+	}
+
+	// Plugin and sidecar handlers are registered last, so they replace built-in handlers of the same type
+	if len(env.Plugins.Paths) > 0 {
+		pluginHandlers, err := plugins.Load(env.Plugins.Paths)
+		if err != nil {
+			log.Fatal("Failed to load plugins:", err)
+		}
+		types := make([]models.TaskType, 0, len(pluginHandlers))
+		for _, h := range pluginHandlers {
+			handlerRegistry.Register(h)
+			types = append(types, h.Type())
+		}
+		slog.Info("Plugin handlers registered", "plugins", env.Plugins.Paths, "types", types)
+	}
+
+	for _, target := range env.Sidecars.Targets {
+		sidecarConn, err := sidecar.Dial(target, time.Duration(env.Sidecars.HeartbeatTimeout)*time.Second)
+		if err != nil {
+			log.Fatal("Invalid SIDECAR_TARGETS:", err)
+		}
+		defer func() { _ = sidecarConn.Close() }()
+
+		sidecarHandlers, err := sidecarConn.Handlers(context.Background())
+		if err != nil {
+			log.Fatal("Failed to reach sidecar:", err)
+		}
+		types := make([]models.TaskType, 0, len(sidecarHandlers))
+		for _, h := range sidecarHandlers {
+			handlerRegistry.Register(h)
+			types = append(types, h.Type())
+		}
+		slog.Info("Sidecar handlers registered", "target", target, "types", types)
+	}
 
 	slog.Info("Registered task handlers", "handlers", handlerRegistry.List())
 
+	typeConcurrency, err := config.TypeConcurrency(os.Environ())
+	if err != nil {
+		log.Fatal("Invalid per-type concurrency:", err)
+	}
+
+	errorReporter := errorreport.Nop()
+	if env.SentryDSN != "" {
+		errorReporter, err = errorreport.NewSentry(errorreport.SentryOptions{
+			DSN:         env.SentryDSN,
+			Environment: env.SentryEnvironment,
+			Release:     version,
+			ServerName:  env.ID,
+		})
+		if err != nil {
+			log.Fatal("Invalid SENTRY_DSN:", err)
+		}
+		defer errorReporter.Flush(5 * time.Second)
+		slog.Info("Sentry error reporting enabled")
+	}
+
+	// Secret references in payloads are resolved just before execution
+	var secretResolver *secrets.Resolver
+	if env.Secrets.Provider != "" {
+		var provider secrets.Provider
+		switch env.Secrets.Provider {
+		case config.SecretsProviderEnv:
+			provider = secrets.Env(env.Secrets.EnvPrefix)
+		case config.SecretsProviderVault:
+			provider, err = secrets.NewVault(secrets.VaultOptions{
+				Address: env.Secrets.VaultAddr,
+				Token:   env.Secrets.VaultToken,
+				Mount:   env.Secrets.VaultMount,
+			})
+		case config.SecretsProviderAWS:
+			provider, err = secrets.NewAWS(context.Background(), env.Secrets.AWSRegion)
+		default:
+			log.Fatal("Invalid SECRETS_PROVIDER:", env.Secrets.Provider)
+		}
+		if err != nil {
+			log.Fatal("Failed to set up secrets provider:", err)
+		}
+
+		secretResolver = secrets.NewResolver(provider, time.Duration(env.Secrets.CacheTTL)*time.Second)
+		slog.Info("Secret references enabled", "provider", env.Secrets.Provider)
+	}
+
 	// Start worker
 	workerConfig := worker.Config{
-		PollInterval: time.Duration(env.PollInterval) * time.Second,
-		TaskTimeout:  time.Duration(env.TaskTimeout) * time.Second,
+		PollInterval:      time.Duration(env.PollInterval) * time.Second,
+		MaxPollInterval:   time.Duration(env.MaxPollInterval) * time.Second,
+		TaskTimeout:       time.Duration(env.TaskTimeout) * time.Second,
+		MaxTaskTimeout:    time.Duration(env.MaxTaskTimeout) * time.Second,
+		HeartbeatInterval: time.Duration(env.HeartbeatInterval) * time.Second,
+		ReaperInterval:    time.Duration(env.ReaperInterval) * time.Second,
+		ShutdownTimeout:   time.Duration(env.ShutdownTimeout) * time.Second,
+		MaxConcurrency:    env.Concurrency,
+		TypeConcurrency:   typeConcurrency,
+		WorkerID:          env.ID,
+		Version:           version,
+		ClaimBatchSize:    env.ClaimBatchSize,
+		Prefetch:          env.Prefetch,
+		ReservedSlots:     env.ReservedSlots,
+		ReservedPriority:  env.ReservedPriority,
+		BreakerThreshold:  env.BreakerThreshold,
+		BreakerCooldown:   time.Duration(env.BreakerCooldown) * time.Second,
+		Labels:            env.Labels,
+		SettingsInterval:  time.Duration(env.SettingsInterval) * time.Second,
+		ErrorReporter:     errorReporter,
+		Secrets:           secretResolver,
+
+		PayloadSigner:         payloadsig.New(env.Signing.Key),
+		AllowUnsignedPayloads: env.Signing.AllowUnsigned,
 	}
 	w := worker.NewWorker(store, handlerRegistry, workerConfig)
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	// Admin HTTP server for probes, metrics and draining
+	var adminSrv *http.Server
+	if env.AdminPort != "" {
+		r := gin.New()
+		r.Use(gin.Recovery())
+		if ops := w.RegisterAdminRoutes(r, env.AdminToken); ops != nil {
+			ops.GET("/log-level", gin.WrapH(logging.LevelHandler(logLevel)))
+			ops.PUT("/log-level", gin.WrapH(logging.LevelHandler(logLevel)))
+		} else {
+			slog.Info("WORKER_ADMIN_TOKEN not set, admin operator routes are disabled")
+		}
+
+		adminSrv = &http.Server{
+			Addr:    net.JoinHostPort(env.AdminHost, env.AdminPort),
+			Handler: r,
+		}
+
+		go func() {
+			slog.Info("Admin HTTP server listening", "addr", adminSrv.Addr)
+			if err := adminSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatal("Admin HTTP server error:", err)
+			}
+		}()
+	}
+
 	if err := w.Start(ctx); err != nil && err != context.Canceled {
 		slog.Error("Worker stopped with error", "error", err)
 	}
+
+	// Keep answering probes until the drain has finished
+	if adminSrv != nil {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+
+		if err := adminSrv.Shutdown(shutdownCtx); err != nil {
+			slog.Error("Admin HTTP server forced to shutdown", "error", err)
+		}
+	}
 	slog.Info("Worker stopped gracefully")
 }
@@ -0,0 +1,3 @@
+-- Drop task_history from the history database
+DROP TABLE IF EXISTS task_history;
+DROP TYPE IF EXISTS task_status;
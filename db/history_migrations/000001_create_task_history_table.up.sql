@@ -0,0 +1,46 @@
+-- task_history for a separate history database (DB_HISTORY_URL)
+-- Same layout as in the main database, minus the foreign key to tasks, which live elsewhere
+DO $$
+BEGIN
+    CREATE TYPE task_status AS ENUM ('queued', 'running', 'succeeded', 'failed', 'quarantined');
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS task_history (
+    id BIGSERIAL,
+    task_id BIGINT NOT NULL,
+    status task_status NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    retry_count INTEGER DEFAULT 0,
+    max_retries INTEGER DEFAULT 0,
+    backoff_seconds INTEGER,
+    next_run_at TIMESTAMP,
+    error_message TEXT,
+    worker_id VARCHAR(100),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    -- The partition key must be part of every unique constraint
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE INDEX IF NOT EXISTS idx_task_history_task_id ON task_history(task_id, created_at DESC);
+
+-- Rows outside every monthly partition, e.g. while partition maintenance is not running
+CREATE TABLE IF NOT EXISTS task_history_default PARTITION OF task_history DEFAULT;
+
+-- Partitions for this month and the next two; partition maintenance keeps them ahead
+DO $$
+DECLARE
+    month DATE := date_trunc('month', NOW());
+BEGIN
+    WHILE month <= date_trunc('month', NOW() + INTERVAL '2 months') LOOP
+        EXECUTE format(
+            'CREATE TABLE IF NOT EXISTS %I PARTITION OF task_history FOR VALUES FROM (%L) TO (%L)',
+            'task_history_' || to_char(month, 'YYYY_MM'), month, month + INTERVAL '1 month'
+        );
+        month := month + INTERVAL '1 month';
+    END LOOP;
+END $$;
+
+-- Documentation
+COMMENT ON TABLE task_history IS 'Audit trail of task status changes, partitioned by month as task_history_YYYY_MM';
@@ -0,0 +1,9 @@
+-- BRIN index on task_history.created_at
+-- History is append-only, so every partition is physically ordered by created_at and a BRIN index,
+-- which stores one min/max summary per block range, is a tiny fraction of a btree's size
+-- autosummarize keeps the newest block ranges indexed as rows arrive
+CREATE INDEX IF NOT EXISTS idx_task_history_created_at ON task_history
+    USING BRIN (created_at) WITH (pages_per_range = 32, autosummarize = on);
+
+-- Documentation
+COMMENT ON INDEX idx_task_history_created_at IS 'BRIN index for time-bounded history scans; relies on rows arriving in created_at order';
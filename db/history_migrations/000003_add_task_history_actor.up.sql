@@ -0,0 +1,5 @@
+-- Principal behind API-initiated history events, e.g. who viewed a task's payload
+ALTER TABLE task_history ADD COLUMN IF NOT EXISTS actor VARCHAR(255);
+
+-- Documentation
+COMMENT ON COLUMN task_history.actor IS 'API key name or JWT subject that caused the event (ip:<address> while authentication is off); NULL for worker events';
@@ -4,3 +4,8 @@ import "embed"
 
 //go:embed migrations/*.sql
 var Migrations embed.FS
+
+// HistoryMigrations create task_history in a separate history database
+//
+//go:embed history_migrations/*.sql
+var HistoryMigrations embed.FS
@@ -0,0 +1,3 @@
+-- Drop lock ownership columns
+ALTER TABLE tasks DROP COLUMN IF EXISTS lock_token;
+ALTER TABLE tasks DROP COLUMN IF EXISTS locked_by;
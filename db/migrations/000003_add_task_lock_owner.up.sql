@@ -0,0 +1,7 @@
+-- Track which worker owns a running task and fence stale writes
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS locked_by VARCHAR(100);
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS lock_token BIGINT NOT NULL DEFAULT 0;
+
+-- Documentation
+COMMENT ON COLUMN tasks.locked_by IS 'ID of the worker currently holding the lock';
+COMMENT ON COLUMN tasks.lock_token IS 'Fencing token incremented on every claim; writes must present the current token';
@@ -0,0 +1,3 @@
+-- Drop retry strategy columns
+ALTER TABLE tasks DROP COLUMN IF EXISTS retry_schedule;
+ALTER TABLE tasks DROP COLUMN IF EXISTS retry_strategy;
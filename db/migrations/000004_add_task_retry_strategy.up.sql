@@ -0,0 +1,7 @@
+-- Per-task retry strategy
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS retry_strategy VARCHAR(20) NOT NULL DEFAULT 'exponential';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS retry_schedule INTEGER[];
+
+-- Documentation
+COMMENT ON COLUMN tasks.retry_strategy IS 'How retry delays are computed: fixed, linear, exponential or custom';
+COMMENT ON COLUMN tasks.retry_schedule IS 'Delays in seconds for each retry attempt when retry_strategy is custom; the last entry repeats';
@@ -0,0 +1,2 @@
+-- Drop backoff cap column
+ALTER TABLE tasks DROP COLUMN IF EXISTS max_backoff_seconds;
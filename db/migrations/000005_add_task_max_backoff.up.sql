@@ -0,0 +1,5 @@
+-- Per-task cap on computed retry delays
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS max_backoff_seconds INTEGER;
+
+-- Documentation
+COMMENT ON COLUMN tasks.max_backoff_seconds IS 'Upper bound for computed retry delays; NULL uses the worker default';
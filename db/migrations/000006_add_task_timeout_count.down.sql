@@ -0,0 +1,3 @@
+-- Drop timeout tracking columns
+ALTER TABLE tasks DROP COLUMN IF EXISTS max_timeouts;
+ALTER TABLE tasks DROP COLUMN IF EXISTS timeout_count;
@@ -0,0 +1,7 @@
+-- Track timeouts separately from other failures
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS timeout_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS max_timeouts INTEGER;
+
+-- Documentation
+COMMENT ON COLUMN tasks.timeout_count IS 'Number of attempts that exceeded timeout_seconds';
+COMMENT ON COLUMN tasks.max_timeouts IS 'Separate retry budget for timeouts; NULL means timeouts consume max_retries';
@@ -0,0 +1,5 @@
+-- Drop index
+DROP INDEX IF EXISTS idx_tasks_type_running;
+
+-- Drop concurrency_limits table
+DROP TABLE IF EXISTS concurrency_limits;
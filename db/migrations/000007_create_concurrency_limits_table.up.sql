@@ -0,0 +1,12 @@
+-- Cluster-wide caps on concurrently running tasks per type
+CREATE TABLE IF NOT EXISTS concurrency_limits (
+    task_type VARCHAR(100) PRIMARY KEY,
+    max_running INTEGER NOT NULL CHECK (max_running > 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Speeds up counting running tasks per type during claims
+CREATE INDEX IF NOT EXISTS idx_tasks_type_running ON tasks(type) WHERE status = 'running';
+
+-- Documentation
+COMMENT ON TABLE concurrency_limits IS 'Maximum number of running tasks per type across all workers';
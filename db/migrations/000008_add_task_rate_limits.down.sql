@@ -0,0 +1,9 @@
+-- Drop index
+DROP INDEX IF EXISTS idx_tasks_rate_limit_key;
+
+-- Drop rate limit columns
+ALTER TABLE tasks DROP COLUMN IF EXISTS last_started_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS rate_limit_key;
+
+-- Drop rate_limits table
+DROP TABLE IF EXISTS rate_limits;
@@ -0,0 +1,20 @@
+-- Rate limits keyed on a payload-derived field (e.g. one email per recipient per minute)
+CREATE TABLE IF NOT EXISTS rate_limits (
+    task_type VARCHAR(100) PRIMARY KEY,
+    key_field VARCHAR(100) NOT NULL,
+    max_per_window INTEGER NOT NULL CHECK (max_per_window > 0),
+    window_seconds INTEGER NOT NULL CHECK (window_seconds > 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS rate_limit_key VARCHAR(255);
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS last_started_at TIMESTAMP;
+
+-- Speeds up counting recent starts per key during claims
+CREATE INDEX IF NOT EXISTS idx_tasks_rate_limit_key ON tasks(rate_limit_key, last_started_at) WHERE rate_limit_key IS NOT NULL;
+
+-- Documentation
+COMMENT ON TABLE rate_limits IS 'Maximum task starts per rate limit key within a sliding window, per task type';
+COMMENT ON COLUMN rate_limits.key_field IS 'Top-level payload field whose value forms the rate limit key';
+COMMENT ON COLUMN tasks.rate_limit_key IS 'Key (prefixed with the task type) that rate limits are enforced on';
+COMMENT ON COLUMN tasks.last_started_at IS 'Timestamp of the most recent claim, used for rate limit windows';
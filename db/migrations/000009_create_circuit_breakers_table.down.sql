@@ -0,0 +1,2 @@
+-- Drop circuit_breakers table
+DROP TABLE IF EXISTS circuit_breakers;
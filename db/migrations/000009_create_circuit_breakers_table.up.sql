@@ -0,0 +1,14 @@
+-- Per-type circuit breakers that pause claiming after repeated failures
+CREATE TABLE IF NOT EXISTS circuit_breakers (
+    task_type VARCHAR(100) PRIMARY KEY,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    opened_until TIMESTAMP,
+    trip_count INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Documentation
+COMMENT ON TABLE circuit_breakers IS 'Consecutive failure tracking per task type; claims skip a type while its breaker is open';
+COMMENT ON COLUMN circuit_breakers.opened_until IS 'Claims of this type are paused until this time';
+COMMENT ON COLUMN circuit_breakers.trip_count IS 'Number of times the breaker has opened';
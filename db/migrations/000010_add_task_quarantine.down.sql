@@ -0,0 +1,6 @@
+-- Postgres cannot drop enum values, so quarantined tasks are failed and the value is left in place
+UPDATE tasks SET status = 'failed' WHERE status = 'quarantined';
+
+-- Drop quarantine columns
+ALTER TABLE tasks DROP COLUMN IF EXISTS quarantined_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS crash_count;
@@ -0,0 +1,10 @@
+-- Quarantine for poison tasks that keep crashing or timing out their handler
+-- The new status value cannot be used in this migration, so no partial index on it here
+ALTER TYPE task_status ADD VALUE IF NOT EXISTS 'quarantined';
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS crash_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS quarantined_at TIMESTAMP;
+
+-- Documentation
+COMMENT ON COLUMN tasks.crash_count IS 'Number of handler panics, timeouts, and expired worker locks since the task was created or released';
+COMMENT ON COLUMN tasks.quarantined_at IS 'Timestamp when the task was quarantined; NULL unless status is quarantined';
@@ -0,0 +1,2 @@
+-- Drop required labels column
+ALTER TABLE tasks DROP COLUMN IF EXISTS required_labels;
@@ -0,0 +1,5 @@
+-- Labels a worker must advertise to claim the task (e.g. {"gpu": "true", "region": "eu"})
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS required_labels JSONB NOT NULL DEFAULT '{}';
+
+-- Documentation
+COMMENT ON COLUMN tasks.required_labels IS 'Labels the claiming worker must have; empty means any worker may claim the task';
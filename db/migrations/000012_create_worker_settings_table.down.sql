@@ -0,0 +1,2 @@
+-- Drop worker_settings table
+DROP TABLE IF EXISTS worker_settings;
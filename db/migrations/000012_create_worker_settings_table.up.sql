@@ -0,0 +1,12 @@
+-- Runtime overrides of worker configuration, applied without a restart
+CREATE TABLE IF NOT EXISTS worker_settings (
+    worker_id VARCHAR(255) PRIMARY KEY,
+    max_concurrency INTEGER CHECK (max_concurrency > 0),
+    poll_interval_seconds INTEGER CHECK (poll_interval_seconds > 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Documentation
+COMMENT ON TABLE worker_settings IS 'Per-worker runtime overrides; the row with worker_id ''default'' applies to every worker';
+COMMENT ON COLUMN worker_settings.max_concurrency IS 'Overrides WORKER_CONCURRENCY; NULL keeps the configured value';
+COMMENT ON COLUMN worker_settings.poll_interval_seconds IS 'Overrides WORKER_POLL_INTERVAL; NULL keeps the configured value';
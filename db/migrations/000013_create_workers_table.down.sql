@@ -0,0 +1,2 @@
+-- Drop workers table
+DROP TABLE IF EXISTS workers;
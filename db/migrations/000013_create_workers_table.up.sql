@@ -0,0 +1,17 @@
+-- Registry of workers, kept fresh by their heartbeats
+CREATE TABLE IF NOT EXISTS workers (
+    id VARCHAR(255) PRIMARY KEY,
+    hostname VARCHAR(255) NOT NULL,
+    version VARCHAR(100) NOT NULL,
+    concurrency INTEGER NOT NULL,
+    in_flight INTEGER NOT NULL DEFAULT 0,
+    labels JSONB NOT NULL DEFAULT '{}',
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_seen TIMESTAMP NOT NULL DEFAULT NOW(),
+    stopped_at TIMESTAMP
+);
+
+-- Documentation
+COMMENT ON TABLE workers IS 'Workers that have registered with the queue; rows not seen for a day are pruned';
+COMMENT ON COLUMN workers.last_seen IS 'Timestamp of the most recent heartbeat';
+COMMENT ON COLUMN workers.stopped_at IS 'Set when the worker shut down gracefully';
@@ -0,0 +1,3 @@
+-- Drop queue pause tables
+DROP TABLE IF EXISTS queue_pause_history;
+DROP TABLE IF EXISTS queue_pauses;
@@ -0,0 +1,22 @@
+-- Operator pauses of the whole queue or of single task types
+CREATE TABLE IF NOT EXISTS queue_pauses (
+    scope VARCHAR(100) PRIMARY KEY,
+    reason TEXT,
+    paused_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Audit trail of pause and resume actions
+CREATE TABLE IF NOT EXISTS queue_pause_history (
+    id BIGSERIAL PRIMARY KEY,
+    scope VARCHAR(100) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_queue_pause_history_created_at ON queue_pause_history(created_at DESC);
+
+-- Documentation
+COMMENT ON TABLE queue_pauses IS 'Claims skip every task while scope * is paused, and tasks of a type while that type is paused';
+COMMENT ON COLUMN queue_pauses.scope IS 'Task type, or * for the whole queue';
+COMMENT ON TABLE queue_pause_history IS 'Every pause and resume performed through the API';
@@ -0,0 +1,2 @@
+-- Drop maintenance mode table
+DROP TABLE IF EXISTS maintenance_mode;
@@ -0,0 +1,13 @@
+-- Queue-wide maintenance mode, a single row toggled through the API
+CREATE TABLE IF NOT EXISTS maintenance_mode (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    stop_claims BOOLEAN NOT NULL DEFAULT FALSE,
+    reason TEXT,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO maintenance_mode (id) VALUES (TRUE) ON CONFLICT (id) DO NOTHING;
+
+-- Documentation
+COMMENT ON TABLE maintenance_mode IS 'While enabled the API rejects new tasks; with stop_claims workers also stop claiming';
@@ -0,0 +1,2 @@
+-- Drop outbox checkpoints table
+DROP TABLE IF EXISTS outbox_checkpoints;
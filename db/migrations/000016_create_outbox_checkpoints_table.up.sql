@@ -0,0 +1,9 @@
+-- Progress of the outbox relay through each outbox table
+CREATE TABLE IF NOT EXISTS outbox_checkpoints (
+    outbox_table VARCHAR(255) PRIMARY KEY,
+    last_id BIGINT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Documentation
+COMMENT ON TABLE outbox_checkpoints IS 'Highest outbox row id the relay has turned into a task, per outbox table';
@@ -0,0 +1,2 @@
+-- Drop claim index
+DROP INDEX CONCURRENTLY IF EXISTS idx_tasks_claim;
@@ -0,0 +1,4 @@
+-- Partial index matching the claim query's filter and ORDER BY
+-- Only queued rows are indexed, so claims stay fast however many finished tasks the table holds
+-- Built concurrently so existing deployments keep claiming while it builds; must stay the only statement in this file
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_tasks_claim ON tasks(priority DESC, created_at ASC, next_run_at) WHERE status = 'queued';
@@ -0,0 +1,28 @@
+-- Convert task_history back to a single table
+ALTER TABLE task_history RENAME TO task_history_partitioned;
+ALTER INDEX idx_task_history_task_id RENAME TO idx_task_history_partitioned_task_id;
+
+CREATE TABLE task_history (
+    id BIGINT PRIMARY KEY DEFAULT nextval('task_history_id_seq'),
+    task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    status task_status NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    retry_count INTEGER DEFAULT 0,
+    max_retries INTEGER DEFAULT 0,
+    backoff_seconds INTEGER,
+    next_run_at TIMESTAMP,
+    error_message TEXT,
+    worker_id VARCHAR(100),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER SEQUENCE task_history_id_seq OWNED BY task_history.id;
+
+CREATE INDEX idx_task_history_task_id ON task_history(task_id, created_at DESC);
+
+INSERT INTO task_history SELECT * FROM task_history_partitioned;
+
+-- Drop the partitioned table and all of its partitions
+DROP TABLE task_history_partitioned;
+
+COMMENT ON TABLE task_history IS 'Audit trail of task status changes';
@@ -0,0 +1,48 @@
+-- Convert task_history to monthly range partitions on created_at
+-- Old months can then be dropped as a whole partition instead of deleted row by row
+ALTER TABLE task_history RENAME TO task_history_unpartitioned;
+ALTER INDEX idx_task_history_task_id RENAME TO idx_task_history_unpartitioned_task_id;
+
+CREATE TABLE task_history (
+    id BIGINT NOT NULL DEFAULT nextval('task_history_id_seq'),
+    task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    status task_status NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    retry_count INTEGER DEFAULT 0,
+    max_retries INTEGER DEFAULT 0,
+    backoff_seconds INTEGER,
+    next_run_at TIMESTAMP,
+    error_message TEXT,
+    worker_id VARCHAR(100),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    -- The partition key must be part of every unique constraint
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+-- Keep the id sequence when the old table is dropped
+ALTER SEQUENCE task_history_id_seq OWNED BY task_history.id;
+
+CREATE INDEX idx_task_history_task_id ON task_history(task_id, created_at DESC);
+
+-- Rows outside every monthly partition, e.g. while partition maintenance is not running
+CREATE TABLE task_history_default PARTITION OF task_history DEFAULT;
+
+-- One partition per month from the oldest existing row up to two months ahead
+DO $$
+DECLARE
+    month DATE := date_trunc('month', COALESCE((SELECT MIN(created_at) FROM task_history_unpartitioned), NOW()));
+BEGIN
+    WHILE month <= date_trunc('month', NOW() + INTERVAL '2 months') LOOP
+        EXECUTE format(
+            'CREATE TABLE IF NOT EXISTS %I PARTITION OF task_history FOR VALUES FROM (%L) TO (%L)',
+            'task_history_' || to_char(month, 'YYYY_MM'), month, month + INTERVAL '1 month'
+        );
+        month := month + INTERVAL '1 month';
+    END LOOP;
+END $$;
+
+INSERT INTO task_history SELECT * FROM task_history_unpartitioned;
+DROP TABLE task_history_unpartitioned;
+
+-- Documentation
+COMMENT ON TABLE task_history IS 'Audit trail of task status changes, partitioned by month as task_history_YYYY_MM';
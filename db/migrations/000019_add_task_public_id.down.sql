@@ -0,0 +1,4 @@
+-- Drop public task identifier
+DROP INDEX IF EXISTS idx_tasks_public_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS public_id;
+DROP FUNCTION IF EXISTS tasks_uuid_v7(TIMESTAMPTZ);
@@ -0,0 +1,24 @@
+-- Public task identifier: a UUIDv7, so ids sort by creation time without revealing task volume
+-- tasks_uuid_v7 stamps a random UUID with the millisecond timestamp and version 7 (PostgreSQL 13+)
+CREATE OR REPLACE FUNCTION tasks_uuid_v7(ts TIMESTAMPTZ) RETURNS UUID AS $$
+    SELECT encode(
+        set_bit(set_bit(
+            overlay(uuid_send(gen_random_uuid())
+                placing substring(int8send(floor(extract(epoch FROM ts) * 1000)::BIGINT) FROM 3)
+                FROM 1 FOR 6),
+            52, 1), 53, 1),
+        'hex')::UUID
+$$ LANGUAGE SQL VOLATILE;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS public_id UUID;
+
+-- Existing tasks keep their creation order
+UPDATE tasks SET public_id = tasks_uuid_v7(created_at) WHERE public_id IS NULL;
+
+ALTER TABLE tasks ALTER COLUMN public_id SET DEFAULT tasks_uuid_v7(clock_timestamp());
+ALTER TABLE tasks ALTER COLUMN public_id SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_public_id ON tasks(public_id);
+
+-- Documentation
+COMMENT ON COLUMN tasks.public_id IS 'UUIDv7 exposed as the task id by the API; the bigint id stays internal';
@@ -0,0 +1,4 @@
+-- Drop task versioning
+DROP TRIGGER IF EXISTS tasks_bump_version ON tasks;
+DROP FUNCTION IF EXISTS tasks_bump_version();
+ALTER TABLE tasks DROP COLUMN IF EXISTS version;
@@ -0,0 +1,19 @@
+-- Optimistic concurrency: every update of a task bumps its version
+-- Writers that read a task first pass the version they saw and are rejected if it moved on
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+-- A trigger bumps the version so no UPDATE can forget it
+CREATE OR REPLACE FUNCTION tasks_bump_version() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS tasks_bump_version ON tasks;
+CREATE TRIGGER tasks_bump_version
+    BEFORE UPDATE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION tasks_bump_version();
+
+-- Documentation
+COMMENT ON COLUMN tasks.version IS 'Incremented on every update; compared by writers to reject stale changes';
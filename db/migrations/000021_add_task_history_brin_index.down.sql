@@ -0,0 +1,2 @@
+-- Drop the task_history BRIN index
+DROP INDEX IF EXISTS idx_task_history_created_at;
@@ -0,0 +1,2 @@
+-- Drop task metadata
+ALTER TABLE tasks DROP COLUMN IF EXISTS metadata;
@@ -0,0 +1,6 @@
+-- Caller-supplied metadata, including the W3C trace context of the creating request
+-- Handed to the task's handler so downstream calls continue the original trace
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- Documentation
+COMMENT ON COLUMN tasks.metadata IS 'String map such as {"traceparent": "00-..."} passed to the handler';
@@ -0,0 +1,2 @@
+-- Drop finished tasks index
+DROP INDEX CONCURRENTLY IF EXISTS idx_tasks_finished;
@@ -0,0 +1,4 @@
+-- Partial index over finished tasks by completion time
+-- Serves latency and throughput statistics over a recent window without scanning the whole table
+-- Built concurrently so existing deployments keep running while it builds; must stay the only statement in this file
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_tasks_finished ON tasks(updated_at) WHERE status IN ('succeeded', 'failed');
@@ -0,0 +1,2 @@
+-- Drop creation time index
+DROP INDEX CONCURRENTLY IF EXISTS idx_tasks_created_at;
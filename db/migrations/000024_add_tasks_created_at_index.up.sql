@@ -0,0 +1,3 @@
+-- Index on creation time for time-bucketed and date-range statistics
+-- Built concurrently so existing deployments keep running while it builds; must stay the only statement in this file
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_tasks_created_at ON tasks(created_at);
@@ -0,0 +1,5 @@
+-- Drop worker task totals
+ALTER TABLE workers
+    DROP COLUMN IF EXISTS tasks_succeeded,
+    DROP COLUMN IF EXISTS tasks_failed,
+    DROP COLUMN IF EXISTS busy_seconds;
@@ -0,0 +1,9 @@
+-- Task totals each worker reports with its heartbeats, reset when it registers
+ALTER TABLE workers
+    ADD COLUMN IF NOT EXISTS tasks_succeeded BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS tasks_failed BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS busy_seconds DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+-- Documentation
+COMMENT ON COLUMN workers.tasks_failed IS 'Failed, timed out and crashed executions since the worker started';
+COMMENT ON COLUMN workers.busy_seconds IS 'Handler execution time summed over the counted executions';
@@ -0,0 +1,2 @@
+-- Drop alert rules
+DROP TABLE IF EXISTS alert_rules;
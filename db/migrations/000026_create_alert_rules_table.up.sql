@@ -0,0 +1,21 @@
+-- Built-in alerting: rules evaluated periodically by the API server, notifying a webhook on every transition
+CREATE TABLE IF NOT EXISTS alert_rules (
+    name VARCHAR(100) PRIMARY KEY,
+    metric VARCHAR(50) NOT NULL,
+    task_type VARCHAR(100) NOT NULL DEFAULT '',
+    operator VARCHAR(2) NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL,
+    window_seconds INTEGER NOT NULL DEFAULT 0 CHECK (window_seconds >= 0),
+    webhook_url TEXT NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    firing BOOLEAN NOT NULL DEFAULT FALSE,
+    last_value DOUBLE PRECISION,
+    last_evaluated_at TIMESTAMP,
+    last_transition_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Documentation
+COMMENT ON TABLE alert_rules IS 'Threshold rules on queue metrics, e.g. failed_tasks of a type > 50 within 10 minutes';
+COMMENT ON COLUMN alert_rules.task_type IS 'Task type the metric is computed for; empty for all types';
+COMMENT ON COLUMN alert_rules.firing IS 'Whether the rule matched at its last evaluation; notifications are sent when it changes';
@@ -0,0 +1,18 @@
+-- API keys: credentials for the task queue API, stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    key_prefix VARCHAR(20) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+-- Documentation
+COMMENT ON TABLE api_keys IS 'API keys checked on every request when API_AUTH is enabled';
+COMMENT ON COLUMN api_keys.key_prefix IS 'First characters of the key in clear, to tell keys apart without revealing them';
+COMMENT ON COLUMN api_keys.key_hash IS 'Hex SHA-256 hash of the key; the key itself is only shown when it is created';
+COMMENT ON COLUMN api_keys.scopes IS 'Granted scopes: read, write and admin, which grants every scope';
+COMMENT ON COLUMN api_keys.revoked_at IS 'When the key was revoked; revoked keys are kept for auditing';
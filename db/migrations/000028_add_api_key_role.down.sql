@@ -0,0 +1,2 @@
+-- Drop API key roles
+ALTER TABLE api_keys DROP COLUMN IF EXISTS role;
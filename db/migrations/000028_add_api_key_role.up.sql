@@ -0,0 +1,5 @@
+-- Role-based access control: keys carry a role on top of, or instead of, individual scopes
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT '';
+
+-- Documentation
+COMMENT ON COLUMN api_keys.role IS 'viewer, operator or admin; empty for keys granted scopes only';
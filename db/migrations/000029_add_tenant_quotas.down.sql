@@ -0,0 +1,3 @@
+-- Drop tenant quotas
+DROP TABLE IF EXISTS tenant_quotas;
+ALTER TABLE tasks DROP COLUMN IF EXISTS tenant;
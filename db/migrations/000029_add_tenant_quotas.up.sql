@@ -0,0 +1,15 @@
+-- Tenants: tasks may belong to a tenant whose quotas cap its queued and running tasks
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS tenant VARCHAR(100) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS tenant_quotas (
+    tenant VARCHAR(100) PRIMARY KEY,
+    max_queued INTEGER CHECK (max_queued >= 0),
+    max_running INTEGER CHECK (max_running >= 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Documentation
+COMMENT ON COLUMN tasks.tenant IS 'Owner subject to tenant_quotas; empty for tasks without a tenant';
+COMMENT ON TABLE tenant_quotas IS 'Per-tenant caps on queued and running tasks; the * row applies to tenants without their own';
+COMMENT ON COLUMN tenant_quotas.max_queued IS 'Queued tasks above which task creation is rejected with 429; NULL falls back to the * row';
+COMMENT ON COLUMN tenant_quotas.max_running IS 'Tasks of the tenant claimed at once across all workers; NULL falls back to the * row';
@@ -0,0 +1,2 @@
+-- Drop tenant index
+DROP INDEX CONCURRENTLY IF EXISTS idx_tasks_tenant_status;
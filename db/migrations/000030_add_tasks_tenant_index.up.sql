@@ -0,0 +1,3 @@
+-- Index on tenant and status for quota checks on queued and running tasks of a tenant
+-- Built concurrently so existing deployments keep running while it builds; must stay the only statement in this file
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_tasks_tenant_status ON tasks(tenant, status) WHERE tenant <> '';
@@ -0,0 +1,2 @@
+-- Drop task redaction
+ALTER TABLE tasks DROP COLUMN IF EXISTS redacted_at;
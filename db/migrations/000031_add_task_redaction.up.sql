@@ -0,0 +1,5 @@
+-- Erasure: finished tasks can be scrubbed of their payload and error messages for data-subject deletion requests
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS redacted_at TIMESTAMP;
+
+-- Documentation
+COMMENT ON COLUMN tasks.redacted_at IS 'When the payload, rate limit key and error messages were irreversibly scrubbed; NULL if never';
@@ -0,0 +1,2 @@
+-- Drop alert rule signing secrets
+ALTER TABLE alert_rules DROP COLUMN IF EXISTS signing_secret;
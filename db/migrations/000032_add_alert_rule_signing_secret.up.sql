@@ -0,0 +1,5 @@
+-- Signed webhooks: every delivery of a rule with a secret carries an HMAC signature its receiver can verify
+ALTER TABLE alert_rules ADD COLUMN IF NOT EXISTS signing_secret TEXT NOT NULL DEFAULT '';
+
+-- Documentation
+COMMENT ON COLUMN alert_rules.signing_secret IS 'HMAC-SHA256 key of the X-Signature header on webhook deliveries; empty = unsigned. Never returned by the API';
@@ -0,0 +1,2 @@
+-- Drop task payload signatures
+ALTER TABLE tasks DROP COLUMN IF EXISTS payload_signature;
@@ -0,0 +1,5 @@
+-- Tamper-evident payloads: an HMAC of the payload computed at enqueue time, verified by workers before execution
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS payload_signature TEXT NOT NULL DEFAULT '';
+
+-- Documentation
+COMMENT ON COLUMN tasks.payload_signature IS 'Hex HMAC-SHA256 of the task type and canonical payload under PAYLOAD_SIGNING_KEY; empty = unsigned';
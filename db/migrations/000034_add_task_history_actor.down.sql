@@ -0,0 +1,2 @@
+-- Drop the task_history actor column
+ALTER TABLE task_history DROP COLUMN IF EXISTS actor;
@@ -0,0 +1,2 @@
+-- Drop task results
+DROP TABLE IF EXISTS task_results;
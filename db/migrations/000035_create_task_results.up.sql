@@ -0,0 +1,10 @@
+-- Task results: output a handler stored for a succeeded task, e.g. the summary of a run_query task
+CREATE TABLE IF NOT EXISTS task_results (
+    task_id BIGINT PRIMARY KEY REFERENCES tasks(id) ON DELETE CASCADE,
+    result JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Documentation
+COMMENT ON TABLE task_results IS 'One result per task, saved by the worker holding its lock right before it completes the task';
+COMMENT ON COLUMN task_results.result IS 'JSON set by the handler with models.SetResult, at most 64 KiB; a retried task replaces it';
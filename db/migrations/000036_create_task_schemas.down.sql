@@ -0,0 +1,2 @@
+-- Drop task schemas
+DROP TABLE IF EXISTS task_schemas;
@@ -0,0 +1,11 @@
+-- Task schemas: the JSON Schema payloads of a task type must match, checked when tasks are created
+CREATE TABLE IF NOT EXISTS task_schemas (
+    task_type TEXT PRIMARY KEY,
+    schema JSONB NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Documentation
+COMMENT ON TABLE task_schemas IS 'One JSON Schema per task type; POST /tasks answers 422 for payloads that do not match';
+COMMENT ON COLUMN task_schemas.task_type IS 'Lowercase task type';
+COMMENT ON COLUMN task_schemas.schema IS 'JSON Schema, draft 2020-12 unless its $schema names another draft; it may not $ref other documents';
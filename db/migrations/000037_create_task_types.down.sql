@@ -0,0 +1,3 @@
+-- Drop the task type registry
+ALTER TABLE workers DROP COLUMN IF EXISTS handler_types;
+DROP TABLE IF EXISTS task_types;
@@ -0,0 +1,12 @@
+-- Task type registry: the types POST /tasks accepts, reported by workers when they register or added by operators
+CREATE TABLE IF NOT EXISTS task_types (
+    task_type TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE workers
+    ADD COLUMN IF NOT EXISTS handler_types TEXT[] NOT NULL DEFAULT '{}';
+
+-- Documentation
+COMMENT ON TABLE task_types IS 'Lowercase task types registered through the API, known whether or not a worker handles them';
+COMMENT ON COLUMN workers.handler_types IS 'Lowercase task types the worker has handlers for, reported when it registers';
@@ -0,0 +1,2 @@
+-- Drop the public id from the payload signature comment
+COMMENT ON COLUMN tasks.payload_signature IS 'Hex HMAC-SHA256 of the task type and canonical payload under PAYLOAD_SIGNING_KEY; empty = unsigned';
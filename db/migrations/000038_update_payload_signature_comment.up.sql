@@ -0,0 +1,4 @@
+-- Payload signatures cover the task's public id, so they cannot be copied onto another row
+
+-- Documentation
+COMMENT ON COLUMN tasks.payload_signature IS 'Hex HMAC-SHA256 of the public id, task type and canonical payload under PAYLOAD_SIGNING_KEY; empty = unsigned';
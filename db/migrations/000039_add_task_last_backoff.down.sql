@@ -0,0 +1,2 @@
+-- Drop previous retry delay column
+ALTER TABLE tasks DROP COLUMN IF EXISTS last_backoff_seconds;
@@ -0,0 +1,5 @@
+-- Previous retry delay, the starting point for decorrelated jitter
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS last_backoff_seconds INTEGER;
+
+-- Documentation
+COMMENT ON COLUMN tasks.last_backoff_seconds IS 'Delay before the most recent retry; NULL until the first retry';
@@ -0,0 +1,9 @@
+-- Bump the version on every update again, lock extensions included
+CREATE OR REPLACE FUNCTION tasks_bump_version() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMENT ON COLUMN tasks.version IS 'Incremented on every update; compared by writers to reject stale changes';
@@ -0,0 +1,20 @@
+-- Heartbeats only move lock_expires_at (and updated_at); bumping the version for them would
+-- make the ETag of a running task stale every heartbeat, so If-Match writes on it always conflict
+CREATE OR REPLACE FUNCTION tasks_bump_version() RETURNS TRIGGER AS $$
+DECLARE
+    unchanged tasks%ROWTYPE;
+BEGIN
+    unchanged := NEW;
+    unchanged.lock_expires_at := OLD.lock_expires_at;
+    unchanged.updated_at := OLD.updated_at;
+    IF unchanged IS NOT DISTINCT FROM OLD THEN
+        NEW.version := OLD.version;
+    ELSE
+        NEW.version := OLD.version + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Documentation
+COMMENT ON COLUMN tasks.version IS 'Incremented on every update except lock extensions; compared by writers to reject stale changes';
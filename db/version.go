@@ -0,0 +1,33 @@
+package db
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// LatestVersion returns the highest migration version in dir of migrations
+func LatestVersion(migrations fs.FS, dir string) (uint, error) {
+	d, err := iofs.New(migrations, dir)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = d.Close() }()
+
+	version, err := d.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := d.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
@@ -0,0 +1,19 @@
+package db
+
+import "testing"
+
+func TestLatestVersion(t *testing.T) {
+	version, err := LatestVersion(Migrations, "migrations")
+	if err != nil {
+		t.Fatalf("LatestVersion: %v", err)
+	}
+
+	files, err := Migrations.ReadDir("migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every version has an up and a down file
+	if want := uint(len(files) / 2); version != want {
+		t.Errorf("LatestVersion = %d, want %d", version, want)
+	}
+}
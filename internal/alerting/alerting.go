@@ -0,0 +1,214 @@
+// Package alerting evaluates alert rules against queue metrics and notifies their webhooks when they fire or resolve
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/webhook"
+)
+
+// notifyTimeout bounds a webhook call when no client is given
+const notifyTimeout = 10 * time.Second
+
+// Evaluator evaluates the alert rules of a store
+type Evaluator struct {
+	store     storage.Store
+	analytics storage.TaskAnalytics // nil when the store cannot compute windowed metrics
+	client    *http.Client
+}
+
+// NewEvaluator creates an evaluator that posts notifications with client (nil = a client with a 10s timeout)
+// Windowed metrics need a store implementing storage.TaskAnalytics
+func NewEvaluator(store storage.Store, client *http.Client) *Evaluator {
+	if client == nil {
+		client = &http.Client{Timeout: notifyTimeout}
+	}
+	analytics, _ := store.(storage.TaskAnalytics)
+	return &Evaluator{store: store, analytics: analytics, client: client}
+}
+
+// Evaluate evaluates every rule once and notifies the webhook of each rule that started or stopped firing
+// A rule whose notification fails is reverted, so the next evaluation notifies again
+func (e *Evaluator) Evaluate(ctx context.Context) error {
+	rules, err := e.store.ListAlertRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	m := &metrics{evaluator: e, rates: map[int][]models.SuccessRate{}}
+	var errs []error
+	for _, rule := range rules {
+		if err := e.evaluate(ctx, rule, m); err != nil {
+			errs = append(errs, fmt.Errorf("alert rule %s: %w", rule.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// evaluate evaluates one rule and notifies its webhook if its state changed
+func (e *Evaluator) evaluate(ctx context.Context, rule models.AlertRule, m *metrics) error {
+	value, err := m.value(ctx, rule)
+	if err != nil {
+		return err
+	}
+
+	firing := rule.Operator.Matches(value, rule.Threshold)
+	changed, err := e.store.RecordAlertEvaluation(ctx, rule.Name, firing, value)
+	if err != nil || !changed {
+		return err
+	}
+
+	status := models.AlertStatusResolved
+	if firing {
+		status = models.AlertStatusFiring
+	}
+	slog.Info("Alert rule "+status, "rule", rule.Name, "metric", rule.Metric, "task_type", rule.TaskType, "value", value, "threshold", rule.Threshold)
+
+	notification := models.AlertNotification{
+		Rule:          rule.Name,
+		Status:        status,
+		Metric:        rule.Metric,
+		TaskType:      rule.TaskType,
+		Operator:      rule.Operator,
+		Threshold:     rule.Threshold,
+		Value:         value,
+		WindowSeconds: rule.WindowSeconds,
+		At:            time.Now().UTC(),
+	}
+	if err := e.notify(ctx, rule, notification); err != nil {
+		if _, revertErr := e.store.RecordAlertEvaluation(ctx, rule.Name, !firing, value); revertErr != nil {
+			slog.Error("Failed to revert alert rule after a failed notification", "rule", rule.Name, "error", revertErr)
+		}
+		return fmt.Errorf("notify: %w", err)
+	}
+	return nil
+}
+
+// notify posts a notification to the rule's webhook in the rule's channel format
+func (e *Evaluator) notify(ctx context.Context, rule models.AlertRule, n models.AlertNotification) error {
+	body, err := notificationBody(rule.Channel, n)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rule.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if rule.SigningSecret != "" {
+		webhook.Sign(req, rule.SigningSecret, body, time.Now())
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// notificationBody encodes a notification as a JSON AlertNotification or a Slack message
+func notificationBody(channel models.AlertChannel, n models.AlertNotification) ([]byte, error) {
+	if channel != models.AlertChannelSlack {
+		return json.Marshal(n)
+	}
+
+	icon := ":rotating_light:"
+	if n.Status == models.AlertStatusResolved {
+		icon = ":white_check_mark:"
+	}
+	subject := string(n.Metric)
+	if n.TaskType != "" {
+		subject += " of " + n.TaskType
+	}
+	if n.WindowSeconds > 0 {
+		subject += " over " + (time.Duration(n.WindowSeconds) * time.Second).String()
+	}
+	text := fmt.Sprintf("%s *[%s] %s*: %s is %g (threshold %s %g)", icon, n.Status, n.Rule, subject, n.Value, n.Operator, n.Threshold)
+
+	return json.Marshal(map[string]string{"text": text})
+}
+
+// metrics loads the figures rules are evaluated against at most once per evaluation
+type metrics struct {
+	evaluator *Evaluator
+	depths    []models.QueueDepth
+	rates     map[int][]models.SuccessRate // by window in seconds
+}
+
+// value returns the current value of a rule's metric
+func (m *metrics) value(ctx context.Context, rule models.AlertRule) (float64, error) {
+	if !rule.Metric.Windowed() {
+		if m.depths == nil {
+			depths, err := m.evaluator.store.GetQueueDepth(ctx)
+			if err != nil {
+				return 0, err
+			}
+			m.depths = depths
+		}
+		return depthValue(rule, m.depths), nil
+	}
+
+	if m.evaluator.analytics == nil {
+		return 0, fmt.Errorf("%s needs the postgres backend", rule.Metric)
+	}
+	rates, ok := m.rates[rule.WindowSeconds]
+	if !ok {
+		window := time.Duration(rule.WindowSeconds) * time.Second
+		var err error
+		rates, err = m.evaluator.analytics.GetSuccessRates(ctx, models.TimeRange{From: time.Now().Add(-window)})
+		if err != nil {
+			return 0, err
+		}
+		m.rates[rule.WindowSeconds] = rates
+	}
+	return rateValue(rule, rates), nil
+}
+
+// depthValue computes queue_depth or oldest_queued_age over the rule's task types
+func depthValue(rule models.AlertRule, depths []models.QueueDepth) float64 {
+	var value float64
+	for _, d := range depths {
+		if rule.TaskType != "" && d.TaskType != rule.TaskType {
+			continue
+		}
+		switch rule.Metric {
+		case models.AlertMetricQueueDepth:
+			value += float64(d.Queued)
+		case models.AlertMetricOldestQueuedAge:
+			value = max(value, d.OldestAgeSeconds)
+		}
+	}
+	return value
+}
+
+// rateValue computes failed_tasks or failure_rate over the rule's task types
+func rateValue(rule models.AlertRule, rates []models.SuccessRate) float64 {
+	var succeeded, failed int64
+	for _, r := range rates {
+		if rule.TaskType != "" && r.TaskType != rule.TaskType {
+			continue
+		}
+		succeeded += r.Succeeded
+		failed += r.Failed
+	}
+
+	if rule.Metric == models.AlertMetricFailureRate {
+		return models.FailureRate(succeeded, failed)
+	}
+	return float64(failed)
+}
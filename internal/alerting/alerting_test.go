@@ -0,0 +1,205 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/webhook"
+)
+
+// ruleStore keeps alert rule state in memory and reports a fixed queue depth; other Store methods are not used
+type ruleStore struct {
+	storage.Store
+	rules  []models.AlertRule
+	depths []models.QueueDepth
+}
+
+func (s *ruleStore) ListAlertRules(ctx context.Context) ([]models.AlertRule, error) {
+	return s.rules, nil
+}
+
+func (s *ruleStore) RecordAlertEvaluation(ctx context.Context, name string, firing bool, value float64) (bool, error) {
+	for i := range s.rules {
+		if s.rules[i].Name == name {
+			changed := s.rules[i].Firing != firing
+			s.rules[i].Firing = firing
+			return changed, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *ruleStore) GetQueueDepth(ctx context.Context) ([]models.QueueDepth, error) {
+	return s.depths, nil
+}
+
+func TestEvaluateNotifiesTransitions(t *testing.T) {
+	var received []models.AlertNotification
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		var n models.AlertNotification
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			t.Errorf("decode notification: %v", err)
+		}
+		received = append(received, n)
+	}))
+	defer server.Close()
+
+	store := &ruleStore{
+		rules: []models.AlertRule{{
+			Name:       "email-backlog",
+			Metric:     models.AlertMetricQueueDepth,
+			TaskType:   "send_email",
+			Operator:   models.AlertOperatorAbove,
+			Threshold:  100,
+			WebhookURL: server.URL,
+			Channel:    models.AlertChannelWebhook,
+		}},
+		depths: []models.QueueDepth{{TaskType: "send_email", Queued: 150}, {TaskType: "run_query", Queued: 900}},
+	}
+	e := NewEvaluator(store, server.Client())
+	ctx := context.Background()
+
+	// Fires once, however often it is evaluated
+	for i := 0; i < 2; i++ {
+		if err := e.Evaluate(ctx); err != nil {
+			t.Fatalf("Evaluate() error = %v", err)
+		}
+	}
+	if len(received) != 1 || received[0].Status != models.AlertStatusFiring || received[0].Value != 150 {
+		t.Fatalf("notifications = %+v, want one firing with value 150", received)
+	}
+
+	// A failed notification is retried on the next evaluation
+	store.depths[0].Queued = 10
+	fail = true
+	if err := e.Evaluate(ctx); err == nil {
+		t.Fatal("Evaluate() error = nil, want the webhook failure")
+	}
+	if !store.rules[0].Firing {
+		t.Fatal("rule resolved despite the failed notification")
+	}
+	fail = false
+	if err := e.Evaluate(ctx); err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if len(received) != 2 || received[1].Status != models.AlertStatusResolved {
+		t.Fatalf("notifications = %+v, want firing then resolved", received)
+	}
+}
+
+func TestEvaluateSignsDeliveries(t *testing.T) {
+	var verifyErr error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verifyErr = webhook.Verify(r.Header, "0123456789abcdef", body, webhook.DefaultTolerance, time.Now())
+	}))
+	defer server.Close()
+
+	store := &ruleStore{
+		rules: []models.AlertRule{{
+			Name:          "backlog",
+			Metric:        models.AlertMetricQueueDepth,
+			Operator:      models.AlertOperatorAbove,
+			Threshold:     100,
+			WebhookURL:    server.URL,
+			Channel:       models.AlertChannelWebhook,
+			SigningSecret: "0123456789abcdef",
+		}},
+		depths: []models.QueueDepth{{TaskType: "send_email", Queued: 150}},
+	}
+	if err := NewEvaluator(store, server.Client()).Evaluate(context.Background()); err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if verifyErr != nil {
+		t.Errorf("Verify() of the delivery error = %v", verifyErr)
+	}
+}
+
+func TestEvaluateWindowedMetricNeedsAnalytics(t *testing.T) {
+	store := &ruleStore{rules: []models.AlertRule{{
+		Name:          "email-failures",
+		Metric:        models.AlertMetricFailedTasks,
+		Operator:      models.AlertOperatorAbove,
+		WindowSeconds: 600,
+	}}}
+
+	if err := NewEvaluator(store, nil).Evaluate(context.Background()); err == nil {
+		t.Fatal("Evaluate() error = nil, want an error for a store without analytics")
+	}
+}
+
+func TestMetricValues(t *testing.T) {
+	depths := []models.QueueDepth{
+		{TaskType: "send_email", Queued: 10, OldestAgeSeconds: 30},
+		{TaskType: "run_query", Queued: 5, OldestAgeSeconds: 400},
+	}
+	rates := []models.SuccessRate{
+		{TaskType: "send_email", Succeeded: 90, Failed: 10},
+		{TaskType: "run_query", Succeeded: 0, Failed: 100},
+	}
+
+	tests := []struct {
+		rule models.AlertRule
+		want float64
+	}{
+		{models.AlertRule{Metric: models.AlertMetricQueueDepth}, 15},
+		{models.AlertRule{Metric: models.AlertMetricQueueDepth, TaskType: "run_query"}, 5},
+		{models.AlertRule{Metric: models.AlertMetricOldestQueuedAge}, 400},
+		{models.AlertRule{Metric: models.AlertMetricOldestQueuedAge, TaskType: "unknown"}, 0},
+		{models.AlertRule{Metric: models.AlertMetricFailedTasks}, 110},
+		{models.AlertRule{Metric: models.AlertMetricFailureRate, TaskType: "send_email"}, 0.1},
+		{models.AlertRule{Metric: models.AlertMetricFailureRate, TaskType: "unknown"}, 0},
+	}
+
+	for _, tt := range tests {
+		var got float64
+		if tt.rule.Metric.Windowed() {
+			got = rateValue(tt.rule, rates)
+		} else {
+			got = depthValue(tt.rule, depths)
+		}
+		if got != tt.want {
+			t.Errorf("value of %s for %q = %v, want %v", tt.rule.Metric, tt.rule.TaskType, got, tt.want)
+		}
+	}
+}
+
+func TestSlackNotificationBody(t *testing.T) {
+	body, err := notificationBody(models.AlertChannelSlack, models.AlertNotification{
+		Rule:          "email-failures",
+		Status:        models.AlertStatusFiring,
+		Metric:        models.AlertMetricFailedTasks,
+		TaskType:      "send_email",
+		Operator:      models.AlertOperatorAbove,
+		Threshold:     50,
+		Value:         51,
+		WindowSeconds: 600,
+	})
+	if err != nil {
+		t.Fatalf("notificationBody() error = %v", err)
+	}
+
+	var message struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(body, &message); err != nil {
+		t.Fatalf("unmarshal Slack message: %v", err)
+	}
+	want := "*[firing] email-failures*: failed_tasks of send_email over 10m0s is 51 (threshold > 50)"
+	if !strings.Contains(message.Text, want) {
+		t.Errorf("Slack text = %q, want it to contain %q", message.Text, want)
+	}
+}
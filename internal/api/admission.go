@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// admissionRefresh is how long queue depths are reused between admission checks
+const admissionRefresh = time.Second
+
+// admissionRetryAfter is when producers turned away at a full queue are told to try again
+const admissionRetryAfter = 30 * time.Second
+
+// admissionControl caps how many tasks may be queued, in total and per task type
+// Depths come from GetQueueDepth, refreshed at most every admissionRefresh and advanced locally
+// by the tasks admitted in between, so a burst cannot overshoot a cap by more than other servers add
+type admissionControl struct {
+	maxQueued int64            // 0 = no global cap
+	typeMax   map[string]int64 // per-type caps by lowercase type
+
+	mu      sync.Mutex
+	fetched time.Time
+	total   int64
+	byType  map[string]int64
+}
+
+// newAdmissionControl creates the caps; nil when there are none
+func newAdmissionControl(maxQueued int, typeMax map[string]int) *admissionControl {
+	if maxQueued <= 0 && len(typeMax) == 0 {
+		return nil
+	}
+
+	a := &admissionControl{maxQueued: int64(maxQueued), typeMax: map[string]int64{}}
+	for taskType, limit := range typeMax {
+		a.typeMax[strings.ToLower(taskType)] = int64(limit)
+	}
+	return a
+}
+
+// admissionRejection describes the cap a request would break
+type admissionRejection struct {
+	taskType  string // empty for the global cap
+	maxQueued int64
+	queued    int64
+}
+
+// admit checks reqs against the caps and counts them as queued if they fit
+func (a *admissionControl) admit(ctx context.Context, store storage.Store, reqs []models.CreateTaskRequest, now time.Time) (*admissionRejection, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if now.Sub(a.fetched) >= admissionRefresh {
+		depths, err := store.GetQueueDepth(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		a.total, a.byType = 0, map[string]int64{}
+		for _, d := range depths {
+			a.total += d.Queued
+			a.byType[strings.ToLower(d.TaskType)] += d.Queued
+		}
+		a.fetched = now
+	}
+
+	added := map[string]int64{}
+	for _, req := range reqs {
+		added[strings.ToLower(req.Type)]++
+	}
+
+	if a.maxQueued > 0 && a.total+int64(len(reqs)) > a.maxQueued {
+		return &admissionRejection{maxQueued: a.maxQueued, queued: a.total}, nil
+	}
+	for taskType, n := range added {
+		if limit, ok := a.typeMax[taskType]; ok && a.byType[taskType]+n > limit {
+			return &admissionRejection{taskType: taskType, maxQueued: limit, queued: a.byType[taskType]}, nil
+		}
+	}
+
+	a.total += int64(len(reqs))
+	for taskType, n := range added {
+		a.byType[taskType] += n
+	}
+	return nil, nil
+}
+
+// admitted applies admission control to reqs and reports whether they may be created
+// Answers 429 with Retry-After once a queued-task cap would be exceeded
+func (h *Handler) admitted(c *gin.Context, reqs []models.CreateTaskRequest) bool {
+	if h.admission == nil {
+		return true
+	}
+
+	rejection, err := h.admission.admit(c.Request.Context(), h.store, reqs, time.Now())
+	if err != nil {
+		slog.Error("Failed to get queue depth for admission control", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create task",
+		})
+		return false
+	}
+	if rejection == nil {
+		return true
+	}
+
+	slog.Warn("Queue full, task creation rejected", "task_type", rejection.taskType,
+		"max_queued", rejection.maxQueued, "queued", rejection.queued)
+	retryAfter := int(admissionRetryAfter.Seconds())
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+
+	body := gin.H{
+		"error":               "Queue is full",
+		"max_queued":          rejection.maxQueued,
+		"queued_tasks":        rejection.queued,
+		"retry_after_seconds": retryAfter,
+	}
+	if rejection.taskType != "" {
+		body["task_type"] = rejection.taskType
+	}
+	c.JSON(http.StatusTooManyRequests, body)
+	return false
+}
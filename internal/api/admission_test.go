@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// depthStore reports fixed queue depths and accepts every task
+type depthStore struct {
+	createStore
+	depths []models.QueueDepth
+}
+
+func (s *depthStore) GetQueueDepth(ctx context.Context) ([]models.QueueDepth, error) {
+	return s.depths, nil
+}
+
+func TestAdmissionControl(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := &depthStore{depths: []models.QueueDepth{
+		{TaskType: "send_email", Queued: 1},
+		{TaskType: "run_query", Queued: 5},
+	}}
+	h := NewHandler(store, Config{MaxQueued: 8, TypeMaxQueued: map[string]int{"send_email": 2}})
+	r := gin.New()
+	r.POST("/api/tasks", h.CreateTask)
+	r.POST("/api/tasks/batch", h.CreateTasks)
+
+	serve := func(path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return w
+	}
+
+	email := `{"name":"n","type":"send_email","payload":{}}`
+	if w := serve("/api/tasks", email); w.Code != http.StatusCreated {
+		t.Fatalf("first send_email = %d %s, want 201", w.Code, w.Body)
+	}
+
+	w := serve("/api/tasks", email)
+	if w.Code != http.StatusTooManyRequests || !strings.Contains(w.Body.String(), `"task_type":"send_email"`) {
+		t.Fatalf("second send_email = %d %s, want 429 for the type cap", w.Code, w.Body)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Fatal("429 without Retry-After")
+	}
+
+	batch := `{"tasks":[{"name":"a","type":"run_query","payload":{}},{"name":"b","type":"run_query","payload":{}}]}`
+	if w := serve("/api/tasks/batch", batch); w.Code != http.StatusTooManyRequests || strings.Contains(w.Body.String(), "task_type") {
+		t.Fatalf("batch over the global cap = %d %s, want 429 for the global cap", w.Code, w.Body)
+	}
+
+	if w := serve("/api/tasks", `{"name":"n","type":"run_query","payload":{}}`); w.Code != http.StatusCreated {
+		t.Fatalf("run_query within the global cap = %d %s, want 201", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// ListAlertRules handles GET /alert-rules
+// Returns every alert rule with its firing state and last evaluated value
+// Signing secrets are never returned; signed tells whether a rule has one
+func (h *Handler) ListAlertRules(c *gin.Context) {
+	rules, err := h.store.ListAlertRules(c.Request.Context())
+	if err != nil {
+		slog.Error("Failed to list alert rules", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve alert rules",
+		})
+		return
+	}
+
+	for i := range rules {
+		rules[i].Signed = rules[i].SigningSecret != ""
+	}
+
+	c.JSON(http.StatusOK, models.AlertRulesResponse{
+		Rules: rules,
+	})
+}
+
+// SetAlertRule handles PUT /alert-rules/:name
+// Creates or replaces a rule such as "failed_tasks of send_email > 50 within 10 minutes"
+func (h *Handler) SetAlertRule(c *gin.Context) {
+	name := c.Param("name")
+	if err := models.ValidateAlertRuleName(name); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid alert rule name",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var req models.SetAlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Warn("Invalid request body", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	req.Normalize()
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid alert rule",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Windowed metrics count finished tasks, which only stores with analytics can do
+	if req.Metric.Windowed() {
+		if _, ok := h.analytics(c); !ok {
+			return
+		}
+	}
+
+	if err := h.store.SetAlertRule(c.Request.Context(), name, req); err != nil {
+		slog.Error("Failed to set alert rule", "rule", name, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to set alert rule",
+		})
+		return
+	}
+
+	slog.Info("Alert rule set", "rule", name, "metric", req.Metric, "task_type", req.TaskType,
+		"operator", req.Operator, "threshold", *req.Threshold, "window_seconds", req.WindowSeconds, "signed", req.SigningSecret != "")
+	c.JSON(http.StatusOK, gin.H{
+		"name":           name,
+		"metric":         req.Metric,
+		"task_type":      req.TaskType,
+		"operator":       req.Operator,
+		"threshold":      *req.Threshold,
+		"window_seconds": req.WindowSeconds,
+		"channel":        req.Channel,
+		"signed":         req.SigningSecret != "",
+	})
+}
+
+// DeleteAlertRule handles DELETE /alert-rules/:name
+// Removes an alert rule; a firing rule is removed without a resolved notification
+func (h *Handler) DeleteAlertRule(c *gin.Context) {
+	name := c.Param("name")
+
+	if err := h.store.DeleteAlertRule(c.Request.Context(), name); err != nil {
+		if errors.Is(err, storage.ErrAlertRuleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Alert rule not found",
+			})
+			return
+		}
+
+		slog.Error("Failed to delete alert rule", "rule", name, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete alert rule",
+		})
+		return
+	}
+
+	slog.Info("Alert rule removed", "rule", name)
+	c.Status(http.StatusNoContent)
+}
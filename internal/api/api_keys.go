@@ -0,0 +1,115 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAPIKey handles POST /keys
+// Generates a key with the requested scopes; the key is only returned in this response
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	var req models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Warn("Invalid request body", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	req.Normalize()
+	if err := req.Validate(time.Now()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	secret, prefix, hash, err := models.GenerateAPIKey()
+	if err != nil {
+		slog.Error("Failed to generate API key", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create API key",
+		})
+		return
+	}
+
+	key, err := h.store.CreateAPIKey(c.Request.Context(), req, prefix, hash)
+	if err != nil {
+		slog.Error("Failed to create API key", "name", req.Name, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create API key",
+		})
+		return
+	}
+
+	slog.Info("API key created", "key_id", key.ID, "name", key.Name, "prefix", key.Prefix, "scopes", key.Scopes, "by", keyName(c))
+	c.JSON(http.StatusCreated, models.CreateAPIKeyResponse{
+		APIKey: *key,
+		Key:    secret,
+	})
+}
+
+// ListAPIKeys handles GET /keys
+// Returns every key, including revoked ones, without the keys themselves
+func (h *Handler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.store.ListAPIKeys(c.Request.Context())
+	if err != nil {
+		slog.Error("Failed to list API keys", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve API keys",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIKeysResponse{
+		Keys: keys,
+	})
+}
+
+// RevokeAPIKey handles DELETE /keys/:id
+// Revoked keys stop authenticating immediately and stay listed for auditing
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid API key ID",
+		})
+		return
+	}
+
+	if err := h.store.RevokeAPIKey(c.Request.Context(), id); err != nil {
+		if errors.Is(err, storage.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "API key not found",
+			})
+			return
+		}
+
+		slog.Error("Failed to revoke API key", "key_id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to revoke API key",
+		})
+		return
+	}
+
+	slog.Info("API key revoked", "key_id", id, "by", keyName(c))
+	c.Status(http.StatusNoContent)
+}
+
+// keyName names the key that authenticated the request for audit logs, empty while authentication is disabled
+func keyName(c *gin.Context) string {
+	if key := authenticatedKey(c); key != nil {
+		return key.Name
+	}
+	return ""
+}
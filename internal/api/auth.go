@@ -0,0 +1,155 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// apiKeyContextKey is the gin context key holding the authenticated *models.APIKey
+const apiKeyContextKey = "api_key"
+
+// adminKeyName names the API_ADMIN_KEY credential in logs and responses
+const adminKeyName = "admin"
+
+// jwtClaims are the claims read from a JWT bearer token
+type jwtClaims struct {
+	Role models.Role `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// Authenticate returns middleware that requires "Authorization: Bearer <credential>",
+// an API key or, when a JWT secret is configured, an HS256 JWT with a role claim
+// Routes then require a scope with RequireScope; does nothing while authentication is disabled
+func (h *Handler) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !h.auth {
+			c.Next()
+			return
+		}
+
+		key, ok := h.lookupAPIKey(c)
+		if !ok {
+			return
+		}
+		c.Set(apiKeyContextKey, key)
+
+		c.Next()
+	}
+}
+
+// RequireScope returns middleware that requires the authenticated key's role or scopes to grant scope
+// Must run after Authenticate; does nothing while authentication is disabled
+// The admin scope also requires the client to be in the admin allowlist, with or without authentication
+func (h *Handler) RequireScope(scope models.APIKeyScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if scope == models.APIKeyScopeAdmin && !h.allowAdminNetwork(c) {
+			return
+		}
+		if !h.auth {
+			c.Next()
+			return
+		}
+
+		key, _ := c.Get(apiKeyContextKey)
+		apiKey, ok := key.(*models.APIKey)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing API key"})
+			return
+		}
+		if !requireScope(c, apiKey, scope) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// lookupAPIKey resolves the bearer key of a request, answering 401 when it is missing or not active
+func (h *Handler) lookupAPIKey(c *gin.Context) (*models.APIKey, bool) {
+	raw, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	raw = strings.TrimSpace(raw)
+	if !found || raw == "" {
+		c.Header("WWW-Authenticate", `Bearer realm="taskqueue"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing API key"})
+		return nil, false
+	}
+
+	if h.adminKey != "" && subtle.ConstantTimeCompare([]byte(raw), []byte(h.adminKey)) == 1 {
+		return &models.APIKey{Name: adminKeyName, Role: models.RoleAdmin}, true
+	}
+
+	if len(h.jwtSecret) > 0 && !strings.HasPrefix(raw, models.APIKeyPrefix) && strings.Count(raw, ".") == 2 {
+		return h.verifyJWT(c, raw)
+	}
+
+	key, err := h.store.GetAPIKeyByHash(c.Request.Context(), models.HashAPIKey(raw))
+	if err != nil && !errors.Is(err, storage.ErrAPIKeyNotFound) {
+		slog.Error("Failed to look up API key", "error", err)
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to verify API key"})
+		return nil, false
+	}
+	if err != nil || !key.Active(time.Now()) {
+		c.Header("WWW-Authenticate", `Bearer realm="taskqueue", error="invalid_token"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+		return nil, false
+	}
+
+	return key, true
+}
+
+// verifyJWT resolves a JWT bearer token to a key named after its subject with the token's role
+// Tokens must be signed with HS256, carry an expiry and, when configured, the expected issuer
+func (h *Handler) verifyJWT(c *gin.Context, raw string) (*models.APIKey, bool) {
+	options := []jwt.ParserOption{jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired()}
+	if h.jwtIssuer != "" {
+		options = append(options, jwt.WithIssuer(h.jwtIssuer))
+	}
+
+	var claims jwtClaims
+	_, err := jwt.ParseWithClaims(raw, &claims, func(*jwt.Token) (any, error) { return h.jwtSecret, nil }, options...)
+	if err == nil && !claims.Role.IsValid() {
+		err = errors.New("role claim must be viewer, operator or admin")
+	}
+	if err != nil {
+		slog.Warn("Rejected JWT", "error", err)
+		c.Header("WWW-Authenticate", `Bearer realm="taskqueue", error="invalid_token"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return nil, false
+	}
+
+	key := &models.APIKey{Name: claims.Subject, Role: claims.Role}
+	if claims.ExpiresAt != nil {
+		key.ExpiresAt = &claims.ExpiresAt.Time
+	}
+	return key, true
+}
+
+// requireScope answers 403 unless key grants scope
+func requireScope(c *gin.Context, key *models.APIKey, scope models.APIKeyScope) bool {
+	if key.Allows(scope) {
+		return true
+	}
+
+	slog.Warn("API key lacks scope", "key_id", key.ID, "key_name", key.Name, "scope", scope, "path", c.FullPath())
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"error":   "Insufficient scope",
+		"details": "requires the " + string(scope) + " scope",
+	})
+	return false
+}
+
+// authenticatedKey returns the key that authenticated the request, nil while authentication is disabled
+func authenticatedKey(c *gin.Context) *models.APIKey {
+	key, _ := c.Get(apiKeyContextKey)
+	apiKey, _ := key.(*models.APIKey)
+	return apiKey
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// keyStore serves API keys by hash; other Store methods are not used
+type keyStore struct {
+	storage.Store
+	keys map[string]*models.APIKey
+}
+
+func (s *keyStore) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	if key, ok := s.keys[hash]; ok {
+		return key, nil
+	}
+	return nil, storage.ErrAPIKeyNotFound
+}
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	revokedAt := time.Now().Add(-time.Minute)
+	store := &keyStore{keys: map[string]*models.APIKey{
+		models.HashAPIKey("tq_reader"):   {ID: 1, Name: "reader", Scopes: []models.APIKeyScope{models.APIKeyScopeRead}},
+		models.HashAPIKey("tq_operator"): {ID: 2, Name: "operator", Role: models.RoleOperator},
+		models.HashAPIKey("tq_revoked"):  {ID: 3, Name: "revoked", Role: models.RoleAdmin, RevokedAt: &revokedAt},
+	}}
+	h := &Handler{store: store, auth: true, adminKey: "bootstrap", jwtSecret: []byte("secret"), jwtIssuer: "sso"}
+
+	r := gin.New()
+	api := r.Group("/api", h.Authenticate())
+	api.GET("/tasks/1", h.RequireScope(models.APIKeyScopeRead), func(c *gin.Context) { c.Status(http.StatusOK) })
+	api.POST("/tasks", h.RequireScope(models.APIKeyScopeWrite), func(c *gin.Context) { c.Status(http.StatusCreated) })
+	api.POST("/queue/pause", h.RequireScope(models.APIKeyScopeAdmin), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	viewerJWT := signJWT(t, "secret", jwtClaims{Role: models.RoleViewer, RegisteredClaims: jwt.RegisteredClaims{
+		Subject: "dashboard", Issuer: "sso", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}})
+	adminJWT := signJWT(t, "secret", jwtClaims{Role: models.RoleAdmin, RegisteredClaims: jwt.RegisteredClaims{
+		Subject: "oncall", Issuer: "sso", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}})
+	expiredJWT := signJWT(t, "secret", jwtClaims{Role: models.RoleAdmin, RegisteredClaims: jwt.RegisteredClaims{
+		Subject: "oncall", Issuer: "sso", ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour)),
+	}})
+	forgedJWT := signJWT(t, "other", jwtClaims{Role: models.RoleAdmin, RegisteredClaims: jwt.RegisteredClaims{
+		Subject: "oncall", Issuer: "sso", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}})
+
+	tests := []struct {
+		method, path, key string
+		want              int
+	}{
+		{http.MethodGet, "/api/tasks/1", "", http.StatusUnauthorized},
+		{http.MethodGet, "/api/tasks/1", "tq_unknown", http.StatusUnauthorized},
+		{http.MethodGet, "/api/tasks/1", "tq_revoked", http.StatusUnauthorized},
+		{http.MethodGet, "/api/tasks/1", "tq_reader", http.StatusOK},
+		{http.MethodPost, "/api/tasks", "tq_reader", http.StatusForbidden},
+		{http.MethodPost, "/api/tasks", "tq_operator", http.StatusCreated},
+		{http.MethodPost, "/api/queue/pause", "tq_operator", http.StatusForbidden},
+		{http.MethodPost, "/api/queue/pause", "bootstrap", http.StatusOK},
+		{http.MethodGet, "/api/tasks/1", viewerJWT, http.StatusOK},
+		{http.MethodPost, "/api/tasks", viewerJWT, http.StatusForbidden},
+		{http.MethodPost, "/api/queue/pause", adminJWT, http.StatusOK},
+		{http.MethodGet, "/api/tasks/1", expiredJWT, http.StatusUnauthorized},
+		{http.MethodGet, "/api/tasks/1", forgedJWT, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.key != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.key)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Errorf("%s %s with credential %q = %d, want %d", tt.method, tt.path, tt.key, rec.Code, tt.want)
+		}
+	}
+}
+
+// signJWT signs claims with HS256, failing the test on error
+func signJWT(t *testing.T, secret string, claims jwtClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+	return token
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// streamPath is a long-lived request, so it does not hold a concurrency slot
+const streamPath = "/api/tasks/stream"
+
+// requestBudget bounds one class of requests server-wide by rate and by requests in flight
+type requestBudget struct {
+	name    string
+	limiter *keyLimiter   // a single bucket under name, nil = unlimited rate
+	slots   chan struct{} // one entry per request in flight, nil = unlimited concurrency
+}
+
+// newRequestBudget creates a budget; non-positive rates and concurrencies are unlimited
+func newRequestBudget(name string, rate float64, burst, concurrency int) *requestBudget {
+	b := &requestBudget{name: name, limiter: newKeyLimiter(rate, burst)}
+	if concurrency > 0 {
+		b.slots = make(chan struct{}, concurrency)
+	}
+	return b
+}
+
+// Backpressure returns middleware that bounds requests server-wide, with separate budgets
+// for reads (GET, HEAD and OPTIONS) and writes, so a polling storm cannot starve task creation
+// Answers 429 once a budget's rate is used up and 503 while all of its concurrency slots are taken,
+// both with Retry-After; does nothing for budgets without limits
+func (h *Handler) Backpressure() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		budget := h.writeBudget
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			budget = h.readBudget
+		}
+
+		if budget.limiter != nil {
+			if result := budget.limiter.take(budget.name, time.Now()); !result.allowed {
+				slog.Warn("Server rate limit exceeded", "budget", budget.name, "path", c.FullPath())
+				c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.retryAfter)))
+				c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+					"error":               "Server rate limit exceeded",
+					"budget":              budget.name,
+					"retry_after_seconds": ceilSeconds(result.retryAfter),
+				})
+				return
+			}
+		}
+
+		if budget.slots != nil && c.FullPath() != streamPath {
+			select {
+			case budget.slots <- struct{}{}:
+				defer func() { <-budget.slots }()
+			default:
+				slog.Warn("Server busy", "budget", budget.name, "in_flight", cap(budget.slots), "path", c.FullPath())
+				c.Header("Retry-After", "1")
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+					"error":  "Server busy",
+					"budget": budget.name,
+				})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
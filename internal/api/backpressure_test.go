@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBackpressure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := &Handler{
+		readBudget:  newRequestBudget("read", 1, 1, 0),
+		writeBudget: newRequestBudget("write", 0, 0, 1),
+	}
+
+	started, release := make(chan struct{}), make(chan struct{})
+	r := gin.New()
+	api := r.Group("/api", h.Backpressure())
+	api.GET("/stats", func(c *gin.Context) { c.Status(http.StatusOK) })
+	api.POST("/tasks", func(c *gin.Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusCreated)
+	})
+	api.POST("/tasks/batch", func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	// A write in flight takes the only write slot
+	done := make(chan int)
+	go func() { done <- serve(http.MethodPost, "/api/tasks").Code }()
+	<-started
+
+	if w := serve(http.MethodPost, "/api/tasks/batch"); w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" {
+		t.Fatalf("write while busy = %d %v, want 503 with Retry-After", w.Code, w.Header())
+	}
+
+	// Reads have a budget of their own
+	if w := serve(http.MethodGet, "/api/stats"); w.Code != http.StatusOK {
+		t.Fatalf("read while writes are busy = %d, want 200", w.Code)
+	}
+	if w := serve(http.MethodGet, "/api/stats"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("read past the rate = %d, want 429", w.Code)
+	}
+
+	close(release)
+	if code := <-done; code != http.StatusCreated {
+		t.Fatalf("write in flight = %d, want 201", code)
+	}
+	if w := serve(http.MethodPost, "/api/tasks/batch"); w.Code != http.StatusCreated {
+		t.Fatalf("write after the slot freed = %d, want 201", w.Code)
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// LimitBodySize returns middleware capping request bodies at the configured size
+// Bodies announced as larger are answered with 413 at once; chunked bodies fail when reading passes the limit
+func (h *Handler) LimitBodySize() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.maxBodyBytes <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > h.maxBodyBytes {
+			slog.Warn("Request body too large", "bytes", c.Request.ContentLength, "max_bytes", h.maxBodyBytes, "path", c.FullPath())
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error":     "Request body too large",
+				"details":   fmt.Sprintf("body is %d bytes, the limit is %d", c.Request.ContentLength, h.maxBodyBytes),
+				"max_bytes": h.maxBodyBytes,
+			})
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBodyBytes)
+		c.Next()
+	}
+}
+
+// bodyTooLarge answers 413 if binding failed because the body passed the limit and reports whether it did
+func bodyTooLarge(c *gin.Context, err error) bool {
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		return false
+	}
+
+	c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+		"error":     "Request body too large",
+		"details":   fmt.Sprintf("body is over the limit of %d bytes", tooLarge.Limit),
+		"max_bytes": tooLarge.Limit,
+	})
+	return true
+}
+
+// oversizedPayload returns the 413 response for a payload above the configured maximum, or nil
+func (h *Handler) oversizedPayload(req models.CreateTaskRequest) gin.H {
+	if h.maxPayloadBytes <= 0 || len(req.Payload) <= h.maxPayloadBytes {
+		return nil
+	}
+
+	return gin.H{
+		"error":     "Payload too large",
+		"details":   fmt.Sprintf("payload is %d bytes, the limit is %d", len(req.Payload), h.maxPayloadBytes),
+		"max_bytes": h.maxPayloadBytes,
+	}
+}
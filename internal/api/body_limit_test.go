@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// createStore accepts every task; other Store methods are not used
+type createStore struct {
+	storage.Store
+}
+
+func (s *createStore) GetMaintenance(ctx context.Context) (*models.MaintenanceMode, error) {
+	return &models.MaintenanceMode{}, nil
+}
+
+func (s *createStore) CreateTask(ctx context.Context, req models.CreateTaskRequest) (*models.Task, error) {
+	return &models.Task{Type: req.Type, Status: models.TaskStatusQueued}, nil
+}
+
+func (s *createStore) CreateTasks(ctx context.Context, reqs []models.CreateTaskRequest) ([]*models.Task, error) {
+	tasks := make([]*models.Task, len(reqs))
+	for i := range reqs {
+		tasks[i] = &models.Task{Type: reqs[i].Type, Status: models.TaskStatusQueued}
+	}
+	return tasks, nil
+}
+
+func TestSizeLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := NewHandler(&createStore{}, Config{MaxBodyBytes: 256, MaxPayloadBytes: 32})
+	r := gin.New()
+	r.Use(h.LimitBodySize())
+	r.POST("/api/tasks", h.CreateTask)
+	r.POST("/api/tasks/batch", h.CreateTasks)
+
+	serve := func(path, body string, chunked bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if chunked {
+			req.ContentLength = -1
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := serve("/api/tasks", `{"name":"n","type":"send_email","payload":{"to":"a@example.com"}}`, false); w.Code != http.StatusCreated {
+		t.Fatalf("small task = %d %s, want 201", w.Code, w.Body)
+	}
+
+	big := `{"name":"n","type":"send_email","payload":{"body":"` + strings.Repeat("x", 40) + `"}}`
+	if w := serve("/api/tasks", big, false); w.Code != http.StatusRequestEntityTooLarge || !strings.Contains(w.Body.String(), "Payload too large") {
+		t.Errorf("large payload = %d %s, want 413 Payload too large", w.Code, w.Body)
+	}
+	batch := `{"tasks":[{"name":"n","type":"send_email"},` + big + `]}`
+	if w := serve("/api/tasks/batch", batch, false); w.Code != http.StatusRequestEntityTooLarge || !strings.Contains(w.Body.String(), `"index":1`) {
+		t.Errorf("batch with a large payload = %d %s, want 413 for index 1", w.Code, w.Body)
+	}
+
+	huge := `{"name":"` + strings.Repeat("x", 300) + `","type":"send_email"}`
+	for _, chunked := range []bool{false, true} {
+		if w := serve("/api/tasks", huge, chunked); w.Code != http.StatusRequestEntityTooLarge || !strings.Contains(w.Body.String(), "Request body too large") {
+			t.Errorf("large body (chunked %v) = %d %s, want 413 Request body too large", chunked, w.Code, w.Body)
+		}
+	}
+}
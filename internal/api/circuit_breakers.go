@@ -0,0 +1,88 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// ListCircuitBreakers handles GET /circuit-breakers
+// Returns the breaker state of every task type that has failed
+func (h *Handler) ListCircuitBreakers(c *gin.Context) {
+	breakers, err := h.store.ListCircuitBreakers(c.Request.Context())
+	if err != nil {
+		slog.Error("Failed to list circuit breakers", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve circuit breakers",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.CircuitBreakersResponse{
+		Breakers: breakers,
+	})
+}
+
+// OpenCircuitBreaker handles POST /circuit-breakers/:type/open
+// Pauses claiming of the given task type for the requested cooldown
+func (h *Handler) OpenCircuitBreaker(c *gin.Context) {
+	taskType := c.Param("type")
+
+	var req models.OpenCircuitBreakerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Warn("Invalid request body", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	cooldown := time.Duration(req.CooldownSeconds) * time.Second
+	if err := h.store.OpenCircuitBreaker(c.Request.Context(), taskType, cooldown); err != nil {
+		slog.Error("Failed to open circuit breaker", "task_type", taskType, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to open circuit breaker",
+		})
+		return
+	}
+
+	slog.Info("Circuit breaker opened manually", "task_type", taskType, "cooldown", cooldown)
+	c.JSON(http.StatusOK, gin.H{
+		"task_type":        taskType,
+		"state":            "open",
+		"cooldown_seconds": req.CooldownSeconds,
+	})
+}
+
+// CloseCircuitBreaker handles POST /circuit-breakers/:type/close
+// Resumes claiming of the given task type immediately
+func (h *Handler) CloseCircuitBreaker(c *gin.Context) {
+	taskType := c.Param("type")
+
+	if err := h.store.CloseCircuitBreaker(c.Request.Context(), taskType); err != nil {
+		if errors.Is(err, storage.ErrCircuitBreakerNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Circuit breaker not found",
+			})
+			return
+		}
+
+		slog.Error("Failed to close circuit breaker", "task_type", taskType, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to close circuit breaker",
+		})
+		return
+	}
+
+	slog.Info("Circuit breaker closed manually", "task_type", taskType)
+	c.JSON(http.StatusOK, gin.H{
+		"task_type": taskType,
+		"state":     "closed",
+	})
+}
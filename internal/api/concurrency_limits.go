@@ -0,0 +1,82 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// ListConcurrencyLimits handles GET /concurrency-limits
+// Returns every per-type concurrency limit with the number of tasks currently running
+func (h *Handler) ListConcurrencyLimits(c *gin.Context) {
+	limits, err := h.store.ListConcurrencyLimits(c.Request.Context())
+	if err != nil {
+		slog.Error("Failed to list concurrency limits", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve concurrency limits",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ConcurrencyLimitsResponse{
+		Limits: limits,
+	})
+}
+
+// SetConcurrencyLimit handles PUT /concurrency-limits/:type
+// Caps the number of running tasks of the given type across all workers
+func (h *Handler) SetConcurrencyLimit(c *gin.Context) {
+	taskType := c.Param("type")
+
+	var req models.SetConcurrencyLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Warn("Invalid request body", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.store.SetConcurrencyLimit(c.Request.Context(), taskType, req.MaxRunning); err != nil {
+		slog.Error("Failed to set concurrency limit", "task_type", taskType, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to set concurrency limit",
+		})
+		return
+	}
+
+	slog.Info("Concurrency limit set", "task_type", taskType, "max_running", req.MaxRunning)
+	c.JSON(http.StatusOK, gin.H{
+		"task_type":   taskType,
+		"max_running": req.MaxRunning,
+	})
+}
+
+// DeleteConcurrencyLimit handles DELETE /concurrency-limits/:type
+// Removes the concurrency cap for the given task type
+func (h *Handler) DeleteConcurrencyLimit(c *gin.Context) {
+	taskType := c.Param("type")
+
+	if err := h.store.DeleteConcurrencyLimit(c.Request.Context(), taskType); err != nil {
+		if errors.Is(err, storage.ErrConcurrencyLimitNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Concurrency limit not found",
+			})
+			return
+		}
+
+		slog.Error("Failed to delete concurrency limit", "task_type", taskType, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete concurrency limit",
+		})
+		return
+	}
+
+	slog.Info("Concurrency limit removed", "task_type", taskType)
+	c.Status(http.StatusNoContent)
+}
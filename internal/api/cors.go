@@ -0,0 +1,112 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSConfig is the cross-origin policy for browser dashboards hosted on other origins
+type CORSConfig struct {
+	AllowedOrigins   []string      // exact origins such as https://ops.example.com, or "*" for any; empty = same-origin only
+	AllowedMethods   []string      // methods preflights may ask for
+	AllowedHeaders   []string      // request headers preflights may ask for
+	AllowCredentials bool          // let browsers send cookies and Authorization; never with "*"
+	MaxAge           time.Duration // how long browsers may cache a preflight answer
+}
+
+// corsExposedHeaders are the response headers scripts on other origins may read
+var corsExposedHeaders = strings.Join([]string{
+	RequestIDHeader, "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset",
+}, ", ")
+
+// corsPolicy is a prepared CORSConfig
+type corsPolicy struct {
+	anyOrigin   bool
+	origins     map[string]bool // lower-cased
+	methods     string
+	headers     string
+	credentials bool
+	maxAge      string
+}
+
+// newCORSPolicy prepares config; nil when no origin is allowed
+func newCORSPolicy(config CORSConfig) *corsPolicy {
+	if len(config.AllowedOrigins) == 0 {
+		return nil
+	}
+
+	p := &corsPolicy{
+		origins: map[string]bool{},
+		methods: strings.ToUpper(strings.Join(config.AllowedMethods, ", ")),
+		headers: strings.Join(config.AllowedHeaders, ", "),
+		maxAge:  strconv.Itoa(int(config.MaxAge.Seconds())),
+	}
+	for _, origin := range config.AllowedOrigins {
+		origin = strings.TrimSpace(origin)
+		if origin == "*" {
+			p.anyOrigin = true
+		}
+		p.origins[strings.ToLower(strings.TrimSuffix(origin, "/"))] = true
+	}
+	// Answering "*" to credentialed requests would let any site act as the signed-in user
+	p.credentials = config.AllowCredentials && !p.anyOrigin
+	return p
+}
+
+// allows reports whether requests from origin may be answered with CORS headers
+func (p *corsPolicy) allows(origin string) bool {
+	return p.anyOrigin || p.origins[strings.ToLower(origin)]
+}
+
+// CORS returns middleware applying the cross-origin policy to every route
+// Preflight requests from allowed origins are answered with 204 before authentication and budgets,
+// those from other origins with 403; other requests get no CORS headers unless their origin is allowed
+// Registered on the engine, so it also answers preflights for routes without an OPTIONS handler
+func (h *Handler) CORS() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if h.cors == nil || origin == "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		if !h.cors.allows(origin) {
+			if preflight {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error":  "Origin not allowed",
+					"origin": origin,
+				})
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if h.cors.anyOrigin {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if h.cors.credentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			c.Header("Access-Control-Allow-Methods", h.cors.methods)
+			c.Header("Access-Control-Allow-Headers", h.cors.headers)
+			c.Header("Access-Control-Max-Age", h.cors.maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Header("Access-Control-Expose-Headers", corsExposedHeaders)
+		c.Next()
+	}
+}
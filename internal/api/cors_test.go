@@ -0,0 +1,86 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := &Handler{cors: newCORSPolicy(CORSConfig{
+		AllowedOrigins:   []string{"https://ops.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})}
+
+	r := gin.New()
+	r.Use(h.CORS())
+	// Stands in for authentication, which preflights must not reach
+	api := r.Group("/api", func(c *gin.Context) { c.AbortWithStatus(http.StatusUnauthorized) })
+	api.POST("/tasks", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	r.GET("/readiness", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	serve := func(method, path, origin string, preflight bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if preflight {
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodOptions, "/api/tasks", "https://ops.example.com", true)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("preflight = %d, want 204", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://ops.example.com" {
+		t.Errorf("Allow-Origin = %q, want the origin", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST" {
+		t.Errorf("Allow-Methods = %q, want GET, POST", got)
+	}
+	if got := w.Header().Get("Access-Control-Max-Age"); got != "600" {
+		t.Errorf("Max-Age = %q, want 600", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("Allow-Credentials = %q, want true", got)
+	}
+
+	if w := serve(http.MethodOptions, "/api/tasks", "https://evil.example.com", true); w.Code != http.StatusForbidden {
+		t.Errorf("preflight from another origin = %d, want 403", w.Code)
+	}
+
+	w = serve(http.MethodGet, "/readiness", "https://OPS.example.com", false)
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "https://OPS.example.com" {
+		t.Errorf("request from an allowed origin = %d %v, want 200 with Allow-Origin", w.Code, w.Header())
+	}
+	if w.Header().Get("Access-Control-Expose-Headers") == "" {
+		t.Error("request from an allowed origin exposes no headers")
+	}
+
+	w = serve(http.MethodGet, "/readiness", "https://evil.example.com", false)
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("request from another origin = %d %v, want 200 without Allow-Origin", w.Code, w.Header())
+	}
+}
+
+func TestCORSAnyOriginIsNeverCredentialed(t *testing.T) {
+	p := newCORSPolicy(CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+	if !p.allows("https://anything.example.com") || p.credentials {
+		t.Errorf("policy = %+v, want any origin without credentials", p)
+	}
+	if newCORSPolicy(CORSConfig{}) != nil {
+		t.Error("policy without origins is not nil")
+	}
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// limiterSweepInterval is how often buckets that have refilled completely are dropped
+const limiterSweepInterval = time.Minute
+
+// tokenBucket holds the tokens of one caller as of updated
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// keyLimiter is an in-memory token bucket per caller, refilled at rate tokens per second up to burst
+// Every API server replica keeps its own buckets
+type keyLimiter struct {
+	rate  float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// newKeyLimiter creates a limiter; a non-positive rate returns nil, which allows everything
+func newKeyLimiter(rate float64, burst int) *keyLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = int(math.Ceil(rate))
+	}
+	return &keyLimiter{rate: rate, burst: float64(burst), buckets: map[string]*tokenBucket{}}
+}
+
+// limiterResult describes a take: whether it was allowed, the whole tokens left,
+// how long until a token is available and until the bucket is full again
+type limiterResult struct {
+	allowed    bool
+	remaining  int
+	retryAfter time.Duration
+	reset      time.Duration
+}
+
+// take removes a token from key's bucket at now, if it has one
+func (l *keyLimiter) take(key string, now time.Time) limiterResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, updated: now}
+		l.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(l.burst, b.tokens+elapsed*l.rate)
+		b.updated = now
+	}
+
+	result := limiterResult{allowed: b.tokens >= 1}
+	if result.allowed {
+		b.tokens--
+	} else {
+		result.retryAfter = l.wait(1 - b.tokens)
+	}
+	result.remaining = int(b.tokens)
+	result.reset = l.wait(l.burst - b.tokens)
+	return result
+}
+
+// wait is how long the bucket takes to gain tokens
+func (l *keyLimiter) wait(tokens float64) time.Duration {
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
+
+// sweep drops buckets that have refilled completely, since a new bucket starts full anyway
+func (l *keyLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < limiterSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// LimitTaskCreation returns middleware that rate limits task creation per API key,
+// or per client IP while authentication is disabled
+// Answers 429 with Retry-After once the caller's bucket is empty; every response carries
+// the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers
+// Must run after Authenticate; does nothing unless a creation rate is configured
+func (h *Handler) LimitTaskCreation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.createLimiter == nil {
+			c.Next()
+			return
+		}
+
+		caller := limiterCaller(c)
+		result := h.createLimiter.take(caller, time.Now())
+		setRateLimitHeaders(c, h.createLimiter, result)
+
+		if !result.allowed {
+			slog.Warn("Task creation rate limited", "caller", caller, "path", c.FullPath())
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.retryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":               "Rate limit exceeded",
+				"retry_after_seconds": ceilSeconds(result.retryAfter),
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// limiterCaller identifies the caller a bucket belongs to
+func limiterCaller(c *gin.Context) string {
+	key := authenticatedKey(c)
+	switch {
+	case key == nil:
+		return "ip:" + c.ClientIP()
+	case key.ID != 0:
+		return "key:" + strconv.FormatInt(key.ID, 10)
+	default:
+		// The admin key and JWTs have no id
+		return "name:" + key.Name
+	}
+}
+
+// setRateLimitHeaders sets the RateLimit-* headers of the IETF rate limit fields draft
+func setRateLimitHeaders(c *gin.Context, l *keyLimiter, result limiterResult) {
+	c.Header("RateLimit-Limit", strconv.Itoa(int(l.burst)))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.reset)))
+}
+
+// ceilSeconds rounds d up to whole seconds
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+func TestKeyLimiter(t *testing.T) {
+	l := newKeyLimiter(2, 3)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if r := l.take("a", now); !r.allowed || r.remaining != 2-i {
+			t.Fatalf("take %d = %+v, want allowed with %d remaining", i, r, 2-i)
+		}
+	}
+
+	r := l.take("a", now)
+	if r.allowed || r.retryAfter != 500*time.Millisecond || r.reset != 1500*time.Millisecond {
+		t.Fatalf("empty bucket take = %+v, want denied with retry after 500ms and reset after 1.5s", r)
+	}
+
+	if r := l.take("b", now); !r.allowed {
+		t.Fatalf("other caller denied: %+v", r)
+	}
+
+	if r := l.take("a", now.Add(500*time.Millisecond)); !r.allowed || r.remaining != 0 {
+		t.Fatalf("take after refill = %+v, want allowed with 0 remaining", r)
+	}
+
+	// Full buckets are dropped on the next sweep
+	l.take("c", now.Add(time.Hour))
+	if _, ok := l.buckets["b"]; ok {
+		t.Fatal("full bucket not swept")
+	}
+
+	if newKeyLimiter(0, 10) != nil {
+		t.Fatal("zero rate should disable the limiter")
+	}
+}
+
+func TestLimitTaskCreation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := &Handler{createLimiter: newKeyLimiter(1, 1)}
+	r := gin.New()
+	r.POST("/api/tasks", func(c *gin.Context) {
+		c.Set(apiKeyContextKey, &models.APIKey{ID: 7, Role: models.RoleOperator})
+	}, h.LimitTaskCreation(), func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	first := httptest.NewRecorder()
+	r.ServeHTTP(first, httptest.NewRequest(http.MethodPost, "/api/tasks", nil))
+	if first.Code != http.StatusCreated || first.Header().Get("RateLimit-Limit") != "1" || first.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("first request = %d %v", first.Code, first.Header())
+	}
+
+	second := httptest.NewRecorder()
+	r.ServeHTTP(second, httptest.NewRequest(http.MethodPost, "/api/tasks", nil))
+	if second.Code != http.StatusTooManyRequests || second.Header().Get("Retry-After") != "1" {
+		t.Fatalf("second request = %d %v", second.Code, second.Header())
+	}
+}
@@ -1,12 +1,9 @@
 package api
 
 import (
-	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,7 +14,6 @@ func (h *Handler) StreamTasks(c *gin.Context) {
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
 	c.Header("Connection", "keep-alive")
-	c.Header("Access-Control-Allow-Origin", "*")
 
 	ctx := c.Request.Context()
 	flusher, ok := c.Writer.(http.Flusher)
@@ -26,28 +22,15 @@ func (h *Handler) StreamTasks(c *gin.Context) {
 		return
 	}
 
-	// Send updates every 2 seconds
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
+	// Updates come from the shared refresher every statsStreamInterval
+	updates := h.statsStream.subscribe()
+	defer h.statsStream.unsubscribe(updates)
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
-			// Get latest stats
-			stats, err := h.store.GetStats(context.Background())
-			if err != nil {
-				slog.Error("Failed to get stats for SSE", "error", err)
-				continue
-			}
-
-			data, err := json.Marshal(stats)
-			if err != nil {
-				slog.Error("Failed to marshal stats", "error", err)
-				continue
-			}
-
+		case data := <-updates:
 			// SSE format: "event: stats\ndata: <json>\n\n"
 			if _, err := fmt.Fprintf(c.Writer, "event: stats\ndata: %s\n\n", string(data)); err != nil {
 				slog.Error("Failed to write SSE data", "error", err)
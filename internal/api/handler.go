@@ -1,48 +1,261 @@
 package api
 
 import (
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/payloadsig"
 	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
 	"github.com/gin-gonic/gin"
 )
 
 // Handler handles HTTP requests for the task queue API
 type Handler struct {
-	store storage.Store
+	store       storage.Store
+	stats       *statsCache
+	statsStream *statsBroadcaster
+
+	schemaVersion uint
+	auth          bool
+	adminKey      string
+	jwtSecret     []byte
+	jwtIssuer     string
+	createLimiter *keyLimiter
+	readBudget    *requestBudget
+	writeBudget   *requestBudget
+	cors          *corsPolicy    // nil = same-origin only
+	adminNetworks []netip.Prefix // empty = admin routes accept any client address
+
+	maxBodyBytes    int64
+	maxPayloadBytes int
+	payloadSigner   *payloadsig.Signer // nil = payloads are stored unsigned
+	admission       *admissionControl  // nil = no queued-task caps
+	schemas         *schemaRegistry
+
+	unknownTaskTypes string // one of the UnknownTaskTypes modes, empty = accept
+	taskTypes        *typeRegistry
+
+	auditPayloads  bool
+	integerTaskIDs bool
+
+	readOnlyMu sync.RWMutex
+	readOnly   models.ReadOnlyMode
+}
+
+// Config holds optional API behaviour settings
+type Config struct {
+	StatsCacheTTL time.Duration // How long GET /api/stats results are reused (0 = query every time)
+	SchemaVersion uint          // Migration version /readiness requires the database to be at (0 = not checked)
+
+	// Auth requires an API key on every /api and /tasks request; AdminKey is a static key with the admin role
+	// JWTs signed with JWTSecret (HS256) are accepted as well, their role claim picking the role
+	Auth      bool
+	AdminKey  string
+	JWTSecret string
+	JWTIssuer string // required iss claim, empty = any
+
+	// CreateRate limits POST /tasks and /tasks/batch to this many requests per second per API key (0 = unlimited)
+	// CreateBurst is how many a key may make at once (0 = one second's worth)
+	CreateRate  float64
+	CreateBurst int
+
+	// Server-wide request budgets: Read covers GET, HEAD and OPTIONS, Write everything else
+	// Rates are per second with bursts as above; concurrency caps requests in flight (0 = unlimited)
+	Read  BudgetConfig
+	Write BudgetConfig
+
+	// CORS lets browser dashboards on other origins call the API
+	CORS CORSConfig
+
+	// AdminNetworks restricts admin-scope routes to these client networks (empty = any), see ParseAllowlist
+	// Client addresses come from gin's ClientIP, so configure the engine's trusted proxies to honor X-Forwarded-For
+	AdminNetworks []netip.Prefix
+
+	// MaxBodyBytes caps every request body and MaxPayloadBytes the payload of every created task (0 = unlimited)
+	MaxBodyBytes    int64
+	MaxPayloadBytes int
+
+	// PayloadSigningKey signs every created task's payload, so workers can detect later modification (empty = unsigned)
+	PayloadSigningKey string
+
+	// MaxQueued and TypeMaxQueued cap the tasks queued in total and per task type; creating more answers 429 (0 = uncapped)
+	MaxQueued     int
+	TypeMaxQueued map[string]int
+
+	// UnknownTaskTypes is how tasks of types no worker handles are treated: UnknownTaskTypesAccept (default), Warn or Reject
+	UnknownTaskTypes string
+
+	// IntegerTaskIDs lets task routes look tasks up by their internal integer id, for clients from before public ids
+	// Off by default: sequential ids reveal how many tasks exist and invite enumeration
+	IntegerTaskIDs bool
+
+	// AuditPayloads records a payload_accessed history event naming the principal of every GET /tasks/:id
+	AuditPayloads bool
+
+	// ReadOnly starts the server rejecting every mutation with 503; PUT /api/read-only switches it at runtime
+	ReadOnly bool
+}
+
+// BudgetConfig limits one class of requests server-wide
+type BudgetConfig struct {
+	Rate        float64
+	Burst       int
+	Concurrency int
 }
 
 // NewHandler creates a new API handler
-func NewHandler(store storage.Store) *Handler {
-	return &Handler{
-		store: store,
+func NewHandler(store storage.Store, config Config) *Handler {
+	stats := newStatsCache(store, config.StatsCacheTTL)
+
+	h := &Handler{
+		store:       store,
+		stats:       stats,
+		statsStream: newStatsBroadcaster(stats),
+
+		schemaVersion: config.SchemaVersion,
+		auth:          config.Auth,
+		adminKey:      config.AdminKey,
+		jwtSecret:     []byte(config.JWTSecret),
+		jwtIssuer:     config.JWTIssuer,
+		createLimiter: newKeyLimiter(config.CreateRate, config.CreateBurst),
+		readBudget:    newRequestBudget("read", config.Read.Rate, config.Read.Burst, config.Read.Concurrency),
+		writeBudget:   newRequestBudget("write", config.Write.Rate, config.Write.Burst, config.Write.Concurrency),
+		cors:          newCORSPolicy(config.CORS),
+		adminNetworks: config.AdminNetworks,
+
+		maxBodyBytes:    config.MaxBodyBytes,
+		maxPayloadBytes: config.MaxPayloadBytes,
+		payloadSigner:   payloadsig.New(config.PayloadSigningKey),
+		admission:       newAdmissionControl(config.MaxQueued, config.TypeMaxQueued),
+		schemas:         &schemaRegistry{},
+		auditPayloads:   config.AuditPayloads,
+		integerTaskIDs:  config.IntegerTaskIDs,
+
+		unknownTaskTypes: config.UnknownTaskTypes,
+		taskTypes:        &typeRegistry{},
 	}
+	if config.ReadOnly {
+		h.setReadOnly(true, nil)
+	}
+	return h
 }
 
 // RegisterRoutes registers all API routes on the given router
 func (h *Handler) RegisterRoutes(r *gin.Engine) {
 	// Health check endpoint
 	r.GET("/health", h.Health)
+	r.GET("/readiness", h.Readiness)
 
 	// Dashboard UI
 	r.GET("/", h.ServeDashboard)
 	r.Static("/static", "./web/static")
 
 	// API endpoints
-	api := r.Group("/api")
+	// Viewers read, operators also create and release tasks, admins also control the queue and its configuration
+	viewer := h.RequireScope(models.APIKeyScopeRead)
+	operator := h.RequireScope(models.APIKeyScopeWrite)
+	admin := h.RequireScope(models.APIKeyScopeAdmin)
+	limitCreate := h.LimitTaskCreation()
+
+	api := r.Group("/api", h.Backpressure(), h.Authenticate())
 	{
 		// Task management endpoints
-		api.POST("/tasks", h.CreateTask)
-		api.GET("/tasks/:id", h.GetTask)
-		api.GET("/tasks/:id/history", h.GetTaskHistory)
+		api.POST("/tasks", operator, limitCreate, h.CreateTask)
+		api.POST("/tasks/batch", operator, limitCreate, h.CreateTasks)
+		api.GET("/tasks/:id", viewer, h.GetTask)
+		api.PATCH("/tasks/:id", admin, h.UpdateTask)
+		api.GET("/tasks/:id/history", viewer, h.GetTaskHistory)
+		api.GET("/tasks/:id/result", viewer, h.GetTaskResult)
+
+		// Poison task quarantine
+		api.GET("/tasks/quarantined", viewer, h.ListQuarantinedTasks)
+		api.POST("/tasks/:id/release", operator, h.ReleaseTask)
+
+		// Erasure of personal data from finished tasks
+		api.POST("/tasks/:id/redact", admin, h.RedactTask)
+		api.POST("/tasks/redact", admin, h.RedactTasks)
 
 		// Dashboard statistics endpoint
-		api.GET("/stats", h.GetStats)
+		api.GET("/stats", viewer, h.GetStats)
+		api.GET("/stats/types", viewer, h.GetTypeStats)
+		api.GET("/stats/queue", viewer, h.GetQueueDepth)
+		api.GET("/stats/latency", viewer, h.GetLatencyStats)
+		api.GET("/stats/timeseries", viewer, h.GetThroughput)
+		api.GET("/stats/success-rate", viewer, h.GetSuccessRates)
+		api.GET("/stats/retries", viewer, h.GetRetryDistribution)
+
+		// Autoscaling signal for worker deployments
+		api.GET("/scaling/backlog", viewer, h.GetScalingBacklog)
+
+		// Cluster-wide concurrency limits per task type
+		api.GET("/concurrency-limits", viewer, h.ListConcurrencyLimits)
+		api.PUT("/concurrency-limits/:type", admin, h.SetConcurrencyLimit)
+		api.DELETE("/concurrency-limits/:type", admin, h.DeleteConcurrencyLimit)
+
+		// Per-key rate limits per task type
+		api.GET("/rate-limits", viewer, h.ListRateLimits)
+		api.PUT("/rate-limits/:type", admin, h.SetRateLimit)
+		api.DELETE("/rate-limits/:type", admin, h.DeleteRateLimit)
+
+		// JSON Schemas payloads of a task type must match at creation
+		api.GET("/task-schemas", viewer, h.ListTaskSchemas)
+		api.PUT("/task-schemas/:type", admin, h.SetTaskSchema)
+		api.DELETE("/task-schemas/:type", admin, h.DeleteTaskSchema)
+
+		// Task types workers handle, checked at creation unless API_UNKNOWN_TASK_TYPES=accept
+		api.GET("/task-types", viewer, h.ListTaskTypes)
+		api.PUT("/task-types/:type", admin, h.RegisterTaskType)
+		api.DELETE("/task-types/:type", admin, h.DeregisterTaskType)
+
+		// Per-tenant quotas on queued and running tasks
+		api.GET("/tenant-quotas", viewer, h.ListTenantQuotas)
+		api.PUT("/tenant-quotas/:tenant", admin, h.SetTenantQuota)
+		api.DELETE("/tenant-quotas/:tenant", admin, h.DeleteTenantQuota)
+
+		// Circuit breakers per task type
+		api.GET("/circuit-breakers", viewer, h.ListCircuitBreakers)
+		api.POST("/circuit-breakers/:type/open", admin, h.OpenCircuitBreaker)
+		api.POST("/circuit-breakers/:type/close", admin, h.CloseCircuitBreaker)
+
+		// Built-in alerting on queue metrics
+		api.GET("/alert-rules", viewer, h.ListAlertRules)
+		api.PUT("/alert-rules/:name", admin, h.SetAlertRule)
+		api.DELETE("/alert-rules/:name", admin, h.DeleteAlertRule)
+
+		// Queue-wide maintenance mode
+		api.GET("/maintenance", viewer, h.GetMaintenance)
+		api.PUT("/maintenance", admin, h.SetMaintenance)
+
+		// Read-only mode of this server
+		api.GET("/read-only", viewer, h.GetReadOnly)
+		api.PUT("/read-only", admin, h.SetReadOnly)
+
+		// Pausing the whole queue or single task types
+		api.GET("/pauses", viewer, h.ListPauses)
+		api.GET("/pauses/history", viewer, h.ListPauseHistory)
+		api.POST("/queue/pause", admin, h.PauseQueue)
+		api.POST("/queue/resume", admin, h.ResumeQueue)
+		api.POST("/types/:type/pause", admin, h.PauseType)
+		api.POST("/types/:type/resume", admin, h.ResumeType)
+
+		// Registered workers
+		api.GET("/workers", viewer, h.ListWorkers)
+		api.GET("/workers/:id/stats", viewer, h.GetWorkerStats)
+
+		// Runtime worker settings overrides
+		api.GET("/worker-settings", viewer, h.ListWorkerSettings)
+		api.PUT("/worker-settings/:worker_id", admin, h.SetWorkerSettings)
+		api.DELETE("/worker-settings/:worker_id", admin, h.DeleteWorkerSettings)
 
 		// Server-Sent Events stream for real-time updates
-		api.GET("/tasks/stream", h.StreamTasks)
-	}
-}
+		api.GET("/tasks/stream", viewer, h.StreamTasks)
 
-// Health checks if the service is healthy
-func (h *Handler) Health(c *gin.Context) {
-	c.JSON(200, gin.H{"status": "healthy"})
+		// API key management
+		keys := api.Group("/keys", admin)
+		keys.GET("", h.ListAPIKeys)
+		keys.POST("", h.CreateAPIKey)
+		keys.DELETE("/:id", h.RevokeAPIKey)
+	}
 }
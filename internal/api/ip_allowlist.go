@@ -0,0 +1,63 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ParseAllowlist parses CIDR ranges and bare addresses, e.g. "10.0.0.0/8" or "192.0.2.7"
+func ParseAllowlist(entries []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid address %q: %w", entry, err)
+			}
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR range %q: %w", entry, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// allowAdminNetwork checks the client address against the admin allowlist, answering 403 outside it
+// The address is gin's ClientIP, so X-Forwarded-For only counts when the request came through a trusted proxy
+func (h *Handler) allowAdminNetwork(c *gin.Context) bool {
+	if len(h.adminNetworks) == 0 {
+		return true
+	}
+
+	if addr, err := netip.ParseAddr(c.ClientIP()); err == nil {
+		addr = addr.Unmap()
+		for _, prefix := range h.adminNetworks {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+	}
+
+	slog.Warn("Admin request from a network outside the allowlist", "client_ip", c.ClientIP(), "path", c.FullPath())
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"error":   "Client address not allowed",
+		"details": "admin routes only accept requests from allowed networks",
+	})
+	return false
+}
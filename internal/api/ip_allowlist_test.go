@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseAllowlist(t *testing.T) {
+	prefixes, err := ParseAllowlist([]string{"10.0.0.0/8", " 192.0.2.7 ", "2001:db8::/32", ""})
+	if err != nil {
+		t.Fatalf("ParseAllowlist() error = %v", err)
+	}
+	if len(prefixes) != 3 || prefixes[1].String() != "192.0.2.7/32" {
+		t.Fatalf("ParseAllowlist() = %v", prefixes)
+	}
+
+	for _, entry := range []string{"10.0.0.0/33", "not-an-ip"} {
+		if _, err := ParseAllowlist([]string{entry}); err == nil {
+			t.Fatalf("ParseAllowlist(%q) expected error", entry)
+		}
+	}
+}
+
+func TestAdminAllowlist(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	networks, err := ParseAllowlist([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewHandler(&createStore{}, Config{AdminNetworks: networks})
+
+	r := gin.New()
+	if err := r.SetTrustedProxies([]string{"192.0.2.1"}); err != nil {
+		t.Fatal(err)
+	}
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/api/stats", h.RequireScope(models.APIKeyScopeRead), ok)
+	r.POST("/api/queue/pause", h.RequireScope(models.APIKeyScopeAdmin), ok)
+
+	tests := []struct {
+		name, method, path, remote, forwarded string
+		want                                  int
+	}{
+		{"inside the allowlist", http.MethodPost, "/api/queue/pause", "10.1.2.3:4000", "", http.StatusOK},
+		{"outside the allowlist", http.MethodPost, "/api/queue/pause", "198.51.100.9:4000", "", http.StatusForbidden},
+		{"through a trusted proxy", http.MethodPost, "/api/queue/pause", "192.0.2.1:4000", "10.9.9.9", http.StatusOK},
+		{"spoofed without a trusted proxy", http.MethodPost, "/api/queue/pause", "198.51.100.9:4000", "10.9.9.9", http.StatusForbidden},
+		{"non-admin route", http.MethodGet, "/api/stats", "198.51.100.9:4000", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.RemoteAddr = tt.remote
+		if tt.forwarded != "" {
+			req.Header.Set("X-Forwarded-For", tt.forwarded)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
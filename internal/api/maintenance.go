@@ -0,0 +1,98 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// GetMaintenance handles GET /maintenance
+// Returns the queue-wide maintenance mode
+func (h *Handler) GetMaintenance(c *gin.Context) {
+	mode, err := h.store.GetMaintenance(c.Request.Context())
+	if err != nil {
+		slog.Error("Failed to get maintenance mode", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve maintenance mode",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, mode)
+}
+
+// SetMaintenance handles PUT /maintenance
+// Enables or disables maintenance mode; stop_claims also stops workers from claiming
+func (h *Handler) SetMaintenance(c *gin.Context) {
+	var req models.SetMaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Warn("Invalid request body", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	mode, err := h.store.SetMaintenance(c.Request.Context(), req)
+	if err != nil {
+		slog.Error("Failed to set maintenance mode", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to set maintenance mode",
+		})
+		return
+	}
+
+	slog.Info("Maintenance mode changed", "enabled", mode.Enabled, "stop_claims", mode.StopClaims)
+	c.JSON(http.StatusOK, mode)
+}
+
+// Health handles GET /health
+// Reports every storage component with latency, pool use and migration version, with 503 when any is degraded
+func (h *Handler) Health(c *gin.Context) {
+	resp := models.NewHealthResponse(storage.CheckHealth(c.Request.Context(), h.store))
+	status := http.StatusOK
+	if resp.Status != models.HealthHealthy {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, resp)
+}
+
+// Readiness handles GET /readiness
+// Not ready when the database is unreachable or its schema is not at the migration version this build embeds,
+// so rollouts only send traffic to servers matching the schema; maintenance mode is reported but keeps reads serving
+func (h *Handler) Readiness(c *gin.Context) {
+	mode, err := h.store.GetMaintenance(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "error": "database unavailable"})
+		return
+	}
+
+	if v, ok := h.store.(storage.SchemaVersioner); ok && h.schemaVersion > 0 {
+		applied, err := v.SchemaVersion(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "error": "schema version unavailable"})
+			return
+		}
+		if applied.Dirty || applied.Version != h.schemaVersion {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status":                  "not ready",
+				"error":                   "schema version mismatch",
+				"schema_version":          applied.Version,
+				"schema_dirty":            applied.Dirty,
+				"expected_schema_version": h.schemaVersion,
+			})
+			return
+		}
+	}
+
+	if mode.Enabled {
+		c.JSON(http.StatusOK, gin.H{"status": "maintenance", "maintenance": mode})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ready"})
+}
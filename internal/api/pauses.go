@@ -0,0 +1,161 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultPauseHistoryLimit is the number of audit entries returned when no limit is given
+const defaultPauseHistoryLimit = 100
+
+// PauseQueue handles POST /queue/pause
+// Stops workers from claiming any task until the queue is resumed
+func (h *Handler) PauseQueue(c *gin.Context) {
+	h.pause(c, models.PauseScopeQueue)
+}
+
+// ResumeQueue handles POST /queue/resume
+// Lets workers claim tasks again; per-type pauses stay in place
+func (h *Handler) ResumeQueue(c *gin.Context) {
+	h.resume(c, models.PauseScopeQueue)
+}
+
+// PauseType handles POST /types/:type/pause
+// Stops workers from claiming tasks of the given type until it is resumed
+func (h *Handler) PauseType(c *gin.Context) {
+	h.pause(c, c.Param("type"))
+}
+
+// ResumeType handles POST /types/:type/resume
+// Lets workers claim tasks of the given type again
+func (h *Handler) ResumeType(c *gin.Context) {
+	h.resume(c, c.Param("type"))
+}
+
+// ListPauses handles GET /pauses
+// Returns the active pauses
+func (h *Handler) ListPauses(c *gin.Context) {
+	pauses, err := h.store.ListPauses(c.Request.Context())
+	if err != nil {
+		slog.Error("Failed to list pauses", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve pauses",
+		})
+		return
+	}
+
+	response := models.PausesResponse{Pauses: pauses}
+	for _, p := range pauses {
+		if p.Scope == models.PauseScopeQueue {
+			response.QueuePaused = true
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListPauseHistory handles GET /pauses/history
+// Returns the pause and resume audit trail, newest first
+func (h *Handler) ListPauseHistory(c *gin.Context) {
+	limit := defaultPauseHistoryLimit
+	if limitParam := c.Query("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid limit",
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	events, err := h.store.ListPauseHistory(c.Request.Context(), limit)
+	if err != nil {
+		slog.Error("Failed to list pause history", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve pause history",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PauseHistoryResponse{
+		Events: events,
+	})
+}
+
+// pause records a pause of scope with the optional reason from the request body
+func (h *Handler) pause(c *gin.Context, scope string) {
+	req, ok := bindPauseRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.store.Pause(c.Request.Context(), scope, req.Reason); err != nil {
+		slog.Error("Failed to pause", "scope", scope, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to pause",
+		})
+		return
+	}
+
+	slog.Info("Paused", "scope", scope)
+	c.JSON(http.StatusOK, gin.H{
+		"scope":  scope,
+		"paused": true,
+	})
+}
+
+// resume lifts the pause of scope
+func (h *Handler) resume(c *gin.Context, scope string) {
+	req, ok := bindPauseRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.store.Resume(c.Request.Context(), scope, req.Reason); err != nil {
+		if errors.Is(err, storage.ErrNotPaused) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Not paused",
+			})
+			return
+		}
+
+		slog.Error("Failed to resume", "scope", scope, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to resume",
+		})
+		return
+	}
+
+	slog.Info("Resumed", "scope", scope)
+	c.JSON(http.StatusOK, gin.H{
+		"scope":  scope,
+		"paused": false,
+	})
+}
+
+// bindPauseRequest parses the optional pause request body
+// Writes a 400 response and returns false if a body was sent but is invalid
+func bindPauseRequest(c *gin.Context) (models.PauseRequest, bool) {
+	var req models.PauseRequest
+	if c.Request.ContentLength == 0 {
+		return req, true
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Warn("Invalid request body", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return req, false
+	}
+
+	return req, true
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// auditPayloadAccess records who is about to read task's payload and result, when payload auditing is enabled
+// Fails closed: without an audit entry the payload is not returned, answering 500 instead
+func (h *Handler) auditPayloadAccess(c *gin.Context, task *models.Task) bool {
+	if !h.auditPayloads {
+		return true
+	}
+
+	actor := payloadActor(c)
+	err := h.store.InsertHistory(c.Request.Context(), models.TaskHistory{
+		TaskID:    task.ID,
+		Status:    task.Status,
+		EventType: models.EventPayloadAccessed,
+		Actor:     &actor,
+	})
+	if err != nil {
+		slog.Error("Failed to record payload access", "task_id", task.ID, "actor", actor, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve task",
+		})
+		return false
+	}
+
+	slog.Info("Task payload accessed", "task_id", task.ID, "public_id", task.PublicID, "actor", actor, "request_id", requestID(c))
+	return true
+}
+
+// payloadActor names the principal of a request in the audit trail, its client address while authentication is off
+func payloadActor(c *gin.Context) string {
+	if name := keyName(c); name != "" {
+		return name
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// auditStore serves one task and keeps the history entries inserted for it
+type auditStore struct {
+	createStore
+	task    *models.Task
+	history []models.TaskHistory
+}
+
+func (s *auditStore) GetTaskByPublicID(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	return s.task, nil
+}
+
+func (s *auditStore) InsertHistory(ctx context.Context, history models.TaskHistory) error {
+	s.history = append(s.history, history)
+	return nil
+}
+
+func TestPayloadAccessAudit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, enabled := range []bool{false, true} {
+		store := &auditStore{task: &models.Task{ID: 7, PublicID: uuid.New(), Status: models.TaskStatusSucceeded}}
+		h := NewHandler(store, Config{AuditPayloads: enabled})
+		r := gin.New()
+		r.GET("/api/tasks/:id", h.GetTask)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks/"+store.task.PublicID.String(), nil)
+		req.RemoteAddr = "192.0.2.7:4000"
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET task = %d %s, want 200", w.Code, w.Body)
+		}
+
+		if !enabled {
+			if len(store.history) != 0 {
+				t.Fatalf("auditing disabled recorded %+v", store.history)
+			}
+			continue
+		}
+		if len(store.history) != 1 {
+			t.Fatalf("recorded %d history entries, want 1", len(store.history))
+		}
+		entry := store.history[0]
+		if entry.EventType != models.EventPayloadAccessed || entry.TaskID != 7 || entry.Actor == nil || *entry.Actor != "ip:192.0.2.7" {
+			t.Fatalf("history entry = %+v, want payload_accessed by ip:192.0.2.7", entry)
+		}
+	}
+}
+
+func (s *auditStore) GetTask(ctx context.Context, id int64) (*models.Task, error) {
+	return s.task, nil
+}
+
+func TestTaskIDs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, integerIDs := range []bool{false, true} {
+		store := &auditStore{task: &models.Task{ID: 7, PublicID: uuid.New(), Status: models.TaskStatusSucceeded}}
+		h := NewHandler(store, Config{IntegerTaskIDs: integerIDs})
+		r := gin.New()
+		r.GET("/api/tasks/:id", h.GetTask)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/tasks/"+store.task.PublicID.String(), nil))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"id":"`+store.task.PublicID.String()+`"`) {
+			t.Fatalf("GET by public id = %d %s, want 200 with the public id", w.Code, w.Body)
+		}
+
+		want := http.StatusBadRequest
+		if integerIDs {
+			want = http.StatusOK
+		}
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/tasks/7", nil))
+		if w.Code != want {
+			t.Errorf("GET by integer id with IntegerTaskIDs=%v = %d, want %d", integerIDs, w.Code, want)
+		}
+	}
+
+	encoded, err := json.Marshal(models.Task{ID: 7, PublicID: uuid.New()})
+	if err != nil || strings.Contains(string(encoded), `"id":7`) {
+		t.Errorf("encoded task = %s, want no integer id", encoded)
+	}
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultQuarantineListLimit is the number of quarantined tasks returned when no limit is given
+const defaultQuarantineListLimit = 100
+
+// ListQuarantinedTasks handles GET /tasks/quarantined
+// Returns quarantined tasks, most recently quarantined first
+func (h *Handler) ListQuarantinedTasks(c *gin.Context) {
+	limit := defaultQuarantineListLimit
+	if limitParam := c.Query("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid limit",
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	tasks, err := h.store.ListQuarantinedTasks(c.Request.Context(), limit)
+	if err != nil {
+		slog.Error("Failed to list quarantined tasks", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve quarantined tasks",
+		})
+		return
+	}
+
+	response := models.QuarantinedTasksResponse{
+		Tasks: make([]models.TaskResponse, 0, len(tasks)),
+	}
+	for _, task := range tasks {
+		response.Tasks = append(response.Tasks, task.ToTaskResponse())
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ReleaseTask handles POST /tasks/:id/release
+// Returns a quarantined task to the queue; an If-Match version rejects stale releases with 409
+func (h *Handler) ReleaseTask(c *gin.Context) {
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	task, ok := h.taskFromParam(c)
+	if !ok {
+		return
+	}
+	taskID := task.ID
+
+	if err := h.store.ReleaseTask(c.Request.Context(), taskID, version); err != nil {
+		if errors.Is(err, storage.ErrTaskNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Task not found",
+			})
+			return
+		}
+		if errors.Is(err, storage.ErrNotQuarantined) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Task is not quarantined",
+			})
+			return
+		}
+		if errors.Is(err, storage.ErrVersionConflict) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Task was modified since the given version",
+			})
+			return
+		}
+
+		slog.Error("Failed to release task", "task_id", taskID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to release task",
+		})
+		return
+	}
+
+	slog.Info("Task released from quarantine", "task_id", taskID)
+	c.JSON(http.StatusOK, gin.H{
+		"id":     task.PublicID,
+		"status": models.TaskStatusQueued,
+	})
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// ListRateLimits handles GET /rate-limits
+// Returns every per-type rate limit
+func (h *Handler) ListRateLimits(c *gin.Context) {
+	limits, err := h.store.ListRateLimits(c.Request.Context())
+	if err != nil {
+		slog.Error("Failed to list rate limits", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve rate limits",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.RateLimitsResponse{
+		Limits: limits,
+	})
+}
+
+// SetRateLimit handles PUT /rate-limits/:type
+// Limits how many tasks of the given type sharing a payload-derived key may start per window
+func (h *Handler) SetRateLimit(c *gin.Context) {
+	taskType := c.Param("type")
+
+	var req models.SetRateLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Warn("Invalid request body", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.store.SetRateLimit(c.Request.Context(), taskType, req); err != nil {
+		slog.Error("Failed to set rate limit", "task_type", taskType, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to set rate limit",
+		})
+		return
+	}
+
+	slog.Info("Rate limit set", "task_type", taskType, "key_field", req.KeyField,
+		"max_per_window", req.MaxPerWindow, "window_seconds", req.WindowSeconds)
+	c.JSON(http.StatusOK, gin.H{
+		"task_type":      taskType,
+		"key_field":      req.KeyField,
+		"max_per_window": req.MaxPerWindow,
+		"window_seconds": req.WindowSeconds,
+	})
+}
+
+// DeleteRateLimit handles DELETE /rate-limits/:type
+// Removes the rate limit for the given task type
+func (h *Handler) DeleteRateLimit(c *gin.Context) {
+	taskType := c.Param("type")
+
+	if err := h.store.DeleteRateLimit(c.Request.Context(), taskType); err != nil {
+		if errors.Is(err, storage.ErrRateLimitNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Rate limit not found",
+			})
+			return
+		}
+
+		slog.Error("Failed to delete rate limit", "task_type", taskType, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete rate limit",
+		})
+		return
+	}
+
+	slog.Info("Rate limit removed", "task_type", taskType)
+	c.Status(http.StatusNoContent)
+}
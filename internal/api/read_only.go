@@ -0,0 +1,92 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// readOnlyExempt lists the routes that change this server's own state, so they keep working while read-only
+var readOnlyExempt = map[string]bool{
+	"/api/read-only": true,
+	"/api/log-level": true,
+}
+
+// RejectWritesWhenReadOnly answers 503 to every mutation while the server is read-only
+// Unlike maintenance mode the switch lives in memory, so it works while the database is migrating or failing over
+// GET, HEAD and OPTIONS requests always pass
+func (h *Handler) RejectWritesWhenReadOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if readOnlyExempt[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		mode := h.getReadOnly()
+		if !mode.Enabled {
+			c.Next()
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error":  "The API is read-only for maintenance; only reads are served",
+			"reason": mode.Reason,
+		})
+	}
+}
+
+// getReadOnly returns a copy of the current read-only mode
+func (h *Handler) getReadOnly() models.ReadOnlyMode {
+	h.readOnlyMu.RLock()
+	defer h.readOnlyMu.RUnlock()
+	return h.readOnly
+}
+
+// setReadOnly switches read-only mode and returns the new one
+func (h *Handler) setReadOnly(enabled bool, reason *string) models.ReadOnlyMode {
+	h.readOnlyMu.Lock()
+	defer h.readOnlyMu.Unlock()
+
+	if !enabled {
+		reason = nil
+	}
+	h.readOnly = models.ReadOnlyMode{Enabled: enabled, Reason: reason, UpdatedAt: time.Now()}
+	return h.readOnly
+}
+
+// GetReadOnly handles GET /read-only
+// Returns this server's read-only mode
+func (h *Handler) GetReadOnly(c *gin.Context) {
+	c.JSON(http.StatusOK, h.getReadOnly())
+}
+
+// SetReadOnly handles PUT /read-only
+// Enables or disables read-only mode on this server; every other server keeps its own mode
+func (h *Handler) SetReadOnly(c *gin.Context) {
+	var req models.SetReadOnlyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Warn("Invalid request body", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+	if req.Reason != nil {
+		reason := strings.TrimSpace(*req.Reason)
+		req.Reason = &reason
+	}
+
+	mode := h.setReadOnly(*req.Enabled, req.Reason)
+	slog.Warn("Read-only mode changed", "enabled", mode.Enabled)
+	c.JSON(http.StatusOK, mode)
+}
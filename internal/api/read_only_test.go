@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestReadOnlyMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := NewHandler(&createStore{}, Config{ReadOnly: true})
+	r := gin.New()
+	r.Use(h.RejectWritesWhenReadOnly())
+	r.POST("/api/tasks", h.CreateTask)
+	r.GET("/api/read-only", h.GetReadOnly)
+	r.PUT("/api/read-only", h.SetReadOnly)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	task := `{"name":"n","type":"send_email","payload":{}}`
+	if w := serve(http.MethodPost, "/api/tasks", task); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("create while read-only = %d %s, want 503", w.Code, w.Body)
+	}
+	if w := serve(http.MethodGet, "/api/read-only", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"enabled":true`) {
+		t.Fatalf("GET /api/read-only = %d %s, want enabled", w.Code, w.Body)
+	}
+
+	if w := serve(http.MethodPut, "/api/read-only", `{"enabled":false}`); w.Code != http.StatusOK {
+		t.Fatalf("disable read-only = %d %s, want 200", w.Code, w.Body)
+	}
+	if w := serve(http.MethodPost, "/api/tasks", task); w.Code != http.StatusCreated {
+		t.Fatalf("create after read-only = %d %s, want 201", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// RedactTask handles POST /tasks/:id/redact
+// Irreversibly scrubs the payload and error messages of a finished task, e.g. for a data-subject deletion request
+// An If-Match version rejects stale redactions with 409
+func (h *Handler) RedactTask(c *gin.Context) {
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	task, ok := h.taskFromParam(c)
+	if !ok {
+		return
+	}
+
+	redacted, err := h.store.RedactTask(c.Request.Context(), task.ID, version)
+	if err != nil {
+		if errors.Is(err, storage.ErrTaskNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Task not found",
+			})
+			return
+		}
+		if errors.Is(err, storage.ErrVersionConflict) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Task was modified since the given version",
+			})
+			return
+		}
+		if errors.Is(err, storage.ErrTaskNotFinished) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Task is not finished",
+				"details": "only succeeded and failed tasks can be redacted",
+			})
+			return
+		}
+
+		slog.Error("Failed to redact task", "task_id", task.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to redact task",
+		})
+		return
+	}
+
+	slog.Info("Task redacted", "task_id", task.ID, "key_name", keyName(c))
+	setETag(c, redacted)
+	c.JSON(http.StatusOK, redacted.ToTaskResponse())
+}
+
+// RedactTasks handles POST /tasks/redact
+// Redacts every finished task of a tenant or with the given metadata entries
+func (h *Handler) RedactTasks(c *gin.Context) {
+	var req models.RedactTasksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Warn("Invalid request body", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid redaction",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	redacted, err := h.store.RedactTasks(c.Request.Context(), req)
+	if err != nil {
+		slog.Error("Failed to redact tasks", "tenant", req.Tenant, "redacted", redacted, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to redact tasks",
+		})
+		return
+	}
+
+	slog.Info("Tasks redacted", "tenant", req.Tenant, "metadata_keys", len(req.Metadata), "redacted", redacted, "key_name", keyName(c))
+	c.JSON(http.StatusOK, models.RedactTasksResponse{
+		Redacted: redacted,
+	})
+}
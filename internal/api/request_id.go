@@ -0,0 +1,73 @@
+package api
+
+import (
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the request ID, accepted from clients and echoed on every response
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the gin context key holding the request ID
+const requestIDKey = "request_id"
+
+// maxRequestIDLen bounds client-supplied request IDs
+const maxRequestIDLen = 128
+
+// RequestID returns middleware that assigns every request an ID
+// A valid X-Request-ID from the client is kept, otherwise a UUID is generated
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// requestID returns the ID assigned by RequestID, empty when the middleware is not installed
+func requestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// validRequestID accepts short printable ASCII IDs, so they are safe to log and store
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// withRequestMetadata adds the request ID and W3C trace context headers to a task's metadata
+// Values given explicitly in metadata win over the request's own
+func withRequestMetadata(c *gin.Context, metadata map[string]string) map[string]string {
+	values := map[string]string{
+		models.MetadataRequestID:   requestID(c),
+		models.MetadataTraceParent: c.GetHeader("traceparent"),
+		models.MetadataTraceState:  c.GetHeader("tracestate"),
+	}
+
+	for key, value := range values {
+		if value == "" || len(value) > models.MaxMetadataValueLen {
+			continue
+		}
+		if _, ok := metadata[key]; ok {
+			continue
+		}
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		metadata[key] = value
+	}
+	return metadata
+}
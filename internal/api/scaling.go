@@ -0,0 +1,35 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetScalingBacklog handles GET /scaling/backlog
+// Returns the claimable backlog for autoscalers such as the KEDA metrics-api scaler,
+// summed over the task types given as ?type=send_email&type=run_query (or ?type=send_email,run_query), or over all types
+func (h *Handler) GetScalingBacklog(c *gin.Context) {
+	var taskTypes []string
+	for _, param := range c.QueryArray("type") {
+		for _, t := range strings.Split(param, ",") {
+			if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+				taskTypes = append(taskTypes, t)
+			}
+		}
+	}
+
+	depths, err := h.store.GetQueueDepth(c.Request.Context())
+	if err != nil {
+		slog.Error("Failed to get queue depth", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve backlog",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewScalingBacklogResponse(depths, taskTypes))
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+)
+
+// statsStreamInterval is how often stats are pushed to SSE clients
+const statsStreamInterval = 2 * time.Second
+
+// statsCache serves GetStats results for up to ttl
+// Concurrent callers during a refresh wait for that single query instead of issuing their own
+type statsCache struct {
+	store storage.Store
+	ttl   time.Duration
+
+	mu        sync.Mutex
+	stats     *models.TaskStatsResponse
+	fetchedAt time.Time
+}
+
+// newStatsCache creates a cache; a zero ttl queries the store on every call
+func newStatsCache(store storage.Store, ttl time.Duration) *statsCache {
+	return &statsCache{store: store, ttl: ttl}
+}
+
+// get returns cached stats, refreshing them once they are older than the ttl
+func (c *statsCache) get(ctx context.Context) (*models.TaskStatsResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stats != nil && time.Since(c.fetchedAt) < c.ttl {
+		return c.stats, nil
+	}
+
+	stats, err := c.store.GetStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.stats = stats
+	c.fetchedAt = time.Now()
+	return stats, nil
+}
+
+// statsBroadcaster pushes stats to every SSE client from one shared refresher
+// The refresher only runs while at least one client is subscribed
+type statsBroadcaster struct {
+	cache *statsCache
+
+	mu          sync.Mutex
+	subscribers map[chan []byte]struct{}
+	stop        context.CancelFunc
+}
+
+// newStatsBroadcaster creates a broadcaster reading from cache
+func newStatsBroadcaster(cache *statsCache) *statsBroadcaster {
+	return &statsBroadcaster{
+		cache:       cache,
+		subscribers: map[chan []byte]struct{}{},
+	}
+}
+
+// subscribe registers a client and returns its channel of marshaled stats
+// Slow clients miss updates rather than holding up the others
+func (b *statsBroadcaster) subscribe() chan []byte {
+	ch := make(chan []byte, 1)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscribers[ch] = struct{}{}
+	if b.stop == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		b.stop = cancel
+		go b.run(ctx)
+	}
+	return ch
+}
+
+// unsubscribe removes a client and stops the refresher after the last one leaves
+func (b *statsBroadcaster) unsubscribe(ch chan []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subscribers, ch)
+	if len(b.subscribers) == 0 && b.stop != nil {
+		b.stop()
+		b.stop = nil
+	}
+}
+
+// run fetches and fans out stats every statsStreamInterval until ctx is done
+func (b *statsBroadcaster) run(ctx context.Context) {
+	ticker := time.NewTicker(statsStreamInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		stats, err := b.cache.get(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("Failed to get stats for SSE", "error", err)
+			}
+			continue
+		}
+
+		data, err := json.Marshal(stats)
+		if err != nil {
+			slog.Error("Failed to marshal stats", "error", err)
+			continue
+		}
+
+		b.mu.Lock()
+		for ch := range b.subscribers {
+			select {
+			case ch <- data:
+			default:
+			}
+		}
+		b.mu.Unlock()
+	}
+}
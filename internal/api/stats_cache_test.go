@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+)
+
+// countingStore counts GetStats calls; other Store methods are not used
+type countingStore struct {
+	storage.Store
+	calls atomic.Int32
+}
+
+func (s *countingStore) GetStats(ctx context.Context) (*models.TaskStatsResponse, error) {
+	s.calls.Add(1)
+	return &models.TaskStatsResponse{}, nil
+}
+
+func TestStatsCache(t *testing.T) {
+	store := &countingStore{}
+	cache := newStatsCache(store, 50*time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.get(context.Background()); err != nil {
+			t.Fatalf("get() error = %v", err)
+		}
+	}
+	if calls := store.calls.Load(); calls != 1 {
+		t.Errorf("GetStats called %d times within the ttl, want 1", calls)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if _, err := cache.get(context.Background()); err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+	if calls := store.calls.Load(); calls != 2 {
+		t.Errorf("GetStats called %d times after the ttl, want 2", calls)
+	}
+}
+
+func TestStatsCacheDisabled(t *testing.T) {
+	store := &countingStore{}
+	cache := newStatsCache(store, 0)
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.get(context.Background()); err != nil {
+			t.Fatalf("get() error = %v", err)
+		}
+	}
+	if calls := store.calls.Load(); calls != 3 {
+		t.Errorf("GetStats called %d times with caching disabled, want 3", calls)
+	}
+}
@@ -6,19 +6,28 @@ import (
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
 	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // CreateTask handles POST /tasks
 // Creates a new task that will be processed by background workers
 func (h *Handler) CreateTask(c *gin.Context) {
+	if h.rejectDuringMaintenance(c) {
+		return
+	}
+
 	var req models.CreateTaskRequest
 
 	// Bind and validate JSON request body
 	if err := c.ShouldBindJSON(&req); err != nil {
+		if bodyTooLarge(c, err) {
+			return
+		}
 		slog.Warn("Invalid request body", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Invalid request body",
@@ -27,23 +36,44 @@ func (h *Handler) CreateTask(c *gin.Context) {
 		return
 	}
 
-	// Validate required field: type
-	if req.Type == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Task type is required",
-		})
+	if invalid := validateCreateTask(req); invalid != nil {
+		c.JSON(http.StatusBadRequest, invalid)
 		return
 	}
+	if oversized := h.oversizedPayload(req); oversized != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, oversized)
+		return
+	}
+	req.Metadata = withRequestMetadata(c, req.Metadata)
 
 	// If payload is not provided or empty, set to empty JSON object
 	if len(req.Payload) == 0 {
 		req.Payload = json.RawMessage("{}")
 	}
+	unknownType, rejected := h.unknownTaskType(c, req)
+	if rejected != nil {
+		c.JSON(http.StatusUnprocessableEntity, rejected)
+		return
+	}
+	if invalid := h.invalidPayload(c, req); invalid != nil {
+		c.JSON(http.StatusUnprocessableEntity, invalid)
+		return
+	}
+	if invalid := h.signPayload(&req); invalid != nil {
+		c.JSON(http.StatusBadRequest, invalid)
+		return
+	}
+	if !h.admitted(c, []models.CreateTaskRequest{req}) {
+		return
+	}
 
 	// Create the task in storage
 	task, err := h.store.CreateTask(c.Request.Context(), req)
 	if err != nil {
-		slog.Error("Failed to create task", "error", err)
+		if quotaExceeded(c, err) {
+			return
+		}
+		slog.Error("Failed to create task", "request_id", requestID(c), "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to create task",
 		})
@@ -52,6 +82,7 @@ func (h *Handler) CreateTask(c *gin.Context) {
 
 	slog.Info("Task created",
 		"task_id", task.ID,
+		"request_id", requestID(c),
 		"task_name", task.Name,
 		"task_type", task.Type,
 		"priority", task.Priority,
@@ -60,43 +91,340 @@ func (h *Handler) CreateTask(c *gin.Context) {
 
 	// Return success response
 	c.JSON(http.StatusCreated, models.CreateTaskResponse{
-		ID:     task.ID,
-		Status: task.Status.String(),
+		ID:          task.PublicID,
+		Status:      task.Status.String(),
+		UnknownType: unknownType,
 	})
 }
 
+// CreateTasks handles POST /tasks/batch
+// Creates up to 1000 tasks at once; nothing is created if any task is invalid
+func (h *Handler) CreateTasks(c *gin.Context) {
+	if h.rejectDuringMaintenance(c) {
+		return
+	}
+
+	var req models.CreateTasksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if bodyTooLarge(c, err) {
+			return
+		}
+		slog.Warn("Invalid request body", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	unknownTypes := make([]bool, len(req.Tasks))
+	for i := range req.Tasks {
+		if invalid := validateCreateTask(req.Tasks[i]); invalid != nil {
+			invalid["index"] = i
+			c.JSON(http.StatusBadRequest, invalid)
+			return
+		}
+		if oversized := h.oversizedPayload(req.Tasks[i]); oversized != nil {
+			oversized["index"] = i
+			c.JSON(http.StatusRequestEntityTooLarge, oversized)
+			return
+		}
+		req.Tasks[i].Metadata = withRequestMetadata(c, req.Tasks[i].Metadata)
+		if len(req.Tasks[i].Payload) == 0 {
+			req.Tasks[i].Payload = json.RawMessage("{}")
+		}
+		var rejected gin.H
+		if unknownTypes[i], rejected = h.unknownTaskType(c, req.Tasks[i]); rejected != nil {
+			rejected["index"] = i
+			c.JSON(http.StatusUnprocessableEntity, rejected)
+			return
+		}
+		if invalid := h.invalidPayload(c, req.Tasks[i]); invalid != nil {
+			invalid["index"] = i
+			c.JSON(http.StatusUnprocessableEntity, invalid)
+			return
+		}
+		if invalid := h.signPayload(&req.Tasks[i]); invalid != nil {
+			invalid["index"] = i
+			c.JSON(http.StatusBadRequest, invalid)
+			return
+		}
+	}
+
+	if !h.admitted(c, req.Tasks) {
+		return
+	}
+
+	tasks, err := h.store.CreateTasks(c.Request.Context(), req.Tasks)
+	if err != nil {
+		if quotaExceeded(c, err) {
+			return
+		}
+		slog.Error("Failed to create tasks", "count", len(req.Tasks), "request_id", requestID(c), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create tasks",
+		})
+		return
+	}
+
+	slog.Info("Tasks created", "count", len(tasks), "request_id", requestID(c))
+
+	resp := models.CreateTasksResponse{Tasks: make([]models.CreateTaskResponse, len(tasks))}
+	for i, task := range tasks {
+		resp.Tasks[i] = models.CreateTaskResponse{
+			ID:          task.PublicID,
+			Status:      task.Status.String(),
+			UnknownType: unknownTypes[i],
+		}
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// rejectDuringMaintenance answers 503 while maintenance mode is enabled and reports whether it did
+// Only new work is rejected; reads keep working
+func (h *Handler) rejectDuringMaintenance(c *gin.Context) bool {
+	mode, err := h.store.GetMaintenance(c.Request.Context())
+	if err != nil {
+		slog.Error("Failed to get maintenance mode", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create task",
+		})
+		return true
+	}
+	if mode.Enabled {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":  "Task creation is disabled during maintenance",
+			"reason": mode.Reason,
+		})
+		return true
+	}
+	return false
+}
+
+// signPayload signs the payload of req when payload signing is enabled, returning the error response or nil
+func (h *Handler) signPayload(req *models.CreateTaskRequest) gin.H {
+	if h.payloadSigner == nil {
+		return nil
+	}
+
+	publicID, err := uuid.NewV7()
+	if err != nil {
+		return gin.H{"error": "Failed to create task"}
+	}
+	signature, err := h.payloadSigner.Sign(publicID, req.Type, req.Payload)
+	if err != nil {
+		return gin.H{
+			"error":   "Invalid payload",
+			"details": err.Error(),
+		}
+	}
+	req.PublicID, req.PayloadSignature = publicID, signature
+	return nil
+}
+
+// validateCreateTask checks the fields binding cannot, returning the error response or nil
+func validateCreateTask(req models.CreateTaskRequest) gin.H {
+	// Validate required field: type
+	if req.Type == "" {
+		return gin.H{"error": "Task type is required"}
+	}
+
+	// Validate retry strategy and schedule
+	if req.RetryStrategy != "" && !req.RetryStrategy.IsValid() {
+		return gin.H{"error": "Invalid retry strategy"}
+	}
+	if req.RetryStrategy == models.RetryStrategyCustom && len(req.RetrySchedule) == 0 {
+		return gin.H{"error": "Retry schedule is required for custom retry strategy"}
+	}
+	if _, err := models.ParseRetrySchedule(req.RetrySchedule); err != nil {
+		return gin.H{
+			"error":   "Invalid retry schedule",
+			"details": err.Error(),
+		}
+	}
+
+	if err := models.ValidateMetadata(req.Metadata); err != nil {
+		return gin.H{
+			"error":   "Invalid metadata",
+			"details": err.Error(),
+		}
+	}
+
+	if err := models.ValidateTenant(req.Tenant); err != nil {
+		return gin.H{
+			"error":   "Invalid tenant",
+			"details": err.Error(),
+		}
+	}
+
+	return nil
+}
+
 // GetTask handles GET /tasks/:id
 // Returns the status and details of the task with the given ID
 func (h *Handler) GetTask(c *gin.Context) {
-	// Parse task ID from URL parameter
-	idParam := c.Param("id")
-	taskID, err := strconv.ParseInt(idParam, 10, 64)
-	if err != nil {
-		slog.Warn("Invalid task ID", "id", idParam, "error", err)
+	task, ok := h.taskFromParam(c)
+	if !ok {
+		return
+	}
+	if !h.auditPayloadAccess(c, task) {
+		return
+	}
+
+	setETag(c, task)
+
+	// Return task details
+	c.JSON(http.StatusOK, task.ToTaskResponse())
+}
+
+// UpdateTask handles PATCH /tasks/:id
+// Requeues or cancels a task; an If-Match version rejects stale updates with 409
+func (h *Handler) UpdateTask(c *gin.Context) {
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Warn("Invalid request body", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid task ID",
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid status",
+			"details": err.Error(),
 		})
 		return
 	}
 
-	// Retrieve task from storage
-	task, err := h.store.GetTask(c.Request.Context(), taskID)
-	if err != nil {
+	task, ok := h.taskFromParam(c)
+	if !ok {
+		return
+	}
+	if req.LastError == nil {
+		req.LastError = task.LastError
+	}
+
+	ctx := c.Request.Context()
+	if err := h.store.UpdateTaskStatus(ctx, task.ID, req.Status, req.LastError, version); err != nil {
 		if errors.Is(err, storage.ErrTaskNotFound) {
-			slog.Warn("Task not found", "task_id", taskID)
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": "Task not found",
 			})
 			return
 		}
+		if errors.Is(err, storage.ErrVersionConflict) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Task was modified since the given version",
+			})
+			return
+		}
 
-		slog.Error("Failed to get task", "task_id", taskID, "error", err)
+		slog.Error("Failed to update task", "task_id", task.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update task",
+		})
+		return
+	}
+
+	// Best-effort history logging
+	actor := payloadActor(c)
+	history := models.TaskHistory{
+		TaskID:       task.ID,
+		Status:       req.Status,
+		EventType:    models.EventTaskUpdated,
+		ErrorMessage: req.LastError,
+		Actor:        &actor,
+	}
+	if err := h.store.InsertHistory(ctx, history); err != nil {
+		slog.Error("Failed to insert update history", "task_id", task.ID, "error", err)
+	}
+
+	updated, err := h.store.GetTask(ctx, task.ID)
+	if err != nil {
+		slog.Error("Failed to retrieve updated task", "task_id", task.ID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve task",
 		})
 		return
 	}
 
-	// Return task details
-	c.JSON(http.StatusOK, task.ToTaskResponse())
+	slog.Info("Task updated", "task_id", task.ID, "status", req.Status, "actor", actor)
+	setETag(c, updated)
+	c.JSON(http.StatusOK, gin.H{
+		"id":      updated.PublicID,
+		"status":  updated.Status,
+		"version": updated.Version,
+	})
+}
+
+// setETag sends the task's version as the ETag, so clients can send it back in If-Match
+func setETag(c *gin.Context, task *models.Task) {
+	c.Header("ETag", strconv.Quote(strconv.FormatInt(task.Version, 10)))
+}
+
+// ifMatchVersion parses the task version a write is conditional on from the If-Match header
+// Returns nil without the header or for If-Match: *, and answers 400 itself when it is malformed
+func ifMatchVersion(c *gin.Context) (*int64, bool) {
+	header := c.GetHeader("If-Match")
+	if header == "" || header == "*" {
+		return nil, true
+	}
+
+	raw := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	version, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		slog.Warn("Invalid If-Match header", "if_match", header)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "If-Match must be a task version",
+		})
+		return nil, false
+	}
+
+	return &version, true
+}
+
+// taskFromParam loads the task named by the :id parameter, answering 400 or 404 itself when it cannot
+// The id is the task's public UUID; integer ids are only accepted with Config.IntegerTaskIDs
+func (h *Handler) taskFromParam(c *gin.Context) (*models.Task, bool) {
+	idParam := c.Param("id")
+
+	var (
+		task *models.Task
+		err  error
+	)
+	if publicID, parseErr := uuid.Parse(idParam); parseErr == nil {
+		task, err = h.store.GetTaskByPublicID(c.Request.Context(), publicID)
+	} else if taskID, parseErr := strconv.ParseInt(idParam, 10, 64); parseErr == nil && h.integerTaskIDs {
+		task, err = h.store.GetTask(c.Request.Context(), taskID)
+	} else {
+		slog.Warn("Invalid task ID", "id", idParam)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID",
+		})
+		return nil, false
+	}
+
+	if err != nil {
+		if errors.Is(err, storage.ErrTaskNotFound) {
+			slog.Warn("Task not found", "id", idParam)
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Task not found",
+			})
+			return nil, false
+		}
+
+		slog.Error("Failed to get task", "id", idParam, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve task",
+		})
+		return nil, false
+	}
+
+	return task, true
 }
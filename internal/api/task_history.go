@@ -1,52 +1,26 @@
 package api
 
 import (
-	"errors"
 	"log/slog"
 	"net/http"
-	"strconv"
 
 	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
-	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
 	"github.com/gin-gonic/gin"
 )
 
 // GetTaskHistory handles GET /tasks/:id/history
 // Returns the complete history of status changes for the task
 func (h *Handler) GetTaskHistory(c *gin.Context) {
-	// Parse task ID from URL parameter
-	idParam := c.Param("id")
-	taskID, err := strconv.ParseInt(idParam, 10, 64)
-	if err != nil {
-		slog.Warn("Invalid task ID", "id", idParam, "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid task ID",
-		})
-		return
-	}
-
 	// Verify task exists first
-	_, err = h.store.GetTask(c.Request.Context(), taskID)
-	if err != nil {
-		if errors.Is(err, storage.ErrTaskNotFound) {
-			slog.Warn("Task not found", "task_id", taskID)
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Task not found",
-			})
-			return
-		}
-
-		slog.Error("Failed to verify task existence", "task_id", taskID, "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to retrieve task",
-		})
+	task, ok := h.taskFromParam(c)
+	if !ok {
 		return
 	}
 
 	// Retrieve task history from storage
-	history, err := h.store.GetTaskHistory(c.Request.Context(), taskID)
+	history, err := h.store.GetTaskHistory(c.Request.Context(), task.ID, task.CreatedAt)
 	if err != nil {
-		slog.Error("Failed to get task history", "task_id", taskID, "error", err)
+		slog.Error("Failed to get task history", "task_id", task.ID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve task history",
 		})
@@ -55,6 +29,7 @@ func (h *Handler) GetTaskHistory(c *gin.Context) {
 
 	// Return history
 	c.JSON(http.StatusOK, models.TaskHistoryResponse{
+		TaskID:  task.PublicID,
 		History: history,
 	})
 }
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// GetTaskResult handles GET /tasks/:id/result
+// Returns the result the task's handler stored, 404 if it stored none
+func (h *Handler) GetTaskResult(c *gin.Context) {
+	results, ok := h.store.(storage.TaskResults)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, gin.H{
+			"error": "Not supported by the storage backend",
+		})
+		return
+	}
+
+	task, ok := h.taskFromParam(c)
+	if !ok {
+		return
+	}
+
+	result, err := results.GetTaskResult(c.Request.Context(), task.ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrTaskResultNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Task result not found",
+				"details": "the task has not succeeded yet or its handler stored no result",
+			})
+			return
+		}
+
+		slog.Error("Failed to get task result", "task_id", task.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve task result",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TaskResultResponse{
+		TaskID:     task.PublicID,
+		TaskResult: *result,
+	})
+}
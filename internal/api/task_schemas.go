@@ -0,0 +1,196 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/payloadschema"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// schemaRefresh is how long compiled schemas are reused; changes made through other servers apply within it
+const schemaRefresh = 5 * time.Second
+
+// schemaRegistry caches the compiled payload schemas of every task type
+type schemaRegistry struct {
+	mu      sync.Mutex
+	fetched time.Time
+	schemas map[string]*payloadschema.Schema // by lowercase type
+}
+
+// lookup returns the schema of taskType, nil when it has none
+func (r *schemaRegistry) lookup(ctx context.Context, store storage.TaskSchemas, taskType string, now time.Time) (*payloadschema.Schema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.schemas == nil || now.Sub(r.fetched) >= schemaRefresh {
+		list, err := store.ListTaskSchemas(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		r.schemas = map[string]*payloadschema.Schema{}
+		for _, ts := range list {
+			schema, err := payloadschema.Compile(ts.Schema)
+			if err != nil {
+				// Schemas are compiled before they are stored, so this only happens after a library change
+				slog.Error("Failed to compile task schema, payloads are not checked", "task_type", ts.TaskType, "error", err)
+				continue
+			}
+			r.schemas[strings.ToLower(ts.TaskType)] = schema
+		}
+		r.fetched = now
+	}
+
+	return r.schemas[strings.ToLower(taskType)], nil
+}
+
+// invalidate makes the next lookup read the schemas again
+func (r *schemaRegistry) invalidate() {
+	r.mu.Lock()
+	r.schemas = nil
+	r.mu.Unlock()
+}
+
+// invalidPayload checks the payload of req against the schema of its type, returning the 422 response or nil
+// A store that cannot be read lets the task through: the handler still rejects a payload it cannot use
+func (h *Handler) invalidPayload(c *gin.Context, req models.CreateTaskRequest) gin.H {
+	store, ok := h.store.(storage.TaskSchemas)
+	if !ok {
+		return nil
+	}
+
+	schema, err := h.schemas.lookup(c.Request.Context(), store, req.Type, time.Now())
+	if err != nil {
+		slog.Warn("Failed to load task schemas, payload not checked", "task_type", req.Type, "error", err)
+		return nil
+	}
+	if schema == nil {
+		return nil
+	}
+
+	fieldErrs := schema.Validate(req.Payload)
+	if fieldErrs == nil {
+		return nil
+	}
+
+	slog.Warn("Payload does not match task schema", "task_type", req.Type, "errors", len(fieldErrs))
+	return gin.H{
+		"error":     "Payload does not match the task type's schema",
+		"task_type": req.Type,
+		"fields":    fieldErrs,
+	}
+}
+
+// ListTaskSchemas handles GET /task-schemas
+// Returns the payload schema of every task type that has one
+func (h *Handler) ListTaskSchemas(c *gin.Context) {
+	schemas, ok := h.taskSchemas(c)
+	if !ok {
+		return
+	}
+
+	list, err := schemas.ListTaskSchemas(c.Request.Context())
+	if err != nil {
+		slog.Error("Failed to list task schemas", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve task schemas",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TaskSchemasResponse{
+		Schemas: list,
+	})
+}
+
+// SetTaskSchema handles PUT /task-schemas/:type
+// Creates or replaces the JSON Schema payloads of the given type must match to be created
+func (h *Handler) SetTaskSchema(c *gin.Context) {
+	schemas, ok := h.taskSchemas(c)
+	if !ok {
+		return
+	}
+
+	taskType := c.Param("type")
+
+	var req models.SetTaskSchemaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Warn("Invalid request body", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if _, err := payloadschema.Compile(req.Schema); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid schema",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := schemas.SetTaskSchema(c.Request.Context(), taskType, req.Schema); err != nil {
+		slog.Error("Failed to set task schema", "task_type", taskType, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to set task schema",
+		})
+		return
+	}
+	h.schemas.invalidate()
+
+	slog.Info("Task schema set", "task_type", taskType)
+	c.JSON(http.StatusOK, gin.H{
+		"task_type": strings.ToLower(taskType),
+		"schema":    req.Schema,
+	})
+}
+
+// DeleteTaskSchema handles DELETE /task-schemas/:type
+// Removes the schema of the given task type; its payloads are no longer checked
+func (h *Handler) DeleteTaskSchema(c *gin.Context) {
+	schemas, ok := h.taskSchemas(c)
+	if !ok {
+		return
+	}
+
+	taskType := c.Param("type")
+	if err := schemas.DeleteTaskSchema(c.Request.Context(), taskType); err != nil {
+		if errors.Is(err, storage.ErrTaskSchemaNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Task schema not found",
+			})
+			return
+		}
+
+		slog.Error("Failed to delete task schema", "task_type", taskType, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete task schema",
+		})
+		return
+	}
+	h.schemas.invalidate()
+
+	slog.Info("Task schema removed", "task_type", taskType)
+	c.Status(http.StatusNoContent)
+}
+
+// taskSchemas returns the store's task schemas, answering 501 itself when the backend does not keep them
+func (h *Handler) taskSchemas(c *gin.Context) (storage.TaskSchemas, bool) {
+	s, ok := h.store.(storage.TaskSchemas)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, gin.H{
+			"error": "Not supported by the storage backend",
+		})
+	}
+	return s, ok
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// schemaStore keeps task schemas in memory and accepts every task
+type schemaStore struct {
+	createStore
+	schemas map[string]json.RawMessage
+}
+
+func (s *schemaStore) ListTaskSchemas(ctx context.Context) ([]models.TaskSchema, error) {
+	list := []models.TaskSchema{}
+	for taskType, schema := range s.schemas {
+		list = append(list, models.TaskSchema{TaskType: taskType, Schema: schema})
+	}
+	return list, nil
+}
+
+func (s *schemaStore) SetTaskSchema(ctx context.Context, taskType string, schema json.RawMessage) error {
+	s.schemas[strings.ToLower(taskType)] = schema
+	return nil
+}
+
+func (s *schemaStore) DeleteTaskSchema(ctx context.Context, taskType string) error {
+	delete(s.schemas, strings.ToLower(taskType))
+	return nil
+}
+
+func TestTaskSchemas(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := NewHandler(&schemaStore{schemas: map[string]json.RawMessage{}}, Config{})
+	r := gin.New()
+	r.POST("/api/tasks", h.CreateTask)
+	r.POST("/api/tasks/batch", h.CreateTasks)
+	r.PUT("/api/task-schemas/:type", h.SetTaskSchema)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	if w := serve(http.MethodPut, "/api/task-schemas/send_email", `{"schema":{"type":"strin"}}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid schema = %d %s, want 400", w.Code, w.Body)
+	}
+	schema := `{"schema":{"type":"object","required":["to"],"properties":{"to":{"type":"string"}}}}`
+	if w := serve(http.MethodPut, "/api/task-schemas/send_email", schema); w.Code != http.StatusOK {
+		t.Fatalf("set schema = %d %s, want 200", w.Code, w.Body)
+	}
+
+	if w := serve(http.MethodPost, "/api/tasks", `{"name":"n","type":"send_email","payload":{"to":"a@example.com"}}`); w.Code != http.StatusCreated {
+		t.Fatalf("matching payload = %d %s, want 201", w.Code, w.Body)
+	}
+	if w := serve(http.MethodPost, "/api/tasks", `{"name":"n","type":"other","payload":{}}`); w.Code != http.StatusCreated {
+		t.Fatalf("type without schema = %d %s, want 201", w.Code, w.Body)
+	}
+
+	w := serve(http.MethodPost, "/api/tasks", `{"name":"n","type":"Send_Email","payload":{"to":1}}`)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), `"field":"/to"`) {
+		t.Fatalf("mismatching payload = %d %s, want 422 naming /to", w.Code, w.Body)
+	}
+
+	batch := `{"tasks":[{"name":"a","type":"send_email","payload":{"to":"a@example.com"}},{"name":"b","type":"send_email"}]}`
+	w = serve(http.MethodPost, "/api/tasks/batch", batch)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), `"index":1`) {
+		t.Fatalf("batch with a mismatching payload = %d %s, want 422 for index 1", w.Code, w.Body)
+	}
+}
@@ -3,15 +3,34 @@ package api
 import (
 	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
 	"github.com/gin-gonic/gin"
 )
 
 // GetStats handles GET /stats
 // Returns system statistics for dashboard visualization
+// With from and/or to, only tasks created within that range are counted
 func (h *Handler) GetStats(c *gin.Context) {
-	// Retrieve statistics from storage
-	stats, err := h.store.GetStats(c.Request.Context())
+	r, ok := statsRange(c)
+	if !ok {
+		return
+	}
+
+	var stats *models.TaskStatsResponse
+	var err error
+	if r.IsZero() {
+		// Retrieve statistics, cached for a short while across requests
+		stats, err = h.stats.get(c.Request.Context())
+	} else {
+		a, ok := h.analytics(c)
+		if !ok {
+			return
+		}
+		stats, err = a.GetRangeStats(c.Request.Context(), r)
+	}
 	if err != nil {
 		slog.Error("Failed to get stats", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -23,3 +42,267 @@ func (h *Handler) GetStats(c *gin.Context) {
 	// Return statistics
 	c.JSON(http.StatusOK, stats)
 }
+
+// GetTypeStats handles GET /stats/types
+// Returns task counts, failure rate and average retries per task type
+// With from and/or to, only tasks created within that range are counted
+func (h *Handler) GetTypeStats(c *gin.Context) {
+	r, ok := statsRange(c)
+	if !ok {
+		return
+	}
+
+	var stats []models.TypeStats
+	var err error
+	if r.IsZero() {
+		stats, err = h.store.GetTypeStats(c.Request.Context())
+	} else {
+		a, ok := h.analytics(c)
+		if !ok {
+			return
+		}
+		stats, err = a.GetRangeTypeStats(c.Request.Context(), r)
+	}
+	if err != nil {
+		slog.Error("Failed to get type stats", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve statistics",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TypeStatsResponse{Types: stats})
+}
+
+// GetQueueDepth handles GET /stats/queue
+// Returns the queued and claimable task counts and the age of the oldest claimable task per type
+func (h *Handler) GetQueueDepth(c *gin.Context) {
+	depths, err := h.store.GetQueueDepth(c.Request.Context())
+	if err != nil {
+		slog.Error("Failed to get queue depth", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve queue depth",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.QueueDepthResponse{Types: depths})
+}
+
+// Windows of the analytic stats endpoints
+const (
+	defaultStatsWindow = time.Hour
+	maxStatsWindow     = 31 * 24 * time.Hour
+)
+
+// analytics returns the store's analytic queries, answering 501 itself when the backend has none
+func (h *Handler) analytics(c *gin.Context) (storage.TaskAnalytics, bool) {
+	a, ok := h.store.(storage.TaskAnalytics)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, gin.H{
+			"error": "Not supported by the storage backend",
+		})
+	}
+	return a, ok
+}
+
+// statsRange parses the from and to query parameters, RFC 3339 times or dates such as 2025-12-02
+// A date alone means midnight UTC, so from=2025-12-02&to=2025-12-03 covers that day
+// Answers 400 itself when either is malformed or from is not before to
+func statsRange(c *gin.Context) (models.TimeRange, bool) {
+	var r models.TimeRange
+	for _, bound := range []struct {
+		param string
+		value *time.Time
+	}{{"from", &r.From}, {"to", &r.To}} {
+		raw := c.Query(bound.param)
+		if raw == "" {
+			continue
+		}
+
+		t, err := parseStatsTime(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid " + bound.param,
+				"details": "must be an RFC 3339 time or a date, e.g. 2025-12-02T15:04:05Z or 2025-12-02",
+			})
+			return r, false
+		}
+		*bound.value = t
+	}
+
+	if !r.From.IsZero() && !r.To.IsZero() && !r.From.Before(r.To) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "from must be before to",
+		})
+		return r, false
+	}
+	return r, true
+}
+
+// parseStatsTime parses an RFC 3339 time or a date
+func parseStatsTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, raw)
+}
+
+// statsWindowRange returns the range the windowed stats endpoints cover
+// from and to select it directly; without from it is the window (e.g. 15m or 24h, default 1h) ending at to or now
+// Answers 400 itself when the parameters are malformed or the range is longer than maxStatsWindow
+func statsWindowRange(c *gin.Context) (models.TimeRange, time.Duration, bool) {
+	r, ok := statsRange(c)
+	if !ok {
+		return r, 0, false
+	}
+
+	end := r.To
+	if end.IsZero() {
+		end = time.Now()
+	}
+
+	if r.From.IsZero() {
+		window := defaultStatsWindow
+		if raw := c.Query("window"); raw != "" {
+			var err error
+			window, err = time.ParseDuration(raw)
+			if err != nil || window <= 0 {
+				window = -1
+			}
+		}
+		r.From = end.Add(-window)
+	}
+
+	span := end.Sub(r.From)
+	if span <= 0 || span > maxStatsWindow {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid window",
+			"details": "window must be a duration between 1s and 744h, e.g. 15m or 24h",
+		})
+		return r, 0, false
+	}
+	return r, span, true
+}
+
+// GetLatencyStats handles GET /stats/latency
+// Returns p50/p90/p99 queue wait and execution times per type for tasks finished within the window (default 1h)
+// or between from and to
+func (h *Handler) GetLatencyStats(c *gin.Context) {
+	a, ok := h.analytics(c)
+	if !ok {
+		return
+	}
+	r, window, ok := statsWindowRange(c)
+	if !ok {
+		return
+	}
+
+	stats, err := a.GetLatencyStats(c.Request.Context(), r)
+	if err != nil {
+		slog.Error("Failed to get latency stats", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve latency statistics",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.LatencyStatsResponse{WindowSeconds: window.Seconds(), Types: stats})
+}
+
+// GetSuccessRates handles GET /stats/success-rate
+// Returns the succeeded share of tasks finished within the window (default 1h) or between from and to, per type
+func (h *Handler) GetSuccessRates(c *gin.Context) {
+	a, ok := h.analytics(c)
+	if !ok {
+		return
+	}
+	r, window, ok := statsWindowRange(c)
+	if !ok {
+		return
+	}
+
+	rates, err := a.GetSuccessRates(c.Request.Context(), r)
+	if err != nil {
+		slog.Error("Failed to get success rates", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve success rates",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessRateResponse{WindowSeconds: window.Seconds(), Types: rates})
+}
+
+// GetRetryDistribution handles GET /stats/retries
+// Returns how many tasks finished after each retry count, succeeded or failed, per type and overall,
+// for tasks finished within the window (default 1h) or between from and to
+func (h *Handler) GetRetryDistribution(c *gin.Context) {
+	a, ok := h.analytics(c)
+	if !ok {
+		return
+	}
+	r, window, ok := statsWindowRange(c)
+	if !ok {
+		return
+	}
+
+	distributions, err := a.GetRetryDistribution(c.Request.Context(), r)
+	if err != nil {
+		slog.Error("Failed to get retry distribution", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve retry distribution",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.RetryDistributionResponse{
+		WindowSeconds: window.Seconds(),
+		Overall:       models.MergeRetryBuckets(distributions),
+		Types:         distributions,
+	})
+}
+
+// maxThroughputBuckets bounds the size of a throughput response
+const maxThroughputBuckets = 5000
+
+// GetThroughput handles GET /stats/timeseries
+// Returns created, succeeded and failed counts per bucket (1m, 1h or 1d; default 1m) over the window (default 1h)
+// or between from and to
+func (h *Handler) GetThroughput(c *gin.Context) {
+	a, ok := h.analytics(c)
+	if !ok {
+		return
+	}
+	r, window, ok := statsWindowRange(c)
+	if !ok {
+		return
+	}
+
+	bucket := models.StatsBucket(c.DefaultQuery("bucket", string(models.StatsBucketMinute)))
+	if !bucket.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid bucket",
+			"details": "bucket must be 1m, 1h or 1d",
+		})
+		return
+	}
+	if window/bucket.Duration() > maxThroughputBuckets {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Too many buckets",
+			"details": "use a wider bucket or a shorter window",
+		})
+		return
+	}
+
+	buckets, err := a.GetThroughput(c.Request.Context(), bucket, r)
+	if err != nil {
+		slog.Error("Failed to get throughput", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve throughput",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ThroughputResponse{Bucket: bucket, WindowSeconds: window.Seconds(), Buckets: buckets})
+}
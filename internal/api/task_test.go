@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// versionStore holds one task and rejects writes made against a stale version, like the real stores
+type versionStore struct {
+	auditStore
+}
+
+func (s *versionStore) GetTask(ctx context.Context, id int64) (*models.Task, error) {
+	return s.task, nil
+}
+
+func (s *versionStore) UpdateTaskStatus(ctx context.Context, taskID int64, status models.TaskStatus, errorMessage *string, version *int64) error {
+	if version != nil && *version != s.task.Version {
+		return storage.ErrVersionConflict
+	}
+	s.task.Status = status
+	s.task.LastError = errorMessage
+	s.task.Version++
+	return nil
+}
+
+func (s *versionStore) RedactTask(ctx context.Context, taskID int64, version *int64) (*models.Task, error) {
+	if version != nil && *version != s.task.Version {
+		return nil, storage.ErrVersionConflict
+	}
+	s.task.Version++
+	return s.task, nil
+}
+
+func TestUpdateTask(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		ifMatch    string
+		body       string
+		wantCode   int
+		wantStatus models.TaskStatus
+	}{
+		{"cancel", `"4"`, `{"status": "failed", "last_error": "cancelled by operator"}`, http.StatusOK, models.TaskStatusFailed},
+		{"requeue without If-Match", "", `{"status": "queued"}`, http.StatusOK, models.TaskStatusQueued},
+		{"stale version", `"3"`, `{"status": "failed"}`, http.StatusConflict, models.TaskStatusRunning},
+		{"malformed If-Match", `"four"`, `{"status": "failed"}`, http.StatusBadRequest, models.TaskStatusRunning},
+		{"status not settable", `"4"`, `{"status": "succeeded"}`, http.StatusBadRequest, models.TaskStatusRunning},
+		{"missing status", `"4"`, `{}`, http.StatusBadRequest, models.TaskStatusRunning},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &versionStore{auditStore{task: &models.Task{ID: 7, PublicID: uuid.New(), Status: models.TaskStatusRunning, Version: 4}}}
+			h := NewHandler(store, Config{})
+			r := gin.New()
+			r.PATCH("/api/tasks/:id", h.UpdateTask)
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/tasks/"+store.task.PublicID.String(), strings.NewReader(tt.body))
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("PATCH = %d %s, want %d", w.Code, w.Body, tt.wantCode)
+			}
+			if store.task.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", store.task.Status, tt.wantStatus)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			if etag := w.Header().Get("ETag"); etag != `"5"` {
+				t.Errorf("ETag = %s, want \"5\"", etag)
+			}
+			if len(store.history) != 1 || store.history[0].EventType != models.EventTaskUpdated {
+				t.Errorf("history = %+v, want one task_updated event", store.history)
+			}
+		})
+	}
+}
+
+func TestRedactTaskIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := &versionStore{auditStore{task: &models.Task{ID: 7, PublicID: uuid.New(), Status: models.TaskStatusFailed, Version: 4}}}
+	h := NewHandler(store, Config{})
+	r := gin.New()
+	r.POST("/api/tasks/:id/redact", h.RedactTask)
+
+	redact := func(ifMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/tasks/"+store.task.PublicID.String()+"/redact", nil)
+		req.Header.Set("If-Match", ifMatch)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := redact(`"3"`); w.Code != http.StatusConflict {
+		t.Fatalf("redact with a stale version = %d %s, want 409", w.Code, w.Body)
+	}
+	if w := redact(`"4"`); w.Code != http.StatusOK {
+		t.Fatalf("redact with the current version = %d %s, want 200", w.Code, w.Body)
+	}
+}
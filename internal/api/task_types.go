@@ -0,0 +1,188 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// How task creation treats types no worker handles and no operator registered, see Config.UnknownTaskTypes
+const (
+	UnknownTaskTypesAccept = "accept" // create the task as usual
+	UnknownTaskTypesWarn   = "warn"   // create the task and set unknown_type in the response
+	UnknownTaskTypesReject = "reject" // answer 422
+)
+
+// typeRefresh is how long the known task types are reused
+// A type missing from them is looked up again after typeMissRefresh, so a newly started worker's types apply quickly
+const (
+	typeRefresh     = 30 * time.Second
+	typeMissRefresh = time.Second
+)
+
+// typeRegistry caches the task types tasks may be created for
+type typeRegistry struct {
+	mu      sync.Mutex
+	fetched time.Time
+	types   map[string]bool // lowercase types; nil until fetched
+}
+
+// known reports whether taskType is handled by a worker or registered
+// Every type is known while the registry is empty, e.g. before the first worker has started
+func (r *typeRegistry) known(ctx context.Context, store storage.TaskTypes, taskType string, now time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	taskType = strings.ToLower(taskType)
+	age := now.Sub(r.fetched)
+	if r.types == nil || age >= typeRefresh || (!r.types[taskType] && age >= typeMissRefresh) {
+		list, err := store.ListTaskTypes(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		r.types = map[string]bool{}
+		for _, t := range list {
+			r.types[strings.ToLower(t.TaskType)] = true
+		}
+		r.fetched = now
+	}
+
+	return len(r.types) == 0 || r.types[taskType], nil
+}
+
+// invalidate makes the next lookup read the types again
+func (r *typeRegistry) invalidate() {
+	r.mu.Lock()
+	r.types = nil
+	r.mu.Unlock()
+}
+
+// unknownTaskType checks req's type against the task type registry
+// It reports whether the type is unknown, and returns the 422 response when such tasks are rejected
+// A registry that cannot be read lets the task through, as if the type were known
+func (h *Handler) unknownTaskType(c *gin.Context, req models.CreateTaskRequest) (bool, gin.H) {
+	if h.unknownTaskTypes != UnknownTaskTypesWarn && h.unknownTaskTypes != UnknownTaskTypesReject {
+		return false, nil
+	}
+	store, ok := h.store.(storage.TaskTypes)
+	if !ok {
+		return false, nil
+	}
+
+	known, err := h.taskTypes.known(c.Request.Context(), store, req.Type, time.Now())
+	if err != nil {
+		slog.Warn("Failed to load task types, type not checked", "task_type", req.Type, "error", err)
+		return false, nil
+	}
+	if known {
+		return false, nil
+	}
+
+	if h.unknownTaskTypes == UnknownTaskTypesReject {
+		slog.Warn("Task type unknown, task creation rejected", "task_type", req.Type)
+		return true, gin.H{
+			"error":     "Unknown task type",
+			"task_type": req.Type,
+			"details":   "no worker handles this type; register it with PUT /api/task-types/:type if its workers are not running yet",
+		}
+	}
+
+	slog.Warn("Task created for a type no worker handles", "task_type", req.Type)
+	return true, nil
+}
+
+// ListTaskTypes handles GET /task-types
+// Returns every type workers reported handlers for or an operator registered
+func (h *Handler) ListTaskTypes(c *gin.Context) {
+	types, ok := h.taskTypeRegistry(c)
+	if !ok {
+		return
+	}
+
+	list, err := types.ListTaskTypes(c.Request.Context())
+	if err != nil {
+		slog.Error("Failed to list task types", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve task types",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TaskTypesResponse{
+		Types: list,
+	})
+}
+
+// RegisterTaskType handles PUT /task-types/:type
+// Accepts tasks of the given type before any worker reports a handler for it
+func (h *Handler) RegisterTaskType(c *gin.Context) {
+	types, ok := h.taskTypeRegistry(c)
+	if !ok {
+		return
+	}
+
+	taskType := c.Param("type")
+	if err := types.RegisterTaskType(c.Request.Context(), taskType); err != nil {
+		slog.Error("Failed to register task type", "task_type", taskType, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to register task type",
+		})
+		return
+	}
+	h.taskTypes.invalidate()
+
+	slog.Info("Task type registered", "task_type", taskType)
+	c.JSON(http.StatusOK, gin.H{
+		"task_type":  strings.ToLower(taskType),
+		"registered": true,
+	})
+}
+
+// DeregisterTaskType handles DELETE /task-types/:type
+// Removes a registered type; it stays known while workers report a handler for it
+func (h *Handler) DeregisterTaskType(c *gin.Context) {
+	types, ok := h.taskTypeRegistry(c)
+	if !ok {
+		return
+	}
+
+	taskType := c.Param("type")
+	if err := types.DeregisterTaskType(c.Request.Context(), taskType); err != nil {
+		if errors.Is(err, storage.ErrTaskTypeNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Task type not registered",
+			})
+			return
+		}
+
+		slog.Error("Failed to deregister task type", "task_type", taskType, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to deregister task type",
+		})
+		return
+	}
+	h.taskTypes.invalidate()
+
+	slog.Info("Task type deregistered", "task_type", taskType)
+	c.Status(http.StatusNoContent)
+}
+
+// taskTypeRegistry returns the store's task type registry, answering 501 itself when the backend does not keep one
+func (h *Handler) taskTypeRegistry(c *gin.Context) (storage.TaskTypes, bool) {
+	t, ok := h.store.(storage.TaskTypes)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, gin.H{
+			"error": "Not supported by the storage backend",
+		})
+	}
+	return t, ok
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// typeStore knows a fixed set of task types and accepts every task
+type typeStore struct {
+	createStore
+	types map[string]bool
+}
+
+func (s *typeStore) ListTaskTypes(ctx context.Context) ([]models.TaskTypeInfo, error) {
+	list := []models.TaskTypeInfo{}
+	for taskType := range s.types {
+		list = append(list, models.TaskTypeInfo{TaskType: taskType, Workers: 1})
+	}
+	return list, nil
+}
+
+func (s *typeStore) RegisterTaskType(ctx context.Context, taskType string) error {
+	s.types[strings.ToLower(taskType)] = true
+	return nil
+}
+
+func (s *typeStore) DeregisterTaskType(ctx context.Context, taskType string) error {
+	delete(s.types, strings.ToLower(taskType))
+	return nil
+}
+
+func TestUnknownTaskTypes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, mode := range []string{UnknownTaskTypesAccept, UnknownTaskTypesWarn, UnknownTaskTypesReject} {
+		t.Run(mode, func(t *testing.T) {
+			h := NewHandler(&typeStore{types: map[string]bool{"send_email": true}}, Config{UnknownTaskTypes: mode})
+			r := gin.New()
+			r.POST("/api/tasks", h.CreateTask)
+			r.POST("/api/tasks/batch", h.CreateTasks)
+			r.PUT("/api/task-types/:type", h.RegisterTaskType)
+
+			serve := func(method, path, body string) *httptest.ResponseRecorder {
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+				return w
+			}
+
+			if w := serve(http.MethodPost, "/api/tasks", `{"name":"n","type":"Send_Email"}`); w.Code != http.StatusCreated || strings.Contains(w.Body.String(), "unknown_type") {
+				t.Fatalf("known type = %d %s, want 201", w.Code, w.Body)
+			}
+
+			w := serve(http.MethodPost, "/api/tasks", `{"name":"n","type":"send_emial"}`)
+			switch mode {
+			case UnknownTaskTypesAccept:
+				if w.Code != http.StatusCreated || strings.Contains(w.Body.String(), "unknown_type") {
+					t.Fatalf("unknown type = %d %s, want 201 without a flag", w.Code, w.Body)
+				}
+			case UnknownTaskTypesWarn:
+				if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), `"unknown_type":true`) {
+					t.Fatalf("unknown type = %d %s, want 201 flagged unknown_type", w.Code, w.Body)
+				}
+			case UnknownTaskTypesReject:
+				if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), `"task_type":"send_emial"`) {
+					t.Fatalf("unknown type = %d %s, want 422", w.Code, w.Body)
+				}
+
+				batch := `{"tasks":[{"name":"a","type":"send_email"},{"name":"b","type":"resize_image"}]}`
+				if w := serve(http.MethodPost, "/api/tasks/batch", batch); w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), `"index":1`) {
+					t.Fatalf("batch with an unknown type = %d %s, want 422 for index 1", w.Code, w.Body)
+				}
+				if w := serve(http.MethodPut, "/api/task-types/resize_image", ""); w.Code != http.StatusOK {
+					t.Fatalf("register type = %d %s, want 200", w.Code, w.Body)
+				}
+				if w := serve(http.MethodPost, "/api/tasks/batch", batch); w.Code != http.StatusCreated {
+					t.Fatalf("batch after registering = %d %s, want 201", w.Code, w.Body)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// ListTenantQuotas handles GET /tenant-quotas
+// Returns every tenant quota with the tenant's queued and running tasks
+func (h *Handler) ListTenantQuotas(c *gin.Context) {
+	quotas, ok := h.tenantQuotas(c)
+	if !ok {
+		return
+	}
+
+	list, err := quotas.ListTenantQuotas(c.Request.Context())
+	if err != nil {
+		slog.Error("Failed to list tenant quotas", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve tenant quotas",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TenantQuotasResponse{
+		Quotas: list,
+	})
+}
+
+// SetTenantQuota handles PUT /tenant-quotas/:tenant
+// Creates or replaces a tenant's caps on queued and running tasks; tenant * sets the default
+func (h *Handler) SetTenantQuota(c *gin.Context) {
+	quotas, ok := h.tenantQuotas(c)
+	if !ok {
+		return
+	}
+
+	tenant := c.Param("tenant")
+	if err := models.ValidateTenantQuotaName(tenant); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid tenant",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var req models.SetTenantQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Warn("Invalid request body", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid tenant quota",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := quotas.SetTenantQuota(c.Request.Context(), tenant, req); err != nil {
+		slog.Error("Failed to set tenant quota", "tenant", tenant, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to set tenant quota",
+		})
+		return
+	}
+
+	slog.Info("Tenant quota set", "tenant", tenant, "max_queued", req.MaxQueued, "max_running", req.MaxRunning)
+	c.JSON(http.StatusOK, gin.H{
+		"tenant":      tenant,
+		"max_queued":  req.MaxQueued,
+		"max_running": req.MaxRunning,
+	})
+}
+
+// DeleteTenantQuota handles DELETE /tenant-quotas/:tenant
+// Removes a tenant's quota; the default quota applies to it from then on, if one is set
+func (h *Handler) DeleteTenantQuota(c *gin.Context) {
+	quotas, ok := h.tenantQuotas(c)
+	if !ok {
+		return
+	}
+
+	tenant := c.Param("tenant")
+	if err := quotas.DeleteTenantQuota(c.Request.Context(), tenant); err != nil {
+		if errors.Is(err, storage.ErrTenantQuotaNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Tenant quota not found",
+			})
+			return
+		}
+
+		slog.Error("Failed to delete tenant quota", "tenant", tenant, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete tenant quota",
+		})
+		return
+	}
+
+	slog.Info("Tenant quota removed", "tenant", tenant)
+	c.Status(http.StatusNoContent)
+}
+
+// tenantQuotas returns the store's tenant quotas, answering 501 itself when the backend does not enforce them
+func (h *Handler) tenantQuotas(c *gin.Context) (storage.TenantQuotas, bool) {
+	q, ok := h.store.(storage.TenantQuotas)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, gin.H{
+			"error": "Not supported by the storage backend",
+		})
+	}
+	return q, ok
+}
+
+// quotaExceeded answers 429 with the tenant's quota when err is a *storage.QuotaExceededError
+func quotaExceeded(c *gin.Context, err error) bool {
+	var exceeded *storage.QuotaExceededError
+	if !errors.As(err, &exceeded) {
+		return false
+	}
+
+	slog.Warn("Tenant quota exceeded", "tenant", exceeded.Tenant, "max_queued", exceeded.MaxQueued, "queued", exceeded.Queued)
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":        "Tenant quota exceeded",
+		"tenant":       exceeded.Tenant,
+		"max_queued":   exceeded.MaxQueued,
+		"queued_tasks": exceeded.Queued,
+	})
+	return true
+}
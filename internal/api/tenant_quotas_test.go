@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestTenantQuotasUnsupported keeps quota configuration refused on stores without storage.TenantQuotas,
+// such as Redis, whose claim would otherwise ignore max_running
+func TestTenantQuotasUnsupported(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := NewHandler(&createStore{}, Config{})
+	r := gin.New()
+	r.GET("/api/tenant-quotas", h.ListTenantQuotas)
+	r.PUT("/api/tenant-quotas/:tenant", h.SetTenantQuota)
+	r.DELETE("/api/tenant-quotas/:tenant", h.DeleteTenantQuota)
+
+	tests := []struct {
+		method, path, body string
+	}{
+		{http.MethodGet, "/api/tenant-quotas", ""},
+		{http.MethodPut, "/api/tenant-quotas/acme", `{"max_running": 5}`},
+		{http.MethodDelete, "/api/tenant-quotas/acme", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			if w.Code != http.StatusNotImplemented {
+				t.Errorf("%s %s = %d %s, want 501", tt.method, tt.path, w.Code, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// ListWorkerSettings handles GET /worker-settings
+// Returns every runtime override of worker configuration
+func (h *Handler) ListWorkerSettings(c *gin.Context) {
+	settings, err := h.store.ListWorkerSettings(c.Request.Context())
+	if err != nil {
+		slog.Error("Failed to list worker settings", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve worker settings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.WorkerSettingsResponse{
+		Settings: settings,
+	})
+}
+
+// SetWorkerSettings handles PUT /worker-settings/:worker_id
+// Overrides the concurrency and poll interval of a running worker, or of every worker for "default"
+func (h *Handler) SetWorkerSettings(c *gin.Context) {
+	workerID := c.Param("worker_id")
+
+	var req models.SetWorkerSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Warn("Invalid request body", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.store.SetWorkerSettings(c.Request.Context(), workerID, req); err != nil {
+		slog.Error("Failed to set worker settings", "worker_id", workerID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to set worker settings",
+		})
+		return
+	}
+
+	slog.Info("Worker settings set", "worker_id", workerID,
+		"max_concurrency", req.MaxConcurrency, "poll_interval_seconds", req.PollIntervalSeconds)
+	c.JSON(http.StatusOK, gin.H{
+		"worker_id":             workerID,
+		"max_concurrency":       req.MaxConcurrency,
+		"poll_interval_seconds": req.PollIntervalSeconds,
+	})
+}
+
+// DeleteWorkerSettings handles DELETE /worker-settings/:worker_id
+// Restores the configured values on the next settings reload
+func (h *Handler) DeleteWorkerSettings(c *gin.Context) {
+	workerID := c.Param("worker_id")
+
+	if err := h.store.DeleteWorkerSettings(c.Request.Context(), workerID); err != nil {
+		if errors.Is(err, storage.ErrWorkerSettingsNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Worker settings not found",
+			})
+			return
+		}
+
+		slog.Error("Failed to delete worker settings", "worker_id", workerID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete worker settings",
+		})
+		return
+	}
+
+	slog.Info("Worker settings removed", "worker_id", workerID)
+	c.Status(http.StatusNoContent)
+}
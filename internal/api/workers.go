@@ -0,0 +1,61 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// workerStaleAfter is how long a worker may miss heartbeats before it is reported as stale
+const workerStaleAfter = 1 * time.Minute
+
+// ListWorkers handles GET /workers
+// Returns registered workers with their load and the number of task locks each holds
+func (h *Handler) ListWorkers(c *gin.Context) {
+	workers, err := h.store.ListWorkers(c.Request.Context(), workerStaleAfter)
+	if err != nil {
+		slog.Error("Failed to list workers", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve workers",
+		})
+		return
+	}
+
+	response := models.WorkersResponse{Workers: workers}
+	for _, w := range workers {
+		if w.Status == models.WorkerStatusActive {
+			response.Active++
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetWorkerStats handles GET /workers/:id/stats
+// Returns tasks processed, failure rate and average duration since the worker started, with its current load
+// Totals come from the worker's heartbeats, so they lag by up to one heartbeat interval
+func (h *Handler) GetWorkerStats(c *gin.Context) {
+	workers, err := h.store.ListWorkers(c.Request.Context(), workerStaleAfter)
+	if err != nil {
+		slog.Error("Failed to list workers", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve worker stats",
+		})
+		return
+	}
+
+	id := c.Param("id")
+	for _, w := range workers {
+		if w.ID == id {
+			c.JSON(http.StatusOK, models.NewWorkerStatsResponse(w))
+			return
+		}
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{
+		"error": "Worker not found",
+	})
+}
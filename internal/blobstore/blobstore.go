@@ -0,0 +1,71 @@
+// Package blobstore reads and writes objects in an object store: Amazon S3 (or a compatible store such as MinIO),
+// Google Cloud Storage, or a local directory for development and tests
+// Keys are slash-separated paths within one bucket; callers never name the bucket themselves
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// ErrNotFound is returned by Get when no object has the key
+var ErrNotFound = errors.New("object not found")
+
+// Store is one bucket of an object store
+type Store interface {
+	// Get opens the object at key; the caller closes its body
+	Get(ctx context.Context, key string) (*Object, error)
+	// Put stores size bytes of body at key, replacing any object there
+	// body is seekable so that a failed upload can be retried from the start
+	Put(ctx context.Context, key string, body io.ReadSeeker, size int64, contentType string) error
+	// URL returns where the object at key lives, e.g. s3://bucket/key, for results and logs
+	URL(key string) string
+}
+
+// Object is an object being read
+type Object struct {
+	Body        io.ReadCloser
+	Size        int64 // -1 when the store did not say
+	ContentType string
+}
+
+// ValidateKey rejects keys that are empty, absolute, or step out of their prefix with ..
+func ValidateKey(key string) error {
+	if key == "" {
+		return errors.New("key is empty")
+	}
+	if strings.HasPrefix(key, "/") || strings.Contains(key, "\\") || path.Clean(key) != key || key == "." ||
+		key == ".." || strings.HasPrefix(key, "../") {
+		return fmt.Errorf("invalid key: %q", key)
+	}
+	return nil
+}
+
+// WithPrefix returns a store keeping every key under prefix, e.g. taskqueue/ in a shared bucket
+func WithPrefix(store Store, prefix string) Store {
+	if prefix == "" {
+		return store
+	}
+	return &prefixed{store: store, prefix: strings.TrimSuffix(prefix, "/") + "/"}
+}
+
+type prefixed struct {
+	store  Store
+	prefix string
+}
+
+func (p *prefixed) Get(ctx context.Context, key string) (*Object, error) {
+	return p.store.Get(ctx, p.prefix+key)
+}
+
+func (p *prefixed) Put(ctx context.Context, key string, body io.ReadSeeker, size int64, contentType string) error {
+	return p.store.Put(ctx, p.prefix+key, body, size, contentType)
+}
+
+func (p *prefixed) URL(key string) string {
+	return p.store.URL(p.prefix + key)
+}
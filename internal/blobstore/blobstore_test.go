@@ -0,0 +1,153 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateKey(t *testing.T) {
+	for _, key := range []string{"report.csv", "uploads/2024/report.csv", "a..b/c"} {
+		if err := ValidateKey(key); err != nil {
+			t.Errorf("ValidateKey(%q) error = %v", key, err)
+		}
+	}
+	for _, key := range []string{"", ".", "..", "../etc/passwd", "a/../../b", "/abs", "a//b", "a/", "a\\b"} {
+		if err := ValidateKey(key); err == nil {
+			t.Errorf("ValidateKey(%q) accepted an invalid key", key)
+		}
+	}
+}
+
+func TestDir(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefixed := WithPrefix(store, "jobs/")
+	ctx := context.Background()
+
+	if err := prefixed.Put(ctx, "out/result.json", strings.NewReader(`{"ok":true}`), 11, "application/json"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "jobs", "out", "result.json")); err != nil || string(data) != `{"ok":true}` {
+		t.Fatalf("stored file = %q, %v", data, err)
+	}
+
+	object, err := prefixed.Get(ctx, "out/result.json")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer func() { _ = object.Body.Close() }()
+	data, _ := io.ReadAll(object.Body)
+	if string(data) != `{"ok":true}` || object.Size != 11 || object.ContentType != "application/json" {
+		t.Errorf("Get() = %q, size %d, type %q", data, object.Size, object.ContentType)
+	}
+
+	if got, want := prefixed.URL("out/result.json"), "file://"+filepath.ToSlash(root)+"/jobs/out/result.json"; got != want {
+		t.Errorf("URL() = %q, want %q", got, want)
+	}
+
+	if _, err := prefixed.Get(ctx, "out/missing.json"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Get(ctx, "../outside"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(../outside) error = %v, want an invalid key", err)
+	}
+}
+
+func TestGCS(t *testing.T) {
+	objects := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer token-1" {
+			t.Errorf("Authorization = %q", got)
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/upload/storage/v1/b/tasks/o":
+			body, _ := io.ReadAll(r.Body)
+			objects[r.URL.Query().Get("name")] = string(body)
+			_, _ = io.WriteString(w, `{}`)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.EscapedPath(), "/storage/v1/b/tasks/o/"):
+			key := strings.TrimPrefix(r.URL.Path, "/storage/v1/b/tasks/o/")
+			body, ok := objects[key]
+			if !ok || r.URL.Query().Get("alt") != "media" {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = io.WriteString(w, `{"error":{"code":404,"message":"No such object"}}`)
+				return
+			}
+			_, _ = io.WriteString(w, body)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, `{"error":{"code":403,"message":"no access"}}`)
+		}
+	}))
+	defer server.Close()
+
+	store := &GCS{
+		bucket:   "tasks",
+		endpoint: server.URL,
+		token:    func(context.Context) (string, error) { return "token-1", nil },
+		client:   server.Client(),
+	}
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "out/a b.txt", strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if objects["out/a b.txt"] != "hello" {
+		t.Fatalf("uploaded objects = %v", objects)
+	}
+
+	object, err := store.Get(ctx, "out/a b.txt")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	data, _ := io.ReadAll(object.Body)
+	_ = object.Body.Close()
+	if string(data) != "hello" {
+		t.Errorf("Get() = %q", data)
+	}
+
+	if _, err := store.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+	if got := store.URL("out/a b.txt"); got != "gs://tasks/out/a b.txt" {
+		t.Errorf("URL() = %q", got)
+	}
+
+	store.bucket = "other"
+	if err := store.Put(ctx, "x", strings.NewReader("x"), 1, ""); err == nil || !strings.Contains(err.Error(), "no access") {
+		t.Errorf("Put() to a forbidden bucket error = %v", err)
+	}
+}
+
+func TestMetadataToken(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = io.WriteString(w, `{"access_token":"token-1","expires_in":3599,"token_type":"Bearer"}`)
+	}))
+	defer server.Close()
+
+	token := newMetadataToken(server.URL)
+	for range 2 {
+		got, err := token.get(context.Background())
+		if err != nil || got != "token-1" {
+			t.Fatalf("get() = %q, %v", got, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("metadata server requests = %d, want 1 (cached)", requests)
+	}
+}
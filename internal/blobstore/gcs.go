@@ -0,0 +1,185 @@
+package blobstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// gcsEndpoint is the Cloud Storage JSON API
+const gcsEndpoint = "https://storage.googleapis.com"
+
+// gcsTokenURL is where the metadata server hands out access tokens of the instance's service account,
+// which on GKE is the workload identity of the pod
+const gcsTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// gcsTimeout bounds every metadata request; object transfers are bound by the caller's context only
+const gcsTimeout = 10 * time.Second
+
+// GCSConfig configures a Cloud Storage store
+type GCSConfig struct {
+	Bucket   string
+	Endpoint string // JSON API endpoint of an emulator such as fake-gcs-server, whose requests are not authenticated; empty = Cloud Storage
+}
+
+// GCS stores objects in a Google Cloud Storage bucket through the JSON API
+type GCS struct {
+	bucket   string
+	endpoint string
+	token    func(ctx context.Context) (string, error)
+	client   *http.Client
+}
+
+// NewGCS creates a Cloud Storage store authenticating as the service account of the instance or pod
+func NewGCS(cfg GCSConfig) (*GCS, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("bucket is required")
+	}
+
+	g := &GCS{bucket: cfg.Bucket, endpoint: cfg.Endpoint, client: &http.Client{}}
+	if g.endpoint == "" {
+		g.endpoint = gcsEndpoint
+		g.token = newMetadataToken(gcsTokenURL).get
+	}
+	return g, nil
+}
+
+// Get downloads the object at key
+func (g *GCS) Get(ctx context.Context, key string) (*Object, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+
+	target := g.endpoint + "/storage/v1/b/" + url.PathEscape(g.bucket) + "/o/" + url.PathEscape(key) + "?alt=media"
+	resp, err := g.do(ctx, http.MethodGet, target, nil, -1, "")
+	if err != nil {
+		return nil, fmt.Errorf("get gs://%s/%s: %w", g.bucket, key, err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%w: gs://%s/%s", ErrNotFound, g.bucket, key)
+	case resp.StatusCode != http.StatusOK:
+		defer func() { _ = resp.Body.Close() }()
+		return nil, fmt.Errorf("get gs://%s/%s: %w", g.bucket, key, gcsError(resp))
+	}
+	return &Object{Body: resp.Body, Size: resp.ContentLength, ContentType: resp.Header.Get("Content-Type")}, nil
+}
+
+// URL returns the gs:// URL of key
+func (g *GCS) URL(key string) string {
+	return "gs://" + g.bucket + "/" + key
+}
+
+// Put uploads body in a single media upload request
+func (g *GCS) Put(ctx context.Context, key string, body io.ReadSeeker, size int64, contentType string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	query := url.Values{"uploadType": {"media"}, "name": {key}}
+	target := g.endpoint + "/upload/storage/v1/b/" + url.PathEscape(g.bucket) + "/o?" + query.Encode()
+	resp, err := g.do(ctx, http.MethodPost, target, body, size, contentType)
+	if err != nil {
+		return fmt.Errorf("put gs://%s/%s: %w", g.bucket, key, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("put gs://%s/%s: %w", g.bucket, key, gcsError(resp))
+	}
+	return nil
+}
+
+func (g *GCS) do(ctx context.Context, method, target string, body io.Reader, size int64, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+		req.Header.Set("Content-Type", contentType)
+	}
+	if g.token != nil {
+		token, err := g.token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return g.client.Do(req)
+}
+
+// gcsError returns the message of a JSON API error response
+func gcsError(resp *http.Response) error {
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var decoded struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(detail, &decoded) == nil && decoded.Error.Message != "" {
+		return fmt.Errorf("cloud storage answered %d: %s", resp.StatusCode, decoded.Error.Message)
+	}
+	return fmt.Errorf("cloud storage answered %d: %s", resp.StatusCode, detail)
+}
+
+// metadataToken caches the access token of the metadata server until shortly before it expires
+type metadataToken struct {
+	url    string
+	client *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func newMetadataToken(tokenURL string) *metadataToken {
+	return &metadataToken{url: tokenURL, client: &http.Client{Timeout: gcsTimeout}}
+}
+
+func (m *metadataToken) get(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.token != "" && time.Now().Before(m.expires) {
+		return m.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("get access token: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("get access token: metadata server answered %d: %s", resp.StatusCode, detail)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("decode access token: %w", err)
+	}
+
+	// Renew a minute early, so no request goes out with a token about to expire
+	m.token = token.AccessToken
+	m.expires = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return m.token, nil
+}
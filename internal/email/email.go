@@ -0,0 +1,131 @@
+// Package email delivers the messages of send_email tasks
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Message is one email to deliver
+// Text and HTML are alternative bodies; at least one is set
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Validate checks the addresses and rejects header injection, returning an error retrying cannot fix
+func (m *Message) Validate() error {
+	if _, err := mail.ParseAddress(m.From); err != nil {
+		return fmt.Errorf("invalid from address %q: %w", m.From, err)
+	}
+	if len(m.To) == 0 {
+		return errors.New("at least one recipient is required")
+	}
+	for _, to := range m.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", to, err)
+		}
+	}
+	if strings.ContainsAny(m.Subject, "\r\n") {
+		return errors.New("subject must not contain line breaks")
+	}
+	return nil
+}
+
+// addresses returns the bare addresses of list, without display names
+func addresses(list []string) []string {
+	bare := make([]string, len(list))
+	for i, entry := range list {
+		bare[i] = entry
+		if addr, err := mail.ParseAddress(entry); err == nil {
+			bare[i] = addr.Address
+		}
+	}
+	return bare
+}
+
+// mimeBytes renders m as an RFC 5322 message, multipart/alternative when it has both bodies
+func (m *Message) mimeBytes(now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	domain := "localhost"
+	if addr, err := mail.ParseAddress(m.From); err == nil {
+		if _, host, ok := strings.Cut(addr.Address, "@"); ok {
+			domain = host
+		}
+	}
+
+	header("From", m.From)
+	header("To", strings.Join(m.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", "<"+hex.EncodeToString(id)+"@"+domain+">")
+	header("MIME-Version", "1.0")
+
+	if m.Text != "" && m.HTML != "" {
+		parts := multipart.NewWriter(&buf)
+		header("Content-Type", `multipart/alternative; boundary="`+parts.Boundary()+`"`)
+		buf.WriteString("\r\n")
+		for _, body := range []struct{ contentType, content string }{
+			{"text/plain; charset=utf-8", m.Text},
+			{"text/html; charset=utf-8", m.HTML},
+		} {
+			part, err := parts.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {body.contentType},
+				"Content-Transfer-Encoding": {"quoted-printable"},
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := writeQuotedPrintable(part, body.content); err != nil {
+				return nil, err
+			}
+		}
+		if err := parts.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	contentType, content := "text/plain; charset=utf-8", m.Text
+	if m.HTML != "" {
+		contentType, content = "text/html; charset=utf-8", m.HTML
+	}
+	header("Content-Type", contentType)
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+	if err := writeQuotedPrintable(&buf, content); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeQuotedPrintable writes content quoted-printable encoded, so long lines and non-ASCII survive transport
+func writeQuotedPrintable(w io.Writer, content string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
@@ -0,0 +1,72 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// mailgunAPIBase is Mailgun's US API; EU domains use https://api.eu.mailgun.net
+const mailgunAPIBase = "https://api.mailgun.net"
+
+// Mailgun delivers messages through the Mailgun messages API of one sending domain
+type Mailgun struct {
+	apiKey string
+	url    string
+	client *http.Client
+}
+
+// NewMailgun creates a Mailgun sender for domain; an empty apiBase uses the US API
+func NewMailgun(apiKey, domain, apiBase string) *Mailgun {
+	if apiBase == "" {
+		apiBase = mailgunAPIBase
+	}
+	return &Mailgun{
+		apiKey: apiKey,
+		url:    strings.TrimSuffix(apiBase, "/") + "/v3/" + url.PathEscape(domain) + "/messages",
+		client: newHTTPClient(),
+	}
+}
+
+// Send delivers msg, queued by Mailgun with 200 OK
+func (m *Mailgun) Send(ctx context.Context, msg Message) error {
+	if err := msg.Validate(); err != nil {
+		return models.Permanent(err)
+	}
+
+	form := url.Values{}
+	form.Set("from", msg.From)
+	for _, to := range msg.To {
+		form.Add("to", to)
+	}
+	form.Set("subject", msg.Subject)
+	// Mailgun requires at least one body
+	if msg.Text != "" || msg.HTML == "" {
+		form.Set("text", orSpace(msg.Text))
+	}
+	if msg.HTML != "" {
+		form.Set("html", msg.HTML)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth("api", m.apiKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Mailgun request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		return apiError("Mailgun", resp)
+	}
+	return nil
+}
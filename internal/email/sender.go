@@ -0,0 +1,90 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// Sender delivers messages through one email provider
+// Errors retrying cannot fix, such as a rejected recipient, are marked with models.Permanent
+// Problems with the provider account or credentials stay retryable, so fixing the configuration recovers the tasks
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Simulated only logs messages, for local development and tests
+// Deliveries take 3 seconds and one in four fails
+type Simulated struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewSimulated creates a simulated sender
+func NewSimulated() *Simulated {
+	return &Simulated{rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// Send pretends to deliver msg
+func (s *Simulated) Send(ctx context.Context, msg Message) error {
+	slog.Info("Sending email (simulated)",
+		"to", msg.To,
+		"subject", msg.Subject,
+		"body_length", len(msg.Text)+len(msg.HTML),
+	)
+
+	// Simulate 25% failure rate
+	s.mu.Lock()
+	fail := s.rng.Intn(4) == 0
+	s.mu.Unlock()
+	if fail {
+		slog.Warn("Email sending failed (simulated)", "to", msg.To)
+		return fmt.Errorf("email delivery failed: SMTP connection timeout")
+	}
+
+	// Simulate email sending with cancellation support
+	select {
+	case <-time.After(3 * time.Second):
+		return nil
+	case <-ctx.Done():
+		slog.Warn("Email sending cancelled", "to", msg.To, "error", ctx.Err())
+		return ctx.Err()
+	}
+}
+
+// httpTimeout bounds every request to an HTTP email API; a task's earlier deadline still applies
+const httpTimeout = 30 * time.Second
+
+// newHTTPClient returns the client HTTP email APIs are called with
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: httpTimeout}
+}
+
+// apiError classifies the failed response of an HTTP email API
+// 429 is retried after Retry-After, other 4xx replies except authentication failures are permanent
+func apiError(provider string, resp *http.Response) error {
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err := fmt.Errorf("%s answered %d: %s", provider, resp.StatusCode, detail)
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && seconds > 0 {
+			return models.RetryAfter(err, time.Duration(seconds)*time.Second)
+		}
+		return err
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return err
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return models.Permanent(err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,83 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+func TestSendGrid(t *testing.T) {
+	var got sendGridRequest
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sg-key" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sender := NewSendGrid("sg-key")
+	sender.url = server.URL
+	msg := Message{From: "Queue <queue@example.com>", To: []string{"jane@example.com"}, Subject: "Hi", HTML: "<p>Hi</p>"}
+
+	if err := sender.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got.From.Email != "queue@example.com" || got.From.Name != "Queue" || len(got.Personalizations[0].To) != 1 {
+		t.Errorf("request = %+v", got)
+	}
+	if len(got.Content) != 1 || got.Content[0].Type != "text/html" {
+		t.Errorf("content = %+v, want only text/html", got.Content)
+	}
+
+	status = http.StatusBadRequest
+	if err := sender.Send(context.Background(), msg); !models.IsPermanent(err) {
+		t.Errorf("Send() on 400 = %v, want a permanent error", err)
+	}
+
+	status = http.StatusTooManyRequests
+	err := sender.Send(context.Background(), msg)
+	if delay, ok := models.RetryDelay(err); models.IsPermanent(err) || !ok || delay != 7*time.Second {
+		t.Errorf("Send() on 429 = %v, want a retry after 7s", err)
+	}
+
+	status = http.StatusUnauthorized
+	if err := sender.Send(context.Background(), msg); err == nil || models.IsPermanent(err) {
+		t.Errorf("Send() on 401 = %v, want a retryable error", err)
+	}
+}
+
+func TestMailgun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, key, _ := r.BasicAuth()
+		if r.URL.Path != "/v3/mg.example.com/messages" || user != "api" || key != "mg-key" {
+			t.Errorf("request to %s as %s:%s", r.URL.Path, user, key)
+		}
+		if r.FormValue("to") != "jane@example.com" || r.FormValue("text") != "plain" || r.FormValue("html") != "" {
+			t.Errorf("form = %v", r.Form)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := NewMailgun("mg-key", "mg.example.com", server.URL)
+	msg := Message{From: "queue@example.com", To: []string{"jane@example.com"}, Subject: "Hi", Text: "plain"}
+	if err := sender.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	msg.To = []string{"not an address"}
+	if err := sender.Send(context.Background(), msg); !models.IsPermanent(err) {
+		t.Errorf("Send(invalid recipient) = %v, want a permanent error", err)
+	}
+}
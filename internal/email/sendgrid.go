@@ -0,0 +1,111 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// sendGridURL is the SendGrid v3 mail send endpoint
+const sendGridURL = "https://api.sendgrid.com/v3/mail/send"
+
+// SendGrid delivers messages through the SendGrid v3 API
+type SendGrid struct {
+	apiKey string
+	url    string
+	client *http.Client
+}
+
+// NewSendGrid creates a SendGrid sender authenticating with apiKey
+func NewSendGrid(apiKey string) *SendGrid {
+	return &SendGrid{apiKey: apiKey, url: sendGridURL, client: newHTTPClient()}
+}
+
+// sendGridAddress is an address in a SendGrid request
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+// sendGridContent is one body of a SendGrid request
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// sendGridRequest is the body of a mail send request
+type sendGridRequest struct {
+	Personalizations []struct {
+		To []sendGridAddress `json:"to"`
+	} `json:"personalizations"`
+	From    sendGridAddress   `json:"from"`
+	Subject string            `json:"subject"`
+	Content []sendGridContent `json:"content"`
+}
+
+// Send delivers msg, answered with 202 Accepted
+func (s *SendGrid) Send(ctx context.Context, msg Message) error {
+	if err := msg.Validate(); err != nil {
+		return models.Permanent(err)
+	}
+
+	req := sendGridRequest{From: sendGridAddressOf(msg.From), Subject: msg.Subject}
+	req.Personalizations = make([]struct {
+		To []sendGridAddress `json:"to"`
+	}, 1)
+	for _, to := range msg.To {
+		req.Personalizations[0].To = append(req.Personalizations[0].To, sendGridAddressOf(to))
+	}
+	// SendGrid requires a non-empty body and text/plain before text/html
+	if msg.Text != "" || msg.HTML == "" {
+		req.Content = append(req.Content, sendGridContent{Type: "text/plain", Value: orSpace(msg.Text)})
+	}
+	if msg.HTML != "" {
+		req.Content = append(req.Content, sendGridContent{Type: "text/html", Value: msg.HTML})
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+s.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("SendGrid request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		return apiError("SendGrid", resp)
+	}
+	return nil
+}
+
+// sendGridAddressOf splits a validated address into its email and display name
+func sendGridAddressOf(value string) sendGridAddress {
+	addr, err := mail.ParseAddress(value)
+	if err != nil {
+		return sendGridAddress{Email: value}
+	}
+	return sendGridAddress{Email: addr.Address, Name: addr.Name}
+}
+
+// orSpace returns value, or a single space for an empty one
+func orSpace(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return " "
+	}
+	return value
+}
@@ -0,0 +1,80 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+// SES delivers messages through Amazon SES
+type SES struct {
+	client           *sesv2.Client
+	configurationSet string
+}
+
+// NewSES creates an SES sender with the default credential chain (environment, shared config, IRSA or the instance role)
+// An empty region uses the configured one; an empty configurationSet sends without one
+func NewSES(ctx context.Context, region, configurationSet string) (*SES, error) {
+	var opts []func(*config.LoadOptions) error
+	if region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("load aws config: %w", err)
+	}
+	return &SES{client: sesv2.NewFromConfig(cfg), configurationSet: configurationSet}, nil
+}
+
+// Send delivers msg as a simple message, SES building the MIME structure
+func (s *SES) Send(ctx context.Context, msg Message) error {
+	if err := msg.Validate(); err != nil {
+		return models.Permanent(err)
+	}
+
+	body := &types.Body{}
+	if msg.Text != "" || msg.HTML == "" {
+		body.Text = &types.Content{Data: aws.String(msg.Text), Charset: aws.String("UTF-8")}
+	}
+	if msg.HTML != "" {
+		body.Html = &types.Content{Data: aws.String(msg.HTML), Charset: aws.String("UTF-8")}
+	}
+
+	input := &sesv2.SendEmailInput{
+		FromEmailAddress: aws.String(msg.From),
+		Destination:      &types.Destination{ToAddresses: msg.To},
+		Content: &types.EmailContent{
+			Simple: &types.Message{
+				Subject: &types.Content{Data: aws.String(msg.Subject), Charset: aws.String("UTF-8")},
+				Body:    body,
+			},
+		},
+	}
+	if s.configurationSet != "" {
+		input.ConfigurationSetName = aws.String(s.configurationSet)
+	}
+
+	if _, err := s.client.SendEmail(ctx, input); err != nil {
+		return sesError(err)
+	}
+	return nil
+}
+
+// sesError marks rejections of the message itself as permanent
+func sesError(err error) error {
+	var (
+		rejected *types.MessageRejected
+		bad      *types.BadRequestException
+	)
+	if errors.As(err, &rejected) || errors.As(err, &bad) {
+		return models.Permanent(fmt.Errorf("SES rejected the message: %w", err))
+	}
+	return fmt.Errorf("SES delivery failed: %w", err)
+}
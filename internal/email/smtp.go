@@ -0,0 +1,239 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// TLS modes of an SMTP connection
+const (
+	SMTPTLSStartTLS = "starttls" // upgrade a plain connection with STARTTLS, usually port 587
+	SMTPTLSImplicit = "tls"      // TLS from the first byte, usually port 465
+	SMTPTLSNone     = "none"     // plain text, only for local relays and test servers
+)
+
+// SMTPConfig configures the SMTP relay messages are submitted to
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // empty = no authentication
+	Password string
+	TLS      string        // one of the SMTPTLS modes, empty = starttls
+	Timeout  time.Duration // per message, including connecting; shortened by the task's deadline (0 = 30s)
+	PoolSize int           // idle connections kept for reuse (0 = 2)
+}
+
+// SMTP submits messages to a relay, reusing connections between messages
+type SMTP struct {
+	config SMTPConfig
+	idle   chan *smtpConn
+}
+
+// smtpConn is an authenticated connection and the client speaking over it
+type smtpConn struct {
+	conn   net.Conn
+	client *smtp.Client
+}
+
+// NewSMTP creates a sender for config; connections are opened on first use
+func NewSMTP(config SMTPConfig) (*SMTP, error) {
+	if config.Host == "" {
+		return nil, errors.New("SMTP host is required")
+	}
+	switch config.TLS {
+	case "":
+		config.TLS = SMTPTLSStartTLS
+	case SMTPTLSStartTLS, SMTPTLSImplicit, SMTPTLSNone:
+	default:
+		return nil, fmt.Errorf("invalid SMTP TLS mode %q", config.TLS)
+	}
+	if config.Port == 0 {
+		config.Port = 587
+		if config.TLS == SMTPTLSImplicit {
+			config.Port = 465
+		}
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.PoolSize <= 0 {
+		config.PoolSize = 2
+	}
+
+	return &SMTP{config: config, idle: make(chan *smtpConn, config.PoolSize)}, nil
+}
+
+// Send submits msg, failing at the earlier of ctx's deadline and the configured timeout
+// Messages rejected with a 5xx reply and invalid messages are permanent; connection problems and 4xx replies are retryable
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	if err := msg.Validate(); err != nil {
+		return models.Permanent(err)
+	}
+	body, err := msg.mimeBytes(time.Now())
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(s.config.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	// Connection and authentication failures are retried, so fixing the relay configuration recovers the tasks
+	c, err := s.conn(ctx, deadline)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("SMTP connection failed: %w", err)
+	}
+
+	// Cancellation unblocks any read or write in progress
+	stop := context.AfterFunc(ctx, func() { _ = c.conn.SetDeadline(time.Now()) })
+	err = c.submit(msg, body)
+	if !stop() || err != nil {
+		c.close()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return smtpError(err)
+	}
+
+	s.release(c)
+	return nil
+}
+
+// Close closes the idle connections
+func (s *SMTP) Close() error {
+	for {
+		select {
+		case c := <-s.idle:
+			_ = c.client.Quit()
+			c.close()
+		default:
+			return nil
+		}
+	}
+}
+
+// conn returns an idle connection that still answers, or dials a new one
+func (s *SMTP) conn(ctx context.Context, deadline time.Time) (*smtpConn, error) {
+	for {
+		select {
+		case c := <-s.idle:
+			_ = c.conn.SetDeadline(deadline)
+			// Relays drop idle connections, so check before reusing one
+			if err := c.client.Noop(); err != nil {
+				c.close()
+				continue
+			}
+			return c, nil
+		default:
+			return s.dial(ctx, deadline)
+		}
+	}
+}
+
+// release keeps c for the next message, closing it when the pool is full
+func (s *SMTP) release(c *smtpConn) {
+	_ = c.conn.SetDeadline(time.Time{})
+	select {
+	case s.idle <- c:
+	default:
+		_ = c.client.Quit()
+		c.close()
+	}
+}
+
+// dial connects, secures and authenticates a new connection
+func (s *SMTP) dial(ctx context.Context, deadline time.Time) (*smtpConn, error) {
+	dialer := &net.Dialer{Deadline: deadline}
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	tlsConfig := &tls.Config{ServerName: s.config.Host, MinVersion: tls.VersionTLS12}
+
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.SetDeadline(deadline)
+
+	if s.config.TLS == SMTPTLSImplicit {
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	client, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	c := &smtpConn{conn: conn, client: client}
+
+	if s.config.TLS == SMTPTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			c.close()
+			return nil, errors.New("SMTP server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			c.close()
+			return nil, err
+		}
+	}
+
+	if s.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)); err != nil {
+			c.close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// submit runs one mail transaction
+func (c *smtpConn) submit(msg Message, body []byte) error {
+	if err := c.client.Mail(addresses([]string{msg.From})[0]); err != nil {
+		return err
+	}
+	for _, to := range addresses(msg.To) {
+		if err := c.client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// close drops the connection without a QUIT
+func (c *smtpConn) close() {
+	_ = c.client.Close()
+}
+
+// smtpError marks 5xx replies, which the relay will give again, as permanent
+func smtpError(err error) error {
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return models.Permanent(fmt.Errorf("SMTP rejected the message: %w", err))
+	}
+	return fmt.Errorf("SMTP delivery failed: %w", err)
+}
@@ -0,0 +1,129 @@
+package email
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// fakeRelay is a minimal SMTP server that rejects one recipient and records the messages it accepts
+type fakeRelay struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	conns    int
+	messages []string
+}
+
+func newFakeRelay(t *testing.T) *fakeRelay {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &fakeRelay{listener: listener}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			r.mu.Lock()
+			r.conns++
+			r.mu.Unlock()
+			go r.serve(conn)
+		}
+	}()
+	return r
+}
+
+func (r *fakeRelay) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 fake ESMTP")
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb := strings.ToUpper(strings.Fields(line + " ")[0])
+		switch verb {
+		case "EHLO", "HELO":
+			_ = tp.PrintfLine("250 fake")
+		case "MAIL", "NOOP", "RSET":
+			_ = tp.PrintfLine("250 OK")
+		case "RCPT":
+			if strings.Contains(line, "nobody@") {
+				_ = tp.PrintfLine("550 No such user")
+				continue
+			}
+			_ = tp.PrintfLine("250 OK")
+		case "DATA":
+			_ = tp.PrintfLine("354 Go ahead")
+			lines, err := tp.ReadDotLines()
+			if err != nil {
+				return
+			}
+			r.mu.Lock()
+			r.messages = append(r.messages, strings.Join(lines, "\n"))
+			r.mu.Unlock()
+			_ = tp.PrintfLine("250 Queued")
+		case "QUIT":
+			_ = tp.PrintfLine("221 Bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 Unknown command")
+		}
+	}
+}
+
+func TestSMTPSend(t *testing.T) {
+	relay := newFakeRelay(t)
+	host, port, _ := net.SplitHostPort(relay.listener.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+
+	sender, err := NewSMTP(SMTPConfig{Host: host, Port: portNum, TLS: SMTPTLSNone, Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = sender.Close() }()
+
+	ctx := context.Background()
+	msg := Message{From: "Queue <queue@example.com>", To: []string{"jane@example.com"}, Subject: "Grüße", Text: "plain", HTML: "<p>html</p>"}
+	for i := 0; i < 2; i++ {
+		if err := sender.Send(ctx, msg); err != nil {
+			t.Fatalf("Send() #%d error = %v", i, err)
+		}
+	}
+
+	relay.mu.Lock()
+	conns, messages := relay.conns, relay.messages
+	relay.mu.Unlock()
+	if conns != 1 {
+		t.Errorf("relay saw %d connections, want 1 reused", conns)
+	}
+	if len(messages) != 2 || !strings.Contains(messages[0], "multipart/alternative") || !strings.Contains(messages[0], "=?utf-8?q?Gr=C3=BC=C3=9Fe?=") {
+		t.Errorf("messages = %q", messages)
+	}
+
+	rejected := msg
+	rejected.To = []string{"nobody@example.com"}
+	if err := sender.Send(ctx, rejected); !models.IsPermanent(err) {
+		t.Errorf("Send(rejected recipient) = %v, want a permanent error", err)
+	}
+
+	invalid := msg
+	invalid.Subject = "line\r\nBcc: everyone@example.com"
+	if err := sender.Send(ctx, invalid); !models.IsPermanent(err) {
+		t.Errorf("Send(header injection) = %v, want a permanent error", err)
+	}
+}
@@ -0,0 +1,171 @@
+package email
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Template files are named <name>.subject.tmpl, <name>.txt.tmpl and <name>.html.tmpl
+const (
+	templateSubjectExt = ".subject.tmpl"
+	templateTextExt    = ".txt.tmpl"
+	templateHTMLExt    = ".html.tmpl"
+)
+
+// templateNamePattern restricts template names to what is safe in a file name
+var templateNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ErrUnknownTemplate is returned when a payload names a template that was not loaded
+var ErrUnknownTemplate = errors.New("unknown email template")
+
+// Templates is a set of named email templates
+// The HTML body is rendered with html/template, so variables are escaped for their context;
+// the subject and the plain body are rendered with text/template
+// A variable the template uses but the payload does not set is an error, not an empty string
+type Templates struct {
+	byName map[string]*emailTemplate
+}
+
+type emailTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// LoadTemplates parses every template in dir
+// A template needs an HTML or a plain body; its subject file is optional
+// Any file that fails to parse fails the whole load, so broken templates are caught at startup
+func LoadTemplates(dir string) (*Templates, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read email templates: %w", err)
+	}
+
+	t := &Templates{byName: map[string]*emailTemplate{}}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name, ext, ok := splitTemplateFile(entry.Name())
+		if !ok {
+			continue
+		}
+		if !templateNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("email template %s: name must be lowercase letters, digits, - and _", entry.Name())
+		}
+
+		source, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read email template %s: %w", entry.Name(), err)
+		}
+
+		tmpl := t.byName[name]
+		if tmpl == nil {
+			tmpl = &emailTemplate{}
+			t.byName[name] = tmpl
+		}
+
+		switch ext {
+		case templateSubjectExt:
+			tmpl.subject, err = texttemplate.New(entry.Name()).Option("missingkey=error").Parse(strings.TrimSpace(string(source)))
+		case templateTextExt:
+			tmpl.text, err = texttemplate.New(entry.Name()).Option("missingkey=error").Parse(string(source))
+		case templateHTMLExt:
+			tmpl.html, err = htmltemplate.New(entry.Name()).Option("missingkey=error").Parse(string(source))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse email template: %w", err)
+		}
+	}
+
+	for name, tmpl := range t.byName {
+		if tmpl.text == nil && tmpl.html == nil {
+			return nil, fmt.Errorf("email template %s has no %s or %s body", name, templateTextExt, templateHTMLExt)
+		}
+	}
+
+	return t, nil
+}
+
+// splitTemplateFile splits a template file name into the template name and its extension
+func splitTemplateFile(file string) (name, ext string, ok bool) {
+	for _, suffix := range []string{templateSubjectExt, templateTextExt, templateHTMLExt} {
+		if name, found := strings.CutSuffix(file, suffix); found {
+			return name, suffix, true
+		}
+	}
+	return "", "", false
+}
+
+// Names returns the names of the loaded templates, sorted
+func (t *Templates) Names() []string {
+	if t == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(t.byName))
+	for name := range t.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render renders the named template with vars into the subject and bodies of a message
+// The subject stays empty when the template has no subject file
+func (t *Templates) Render(name string, vars map[string]any) (Message, error) {
+	var tmpl *emailTemplate
+	if t != nil {
+		tmpl = t.byName[name]
+	}
+	if tmpl == nil {
+		return Message{}, fmt.Errorf("%w: %q", ErrUnknownTemplate, name)
+	}
+
+	if vars == nil {
+		vars = map[string]any{}
+	}
+
+	var msg Message
+	var err error
+	if tmpl.subject != nil {
+		if msg.Subject, err = execute(tmpl.subject, vars); err != nil {
+			return Message{}, err
+		}
+	}
+	if tmpl.text != nil {
+		if msg.Text, err = execute(tmpl.text, vars); err != nil {
+			return Message{}, err
+		}
+	}
+	if tmpl.html != nil {
+		if msg.HTML, err = execute(tmpl.html, vars); err != nil {
+			return Message{}, err
+		}
+	}
+
+	return msg, nil
+}
+
+// executor is implemented by both text/template and html/template templates
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+func execute(tmpl executor, vars map[string]any) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("render email template: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,93 @@
+package email
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTemplates(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestTemplatesRender(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"welcome.subject.tmpl": "Welcome, {{.name}}\n",
+		"welcome.txt.tmpl":     "Hi {{.name}}",
+		"welcome.html.tmpl":    `<p>Hi {{.name}}</p><a href="/u?n={{.name}}">profile</a>`,
+		"receipt.txt.tmpl":     "Total: {{.total}}",
+		"README.md":            "not a template",
+	})
+
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplates: %v", err)
+	}
+	if got := strings.Join(templates.Names(), ","); got != "receipt,welcome" {
+		t.Errorf("Names() = %s, want receipt,welcome", got)
+	}
+
+	msg, err := templates.Render("welcome", map[string]any{"name": "<Jane & Co>"})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if msg.Subject != "Welcome, <Jane & Co>" {
+		t.Errorf("subject = %q", msg.Subject)
+	}
+	if msg.Text != "Hi <Jane & Co>" {
+		t.Errorf("text = %q", msg.Text)
+	}
+	want := `<p>Hi &lt;Jane &amp; Co&gt;</p><a href="/u?n=%3cJane%20%26%20Co%3e">profile</a>`
+	if msg.HTML != want {
+		t.Errorf("html = %q, want %q", msg.HTML, want)
+	}
+
+	msg, err = templates.Render("receipt", map[string]any{"total": 42})
+	if err != nil {
+		t.Fatalf("Render receipt: %v", err)
+	}
+	if msg.Subject != "" || msg.HTML != "" || msg.Text != "Total: 42" {
+		t.Errorf("receipt = %+v", msg)
+	}
+}
+
+func TestTemplatesRenderErrors(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{"welcome.html.tmpl": "<p>Hi {{.name}}</p>"})
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplates: %v", err)
+	}
+
+	if _, err := templates.Render("missing", nil); !errors.Is(err, ErrUnknownTemplate) {
+		t.Errorf("unknown template: err = %v, want ErrUnknownTemplate", err)
+	}
+	if _, err := templates.Render("welcome", nil); err == nil {
+		t.Error("missing variable: expected an error")
+	}
+
+	var unset *Templates
+	if _, err := unset.Render("welcome", nil); !errors.Is(err, ErrUnknownTemplate) {
+		t.Errorf("nil templates: err = %v, want ErrUnknownTemplate", err)
+	}
+}
+
+func TestLoadTemplatesRejectsBrokenFiles(t *testing.T) {
+	for name, files := range map[string]map[string]string{
+		"parse error":  {"welcome.html.tmpl": "<p>{{.name</p>"},
+		"no body":      {"welcome.subject.tmpl": "Hi"},
+		"invalid name": {"Welcome.txt.tmpl": "Hi"},
+	} {
+		if _, err := LoadTemplates(writeTemplates(t, files)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+// Package errorreport sends errors that need a human to an error tracker such as Sentry
+package errorreport
+
+import (
+	"context"
+	"time"
+)
+
+// Reporter receives errors along with the context needed to triage them
+// Implementations must be safe for concurrent use and must not block the caller for long
+type Reporter interface {
+	// Report sends err, tagged with tags such as task_id and task_type
+	Report(ctx context.Context, err error, tags map[string]string)
+	// Flush waits up to timeout for buffered reports to be sent, reporting whether they were
+	Flush(timeout time.Duration) bool
+}
+
+// Nop returns a Reporter that discards everything
+func Nop() Reporter {
+	return nop{}
+}
+
+type nop struct{}
+
+func (nop) Report(context.Context, error, map[string]string) {}
+
+func (nop) Flush(time.Duration) bool { return true }
@@ -0,0 +1,51 @@
+package errorreport
+
+import (
+	"context"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// SentryOptions configures the Sentry reporter
+type SentryOptions struct {
+	DSN         string
+	Environment string // e.g. production; empty = Sentry's default
+	Release     string // build version the errors are attributed to
+	ServerName  string // e.g. the worker ID
+}
+
+// Sentry reports errors to Sentry
+type Sentry struct {
+	hub *sentry.Hub
+}
+
+// NewSentry creates a Sentry reporter
+// Stack traces are attached, and for recovered panics include the panicking frames
+func NewSentry(opts SentryOptions) (*Sentry, error) {
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:              opts.DSN,
+		Environment:      opts.Environment,
+		Release:          opts.Release,
+		ServerName:       opts.ServerName,
+		AttachStacktrace: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sentry{hub: sentry.NewHub(client, sentry.NewScope())}, nil
+}
+
+// Report sends err as a Sentry event with tags
+func (s *Sentry) Report(ctx context.Context, err error, tags map[string]string) {
+	s.hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTags(tags)
+		s.hub.CaptureException(err)
+	})
+}
+
+// Flush waits for queued events to reach Sentry
+func (s *Sentry) Flush(timeout time.Duration) bool {
+	return s.hub.Flush(timeout)
+}
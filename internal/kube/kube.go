@@ -0,0 +1,236 @@
+// Package kube is a minimal client for the Kubernetes API, covering what running Jobs from inside a cluster needs:
+// creating, reading and deleting Jobs, and reading the status and logs of their pods
+// It talks to the API server with the pod's service account, so the worker needs no kubeconfig and no client-go
+package kube
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// serviceAccountDir is where Kubernetes mounts the pod's service account credentials
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// requestTimeout bounds every API request; a caller's earlier deadline still applies
+const requestTimeout = 30 * time.Second
+
+// Client calls the Kubernetes API in one namespace
+type Client struct {
+	baseURL   string
+	namespace string
+	token     func() (string, error)
+	http      *http.Client
+}
+
+// InCluster creates a client for the cluster the process runs in, in namespace or, when empty, the pod's own
+// The service account token is read again for every request, since projected tokens are rotated
+func InCluster(namespace string) (*Client, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running in a Kubernetes cluster: KUBERNETES_SERVICE_HOST is not set")
+	}
+
+	ca, err := os.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("read cluster CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("cluster CA contains no certificate")
+	}
+
+	if namespace == "" {
+		own, err := os.ReadFile(filepath.Join(serviceAccountDir, "namespace"))
+		if err != nil {
+			return nil, fmt.Errorf("read pod namespace: %w", err)
+		}
+		namespace = strings.TrimSpace(string(own))
+	}
+
+	tokenFile := filepath.Join(serviceAccountDir, "token")
+	token := func() (string, error) {
+		token, err := os.ReadFile(tokenFile)
+		return strings.TrimSpace(string(token)), err
+	}
+	transport := &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}}
+	return newClient("https://"+net.JoinHostPort(host, port), namespace, token, transport), nil
+}
+
+// newClient creates a client for the API server at baseURL
+func newClient(baseURL, namespace string, token func() (string, error), transport http.RoundTripper) *Client {
+	return &Client{
+		baseURL:   baseURL,
+		namespace: namespace,
+		token:     token,
+		http:      &http.Client{Timeout: requestTimeout, Transport: transport},
+	}
+}
+
+// Namespace returns the namespace the client works in
+func (c *Client) Namespace() string {
+	return c.namespace
+}
+
+// StatusError is a failed API request, carrying the reason the API server gave
+type StatusError struct {
+	Code    int
+	Reason  string // e.g. Invalid, Forbidden, NotFound
+	Message string
+}
+
+// Error returns the status code and message
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("kubernetes API answered %d %s: %s", e.Code, e.Reason, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 from the API server
+func IsNotFound(err error) bool {
+	var status *StatusError
+	return errors.As(err, &status) && status.Code == http.StatusNotFound
+}
+
+// CreateJob creates job in the client's namespace and returns it as stored, with its generated name
+func (c *Client) CreateJob(ctx context.Context, job *Job) (*Job, error) {
+	var created Job
+	if err := c.do(ctx, http.MethodPost, c.jobsPath(""), nil, job, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// GetJob returns the named job with its status
+func (c *Client) GetJob(ctx context.Context, name string) (*Job, error) {
+	var job Job
+	if err := c.do(ctx, http.MethodGet, c.jobsPath(name), nil, nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// DeleteJob deletes the named job and, in the background, its pods
+func (c *Client) DeleteJob(ctx context.Context, name string) error {
+	options := map[string]string{"propagationPolicy": "Background"}
+	return c.do(ctx, http.MethodDelete, c.jobsPath(name), nil, options, nil)
+}
+
+// JobPods returns the pods the named job created
+func (c *Client) JobPods(ctx context.Context, jobName string) ([]Pod, error) {
+	query := url.Values{"labelSelector": {"job-name=" + jobName}}
+	var list struct {
+		Items []Pod `json:"items"`
+	}
+	if err := c.do(ctx, http.MethodGet, c.podsPath(""), query, nil, &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// PodLogs returns up to the last lines lines, and at most limitBytes bytes, of the named pod's log
+func (c *Client) PodLogs(ctx context.Context, podName string, lines, limitBytes int) (string, error) {
+	query := url.Values{
+		"tailLines":  {fmt.Sprint(lines)},
+		"limitBytes": {fmt.Sprint(limitBytes)},
+	}
+	var logs bytes.Buffer
+	if err := c.do(ctx, http.MethodGet, c.podsPath(podName)+"/log", query, nil, &logs); err != nil {
+		return "", err
+	}
+	return logs.String(), nil
+}
+
+func (c *Client) jobsPath(name string) string {
+	path := "/apis/batch/v1/namespaces/" + url.PathEscape(c.namespace) + "/jobs"
+	if name != "" {
+		path += "/" + url.PathEscape(name)
+	}
+	return path
+}
+
+func (c *Client) podsPath(name string) string {
+	path := "/api/v1/namespaces/" + url.PathEscape(c.namespace) + "/pods"
+	if name != "" {
+		path += "/" + url.PathEscape(name)
+	}
+	return path
+}
+
+// do sends body as JSON and decodes the response into out: a *bytes.Buffer receives it as is
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	token, err := c.token()
+	if err != nil {
+		return fmt.Errorf("read service account token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 300 {
+		return statusError(resp)
+	}
+
+	switch out := out.(type) {
+	case nil:
+		return nil
+	case *bytes.Buffer:
+		_, err := out.ReadFrom(resp.Body)
+		return err
+	default:
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+}
+
+// statusError decodes the Status object the API server answers errors with
+func statusError(resp *http.Response) error {
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	status := &StatusError{Code: resp.StatusCode}
+	var decoded struct {
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(detail, &decoded) == nil && decoded.Message != "" {
+		status.Reason, status.Message = decoded.Reason, decoded.Message
+	} else {
+		status.Message = strings.TrimSpace(string(detail))
+	}
+	return status
+}
@@ -0,0 +1,90 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientJobLifecycle(t *testing.T) {
+	var created Job
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer token-1" {
+			t.Errorf("Authorization = %q", got)
+		}
+
+		switch r.Method + " " + r.URL.Path {
+		case "POST /apis/batch/v1/namespaces/jobs/jobs":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Fatalf("decode job: %v", err)
+			}
+			created.Metadata.Name = created.Metadata.GenerateName + "x7k2p"
+			_ = json.NewEncoder(w).Encode(created)
+		case "GET /apis/batch/v1/namespaces/jobs/jobs/taskqueue-x7k2p":
+			_, _ = io.WriteString(w, `{"metadata":{"name":"taskqueue-x7k2p"},"status":{"succeeded":1,"conditions":[{"type":"Complete","status":"True"}]}}`)
+		case "GET /api/v1/namespaces/jobs/pods":
+			if got := r.URL.Query().Get("labelSelector"); got != "job-name=taskqueue-x7k2p" {
+				t.Errorf("labelSelector = %q", got)
+			}
+			_, _ = io.WriteString(w, `{"items":[{"metadata":{"name":"taskqueue-x7k2p-abcde"},"status":{"phase":"Succeeded","containerStatuses":[{"name":"job","state":{"terminated":{"exitCode":0,"reason":"Completed"}}}]}}]}`)
+		case "GET /api/v1/namespaces/jobs/pods/taskqueue-x7k2p-abcde/log":
+			if got := r.URL.Query().Get("tailLines"); got != "10" {
+				t.Errorf("tailLines = %q", got)
+			}
+			_, _ = io.WriteString(w, "done\n")
+		case "DELETE /apis/batch/v1/namespaces/jobs/jobs/missing":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"kind":"Status","reason":"NotFound","message":"jobs.batch \"missing\" not found","code":404}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := newClient(server.URL, "jobs", func() (string, error) { return "token-1", nil }, http.DefaultTransport)
+	ctx := context.Background()
+
+	job, err := client.CreateJob(ctx, &Job{
+		Metadata: ObjectMeta{GenerateName: "taskqueue-"},
+		Spec:     JobSpec{Template: PodTemplateSpec{Spec: PodSpec{Containers: []Container{{Name: "job", Image: "busybox"}}}}},
+	})
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	if job.Metadata.Name != "taskqueue-x7k2p" || created.Spec.Template.Spec.Containers[0].Image != "busybox" {
+		t.Errorf("CreateJob() = %+v, sent %+v", job.Metadata, created.Spec)
+	}
+
+	job, err = client.GetJob(ctx, job.Metadata.Name)
+	if err != nil {
+		t.Fatalf("GetJob() error = %v", err)
+	}
+	if job.Status.Condition(JobComplete) == nil || job.Status.Condition(JobFailed) != nil {
+		t.Errorf("GetJob() conditions = %+v, want Complete only", job.Status.Conditions)
+	}
+
+	pods, err := client.JobPods(ctx, job.Metadata.Name)
+	if err != nil {
+		t.Fatalf("JobPods() error = %v", err)
+	}
+	if len(pods) != 1 || pods[0].Status.ContainerStatuses[0].State.Terminated.Reason != "Completed" {
+		t.Errorf("JobPods() = %+v", pods)
+	}
+
+	logs, err := client.PodLogs(ctx, pods[0].Metadata.Name, 10, 1024)
+	if err != nil || logs != "done\n" {
+		t.Errorf("PodLogs() = %q, %v", logs, err)
+	}
+
+	err = client.DeleteJob(ctx, "missing")
+	if !IsNotFound(err) {
+		t.Fatalf("DeleteJob() error = %v, want not found", err)
+	}
+	if status := err.(*StatusError); status.Reason != "NotFound" || status.Message != `jobs.batch "missing" not found` {
+		t.Errorf("StatusError = %+v", status)
+	}
+}
@@ -0,0 +1,139 @@
+package kube
+
+import "time"
+
+// The types below mirror the parts of the Kubernetes objects this package reads and writes;
+// fields it does not use are left out and ignored when decoding
+
+// ObjectMeta is the metadata of an object
+type ObjectMeta struct {
+	Name         string            `json:"name,omitempty"`
+	GenerateName string            `json:"generateName,omitempty"`
+	Namespace    string            `json:"namespace,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+// Job is a batch/v1 Job
+type Job struct {
+	APIVersion string     `json:"apiVersion,omitempty"`
+	Kind       string     `json:"kind,omitempty"`
+	Metadata   ObjectMeta `json:"metadata"`
+	Spec       JobSpec    `json:"spec"`
+	Status     JobStatus  `json:"status,omitempty"`
+}
+
+// JobSpec describes how a Job runs
+type JobSpec struct {
+	BackoffLimit            *int32          `json:"backoffLimit,omitempty"`
+	ActiveDeadlineSeconds   *int64          `json:"activeDeadlineSeconds,omitempty"`
+	TTLSecondsAfterFinished *int32          `json:"ttlSecondsAfterFinished,omitempty"`
+	Template                PodTemplateSpec `json:"template"`
+}
+
+// PodTemplateSpec is the pod a Job creates
+type PodTemplateSpec struct {
+	Metadata ObjectMeta `json:"metadata,omitempty"`
+	Spec     PodSpec    `json:"spec"`
+}
+
+// PodSpec describes a pod's containers
+type PodSpec struct {
+	RestartPolicy      string      `json:"restartPolicy,omitempty"`
+	ServiceAccountName string      `json:"serviceAccountName,omitempty"`
+	Containers         []Container `json:"containers"`
+}
+
+// Container is one container of a pod
+type Container struct {
+	Name      string                `json:"name"`
+	Image     string                `json:"image"`
+	Command   []string              `json:"command,omitempty"`
+	Args      []string              `json:"args,omitempty"`
+	Env       []EnvVar              `json:"env,omitempty"`
+	Resources *ResourceRequirements `json:"resources,omitempty"`
+}
+
+// EnvVar is an environment variable of a container
+type EnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ResourceRequirements are the requests and limits of a container, e.g. {"cpu": "500m", "memory": "256Mi"}
+type ResourceRequirements struct {
+	Requests map[string]string `json:"requests,omitempty"`
+	Limits   map[string]string `json:"limits,omitempty"`
+}
+
+// JobStatus is the observed state of a Job
+type JobStatus struct {
+	Active         int32          `json:"active,omitempty"`
+	Succeeded      int32          `json:"succeeded,omitempty"`
+	Failed         int32          `json:"failed,omitempty"`
+	StartTime      *time.Time     `json:"startTime,omitempty"`
+	CompletionTime *time.Time     `json:"completionTime,omitempty"`
+	Conditions     []JobCondition `json:"conditions,omitempty"`
+}
+
+// JobCondition types that end a Job
+const (
+	JobComplete = "Complete"
+	JobFailed   = "Failed"
+)
+
+// JobCondition is one condition of a Job, e.g. Complete or Failed
+type JobCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"` // True, False or Unknown
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Condition returns the condition of type t when it is true, nil otherwise
+func (s JobStatus) Condition(t string) *JobCondition {
+	for i, c := range s.Conditions {
+		if c.Type == t && c.Status == "True" {
+			return &s.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// Pod is a core/v1 Pod
+type Pod struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Status   PodStatus  `json:"status"`
+}
+
+// PodStatus is the observed state of a pod
+type PodStatus struct {
+	Phase             string            `json:"phase,omitempty"`
+	ContainerStatuses []ContainerStatus `json:"containerStatuses,omitempty"`
+}
+
+// ContainerStatus is the observed state of one container
+type ContainerStatus struct {
+	Name  string         `json:"name"`
+	State ContainerState `json:"state"`
+}
+
+// ContainerState is set in exactly one of its fields
+type ContainerState struct {
+	Waiting    *ContainerStateWaiting    `json:"waiting,omitempty"`
+	Running    *struct{}                 `json:"running,omitempty"`
+	Terminated *ContainerStateTerminated `json:"terminated,omitempty"`
+}
+
+// ContainerStateWaiting is a container that has not started, e.g. because its image cannot be pulled
+type ContainerStateWaiting struct {
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// ContainerStateTerminated is a container that exited
+type ContainerStateTerminated struct {
+	ExitCode int32  `json:"exitCode"`
+	Reason   string `json:"reason,omitempty"` // e.g. Completed, Error, OOMKilled
+	Message  string `json:"message,omitempty"`
+}
@@ -0,0 +1,115 @@
+// Package logging configures the process-wide slog logger
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/config"
+)
+
+// Log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Log outputs
+const (
+	OutputStderr = "stderr"
+	OutputStdout = "stdout"
+)
+
+// Setup installs the default slog logger described by cfg
+// The returned level can be changed while the process runs
+func Setup(cfg config.Logging) (*slog.LevelVar, error) {
+	level := new(slog.LevelVar)
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, fmt.Errorf("invalid LOG_LEVEL %q: %w", cfg.Level, err)
+	}
+
+	output, err := outputWriter(cfg.Output)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := NewHandler(cfg.Format, output, level)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.SetDefault(slog.New(h))
+	return level, nil
+}
+
+// NewHandler creates a slog handler writing format to w
+func NewHandler(format string, w io.Writer, level slog.Leveler) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{Level: level}
+	switch format {
+	case FormatText:
+		return slog.NewTextHandler(w, opts), nil
+	case FormatJSON:
+		return slog.NewJSONHandler(w, opts), nil
+	default:
+		return nil, fmt.Errorf("invalid LOG_FORMAT %q: must be %s or %s", format, FormatText, FormatJSON)
+	}
+}
+
+// outputWriter resolves LOG_OUTPUT
+func outputWriter(output string) (io.Writer, error) {
+	switch output {
+	case OutputStderr:
+		return os.Stderr, nil
+	case OutputStdout:
+		return os.Stdout, nil
+	default:
+		return nil, fmt.Errorf("invalid LOG_OUTPUT %q: must be %s or %s", output, OutputStderr, OutputStdout)
+	}
+}
+
+// levelBody is the request and response body of the log level endpoint
+type levelBody struct {
+	Level string `json:"level"`
+}
+
+// LevelHandler serves the current log level on GET and changes it on PUT {"level": "debug"}
+// The change lasts until the process restarts
+func LevelHandler(level *slog.LevelVar) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var body levelBody
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body", "details": err.Error()})
+				return
+			}
+
+			var next slog.Level
+			if err := next.UnmarshalText([]byte(body.Level)); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid log level", "details": err.Error()})
+				return
+			}
+
+			previous := level.Level()
+			level.Set(next)
+			slog.Warn("Log level changed", "from", previous.String(), "to", next.String())
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, levelBody{Level: level.Level().String()})
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
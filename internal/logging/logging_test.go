@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewHandler_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	h, err := NewHandler(FormatJSON, &buf, slog.LevelInfo)
+	if err != nil {
+		t.Fatalf("NewHandler() error = %v", err)
+	}
+
+	logger := slog.New(h)
+	logger.Debug("hidden")
+	logger.Info("Task succeeded", "task_id", 42)
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("output %q is not a single JSON line: %v", buf.String(), err)
+	}
+	if line["msg"] != "Task succeeded" || line["task_id"] != float64(42) {
+		t.Errorf("line = %v, want msg and task_id", line)
+	}
+}
+
+func TestNewHandler_InvalidFormat(t *testing.T) {
+	if _, err := NewHandler("logfmt", &bytes.Buffer{}, slog.LevelInfo); err == nil {
+		t.Fatal("NewHandler(logfmt) error = nil, want an error")
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	level := new(slog.LevelVar)
+	h := LevelHandler(level)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/log-level", strings.NewReader(`{"level":"debug"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if level.Level() != slog.LevelDebug {
+		t.Errorf("level = %s, want DEBUG", level.Level())
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/log-level", strings.NewReader(`{"level":"loud"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("PUT invalid status = %d, want 400", rec.Code)
+	}
+	if level.Level() != slog.LevelDebug {
+		t.Errorf("level after invalid PUT = %s, want DEBUG", level.Level())
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/log-level", nil))
+	if !strings.Contains(rec.Body.String(), `"DEBUG"`) {
+		t.Errorf("GET body = %s, want the DEBUG level", rec.Body)
+	}
+}
@@ -0,0 +1,205 @@
+// Package maintenance runs periodic housekeeping jobs in the API server
+package maintenance
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/alerting"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/metrics"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+)
+
+// Job is a named housekeeping step
+type Job struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Runner runs its jobs one after another, at startup and then on every interval
+// Jobs must be safe to run from several API servers at once
+type Runner struct {
+	interval time.Duration
+	jobs     []Job
+}
+
+// NewRunner creates a runner for jobs
+func NewRunner(interval time.Duration, jobs ...Job) *Runner {
+	if interval <= 0 {
+		interval = 1 * time.Hour
+	}
+
+	return &Runner{
+		interval: interval,
+		jobs:     jobs,
+	}
+}
+
+// Run runs the jobs until ctx is done
+// A failing job is logged and does not stop the others
+func (r *Runner) Run(ctx context.Context) {
+	if len(r.jobs) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		for _, job := range r.jobs {
+			if err := job.Run(ctx); err != nil && ctx.Err() == nil {
+				slog.Error("Maintenance job failed", "job", job.Name, "error", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// HistoryPartitions returns a job that keeps task history partitions created ahead
+// and drops those older than retention (0 = keep all)
+func HistoryPartitions(p storage.HistoryPartitioner, retention time.Duration) Job {
+	return Job{
+		Name: "history_partitions",
+		Run: func(ctx context.Context) error {
+			created, dropped, err := p.MaintainHistoryPartitions(ctx, retention)
+			if err != nil {
+				return err
+			}
+
+			if len(created) > 0 || len(dropped) > 0 {
+				slog.Info("Maintained task history partitions", "created", created, "dropped", dropped)
+			}
+			return nil
+		},
+	}
+}
+
+// QueueDepth returns a job that exports the backlog of every task type as gauges
+// Types whose backlog cleared are reported as 0 rather than left at their last value
+func QueueDepth(store storage.Store, registry *metrics.Registry) Job {
+	queued := registry.NewGauge("taskqueue_queue_depth", "Queued tasks per type, including tasks scheduled for later.", "type")
+	claimable := registry.NewGauge("taskqueue_queue_claimable", "Queued tasks per type that are due now.", "type")
+	oldestAge := registry.NewGauge("taskqueue_queue_oldest_age_seconds", "Time the oldest claimable task of each type has been waiting.", "type")
+
+	known := map[string]bool{}
+	return Job{
+		Name: "queue_depth",
+		Run: func(ctx context.Context) error {
+			depths, err := store.GetQueueDepth(ctx)
+			if err != nil {
+				return err
+			}
+
+			current := map[string]bool{}
+			for _, d := range depths {
+				current[d.TaskType] = true
+				queued.Set(float64(d.Queued), d.TaskType)
+				claimable.Set(float64(d.Claimable), d.TaskType)
+				oldestAge.Set(d.OldestAgeSeconds, d.TaskType)
+			}
+			for taskType := range known {
+				if !current[taskType] {
+					queued.Set(0, taskType)
+					claimable.Set(0, taskType)
+					oldestAge.Set(0, taskType)
+				}
+			}
+			for taskType := range current {
+				known[taskType] = true
+			}
+			return nil
+		},
+	}
+}
+
+// SuccessRate returns a job that exports the succeeded share of tasks finished within the last window per type
+// Types without finished tasks in the window are dropped instead of keeping a stale rate
+func SuccessRate(a storage.TaskAnalytics, registry *metrics.Registry, window time.Duration) Job {
+	rate := registry.NewGauge("taskqueue_success_rate", "Succeeded share of tasks finished within the success rate window per type.", "type")
+	finished := registry.NewGauge("taskqueue_success_rate_finished_tasks", "Tasks finished within the success rate window per type.", "type")
+
+	known := map[string]bool{}
+	return Job{
+		Name: "success_rate",
+		Run: func(ctx context.Context) error {
+			rates, err := a.GetSuccessRates(ctx, models.TimeRange{From: time.Now().Add(-window)})
+			if err != nil {
+				return err
+			}
+
+			current := map[string]bool{}
+			for _, r := range rates {
+				current[r.TaskType] = true
+				rate.Set(r.SuccessRate, r.TaskType)
+				finished.Set(float64(r.Succeeded+r.Failed), r.TaskType)
+			}
+			for taskType := range known {
+				if !current[taskType] {
+					rate.Delete(taskType)
+					finished.Set(0, taskType)
+				}
+			}
+			for taskType := range current {
+				known[taskType] = true
+			}
+			return nil
+		},
+	}
+}
+
+// AlertRules returns a job that evaluates the alert rules and notifies the webhooks of those that fired or resolved
+func AlertRules(e *alerting.Evaluator) Job {
+	return Job{
+		Name: "alert_rules",
+		Run:  e.Evaluate,
+	}
+}
+
+// AnalyzeTables returns a job that refreshes planner statistics of the queue tables
+// and reports their dead tuples, warning when a table's dead tuple ratio reaches warnRatio
+func AnalyzeTables(a storage.TableAnalyzer, registry *metrics.Registry, warnRatio float64) Job {
+	liveTuples := registry.NewGauge("taskqueue_table_live_tuples", "Estimated live rows per queue table.", "table")
+	deadTuples := registry.NewGauge("taskqueue_table_dead_tuples", "Dead rows awaiting vacuum per queue table.", "table")
+	deadRatio := registry.NewGauge("taskqueue_table_dead_tuple_ratio", "Share of dead rows among all rows per queue table.", "table")
+	lastAutovacuum := registry.NewGauge("taskqueue_table_last_autovacuum_timestamp_seconds", "Unix time of the last autovacuum per queue table, 0 if never.", "table")
+
+	return Job{
+		Name: "analyze_tables",
+		Run: func(ctx context.Context) error {
+			stats, err := a.AnalyzeTables(ctx)
+			if err != nil {
+				return err
+			}
+
+			for _, s := range stats {
+				ratio := s.DeadTupleRatio()
+				liveTuples.Set(float64(s.LiveTuples), s.Table)
+				deadTuples.Set(float64(s.DeadTuples), s.Table)
+				deadRatio.Set(ratio, s.Table)
+
+				var vacuumed float64
+				if s.LastAutovacuum != nil {
+					vacuumed = float64(s.LastAutovacuum.Unix())
+				}
+				lastAutovacuum.Set(vacuumed, s.Table)
+
+				if warnRatio > 0 && ratio >= warnRatio {
+					slog.Warn("Table bloat: autovacuum is falling behind",
+						"table", s.Table,
+						"dead_tuples", s.DeadTuples,
+						"live_tuples", s.LiveTuples,
+						"dead_ratio", ratio,
+						"last_autovacuum", s.LastAutovacuum)
+				}
+			}
+			return nil
+		},
+	}
+}
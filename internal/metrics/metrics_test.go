@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	r := NewRegistry()
+	processed := r.NewCounter("tasks_processed_total", "Tasks processed.", "type", "outcome")
+	inFlight := r.NewGauge("tasks_in_flight", "Tasks in flight.")
+	r.NewGaugeFunc("concurrency", "Worker concurrency.", func() float64 { return 5 })
+
+	processed.Inc("send_email", "succeeded")
+	processed.Add(2, "run_query", "failed")
+	processed.Inc("send_email", "succeeded")
+	processed.Add(-1, "send_email", "succeeded") // ignored
+	inFlight.Set(3)
+	inFlight.Add(-1)
+
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	want := `# HELP tasks_processed_total Tasks processed.
+# TYPE tasks_processed_total counter
+tasks_processed_total{type="run_query",outcome="failed"} 2
+tasks_processed_total{type="send_email",outcome="succeeded"} 2
+# HELP tasks_in_flight Tasks in flight.
+# TYPE tasks_in_flight gauge
+tasks_in_flight 2
+# HELP concurrency Worker concurrency.
+# TYPE concurrency gauge
+concurrency 5
+`
+	if got := b.String(); got != want {
+		t.Fatalf("Write() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGaugeDelete(t *testing.T) {
+	r := NewRegistry()
+	rate := r.NewGauge("success_rate", "Success rate.", "type")
+	rate.Set(0.5, "send_email")
+	rate.Set(1, "run_query")
+	rate.Delete("send_email")
+	rate.Delete("unknown")
+
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	want := `# HELP success_rate Success rate.
+# TYPE success_rate gauge
+success_rate{type="run_query"} 1
+`
+	if got := b.String(); got != want {
+		t.Fatalf("Write() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatLabelsEscapes(t *testing.T) {
+	got := formatLabels([]string{"error"}, []string{"say \"hi\"\n\\"})
+	want := `{error="say \"hi\"\n\\"}`
+	if got != want {
+		t.Fatalf("formatLabels() = %s, want %s", got, want)
+	}
+}
+
+func TestHistogramWrite(t *testing.T) {
+	r := NewRegistry()
+	duration := r.NewHistogram("task_duration_seconds", "Task duration.", []float64{0.1, 1}, "type")
+
+	duration.Observe(0.05, "send_email")
+	duration.Observe(0.1, "send_email") // bounds are inclusive
+	duration.Observe(0.5, "send_email")
+	duration.Observe(3, "send_email")
+
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	want := `# HELP task_duration_seconds Task duration.
+# TYPE task_duration_seconds histogram
+task_duration_seconds_bucket{type="send_email",le="0.1"} 2
+task_duration_seconds_bucket{type="send_email",le="1"} 3
+task_duration_seconds_bucket{type="send_email",le="+Inf"} 4
+task_duration_seconds_sum{type="send_email"} 3.65
+task_duration_seconds_count{type="send_email"} 4
+`
+	if got := b.String(); got != want {
+		t.Fatalf("Write() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,193 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// AlertMetric is the figure an alert rule watches
+type AlertMetric string
+
+const (
+	AlertMetricFailedTasks     AlertMetric = "failed_tasks"      // tasks that finished failed within the window
+	AlertMetricFailureRate     AlertMetric = "failure_rate"      // failed share of tasks finished within the window
+	AlertMetricQueueDepth      AlertMetric = "queue_depth"       // tasks queued now, including those scheduled for later
+	AlertMetricOldestQueuedAge AlertMetric = "oldest_queued_age" // seconds the oldest claimable task has been waiting
+)
+
+// IsValid checks if the alert metric is valid
+func (m AlertMetric) IsValid() bool {
+	switch m {
+	case AlertMetricFailedTasks, AlertMetricFailureRate, AlertMetricQueueDepth, AlertMetricOldestQueuedAge:
+		return true
+	}
+	return false
+}
+
+// Windowed reports whether the metric is computed over the rule's window of finished tasks
+func (m AlertMetric) Windowed() bool {
+	return m == AlertMetricFailedTasks || m == AlertMetricFailureRate
+}
+
+// AlertOperator compares a metric value with a rule's threshold
+type AlertOperator string
+
+const (
+	AlertOperatorAbove   AlertOperator = ">"
+	AlertOperatorAtLeast AlertOperator = ">="
+	AlertOperatorBelow   AlertOperator = "<"
+	AlertOperatorAtMost  AlertOperator = "<="
+
+	// DefaultAlertOperator is used when a rule does not name one
+	DefaultAlertOperator = AlertOperatorAbove
+)
+
+// MaxAlertWindowSeconds caps the window of windowed alert metrics
+const MaxAlertWindowSeconds = 7 * 24 * 3600
+
+// alertRuleNameMaxLen matches the alert_rules.name column
+const alertRuleNameMaxLen = 100
+
+// Bounds of a rule's webhook signing secret
+const (
+	alertSigningSecretMinLen = 16
+	alertSigningSecretMaxLen = 256
+)
+
+// IsValid checks if the alert operator is valid
+func (o AlertOperator) IsValid() bool {
+	switch o {
+	case AlertOperatorAbove, AlertOperatorAtLeast, AlertOperatorBelow, AlertOperatorAtMost:
+		return true
+	}
+	return false
+}
+
+// Matches reports whether value compared with threshold fires the rule
+func (o AlertOperator) Matches(value, threshold float64) bool {
+	switch o {
+	case AlertOperatorAbove:
+		return value > threshold
+	case AlertOperatorAtLeast:
+		return value >= threshold
+	case AlertOperatorBelow:
+		return value < threshold
+	case AlertOperatorAtMost:
+		return value <= threshold
+	}
+	return false
+}
+
+// AlertChannel is the format notifications are posted in
+type AlertChannel string
+
+const (
+	AlertChannelWebhook AlertChannel = "webhook" // JSON AlertNotification
+	AlertChannelSlack   AlertChannel = "slack"   // Slack incoming webhook message
+)
+
+// IsValid checks if the alert channel is valid
+func (c AlertChannel) IsValid() bool {
+	return c == AlertChannelWebhook || c == AlertChannelSlack
+}
+
+// AlertRule fires a notification when a metric crosses a threshold and another once it recovers
+type AlertRule struct {
+	Name             string        `json:"name" db:"name"`
+	Metric           AlertMetric   `json:"metric" db:"metric"`
+	TaskType         string        `json:"task_type,omitempty" db:"task_type"` // empty = all types
+	Operator         AlertOperator `json:"operator" db:"operator"`
+	Threshold        float64       `json:"threshold" db:"threshold"`
+	WindowSeconds    int           `json:"window_seconds,omitempty" db:"window_seconds"` // windowed metrics only
+	WebhookURL       string        `json:"webhook_url" db:"webhook_url"`
+	Channel          AlertChannel  `json:"channel" db:"channel"`
+	SigningSecret    string        `json:"-" db:"signing_secret"` // write-only; empty = unsigned deliveries
+	Signed           bool          `json:"signed"`                // whether a signing secret is set
+	Firing           bool          `json:"firing" db:"firing"`
+	LastValue        *float64      `json:"last_value,omitempty" db:"last_value"`
+	LastEvaluatedAt  *time.Time    `json:"last_evaluated_at,omitempty" db:"last_evaluated_at"`
+	LastTransitionAt *time.Time    `json:"last_transition_at,omitempty" db:"last_transition_at"`
+	UpdatedAt        time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// SetAlertRuleRequest represents the API request to create or replace an alert rule
+type SetAlertRuleRequest struct {
+	Metric        AlertMetric   `json:"metric" binding:"required"`
+	TaskType      string        `json:"task_type"`
+	Operator      AlertOperator `json:"operator"`
+	Threshold     *float64      `json:"threshold" binding:"required"`
+	WindowSeconds int           `json:"window_seconds" binding:"min=0"`
+	WebhookURL    string        `json:"webhook_url" binding:"required,url"`
+	Channel       AlertChannel  `json:"channel"`
+	SigningSecret string        `json:"signing_secret"` // optional; signs every delivery with HMAC-SHA256
+}
+
+// Normalize fills in the defaults of optional fields
+func (r *SetAlertRuleRequest) Normalize() {
+	if r.Operator == "" {
+		r.Operator = DefaultAlertOperator
+	}
+	if r.Channel == "" {
+		r.Channel = AlertChannelWebhook
+	}
+}
+
+// Validate checks a normalized request
+func (r *SetAlertRuleRequest) Validate() error {
+	if !r.Metric.IsValid() {
+		return errors.New("metric must be failed_tasks, failure_rate, queue_depth or oldest_queued_age")
+	}
+	if !r.Operator.IsValid() {
+		return errors.New("operator must be >, >=, < or <=")
+	}
+	if !r.Channel.IsValid() {
+		return errors.New("channel must be webhook or slack")
+	}
+	if r.Metric.Windowed() && (r.WindowSeconds <= 0 || r.WindowSeconds > MaxAlertWindowSeconds) {
+		return errors.New("window_seconds must be between 1 and 604800 for failed_tasks and failure_rate")
+	}
+	if !r.Metric.Windowed() && r.WindowSeconds != 0 {
+		return errors.New("window_seconds only applies to failed_tasks and failure_rate")
+	}
+	if r.SigningSecret != "" && (len(r.SigningSecret) < alertSigningSecretMinLen || len(r.SigningSecret) > alertSigningSecretMaxLen) {
+		return errors.New("signing_secret must be between 16 and 256 characters")
+	}
+	return nil
+}
+
+// ValidateAlertRuleName checks that an alert rule name is usable as a path segment and key
+func ValidateAlertRuleName(name string) error {
+	if name == "" || len(name) > alertRuleNameMaxLen {
+		return errors.New("name must be between 1 and 100 characters")
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return errors.New("name may only contain letters, digits, '-', '_' and '.'")
+		}
+	}
+	return nil
+}
+
+// AlertRulesResponse represents the API response listing alert rules
+type AlertRulesResponse struct {
+	Rules []AlertRule `json:"rules"`
+}
+
+// Alert notification statuses
+const (
+	AlertStatusFiring   = "firing"
+	AlertStatusResolved = "resolved"
+)
+
+// AlertNotification is the body posted to webhook channels when a rule fires or resolves
+type AlertNotification struct {
+	Rule          string        `json:"rule"`
+	Status        string        `json:"status"`
+	Metric        AlertMetric   `json:"metric"`
+	TaskType      string        `json:"task_type,omitempty"`
+	Operator      AlertOperator `json:"operator"`
+	Threshold     float64       `json:"threshold"`
+	Value         float64       `json:"value"`
+	WindowSeconds int           `json:"window_seconds,omitempty"`
+	At            time.Time     `json:"at"`
+}
@@ -0,0 +1,173 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+// APIKeyScope is a permission granted to an API key
+type APIKeyScope string
+
+const (
+	APIKeyScopeRead  APIKeyScope = "read"  // reading tasks, stats, workers and settings
+	APIKeyScopeWrite APIKeyScope = "write" // creating and releasing tasks
+	APIKeyScopeAdmin APIKeyScope = "admin" // pausing, limits, alerting, settings, API keys and log level; grants every other scope
+)
+
+// IsValid checks if the API key scope is valid
+func (s APIKeyScope) IsValid() bool {
+	switch s {
+	case APIKeyScopeRead, APIKeyScopeWrite, APIKeyScopeAdmin:
+		return true
+	}
+	return false
+}
+
+// Role is a named set of scopes attached to an API key or a JWT
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"   // reads tasks and stats
+	RoleOperator Role = "operator" // also creates and releases tasks
+	RoleAdmin    Role = "admin"    // also controls the queue and its configuration
+)
+
+// IsValid checks if the role is valid
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleViewer, RoleOperator, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// Scopes returns the scopes the role grants
+func (r Role) Scopes() []APIKeyScope {
+	switch r {
+	case RoleViewer:
+		return []APIKeyScope{APIKeyScopeRead}
+	case RoleOperator:
+		return []APIKeyScope{APIKeyScopeRead, APIKeyScopeWrite}
+	case RoleAdmin:
+		return []APIKeyScope{APIKeyScopeAdmin}
+	}
+	return nil
+}
+
+// APIKeyPrefix starts every generated API key, so leaked keys are easy to recognize in scanners
+const APIKeyPrefix = "tq_"
+
+// apiKeyDisplayLen is how many characters of a key are stored in clear to tell keys apart
+const apiKeyDisplayLen = len(APIKeyPrefix) + 8
+
+// apiKeyNameMaxLen matches the api_keys.name column
+const apiKeyNameMaxLen = 100
+
+// APIKey is a credential for the task queue API
+// Only the SHA-256 hash of the key is stored; the key itself is shown once when it is created
+type APIKey struct {
+	ID        int64         `json:"id"`
+	Name      string        `json:"name"`
+	Prefix    string        `json:"prefix"` // first characters of the key, e.g. tq_3f9a1c2b
+	Role      Role          `json:"role,omitempty"`
+	Scopes    []APIKeyScope `json:"scopes"` // granted on top of the role's
+	CreatedAt time.Time     `json:"created_at"`
+	ExpiresAt *time.Time    `json:"expires_at,omitempty"`
+	RevokedAt *time.Time    `json:"revoked_at,omitempty"`
+}
+
+// Active reports whether the key is neither revoked nor expired at now
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// Allows reports whether the key's role or scopes grant scope
+func (k *APIKey) Allows(scope APIKeyScope) bool {
+	for _, s := range append(k.Role.Scopes(), k.Scopes...) {
+		if s == scope || s == APIKeyScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateAPIKeyRequest represents the request body for creating an API key
+type CreateAPIKeyRequest struct {
+	Name      string        `json:"name" binding:"required"`
+	Role      Role          `json:"role,omitempty"`
+	Scopes    []APIKeyScope `json:"scopes,omitempty"`
+	ExpiresAt *time.Time    `json:"expires_at,omitempty"`
+}
+
+// Normalize trims the name and drops duplicate scopes
+func (r *CreateAPIKeyRequest) Normalize() {
+	r.Name = strings.TrimSpace(r.Name)
+
+	seen := map[APIKeyScope]bool{}
+	scopes := []APIKeyScope{}
+	for _, s := range r.Scopes {
+		if !seen[s] {
+			seen[s] = true
+			scopes = append(scopes, s)
+		}
+	}
+	r.Scopes = scopes
+}
+
+// Validate checks a normalized request
+func (r *CreateAPIKeyRequest) Validate(now time.Time) error {
+	if r.Name == "" || len(r.Name) > apiKeyNameMaxLen {
+		return errors.New("name must be between 1 and 100 characters")
+	}
+	if r.Role != "" && !r.Role.IsValid() {
+		return errors.New("role must be viewer, operator or admin")
+	}
+	if r.Role == "" && len(r.Scopes) == 0 {
+		return errors.New("a role or at least one scope is required")
+	}
+	for _, s := range r.Scopes {
+		if !s.IsValid() {
+			return errors.New("scopes must be read, write or admin")
+		}
+	}
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(now) {
+		return errors.New("expires_at must be in the future")
+	}
+	return nil
+}
+
+// CreateAPIKeyResponse carries a new key; Key is never returned again
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// APIKeysResponse represents the API response for listing API keys
+type APIKeysResponse struct {
+	Keys []APIKey `json:"keys"`
+}
+
+// GenerateAPIKey returns a new random key with its display prefix and hash
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+
+	key = APIKeyPrefix + hex.EncodeToString(secret)
+	return key, key[:apiKeyDisplayLen], HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the hex SHA-256 hash keys are stored and looked up by
+// Keys carry 256 random bits, so a fast unsalted hash is enough
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RetryAfterError wraps a handler error with a suggested delay before the next attempt
+// Handlers return it when the downstream tells them when to come back (e.g. HTTP 429 Retry-After)
+type RetryAfterError struct {
+	Err   error
+	Delay time.Duration
+}
+
+// Error returns the message of the wrapped error
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%v (retry after %s)", e.Err, e.Delay)
+}
+
+// Unwrap returns the wrapped error
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter wraps err with a suggested retry delay
+func RetryAfter(err error, delay time.Duration) error {
+	return &RetryAfterError{Err: err, Delay: delay}
+}
+
+// RetryDelay returns the retry delay suggested by a handler error, if any
+func RetryDelay(err error) (time.Duration, bool) {
+	var retryErr *RetryAfterError
+	if errors.As(err, &retryErr) && retryErr.Delay > 0 {
+		return retryErr.Delay, true
+	}
+	return 0, false
+}
+
+// PermanentError marks a handler error that retrying cannot fix, e.g. an invalid payload or a rejected recipient
+// The worker fails the task at once instead of scheduling a retry
+type PermanentError struct {
+	Err error
+}
+
+// Error returns the message of the wrapped error
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps err so the task fails without retries
+func Permanent(err error) error {
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent reports whether a handler error is marked permanent
+func IsPermanent(err error) bool {
+	var permanentErr *PermanentError
+	return errors.As(err, &permanentErr)
+}
@@ -0,0 +1,58 @@
+package models
+
+// Component health statuses
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusDegraded = "degraded" // working, but needs attention, e.g. a saturated pool or a dirty migration
+	HealthStatusDown     = "down"
+)
+
+// Overall health statuses
+const (
+	HealthHealthy   = "healthy"
+	HealthUnhealthy = "unhealthy"
+)
+
+// ComponentHealth reports the state of one dependency or subsystem of a process
+type ComponentHealth struct {
+	Name           string           `json:"name"`
+	Status         string           `json:"status"`
+	LatencySeconds float64          `json:"latency_seconds,omitempty"`
+	Error          string           `json:"error,omitempty"`
+	Pool           *PoolHealth      `json:"pool,omitempty"`
+	Migration      *MigrationHealth `json:"migration,omitempty"`
+	Details        map[string]any   `json:"details,omitempty"` // component-specific figures
+}
+
+// PoolHealth reports the use of a connection pool
+type PoolHealth struct {
+	MaxConns      int     `json:"max_conns"`
+	TotalConns    int     `json:"total_conns"`
+	AcquiredConns int     `json:"acquired_conns"`
+	IdleConns     int     `json:"idle_conns"`
+	Utilization   float64 `json:"utilization"` // acquired share of max_conns
+}
+
+// MigrationHealth reports the applied schema migration version
+// A dirty version is one whose migration failed halfway and needs manual repair
+type MigrationHealth struct {
+	Version uint `json:"version"`
+	Dirty   bool `json:"dirty"`
+}
+
+// HealthResponse represents the API response of a detailed health check
+type HealthResponse struct {
+	Status     string            `json:"status"`
+	Components []ComponentHealth `json:"components"`
+}
+
+// NewHealthResponse reports healthy only when every component is ok
+func NewHealthResponse(components []ComponentHealth) HealthResponse {
+	resp := HealthResponse{Status: HealthHealthy, Components: components}
+	for _, c := range components {
+		if c.Status != HealthStatusOK {
+			resp.Status = HealthUnhealthy
+		}
+	}
+	return resp
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"context"
+	"fmt"
+)
+
+// Metadata keys carrying W3C trace context (https://www.w3.org/TR/trace-context/)
+// and the request ID; the API server fills them from the headers of the creating request
+const (
+	MetadataTraceParent = "traceparent"
+	MetadataTraceState  = "tracestate"
+	MetadataRequestID   = "request_id"
+)
+
+// Limits on task metadata, which is stored on every task row
+const (
+	MaxMetadataEntries  = 32
+	MaxMetadataKeyLen   = 64
+	MaxMetadataValueLen = 512
+)
+
+// ValidateMetadata checks metadata against the size limits
+func ValidateMetadata(metadata map[string]string) error {
+	if len(metadata) > MaxMetadataEntries {
+		return fmt.Errorf("at most %d entries allowed, got %d", MaxMetadataEntries, len(metadata))
+	}
+	for key, value := range metadata {
+		if key == "" || len(key) > MaxMetadataKeyLen {
+			return fmt.Errorf("key %q must be 1 to %d bytes", key, MaxMetadataKeyLen)
+		}
+		if len(value) > MaxMetadataValueLen {
+			return fmt.Errorf("value of %q exceeds %d bytes", key, MaxMetadataValueLen)
+		}
+	}
+	return nil
+}
+
+type metadataKey struct{}
+
+// ContextWithMetadata returns a copy of ctx carrying a task's metadata
+// The worker calls it before running a handler
+func ContextWithMetadata(ctx context.Context, metadata map[string]string) context.Context {
+	return context.WithValue(ctx, metadataKey{}, metadata)
+}
+
+// MetadataFromContext returns the metadata of the task a handler is running, nil if there is none
+// Handlers forward MetadataTraceParent and MetadataTraceState on their downstream calls
+func MetadataFromContext(ctx context.Context) map[string]string {
+	metadata, _ := ctx.Value(metadataKey{}).(map[string]string)
+	return metadata
+}
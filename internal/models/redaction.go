@@ -0,0 +1,32 @@
+package models
+
+import "errors"
+
+// RedactedPayload replaces the payload of a redacted task
+const RedactedPayload = `{}`
+
+// RedactedMessage replaces the error messages of a redacted task and its history
+const RedactedMessage = "[redacted]"
+
+// RedactTasksRequest selects the finished tasks a bulk redaction scrubs
+// Tasks must match every given criterion
+type RedactTasksRequest struct {
+	Tenant   string            `json:"tenant,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"` // entries the task's metadata must contain, e.g. {"customer_id": "42"}
+}
+
+// Validate requires at least one criterion, so a bulk redaction never scrubs every task
+func (r *RedactTasksRequest) Validate() error {
+	if r.Tenant == "" && len(r.Metadata) == 0 {
+		return errors.New("tenant or metadata is required")
+	}
+	if err := ValidateTenant(r.Tenant); err != nil {
+		return err
+	}
+	return ValidateMetadata(r.Metadata)
+}
+
+// RedactTasksResponse represents the API response for a bulk redaction
+type RedactTasksResponse struct {
+	Redacted int `json:"redacted"` // tasks scrubbed by this request; tasks redacted before are not counted
+}
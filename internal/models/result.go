@@ -0,0 +1,70 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxTaskResultBytes caps the encoded result a handler can store for a task
+const MaxTaskResultBytes = 64 << 10
+
+// TaskResult is the output a handler stored for its task
+type TaskResult struct {
+	Result    json.RawMessage `json:"result"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// TaskResultResponse represents the API response for a task's result
+type TaskResultResponse struct {
+	TaskID uuid.UUID `json:"task_id"`
+	TaskResult
+}
+
+type resultKey struct{}
+
+// resultSlot holds the result set by a running handler
+type resultSlot struct {
+	mu     sync.Mutex
+	result json.RawMessage
+}
+
+// ContextWithResult returns a copy of ctx collecting the result a handler sets with SetResult,
+// and a function returning that result, nil if none was set
+// The worker calls it before running a handler
+func ContextWithResult(ctx context.Context) (context.Context, func() json.RawMessage) {
+	slot := &resultSlot{}
+	return context.WithValue(ctx, resultKey{}, slot), func() json.RawMessage {
+		slot.mu.Lock()
+		defer slot.mu.Unlock()
+		return slot.result
+	}
+}
+
+// SetResult stores v, encoded as JSON, as the result of the task the handler is running
+// The result is saved once the handler succeeds and replaces any earlier one; it is dropped if the handler fails
+// Fails outside a task, when v cannot be encoded, or when it encodes to more than MaxTaskResultBytes
+func SetResult(ctx context.Context, v any) error {
+	slot, ok := ctx.Value(resultKey{}).(*resultSlot)
+	if !ok {
+		return errors.New("no task result in context")
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode task result: %w", err)
+	}
+	if len(encoded) > MaxTaskResultBytes {
+		return fmt.Errorf("task result is %d bytes, at most %d are stored", len(encoded), MaxTaskResultBytes)
+	}
+
+	slot.mu.Lock()
+	slot.result = encoded
+	slot.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// RetryStrategy determines how the delay before each retry attempt is computed
+type RetryStrategy string
+
+const (
+	RetryStrategyFixed       RetryStrategy = "fixed"       // backoff_seconds on every attempt
+	RetryStrategyLinear      RetryStrategy = "linear"      // backoff_seconds * attempt
+	RetryStrategyExponential RetryStrategy = "exponential" // backoff_seconds * 2^(attempt-1)
+	RetryStrategyCustom      RetryStrategy = "custom"      // delays taken from retry_schedule
+)
+
+// IsValid checks if the retry strategy is valid
+func (s RetryStrategy) IsValid() bool {
+	switch s {
+	case RetryStrategyFixed, RetryStrategyLinear, RetryStrategyExponential, RetryStrategyCustom:
+		return true
+	}
+	return false
+}
+
+// String returns the string representation of RetryStrategy
+func (s RetryStrategy) String() string {
+	return string(s)
+}
+
+// JitterMode determines how randomness is applied to retry delays
+type JitterMode string
+
+const (
+	JitterNone         JitterMode = "none"         // exact delay
+	JitterProportional JitterMode = "proportional" // delay ±25%
+	JitterFull         JitterMode = "full"         // uniform in [0, delay]
+	JitterEqual        JitterMode = "equal"        // delay/2 + uniform in [0, delay/2]
+	JitterDecorrelated JitterMode = "decorrelated" // uniform in [backoff_seconds, 3 * previous delay], ignoring the retry strategy
+)
+
+// IsValid checks if the jitter mode is valid
+func (m JitterMode) IsValid() bool {
+	switch m {
+	case JitterNone, JitterProportional, JitterFull, JitterEqual, JitterDecorrelated:
+		return true
+	}
+	return false
+}
+
+// ParseRetrySchedule converts a schedule of duration strings (e.g. ["30s", "5m", "1h"]) to whole seconds
+func ParseRetrySchedule(schedule []string) ([]int, error) {
+	if len(schedule) == 0 {
+		return nil, nil
+	}
+
+	seconds := make([]int, 0, len(schedule))
+	for _, entry := range schedule {
+		d, err := time.ParseDuration(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retry_schedule entry %q: %w", entry, err)
+		}
+		if d < time.Second {
+			return nil, fmt.Errorf("invalid retry_schedule entry %q: must be at least 1s", entry)
+		}
+		seconds = append(seconds, int(d/time.Second))
+	}
+	return seconds, nil
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TaskSchema is the JSON Schema payloads of a task type must match to be created
+type TaskSchema struct {
+	TaskType  string          `json:"task_type"`
+	Schema    json.RawMessage `json:"schema"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// SetTaskSchemaRequest represents the API request to set the schema of a task type
+type SetTaskSchemaRequest struct {
+	Schema json.RawMessage `json:"schema" binding:"required"`
+}
+
+// TaskSchemasResponse represents the API response listing task schemas
+type TaskSchemasResponse struct {
+	Schemas []TaskSchema `json:"schemas"`
+}
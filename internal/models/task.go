@@ -3,15 +3,24 @@ package models
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"sort"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // TaskType represents the type of task to be executed
 type TaskType string
 
 const (
-	TaskTypeSendEmail TaskType = "send_email"
-	TaskTypeRunQuery  TaskType = "run_query"
+	TaskTypeSendEmail     TaskType = "send_email"
+	TaskTypeRunQuery      TaskType = "run_query"
+	TaskTypeHTTPRequest   TaskType = "http_request"
+	TaskTypeShell         TaskType = "shell"
+	TaskTypeKubernetesJob TaskType = "k8s_job"
+	TaskTypeProcessFile   TaskType = "process_file"
+	TaskTypeRunReport     TaskType = "run_report"
 )
 
 // TaskStatus represents the lifecycle status of a task (4 essential public-facing statuses)
@@ -22,6 +31,9 @@ const (
 	TaskStatusRunning   TaskStatus = "running"
 	TaskStatusSucceeded TaskStatus = "succeeded"
 	TaskStatusFailed    TaskStatus = "failed"
+
+	// TaskStatusQuarantined holds poison tasks out of the queue until an operator releases them
+	TaskStatusQuarantined TaskStatus = "quarantined"
 )
 
 // EventType represents granular task lifecycle events for history tracking
@@ -37,12 +49,19 @@ const (
 	EventWorkerLockAcquired EventType = "worker_lock_acquired"
 	EventWorkerLockExpired  EventType = "worker_lock_expired"
 	EventTaskFailedFinal    EventType = "task_failed_final"
+	EventCircuitOpened      EventType = "circuit_opened"
+	EventTaskQuarantined    EventType = "task_quarantined"
+	EventTaskReleased       EventType = "task_released"
+	EventTaskRedacted       EventType = "task_redacted"
+	EventTaskUpdated        EventType = "task_updated"
+	EventPayloadTampered    EventType = "payload_tampered"
+	EventPayloadAccessed    EventType = "payload_accessed"
 )
 
 // IsValid checks if the task status is valid
 func (s TaskStatus) IsValid() bool {
 	switch s {
-	case TaskStatusQueued, TaskStatusRunning, TaskStatusSucceeded, TaskStatusFailed:
+	case TaskStatusQueued, TaskStatusRunning, TaskStatusSucceeded, TaskStatusFailed, TaskStatusQuarantined:
 		return true
 	}
 	return false
@@ -55,12 +74,18 @@ func (s TaskStatus) String() string {
 
 // Task represents a background task with retry, timeout, and scheduling support
 type Task struct {
-	ID       int64           `json:"id" db:"id"`
+	ID       int64           `json:"-" db:"id"`         // internal; never leaves the store, so task volume does not leak
+	PublicID uuid.UUID       `json:"id" db:"public_id"` // UUIDv7, the only id the API exposes
 	Name     string          `json:"name" db:"name"`
 	Type     string          `json:"type" db:"type"`
 	Payload  json.RawMessage `json:"payload" db:"payload"`
 	Status   TaskStatus      `json:"status" db:"status"`
 	Priority int             `json:"priority" db:"priority"`
+	Tenant   string          `json:"tenant,omitempty" db:"tenant"` // owner the tenant quotas apply to, empty for none
+
+	// Routing: only workers advertising all of these labels may claim the task
+	RequiredLabels map[string]string `json:"required_labels,omitempty" db:"required_labels"`
+	Metadata       map[string]string `json:"metadata,omitempty" db:"metadata"` // e.g. trace context, see MetadataFromContext
 
 	// Retry metadata
 	RetryCount int     `json:"retry_count" db:"retry_count"`
@@ -68,22 +93,73 @@ type Task struct {
 	LastError  *string `json:"last_error,omitempty" db:"last_error"`
 
 	// Scheduling & backoff
-	NextRunAt      time.Time `json:"next_run_at" db:"next_run_at"`
-	BackoffSeconds int       `json:"backoff_seconds" db:"backoff_seconds"`
+	NextRunAt         time.Time     `json:"next_run_at" db:"next_run_at"`
+	BackoffSeconds    int           `json:"backoff_seconds" db:"backoff_seconds"`
+	RetryStrategy     RetryStrategy `json:"retry_strategy" db:"retry_strategy"`
+	RetrySchedule     []int         `json:"retry_schedule,omitempty" db:"retry_schedule"`
+	MaxBackoffSeconds *int          `json:"max_backoff_seconds,omitempty" db:"max_backoff_seconds"`
+
+	// Delay before the current retry, the previous sleep for decorrelated jitter
+	LastBackoffSeconds *int `json:"last_backoff_seconds,omitempty" db:"last_backoff_seconds"`
 
 	// Timeout & worker safety
 	TimeoutSeconds int        `json:"timeout_seconds" db:"timeout_seconds"`
+	TimeoutCount   int        `json:"timeout_count" db:"timeout_count"`
+	MaxTimeouts    *int       `json:"max_timeouts,omitempty" db:"max_timeouts"`
 	LockedAt       *time.Time `json:"locked_at,omitempty" db:"locked_at"`
 	LockExpiresAt  *time.Time `json:"lock_expires_at,omitempty" db:"lock_expires_at"`
+	LockedBy       *string    `json:"locked_by,omitempty" db:"locked_by"`
+	LockToken      int64      `json:"lock_token" db:"lock_token"`
+
+	// Poison task detection
+	CrashCount    int        `json:"crash_count" db:"crash_count"`
+	QuarantinedAt *time.Time `json:"quarantined_at,omitempty" db:"quarantined_at"`
+
+	// Rate limiting
+	RateLimitKey  *string    `json:"rate_limit_key,omitempty" db:"rate_limit_key"`
+	LastStartedAt *time.Time `json:"last_started_at,omitempty" db:"last_started_at"`
+
+	// Erasure of the payload and error messages
+	RedactedAt *time.Time `json:"redacted_at,omitempty" db:"redacted_at"`
+
+	// HMAC of the payload taken at enqueue time, empty = unsigned
+	PayloadSignature string `json:"-" db:"payload_signature"`
+
+	// Optimistic concurrency, bumped on every update
+	Version int64 `json:"version" db:"version"`
 
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// TaskLock identifies the claim a worker holds on a running task
+// Writes that finish a task must present it so a worker whose lock expired cannot overwrite a newer claim
+type TaskLock struct {
+	WorkerID string
+	Token    int64
+}
+
+// Lock returns the lock held on the task by the worker that claimed it
+func (t *Task) Lock() TaskLock {
+	lock := TaskLock{Token: t.LockToken}
+	if t.LockedBy != nil {
+		lock.WorkerID = *t.LockedBy
+	}
+	return lock
+}
+
+// ClaimFilter narrows which queued tasks a claim may pick up
+// The zero value matches every task that requires no labels
+type ClaimFilter struct {
+	MinPriority *int              // only claim tasks with at least this priority
+	Labels      map[string]string // labels of the claiming worker; tasks requiring others are skipped
+	TypeSlots   map[string]int    // at most this many tasks of each listed type
+}
+
 // TaskHistory represents a detailed status change event in a task's lifecycle
 type TaskHistory struct {
 	ID        int64      `json:"id" db:"id"`
-	TaskID    int64      `json:"task_id" db:"task_id"`
+	TaskID    int64      `json:"-" db:"task_id"` // internal; responses carry the public id in TaskHistoryResponse
 	Status    TaskStatus `json:"status" db:"status"`
 	EventType EventType  `json:"event_type" db:"event_type"`
 
@@ -95,44 +171,97 @@ type TaskHistory struct {
 
 	ErrorMessage *string   `json:"error_message,omitempty" db:"error_message"`
 	WorkerID     *string   `json:"worker_id,omitempty" db:"worker_id"`
+	Actor        *string   `json:"actor,omitempty" db:"actor"` // API principal behind the event, e.g. of payload_accessed
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 }
 
 // CreateTaskRequest represents the API request to create a new task
 type CreateTaskRequest struct {
-	Name           string          `json:"name" binding:"required"`
-	Type           string          `json:"type" binding:"required"`
-	Payload        json.RawMessage `json:"payload"`
-	Priority       int             `json:"priority"`
-	MaxRetries     *int            `json:"max_retries,omitempty"`
-	TimeoutSeconds *int            `json:"timeout_seconds,omitempty"`
-	BackoffSeconds *int            `json:"backoff_seconds,omitempty"`
+	Name              string            `json:"name" binding:"required"`
+	Type              string            `json:"type" binding:"required"`
+	Payload           json.RawMessage   `json:"payload"`
+	Priority          int               `json:"priority"`
+	Tenant            string            `json:"tenant,omitempty"` // subjects the task to the tenant's quotas
+	MaxRetries        *int              `json:"max_retries,omitempty"`
+	TimeoutSeconds    *int              `json:"timeout_seconds,omitempty"`
+	MaxTimeouts       *int              `json:"max_timeouts,omitempty"` // separate retry budget for timeouts
+	BackoffSeconds    *int              `json:"backoff_seconds,omitempty"`
+	RetryStrategy     RetryStrategy     `json:"retry_strategy,omitempty"`
+	RetrySchedule     []string          `json:"retry_schedule,omitempty"` // durations, e.g. ["30s", "5m", "1h"]
+	MaxBackoffSeconds *int              `json:"max_backoff_seconds,omitempty"`
+	RateLimitKey      *string           `json:"rate_limit_key,omitempty"`  // overrides the key derived from the payload
+	RequiredLabels    map[string]string `json:"required_labels,omitempty"` // e.g. {"gpu": "true"}
+	Metadata          map[string]string `json:"metadata,omitempty"`        // handed to the handler; traceparent defaults to the request header
+	PayloadSignature  string            `json:"-"`                         // set by the server from PAYLOAD_SIGNING_KEY, never by clients
+	PublicID          uuid.UUID         `json:"-"`                         // chosen before signing, since the signature covers it; zero = the store generates one
 }
 
 // CreateTaskResponse represents the API response when creating a task
 type CreateTaskResponse struct {
-	ID     int64  `json:"id"`
-	Status string `json:"status"`
+	ID          uuid.UUID `json:"id"`
+	Status      string    `json:"status"`
+	UnknownType bool      `json:"unknown_type,omitempty"` // no worker handles the type, with API_UNKNOWN_TASK_TYPES=warn
+}
+
+// UpdateTaskRequest represents the API request to change a task's status by hand
+// queued requeues the task, failed cancels it; a worker running it loses its lock at the next heartbeat
+type UpdateTaskRequest struct {
+	Status    TaskStatus `json:"status" binding:"required"`
+	LastError *string    `json:"last_error,omitempty"` // replaces the task's last error, e.g. with why it was cancelled
+}
+
+// Validate checks that the requested status may be set by hand
+func (r UpdateTaskRequest) Validate() error {
+	switch r.Status {
+	case TaskStatusQueued, TaskStatusFailed:
+		return nil
+	}
+	return fmt.Errorf("status must be %s or %s", TaskStatusQueued, TaskStatusFailed)
+}
+
+// CreateTasksRequest represents the API request to create up to 1000 tasks at once
+type CreateTasksRequest struct {
+	Tasks []CreateTaskRequest `json:"tasks" binding:"required,min=1,max=1000,dive"`
+}
+
+// CreateTasksResponse represents the API response when creating many tasks, in request order
+type CreateTasksResponse struct {
+	Tasks []CreateTaskResponse `json:"tasks"`
 }
 
 // TaskResponse represents the API response for task details
 type TaskResponse struct {
-	ID             int64           `json:"id"`
-	Name           string          `json:"name"`
-	Type           string          `json:"type"`
-	Payload        json.RawMessage `json:"payload"`
-	Status         string          `json:"status"`
-	Priority       int             `json:"priority"`
-	RetryCount     int             `json:"retry_count"`
-	MaxRetries     int             `json:"max_retries"`
-	LastError      *string         `json:"last_error,omitempty"`
-	TimeoutSeconds int             `json:"timeout_seconds"`
-	CreatedAt      time.Time       `json:"created_at"`
-	UpdatedAt      time.Time       `json:"updated_at"`
+	ID             uuid.UUID         `json:"id"`
+	Name           string            `json:"name"`
+	Type           string            `json:"type"`
+	Payload        json.RawMessage   `json:"payload"`
+	Status         string            `json:"status"`
+	Priority       int               `json:"priority"`
+	Tenant         string            `json:"tenant,omitempty"`
+	RequiredLabels map[string]string `json:"required_labels,omitempty"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	RetryCount     int               `json:"retry_count"`
+	MaxRetries     int               `json:"max_retries"`
+	LastError      *string           `json:"last_error,omitempty"`
+	RetryStrategy  string            `json:"retry_strategy"`
+	TimeoutSeconds int               `json:"timeout_seconds"`
+	TimeoutCount   int               `json:"timeout_count"`
+	CrashCount     int               `json:"crash_count"`
+	QuarantinedAt  *time.Time        `json:"quarantined_at,omitempty"`
+	RedactedAt     *time.Time        `json:"redacted_at,omitempty"`
+	Version        int64             `json:"version"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// QuarantinedTasksResponse represents the API response listing quarantined tasks
+type QuarantinedTasksResponse struct {
+	Tasks []TaskResponse `json:"tasks"`
 }
 
 // TaskHistoryResponse represents the API response for task history
 type TaskHistoryResponse struct {
+	TaskID  uuid.UUID     `json:"task_id"`
 	History []TaskHistory `json:"history"`
 }
 
@@ -143,23 +272,494 @@ type TaskStatsResponse struct {
 	RunningTasks     int64   `json:"running_tasks"`
 	SucceededTasks   int64   `json:"succeeded_tasks"`
 	FailedTasks      int64   `json:"failed_tasks"`
+	QuarantinedTasks int64   `json:"quarantined_tasks"`
 	AvgRetryCount    float64 `json:"avg_retry_count"`
 	TasksWithRetries int64   `json:"tasks_with_retries"`
+	Estimated        bool    `json:"estimated,omitempty"` // counts other than queued and running are sampled estimates
+}
+
+// TypeStats reports the counts and failure figures of one task type
+type TypeStats struct {
+	TaskType      string  `json:"task_type"`
+	TotalTasks    int64   `json:"total_tasks"`
+	Queued        int64   `json:"queued_tasks"`
+	Running       int64   `json:"running_tasks"`
+	Succeeded     int64   `json:"succeeded_tasks"`
+	Failed        int64   `json:"failed_tasks"`
+	Quarantined   int64   `json:"quarantined_tasks"`
+	FailureRate   float64 `json:"failure_rate"` // failed share of finished tasks, 0 when none finished
+	AvgRetryCount float64 `json:"avg_retry_count"`
+}
+
+// FailureRate returns the failed share of finished tasks, 0 when none finished
+func FailureRate(succeeded, failed int64) float64 {
+	if succeeded+failed == 0 {
+		return 0
+	}
+	return float64(failed) / float64(succeeded+failed)
+}
+
+// TypeStatsResponse represents the API response listing stats per task type
+type TypeStatsResponse struct {
+	Types []TypeStats `json:"types"`
+}
+
+// QueueDepth reports the backlog of one task type
+// Claimable tasks are queued and due; the oldest of them shows how far behind the workers are
+type QueueDepth struct {
+	TaskType          string     `json:"task_type"`
+	Queued            int64      `json:"queued"`    // includes tasks scheduled for later
+	Claimable         int64      `json:"claimable"` // due now
+	OldestClaimableAt *time.Time `json:"oldest_claimable_at,omitempty"`
+	OldestAgeSeconds  float64    `json:"oldest_age_seconds"` // time the oldest claimable task has been waiting, 0 if none
+}
+
+// QueueDepthResponse represents the API response listing the backlog per task type
+type QueueDepthResponse struct {
+	Types []QueueDepth `json:"types"`
+}
+
+// ScalingBacklogResponse represents the API response autoscalers poll, summed over the selected task types
+// Backlog counts claimable tasks, so workers added for it have work straight away
+type ScalingBacklogResponse struct {
+	Backlog          int64        `json:"backlog"`
+	Queued           int64        `json:"queued"`
+	OldestAgeSeconds float64      `json:"oldest_age_seconds"`
+	Types            []QueueDepth `json:"types"`
+}
+
+// NewScalingBacklogResponse sums the depths of the given task types, or of all types when none are given
+func NewScalingBacklogResponse(depths []QueueDepth, taskTypes []string) ScalingBacklogResponse {
+	selected := map[string]bool{}
+	for _, t := range taskTypes {
+		selected[t] = true
+	}
+
+	resp := ScalingBacklogResponse{Types: []QueueDepth{}}
+	for _, d := range depths {
+		if len(selected) > 0 && !selected[d.TaskType] {
+			continue
+		}
+		resp.Backlog += d.Claimable
+		resp.Queued += d.Queued
+		resp.OldestAgeSeconds = max(resp.OldestAgeSeconds, d.OldestAgeSeconds)
+		resp.Types = append(resp.Types, d)
+	}
+	return resp
+}
+
+// Percentiles summarizes a distribution of durations in seconds
+type Percentiles struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+}
+
+// LatencyStats reports how long tasks of one type waited and ran
+// Queue wait is the time from a task becoming due to its last attempt starting
+type LatencyStats struct {
+	TaskType  string      `json:"task_type"`
+	Count     int64       `json:"count"`
+	QueueWait Percentiles `json:"queue_wait_seconds"`
+	Execution Percentiles `json:"execution_seconds"`
+}
+
+// LatencyStatsResponse represents the API response for latency percentiles over a window
+type LatencyStatsResponse struct {
+	WindowSeconds float64        `json:"window_seconds"`
+	Types         []LatencyStats `json:"types"`
+}
+
+// SuccessRate reports how many tasks of one type finished within a window and the succeeded share of them
+type SuccessRate struct {
+	TaskType    string  `json:"task_type"`
+	Succeeded   int64   `json:"succeeded"`
+	Failed      int64   `json:"failed"`
+	SuccessRate float64 `json:"success_rate"`
+}
+
+// SuccessRateResponse represents the API response for success rates over a window
+// Types without finished tasks in the window are left out
+type SuccessRateResponse struct {
+	WindowSeconds float64       `json:"window_seconds"`
+	Types         []SuccessRate `json:"types"`
+}
+
+// RetryBucket counts the finished tasks that used a given number of retries
+type RetryBucket struct {
+	Retries   int   `json:"retries"`
+	Succeeded int64 `json:"succeeded"`
+	Failed    int64 `json:"failed"`
+}
+
+// RetryDistribution is the retry count histogram of one task type, by ascending retry count
+// Only retry counts some finished task used are included
+type RetryDistribution struct {
+	TaskType string        `json:"task_type"`
+	Buckets  []RetryBucket `json:"buckets"`
+}
+
+// RetryDistributionResponse represents the API response for retry counts of tasks finished within a window
+type RetryDistributionResponse struct {
+	WindowSeconds float64             `json:"window_seconds"`
+	Overall       []RetryBucket       `json:"overall"`
+	Types         []RetryDistribution `json:"types"`
+}
+
+// MergeRetryBuckets sums the buckets of all distributions by retry count, by ascending retry count
+func MergeRetryBuckets(distributions []RetryDistribution) []RetryBucket {
+	byRetries := map[int]*RetryBucket{}
+	for _, d := range distributions {
+		for _, b := range d.Buckets {
+			merged, ok := byRetries[b.Retries]
+			if !ok {
+				merged = &RetryBucket{Retries: b.Retries}
+				byRetries[b.Retries] = merged
+			}
+			merged.Succeeded += b.Succeeded
+			merged.Failed += b.Failed
+		}
+	}
+
+	buckets := make([]RetryBucket, 0, len(byRetries))
+	for _, b := range byRetries {
+		buckets = append(buckets, *b)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Retries < buckets[j].Retries })
+	return buckets
+}
+
+// TimeRange bounds statistics to [From, To); a zero bound is open
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// IsZero reports whether both bounds are open
+func (r TimeRange) IsZero() bool {
+	return r.From.IsZero() && r.To.IsZero()
+}
+
+// StatsBucket is the width of a throughput bucket
+type StatsBucket string
+
+const (
+	StatsBucketMinute StatsBucket = "1m"
+	StatsBucketHour   StatsBucket = "1h"
+	StatsBucketDay    StatsBucket = "1d"
+)
+
+// IsValid reports whether b is a supported bucket width
+func (b StatsBucket) IsValid() bool {
+	_, ok := statsBucketWidths[b]
+	return ok
+}
+
+// Duration returns the width of the bucket
+func (b StatsBucket) Duration() time.Duration {
+	return statsBucketWidths[b]
+}
+
+var statsBucketWidths = map[StatsBucket]time.Duration{
+	StatsBucketMinute: time.Minute,
+	StatsBucketHour:   time.Hour,
+	StatsBucketDay:    24 * time.Hour,
+}
+
+// ThroughputBucket counts the tasks created and finished within one bucket
+type ThroughputBucket struct {
+	Start     time.Time `json:"start"`
+	Created   int64     `json:"created"`
+	Succeeded int64     `json:"succeeded"`
+	Failed    int64     `json:"failed"`
+}
+
+// ThroughputResponse represents the API response for time-bucketed throughput
+type ThroughputResponse struct {
+	Bucket        StatsBucket        `json:"bucket"`
+	WindowSeconds float64            `json:"window_seconds"`
+	Buckets       []ThroughputBucket `json:"buckets"`
+}
+
+// ConcurrencyLimit caps how many tasks of a type may run at once across all workers
+type ConcurrencyLimit struct {
+	TaskType   string    `json:"task_type" db:"task_type"`
+	MaxRunning int       `json:"max_running" db:"max_running"`
+	Running    int64     `json:"running"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SetConcurrencyLimitRequest represents the API request to set a concurrency limit
+type SetConcurrencyLimitRequest struct {
+	MaxRunning int `json:"max_running" binding:"required,min=1"`
+}
+
+// ConcurrencyLimitsResponse represents the API response listing concurrency limits
+type ConcurrencyLimitsResponse struct {
+	Limits []ConcurrencyLimit `json:"limits"`
+}
+
+// RateLimit caps how many tasks sharing a rate limit key may start within a window
+// The key is read from a top-level payload field, e.g. "to" for at most one email per recipient per minute
+type RateLimit struct {
+	TaskType      string    `json:"task_type" db:"task_type"`
+	KeyField      string    `json:"key_field" db:"key_field"`
+	MaxPerWindow  int       `json:"max_per_window" db:"max_per_window"`
+	WindowSeconds int       `json:"window_seconds" db:"window_seconds"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SetRateLimitRequest represents the API request to set a rate limit
+type SetRateLimitRequest struct {
+	KeyField      string `json:"key_field" binding:"required"`
+	MaxPerWindow  int    `json:"max_per_window" binding:"required,min=1"`
+	WindowSeconds int    `json:"window_seconds" binding:"required,min=1"`
+}
+
+// RateLimitsResponse represents the API response listing rate limits
+type RateLimitsResponse struct {
+	Limits []RateLimit `json:"limits"`
+}
+
+// CircuitBreaker tracks consecutive failures of a task type
+// While open, no worker claims tasks of the type
+type CircuitBreaker struct {
+	TaskType            string     `json:"task_type" db:"task_type"`
+	State               string     `json:"state"` // "open" or "closed"
+	ConsecutiveFailures int        `json:"consecutive_failures" db:"consecutive_failures"`
+	OpenedUntil         *time.Time `json:"opened_until,omitempty" db:"opened_until"`
+	TripCount           int        `json:"trip_count" db:"trip_count"`
+	LastError           *string    `json:"last_error,omitempty" db:"last_error"`
+	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// OpenCircuitBreakerRequest represents the API request to open a circuit breaker manually
+type OpenCircuitBreakerRequest struct {
+	CooldownSeconds int `json:"cooldown_seconds" binding:"required,min=1"`
+}
+
+// CircuitBreakersResponse represents the API response listing circuit breakers
+type CircuitBreakersResponse struct {
+	Breakers []CircuitBreaker `json:"breakers"`
+}
+
+// PauseScopeQueue is the pause scope that stops claiming of every task type
+const PauseScopeQueue = "*"
+
+// PauseAction identifies an entry in the pause audit trail
+type PauseAction string
+
+const (
+	PauseActionPaused  PauseAction = "paused"
+	PauseActionResumed PauseAction = "resumed"
+)
+
+// QueuePause stops workers from claiming tasks of a type, or of every type
+type QueuePause struct {
+	Scope    string    `json:"scope" db:"scope"` // Task type, or "*" for the whole queue
+	Reason   *string   `json:"reason,omitempty" db:"reason"`
+	PausedAt time.Time `json:"paused_at" db:"paused_at"`
+}
+
+// PauseEvent records a pause or resume performed through the API
+type PauseEvent struct {
+	ID        int64       `json:"id" db:"id"`
+	Scope     string      `json:"scope" db:"scope"`
+	Action    PauseAction `json:"action" db:"action"`
+	Reason    *string     `json:"reason,omitempty" db:"reason"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+}
+
+// PauseRequest represents the optional API request body of a pause or resume
+type PauseRequest struct {
+	Reason *string `json:"reason"`
+}
+
+// PausesResponse represents the API response listing active pauses
+type PausesResponse struct {
+	QueuePaused bool         `json:"queue_paused"`
+	Pauses      []QueuePause `json:"pauses"`
+}
+
+// PauseHistoryResponse represents the API response listing the pause audit trail
+type PauseHistoryResponse struct {
+	Events []PauseEvent `json:"events"`
+}
+
+// MaintenanceMode is the queue-wide maintenance toggle
+// While enabled new tasks are rejected; StopClaims also stops workers from claiming
+type MaintenanceMode struct {
+	Enabled    bool      `json:"enabled" db:"enabled"`
+	StopClaims bool      `json:"stop_claims" db:"stop_claims"`
+	Reason     *string   `json:"reason,omitempty" db:"reason"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SetMaintenanceRequest represents the API request to toggle maintenance mode
+type SetMaintenanceRequest struct {
+	Enabled    *bool   `json:"enabled" binding:"required"`
+	StopClaims bool    `json:"stop_claims"`
+	Reason     *string `json:"reason"`
+}
+
+// ReadOnlyMode is an API server's read-only mode, held in memory by each server
+type ReadOnlyMode struct {
+	Enabled   bool      `json:"enabled"`
+	Reason    *string   `json:"reason,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SetReadOnlyRequest represents the API request to toggle read-only mode
+type SetReadOnlyRequest struct {
+	Enabled *bool   `json:"enabled" binding:"required"`
+	Reason  *string `json:"reason"`
+}
+
+// DefaultWorkerSettings is the worker_id of the settings row that applies to every worker
+const DefaultWorkerSettings = "default"
+
+// WorkerSettings overrides worker configuration at runtime without a restart
+// Nil fields keep the worker's configured value
+type WorkerSettings struct {
+	WorkerID            string    `json:"worker_id" db:"worker_id"`
+	MaxConcurrency      *int      `json:"max_concurrency,omitempty" db:"max_concurrency"`
+	PollIntervalSeconds *int      `json:"poll_interval_seconds,omitempty" db:"poll_interval_seconds"`
+	UpdatedAt           time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SetWorkerSettingsRequest represents the API request to override worker settings
+type SetWorkerSettingsRequest struct {
+	MaxConcurrency      *int `json:"max_concurrency" binding:"omitempty,min=1"`
+	PollIntervalSeconds *int `json:"poll_interval_seconds" binding:"omitempty,min=1"`
+}
+
+// WorkerSettingsResponse represents the API response listing worker settings
+type WorkerSettingsResponse struct {
+	Settings []WorkerSettings `json:"settings"`
+}
+
+// Worker statuses reported by the workers listing
+const (
+	WorkerStatusActive  = "active"
+	WorkerStatusStale   = "stale"
+	WorkerStatusStopped = "stopped"
+)
+
+// WorkerInfo describes a registered worker
+type WorkerInfo struct {
+	ID          string            `json:"id" db:"id"`
+	Hostname    string            `json:"hostname" db:"hostname"`
+	Version     string            `json:"version" db:"version"`
+	Concurrency int               `json:"concurrency" db:"concurrency"`
+	InFlight    int               `json:"in_flight" db:"in_flight"`
+	Labels      map[string]string `json:"labels,omitempty" db:"labels"`
+	TaskTypes   []string          `json:"task_types,omitempty" db:"handler_types"` // lowercase types the worker has handlers for
+	StartedAt   time.Time         `json:"started_at" db:"started_at"`
+	LastSeen    time.Time         `json:"last_seen" db:"last_seen"`
+	StoppedAt   *time.Time        `json:"stopped_at,omitempty" db:"stopped_at"`
+	Status      string            `json:"status"`
+	LockedTasks int64             `json:"locked_tasks"`
+	WorkerCounters
+}
+
+// WorkerCounters are a worker's task totals since it started, reported with its heartbeats
+// Failed executions include timeouts and crashes; executions interrupted by shutdown are not counted
+type WorkerCounters struct {
+	TasksSucceeded int64   `json:"tasks_succeeded" db:"tasks_succeeded"`
+	TasksFailed    int64   `json:"tasks_failed" db:"tasks_failed"`
+	BusySeconds    float64 `json:"busy_seconds" db:"busy_seconds"` // handler execution time of the counted executions
+}
+
+// WorkerHeartbeat is the current load and task totals a worker reports on every heartbeat
+type WorkerHeartbeat struct {
+	Concurrency int
+	InFlight    int
+	WorkerCounters
+}
+
+// WorkerStatsResponse represents the API response for the throughput and health of one worker
+type WorkerStatsResponse struct {
+	WorkerID           string    `json:"worker_id"`
+	Status             string    `json:"status"`
+	StartedAt          time.Time `json:"started_at"`
+	LastSeen           time.Time `json:"last_seen"`
+	TasksProcessed     int64     `json:"tasks_processed"`
+	TasksSucceeded     int64     `json:"tasks_succeeded"`
+	TasksFailed        int64     `json:"tasks_failed"`
+	FailureRate        float64   `json:"failure_rate"`
+	AvgDurationSeconds float64   `json:"avg_duration_seconds"` // 0 until a task was processed
+	Concurrency        int       `json:"concurrency"`
+	InFlight           int       `json:"in_flight"`
+	LockedTasks        int64     `json:"locked_tasks"`
+}
+
+// NewWorkerStatsResponse derives the stats of a registered worker from its heartbeat totals
+func NewWorkerStatsResponse(w WorkerInfo) WorkerStatsResponse {
+	stats := WorkerStatsResponse{
+		WorkerID:       w.ID,
+		Status:         w.Status,
+		StartedAt:      w.StartedAt,
+		LastSeen:       w.LastSeen,
+		TasksProcessed: w.TasksSucceeded + w.TasksFailed,
+		TasksSucceeded: w.TasksSucceeded,
+		TasksFailed:    w.TasksFailed,
+		FailureRate:    FailureRate(w.TasksSucceeded, w.TasksFailed),
+		Concurrency:    w.Concurrency,
+		InFlight:       w.InFlight,
+		LockedTasks:    w.LockedTasks,
+	}
+	if stats.TasksProcessed > 0 {
+		stats.AvgDurationSeconds = w.BusySeconds / float64(stats.TasksProcessed)
+	}
+	return stats
+}
+
+// WorkersResponse represents the API response listing workers
+type WorkersResponse struct {
+	Active  int          `json:"active"`
+	Workers []WorkerInfo `json:"workers"`
+}
+
+// TableStats reports the size and churn of a queue table, summed over its partitions
+type TableStats struct {
+	Table           string     `json:"table"`
+	LiveTuples      int64      `json:"live_tuples"`
+	DeadTuples      int64      `json:"dead_tuples"`
+	LastAutovacuum  *time.Time `json:"last_autovacuum,omitempty"`
+	LastAutoanalyze *time.Time `json:"last_autoanalyze,omitempty"`
+}
+
+// DeadTupleRatio returns the share of dead tuples among all tuples, 0 for an empty table
+func (s TableStats) DeadTupleRatio() float64 {
+	total := s.LiveTuples + s.DeadTuples
+	if total == 0 {
+		return 0
+	}
+	return float64(s.DeadTuples) / float64(total)
 }
 
 // ToTaskResponse converts a Task to TaskResponse
 func (t *Task) ToTaskResponse() TaskResponse {
 	return TaskResponse{
-		ID:             t.ID,
+		ID:             t.PublicID,
 		Name:           t.Name,
 		Type:           t.Type,
 		Payload:        t.Payload,
 		Status:         t.Status.String(),
 		Priority:       t.Priority,
+		Tenant:         t.Tenant,
+		RequiredLabels: t.RequiredLabels,
+		Metadata:       t.Metadata,
 		RetryCount:     t.RetryCount,
 		MaxRetries:     t.MaxRetries,
 		LastError:      t.LastError,
+		RetryStrategy:  t.RetryStrategy.String(),
 		TimeoutSeconds: t.TimeoutSeconds,
+		TimeoutCount:   t.TimeoutCount,
+		CrashCount:     t.CrashCount,
+		QuarantinedAt:  t.QuarantinedAt,
+		RedactedAt:     t.RedactedAt,
+		Version:        t.Version,
 		CreatedAt:      t.CreatedAt,
 		UpdatedAt:      t.UpdatedAt,
 	}
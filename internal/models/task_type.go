@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// TaskTypeInfo is a task type tasks may be created for: one a registered worker handles or an operator registered
+type TaskTypeInfo struct {
+	TaskType   string     `json:"task_type"`
+	Workers    int        `json:"workers"`             // registered workers with a handler for it, whether active, stale or stopped
+	LastSeen   *time.Time `json:"last_seen,omitempty"` // latest heartbeat of those workers
+	Registered bool       `json:"registered"`          // registered through the API
+}
+
+// TaskTypesResponse represents the API response listing task types
+type TaskTypesResponse struct {
+	Types []TaskTypeInfo `json:"types"`
+}
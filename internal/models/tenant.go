@@ -0,0 +1,72 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultTenantQuota names the quota applied to tenants without one of their own
+const DefaultTenantQuota = "*"
+
+// tenantMaxLen matches the tasks.tenant and tenant_quotas.tenant columns
+const tenantMaxLen = 100
+
+// TenantQuota caps what one tenant may hold in the queue
+// A nil cap is unlimited, or taken from the DefaultTenantQuota entry when one exists
+type TenantQuota struct {
+	Tenant     string    `json:"tenant"`
+	MaxQueued  *int      `json:"max_queued,omitempty"`  // queued tasks, including those scheduled for later
+	MaxRunning *int      `json:"max_running,omitempty"` // tasks running at once across all workers
+	Queued     int64     `json:"queued_tasks"`
+	Running    int64     `json:"running_tasks"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// SetTenantQuotaRequest represents the request body for setting a tenant quota
+type SetTenantQuotaRequest struct {
+	MaxQueued  *int `json:"max_queued,omitempty"`
+	MaxRunning *int `json:"max_running,omitempty"`
+}
+
+// Validate checks the request
+func (r *SetTenantQuotaRequest) Validate() error {
+	if r.MaxQueued == nil && r.MaxRunning == nil {
+		return errors.New("max_queued or max_running is required")
+	}
+	if r.MaxQueued != nil && *r.MaxQueued < 0 {
+		return errors.New("max_queued must not be negative")
+	}
+	if r.MaxRunning != nil && *r.MaxRunning < 0 {
+		return errors.New("max_running must not be negative")
+	}
+	return nil
+}
+
+// TenantQuotasResponse represents the API response for listing tenant quotas
+type TenantQuotasResponse struct {
+	Quotas []TenantQuota `json:"quotas"`
+}
+
+// ValidateTenant checks that a task's tenant is usable as a path segment and key; empty means no tenant
+func ValidateTenant(tenant string) error {
+	if len(tenant) > tenantMaxLen {
+		return errors.New("tenant must be at most 100 characters")
+	}
+	for _, r := range tenant {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return errors.New("tenant may only contain letters, digits, '-', '_' and '.'")
+		}
+	}
+	return nil
+}
+
+// ValidateTenantQuotaName checks the tenant of a quota, which may also be DefaultTenantQuota
+func ValidateTenantQuotaName(tenant string) error {
+	if tenant == DefaultTenantQuota {
+		return nil
+	}
+	if tenant == "" {
+		return errors.New("tenant is required")
+	}
+	return ValidateTenant(tenant)
+}
@@ -0,0 +1,123 @@
+// Package payloadschema validates task payloads against JSON Schemas registered per task type
+// Schemas are self-contained: $ref may point within the schema but never to files or URLs
+package payloadschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// schemaURL names the schema being compiled; nothing is loaded from it
+const schemaURL = "urn:taskqueue:payload-schema"
+
+// maxFieldErrors caps how many field errors a rejected payload reports
+const maxFieldErrors = 20
+
+// printer renders the validator's messages
+var printer = message.NewPrinter(language.English)
+
+// errExternalRef rejects schemas that refer to other documents
+var errExternalRef = errors.New("schemas cannot refer to other documents")
+
+// Schema is a compiled payload schema
+type Schema struct {
+	compiled *jsonschema.Schema
+}
+
+// FieldError is one way a payload breaks its schema
+type FieldError struct {
+	Field   string `json:"field"` // JSON pointer into the payload, e.g. /to/0; empty for the payload itself
+	Message string `json:"message"`
+}
+
+// Compile parses and compiles a JSON Schema, draft 2020-12 unless its $schema names another draft
+func Compile(raw json.RawMessage) (*Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.UseLoader(refusingLoader{})
+	compiler.AssertFormat()
+	if err := compiler.AddResource(schemaURL, doc); err != nil {
+		return nil, err
+	}
+	compiled, err := compiler.Compile(schemaURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{compiled: compiled}, nil
+}
+
+// Validate returns the ways payload breaks the schema, nil when it matches
+func (s *Schema) Validate(payload json.RawMessage) []FieldError {
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(payload))
+	if err != nil {
+		return []FieldError{{Message: "payload is not valid JSON"}}
+	}
+
+	err = s.compiled.Validate(instance)
+	if err == nil {
+		return nil
+	}
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return []FieldError{{Message: err.Error()}}
+	}
+
+	var fieldErrs []FieldError
+	seen := map[FieldError]bool{}
+	collect(validationErr, &fieldErrs, seen)
+	sort.SliceStable(fieldErrs, func(i, j int) bool { return fieldErrs[i].Field < fieldErrs[j].Field })
+	if len(fieldErrs) > maxFieldErrors {
+		fieldErrs = fieldErrs[:maxFieldErrors]
+	}
+	if len(fieldErrs) == 0 {
+		fieldErrs = []FieldError{{Message: validationErr.Error()}}
+	}
+	return fieldErrs
+}
+
+// collect appends the failing keywords under err: only leaves say what is wrong, the rest group them
+func collect(err *jsonschema.ValidationError, fieldErrs *[]FieldError, seen map[FieldError]bool) {
+	if len(err.Causes) > 0 {
+		for _, cause := range err.Causes {
+			collect(cause, fieldErrs, seen)
+		}
+		return
+	}
+
+	fe := FieldError{Message: err.ErrorKind.LocalizedString(printer)}
+	if len(err.InstanceLocation) > 0 {
+		fe.Field = "/" + strings.Join(escapePointer(err.InstanceLocation), "/")
+	}
+	if !seen[fe] {
+		seen[fe] = true
+		*fieldErrs = append(*fieldErrs, fe)
+	}
+}
+
+// escapePointer escapes the tokens of a JSON pointer
+func escapePointer(tokens []string) []string {
+	escaped := make([]string, len(tokens))
+	for i, token := range tokens {
+		escaped[i] = strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+	}
+	return escaped
+}
+
+// refusingLoader keeps the compiler from reading files or fetching URLs named by $ref
+type refusingLoader struct{}
+
+func (refusingLoader) Load(url string) (any, error) {
+	return nil, fmt.Errorf("%w: %s", errExternalRef, url)
+}
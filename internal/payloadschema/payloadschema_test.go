@@ -0,0 +1,59 @@
+package payloadschema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const emailSchema = `{
+	"type": "object",
+	"required": ["to", "subject"],
+	"properties": {
+		"to": {"type": "array", "minItems": 1, "items": {"type": "string", "format": "email"}},
+		"subject": {"type": "string", "maxLength": 20},
+		"priority": {"$ref": "#/$defs/priority"}
+	},
+	"$defs": {"priority": {"enum": ["low", "high"]}}
+}`
+
+func TestValidate(t *testing.T) {
+	schema, err := Compile(json.RawMessage(emailSchema))
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	if errs := schema.Validate(json.RawMessage(`{"to": ["a@example.com"], "subject": "Hi", "priority": "low"}`)); errs != nil {
+		t.Errorf("Validate(valid) = %v", errs)
+	}
+
+	errs := schema.Validate(json.RawMessage(`{"to": ["not an address"], "priority": "urgent"}`))
+	fields := map[string]string{}
+	for _, fe := range errs {
+		fields[fe.Field] = fe.Message
+	}
+	if len(errs) != 3 || fields[""] == "" || fields["/to/0"] == "" || fields["/priority"] == "" {
+		t.Errorf("Validate(invalid) = %+v, want errors for the payload, /to/0 and /priority", errs)
+	}
+	if !strings.Contains(fields[""], "subject") {
+		t.Errorf("missing property error = %q, want it to name subject", fields[""])
+	}
+
+	if errs := schema.Validate(json.RawMessage(`{not json`)); len(errs) != 1 {
+		t.Errorf("Validate(not JSON) = %v", errs)
+	}
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	for name, raw := range map[string]string{
+		"not JSON":      `{"type":`,
+		"bad keyword":   `{"type": "strin"}`,
+		"file ref":      `{"$ref": "file:///etc/passwd"}`,
+		"remote ref":    `{"$ref": "https://example.com/schema.json"}`,
+		"missing local": `{"$ref": "#/$defs/missing"}`,
+	} {
+		if _, err := Compile(json.RawMessage(raw)); err == nil {
+			t.Errorf("Compile(%s) accepted %s", name, raw)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package payloadsig
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSignSurvivesNormalization(t *testing.T) {
+	s := New("k3y")
+	id := uuid.MustParse("01928f3a-7c2e-7d45-9b1a-3f0e5c8d2a61")
+
+	signature, err := s.Sign(id, "Send_Email", json.RawMessage(`{"to": "a@example.com", "amount": 1.50, "tags": ["x", "y"], "n": 1e2}`))
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	// As Postgres returns it from a JSONB column
+	normalized := json.RawMessage(`{"n": 100, "to": "a@example.com", "tags": ["x", "y"], "amount": 1.5}`)
+	if err := s.Verify(id, "send_email", normalized, signature); err != nil {
+		t.Errorf("Verify(normalized) error = %v", err)
+	}
+
+	// The same payload and signature copied onto another task
+	copied := uuid.MustParse("01928f3a-7c2e-7d45-9b1a-3f0e5c8d2a62")
+
+	tests := []struct {
+		name      string
+		publicID  uuid.UUID
+		taskType  string
+		payload   string
+		signature string
+		want      error
+	}{
+		{"changed value", id, "send_email", `{"n": 100, "to": "b@example.com", "tags": ["x", "y"], "amount": 1.5}`, signature, ErrMismatch},
+		{"reordered array", id, "send_email", `{"n": 100, "to": "a@example.com", "tags": ["y", "x"], "amount": 1.5}`, signature, ErrMismatch},
+		{"other type", id, "run_query", string(normalized), signature, ErrMismatch},
+		{"copied to another task", copied, "send_email", string(normalized), signature, ErrMismatch},
+		{"unsigned", id, "send_email", string(normalized), "", ErrUnsigned},
+	}
+	for _, tt := range tests {
+		if err := s.Verify(tt.publicID, tt.taskType, json.RawMessage(tt.payload), tt.signature); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Verify() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	if err := New("other").Verify(id, "send_email", normalized, signature); !errors.Is(err, ErrMismatch) {
+		t.Errorf("Verify() with another key error = %v, want ErrMismatch", err)
+	}
+}
+
+func TestCanonicalEmptyPayload(t *testing.T) {
+	got, err := Canonical(nil)
+	if err != nil || string(got) != "{}" {
+		t.Errorf("Canonical(nil) = %s, %v, want {}", got, err)
+	}
+	if New("") != nil {
+		t.Error("New(\"\") is not nil")
+	}
+}
@@ -0,0 +1,25 @@
+//go:build cgo && (linux || darwin || freebsd)
+
+package plugins
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// open loads the plugin at path and returns its Handlers function
+func open(path string) (func() ([]any, error), error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := p.Lookup(symbol)
+	if err != nil {
+		return nil, err
+	}
+	handlers, ok := sym.(func() ([]any, error))
+	if !ok {
+		return nil, fmt.Errorf("%s is a %T, want func() ([]any, error)", symbol, sym)
+	}
+	return handlers, nil
+}
@@ -0,0 +1,10 @@
+//go:build !cgo || !(linux || darwin || freebsd)
+
+package plugins
+
+import "errors"
+
+// open fails: Go loads plugins only into binaries built with cgo on linux, darwin or freebsd
+func open(string) (func() ([]any, error), error) {
+	return nil, errors.New("this worker cannot load plugins; build it with CGO_ENABLED=1 on linux, darwin or freebsd")
+}
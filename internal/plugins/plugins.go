@@ -0,0 +1,104 @@
+// Package plugins loads task handlers from Go plugins (.so files built with -buildmode=plugin) at worker startup,
+// so handlers that are not open source can ship separately from the worker binary
+//
+// A plugin's main package exports
+//
+//	func Handlers() ([]any, error)
+//
+// returning values with the methods
+//
+//	Type() string
+//	Execute(ctx context.Context, payload json.RawMessage) error
+//
+// The contract uses only standard library types, since plugins cannot import the worker's internal packages
+// An Execute error with a method Permanent() bool returning true fails the task at once,
+// and one with RetryAfter() time.Duration suggests the delay before the next attempt
+//
+// Go only loads plugins built with the same toolchain and the same versions of every shared dependency
+// as the worker, into a worker built with cgo on linux, darwin or freebsd
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// symbol is the name plugins export their handlers under
+const symbol = "Handlers"
+
+// handler is what a plugin's handlers implement
+type handler interface {
+	Type() string
+	Execute(ctx context.Context, payload json.RawMessage) error
+}
+
+// Load opens every plugin in paths and returns their handlers
+func Load(paths []string) ([]models.TaskHandler, error) {
+	var handlers []models.TaskHandler
+	for _, path := range paths {
+		handlersOf, err := open(path)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", path, err)
+		}
+
+		values, err := handlersOf()
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", path, err)
+		}
+		for _, value := range values {
+			adapted, err := adapt(value)
+			if err != nil {
+				return nil, fmt.Errorf("plugin %s: %w", path, err)
+			}
+			handlers = append(handlers, adapted)
+		}
+	}
+	return handlers, nil
+}
+
+// adapt wraps one value a plugin returned as a task handler
+func adapt(value any) (models.TaskHandler, error) {
+	h, ok := value.(handler)
+	if !ok {
+		return nil, fmt.Errorf("%T has no Type() string and Execute(context.Context, json.RawMessage) error methods", value)
+	}
+	if h.Type() == "" {
+		return nil, fmt.Errorf("%T has an empty task type", value)
+	}
+	return &pluginHandler{handler: h}, nil
+}
+
+// pluginHandler translates a plugin handler's errors into the worker's
+type pluginHandler struct {
+	handler handler
+}
+
+func (p *pluginHandler) Type() models.TaskType {
+	return models.TaskType(p.handler.Type())
+}
+
+func (p *pluginHandler) Execute(ctx context.Context, payload json.RawMessage) error {
+	return handlerError(p.handler.Execute(ctx, payload))
+}
+
+// handlerError marks the errors plugins flag as permanent or delayed the way the worker recognizes
+func handlerError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var permanent interface{ Permanent() bool }
+	if errors.As(err, &permanent) && permanent.Permanent() {
+		return models.Permanent(err)
+	}
+	var delayed interface{ RetryAfter() time.Duration }
+	if errors.As(err, &delayed) && delayed.RetryAfter() > 0 {
+		return models.RetryAfter(err, delayed.RetryAfter())
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+type fakeHandler struct {
+	taskType string
+	err      error
+}
+
+func (f fakeHandler) Type() string { return f.taskType }
+
+func (f fakeHandler) Execute(context.Context, json.RawMessage) error { return f.err }
+
+// pluginError is how a plugin, which cannot import models, marks its errors
+type pluginError struct {
+	permanent bool
+	delay     time.Duration
+}
+
+func (e pluginError) Error() string             { return "plugin failure" }
+func (e pluginError) Permanent() bool           { return e.permanent }
+func (e pluginError) RetryAfter() time.Duration { return e.delay }
+
+func TestAdapt(t *testing.T) {
+	if _, err := adapt("not a handler"); err == nil {
+		t.Error("adapt() accepted a value without handler methods")
+	}
+	if _, err := adapt(fakeHandler{}); err == nil {
+		t.Error("adapt() accepted an empty task type")
+	}
+
+	h, err := adapt(fakeHandler{taskType: "invoice_pdf"})
+	if err != nil {
+		t.Fatalf("adapt() error = %v", err)
+	}
+	if h.Type() != "invoice_pdf" {
+		t.Errorf("Type() = %q", h.Type())
+	}
+	if err := h.Execute(context.Background(), nil); err != nil {
+		t.Errorf("Execute() error = %v", err)
+	}
+}
+
+func TestHandlerError(t *testing.T) {
+	if err := handlerError(fmt.Errorf("wrapped: %w", pluginError{permanent: true})); !models.IsPermanent(err) {
+		t.Errorf("permanent plugin error = %v, want permanent", err)
+	}
+
+	err := handlerError(pluginError{delay: time.Minute})
+	if delay, ok := models.RetryDelay(err); !ok || delay != time.Minute || models.IsPermanent(err) {
+		t.Errorf("delayed plugin error = %v, delay %s", err, delay)
+	}
+
+	plain := errors.New("plain")
+	if err := handlerError(plain); err != plain {
+		t.Errorf("plain error = %v, want it unchanged", err)
+	}
+	if err := handlerError(pluginError{}); models.IsPermanent(err) {
+		t.Errorf("unflagged plugin error = %v, want retryable", err)
+	}
+}
@@ -0,0 +1,239 @@
+// Package relay turns rows of an application-owned outbox table into tasks
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/payloadsig"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Config holds the relay settings
+type Config struct {
+	Table        string        // Outbox table, optionally schema-qualified ("app.outbox")
+	BatchSize    int           // Rows read per query
+	PollInterval time.Duration // Wait between queries once the outbox is drained
+	GapTimeout   time.Duration // How long a missing id holds back later rows before it is skipped
+	TablePrefix  string        // Table prefix of the queue tables, applied to outbox_checkpoints
+
+	// PayloadSigner signs the payload of every relayed task, like the API server does (nil = unsigned)
+	PayloadSigner *payloadsig.Signer
+}
+
+// Relay copies outbox rows into the task store
+// Rows are read in id order from the last checkpoint; the checkpoint is advanced only after
+// their tasks were created, so delivery is at-least-once
+type Relay struct {
+	pool   *pgxpool.Pool
+	store  storage.Store
+	config Config
+	table  string // sanitized identifier of config.Table
+
+	checkpoints string // sanitized identifier of the outbox_checkpoints table
+
+	lastID    int64
+	loaded    bool      // lastID has been read from outbox_checkpoints
+	gapSince  time.Time // when the relay first waited on the id after lastID, zero if not waiting
+	gapWaitID int64     // the missing id being waited on
+}
+
+// outboxRow is a single outbox entry
+type outboxRow struct {
+	id      int64
+	request []byte
+}
+
+// NewRelay creates a relay reading config.Table through pool and creating tasks in store
+func NewRelay(pool *pgxpool.Pool, store storage.Store, config Config) *Relay {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 1 * time.Second
+	}
+
+	return &Relay{
+		pool:   pool,
+		store:  store,
+		config: config,
+		table:  pgx.Identifier(strings.Split(config.Table, ".")).Sanitize(),
+
+		checkpoints: pgx.Identifier{config.TablePrefix + "outbox_checkpoints"}.Sanitize(),
+	}
+}
+
+// Run relays outbox rows until ctx is done
+// Errors are logged and retried after the poll interval
+func (r *Relay) Run(ctx context.Context) error {
+	slog.Info("Outbox relay started", "table", r.config.Table, "batch_size", r.config.BatchSize)
+
+	for {
+		relayed, err := r.relayBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.Error("Failed to relay outbox rows", "table", r.config.Table, "error", err)
+		}
+
+		// A full batch means more rows are probably waiting
+		if err == nil && relayed == r.config.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.config.PollInterval):
+		}
+	}
+}
+
+// relayBatch creates tasks for the next batch of outbox rows and saves the checkpoint
+// Returns the number of rows consumed, including skipped invalid rows
+func (r *Relay) relayBatch(ctx context.Context) (int, error) {
+	if !r.loaded {
+		if err := r.loadCheckpoint(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	rows, err := r.fetch(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	start := r.lastID
+	relayed := 0
+	for _, row := range rows {
+		if !r.ready(row.id) {
+			break
+		}
+
+		if err := r.relayRow(ctx, row); err != nil {
+			// Keep the progress made so far
+			if saveErr := r.saveCheckpoint(ctx, start); saveErr != nil {
+				slog.Error("Failed to save outbox checkpoint", "table", r.config.Table, "error", saveErr)
+			}
+			return relayed, err
+		}
+
+		r.lastID = row.id
+		relayed++
+	}
+
+	if err := r.saveCheckpoint(ctx, start); err != nil {
+		return relayed, err
+	}
+	return relayed, nil
+}
+
+// ready reports whether the row with id may be relayed next
+// Ids are assigned before commit, so a missing id may still appear from a slower transaction;
+// later rows wait for it up to the gap timeout, after which it is treated as rolled back
+func (r *Relay) ready(id int64) bool {
+	if id == r.lastID+1 {
+		r.gapSince = time.Time{}
+		return true
+	}
+
+	if r.gapSince.IsZero() || r.gapWaitID != r.lastID+1 {
+		r.gapSince = time.Now()
+		r.gapWaitID = r.lastID + 1
+	}
+	if time.Since(r.gapSince) < r.config.GapTimeout {
+		return false
+	}
+
+	slog.Warn("Skipping outbox id gap", "table", r.config.Table, "from", r.lastID+1, "to", id-1)
+	r.gapSince = time.Time{}
+	return true
+}
+
+// relayRow creates the task described by an outbox row
+// Rows that can never become a task are logged and skipped so they do not block the outbox
+func (r *Relay) relayRow(ctx context.Context, row outboxRow) error {
+	var req models.CreateTaskRequest
+	if err := json.Unmarshal(row.request, &req); err != nil || req.Name == "" || req.Type == "" {
+		slog.Error("Skipping invalid outbox row", "table", r.config.Table, "id", row.id, "error", err)
+		return nil
+	}
+
+	if r.config.PayloadSigner != nil {
+		publicID, err := uuid.NewV7()
+		if err != nil {
+			return fmt.Errorf("outbox row %d: %w", row.id, err)
+		}
+		signature, err := r.config.PayloadSigner.Sign(publicID, req.Type, req.Payload)
+		if err != nil {
+			slog.Error("Skipping outbox row with an invalid payload", "table", r.config.Table, "id", row.id, "error", err)
+			return nil
+		}
+		req.PublicID, req.PayloadSignature = publicID, signature
+	}
+
+	task, err := r.store.CreateTask(ctx, req)
+	if err != nil {
+		return fmt.Errorf("outbox row %d: %w", row.id, err)
+	}
+
+	slog.Info("Relayed outbox row", "table", r.config.Table, "id", row.id, "task_id", task.ID, "task_type", task.Type)
+	return nil
+}
+
+// fetch reads the next batch of rows after the checkpoint
+func (r *Relay) fetch(ctx context.Context) ([]outboxRow, error) {
+	query := `SELECT id, request FROM ` + r.table + ` WHERE id > $1 ORDER BY id LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, r.lastID, r.config.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batch []outboxRow
+	for rows.Next() {
+		var row outboxRow
+		if err := rows.Scan(&row.id, &row.request); err != nil {
+			return nil, err
+		}
+		batch = append(batch, row)
+	}
+	return batch, rows.Err()
+}
+
+// loadCheckpoint reads the last relayed id; a table without a checkpoint starts from the beginning
+func (r *Relay) loadCheckpoint(ctx context.Context) error {
+	err := r.pool.QueryRow(ctx,
+		`SELECT last_id FROM `+r.checkpoints+` WHERE outbox_table = $1`,
+		r.config.Table,
+	).Scan(&r.lastID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	r.loaded = true
+	return nil
+}
+
+// saveCheckpoint stores lastID if it moved past start
+func (r *Relay) saveCheckpoint(ctx context.Context, start int64) error {
+	if r.lastID == start {
+		return nil
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO `+r.checkpoints+` (outbox_table, last_id, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (outbox_table) DO UPDATE
+		SET last_id = EXCLUDED.last_id, updated_at = EXCLUDED.updated_at
+	`, r.config.Table, r.lastID)
+	return err
+}
@@ -0,0 +1,33 @@
+package relay
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReady(t *testing.T) {
+	r := &Relay{config: Config{GapTimeout: 50 * time.Millisecond}, lastID: 4}
+
+	if !r.ready(5) {
+		t.Fatal("ready(5) = false, want true for the next id")
+	}
+	r.lastID = 5
+
+	// id 6 is missing: 7 waits for it until the gap timeout
+	if r.ready(7) {
+		t.Fatal("ready(7) = true, want false while waiting on id 6")
+	}
+	time.Sleep(60 * time.Millisecond)
+	if !r.ready(7) {
+		t.Fatal("ready(7) = false, want true after the gap timeout")
+	}
+	r.lastID = 7
+
+	// A new gap starts a new wait
+	if r.ready(9) {
+		t.Fatal("ready(9) = true, want false for a new gap")
+	}
+	if !r.ready(8) {
+		t.Fatal("ready(8) = false, want true once the missing id appears")
+	}
+}
@@ -0,0 +1,268 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+)
+
+// Client is the store of a remote worker, calling a Server over HTTPS
+// Errors restore the storage errors the server hit, e.g. storage.ErrLockLost; an unreachable server
+// is reported as storage.ErrUnavailable, so the worker pauses claims as it does during a database outage
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewClient creates a client for the server at baseURL, e.g. https://queue.internal:8443
+// tlsConfig presents the worker's certificate, e.g. tlsconfig.Reloader.ClientConfig; timeout bounds every call
+func NewClient(baseURL string, tlsConfig *tls.Config, timeout time.Duration) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	transport.ForceAttemptHTTP2 = true
+
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/") + PathPrefix,
+		http:    &http.Client{Transport: transport, Timeout: timeout},
+	}
+}
+
+// do sends in as the JSON body of a request and decodes the response into out, either may be nil
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%w: %w", storage.ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var e errorResponse
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		if target, ok := errorCodes[e.Code]; ok {
+			if e.Details == "" || e.Details == target.Error() {
+				return target
+			}
+			return fmt.Errorf("%w: %s", target, e.Details)
+		}
+		switch resp.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			// A proxy in front of the server, or the server restarting
+			return fmt.Errorf("%w: %s %s: %s", storage.ErrUnavailable, method, path, resp.Status)
+		}
+		return fmt.Errorf("%s %s: %s: %s %s", method, path, resp.Status, e.Error, e.Details)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// lockCall sends a call on a claimed task
+func (c *Client) lockCall(ctx context.Context, taskID int64, call string, req lockRequest) error {
+	return c.do(ctx, http.MethodPost, "/tasks/"+strconv.FormatInt(taskID, 10)+"/"+call, req, nil)
+}
+
+func lockOf(lock models.TaskLock) lockRequest {
+	return lockRequest{WorkerID: lock.WorkerID, LockToken: lock.Token}
+}
+
+// ClaimNextTasks claims up to n tasks for workerID, see storage.Store
+func (c *Client) ClaimNextTasks(ctx context.Context, workerID string, n int, filter models.ClaimFilter) ([]*models.Task, error) {
+	var resp claimResponse
+	err := c.do(ctx, http.MethodPost, "/claim", claimRequest{
+		WorkerID:    workerID,
+		N:           n,
+		MinPriority: filter.MinPriority,
+		Labels:      filter.Labels,
+		TypeSlots:   filter.TypeSlots,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*models.Task, 0, len(resp.Tasks))
+	for _, t := range resp.Tasks {
+		if t.Task == nil {
+			continue
+		}
+		t.Task.ID = t.InternalID
+		t.Task.PayloadSignature = t.PayloadSignature
+		tasks = append(tasks, t.Task)
+	}
+	return tasks, nil
+}
+
+// ExtendLock renews the lock held on a task, see storage.Store
+func (c *Client) ExtendLock(ctx context.Context, taskID int64, lock models.TaskLock, extendBy time.Duration) error {
+	req := lockOf(lock)
+	req.ExtendByMs = extendBy.Milliseconds()
+	return c.lockCall(ctx, taskID, "extend", req)
+}
+
+// ReapExpiredLocks recovers running tasks whose lock has expired, see storage.Store
+func (c *Client) ReapExpiredLocks(ctx context.Context) (int, error) {
+	var resp reapResponse
+	if err := c.do(ctx, http.MethodPost, "/reap", nil, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Reaped, nil
+}
+
+// CompleteTask marks a task as succeeded, see storage.Store
+func (c *Client) CompleteTask(ctx context.Context, taskID int64, lock models.TaskLock) error {
+	return c.lockCall(ctx, taskID, "complete", lockOf(lock))
+}
+
+// RequeueTask returns a running task to the queue, see storage.Store
+func (c *Client) RequeueTask(ctx context.Context, taskID int64, lock models.TaskLock) error {
+	return c.lockCall(ctx, taskID, "requeue", lockOf(lock))
+}
+
+// ScheduleRetry marks a task for retry, see storage.Store
+func (c *Client) ScheduleRetry(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string, retryAfter time.Duration) error {
+	req := lockOf(lock)
+	req.ErrorMessage = errorMessage
+	req.RetryAfterMs = retryAfter.Milliseconds()
+	return c.lockCall(ctx, taskID, "retry", req)
+}
+
+// RecordTimeout handles a task that exceeded its timeout, see storage.Store
+func (c *Client) RecordTimeout(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string) error {
+	req := lockOf(lock)
+	req.ErrorMessage = errorMessage
+	return c.lockCall(ctx, taskID, "timeout", req)
+}
+
+// RecordCrash handles a task whose handler panicked, see storage.Store
+func (c *Client) RecordCrash(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string) error {
+	req := lockOf(lock)
+	req.ErrorMessage = errorMessage
+	return c.lockCall(ctx, taskID, "crash", req)
+}
+
+// MarkTaskFailed permanently marks a task as failed, see storage.Store
+func (c *Client) MarkTaskFailed(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string) error {
+	req := lockOf(lock)
+	req.ErrorMessage = errorMessage
+	return c.lockCall(ctx, taskID, "fail", req)
+}
+
+// SaveTaskResult stores the result of a task, see storage.TaskResults
+// Servers whose store keeps no results discard it
+func (c *Client) SaveTaskResult(ctx context.Context, taskID int64, lock models.TaskLock, result json.RawMessage) error {
+	req := lockOf(lock)
+	req.Result = result
+	return c.lockCall(ctx, taskID, "result", req)
+}
+
+// InsertHistory adds a task history entry, see storage.Store
+func (c *Client) InsertHistory(ctx context.Context, history models.TaskHistory) error {
+	return c.do(ctx, http.MethodPost, "/history", wireHistory{TaskHistory: &history, TaskID: history.TaskID}, nil)
+}
+
+// RegisterWorker records a starting worker, see storage.Store
+func (c *Client) RegisterWorker(ctx context.Context, worker models.WorkerInfo) error {
+	return c.do(ctx, http.MethodPut, "/workers/"+url.PathEscape(worker.ID), worker, nil)
+}
+
+// HeartbeatWorker refreshes a worker's last_seen and load, see storage.Store
+func (c *Client) HeartbeatWorker(ctx context.Context, workerID string, heartbeat models.WorkerHeartbeat) error {
+	return c.do(ctx, http.MethodPost, "/workers/"+url.PathEscape(workerID)+"/heartbeat", heartbeatRequest{
+		Concurrency:    heartbeat.Concurrency,
+		InFlight:       heartbeat.InFlight,
+		WorkerCounters: heartbeat.WorkerCounters,
+	}, nil)
+}
+
+// DeregisterWorker marks a worker as stopped, see storage.Store
+func (c *Client) DeregisterWorker(ctx context.Context, workerID string) error {
+	return c.do(ctx, http.MethodDelete, "/workers/"+url.PathEscape(workerID), nil, nil)
+}
+
+// GetWorkerSettings returns the runtime overrides of a worker, see storage.Store
+func (c *Client) GetWorkerSettings(ctx context.Context, workerID string) (*models.WorkerSettings, error) {
+	var settings models.WorkerSettings
+	if err := c.do(ctx, http.MethodGet, "/workers/"+url.PathEscape(workerID)+"/settings", nil, &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// RecordTypeFailure counts a failure against a task type's circuit breaker, see storage.Store
+func (c *Client) RecordTypeFailure(ctx context.Context, taskType string, errorMessage string, threshold int, cooldown time.Duration) (bool, error) {
+	var resp typeFailureResponse
+	err := c.do(ctx, http.MethodPost, "/types/failure", typeFailureRequest{
+		Type:         taskType,
+		ErrorMessage: errorMessage,
+		Threshold:    threshold,
+		CooldownMs:   cooldown.Milliseconds(),
+	}, &resp)
+	return resp.Opened, err
+}
+
+// RecordTypeSuccess resets a task type's consecutive failure count, see storage.Store
+func (c *Client) RecordTypeSuccess(ctx context.Context, taskType string) error {
+	return c.do(ctx, http.MethodPost, "/types/success", typeSuccessRequest{Type: taskType}, nil)
+}
+
+// GetMaintenance returns the queue-wide maintenance mode, see storage.Store
+func (c *Client) GetMaintenance(ctx context.Context) (*models.MaintenanceMode, error) {
+	var mode models.MaintenanceMode
+	if err := c.do(ctx, http.MethodGet, "/maintenance", nil, &mode); err != nil {
+		return nil, err
+	}
+	return &mode, nil
+}
+
+// Ping checks that the server and its store are reachable
+func (c *Client) Ping(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/ping", nil, nil)
+}
+
+// CheckHealth reports the reachability of the server as the worker's only backend, see storage.HealthChecker
+func (c *Client) CheckHealth(ctx context.Context) []models.ComponentHealth {
+	h := models.ComponentHealth{Name: "remote", Status: models.HealthStatusOK}
+	started := time.Now()
+	err := c.Ping(ctx)
+	h.LatencySeconds = time.Since(started).Seconds()
+	if err != nil {
+		h.Status = models.HealthStatusDown
+		h.Error = err.Error()
+	}
+	return []models.ComponentHealth{h}
+}
@@ -0,0 +1,108 @@
+// Package remote carries the worker protocol over HTTPS with mutual TLS, so workers on other networks can
+// claim, heartbeat and complete tasks through the API server without database credentials
+//
+// The API server serves it with Server on a listener of its own; workers use Client as their store
+// A worker's identity is the common name of its client certificate: it may only claim, hold locks and
+// register as that name, or as the name followed by a hyphen and a suffix, e.g. one per replica
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+)
+
+// PathPrefix is the path every route of the protocol is served under
+const PathPrefix = "/remote/v1"
+
+// errorCodes maps the storage errors workers act on to the codes that carry them across the wire
+var errorCodes = map[string]error{
+	"lock_lost":                 storage.ErrLockLost,
+	"task_not_found":            storage.ErrTaskNotFound,
+	"task_result_not_found":     storage.ErrTaskResultNotFound,
+	"worker_settings_not_found": storage.ErrWorkerSettingsNotFound,
+	"unavailable":               storage.ErrUnavailable,
+}
+
+// errorResponse is the body of every failed request
+type errorResponse struct {
+	Error   string `json:"error"`
+	Code    string `json:"code,omitempty"` // one of errorCodes, empty for other errors
+	Details string `json:"details,omitempty"`
+}
+
+// wireTask is a claimed task with the fields API responses leave out, which workers need
+type wireTask struct {
+	*models.Task
+	InternalID       int64  `json:"internal_id"`
+	PayloadSignature string `json:"payload_signature,omitempty"`
+}
+
+// wireHistory is a history entry with its internal task id
+type wireHistory struct {
+	*models.TaskHistory
+	TaskID int64 `json:"task_id"`
+}
+
+type claimRequest struct {
+	WorkerID    string            `json:"worker_id"`
+	N           int               `json:"n"`
+	MinPriority *int              `json:"min_priority,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	TypeSlots   map[string]int    `json:"type_slots,omitempty"`
+}
+
+type claimResponse struct {
+	Tasks []wireTask `json:"tasks"`
+}
+
+// lockRequest is the body of every call on a claimed task, naming the lock it must still hold
+type lockRequest struct {
+	WorkerID     string          `json:"worker_id"`
+	LockToken    int64           `json:"lock_token"`
+	ErrorMessage string          `json:"error_message,omitempty"`
+	ExtendByMs   int64           `json:"extend_by_ms,omitempty"`   // extend
+	RetryAfterMs int64           `json:"retry_after_ms,omitempty"` // retry, 0 = computed backoff
+	Result       json.RawMessage `json:"result,omitempty"`         // result
+}
+
+func (r lockRequest) lock() models.TaskLock {
+	return models.TaskLock{WorkerID: r.WorkerID, Token: r.LockToken}
+}
+
+type heartbeatRequest struct {
+	Concurrency int `json:"concurrency"`
+	InFlight    int `json:"in_flight"`
+	models.WorkerCounters
+}
+
+type typeFailureRequest struct {
+	Type         string `json:"type"`
+	ErrorMessage string `json:"error_message"`
+	Threshold    int    `json:"threshold"`
+	CooldownMs   int64  `json:"cooldown_ms"`
+}
+
+type typeFailureResponse struct {
+	Opened bool `json:"opened"`
+}
+
+type typeSuccessRequest struct {
+	Type string `json:"type"`
+}
+
+type reapResponse struct {
+	Reaped int `json:"reaped"`
+}
+
+// codeOf returns the wire code of err, empty if workers do not act on it
+func codeOf(err error) string {
+	for code, target := range errorCodes {
+		if errors.Is(err, target) {
+			return code
+		}
+	}
+	return ""
+}
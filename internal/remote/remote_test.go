@@ -0,0 +1,283 @@
+package remote
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/tlsconfig"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/worker"
+	"github.com/gin-gonic/gin"
+)
+
+var _ worker.Store = (*Client)(nil)
+
+// workerStore holds one running task locked by worker-1 with token 7
+type workerStore struct {
+	storage.Store
+
+	task     models.Task
+	extendBy time.Duration
+	history  []models.TaskHistory
+	done     bool
+}
+
+func newWorkerStore() *workerStore {
+	lockedBy := "worker-1"
+	return &workerStore{task: models.Task{
+		ID:               42,
+		Name:             "remote",
+		Type:             "noop",
+		Status:           models.TaskStatusRunning,
+		LockedBy:         &lockedBy,
+		LockToken:        7,
+		PayloadSignature: "signature",
+	}}
+}
+
+func (s *workerStore) ClaimNextTasks(_ context.Context, _ string, n int, _ models.ClaimFilter) ([]*models.Task, error) {
+	if n < 1 {
+		return nil, nil
+	}
+	task := s.task
+	return []*models.Task{&task}, nil
+}
+
+func (s *workerStore) checkLock(taskID int64, lock models.TaskLock) error {
+	if taskID != s.task.ID {
+		return storage.ErrTaskNotFound
+	}
+	if lock != s.task.Lock() {
+		return storage.ErrLockLost
+	}
+	return nil
+}
+
+func (s *workerStore) ExtendLock(_ context.Context, taskID int64, lock models.TaskLock, extendBy time.Duration) error {
+	if err := s.checkLock(taskID, lock); err != nil {
+		return err
+	}
+	s.extendBy = extendBy
+	return nil
+}
+
+func (s *workerStore) CompleteTask(_ context.Context, taskID int64, lock models.TaskLock) error {
+	if err := s.checkLock(taskID, lock); err != nil {
+		return err
+	}
+	s.done = true
+	return nil
+}
+
+func (s *workerStore) InsertHistory(_ context.Context, history models.TaskHistory) error {
+	s.history = append(s.history, history)
+	return nil
+}
+
+// certificates writes a CA, a server certificate for queue.internal and client certificates for names into dir
+func certificates(t *testing.T, dir string, names ...string) (server tlsconfig.Files, clients map[string]tlsconfig.Files) {
+	t.Helper()
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+	caFile := filepath.Join(dir, "ca.crt")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	issue := func(name string, usage x509.ExtKeyUsage) tlsconfig.Files {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(time.Now().UnixNano()),
+			Subject:      pkix.Name{CommonName: name},
+			DNSNames:     []string{name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyDER, _ := x509.MarshalECPrivateKey(key)
+
+		files := tlsconfig.Files{
+			CertFile: filepath.Join(dir, name+".crt"),
+			KeyFile:  filepath.Join(dir, name+".key"),
+			CAFile:   caFile,
+		}
+		if err := os.WriteFile(files.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(files.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return files
+	}
+
+	clients = make(map[string]tlsconfig.Files)
+	for _, name := range names {
+		clients[name] = issue(name, x509.ExtKeyUsageClientAuth)
+	}
+	return issue("queue.internal", x509.ExtKeyUsageServerAuth), clients
+}
+
+// serve starts a Server on store over mutual TLS and returns its base URL
+func serve(t *testing.T, store storage.Store, files tlsconfig.Files) string {
+	t.Helper()
+	certs, err := tlsconfig.NewReloader(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	NewServer(store).RegisterRoutes(r)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: r, TLSConfig: certs.ServerConfig()}
+	go func() { _ = srv.ServeTLS(ln, "", "") }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return "https://" + ln.Addr().String()
+}
+
+func newClient(t *testing.T, baseURL string, files tlsconfig.Files) *Client {
+	t.Helper()
+	certs, err := tlsconfig.NewReloader(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewClient(baseURL, certs.ClientConfig("queue.internal"), 5*time.Second)
+}
+
+func TestClientServerRoundTrip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serverFiles, clients := certificates(t, t.TempDir(), "worker-1", "worker-2")
+	store := newWorkerStore()
+	baseURL := serve(t, store, serverFiles)
+	client := newClient(t, baseURL, clients["worker-1"])
+	ctx := context.Background()
+
+	tasks, err := client.ClaimNextTasks(ctx, "worker-1", 1, models.ClaimFilter{})
+	if err != nil {
+		t.Fatalf("ClaimNextTasks() error = %v", err)
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("ClaimNextTasks() = %d tasks, want 1", len(tasks))
+	}
+	task := tasks[0]
+	if task.ID != 42 || task.PayloadSignature != "signature" || task.Lock() != store.task.Lock() {
+		t.Errorf("claimed task = id %d, signature %q, lock %+v; want the store's", task.ID, task.PayloadSignature, task.Lock())
+	}
+
+	if err := client.ExtendLock(ctx, task.ID, task.Lock(), 30*time.Second); err != nil {
+		t.Fatalf("ExtendLock() error = %v", err)
+	}
+	if store.extendBy != 30*time.Second {
+		t.Errorf("extended by %v, want 30s", store.extendBy)
+	}
+
+	workerID := "worker-1"
+	if err := client.InsertHistory(ctx, models.TaskHistory{TaskID: task.ID, EventType: models.EventWorkerLockAcquired, WorkerID: &workerID}); err != nil {
+		t.Fatalf("InsertHistory() error = %v", err)
+	}
+	if len(store.history) != 1 || store.history[0].TaskID != 42 {
+		t.Errorf("history = %+v, want one entry of task 42", store.history)
+	}
+
+	// Storage errors survive the round trip
+	stale := task.Lock()
+	stale.Token++
+	if err := client.CompleteTask(ctx, task.ID, stale); !errors.Is(err, storage.ErrLockLost) {
+		t.Errorf("CompleteTask(stale lock) error = %v, want ErrLockLost", err)
+	}
+	if err := client.CompleteTask(ctx, task.ID, task.Lock()); err != nil {
+		t.Fatalf("CompleteTask() error = %v", err)
+	}
+	if !store.done {
+		t.Error("task not completed")
+	}
+
+	// A certificate only acts as its own name
+	if _, err := client.ClaimNextTasks(ctx, "worker-2", 1, models.ClaimFilter{}); err == nil {
+		t.Error("ClaimNextTasks() as another worker succeeded")
+	}
+	other := newClient(t, baseURL, clients["worker-2"])
+	if err := other.CompleteTask(ctx, task.ID, task.Lock()); err == nil || errors.Is(err, storage.ErrLockLost) {
+		t.Errorf("CompleteTask() under another worker's lock error = %v, want forbidden", err)
+	}
+	if _, err := client.ClaimNextTasks(ctx, "worker-1-replica-0", 1, models.ClaimFilter{}); err != nil {
+		t.Errorf("ClaimNextTasks() as a suffixed name error = %v", err)
+	}
+}
+
+func TestServerRequiresClientCertificate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serverFiles, _ := certificates(t, t.TempDir())
+	baseURL := serve(t, newWorkerStore(), serverFiles)
+
+	// Refused in the handshake
+	anonymous, err := tlsconfig.NewReloader(serverFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := anonymous.ClientConfig("queue.internal")
+	config.GetClientCertificate = nil
+	if _, err := NewClient(baseURL, config, 5*time.Second).ClaimNextTasks(context.Background(), "worker-1", 1, models.ClaimFilter{}); err == nil {
+		t.Error("ClaimNextTasks() without a client certificate succeeded")
+	}
+
+	// And by the routes, should they be served without client certificates
+	r := gin.New()
+	NewServer(newWorkerStore()).RegisterRoutes(r)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, PathPrefix+"/ping", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("plain request status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestClientReportsUnreachableServerAsUnavailable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	client := NewClient("https://"+addr, &tls.Config{MinVersion: tls.VersionTLS12}, time.Second)
+	if err := client.Ping(context.Background()); !errors.Is(err, storage.ErrUnavailable) {
+		t.Errorf("Ping() error = %v, want ErrUnavailable", err)
+	}
+}
@@ -0,0 +1,316 @@
+package remote
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// identityKey is the gin context key of the authenticated worker name
+const identityKey = "remote_identity"
+
+// Server exposes the worker side of a store to remote workers
+// Serve it only over TLS requiring client certificates, e.g. tlsconfig.Reloader.ServerConfig with a CA bundle
+type Server struct {
+	store storage.Store
+}
+
+// NewServer creates a server acting on store, which decides retries, backoff and quarantine as for local workers
+func NewServer(store storage.Store) *Server {
+	return &Server{store: store}
+}
+
+// RegisterRoutes registers the protocol under PathPrefix
+func (s *Server) RegisterRoutes(r gin.IRouter) {
+	g := r.Group(PathPrefix, authenticate())
+
+	g.GET("/ping", s.ping)
+	g.GET("/maintenance", s.getMaintenance)
+	g.POST("/claim", s.claim)
+	g.POST("/reap", s.reap)
+	g.POST("/history", s.insertHistory)
+
+	g.POST("/tasks/:id/extend", s.lockCall(func(ctx context.Context, id int64, req lockRequest) error {
+		return s.store.ExtendLock(ctx, id, req.lock(), time.Duration(req.ExtendByMs)*time.Millisecond)
+	}))
+	g.POST("/tasks/:id/complete", s.lockCall(func(ctx context.Context, id int64, req lockRequest) error {
+		return s.store.CompleteTask(ctx, id, req.lock())
+	}))
+	g.POST("/tasks/:id/requeue", s.lockCall(func(ctx context.Context, id int64, req lockRequest) error {
+		return s.store.RequeueTask(ctx, id, req.lock())
+	}))
+	g.POST("/tasks/:id/retry", s.lockCall(func(ctx context.Context, id int64, req lockRequest) error {
+		return s.store.ScheduleRetry(ctx, id, req.lock(), req.ErrorMessage, time.Duration(req.RetryAfterMs)*time.Millisecond)
+	}))
+	g.POST("/tasks/:id/timeout", s.lockCall(func(ctx context.Context, id int64, req lockRequest) error {
+		return s.store.RecordTimeout(ctx, id, req.lock(), req.ErrorMessage)
+	}))
+	g.POST("/tasks/:id/crash", s.lockCall(func(ctx context.Context, id int64, req lockRequest) error {
+		return s.store.RecordCrash(ctx, id, req.lock(), req.ErrorMessage)
+	}))
+	g.POST("/tasks/:id/fail", s.lockCall(func(ctx context.Context, id int64, req lockRequest) error {
+		return s.store.MarkTaskFailed(ctx, id, req.lock(), req.ErrorMessage)
+	}))
+	g.POST("/tasks/:id/result", s.lockCall(func(ctx context.Context, id int64, req lockRequest) error {
+		results, ok := s.store.(storage.TaskResults)
+		if !ok {
+			slog.Warn("Storage backend does not keep task results, discarding result", "task_id", id)
+			return nil
+		}
+		return results.SaveTaskResult(ctx, id, req.lock(), req.Result)
+	}))
+
+	g.PUT("/workers/:worker_id", s.workerCall(s.registerWorker))
+	g.POST("/workers/:worker_id/heartbeat", s.workerCall(s.heartbeatWorker))
+	g.DELETE("/workers/:worker_id", s.workerCall(s.deregisterWorker))
+	g.GET("/workers/:worker_id/settings", s.workerCall(s.getWorkerSettings))
+
+	g.POST("/types/failure", s.recordTypeFailure)
+	g.POST("/types/success", s.recordTypeSuccess)
+}
+
+// authenticate admits requests over connections with a client certificate, naming the worker after it
+// The TLS configuration has verified the certificate during the handshake
+func authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS == nil || len(c.Request.TLS.PeerCertificates) == 0 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse{Error: "Client certificate required"})
+			return
+		}
+
+		identity := c.Request.TLS.PeerCertificates[0].Subject.CommonName
+		if identity == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, errorResponse{Error: "Client certificate has no common name"})
+			return
+		}
+		c.Set(identityKey, identity)
+		c.Next()
+	}
+}
+
+// authorized reports whether the authenticated worker may act as workerID, answering 403 if not
+func authorized(c *gin.Context, workerID string) bool {
+	identity := c.GetString(identityKey)
+	if workerID == identity || strings.HasPrefix(workerID, identity+"-") {
+		return true
+	}
+
+	slog.Warn("Remote worker acting under another identity", "identity", identity, "worker_id", workerID)
+	c.JSON(http.StatusForbidden, errorResponse{
+		Error:   "Worker ID does not match the client certificate",
+		Details: "certificate " + identity + " cannot act as " + workerID,
+	})
+	return false
+}
+
+// fail answers a failed store call, with the code of the storage error for the client to restore
+func fail(c *gin.Context, msg string, err error) {
+	code := codeOf(err)
+	status := http.StatusInternalServerError
+	switch code {
+	case "lock_lost":
+		status = http.StatusConflict
+	case "task_not_found", "task_result_not_found", "worker_settings_not_found":
+		status = http.StatusNotFound
+	case "unavailable":
+		status = http.StatusServiceUnavailable
+	}
+
+	if status == http.StatusInternalServerError {
+		slog.Error(msg, "path", c.FullPath(), "error", err)
+	}
+	c.JSON(status, errorResponse{Error: msg, Code: code, Details: err.Error()})
+}
+
+// bind decodes the request body into req, answering 400 if it is invalid
+func bind(c *gin.Context, req any) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "Invalid request body", Details: err.Error()})
+		return false
+	}
+	return true
+}
+
+func (s *Server) ping(c *gin.Context) {
+	if err := s.store.Ping(c.Request.Context()); err != nil {
+		fail(c, "Store unreachable", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) getMaintenance(c *gin.Context) {
+	mode, err := s.store.GetMaintenance(c.Request.Context())
+	if err != nil {
+		fail(c, "Failed to get maintenance mode", err)
+		return
+	}
+	c.JSON(http.StatusOK, mode)
+}
+
+func (s *Server) claim(c *gin.Context) {
+	var req claimRequest
+	if !bind(c, &req) || !authorized(c, req.WorkerID) {
+		return
+	}
+
+	tasks, err := s.store.ClaimNextTasks(c.Request.Context(), req.WorkerID, req.N, models.ClaimFilter{
+		MinPriority: req.MinPriority,
+		Labels:      req.Labels,
+		TypeSlots:   req.TypeSlots,
+	})
+	if err != nil {
+		fail(c, "Failed to claim tasks", err)
+		return
+	}
+
+	resp := claimResponse{Tasks: make([]wireTask, len(tasks))}
+	for i, task := range tasks {
+		resp.Tasks[i] = wireTask{Task: task, InternalID: task.ID, PayloadSignature: task.PayloadSignature}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) reap(c *gin.Context) {
+	n, err := s.store.ReapExpiredLocks(c.Request.Context())
+	if err != nil {
+		fail(c, "Failed to reap expired locks", err)
+		return
+	}
+	c.JSON(http.StatusOK, reapResponse{Reaped: n})
+}
+
+func (s *Server) insertHistory(c *gin.Context) {
+	req := wireHistory{TaskHistory: &models.TaskHistory{}}
+	if !bind(c, &req) {
+		return
+	}
+	if req.WorkerID != nil && !authorized(c, *req.WorkerID) {
+		return
+	}
+
+	history := *req.TaskHistory
+	history.TaskID = req.TaskID
+	if err := s.store.InsertHistory(c.Request.Context(), history); err != nil {
+		fail(c, "Failed to insert history", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// lockCall serves a call on the task in the path, made under a lock of the authenticated worker
+func (s *Server) lockCall(call func(ctx context.Context, id int64, req lockRequest) error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse{Error: "Invalid task ID", Details: err.Error()})
+			return
+		}
+
+		var req lockRequest
+		if !bind(c, &req) || !authorized(c, req.WorkerID) {
+			return
+		}
+
+		if err := call(c.Request.Context(), id, req); err != nil {
+			fail(c, "Failed to update task", err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// workerCall serves a call on the worker in the path, which must be the authenticated worker
+func (s *Server) workerCall(call func(c *gin.Context, workerID string)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workerID := c.Param("worker_id")
+		if !authorized(c, workerID) {
+			return
+		}
+		call(c, workerID)
+	}
+}
+
+func (s *Server) registerWorker(c *gin.Context, workerID string) {
+	var info models.WorkerInfo
+	if !bind(c, &info) {
+		return
+	}
+
+	info.ID = workerID
+	if err := s.store.RegisterWorker(c.Request.Context(), info); err != nil {
+		fail(c, "Failed to register worker", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) heartbeatWorker(c *gin.Context, workerID string) {
+	var req heartbeatRequest
+	if !bind(c, &req) {
+		return
+	}
+
+	err := s.store.HeartbeatWorker(c.Request.Context(), workerID, models.WorkerHeartbeat{
+		Concurrency:    req.Concurrency,
+		InFlight:       req.InFlight,
+		WorkerCounters: req.WorkerCounters,
+	})
+	if err != nil {
+		fail(c, "Failed to record worker heartbeat", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) deregisterWorker(c *gin.Context, workerID string) {
+	if err := s.store.DeregisterWorker(c.Request.Context(), workerID); err != nil {
+		fail(c, "Failed to deregister worker", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) getWorkerSettings(c *gin.Context, workerID string) {
+	settings, err := s.store.GetWorkerSettings(c.Request.Context(), workerID)
+	if err != nil {
+		fail(c, "Failed to get worker settings", err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+func (s *Server) recordTypeFailure(c *gin.Context) {
+	var req typeFailureRequest
+	if !bind(c, &req) {
+		return
+	}
+
+	opened, err := s.store.RecordTypeFailure(c.Request.Context(), req.Type, req.ErrorMessage,
+		req.Threshold, time.Duration(req.CooldownMs)*time.Millisecond)
+	if err != nil {
+		fail(c, "Failed to record task type failure", err)
+		return
+	}
+	c.JSON(http.StatusOK, typeFailureResponse{Opened: opened})
+}
+
+func (s *Server) recordTypeSuccess(c *gin.Context) {
+	var req typeSuccessRequest
+	if !bind(c, &req) {
+		return
+	}
+
+	if err := s.store.RecordTypeSuccess(c.Request.Context(), req.Type); err != nil {
+		fail(c, "Failed to record task type success", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
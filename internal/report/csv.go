@@ -0,0 +1,19 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// WriteCSV writes the columns of t as a header row followed by its rows
+// The title and note are left out, so the file loads into spreadsheets and other tools as is
+func WriteCSV(w io.Writer, t Table) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(t.Columns); err != nil {
+		return err
+	}
+	if err := out.WriteAll(t.Rows); err != nil {
+		return err
+	}
+	return out.Error()
+}
@@ -0,0 +1,169 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// The PDF layout: landscape A4 in 8 point Courier, whose glyphs are 0.6 em wide, so columns line up
+// without font metrics and the standard fonts need not be embedded
+const (
+	pdfPageWidth    = 842
+	pdfPageHeight   = 595
+	pdfMargin       = 36
+	pdfFontSize     = 8
+	pdfLineHeight   = 10
+	pdfLineChars    = (pdfPageWidth - 2*pdfMargin) * 10 / (pdfFontSize * 6)
+	pdfPageLines    = (pdfPageHeight-2*pdfMargin)/pdfLineHeight - 2 // keeps two lines for the page number
+	pdfMaxCellChars = 40
+	pdfColumnGap    = 2
+)
+
+// pdfLine is one line of text on a page
+type pdfLine struct {
+	text string
+	bold bool
+}
+
+// WritePDF writes t as a PDF table, repeating the column header on every page
+// Cells wider than 40 characters are cut, as are lines wider than the page; the CSV format keeps every value whole
+// Characters outside Latin-1 are printed as ?
+func WritePDF(w io.Writer, t Table) error {
+	widths := make([]int, len(t.Columns))
+	for i, column := range t.Columns {
+		widths[i] = min(utf8.RuneCountInString(column), pdfMaxCellChars)
+	}
+	for _, row := range t.Rows {
+		for i := range widths {
+			if i < len(row) {
+				widths[i] = max(widths[i], min(utf8.RuneCountInString(row[i]), pdfMaxCellChars))
+			}
+		}
+	}
+
+	header := []pdfLine{{text: formatRow(t.Columns, widths), bold: true}}
+	header = append(header, pdfLine{text: strings.Repeat("-", min(utf8.RuneCountInString(header[0].text), pdfLineChars))})
+
+	var pages [][]pdfLine
+	page := []pdfLine{}
+	if t.Title != "" {
+		page = append(page, pdfLine{text: t.Title, bold: true}, pdfLine{})
+	}
+	page = append(page, header...)
+	for _, row := range t.Rows {
+		if len(page) == pdfPageLines {
+			pages = append(pages, page)
+			page = append([]pdfLine{}, header...)
+		}
+		page = append(page, pdfLine{text: formatRow(row, widths)})
+	}
+	if t.Note != "" {
+		if len(page)+2 > pdfPageLines {
+			pages = append(pages, page)
+			page = nil
+		}
+		page = append(page, pdfLine{}, pdfLine{text: t.Note})
+	}
+	pages = append(pages, page)
+
+	return writePDFDocument(w, pages)
+}
+
+// formatRow pads, or cuts, every cell to its column's width
+func formatRow(cells []string, widths []int) string {
+	var b strings.Builder
+	for i, width := range widths {
+		cell := ""
+		if i < len(cells) {
+			cell = cells[i]
+		}
+		cell = strings.Join(strings.Fields(cell), " ") // a cell takes exactly one line
+		if n := utf8.RuneCountInString(cell); n > width {
+			cell = string([]rune(cell)[:width-1]) + "~"
+		} else {
+			cell += strings.Repeat(" ", width-n)
+		}
+		if i > 0 {
+			b.WriteString(strings.Repeat(" ", pdfColumnGap))
+		}
+		b.WriteString(cell)
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// writePDFDocument writes pages as a PDF 1.4 document
+// Objects 1 to 4 are the catalog, the page tree and the two fonts; each page is followed by its content stream
+func writePDFDocument(w io.Writer, pages [][]pdfLine) error {
+	var doc bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, doc.Len())
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	doc.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, lines := range pages {
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT\n%d TL\n%d %d Td\n", pdfLineHeight, pdfMargin, pdfPageHeight-pdfMargin-pdfFontSize)
+		for _, line := range lines {
+			font := "F1"
+			if line.bold {
+				font = "F2"
+			}
+			fmt.Fprintf(&content, "/%s %d Tf\n(%s) Tj\nT*\n", font, pdfFontSize, pdfText(line.text))
+		}
+		fmt.Fprintf(&content, "ET\nBT\n/F1 %d Tf\n%d %d Td\n(Page %d of %d) Tj\nET\n",
+			pdfFontSize, pdfMargin, pdfMargin, i+1, len(pages))
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.Bytes()))
+	}
+
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+// pdfText encodes s as the content of a PDF string in WinAnsiEncoding, cut to the width of a line
+func pdfText(s string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range s {
+		if n == pdfLineChars {
+			break
+		}
+		n++
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r) // Latin-1 and WinAnsiEncoding agree on this range
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,90 @@
+// Package report loads the named report queries run_report tasks run and renders their rows as CSV or PDF
+package report
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// queryExt is the extension of report query files, named <name>.sql
+const queryExt = ".sql"
+
+// namePattern restricts report names to what is safe in a file name and an object key
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ErrUnknownReport is returned when a payload names a report that was not loaded
+var ErrUnknownReport = errors.New("unknown report")
+
+// Formats a report can be rendered in
+const (
+	FormatCSV = "csv"
+	FormatPDF = "pdf"
+)
+
+// Table is the rendered content of a report
+type Table struct {
+	Title   string
+	Columns []string
+	Rows    [][]string
+	Note    string // printed below the rows, e.g. that they were truncated
+}
+
+// Queries is a set of named report queries
+// Each is a single SQL statement whose parameters ($1, $2, ...) come from the task's payload
+type Queries struct {
+	byName map[string]string
+}
+
+// LoadQueries reads every <name>.sql file in dir
+func LoadQueries(dir string) (*Queries, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read reports: %w", err)
+	}
+
+	q := &Queries{byName: map[string]string{}}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), queryExt)
+		if entry.IsDir() || !ok {
+			continue
+		}
+		if !namePattern.MatchString(name) {
+			return nil, fmt.Errorf("report %s: name must be lowercase letters, digits, - and _", entry.Name())
+		}
+
+		source, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read report %s: %w", entry.Name(), err)
+		}
+		query := strings.TrimSpace(string(source))
+		if query == "" {
+			return nil, fmt.Errorf("report %s is empty", entry.Name())
+		}
+		q.byName[name] = query
+	}
+	return q, nil
+}
+
+// Names returns the names of the loaded reports, sorted
+func (q *Queries) Names() []string {
+	names := make([]string, 0, len(q.byName))
+	for name := range q.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Query returns the query of the named report
+func (q *Queries) Query(name string) (string, error) {
+	query, ok := q.byName[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownReport, name)
+	}
+	return query, nil
+}
@@ -0,0 +1,109 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLoadQueries(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "monthly_orders.sql", "SELECT day, count(*) FROM orders WHERE month = $1 GROUP BY day\n")
+	writeFile(t, dir, "signups.sql", "SELECT 1")
+	writeFile(t, dir, "README.md", "not a report")
+
+	queries, err := LoadQueries(dir)
+	if err != nil {
+		t.Fatalf("LoadQueries() error = %v", err)
+	}
+	if names := queries.Names(); !slices.Equal(names, []string{"monthly_orders", "signups"}) {
+		t.Errorf("Names() = %v", names)
+	}
+	if query, err := queries.Query("monthly_orders"); err != nil || !strings.HasSuffix(query, "GROUP BY day") {
+		t.Errorf("Query() = %q, %v", query, err)
+	}
+	if _, err := queries.Query("missing"); !errors.Is(err, ErrUnknownReport) {
+		t.Errorf("Query(missing) error = %v, want ErrUnknownReport", err)
+	}
+
+	writeFile(t, dir, "Bad Name.sql", "SELECT 1")
+	if _, err := LoadQueries(dir); err == nil {
+		t.Error("LoadQueries() accepted an invalid report name")
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, Table{
+		Title:   "ignored",
+		Columns: []string{"name", "note"},
+		Rows:    [][]string{{"a", "plain"}, {"b", "with, comma"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "name,note\na,plain\nb,\"with, comma\"\n"; buf.String() != want {
+		t.Errorf("WriteCSV() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	rows := make([][]string, 120)
+	for i := range rows {
+		rows[i] = []string{strconv.Itoa(i), "café (paris)", strings.Repeat("x", 60)}
+	}
+
+	var buf bytes.Buffer
+	err := WritePDF(&buf, Table{Title: "Orders", Columns: []string{"id", "city", "long"}, Rows: rows, Note: "Truncated"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := buf.String()
+
+	// 50 lines a page: the title, a blank line, the header and its rule leave 46 rows on the first page
+	// and 48 on the next; the third page takes the remaining 26 rows and the note
+	if !strings.Contains(doc, "/Count 3 >>") {
+		t.Errorf("page count: want 3 pages in\n%s", doc[:200])
+	}
+	for _, want := range []string{
+		`(Orders) Tj`,
+		`(119  caf\351 \(paris\)  ` + strings.Repeat("x", 39) + `~) Tj`,
+		`(Truncated) Tj`,
+		`(Page 3 of 3) Tj`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("PDF is missing %s", want)
+		}
+	}
+
+	// Every cross-reference entry must point at the start of its object
+	xref := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindStringSubmatch(doc)
+	if xref == nil {
+		t.Fatal("PDF has no startxref")
+	}
+	start, _ := strconv.Atoi(xref[1])
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(doc[start:], -1)
+	if len(entries) != 4+2*3 {
+		t.Fatalf("xref has %d objects, want 10", len(entries))
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(entry[1])
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !strings.HasPrefix(doc[offset:], want) {
+			t.Errorf("xref entry %d points at %q", i+1, doc[offset:offset+10])
+		}
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
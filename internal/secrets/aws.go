@@ -0,0 +1,65 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+// AWS reads secrets from AWS Secrets Manager
+// prod/smtp/password reads the password key of the JSON secret prod/smtp
+type AWS struct {
+	client *secretsmanager.Client
+}
+
+// NewAWS creates an AWS Secrets Manager provider with the default credential chain
+// (environment, shared config, IRSA or the instance role); an empty region uses the configured one
+func NewAWS(ctx context.Context, region string) (*AWS, error) {
+	var opts []func(*config.LoadOptions) error
+	if region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("load aws config: %w", err)
+	}
+	return &AWS{client: secretsmanager.NewFromConfig(cfg)}, nil
+}
+
+// Lookup reads a key of the current version of a JSON secret
+func (a *AWS) Lookup(ctx context.Context, name string) (string, error) {
+	id, key, err := splitName(name)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := a.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(id)})
+	if err != nil {
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	if out.SecretString == nil {
+		return "", fmt.Errorf("secret %s is binary", id)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(*out.SecretString), &fields); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object", id)
+	}
+
+	value, ok := fields[key].(string)
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
@@ -0,0 +1,39 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// Env returns a provider reading secrets from environment variables
+// smtp/password is read from <prefix>SMTP_PASSWORD: letters are upper-cased and other characters become _
+func Env(prefix string) Provider {
+	return envProvider{prefix: prefix}
+}
+
+type envProvider struct {
+	prefix string
+}
+
+// Lookup reads the variable named after the secret
+func (p envProvider) Lookup(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(p.prefix + envName(name))
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// envName maps a secret name to an environment variable name
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,139 @@
+// Package secrets resolves {"$secret": "name"} references in task payloads just before a handler runs,
+// so credentials are never stored with the task
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RefKey is the only key of a secret reference object, e.g. {"password": {"$secret": "smtp/password"}}
+const RefKey = "$secret"
+
+// ErrNotFound is returned by providers for secrets that do not exist
+var ErrNotFound = errors.New("secret not found")
+
+// Provider looks up secrets by name
+// Names are "<secret>/<key>": providers that store key/value secrets read key from secret,
+// flat providers such as the environment use the whole name
+// Implementations must be safe for concurrent use
+type Provider interface {
+	Lookup(ctx context.Context, name string) (string, error)
+}
+
+// splitName splits a secret name into the secret and the key within it
+func splitName(name string) (secret, key string, err error) {
+	i := strings.LastIndex(name, "/")
+	if i <= 0 || i == len(name)-1 {
+		return "", "", fmt.Errorf("secret name %q must be <secret>/<key>", name)
+	}
+	return name[:i], name[i+1:], nil
+}
+
+// cachedSecret is a looked up value and when it stops being reused
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+// Resolver replaces secret references in payloads with values from a provider
+// Values are cached for ttl so a busy task type does not query the provider for every task
+type Resolver struct {
+	provider Provider
+	ttl      time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedSecret
+}
+
+// NewResolver creates a resolver; a zero ttl looks every secret up each time
+func NewResolver(provider Provider, ttl time.Duration) *Resolver {
+	return &Resolver{provider: provider, ttl: ttl, cache: map[string]cachedSecret{}}
+}
+
+// Resolve returns payload with every secret reference replaced by its value as a JSON string
+// Payloads without references are returned unchanged without being decoded
+func (r *Resolver) Resolve(ctx context.Context, payload json.RawMessage) (json.RawMessage, error) {
+	if !bytes.Contains(payload, []byte(`"`+RefKey+`"`)) {
+		return payload, nil
+	}
+
+	var doc any
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber() // keep large integers exact
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+
+	resolved, err := r.resolve(ctx, doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(resolved)
+}
+
+// resolve walks a decoded JSON value, replacing reference objects
+func (r *Resolver) resolve(ctx context.Context, v any) (any, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		if name, ok := ref(v); ok {
+			return r.lookup(ctx, name)
+		}
+		for key, child := range v {
+			resolved, err := r.resolve(ctx, child)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = resolved
+		}
+	case []any:
+		for i, child := range v {
+			resolved, err := r.resolve(ctx, child)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+	}
+	return v, nil
+}
+
+// ref returns the secret name of a reference object, an object whose only key is RefKey with a string value
+func ref(obj map[string]any) (string, bool) {
+	if len(obj) != 1 {
+		return "", false
+	}
+	name, ok := obj[RefKey].(string)
+	return name, ok
+}
+
+// lookup returns a secret from the cache or the provider
+func (r *Resolver) lookup(ctx context.Context, name string) (string, error) {
+	now := time.Now()
+
+	r.mu.Lock()
+	cached, ok := r.cache[name]
+	r.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.value, nil
+	}
+
+	value, err := r.provider.Lookup(ctx, name)
+	if err != nil {
+		// The name is safe to show, the value never is
+		return "", fmt.Errorf("resolve secret %q: %w", name, err)
+	}
+
+	if r.ttl > 0 {
+		r.mu.Lock()
+		r.cache[name] = cachedSecret{value: value, expires: now.Add(r.ttl)}
+		r.mu.Unlock()
+	}
+	return value, nil
+}
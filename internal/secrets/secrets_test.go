@@ -0,0 +1,69 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// countingProvider serves secrets from a map, counting lookups
+type countingProvider struct {
+	values  map[string]string
+	lookups int
+}
+
+func (p *countingProvider) Lookup(_ context.Context, name string) (string, error) {
+	p.lookups++
+	if value, ok := p.values[name]; ok {
+		return value, nil
+	}
+	return "", ErrNotFound
+}
+
+func TestResolve(t *testing.T) {
+	ctx := context.Background()
+	provider := &countingProvider{values: map[string]string{"smtp/password": `s3cr"t`}}
+	r := NewResolver(provider, time.Minute)
+
+	payload := json.RawMessage(`{"to":"a@example.com","auth":{"password":{"$secret":"smtp/password"}},"ids":[12345678901234567890],"list":[{"$secret":"smtp/password"}],"literal":{"$secret":"x","other":1}}`)
+	got, err := r.Resolve(ctx, payload)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	want := `{"auth":{"password":"s3cr\"t"},"ids":[12345678901234567890],"list":["s3cr\"t"],"literal":{"$secret":"x","other":1},"to":"a@example.com"}`
+	if string(got) != want {
+		t.Errorf("Resolve() = %s, want %s", got, want)
+	}
+	if provider.lookups != 1 {
+		t.Errorf("provider looked up %d times, want 1 (cached)", provider.lookups)
+	}
+
+	plain := json.RawMessage(`{"to":"a@example.com"}`)
+	if got, err := r.Resolve(ctx, plain); err != nil || string(got) != string(plain) {
+		t.Errorf("Resolve(no refs) = %s, %v, want unchanged", got, err)
+	}
+
+	if _, err := r.Resolve(ctx, json.RawMessage(`{"p":{"$secret":"smtp/missing"}}`)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Resolve(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got := envName("smtp/api-key.v2"); got != "SMTP_API_KEY_V2" {
+		t.Errorf("envName() = %q, want SMTP_API_KEY_V2", got)
+	}
+}
+
+func TestSplitName(t *testing.T) {
+	if secret, key, err := splitName("prod/smtp/password"); err != nil || secret != "prod/smtp" || key != "password" {
+		t.Errorf("splitName() = %q, %q, %v", secret, key, err)
+	}
+	for _, name := range []string{"password", "/password", "smtp/"} {
+		if _, _, err := splitName(name); err == nil {
+			t.Errorf("splitName(%q) should fail", name)
+		}
+	}
+}
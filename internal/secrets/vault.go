@@ -0,0 +1,90 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// VaultOptions configures the HashiCorp Vault provider
+type VaultOptions struct {
+	Address string // e.g. https://vault.internal:8200
+	Token   string
+	Mount   string // KV version 2 mount (default: secret)
+}
+
+// Vault reads secrets from a HashiCorp Vault KV version 2 engine
+// smtp/password reads the password field of the secret at <mount>/smtp
+type Vault struct {
+	address string
+	token   string
+	mount   string
+	client  *http.Client
+}
+
+// NewVault creates a Vault provider
+func NewVault(opts VaultOptions) (*Vault, error) {
+	if opts.Address == "" || opts.Token == "" {
+		return nil, errors.New("vault address and token are required")
+	}
+	if _, err := url.Parse(opts.Address); err != nil {
+		return nil, fmt.Errorf("invalid vault address: %w", err)
+	}
+	if opts.Mount == "" {
+		opts.Mount = "secret"
+	}
+
+	return &Vault{
+		address: strings.TrimRight(opts.Address, "/"),
+		token:   opts.Token,
+		mount:   strings.Trim(opts.Mount, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Lookup reads a field of the latest version of a secret
+func (v *Vault) Lookup(ctx context.Context, name string) (string, error) {
+	path, field, err := splitName(name)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.address+"/v1/"+v.mount+"/data/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("vault answered %s", resp.Status)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("decode vault response: %w", err)
+	}
+
+	value, ok := body.Data.Data[field].(string)
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
@@ -0,0 +1,587 @@
+// HandlerService is the contract between the worker and out-of-process handlers ("sidecars")
+// The worker claims tasks, renews their locks and applies retries; a sidecar only runs them
+// Generate the Go code with `make proto`; sidecars in other languages generate theirs from this file
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: taskqueue/handler/v1/handler.proto
+
+package handlerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListHandlersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListHandlersRequest) Reset() {
+	*x = ListHandlersRequest{}
+	mi := &file_taskqueue_handler_v1_handler_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListHandlersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListHandlersRequest) ProtoMessage() {}
+
+func (x *ListHandlersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taskqueue_handler_v1_handler_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListHandlersRequest.ProtoReflect.Descriptor instead.
+func (*ListHandlersRequest) Descriptor() ([]byte, []int) {
+	return file_taskqueue_handler_v1_handler_proto_rawDescGZIP(), []int{0}
+}
+
+type ListHandlersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskTypes     []string               `protobuf:"bytes,1,rep,name=task_types,json=taskTypes,proto3" json:"task_types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListHandlersResponse) Reset() {
+	*x = ListHandlersResponse{}
+	mi := &file_taskqueue_handler_v1_handler_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListHandlersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListHandlersResponse) ProtoMessage() {}
+
+func (x *ListHandlersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_taskqueue_handler_v1_handler_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListHandlersResponse.ProtoReflect.Descriptor instead.
+func (*ListHandlersResponse) Descriptor() ([]byte, []int) {
+	return file_taskqueue_handler_v1_handler_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListHandlersResponse) GetTaskTypes() []string {
+	if x != nil {
+		return x.TaskTypes
+	}
+	return nil
+}
+
+type ExecuteRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	TaskType string                 `protobuf:"bytes,1,opt,name=task_type,json=taskType,proto3" json:"task_type,omitempty"`
+	// The task's JSON payload
+	Payload []byte `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	// The task's metadata, e.g. traceparent and request_id
+	Metadata      map[string]string `protobuf:"bytes,3,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteRequest) Reset() {
+	*x = ExecuteRequest{}
+	mi := &file_taskqueue_handler_v1_handler_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteRequest) ProtoMessage() {}
+
+func (x *ExecuteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taskqueue_handler_v1_handler_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteRequest.ProtoReflect.Descriptor instead.
+func (*ExecuteRequest) Descriptor() ([]byte, []int) {
+	return file_taskqueue_handler_v1_handler_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ExecuteRequest) GetTaskType() string {
+	if x != nil {
+		return x.TaskType
+	}
+	return ""
+}
+
+func (x *ExecuteRequest) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *ExecuteRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type ExecuteEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*ExecuteEvent_Heartbeat
+	//	*ExecuteEvent_Progress
+	//	*ExecuteEvent_Completed
+	//	*ExecuteEvent_Failed
+	Event         isExecuteEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteEvent) Reset() {
+	*x = ExecuteEvent{}
+	mi := &file_taskqueue_handler_v1_handler_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteEvent) ProtoMessage() {}
+
+func (x *ExecuteEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_taskqueue_handler_v1_handler_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteEvent.ProtoReflect.Descriptor instead.
+func (*ExecuteEvent) Descriptor() ([]byte, []int) {
+	return file_taskqueue_handler_v1_handler_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ExecuteEvent) GetEvent() isExecuteEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *ExecuteEvent) GetHeartbeat() *Heartbeat {
+	if x != nil {
+		if x, ok := x.Event.(*ExecuteEvent_Heartbeat); ok {
+			return x.Heartbeat
+		}
+	}
+	return nil
+}
+
+func (x *ExecuteEvent) GetProgress() *Progress {
+	if x != nil {
+		if x, ok := x.Event.(*ExecuteEvent_Progress); ok {
+			return x.Progress
+		}
+	}
+	return nil
+}
+
+func (x *ExecuteEvent) GetCompleted() *Completed {
+	if x != nil {
+		if x, ok := x.Event.(*ExecuteEvent_Completed); ok {
+			return x.Completed
+		}
+	}
+	return nil
+}
+
+func (x *ExecuteEvent) GetFailed() *Failed {
+	if x != nil {
+		if x, ok := x.Event.(*ExecuteEvent_Failed); ok {
+			return x.Failed
+		}
+	}
+	return nil
+}
+
+type isExecuteEvent_Event interface {
+	isExecuteEvent_Event()
+}
+
+type ExecuteEvent_Heartbeat struct {
+	Heartbeat *Heartbeat `protobuf:"bytes,1,opt,name=heartbeat,proto3,oneof"`
+}
+
+type ExecuteEvent_Progress struct {
+	Progress *Progress `protobuf:"bytes,2,opt,name=progress,proto3,oneof"`
+}
+
+type ExecuteEvent_Completed struct {
+	Completed *Completed `protobuf:"bytes,3,opt,name=completed,proto3,oneof"`
+}
+
+type ExecuteEvent_Failed struct {
+	Failed *Failed `protobuf:"bytes,4,opt,name=failed,proto3,oneof"`
+}
+
+func (*ExecuteEvent_Heartbeat) isExecuteEvent_Event() {}
+
+func (*ExecuteEvent_Progress) isExecuteEvent_Event() {}
+
+func (*ExecuteEvent_Completed) isExecuteEvent_Event() {}
+
+func (*ExecuteEvent_Failed) isExecuteEvent_Event() {}
+
+// Heartbeat tells the worker the sidecar is still working; send one at least every heartbeat timeout
+type Heartbeat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Heartbeat) Reset() {
+	*x = Heartbeat{}
+	mi := &file_taskqueue_handler_v1_handler_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Heartbeat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Heartbeat) ProtoMessage() {}
+
+func (x *Heartbeat) ProtoReflect() protoreflect.Message {
+	mi := &file_taskqueue_handler_v1_handler_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Heartbeat.ProtoReflect.Descriptor instead.
+func (*Heartbeat) Descriptor() ([]byte, []int) {
+	return file_taskqueue_handler_v1_handler_proto_rawDescGZIP(), []int{4}
+}
+
+// Progress reports how far the task got; it also counts as a heartbeat
+type Progress struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 0 to 100, or negative when unknown
+	Percent       float64 `protobuf:"fixed64,1,opt,name=percent,proto3" json:"percent,omitempty"`
+	Message       string  `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Progress) Reset() {
+	*x = Progress{}
+	mi := &file_taskqueue_handler_v1_handler_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Progress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Progress) ProtoMessage() {}
+
+func (x *Progress) ProtoReflect() protoreflect.Message {
+	mi := &file_taskqueue_handler_v1_handler_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Progress.ProtoReflect.Descriptor instead.
+func (*Progress) Descriptor() ([]byte, []int) {
+	return file_taskqueue_handler_v1_handler_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Progress) GetPercent() float64 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+func (x *Progress) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type Completed struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Optional JSON stored as the task's result
+	Result        []byte `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Completed) Reset() {
+	*x = Completed{}
+	mi := &file_taskqueue_handler_v1_handler_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Completed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Completed) ProtoMessage() {}
+
+func (x *Completed) ProtoReflect() protoreflect.Message {
+	mi := &file_taskqueue_handler_v1_handler_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Completed.ProtoReflect.Descriptor instead.
+func (*Completed) Descriptor() ([]byte, []int) {
+	return file_taskqueue_handler_v1_handler_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Completed) GetResult() []byte {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type Failed struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Message string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// Fail the task at once instead of retrying, for errors no retry can fix
+	Permanent bool `protobuf:"varint,2,opt,name=permanent,proto3" json:"permanent,omitempty"`
+	// Suggested delay before the next attempt, 0 = the queue's backoff
+	RetryAfterMs  int64 `protobuf:"varint,3,opt,name=retry_after_ms,json=retryAfterMs,proto3" json:"retry_after_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Failed) Reset() {
+	*x = Failed{}
+	mi := &file_taskqueue_handler_v1_handler_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Failed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Failed) ProtoMessage() {}
+
+func (x *Failed) ProtoReflect() protoreflect.Message {
+	mi := &file_taskqueue_handler_v1_handler_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Failed.ProtoReflect.Descriptor instead.
+func (*Failed) Descriptor() ([]byte, []int) {
+	return file_taskqueue_handler_v1_handler_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Failed) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Failed) GetPermanent() bool {
+	if x != nil {
+		return x.Permanent
+	}
+	return false
+}
+
+func (x *Failed) GetRetryAfterMs() int64 {
+	if x != nil {
+		return x.RetryAfterMs
+	}
+	return 0
+}
+
+var File_taskqueue_handler_v1_handler_proto protoreflect.FileDescriptor
+
+const file_taskqueue_handler_v1_handler_proto_rawDesc = "" +
+	"\n" +
+	"\"taskqueue/handler/v1/handler.proto\x12\x14taskqueue.handler.v1\"\x15\n" +
+	"\x13ListHandlersRequest\"5\n" +
+	"\x14ListHandlersResponse\x12\x1d\n" +
+	"\n" +
+	"task_types\x18\x01 \x03(\tR\ttaskTypes\"\xd4\x01\n" +
+	"\x0eExecuteRequest\x12\x1b\n" +
+	"\ttask_type\x18\x01 \x01(\tR\btaskType\x12\x18\n" +
+	"\apayload\x18\x02 \x01(\fR\apayload\x12N\n" +
+	"\bmetadata\x18\x03 \x03(\v22.taskqueue.handler.v1.ExecuteRequest.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8f\x02\n" +
+	"\fExecuteEvent\x12?\n" +
+	"\theartbeat\x18\x01 \x01(\v2\x1f.taskqueue.handler.v1.HeartbeatH\x00R\theartbeat\x12<\n" +
+	"\bprogress\x18\x02 \x01(\v2\x1e.taskqueue.handler.v1.ProgressH\x00R\bprogress\x12?\n" +
+	"\tcompleted\x18\x03 \x01(\v2\x1f.taskqueue.handler.v1.CompletedH\x00R\tcompleted\x126\n" +
+	"\x06failed\x18\x04 \x01(\v2\x1c.taskqueue.handler.v1.FailedH\x00R\x06failedB\a\n" +
+	"\x05event\"\v\n" +
+	"\tHeartbeat\">\n" +
+	"\bProgress\x12\x18\n" +
+	"\apercent\x18\x01 \x01(\x01R\apercent\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"#\n" +
+	"\tCompleted\x12\x16\n" +
+	"\x06result\x18\x01 \x01(\fR\x06result\"f\n" +
+	"\x06Failed\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12\x1c\n" +
+	"\tpermanent\x18\x02 \x01(\bR\tpermanent\x12$\n" +
+	"\x0eretry_after_ms\x18\x03 \x01(\x03R\fretryAfterMs2\xce\x01\n" +
+	"\x0eHandlerService\x12e\n" +
+	"\fListHandlers\x12).taskqueue.handler.v1.ListHandlersRequest\x1a*.taskqueue.handler.v1.ListHandlersResponse\x12U\n" +
+	"\aExecute\x12$.taskqueue.handler.v1.ExecuteRequest\x1a\".taskqueue.handler.v1.ExecuteEvent0\x01BFZDgithub.com/amitbasuri/taskqueue-runner-go/internal/sidecar/handlerpbb\x06proto3"
+
+var (
+	file_taskqueue_handler_v1_handler_proto_rawDescOnce sync.Once
+	file_taskqueue_handler_v1_handler_proto_rawDescData []byte
+)
+
+func file_taskqueue_handler_v1_handler_proto_rawDescGZIP() []byte {
+	file_taskqueue_handler_v1_handler_proto_rawDescOnce.Do(func() {
+		file_taskqueue_handler_v1_handler_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_taskqueue_handler_v1_handler_proto_rawDesc), len(file_taskqueue_handler_v1_handler_proto_rawDesc)))
+	})
+	return file_taskqueue_handler_v1_handler_proto_rawDescData
+}
+
+var file_taskqueue_handler_v1_handler_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_taskqueue_handler_v1_handler_proto_goTypes = []any{
+	(*ListHandlersRequest)(nil),  // 0: taskqueue.handler.v1.ListHandlersRequest
+	(*ListHandlersResponse)(nil), // 1: taskqueue.handler.v1.ListHandlersResponse
+	(*ExecuteRequest)(nil),       // 2: taskqueue.handler.v1.ExecuteRequest
+	(*ExecuteEvent)(nil),         // 3: taskqueue.handler.v1.ExecuteEvent
+	(*Heartbeat)(nil),            // 4: taskqueue.handler.v1.Heartbeat
+	(*Progress)(nil),             // 5: taskqueue.handler.v1.Progress
+	(*Completed)(nil),            // 6: taskqueue.handler.v1.Completed
+	(*Failed)(nil),               // 7: taskqueue.handler.v1.Failed
+	nil,                          // 8: taskqueue.handler.v1.ExecuteRequest.MetadataEntry
+}
+var file_taskqueue_handler_v1_handler_proto_depIdxs = []int32{
+	8, // 0: taskqueue.handler.v1.ExecuteRequest.metadata:type_name -> taskqueue.handler.v1.ExecuteRequest.MetadataEntry
+	4, // 1: taskqueue.handler.v1.ExecuteEvent.heartbeat:type_name -> taskqueue.handler.v1.Heartbeat
+	5, // 2: taskqueue.handler.v1.ExecuteEvent.progress:type_name -> taskqueue.handler.v1.Progress
+	6, // 3: taskqueue.handler.v1.ExecuteEvent.completed:type_name -> taskqueue.handler.v1.Completed
+	7, // 4: taskqueue.handler.v1.ExecuteEvent.failed:type_name -> taskqueue.handler.v1.Failed
+	0, // 5: taskqueue.handler.v1.HandlerService.ListHandlers:input_type -> taskqueue.handler.v1.ListHandlersRequest
+	2, // 6: taskqueue.handler.v1.HandlerService.Execute:input_type -> taskqueue.handler.v1.ExecuteRequest
+	1, // 7: taskqueue.handler.v1.HandlerService.ListHandlers:output_type -> taskqueue.handler.v1.ListHandlersResponse
+	3, // 8: taskqueue.handler.v1.HandlerService.Execute:output_type -> taskqueue.handler.v1.ExecuteEvent
+	7, // [7:9] is the sub-list for method output_type
+	5, // [5:7] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_taskqueue_handler_v1_handler_proto_init() }
+func file_taskqueue_handler_v1_handler_proto_init() {
+	if File_taskqueue_handler_v1_handler_proto != nil {
+		return
+	}
+	file_taskqueue_handler_v1_handler_proto_msgTypes[3].OneofWrappers = []any{
+		(*ExecuteEvent_Heartbeat)(nil),
+		(*ExecuteEvent_Progress)(nil),
+		(*ExecuteEvent_Completed)(nil),
+		(*ExecuteEvent_Failed)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_taskqueue_handler_v1_handler_proto_rawDesc), len(file_taskqueue_handler_v1_handler_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_taskqueue_handler_v1_handler_proto_goTypes,
+		DependencyIndexes: file_taskqueue_handler_v1_handler_proto_depIdxs,
+		MessageInfos:      file_taskqueue_handler_v1_handler_proto_msgTypes,
+	}.Build()
+	File_taskqueue_handler_v1_handler_proto = out.File
+	file_taskqueue_handler_v1_handler_proto_goTypes = nil
+	file_taskqueue_handler_v1_handler_proto_depIdxs = nil
+}
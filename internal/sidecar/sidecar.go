@@ -0,0 +1,169 @@
+// Package sidecar runs tasks in out-of-process handlers, written in any language, that serve the
+// HandlerService gRPC contract in proto/taskqueue/handler/v1/handler.proto
+// The worker keeps claiming tasks, renewing their locks and applying retries; it only proxies the execution
+package sidecar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/sidecar/handlerpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// listTimeout bounds asking a sidecar for its task types at startup
+const listTimeout = 10 * time.Second
+
+// errHeartbeatTimeout fails an attempt whose sidecar went silent
+var errHeartbeatTimeout = errors.New("sidecar sent no heartbeat")
+
+// Sidecar is a connection to one sidecar process
+type Sidecar struct {
+	target           string
+	conn             *grpc.ClientConn
+	client           handlerpb.HandlerServiceClient
+	heartbeatTimeout time.Duration
+}
+
+// Dial connects to the sidecar at target, e.g. localhost:50051 or unix:///run/sidecar.sock
+// Sidecars run next to the worker, in the same pod or host, so the connection is not encrypted
+// An attempt fails when the sidecar sends no event for heartbeatTimeout, 0 = the task timeout only
+func Dial(target string, heartbeatTimeout time.Duration) (*Sidecar, error) {
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("connect to sidecar %s: %w", target, err)
+	}
+	return &Sidecar{
+		target:           target,
+		conn:             conn,
+		client:           handlerpb.NewHandlerServiceClient(conn),
+		heartbeatTimeout: heartbeatTimeout,
+	}, nil
+}
+
+// Close closes the connection
+func (s *Sidecar) Close() error {
+	return s.conn.Close()
+}
+
+// Handlers asks the sidecar which task types it handles and returns a handler for each
+// Register them like any other handler; a type a built-in handler also handles is replaced by the sidecar's
+func (s *Sidecar) Handlers(ctx context.Context) ([]models.TaskHandler, error) {
+	ctx, cancel := context.WithTimeout(ctx, listTimeout)
+	defer cancel()
+
+	resp, err := s.client.ListHandlers(ctx, &handlerpb.ListHandlersRequest{}, grpc.WaitForReady(true))
+	if err != nil {
+		return nil, fmt.Errorf("list handlers of sidecar %s: %w", s.target, err)
+	}
+
+	handlers := make([]models.TaskHandler, 0, len(resp.GetTaskTypes()))
+	for _, taskType := range resp.GetTaskTypes() {
+		handlers = append(handlers, &handler{sidecar: s, taskType: models.TaskType(taskType)})
+	}
+	return handlers, nil
+}
+
+// handler proxies the tasks of one type to the sidecar
+type handler struct {
+	sidecar  *Sidecar
+	taskType models.TaskType
+}
+
+func (h *handler) Type() models.TaskType {
+	return h.taskType
+}
+
+func (h *handler) Execute(ctx context.Context, payload json.RawMessage) error {
+	// Stop the call when the sidecar goes silent, and with it the sidecar's work
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	stream, err := h.sidecar.client.Execute(ctx, &handlerpb.ExecuteRequest{
+		TaskType: string(h.taskType),
+		Payload:  payload,
+		Metadata: models.MetadataFromContext(ctx),
+	})
+	if err != nil {
+		return callError(ctx, h.sidecar.target, err)
+	}
+
+	var watchdog *time.Timer
+	if timeout := h.sidecar.heartbeatTimeout; timeout > 0 {
+		watchdog = time.AfterFunc(timeout, func() {
+			cancel(fmt.Errorf("%w for %s", errHeartbeatTimeout, timeout))
+		})
+		defer watchdog.Stop()
+	}
+
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("sidecar %s ended the call without a result", h.sidecar.target)
+		}
+		if err != nil {
+			return callError(ctx, h.sidecar.target, err)
+		}
+		if watchdog != nil {
+			watchdog.Reset(h.sidecar.heartbeatTimeout)
+		}
+
+		switch e := event.GetEvent().(type) {
+		case *handlerpb.ExecuteEvent_Heartbeat:
+		case *handlerpb.ExecuteEvent_Progress:
+			slog.Info("Sidecar progress", "type", h.taskType, "percent", e.Progress.GetPercent(), "message", e.Progress.GetMessage())
+		case *handlerpb.ExecuteEvent_Completed:
+			result := e.Completed.GetResult()
+			if len(result) == 0 {
+				return nil
+			}
+			if !json.Valid(result) {
+				return fmt.Errorf("sidecar %s returned a result that is not JSON", h.sidecar.target)
+			}
+			return models.SetResult(ctx, json.RawMessage(result))
+		case *handlerpb.ExecuteEvent_Failed:
+			return failure(e.Failed)
+		}
+	}
+}
+
+// failure turns a failed event into the error the worker retries, or not
+func failure(failed *handlerpb.Failed) error {
+	err := errors.New(failed.GetMessage())
+	switch {
+	case failed.GetPermanent():
+		return models.Permanent(err)
+	case failed.GetRetryAfterMs() > 0:
+		return models.RetryAfter(err, time.Duration(failed.GetRetryAfterMs())*time.Millisecond)
+	default:
+		return err
+	}
+}
+
+// callError classifies a failed call
+// A sidecar that rejects the request as invalid or does not handle its type fails the task at once;
+// an unreachable or crashed sidecar is retried
+func callError(ctx context.Context, target string, err error) error {
+	if cause := context.Cause(ctx); errors.Is(cause, errHeartbeatTimeout) {
+		return fmt.Errorf("sidecar %s: %w", target, cause)
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("sidecar %s: %w", target, ctxErr)
+	}
+
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.Unimplemented:
+		return models.Permanent(fmt.Errorf("sidecar %s: %w", target, err))
+	default:
+		return fmt.Errorf("sidecar %s: %w", target, err)
+	}
+}
@@ -0,0 +1,98 @@
+package sidecar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/sidecar/handlerpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeSidecar answers Execute by the task type of the request
+type fakeSidecar struct {
+	handlerpb.UnimplementedHandlerServiceServer
+}
+
+func (fakeSidecar) ListHandlers(context.Context, *handlerpb.ListHandlersRequest) (*handlerpb.ListHandlersResponse, error) {
+	return &handlerpb.ListHandlersResponse{TaskTypes: []string{"resize_image", "fail", "hang", "reject"}}, nil
+}
+
+func (fakeSidecar) Execute(req *handlerpb.ExecuteRequest, stream grpc.ServerStreamingServer[handlerpb.ExecuteEvent]) error {
+	switch req.GetTaskType() {
+	case "resize_image":
+		_ = stream.Send(&handlerpb.ExecuteEvent{Event: &handlerpb.ExecuteEvent_Progress{Progress: &handlerpb.Progress{Percent: 50}}})
+		result, _ := json.Marshal(map[string]string{"trace": req.GetMetadata()["traceparent"], "payload": string(req.GetPayload())})
+		return stream.Send(&handlerpb.ExecuteEvent{Event: &handlerpb.ExecuteEvent_Completed{Completed: &handlerpb.Completed{Result: result}}})
+	case "fail":
+		return stream.Send(&handlerpb.ExecuteEvent{Event: &handlerpb.ExecuteEvent_Failed{Failed: &handlerpb.Failed{Message: "bad image", Permanent: true}}})
+	case "hang":
+		<-stream.Context().Done()
+		return stream.Context().Err()
+	default:
+		return status.Error(codes.InvalidArgument, "payload has no image")
+	}
+}
+
+func TestSidecar(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	handlerpb.RegisterHandlerServiceServer(server, fakeSidecar{})
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	sidecar, err := Dial(listener.Addr().String(), 200*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = sidecar.Close() }()
+
+	list, err := sidecar.Handlers(context.Background())
+	if err != nil {
+		t.Fatalf("Handlers() error = %v", err)
+	}
+	handlers := map[models.TaskType]models.TaskHandler{}
+	for _, h := range list {
+		handlers[h.Type()] = h
+	}
+	if len(handlers) != 4 {
+		t.Fatalf("Handlers() = %v", list)
+	}
+
+	ctx := models.ContextWithMetadata(context.Background(), map[string]string{models.MetadataTraceParent: "00-abc-def-01"})
+	ctx, result := models.ContextWithResult(ctx)
+	if err := handlers["resize_image"].Execute(ctx, json.RawMessage(`{"width":100}`)); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got, want := string(result()), `{"payload":"{\"width\":100}","trace":"00-abc-def-01"}`; got != want {
+		t.Errorf("result = %s, want %s", got, want)
+	}
+
+	err = handlers["fail"].Execute(context.Background(), json.RawMessage(`{}`))
+	if !models.IsPermanent(err) || err.Error() != "bad image" {
+		t.Errorf("Execute(fail) error = %v, want permanent bad image", err)
+	}
+
+	err = handlers["reject"].Execute(context.Background(), json.RawMessage(`{}`))
+	if !models.IsPermanent(err) {
+		t.Errorf("Execute(reject) error = %v, want permanent", err)
+	}
+
+	started := time.Now()
+	err = handlers["hang"].Execute(context.Background(), json.RawMessage(`{}`))
+	if !errors.Is(err, errHeartbeatTimeout) || models.IsPermanent(err) {
+		t.Errorf("Execute(hang) error = %v, want a retryable heartbeat timeout", err)
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Errorf("Execute(hang) took %s", elapsed)
+	}
+}
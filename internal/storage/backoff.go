@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// CalculateBackoff computes the delay before a retry attempt using the task's retry strategy
+// with the given jitter applied, capped at the task's max_backoff_seconds (or maxBackoff)
+// Shared by the store implementations so retries are scheduled identically
+func CalculateBackoff(task *models.Task, retryCount int, jitterMode models.JitterMode, maxBackoff time.Duration) time.Duration {
+	delay := baseDelaySeconds(task.RetryStrategy, task.BackoffSeconds, task.RetrySchedule, retryCount)
+
+	// Cap to prevent runaway delays
+	maxDelay := maxDelaySeconds(task, maxBackoff)
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	previous := float64(task.BackoffSeconds)
+	if task.LastBackoffSeconds != nil {
+		previous = float64(*task.LastBackoffSeconds)
+	}
+	backoff := applyJitter(jitterMode, delay, float64(task.BackoffSeconds), previous)
+
+	// Jitter must not push the delay past the cap
+	if backoff > maxDelay {
+		backoff = maxDelay
+	}
+
+	// Ensure minimum backoff of 1 second
+	if backoff < 1 {
+		backoff = 1
+	}
+
+	return time.Duration(backoff) * time.Second
+}
+
+// RetryDelay returns the delay before a retry attempt
+// A positive retryAfter, suggested by the handler, replaces the computed backoff but is held to the same cap,
+// so a remote Retry-After cannot park a task for longer than max_backoff_seconds (or maxBackoff)
+func RetryDelay(task *models.Task, retryCount int, retryAfter time.Duration, jitterMode models.JitterMode, maxBackoff time.Duration) time.Duration {
+	if retryAfter <= 0 {
+		return CalculateBackoff(task, retryCount, jitterMode, maxBackoff)
+	}
+	return min(retryAfter, time.Duration(maxDelaySeconds(task, maxBackoff))*time.Second)
+}
+
+// maxDelaySeconds returns the cap on retry delays: the task's max_backoff_seconds, or maxBackoff
+func maxDelaySeconds(task *models.Task, maxBackoff time.Duration) float64 {
+	if task.MaxBackoffSeconds != nil && *task.MaxBackoffSeconds > 0 {
+		return float64(*task.MaxBackoffSeconds)
+	}
+	return maxBackoff.Seconds()
+}
+
+// applyJitter randomizes a delay according to the jitter mode
+// previousSeconds is the task's last retry delay, or baseSeconds before its first retry
+// Using math/rand is sufficient for backoff jitter (crypto/rand is overkill)
+func applyJitter(mode models.JitterMode, delay float64, baseSeconds float64, previousSeconds float64) float64 {
+	switch mode {
+	case models.JitterNone:
+		return delay
+
+	case models.JitterFull:
+		return rand.Float64() * delay
+
+	case models.JitterEqual:
+		return delay/2 + rand.Float64()*(delay/2)
+
+	case models.JitterDecorrelated:
+		// Grows from the previous sleep rather than the attempt's delay, so the strategy only matters through the cap
+		upper := previousSeconds * 3
+		if upper < baseSeconds {
+			return baseSeconds
+		}
+		return baseSeconds + rand.Float64()*(upper-baseSeconds)
+
+	default:
+		// Proportional jitter (±25%)
+		jitterPercent := (rand.Float64() * 0.5) - 0.25 // Range: -0.25 to +0.25
+		return delay + delay*jitterPercent
+	}
+}
+
+// baseDelaySeconds returns the un-jittered delay for a retry attempt (1-based)
+func baseDelaySeconds(strategy models.RetryStrategy, baseSeconds int, schedule []int, retryCount int) float64 {
+	switch strategy {
+	case models.RetryStrategyFixed:
+		return float64(baseSeconds)
+
+	case models.RetryStrategyLinear:
+		return float64(baseSeconds) * float64(retryCount)
+
+	case models.RetryStrategyCustom:
+		if len(schedule) == 0 {
+			return float64(baseSeconds)
+		}
+		// Attempts past the end of the schedule reuse the last delay
+		index := retryCount - 1
+		if index >= len(schedule) {
+			index = len(schedule) - 1
+		}
+		return float64(schedule[index])
+
+	default:
+		// Exponential backoff: base * 2^(retry_count-1)
+		// Cap the exponent to prevent overflow (2^20 = ~1M seconds = 11 days)
+		exponent := retryCount - 1
+		if exponent > 20 {
+			exponent = 20
+		}
+		return float64(baseSeconds) * math.Pow(2, float64(exponent))
+	}
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+func TestBaseDelaySeconds(t *testing.T) {
+	schedule := []int{30, 300, 3600}
+
+	tests := []struct {
+		name       string
+		strategy   models.RetryStrategy
+		retryCount int
+		want       float64
+	}{
+		{"fixed", models.RetryStrategyFixed, 3, 5},
+		{"linear", models.RetryStrategyLinear, 3, 15},
+		{"exponential", models.RetryStrategyExponential, 3, 20},
+		{"custom first", models.RetryStrategyCustom, 1, 30},
+		{"custom last", models.RetryStrategyCustom, 3, 3600},
+		{"custom past end", models.RetryStrategyCustom, 5, 3600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := baseDelaySeconds(tt.strategy, 5, schedule, tt.retryCount)
+			if got != tt.want {
+				t.Fatalf("baseDelaySeconds() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyJitter(t *testing.T) {
+	tests := []struct {
+		mode     models.JitterMode
+		min, max float64
+	}{
+		{models.JitterNone, 40, 40},
+		{models.JitterProportional, 30, 50},
+		{models.JitterFull, 0, 40},
+		{models.JitterEqual, 20, 40},
+		{models.JitterDecorrelated, 5, 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				got := applyJitter(tt.mode, 40, 5, 10)
+				if got < tt.min || got > tt.max {
+					t.Fatalf("applyJitter(%s) = %v, want within [%v, %v]", tt.mode, got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateBackoffDecorrelated(t *testing.T) {
+	tests := []struct {
+		name     string
+		last     *int
+		min, max time.Duration
+	}{
+		{"first retry", nil, 5 * time.Second, 15 * time.Second},
+		{"grows from the previous sleep", intPtr(100), 5 * time.Second, 300 * time.Second},
+		{"capped", intPtr(3000), 5 * time.Second, time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &models.Task{BackoffSeconds: 5, RetryStrategy: models.RetryStrategyFixed, LastBackoffSeconds: tt.last}
+			for i := 0; i < 100; i++ {
+				got := CalculateBackoff(task, 4, models.JitterDecorrelated, time.Hour)
+				if got < tt.min || got > tt.max {
+					t.Fatalf("CalculateBackoff() = %v, want within [%v, %v]", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestRetryDelay(t *testing.T) {
+	taskCap := 120
+	tests := []struct {
+		name       string
+		maxBackoff *int
+		retryAfter time.Duration
+		want       time.Duration
+	}{
+		{"retry after below the cap", nil, 30 * time.Second, 30 * time.Second},
+		{"retry after above the default cap", nil, 48 * time.Hour, time.Hour},
+		{"retry after above the task cap", &taskCap, 10 * time.Minute, 2 * time.Minute},
+		{"computed backoff", nil, 0, 20 * time.Second},
+		{"negative retry after", nil, -time.Second, 20 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &models.Task{BackoffSeconds: 5, MaxBackoffSeconds: tt.maxBackoff}
+			got := RetryDelay(task, 3, tt.retryAfter, models.JitterNone, time.Hour)
+			if got != tt.want {
+				t.Fatalf("RetryDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package storage
+
+import "encoding/json"
+
+// PayloadKey returns the value of a top-level payload field as used for rate limit keys
+// Like PostgreSQL's ->>, strings are used unquoted and other values as their JSON text
+// Returns false when the payload is not an object or the field is missing or null
+func PayloadKey(payload json.RawMessage, field string) (string, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return "", false
+	}
+
+	raw, ok := fields[field]
+	if !ok || string(raw) == "null" {
+		return "", false
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, true
+	}
+	return string(raw), true
+}
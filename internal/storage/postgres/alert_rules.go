@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+// ListAlertRules returns all alert rules with their last evaluation
+func (s *Store) ListAlertRules(ctx context.Context) ([]models.AlertRule, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		SELECT name, metric, task_type, operator, threshold, window_seconds, webhook_url, channel, signing_secret,
+		       firing, last_value, last_evaluated_at, last_transition_at, updated_at
+		FROM alert_rules
+		ORDER BY name ASC
+	`
+
+	rows, err := s.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []models.AlertRule{}
+	for rows.Next() {
+		var r models.AlertRule
+		if err := rows.Scan(&r.Name, &r.Metric, &r.TaskType, &r.Operator, &r.Threshold, &r.WindowSeconds, &r.WebhookURL, &r.Channel, &r.SigningSecret,
+			&r.Firing, &r.LastValue, &r.LastEvaluatedAt, &r.LastTransitionAt, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// SetAlertRule creates or replaces an alert rule
+// A replaced rule keeps its firing state, so changing a threshold does not notify again by itself
+func (s *Store) SetAlertRule(ctx context.Context, name string, req models.SetAlertRuleRequest) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO alert_rules (name, metric, task_type, operator, threshold, window_seconds, webhook_url, channel, signing_secret, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		ON CONFLICT (name) DO UPDATE
+		SET metric = EXCLUDED.metric,
+		    task_type = EXCLUDED.task_type,
+		    operator = EXCLUDED.operator,
+		    threshold = EXCLUDED.threshold,
+		    window_seconds = EXCLUDED.window_seconds,
+		    webhook_url = EXCLUDED.webhook_url,
+		    channel = EXCLUDED.channel,
+		    signing_secret = EXCLUDED.signing_secret,
+		    updated_at = NOW()
+	`
+
+	_, err := s.pool.Exec(ctx, query, name, req.Metric, strings.ToLower(req.TaskType), req.Operator, *req.Threshold,
+		req.WindowSeconds, req.WebhookURL, req.Channel, req.SigningSecret)
+	return err
+}
+
+// DeleteAlertRule removes an alert rule
+func (s *Store) DeleteAlertRule(ctx context.Context, name string) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	result, err := s.pool.Exec(ctx, `DELETE FROM alert_rules WHERE name = $1`, name)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return storage.ErrAlertRuleNotFound
+	}
+
+	return nil
+}
+
+// RecordAlertEvaluation stores the outcome of evaluating a rule
+// Returns true if this call changed the rule's firing state; the row lock makes exactly one
+// of several API servers evaluating the same rule see the change
+func (s *Store) RecordAlertEvaluation(ctx context.Context, name string, firing bool, value float64) (bool, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		WITH previous AS (
+			SELECT firing FROM alert_rules WHERE name = $1 FOR UPDATE
+		)
+		UPDATE alert_rules a
+		SET firing = $2,
+		    last_value = $3,
+		    last_evaluated_at = NOW(),
+		    last_transition_at = CASE WHEN previous.firing <> $2 THEN NOW() ELSE a.last_transition_at END
+		FROM previous
+		WHERE a.name = $1
+		RETURNING previous.firing <> $2
+	`
+
+	var changed bool
+	err := s.pool.QueryRow(ctx, query, name, firing, value).Scan(&changed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Deleted while being evaluated
+		return false, nil
+	}
+	return changed, err
+}
@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// AnalyzeTables runs ANALYZE on tasks and task_history and returns their tuple counts
+// Autovacuum never analyzes a partitioned parent such as task_history, so its statistics go stale without this
+// Counts come from pg_stat_user_tables and are summed over the partitions of task_history
+func (s *Store) AnalyzeTables(ctx context.Context) ([]models.TableStats, error) {
+	ctx, cancel := s.longQuery(ctx)
+	defer cancel()
+
+	tables := []struct {
+		name string
+		pool *prefixedPool
+	}{
+		{"tasks", s.pool},
+		{"task_history", s.history},
+	}
+
+	stats := make([]models.TableStats, 0, len(tables))
+	for _, table := range tables {
+		// The name goes through the prefix rewrite like every other statement
+		if _, err := table.pool.Exec(ctx, `ANALYZE `+table.name); err != nil {
+			return nil, fmt.Errorf("analyzing %s: %w", table.name, err)
+		}
+
+		query := `
+			SELECT COALESCE(SUM(n_live_tup), 0)::bigint, COALESCE(SUM(n_dead_tup), 0)::bigint,
+			       MAX(last_autovacuum), MAX(last_autoanalyze)
+			FROM pg_stat_user_tables
+			WHERE relid = '` + table.name + `'::regclass
+			   OR relid IN (SELECT inhrelid FROM pg_inherits WHERE inhparent = '` + table.name + `'::regclass)
+		`
+
+		tableStats := models.TableStats{Table: table.name}
+		err := table.pool.QueryRow(ctx, query).Scan(
+			&tableStats.LiveTuples,
+			&tableStats.DeadTuples,
+			&tableStats.LastAutovacuum,
+			&tableStats.LastAutoanalyze,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s statistics: %w", table.name, err)
+		}
+		stats = append(stats, tableStats)
+	}
+
+	return stats, nil
+}
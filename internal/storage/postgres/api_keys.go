@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+// apiKeyColumns is the column list scanned by scanAPIKey
+const apiKeyColumns = `id, name, key_prefix, role, scopes, created_at, expires_at, revoked_at`
+
+// CreateAPIKey stores a new API key given its display prefix and hash
+func (s *Store) CreateAPIKey(ctx context.Context, req models.CreateAPIKeyRequest, prefix, hash string) (*models.APIKey, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	scopes := make([]string, len(req.Scopes))
+	for i, scope := range req.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	query := `
+		INSERT INTO api_keys (name, key_prefix, key_hash, role, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + apiKeyColumns
+
+	return scanAPIKey(s.pool.QueryRow(ctx, query, req.Name, prefix, hash, req.Role, scopes, req.ExpiresAt))
+}
+
+// GetAPIKeyByHash returns the key with the given hash, including revoked and expired keys
+func (s *Store) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	key, err := scanAPIKey(s.pool.QueryRow(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, hash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrAPIKeyNotFound
+	}
+	return key, err
+}
+
+// ListAPIKeys returns all API keys, including revoked ones, ordered by ID
+func (s *Store) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey marks a key revoked; revoking it again keeps the first revocation time
+func (s *Store) RevokeAPIKey(ctx context.Context, id int64) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	result, err := s.pool.Exec(ctx, `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW()) WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return storage.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// scanAPIKey scans a row of apiKeyColumns
+func scanAPIKey(row pgx.Row) (*models.APIKey, error) {
+	var key models.APIKey
+	var scopes []string
+	if err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Role, &scopes, &key.CreatedAt, &key.ExpiresAt, &key.RevokedAt); err != nil {
+		return nil, err
+	}
+
+	key.Scopes = make([]models.APIKeyScope, len(scopes))
+	for i, scope := range scopes {
+		key.Scopes[i] = models.APIKeyScope(scope)
+	}
+	return &key, nil
+}
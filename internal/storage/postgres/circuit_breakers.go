@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+)
+
+// RecordTypeFailure counts a failure against the task type's circuit breaker
+// Opens the breaker once threshold consecutive failures are reached and it is not already open
+// After the cooldown the count is kept, so the first failure of a probing task reopens it
+func (s *Store) RecordTypeFailure(ctx context.Context, taskType string, errorMessage string, threshold int, cooldown time.Duration) (bool, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	taskType = strings.ToLower(taskType)
+
+	var failures int
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO circuit_breakers (task_type, consecutive_failures, last_error, updated_at)
+		VALUES ($1, 1, $2, NOW())
+		ON CONFLICT (task_type) DO UPDATE
+		SET consecutive_failures = circuit_breakers.consecutive_failures + 1,
+		    last_error = EXCLUDED.last_error,
+		    updated_at = NOW()
+		RETURNING consecutive_failures
+	`, taskType, errorMessage).Scan(&failures)
+	if err != nil {
+		return false, err
+	}
+
+	if failures < threshold {
+		return false, nil
+	}
+
+	// Only one failure trips the breaker per cooldown, even with many workers failing at once
+	result, err := s.pool.Exec(ctx, `
+		UPDATE circuit_breakers
+		SET opened_until = NOW() + $2::double precision * INTERVAL '1 second',
+		    trip_count = trip_count + 1,
+		    updated_at = NOW()
+		WHERE task_type = $1
+		  AND (opened_until IS NULL OR opened_until <= NOW())
+	`, taskType, cooldown.Seconds())
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// RecordTypeSuccess resets the consecutive failure count of the task type
+// Closes a breaker whose cooldown has passed; writes nothing for types that have not failed
+func (s *Store) RecordTypeSuccess(ctx context.Context, taskType string) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	_, err := s.pool.Exec(ctx, `
+		UPDATE circuit_breakers
+		SET consecutive_failures = 0, updated_at = NOW()
+		WHERE task_type = $1 AND consecutive_failures > 0
+	`, strings.ToLower(taskType))
+	return err
+}
+
+// ListCircuitBreakers returns the circuit breaker state of every task type that has failed
+func (s *Store) ListCircuitBreakers(ctx context.Context) ([]models.CircuitBreaker, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		SELECT task_type,
+		       CASE WHEN opened_until > NOW() THEN 'open' ELSE 'closed' END,
+		       consecutive_failures, opened_until, trip_count, last_error, updated_at
+		FROM circuit_breakers
+		ORDER BY task_type ASC
+	`
+
+	rows, err := s.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	breakers := []models.CircuitBreaker{}
+	for rows.Next() {
+		var b models.CircuitBreaker
+		if err := rows.Scan(&b.TaskType, &b.State, &b.ConsecutiveFailures, &b.OpenedUntil,
+			&b.TripCount, &b.LastError, &b.UpdatedAt); err != nil {
+			return nil, err
+		}
+		breakers = append(breakers, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return breakers, nil
+}
+
+// OpenCircuitBreaker pauses claiming of a task type for cooldown
+func (s *Store) OpenCircuitBreaker(ctx context.Context, taskType string, cooldown time.Duration) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO circuit_breakers (task_type, opened_until, trip_count, updated_at)
+		VALUES ($1, NOW() + $2::double precision * INTERVAL '1 second', 1, NOW())
+		ON CONFLICT (task_type) DO UPDATE
+		SET opened_until = EXCLUDED.opened_until,
+		    trip_count = circuit_breakers.trip_count + 1,
+		    updated_at = NOW()
+	`, strings.ToLower(taskType), cooldown.Seconds())
+	return err
+}
+
+// CloseCircuitBreaker resumes claiming of a task type and resets its failure count
+func (s *Store) CloseCircuitBreaker(ctx context.Context, taskType string) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	result, err := s.pool.Exec(ctx, `
+		UPDATE circuit_breakers
+		SET opened_until = NULL, consecutive_failures = 0, updated_at = NOW()
+		WHERE task_type = $1
+	`, strings.ToLower(taskType))
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return storage.ErrCircuitBreakerNotFound
+	}
+
+	return nil
+}
@@ -2,78 +2,266 @@ package postgres
 
 import (
 	"context"
-	"errors"
+	"log/slog"
+	"sort"
 	"time"
 
 	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
 	"github.com/jackc/pgx/v5"
 )
 
+// claimOversample is how many candidates a claim locks per task it may claim
+// The spare candidates let the batch go round-robin across tenants instead of to whoever queued first
+const claimOversample = 4
+
 // ClaimNextTask atomically claims the next available task for processing
-// Handles timeout recovery and respects next_run_at scheduling
-// Prioritizes tasks with expired locks to prevent starvation
+// Returns nil if no tasks are available
 func (s *Store) ClaimNextTask(ctx context.Context, workerID string) (*models.Task, error) {
+	tasks, err := s.ClaimNextTasks(ctx, workerID, 1, models.ClaimFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tasks) == 0 {
+		return nil, nil // No tasks available
+	}
+
+	return tasks[0], nil
+}
+
+// ClaimNextTasks atomically claims up to n available tasks in a single round-trip
+// Respects next_run_at scheduling; tasks with expired locks are recovered by ReapExpiredLocks
+// Candidates are read in idx_tasks_claim order (priority DESC, created_at ASC over queued rows),
+// claimOversample times n of them, and the batch takes them round-robin by tenant: every tenant's first
+// candidate before any tenant's second, so a tenant with a large backlog cannot take the whole batch
+// Tasks without a tenant take their turns as one tenant
+// Records the claiming worker and bumps the fencing token so stale owners cannot write results
+// Respects cluster-wide per-type caps from concurrency_limits and per-key windows from rate_limits
+// Respects per-tenant max_running caps from tenant_quotas, so a tenant at its share leaves the slots to others
+// Skips task types whose circuit breaker is open, and paused task types or the whole queue while paused
+// Claims nothing while maintenance mode stops claims
+// Only tasks matching filter are considered, including the claiming worker's labels
+// The worker_lock_acquired history of every claimed task is written by the claim statement itself,
+// or in one pipelined batch when history lives in a separate database
+// Fails with storage.ErrUnavailable while the database is unreachable or failing over
+func (s *Store) ClaimNextTasks(ctx context.Context, workerID string, n int, filter models.ClaimFilter) ([]*models.Task, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	tasks, err := s.claimNextTasks(ctx, workerID, n, filter)
+	return tasks, s.unavailable(err)
+}
+
+// claimNextTasks runs the claim transaction for ClaimNextTasks
+func (s *Store) claimNextTasks(ctx context.Context, workerID string, n int, filter models.ClaimFilter) ([]*models.Task, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
 	now := time.Now()
 
+	// A worker satisfies a task when the task's required labels are a subset of its own
+	workerLabels := filter.Labels
+	if workerLabels == nil {
+		workerLabels = map[string]string{}
+	}
+
+	// Types without an entry are only bounded by n
+	typeSlots := filter.TypeSlots
+	if typeSlots == nil {
+		typeSlots = map[string]int{}
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Serialize claims while limits exist so concurrent workers cannot overshoot a cap
+	// The claim below runs as a separate statement so it sees tasks claimed by the previous lock holder
+	if err := lockClaimLimits(ctx, tx); err != nil {
+		return nil, err
+	}
+
 	query := `
-		UPDATE tasks
-		SET 
-			status = $1,
-			locked_at = $2,
-			lock_expires_at = $2 + (timeout_seconds || ' seconds')::interval,
-			updated_at = $2
-		WHERE id = (
-			SELECT id
+		WITH available AS (
+			-- Remaining slots for every capped task type
+			SELECT l.task_type, l.max_running - COUNT(t.id) AS slots
+			FROM concurrency_limits l
+			LEFT JOIN tasks t ON t.type = l.task_type AND t.status = $1
+			GROUP BY l.task_type, l.max_running
+		),
+		tenant_slots AS (
+			-- Remaining slots for every tenant with running tasks and a max_running quota, its own or the default
+			SELECT r.tenant, COALESCE(q.max_running, d.max_running) - r.running AS slots
+			FROM (SELECT tenant, COUNT(*) AS running FROM tasks WHERE status = $1 AND tenant <> '' GROUP BY tenant) r
+			LEFT JOIN tenant_quotas q ON q.tenant = r.tenant
+			LEFT JOIN tenant_quotas d ON d.tenant = $12
+			WHERE COALESCE(q.max_running, d.max_running) IS NOT NULL
+		),
+		rate_windows AS (
+			-- Remaining starts for every rate limit key used within its window
+			SELECT t.rate_limit_key, r.max_per_window - COUNT(*) AS remaining
+			FROM tasks t
+			JOIN rate_limits r ON r.task_type = t.type
+			WHERE t.rate_limit_key IS NOT NULL
+			  AND t.last_started_at > $2::timestamp - (r.window_seconds || ' seconds')::interval
+			GROUP BY t.rate_limit_key, r.max_per_window
+		),
+		candidates AS (
+			SELECT id, type, tenant, rate_limit_key, priority, created_at
 			FROM tasks
 			WHERE status = $3
 			  AND next_run_at <= $2
 			  AND (lock_expires_at IS NULL OR lock_expires_at <= $2)
+			  AND type NOT IN (SELECT task_type FROM available WHERE slots <= 0)
+			  AND tenant NOT IN (SELECT tenant FROM tenant_slots WHERE slots <= 0)
+			  AND type NOT IN (SELECT task_type FROM circuit_breakers WHERE opened_until > $2)
+			  AND NOT EXISTS (SELECT 1 FROM queue_pauses p WHERE p.scope IN ($9, tasks.type))
+			  AND NOT EXISTS (SELECT 1 FROM maintenance_mode WHERE enabled AND stop_claims)
+			  AND (rate_limit_key IS NULL OR rate_limit_key NOT IN (SELECT rate_limit_key FROM rate_windows WHERE remaining <= 0))
+			  AND ($6::int IS NULL OR priority >= $6)
+			  AND required_labels <@ $7::jsonb
+			  AND COALESCE(($8::jsonb ->> type)::int, 1) > 0
+			-- Must match idx_tasks_claim so the scan stops after enough candidates
 			ORDER BY 
-			  -- Prioritize tasks with expired locks (stalled tasks)
-			  CASE WHEN lock_expires_at IS NOT NULL AND lock_expires_at <= $2 THEN 0 ELSE 1 END,
-			  -- Then by priority (higher first)
+			  -- By priority (higher first)
 			  priority DESC, 
 			  -- Then by creation time (FIFO)
 			  created_at ASC
-			LIMIT 1
+			LIMIT $5::int * $13::int
 			FOR UPDATE SKIP LOCKED
+		),
+		next AS (
+			-- Trim the batch so no capped type, tenant or rate limit key receives more tasks than it has room for,
+			-- cluster-wide or on the claiming worker, then fill it round-robin across tenants
+			SELECT c.id
+			FROM (
+				SELECT id, type, tenant, rate_limit_key, priority, created_at,
+				       ROW_NUMBER() OVER (PARTITION BY type ORDER BY priority DESC, created_at ASC) AS type_rank,
+				       ROW_NUMBER() OVER (PARTITION BY tenant ORDER BY priority DESC, created_at ASC) AS tenant_rank,
+				       ROW_NUMBER() OVER (PARTITION BY rate_limit_key ORDER BY priority DESC, created_at ASC) AS key_rank
+				FROM candidates
+			) c
+			LEFT JOIN available a ON a.task_type = c.type
+			LEFT JOIN tenant_slots ts ON ts.tenant = c.tenant
+			LEFT JOIN tenant_quotas tq ON tq.tenant = c.tenant
+			LEFT JOIN tenant_quotas td ON td.tenant = $12
+			LEFT JOIN rate_limits r ON r.task_type = c.type
+			LEFT JOIN rate_windows w ON w.rate_limit_key = c.rate_limit_key
+			WHERE (a.slots IS NULL OR c.type_rank <= a.slots)
+			  AND (c.tenant = '' OR c.tenant_rank <= COALESCE(ts.slots, tq.max_running, td.max_running, c.tenant_rank))
+			  AND c.type_rank <= COALESCE(($8::jsonb ->> c.type)::int, c.type_rank)
+			  AND (c.rate_limit_key IS NULL OR r.task_type IS NULL OR c.key_rank <= COALESCE(w.remaining, r.max_per_window))
+			ORDER BY c.tenant_rank, c.priority DESC, c.created_at ASC
+			LIMIT $5
+		),
+		claimed AS (
+			UPDATE tasks
+			SET 
+				status = $1,
+				locked_at = $2,
+				lock_expires_at = $2::timestamp + (timeout_seconds || ' seconds')::interval,
+				locked_by = $4,
+				lock_token = lock_token + 1,
+				last_started_at = $2,
+				updated_at = $2
+			WHERE id IN (SELECT id FROM next)
+			RETURNING ` + taskColumns + `
+		),
+		lock_history AS (
+			-- Saves a round-trip per task; skipped when history lives in another database
+			INSERT INTO task_history (task_id, status, event_type, retry_count, max_retries, worker_id, created_at)
+			SELECT id, status, $10, retry_count, max_retries, $4, NOW()
+			FROM claimed
+			WHERE $11::boolean
 		)
-		RETURNING id, name, type, payload, status, priority, 
-		          retry_count, max_retries, last_error, 
-		          next_run_at, backoff_seconds, timeout_seconds, 
-		          locked_at, lock_expires_at, created_at, updated_at
-	`
-
-	var task models.Task
-	err := s.pool.QueryRow(ctx, query,
+		SELECT ` + taskColumns + ` FROM claimed`
+
+	rows, err := tx.Query(ctx, query,
 		models.TaskStatusRunning,
 		now,
 		models.TaskStatusQueued,
-	).Scan(
-		&task.ID,
-		&task.Name,
-		&task.Type,
-		&task.Payload,
-		&task.Status,
-		&task.Priority,
-		&task.RetryCount,
-		&task.MaxRetries,
-		&task.LastError,
-		&task.NextRunAt,
-		&task.BackoffSeconds,
-		&task.TimeoutSeconds,
-		&task.LockedAt,
-		&task.LockExpiresAt,
-		&task.CreatedAt,
-		&task.UpdatedAt,
+		workerID,
+		n,
+		filter.MinPriority,
+		jsonArg(workerLabels),
+		jsonArg(typeSlots),
+		models.PauseScopeQueue,
+		models.EventWorkerLockAcquired,
+		!s.separateHistory(),
+		models.DefaultTenantQuota,
+		claimOversample,
 	)
-
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil // No tasks available
+		return nil, err
+	}
+
+	var tasks []*models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
 		}
+		tasks = append(tasks, task)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
 		return nil, err
 	}
 
-	return &task, nil
+	// Best-effort history logging
+	if s.separateHistory() {
+		history := make([]models.TaskHistory, len(tasks))
+		for i, task := range tasks {
+			history[i] = lockAcquiredHistory(task, workerID)
+		}
+		if err := s.insertHistoryBatch(ctx, history); err != nil {
+			slog.Error("Failed to insert lock acquired history", "tasks", len(tasks), "error", err)
+		}
+	}
+
+	// RETURNING does not preserve the CTE ordering, so restore dispatch order
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Priority != tasks[j].Priority {
+			return tasks[i].Priority > tasks[j].Priority
+		}
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+	})
+
+	return tasks, nil
+}
+
+// lockAcquiredHistory is the worker_lock_acquired history entry of a task just claimed by workerID
+func lockAcquiredHistory(task *models.Task, workerID string) models.TaskHistory {
+	return models.TaskHistory{
+		TaskID:     task.ID,
+		Status:     models.TaskStatusRunning,
+		EventType:  models.EventWorkerLockAcquired,
+		RetryCount: &task.RetryCount,
+		MaxRetries: &task.MaxRetries,
+		WorkerID:   &workerID,
+	}
+}
+
+// lockClaimLimits locks the limit tables for the rest of the claim transaction
+// Empty tables lock nothing, so claims only serialize while limits are configured
+func lockClaimLimits(ctx context.Context, tx pgx.Tx) error {
+	if _, err := tx.Exec(ctx, `SELECT task_type FROM concurrency_limits FOR UPDATE`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `SELECT task_type FROM rate_limits FOR UPDATE`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `SELECT tenant FROM tenant_quotas WHERE max_running IS NOT NULL FOR UPDATE`); err != nil {
+		return err
+	}
+	return nil
 }
@@ -5,11 +5,14 @@ import (
 	"log/slog"
 
 	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
-	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
 )
 
 // CompleteTask marks a task as successfully completed
-func (s *Store) CompleteTask(ctx context.Context, taskID int64) error {
+// Only applies if the task is still held by the given lock
+func (s *Store) CompleteTask(ctx context.Context, taskID int64, lock models.TaskLock) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE tasks
 		SET 
@@ -17,17 +20,27 @@ func (s *Store) CompleteTask(ctx context.Context, taskID int64) error {
 			last_error = NULL,
 			locked_at = NULL,
 			lock_expires_at = NULL,
+			locked_by = NULL,
 			updated_at = NOW()
 		WHERE id = $2
+		  AND status = $3
+		  AND locked_by = $4
+		  AND lock_token = $5
 	`
 
-	result, err := s.pool.Exec(ctx, query, models.TaskStatusSucceeded, taskID)
+	result, err := s.pool.Exec(ctx, query,
+		models.TaskStatusSucceeded,
+		taskID,
+		models.TaskStatusRunning,
+		lock.WorkerID,
+		lock.Token,
+	)
 	if err != nil {
 		return err
 	}
 
 	if result.RowsAffected() == 0 {
-		return storage.ErrTaskNotFound
+		return s.lockLostOrNotFound(ctx, taskID)
 	}
 
 	// Best-effort history logging
@@ -35,6 +48,7 @@ func (s *Store) CompleteTask(ctx context.Context, taskID int64) error {
 		TaskID:    taskID,
 		Status:    models.TaskStatusSucceeded,
 		EventType: models.EventTaskSucceeded,
+		WorkerID:  &lock.WorkerID,
 	}
 
 	if err := s.InsertHistory(ctx, history); err != nil {
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+)
+
+// ListConcurrencyLimits returns all configured per-type concurrency limits with current usage
+func (s *Store) ListConcurrencyLimits(ctx context.Context) ([]models.ConcurrencyLimit, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		SELECT l.task_type, l.max_running, COUNT(t.id), l.updated_at
+		FROM concurrency_limits l
+		LEFT JOIN tasks t ON t.type = l.task_type AND t.status = $1
+		GROUP BY l.task_type, l.max_running, l.updated_at
+		ORDER BY l.task_type ASC
+	`
+
+	rows, err := s.pool.Query(ctx, query, models.TaskStatusRunning)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	limits := []models.ConcurrencyLimit{}
+	for rows.Next() {
+		var l models.ConcurrencyLimit
+		if err := rows.Scan(&l.TaskType, &l.MaxRunning, &l.Running, &l.UpdatedAt); err != nil {
+			return nil, err
+		}
+		limits = append(limits, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return limits, nil
+}
+
+// SetConcurrencyLimit creates or updates the concurrency limit for a task type
+func (s *Store) SetConcurrencyLimit(ctx context.Context, taskType string, maxRunning int) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO concurrency_limits (task_type, max_running, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (task_type) DO UPDATE
+		SET max_running = EXCLUDED.max_running, updated_at = NOW()
+	`
+
+	_, err := s.pool.Exec(ctx, query, strings.ToLower(taskType), maxRunning)
+	return err
+}
+
+// DeleteConcurrencyLimit removes the concurrency limit for a task type
+func (s *Store) DeleteConcurrencyLimit(ctx context.Context, taskType string) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	result, err := s.pool.Exec(ctx, `DELETE FROM concurrency_limits WHERE task_type = $1`, strings.ToLower(taskType))
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return storage.ErrConcurrencyLimitNotFound
+	}
+
+	return nil
+}
@@ -2,91 +2,251 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"log/slog"
 	"strings"
 	"time"
 
 	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 // CreateTask creates a new task in the database
 func (s *Store) CreateTask(ctx context.Context, req models.CreateTaskRequest) (*models.Task, error) {
-	// Normalize task type to lowercase for consistent handling
-	req.Type = strings.ToLower(req.Type)
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
 
-	// Set defaults
-	maxRetries := 3
-	if req.MaxRetries != nil {
-		maxRetries = *req.MaxRetries
+	task, err := s.createTask(ctx, req)
+	if err != nil {
+		return nil, err
 	}
 
-	timeoutSeconds := 30
-	if req.TimeoutSeconds != nil {
-		timeoutSeconds = *req.TimeoutSeconds
+	// Best-effort history logging - don't fail task creation if history insert fails
+	if err := insertHistory(ctx, s.history, queuedHistory(task)); err != nil {
+		slog.Error("Failed to insert task creation history", "task_id", task.ID, "error", err)
 	}
 
-	backoffSeconds := 5
-	if req.BackoffSeconds != nil {
-		backoffSeconds = *req.BackoffSeconds
+	// Wake up listening workers
+	s.notifyTaskCreated(ctx, task.ID)
+
+	return task, nil
+}
+
+// createTask inserts a task, in a transaction when its tenant quota check must hold until the insert commits
+func (s *Store) createTask(ctx context.Context, req models.CreateTaskRequest) (*models.Task, error) {
+	if req.Tenant == "" {
+		return s.insertTask(ctx, s.pool, req)
 	}
 
-	// Default payload to empty JSON object if not provided
-	payload := req.Payload
-	if len(payload) == 0 {
-		payload = []byte("{}")
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	task, err := s.insertTask(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// CreateTaskTx creates a task inside the caller's transaction
+// The task, its history entry and the worker notification only take effect if tx commits,
+// so a business write and its follow-up task are enqueued atomically
+// Unlike CreateTask, a failed history insert is returned because it aborts tx
+// With a separate history database the history entry is written right away, outside tx,
+// and remains if tx rolls back
+func (s *Store) CreateTaskTx(ctx context.Context, tx pgx.Tx, req models.CreateTaskRequest) (*models.Task, error) {
+	tx = s.pool.prefix.tx(tx)
+
+	task, err := s.insertTask(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var historyDB querier = tx
+	if s.separateHistory() {
+		historyDB = s.history
+	}
+	if err := insertHistory(ctx, historyDB, queuedHistory(task)); err != nil {
+		return nil, err
+	}
+
+	// NOTIFY inside a transaction is delivered on commit
+	if err := notifyTaskCreated(ctx, tx, task.ID); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// insertTask inserts a queued task through q, applying the request defaults
+// A task with a tenant is checked against the tenant's max_queued quota
+func (s *Store) insertTask(ctx context.Context, q querier, req models.CreateTaskRequest) (*models.Task, error) {
+	row, err := newTaskRow(req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkTenantQuotas(ctx, q, []taskRow{row}); err != nil {
+		return nil, err
 	}
 
 	query := `
-		INSERT INTO tasks (
-			name, type, payload, priority, status, 
-			retry_count, max_retries, backoff_seconds, 
-			timeout_seconds, next_run_at, 
-			created_at, updated_at
+		INSERT INTO tasks (` + strings.Join(taskRowColumns, ", ") + `)
+		VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14,
+			-- Derive the key from the payload field configured for the type's rate limit
+			COALESCE($15, (SELECT task_type || ':' || ($3::jsonb ->> key_field) FROM rate_limits WHERE task_type = $2)),
+			$16, $17, $18, $19, $20, $21, $22
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
-		RETURNING id, name, type, payload, status, priority, 
-		          retry_count, max_retries, last_error, 
-		          next_run_at, backoff_seconds, timeout_seconds, 
-		          locked_at, lock_expires_at, created_at, updated_at
-	`
-
-	var task models.Task
-	err := s.pool.QueryRow(ctx, query,
-		req.Name,
-		req.Type,
-		payload,
-		req.Priority,
-		models.TaskStatusQueued,
-		0, // retry_count starts at 0
-		maxRetries,
-		backoffSeconds,
-		timeoutSeconds,
-		time.Now(), // next_run_at - available immediately
-	).Scan(
-		&task.ID,
-		&task.Name,
-		&task.Type,
-		&task.Payload,
-		&task.Status,
-		&task.Priority,
-		&task.RetryCount,
-		&task.MaxRetries,
-		&task.LastError,
-		&task.NextRunAt,
-		&task.BackoffSeconds,
-		&task.TimeoutSeconds,
-		&task.LockedAt,
-		&task.LockExpiresAt,
-		&task.CreatedAt,
-		&task.UpdatedAt,
-	)
+		RETURNING ` + taskColumns
+
+	return scanTask(q.QueryRow(ctx, query, row.values()...))
+}
+
+// taskRowColumns are the columns written for a new task, in taskRow.values order
+var taskRowColumns = []string{
+	"name", "type", "payload", "priority", "status",
+	"retry_count", "max_retries", "backoff_seconds",
+	"retry_strategy", "retry_schedule", "max_backoff_seconds",
+	"timeout_seconds", "max_timeouts", "next_run_at",
+	"rate_limit_key", "required_labels", "created_at", "updated_at",
+	"metadata", "tenant", "payload_signature", "public_id",
+}
+
+// taskRow holds the column values of a new task after applying the request defaults
+type taskRow struct {
+	name              string
+	taskType          string
+	payload           json.RawMessage
+	priority          int
+	maxRetries        int
+	backoffSeconds    int
+	retryStrategy     models.RetryStrategy
+	retrySchedule     []int
+	maxBackoffSeconds *int
+	timeoutSeconds    int
+	maxTimeouts       *int
+	rateLimitKey      *string // explicit key only; derived keys are filled in by the insert
+	requiredLabels    map[string]string
+	metadata          map[string]string
+	tenant            string
+	payloadSignature  string
+	publicID          uuid.UUID
+	now               time.Time
+}
+
+// newTaskRow applies the defaults for a new task created at now
+func newTaskRow(req models.CreateTaskRequest, now time.Time) (taskRow, error) {
+	row := taskRow{
+		name:              req.Name,
+		taskType:          strings.ToLower(req.Type), // Normalize task type to lowercase for consistent handling
+		payload:           req.Payload,
+		priority:          req.Priority,
+		maxRetries:        3,
+		backoffSeconds:    5,
+		retryStrategy:     models.RetryStrategyExponential,
+		maxBackoffSeconds: req.MaxBackoffSeconds,
+		timeoutSeconds:    30,
+		maxTimeouts:       req.MaxTimeouts,
+		requiredLabels:    req.RequiredLabels,
+		metadata:          req.Metadata,
+		tenant:            req.Tenant,
+		payloadSignature:  req.PayloadSignature,
+		publicID:          req.PublicID,
+		now:               now,
+	}
+
+	// Signed requests carry the id their signature covers
+	if row.publicID == uuid.Nil {
+		publicID, err := uuid.NewV7()
+		if err != nil {
+			return taskRow{}, err
+		}
+		row.publicID = publicID
+	}
+
+	// Set defaults
+	if req.MaxRetries != nil {
+		row.maxRetries = *req.MaxRetries
+	}
+	if req.TimeoutSeconds != nil {
+		row.timeoutSeconds = *req.TimeoutSeconds
+	}
+	if req.BackoffSeconds != nil {
+		row.backoffSeconds = *req.BackoffSeconds
+	}
+	if req.RetryStrategy != "" {
+		row.retryStrategy = req.RetryStrategy
+	}
 
+	retrySchedule, err := models.ParseRetrySchedule(req.RetrySchedule)
 	if err != nil {
-		return nil, err
+		return taskRow{}, err
 	}
+	row.retrySchedule = retrySchedule
 
-	// Best-effort history logging - don't fail task creation if history insert fails
-	history := models.TaskHistory{
+	// Explicit rate limit keys are scoped to the task type, like derived ones
+	if req.RateLimitKey != nil && *req.RateLimitKey != "" {
+		key := row.taskType + ":" + *req.RateLimitKey
+		row.rateLimitKey = &key
+	}
+
+	// No labels means any worker may claim the task
+	if row.requiredLabels == nil {
+		row.requiredLabels = map[string]string{}
+	}
+
+	if row.metadata == nil {
+		row.metadata = map[string]string{}
+	}
+
+	// Default payload to empty JSON object if not provided
+	if len(row.payload) == 0 {
+		row.payload = []byte("{}")
+	}
+
+	return row, nil
+}
+
+// values returns the column values in taskRowColumns order
+func (r taskRow) values() []any {
+	return []any{
+		r.name,
+		r.taskType,
+		r.payload,
+		r.priority,
+		string(models.TaskStatusQueued),
+		0, // retry_count starts at 0
+		r.maxRetries,
+		r.backoffSeconds,
+		string(r.retryStrategy),
+		r.retrySchedule,
+		r.maxBackoffSeconds,
+		r.timeoutSeconds,
+		r.maxTimeouts,
+		r.now, // next_run_at - available immediately
+		r.rateLimitKey,
+		jsonArg(r.requiredLabels),
+		r.now, // created_at
+		r.now, // updated_at
+		jsonArg(r.metadata),
+		r.tenant,
+		r.payloadSignature,
+		r.publicID,
+	}
+}
+
+// queuedHistory returns the task_queued event recorded for a new task
+func queuedHistory(task *models.Task) models.TaskHistory {
+	return models.TaskHistory{
 		TaskID:         task.ID,
 		Status:         models.TaskStatusQueued,
 		EventType:      models.EventTaskQueued,
@@ -95,10 +255,4 @@ func (s *Store) CreateTask(ctx context.Context, req models.CreateTaskRequest) (*
 		BackoffSeconds: &task.BackoffSeconds,
 		NextRunAt:      &task.NextRunAt,
 	}
-
-	if err := s.InsertHistory(ctx, history); err != nil {
-		slog.Error("Failed to insert task creation history", "task_id", task.ID, "error", err)
-	}
-
-	return &task, nil
 }
@@ -0,0 +1,232 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+// copyTypes are the custom column types COPY needs registered to encode them in binary
+var copyTypes = []string{"task_status"}
+
+// CreateTasks creates many tasks in one transaction using COPY, all or none
+// Their task_queued history is copied in the same transaction and workers are notified once
+// With a separate history database, history is copied there after the commit on a best-effort basis
+// Returns the tasks in request order
+func (s *Store) CreateTasks(ctx context.Context, reqs []models.CreateTaskRequest) ([]*models.Task, error) {
+	ctx, cancel := s.longQuery(ctx)
+	defer cancel()
+
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	rows := make([]taskRow, len(reqs))
+	types := map[string]bool{}
+	for i, req := range reqs {
+		row, err := newTaskRow(req, now)
+		if err != nil {
+			return nil, err
+		}
+		rows[i] = row
+		types[row.taskType] = true
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := registerCopyTypes(ctx, tx.Conn(), s.pool.prefix); err != nil {
+		return nil, err
+	}
+
+	// COPY cannot return generated ids, so reserve them up front
+	ids, err := reserveTaskIDs(ctx, tx, len(rows))
+	if err != nil {
+		return nil, err
+	}
+
+	// COPY cannot run the rate limit subquery either, so derive keys here
+	if err := deriveRateLimitKeys(ctx, tx, rows, types); err != nil {
+		return nil, err
+	}
+
+	if err := checkTenantQuotas(ctx, tx, rows); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"tasks"},
+		append([]string{"id"}, taskRowColumns...),
+		pgx.CopyFromSlice(len(rows), func(i int) ([]any, error) {
+			return append([]any{ids[i]}, rows[i].values()...), nil
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := loadTasks(ctx, tx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]models.TaskHistory, len(tasks))
+	for i, task := range tasks {
+		history[i] = queuedHistory(task)
+	}
+	if !s.separateHistory() {
+		if err := copyHistory(ctx, tx, s.pool.prefix, history, now); err != nil {
+			return nil, err
+		}
+	}
+
+	// One notification wakes every listening worker; they claim in batches anyway
+	if err := notifyTaskCreated(ctx, tx, ids[0]); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	if s.separateHistory() {
+		if err := s.copySeparateHistory(ctx, history, now); err != nil {
+			slog.Error("Failed to copy task creation history", "tasks", len(history), "error", err)
+		}
+	}
+	return tasks, nil
+}
+
+// copySeparateHistory copies history entries into the separate history database in their own transaction
+func (s *Store) copySeparateHistory(ctx context.Context, history []models.TaskHistory, createdAt time.Time) error {
+	tx, err := s.history.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := copyHistory(ctx, tx, s.history.prefix, history, createdAt); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// copyHistory inserts many history entries with COPY, all created at createdAt
+func copyHistory(ctx context.Context, tx pgx.Tx, prefix *tablePrefix, history []models.TaskHistory, createdAt time.Time) error {
+	if len(history) == 0 {
+		return nil
+	}
+
+	if err := registerCopyTypes(ctx, tx.Conn(), prefix); err != nil {
+		return err
+	}
+
+	columns := []string{
+		"task_id", "status", "event_type",
+		"retry_count", "max_retries", "backoff_seconds", "next_run_at",
+		"error_message", "worker_id", "created_at",
+	}
+
+	_, err := tx.CopyFrom(ctx, pgx.Identifier{"task_history"}, columns,
+		pgx.CopyFromSlice(len(history), func(i int) ([]any, error) {
+			h := history[i]
+			return []any{
+				h.TaskID, string(h.Status), string(h.EventType),
+				h.RetryCount, h.MaxRetries, h.BackoffSeconds, h.NextRunAt,
+				h.ErrorMessage, h.WorkerID, createdAt,
+			}, nil
+		}),
+	)
+	return err
+}
+
+// registerCopyTypes loads copyTypes into the connection's type map the first time it copies
+func registerCopyTypes(ctx context.Context, conn *pgx.Conn, prefix *tablePrefix) error {
+	typeMap := conn.TypeMap()
+	for _, name := range copyTypes {
+		name = prefix.name(name)
+		if _, ok := typeMap.TypeForName(name); ok {
+			continue
+		}
+
+		t, err := conn.LoadType(ctx, name)
+		if err != nil {
+			return err
+		}
+		typeMap.RegisterType(t)
+	}
+	return nil
+}
+
+// reserveTaskIDs draws n ids from the tasks id sequence
+func reserveTaskIDs(ctx context.Context, tx pgx.Tx, n int) ([]int64, error) {
+	rows, err := tx.Query(ctx, `SELECT nextval(pg_get_serial_sequence('tasks', 'id')) FROM generate_series(1, $1)`, n)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
+
+// deriveRateLimitKeys fills in the payload-derived rate limit key of rows without an explicit one
+func deriveRateLimitKeys(ctx context.Context, tx pgx.Tx, rows []taskRow, types map[string]bool) error {
+	taskTypes := make([]string, 0, len(types))
+	for t := range types {
+		taskTypes = append(taskTypes, t)
+	}
+
+	keyFields := map[string]string{}
+	result, err := tx.Query(ctx, `SELECT task_type, key_field FROM rate_limits WHERE task_type = ANY($1)`, taskTypes)
+	if err != nil {
+		return err
+	}
+	for result.Next() {
+		var taskType, keyField string
+		if err := result.Scan(&taskType, &keyField); err != nil {
+			result.Close()
+			return err
+		}
+		keyFields[taskType] = keyField
+	}
+	result.Close()
+	if err := result.Err(); err != nil {
+		return err
+	}
+
+	for i := range rows {
+		keyField, ok := keyFields[rows[i].taskType]
+		if !ok || rows[i].rateLimitKey != nil {
+			continue
+		}
+		if value, ok := storage.PayloadKey(rows[i].payload, keyField); ok {
+			key := rows[i].taskType + ":" + value
+			rows[i].rateLimitKey = &key
+		}
+	}
+	return nil
+}
+
+// loadTasks reads tasks by id through tx, in the order of ids
+func loadTasks(ctx context.Context, tx pgx.Tx, ids []int64) ([]*models.Task, error) {
+	rows, err := tx.Query(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = ANY($1) ORDER BY id`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []*models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
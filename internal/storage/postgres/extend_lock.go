@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// ExtendLock pushes lock_expires_at forward for a running task
+// Called periodically by the worker heartbeat so long tasks are not re-claimed
+// Only applies if the task is still held by the given lock
+func (s *Store) ExtendLock(ctx context.Context, taskID int64, lock models.TaskLock, extendBy time.Duration) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	now := time.Now()
+
+	query := `
+		UPDATE tasks
+		SET 
+			lock_expires_at = $1,
+			updated_at = $2
+		WHERE id = $3
+		  AND status = $4
+		  AND locked_by = $5
+		  AND lock_token = $6
+	`
+
+	result, err := s.pool.Exec(ctx, query,
+		now.Add(extendBy),
+		now,
+		taskID,
+		models.TaskStatusRunning,
+		lock.WorkerID,
+		lock.Token,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return s.lockLostOrNotFound(ctx, taskID)
+	}
+
+	return nil
+}
@@ -6,40 +6,45 @@ import (
 
 	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
 	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 )
 
 // GetTask retrieves a task by ID
 func (s *Store) GetTask(ctx context.Context, id int64) (*models.Task, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
 	query := `
-		SELECT id, name, type, payload, status, priority, 
-		       retry_count, max_retries, last_error, 
-		       next_run_at, backoff_seconds, timeout_seconds, 
-		       locked_at, lock_expires_at, created_at, updated_at
+		SELECT ` + taskColumns + `
 		FROM tasks
 		WHERE id = $1
 	`
 
-	var task models.Task
-	err := s.pool.QueryRow(ctx, query, id).Scan(
-		&task.ID,
-		&task.Name,
-		&task.Type,
-		&task.Payload,
-		&task.Status,
-		&task.Priority,
-		&task.RetryCount,
-		&task.MaxRetries,
-		&task.LastError,
-		&task.NextRunAt,
-		&task.BackoffSeconds,
-		&task.TimeoutSeconds,
-		&task.LockedAt,
-		&task.LockExpiresAt,
-		&task.CreatedAt,
-		&task.UpdatedAt,
-	)
+	task, err := scanTask(s.pool.QueryRow(ctx, query, id))
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, storage.ErrTaskNotFound
+		}
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// GetTaskByPublicID retrieves a task by its public UUID
+func (s *Store) GetTaskByPublicID(ctx context.Context, publicID uuid.UUID) (*models.Task, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE public_id = $1
+	`
 
+	task, err := scanTask(s.pool.QueryRow(ctx, query, publicID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, storage.ErrTaskNotFound
@@ -47,5 +52,5 @@ func (s *Store) GetTask(ctx context.Context, id int64) (*models.Task, error) {
 		return nil, err
 	}
 
-	return &task, nil
+	return task, nil
 }
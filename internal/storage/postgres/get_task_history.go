@@ -2,22 +2,41 @@ package postgres
 
 import (
 	"context"
+	"time"
 
 	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
 )
 
+// historyLookback widens the lower time bound of history queries
+// History created_at comes from the database clock and task created_at from the application's,
+// so the bound must tolerate skew and time zone differences between the two
+const historyLookback = 24 * time.Hour
+
 // GetTaskHistory retrieves the history of status changes for a task
-func (s *Store) GetTaskHistory(ctx context.Context, taskID int64) ([]models.TaskHistory, error) {
+// The lower time bound lets PostgreSQL skip older monthly partitions and BRIN block ranges
+func (s *Store) GetTaskHistory(ctx context.Context, taskID int64, since time.Time) ([]models.TaskHistory, error) {
+	ctx, cancel := s.longQuery(ctx)
+	defer cancel()
+
+	// A plain comparison, rather than one that also accepts NULL, keeps pruning possible in generic plans
+	args := []any{taskID}
+	bound := ""
+	if !since.IsZero() {
+		bound = "AND created_at >= $2"
+		args = append(args, since.Add(-historyLookback))
+	}
+
 	query := `
 		SELECT id, task_id, status, event_type, 
 		       retry_count, max_retries, backoff_seconds, next_run_at,
-		       error_message, worker_id, created_at
+		       error_message, worker_id, actor, created_at
 		FROM task_history
 		WHERE task_id = $1
+		  ` + bound + `
 		ORDER BY created_at ASC
 	`
 
-	rows, err := s.pool.Query(ctx, query, taskID)
+	rows, err := s.history.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -37,6 +56,7 @@ func (s *Store) GetTaskHistory(ctx context.Context, taskID int64) ([]models.Task
 			&h.NextRunAt,
 			&h.ErrorMessage,
 			&h.WorkerID,
+			&h.Actor,
 			&h.CreatedAt,
 		)
 		if err != nil {
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// CheckHealth reports connectivity, latency, pool use and the applied migration version
+// of the database, and of the history database when it is separate
+// A saturated pool or a dirty migration is reported as degraded
+func (s *Store) CheckHealth(ctx context.Context) []models.ComponentHealth {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	components := []models.ComponentHealth{poolHealth(ctx, "database", s.pool, "schema_migrations")}
+	if s.separateHistory() {
+		components = append(components, poolHealth(ctx, "history_database", s.history, "history_schema_migrations"))
+	}
+	return components
+}
+
+// SchemaVersion returns the migration version applied to the queue database
+func (s *Store) SchemaVersion(ctx context.Context) (models.MigrationHealth, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	version, err := migrationVersion(ctx, s.pool, "schema_migrations")
+	if err != nil {
+		return models.MigrationHealth{}, err
+	}
+	return *version, nil
+}
+
+// poolHealth checks one database through its pool; migrationsTable is the unprefixed golang-migrate table
+func poolHealth(ctx context.Context, name string, pool *prefixedPool, migrationsTable string) models.ComponentHealth {
+	c := models.ComponentHealth{Name: name, Status: models.HealthStatusOK}
+
+	started := time.Now()
+	err := pool.Ping(ctx)
+	c.LatencySeconds = time.Since(started).Seconds()
+	if err != nil {
+		c.Status = models.HealthStatusDown
+		c.Error = err.Error()
+		return c
+	}
+
+	stat := pool.pool.Stat()
+	c.Pool = &models.PoolHealth{
+		MaxConns:      int(stat.MaxConns()),
+		TotalConns:    int(stat.TotalConns()),
+		AcquiredConns: int(stat.AcquiredConns()),
+		IdleConns:     int(stat.IdleConns()),
+	}
+	if c.Pool.MaxConns > 0 {
+		c.Pool.Utilization = float64(c.Pool.AcquiredConns) / float64(c.Pool.MaxConns)
+		if c.Pool.AcquiredConns >= c.Pool.MaxConns {
+			c.Status = models.HealthStatusDegraded
+			c.Error = "connection pool exhausted"
+		}
+	}
+
+	migration, err := migrationVersion(ctx, pool, migrationsTable)
+	switch {
+	case err != nil:
+		c.Status = models.HealthStatusDegraded
+		c.Error = "migration version unavailable: " + err.Error()
+	case migration.Dirty:
+		c.Status = models.HealthStatusDegraded
+		c.Error = "migration is dirty"
+	}
+	c.Migration = migration
+
+	return c
+}
+
+// migrationVersion reads the version golang-migrate recorded, version 0 if none was applied
+// The table name is not among the rewritten queue objects, so the prefix is applied explicitly
+func migrationVersion(ctx context.Context, pool *prefixedPool, table string) (*models.MigrationHealth, error) {
+	var version int64
+	var dirty bool
+	err := pool.pool.QueryRow(ctx, `SELECT version, dirty FROM `+pool.prefix.name(table)+` LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &models.MigrationHealth{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &models.MigrationHealth{Version: uint(version), Dirty: dirty}, nil
+}
@@ -4,20 +4,49 @@ import (
 	"context"
 
 	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/jackc/pgx/v5"
 )
 
+// insertHistoryQuery inserts one history entry from historyArgs
+const insertHistoryQuery = `
+	INSERT INTO task_history (
+		task_id, status, event_type, 
+		retry_count, max_retries, backoff_seconds, next_run_at,
+		error_message, worker_id, actor, created_at
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+`
+
 // InsertHistory adds a new detailed event entry to task history
 func (s *Store) InsertHistory(ctx context.Context, history models.TaskHistory) error {
-	query := `
-		INSERT INTO task_history (
-			task_id, status, event_type, 
-			retry_count, max_retries, backoff_seconds, next_run_at,
-			error_message, worker_id, created_at
-		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
-	`
-
-	_, err := s.pool.Exec(ctx, query,
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	return insertHistory(ctx, s.history, history)
+}
+
+// insertHistory inserts a history entry through q
+func insertHistory(ctx context.Context, q querier, history models.TaskHistory) error {
+	_, err := q.Exec(ctx, insertHistoryQuery, historyArgs(history)...)
+	return err
+}
+
+// insertHistoryBatch inserts history entries in a single pipelined round-trip
+func (s *Store) insertHistoryBatch(ctx context.Context, history []models.TaskHistory) error {
+	if len(history) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, h := range history {
+		batch.Queue(insertHistoryQuery, historyArgs(h)...)
+	}
+	return s.history.SendBatch(ctx, batch).Close()
+}
+
+// historyArgs returns the parameters of insertHistoryQuery
+func historyArgs(history models.TaskHistory) []any {
+	return []any{
 		history.TaskID,
 		history.Status,
 		history.EventType,
@@ -27,6 +56,6 @@ func (s *Store) InsertHistory(ctx context.Context, history models.TaskHistory) e
 		history.NextRunAt,
 		history.ErrorMessage,
 		history.WorkerID,
-	)
-	return err
+		history.Actor,
+	}
 }
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// GetLatencyStats returns queue wait and execution time percentiles per type
+// over the last attempt of tasks that finished within r
+// Reads idx_tasks_finished; a finished task's updated_at is when its last attempt ended
+func (s *Store) GetLatencyStats(ctx context.Context, r models.TimeRange) ([]models.LatencyStats, error) {
+	ctx, cancel := s.longQuery(ctx)
+	defer cancel()
+
+	finished, args := rangeFilter("updated_at", r, nil)
+	if finished != "" {
+		finished = "AND " + finished
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT
+			type,
+			COUNT(*),
+			percentile_cont(ARRAY[0.5, 0.9, 0.99]) WITHIN GROUP (
+				ORDER BY GREATEST(0, EXTRACT(EPOCH FROM last_started_at - next_run_at))::float8
+			),
+			percentile_cont(ARRAY[0.5, 0.9, 0.99]) WITHIN GROUP (
+				ORDER BY GREATEST(0, EXTRACT(EPOCH FROM updated_at - last_started_at))::float8
+			)
+		FROM tasks
+		WHERE status IN ('succeeded', 'failed')
+		  AND last_started_at IS NOT NULL
+		  `+finished+`
+		GROUP BY type
+		ORDER BY type
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []models.LatencyStats{}
+	for rows.Next() {
+		var l models.LatencyStats
+		var wait, execution []float64
+		if err := rows.Scan(&l.TaskType, &l.Count, &wait, &execution); err != nil {
+			return nil, err
+		}
+		l.QueueWait = percentiles(wait)
+		l.Execution = percentiles(execution)
+		stats = append(stats, l)
+	}
+
+	return stats, rows.Err()
+}
+
+// percentiles maps the result of percentile_cont(ARRAY[0.5, 0.9, 0.99])
+func percentiles(values []float64) models.Percentiles {
+	if len(values) != 3 {
+		return models.Percentiles{}
+	}
+	return models.Percentiles{P50: values[0], P90: values[1], P99: values[2]}
+}
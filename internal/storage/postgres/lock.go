@@ -0,0 +1,23 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+)
+
+// lockLostOrNotFound explains why a fenced update matched no rows
+// Returns ErrTaskNotFound if the task does not exist, ErrLockLost otherwise
+func (s *Store) lockLostOrNotFound(ctx context.Context, taskID int64) error {
+	var exists bool
+	err := s.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1)`, taskID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return storage.ErrTaskNotFound
+	}
+
+	return storage.ErrLockLost
+}
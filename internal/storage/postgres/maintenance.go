@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetMaintenance returns the queue-wide maintenance mode
+func (s *Store) GetMaintenance(ctx context.Context) (*models.MaintenanceMode, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		SELECT enabled, stop_claims, reason, updated_at
+		FROM maintenance_mode
+	`
+
+	var m models.MaintenanceMode
+	err := s.pool.QueryRow(ctx, query).Scan(&m.Enabled, &m.StopClaims, &m.Reason, &m.UpdatedAt)
+	if err != nil {
+		// The row is seeded by the migration; treat a missing row as disabled
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &models.MaintenanceMode{}, nil
+		}
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// SetMaintenance toggles maintenance mode and returns the new state
+// Claims only stop while maintenance mode is enabled
+func (s *Store) SetMaintenance(ctx context.Context, req models.SetMaintenanceRequest) (*models.MaintenanceMode, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO maintenance_mode (id, enabled, stop_claims, reason, updated_at)
+		VALUES (TRUE, $1, $2, $3, NOW())
+		ON CONFLICT (id) DO UPDATE
+		SET enabled = EXCLUDED.enabled,
+		    stop_claims = EXCLUDED.stop_claims,
+		    reason = EXCLUDED.reason,
+		    updated_at = EXCLUDED.updated_at
+		RETURNING enabled, stop_claims, reason, updated_at
+	`
+
+	enabled := *req.Enabled
+	stopClaims := enabled && req.StopClaims
+	reason := req.Reason
+	if !enabled {
+		reason = nil
+	}
+
+	var m models.MaintenanceMode
+	err := s.pool.QueryRow(ctx, query, enabled, stopClaims, reason).Scan(&m.Enabled, &m.StopClaims, &m.Reason, &m.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
@@ -5,11 +5,14 @@ import (
 	"log/slog"
 
 	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
-	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
 )
 
 // MarkTaskFailed permanently marks a task as failed (no more retries)
-func (s *Store) MarkTaskFailed(ctx context.Context, taskID int64, errorMessage string) error {
+// Only applies if the task is still held by the given lock
+func (s *Store) MarkTaskFailed(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE tasks
 		SET 
@@ -17,14 +20,21 @@ func (s *Store) MarkTaskFailed(ctx context.Context, taskID int64, errorMessage s
 			last_error = $2,
 			locked_at = NULL,
 			lock_expires_at = NULL,
+			locked_by = NULL,
 			updated_at = NOW()
 		WHERE id = $3
+		  AND status = $4
+		  AND locked_by = $5
+		  AND lock_token = $6
 	`
 
 	result, err := s.pool.Exec(ctx, query,
 		models.TaskStatusFailed,
 		errorMessage,
 		taskID,
+		models.TaskStatusRunning,
+		lock.WorkerID,
+		lock.Token,
 	)
 
 	if err != nil {
@@ -32,7 +42,7 @@ func (s *Store) MarkTaskFailed(ctx context.Context, taskID int64, errorMessage s
 	}
 
 	if result.RowsAffected() == 0 {
-		return storage.ErrTaskNotFound
+		return s.lockLostOrNotFound(ctx, taskID)
 	}
 
 	// Best-effort history logging
@@ -41,6 +51,7 @@ func (s *Store) MarkTaskFailed(ctx context.Context, taskID int64, errorMessage s
 		Status:       models.TaskStatusFailed,
 		EventType:    models.EventTaskFailedFinal,
 		ErrorMessage: &errorMessage,
+		WorkerID:     &lock.WorkerID,
 	}
 
 	if err := s.InsertHistory(ctx, history); err != nil {
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// taskCreatedChannel is the LISTEN/NOTIFY channel used to announce new tasks
+const taskCreatedChannel = "task_created"
+
+// listenRetryDelay is how long to wait before re-establishing a dropped LISTEN connection
+const listenRetryDelay = 1 * time.Second
+
+// notifyTaskCreated announces a new task to listening workers
+// Best-effort: workers still pick the task up on their next poll if this fails
+func (s *Store) notifyTaskCreated(ctx context.Context, taskID int64) {
+	if err := notifyTaskCreated(ctx, s.pool, taskID); err != nil {
+		slog.Error("Failed to notify task creation", "task_id", taskID, "error", err)
+	}
+}
+
+// notifyTaskCreated sends the task_created notification through q
+func notifyTaskCreated(ctx context.Context, q querier, taskID int64) error {
+	_, err := q.Exec(ctx, `SELECT pg_notify('`+taskCreatedChannel+`', $1)`, strconv.FormatInt(taskID, 10))
+	return err
+}
+
+// ListenTaskCreated holds a dedicated connection on LISTEN task_created and signals for every notification
+// Reconnects after connection errors until ctx is done
+// Behind a transaction pooler LISTEN cannot work, so the channel is closed at once and workers poll
+func (s *Store) ListenTaskCreated(ctx context.Context) <-chan struct{} {
+	signals := make(chan struct{}, 1)
+
+	if s.transactionPooling {
+		slog.Info("Task notifications disabled behind a transaction pooler")
+		close(signals)
+		return signals
+	}
+
+	go func() {
+		defer close(signals)
+
+		for ctx.Err() == nil {
+			if err := s.listen(ctx, signals); err != nil && ctx.Err() == nil {
+				slog.Error("Task notification listener failed, reconnecting", "error", err)
+				select {
+				case <-time.After(listenRetryDelay):
+				case <-ctx.Done():
+				}
+			}
+		}
+	}()
+
+	return signals
+}
+
+// listen runs a single LISTEN session until the connection fails or ctx is done
+func (s *Store) listen(ctx context.Context, signals chan<- struct{}) error {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, s.pool.prefix.rewrite("LISTEN "+taskCreatedChannel)); err != nil {
+		return err
+	}
+
+	for {
+		if _, err := conn.Conn().WaitForNotification(ctx); err != nil {
+			return err
+		}
+
+		// Coalesce: one pending signal is enough to wake the dispatcher
+		select {
+		case signals <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// historyPartitionPrefix prefixes the monthly task_history partitions, e.g. task_history_2026_10
+const historyPartitionPrefix = "task_history_"
+
+// historyPartitionsAhead is how many months of partitions exist beyond the current one
+const historyPartitionsAhead = 2
+
+// historyPartitionLockID is the advisory lock that serializes partition maintenance across API servers
+const historyPartitionLockID = 73160018
+
+// MaintainHistoryPartitions creates the task_history partitions for this month and the next ones,
+// and drops partitions whose month ended more than retention ago (0 keeps every partition)
+// Returns the names of the created and dropped partitions
+func (s *Store) MaintainHistoryPartitions(ctx context.Context, retention time.Duration) ([]string, []string, error) {
+	ctx, cancel := s.longQuery(ctx)
+	defer cancel()
+
+	tx, err := s.history.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, historyPartitionLockID); err != nil {
+		return nil, nil, err
+	}
+
+	// Month boundaries follow the database clock, like the created_at defaults
+	var now, month time.Time
+	if err := tx.QueryRow(ctx, `SELECT NOW()::timestamp, date_trunc('month', NOW())::timestamp`).Scan(&now, &month); err != nil {
+		return nil, nil, err
+	}
+
+	existing, err := historyPartitions(ctx, tx, s.history.prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var created []string
+	for i := 0; i <= historyPartitionsAhead; i++ {
+		start := month.AddDate(0, i, 0)
+		name := historyPartitionName(start)
+		if existing[name] {
+			continue
+		}
+
+		query := fmt.Sprintf(`CREATE TABLE %s PARTITION OF task_history FOR VALUES FROM ('%s') TO ('%s')`,
+			pgx.Identifier{name}.Sanitize(), start.Format(time.DateOnly), start.AddDate(0, 1, 0).Format(time.DateOnly))
+		if _, err := tx.Exec(ctx, query); err != nil {
+			return nil, nil, fmt.Errorf("creating partition %s: %w", name, err)
+		}
+		created = append(created, name)
+	}
+
+	var dropped []string
+	if retention > 0 {
+		cutoff := now.Add(-retention)
+		for name := range existing {
+			start, ok := historyPartitionMonth(name)
+			if !ok || !start.AddDate(0, 1, 0).Before(cutoff) {
+				continue
+			}
+
+			if _, err := tx.Exec(ctx, `DROP TABLE `+pgx.Identifier{name}.Sanitize()); err != nil {
+				return nil, nil, fmt.Errorf("dropping partition %s: %w", name, err)
+			}
+			dropped = append(dropped, name)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	return created, dropped, nil
+}
+
+// historyPartitions returns the names of the current task_history partitions, without the table prefix
+func historyPartitions(ctx context.Context, tx pgx.Tx, prefix *tablePrefix) (map[string]bool, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = 'task_history'::regclass
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	partitions := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		partitions[strings.TrimPrefix(name, prefix.prefix)] = true
+	}
+	return partitions, rows.Err()
+}
+
+// historyPartitionName returns the partition holding the month starting at month
+func historyPartitionName(month time.Time) string {
+	return historyPartitionPrefix + month.Format("2006_01")
+}
+
+// historyPartitionMonth parses the month of a monthly partition name
+// Returns false for other partitions, such as task_history_default
+func historyPartitionMonth(name string) (time.Time, bool) {
+	suffix, ok := strings.CutPrefix(name, historyPartitionPrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	month, err := time.Parse("2006_01", suffix)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return month, true
+}
@@ -0,0 +1,26 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistoryPartitionMonth(t *testing.T) {
+	month := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	name := historyPartitionName(month)
+	if name != "task_history_2026_10" {
+		t.Fatalf("historyPartitionName() = %q, want task_history_2026_10", name)
+	}
+
+	got, ok := historyPartitionMonth(name)
+	if !ok || !got.Equal(month) {
+		t.Errorf("historyPartitionMonth(%q) = %v, %v, want %v", name, got, ok, month)
+	}
+
+	for _, other := range []string{"task_history_default", "task_history", "tasks_2026_10"} {
+		if _, ok := historyPartitionMonth(other); ok {
+			t.Errorf("historyPartitionMonth(%q) = true, want false", other)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+// Pause stops claiming of a task type, or of every type for models.PauseScopeQueue
+// The pause and its audit entry are written in one transaction
+func (s *Store) Pause(ctx context.Context, scope string, reason *string) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	scope = strings.ToLower(scope)
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO queue_pauses (scope, reason, paused_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (scope) DO UPDATE
+		SET reason = EXCLUDED.reason
+	`, scope, reason)
+	if err != nil {
+		return err
+	}
+
+	if err := insertPauseEvent(ctx, tx, scope, models.PauseActionPaused, reason); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Resume lifts a pause and records it in the audit trail
+func (s *Store) Resume(ctx context.Context, scope string, reason *string) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	scope = strings.ToLower(scope)
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	result, err := tx.Exec(ctx, `DELETE FROM queue_pauses WHERE scope = $1`, scope)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotPaused
+	}
+
+	if err := insertPauseEvent(ctx, tx, scope, models.PauseActionResumed, reason); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// insertPauseEvent appends an entry to the pause audit trail
+func insertPauseEvent(ctx context.Context, tx pgx.Tx, scope string, action models.PauseAction, reason *string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO queue_pause_history (scope, action, reason, created_at)
+		VALUES ($1, $2, $3, NOW())
+	`, scope, action, reason)
+	return err
+}
+
+// ListPauses returns the active pauses, the whole-queue pause first
+func (s *Store) ListPauses(ctx context.Context) ([]models.QueuePause, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		SELECT scope, reason, paused_at
+		FROM queue_pauses
+		ORDER BY scope = $1 DESC, scope ASC
+	`
+
+	rows, err := s.pool.Query(ctx, query, models.PauseScopeQueue)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pauses := []models.QueuePause{}
+	for rows.Next() {
+		var p models.QueuePause
+		if err := rows.Scan(&p.Scope, &p.Reason, &p.PausedAt); err != nil {
+			return nil, err
+		}
+		pauses = append(pauses, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pauses, nil
+}
+
+// ListPauseHistory returns the most recent pause and resume actions, newest first
+func (s *Store) ListPauseHistory(ctx context.Context, limit int) ([]models.PauseEvent, error) {
+	ctx, cancel := s.longQuery(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, scope, action, reason, created_at
+		FROM queue_pause_history
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1
+	`
+
+	rows, err := s.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.PauseEvent{}
+	for rows.Next() {
+		var e models.PauseEvent
+		if err := rows.Scan(&e.ID, &e.Scope, &e.Action, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
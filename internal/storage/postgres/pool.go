@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PoolConfig holds connection pool options that cannot be expressed in the connection URI
+type PoolConfig struct {
+	LogQueries         bool          // Log every query at info level
+	SlowQueryThreshold time.Duration // Log queries taking at least this long at warn level (0 = disabled)
+
+	ConnectTimeout time.Duration // Keep retrying the first connection for this long (0 = a single attempt)
+}
+
+// Backoff between connection attempts at startup
+const (
+	connectRetryInitial = 500 * time.Millisecond
+	connectRetryMax     = 10 * time.Second
+)
+
+// NewPool creates a connection pool for connString with the query tracer from config
+// Waits for the database to accept a connection, retrying with backoff for up to config.ConnectTimeout,
+// so a binary started before its database or during a restart does not exit at once
+func NewPool(ctx context.Context, connString string, config PoolConfig) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.LogQueries || config.SlowQueryThreshold > 0 {
+		poolConfig.ConnConfig.Tracer = &queryTracer{
+			all:  config.LogQueries,
+			slow: config.SlowQueryThreshold,
+		}
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := waitForDatabase(ctx, pool, config.ConnectTimeout); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return pool, nil
+}
+
+// EnsureSchema creates schema if it does not exist yet, so migrations can run inside it
+// Connections select it through search_path (see config.Database.Schema)
+func EnsureSchema(ctx context.Context, pool *pgxpool.Pool, schema string) error {
+	if schema == "" {
+		return nil
+	}
+	_, err := pool.Exec(ctx, `CREATE SCHEMA IF NOT EXISTS `+pgx.Identifier{schema}.Sanitize())
+	return err
+}
+
+// waitForDatabase pings until the database answers or maxWait has passed
+func waitForDatabase(ctx context.Context, pool *pgxpool.Pool, maxWait time.Duration) error {
+	deadline := time.Now().Add(maxWait)
+	delay := connectRetryInitial
+
+	for attempt := 1; ; attempt++ {
+		err := pool.Ping(ctx)
+		if err == nil {
+			return nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("database not reachable after %d attempts: %w", attempt, err)
+		}
+
+		wait := min(delay, remaining)
+		slog.Warn("Database not reachable, retrying", "attempt", attempt, "retry_in", wait, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		delay = min(delay*2, connectRetryMax)
+	}
+}
@@ -1,22 +1,129 @@
 package postgres
 
 import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// jsonArg encodes v as JSON text for a jsonb parameter
+// Text stays valid under the simple protocol, where Go maps have no PostgreSQL type
+func jsonArg(v any) string {
+	data, _ := json.Marshal(v) // only called with string-keyed maps of plain values
+	return string(data)
+}
+
+// querier is implemented by both *prefixedPool and pgx.Tx
+// Writes that may run inside a caller's transaction take one instead of using s.pool
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 // Store implements the storage.Store interface using PostgreSQL
 type Store struct {
-	pool *pgxpool.Pool
+	pool       *prefixedPool
+	history    *prefixedPool // task_history; the same as pool unless Config.HistoryPool is set
+	jitterMode models.JitterMode
+	maxBackoff time.Duration
+
+	quarantineThreshold int
+	statsEstimateAbove  int64
+	transactionPooling  bool
+
+	shortQueryTimeout time.Duration
+	longQueryTimeout  time.Duration
+}
+
+// Config holds optional store behaviour settings
+type Config struct {
+	JitterMode models.JitterMode // Jitter applied to computed retry delays
+	MaxBackoff time.Duration     // Default cap for computed retry delays (tasks may override)
+
+	QuarantineThreshold int // Crashes (panics, timeouts, expired locks) that quarantine a task (0 = disabled)
+
+	StatsEstimateAbove int64 // Estimated rows in tasks above which GetStats samples instead of scanning (0 = always exact)
+
+	TransactionPooling bool // The pool goes through a transaction pooler such as PgBouncer, so no session state (LISTEN)
+
+	ShortQueryTimeout time.Duration // Bound for claims, status updates and single-task reads (default 5s)
+	LongQueryTimeout  time.Duration // Bound for stats, bulk inserts, sweeps and list exports (default 60s)
+
+	TablePrefix string // Prepended to every table, type and index name, e.g. "tq_" (see ValidateTablePrefix)
+
+	HistoryPool *pgxpool.Pool // Separate database holding task_history (nil = the main pool)
 }
 
 // NewStore creates a new PostgreSQL store
-func NewStore(pool *pgxpool.Pool) *Store {
-	return &Store{
-		pool: pool,
+func NewStore(pool *pgxpool.Pool, config Config) *Store {
+	if !config.JitterMode.IsValid() {
+		config.JitterMode = models.JitterProportional
+	}
+	if config.MaxBackoff == 0 {
+		config.MaxBackoff = 1 * time.Hour
+	}
+	if config.ShortQueryTimeout == 0 {
+		config.ShortQueryTimeout = 5 * time.Second
+	}
+	if config.LongQueryTimeout == 0 {
+		config.LongQueryTimeout = 60 * time.Second
+	}
+
+	prefix := &tablePrefix{prefix: config.TablePrefix}
+	store := &Store{
+		pool:       &prefixedPool{pool: pool, prefix: prefix},
+		jitterMode: config.JitterMode,
+		maxBackoff: config.MaxBackoff,
+
+		quarantineThreshold: config.QuarantineThreshold,
+		statsEstimateAbove:  config.StatsEstimateAbove,
+		transactionPooling:  config.TransactionPooling,
+
+		shortQueryTimeout: config.ShortQueryTimeout,
+		longQueryTimeout:  config.LongQueryTimeout,
+	}
+
+	store.history = store.pool
+	if config.HistoryPool != nil {
+		store.history = &prefixedPool{pool: config.HistoryPool, prefix: prefix}
+	}
+	return store
+}
+
+// separateHistory reports whether task_history lives in its own database,
+// so history cannot be written in the same transaction as its task
+func (s *Store) separateHistory() bool {
+	return s.history != s.pool
+}
+
+// shortQuery bounds a claim, status update or other single-row operation
+// The deadline cancels the running statement, so a runaway query cannot hold a pool connection
+func (s *Store) shortQuery(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, s.shortQueryTimeout)
+}
+
+// longQuery bounds stats, bulk writes, sweeps and list exports
+func (s *Store) longQuery(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, s.longQueryTimeout)
+}
+
+// Ping checks that the database, and the history database if separate, are reachable
+func (s *Store) Ping(ctx context.Context) error {
+	if err := s.pool.Ping(ctx); err != nil {
+		return s.unavailable(err)
+	}
+	if s.separateHistory() {
+		return s.unavailable(s.history.Ping(ctx))
 	}
+	return nil
 }
 
 // GetPool returns the underlying connection pool (for testing)
 func (s *Store) GetPool() *pgxpool.Pool {
-	return s.pool
+	return s.pool.pool
 }
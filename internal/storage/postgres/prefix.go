@@ -0,0 +1,189 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// prefixedNames matches every object the migrations create: tables, the task_status type,
+// the task_created notification channel, and names derived from them such as task_history_2026_10, tasks_id_seq and idx_tasks_claim
+// Queries and migrations are written with the plain names; a table prefix is applied by rewriting them
+var prefixedNames = regexp.MustCompile(`\b(?:tasks|task_history|task_status|concurrency_limits|rate_limits|circuit_breakers|queue_pauses|queue_pause_history|maintenance_mode|worker_settings|workers|outbox_checkpoints|alert_rules|api_keys|tenant_quotas|task_results|task_schemas|task_types|task_created|idx)(?:_\w*)?\b`)
+
+// validTablePrefix keeps the prefix usable unquoted in SQL
+var validTablePrefix = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// ValidateTablePrefix checks that prefix is empty or a lowercase SQL identifier
+func ValidateTablePrefix(prefix string) error {
+	if prefix != "" && !validTablePrefix.MatchString(prefix) {
+		return fmt.Errorf("table prefix %q must be lowercase letters, digits and underscores", prefix)
+	}
+	return nil
+}
+
+// tablePrefix rewrites queries to use prefixed object names
+// The rewritten form of every distinct query is cached, since the store runs a fixed set of them
+type tablePrefix struct {
+	prefix    string
+	rewritten sync.Map // query -> rewritten query
+}
+
+// rewrite returns sql with the prefix applied to every queue object name
+func (p *tablePrefix) rewrite(sql string) string {
+	if p.prefix == "" {
+		return sql
+	}
+	if cached, ok := p.rewritten.Load(sql); ok {
+		return cached.(string)
+	}
+
+	rewritten := prefixedNames.ReplaceAllString(sql, p.prefix+"$0")
+	p.rewritten.Store(sql, rewritten)
+	return rewritten
+}
+
+// name returns the prefixed form of a single object name
+func (p *tablePrefix) name(name string) string {
+	return p.prefix + name
+}
+
+// prefixedPool is the store's connection pool with table names rewritten for the configured prefix
+type prefixedPool struct {
+	pool   *pgxpool.Pool
+	prefix *tablePrefix
+}
+
+func (p *prefixedPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return p.pool.Exec(ctx, p.prefix.rewrite(sql), args...)
+}
+
+func (p *prefixedPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return p.pool.Query(ctx, p.prefix.rewrite(sql), args...)
+}
+
+func (p *prefixedPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return p.pool.QueryRow(ctx, p.prefix.rewrite(sql), args...)
+}
+
+func (p *prefixedPool) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return p.prefix.tx(tx), nil
+}
+
+func (p *prefixedPool) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	for _, q := range b.QueuedQueries {
+		q.SQL = p.prefix.rewrite(q.SQL)
+	}
+	return p.pool.SendBatch(ctx, b)
+}
+
+func (p *prefixedPool) Acquire(ctx context.Context) (*pgxpool.Conn, error) {
+	return p.pool.Acquire(ctx)
+}
+
+func (p *prefixedPool) Ping(ctx context.Context) error {
+	return p.pool.Ping(ctx)
+}
+
+func (p *prefixedPool) Reset() {
+	p.pool.Reset()
+}
+
+// tx wraps tx so its queries are rewritten too; without a prefix tx is returned as is
+func (p *tablePrefix) tx(tx pgx.Tx) pgx.Tx {
+	if p.prefix == "" {
+		return tx
+	}
+	return &prefixedTx{Tx: tx, prefix: p}
+}
+
+// prefixedTx is a transaction whose queries and COPY targets use prefixed names
+type prefixedTx struct {
+	pgx.Tx
+	prefix *tablePrefix
+}
+
+func (t *prefixedTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := t.Tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.prefix.tx(tx), nil
+}
+
+func (t *prefixedTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return t.Tx.Exec(ctx, t.prefix.rewrite(sql), args...)
+}
+
+func (t *prefixedTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return t.Tx.Query(ctx, t.prefix.rewrite(sql), args...)
+}
+
+func (t *prefixedTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return t.Tx.QueryRow(ctx, t.prefix.rewrite(sql), args...)
+}
+
+func (t *prefixedTx) CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, rows pgx.CopyFromSource) (int64, error) {
+	prefixed := append(pgx.Identifier{}, table...)
+	prefixed[len(prefixed)-1] = t.prefix.name(prefixed[len(prefixed)-1])
+	return t.Tx.CopyFrom(ctx, prefixed, columns, rows)
+}
+
+func (t *prefixedTx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	for _, q := range b.QueuedQueries {
+		q.SQL = t.prefix.rewrite(q.SQL)
+	}
+	return t.Tx.SendBatch(ctx, b)
+}
+
+// PrefixMigrations returns src with the table prefix applied to every migration
+func PrefixMigrations(src source.Driver, prefix string) source.Driver {
+	if prefix == "" {
+		return src
+	}
+	return &prefixedSource{Driver: src, prefix: &tablePrefix{prefix: prefix}}
+}
+
+// prefixedSource rewrites migrations read from the embedded source driver
+type prefixedSource struct {
+	source.Driver
+	prefix *tablePrefix
+}
+
+func (s *prefixedSource) ReadUp(version uint) (io.ReadCloser, string, error) {
+	r, identifier, err := s.Driver.ReadUp(version)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.rewrite(r, identifier)
+}
+
+func (s *prefixedSource) ReadDown(version uint) (io.ReadCloser, string, error) {
+	r, identifier, err := s.Driver.ReadDown(version)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.rewrite(r, identifier)
+}
+
+func (s *prefixedSource) rewrite(r io.ReadCloser, identifier string) (io.ReadCloser, string, error) {
+	defer func() { _ = r.Close() }()
+
+	migration, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", err
+	}
+	return io.NopCloser(strings.NewReader(s.prefix.rewrite(string(migration)))), identifier, nil
+}
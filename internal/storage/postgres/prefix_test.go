@@ -0,0 +1,69 @@
+package postgres
+
+import "testing"
+
+func TestTablePrefixRewrite(t *testing.T) {
+	p := &tablePrefix{prefix: "tq_"}
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{
+			`SELECT t.id FROM tasks t JOIN rate_limits r ON r.task_type = t.type WHERE t.rate_limit_key IS NOT NULL`,
+			`SELECT t.id FROM tq_tasks t JOIN tq_rate_limits r ON r.task_type = t.type WHERE t.rate_limit_key IS NOT NULL`,
+		},
+		{
+			`UPDATE tasks SET status = $1::task_status WHERE NOT EXISTS (SELECT 1 FROM queue_pauses p WHERE p.scope = tasks.type)`,
+			`UPDATE tq_tasks SET status = $1::tq_task_status WHERE NOT EXISTS (SELECT 1 FROM tq_queue_pauses p WHERE p.scope = tq_tasks.type)`,
+		},
+		{
+			`SELECT reltuples FROM pg_class WHERE oid = 'tasks'::regclass`,
+			`SELECT reltuples FROM pg_class WHERE oid = 'tq_tasks'::regclass`,
+		},
+		{
+			`CREATE TABLE "task_history_2026_10" PARTITION OF task_history`,
+			`CREATE TABLE "tq_task_history_2026_10" PARTITION OF tq_task_history`,
+		},
+		{
+			`CREATE INDEX idx_tasks_claim ON tasks(priority DESC); SELECT nextval('tasks_id_seq'), 'task_history_' || month`,
+			`CREATE INDEX tq_idx_tasks_claim ON tq_tasks(priority DESC); SELECT nextval('tq_tasks_id_seq'), 'tq_task_history_' || month`,
+		},
+		{
+			`SELECT pg_notify('task_created', $1)`,
+			`SELECT pg_notify('tq_task_created', $1)`,
+		},
+		{
+			// Columns sharing a table name's stem are left alone
+			`SELECT worker_id, task_id, max_running FROM workers`,
+			`SELECT worker_id, task_id, max_running FROM tq_workers`,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := p.rewrite(tt.sql); got != tt.want {
+			t.Errorf("rewrite(%q)\n got %q\nwant %q", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestTablePrefixRewrite_NoPrefix(t *testing.T) {
+	p := &tablePrefix{}
+	sql := `SELECT id FROM tasks`
+	if got := p.rewrite(sql); got != sql {
+		t.Errorf("rewrite(%q) = %q, want it unchanged", sql, got)
+	}
+}
+
+func TestValidateTablePrefix(t *testing.T) {
+	for _, prefix := range []string{"", "tq_", "queue2_"} {
+		if err := ValidateTablePrefix(prefix); err != nil {
+			t.Errorf("ValidateTablePrefix(%q) error = %v", prefix, err)
+		}
+	}
+	for _, prefix := range []string{"Tq_", "tq-", "1tq_", `tq"; DROP`} {
+		if err := ValidateTablePrefix(prefix); err == nil {
+			t.Errorf("ValidateTablePrefix(%q) expected an error", prefix)
+		}
+	}
+}
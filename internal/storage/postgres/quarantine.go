@@ -0,0 +1,199 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+// RecordCrash handles a task whose handler panicked
+// Quarantines the task once crash_count reaches the quarantine threshold,
+// otherwise it is retried like any other failure
+// Only applies if the task is still held by the given lock
+func (s *Store) RecordCrash(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	// Get current task state
+	task, err := s.GetTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+
+	crashCount := task.CrashCount + 1
+	if s.shouldQuarantine(crashCount) {
+		return s.quarantineTask(ctx, task, lock, crashCount, errorMessage)
+	}
+
+	if task.RetryCount >= task.MaxRetries {
+		return s.MarkTaskFailed(ctx, taskID, lock, fmt.Sprintf("max retries exceeded: %s", errorMessage))
+	}
+
+	retryCount := task.RetryCount + 1
+	backoffDuration := s.calculateBackoff(task, retryCount)
+
+	return s.requeueForRetry(ctx, task, lock, retryCount, task.TimeoutCount, crashCount, errorMessage, backoffDuration, models.EventRetryScheduled)
+}
+
+// shouldQuarantine reports whether a task with the given crash count must be quarantined
+func (s *Store) shouldQuarantine(crashCount int) bool {
+	return s.quarantineThreshold > 0 && crashCount >= s.quarantineThreshold
+}
+
+// quarantineTask moves a running task into quarantine, where it is never claimed
+// Only applies if the task is still held by the given lock
+func (s *Store) quarantineTask(ctx context.Context, task *models.Task, lock models.TaskLock, crashCount int, errorMessage string) error {
+	query := `
+		UPDATE tasks
+		SET 
+			status = $1,
+			crash_count = $2,
+			last_error = $3,
+			quarantined_at = NOW(),
+			locked_at = NULL,
+			lock_expires_at = NULL,
+			locked_by = NULL,
+			updated_at = NOW()
+		WHERE id = $4
+		  AND status = $5
+		  AND locked_by = $6
+		  AND lock_token = $7
+	`
+
+	result, err := s.pool.Exec(ctx, query,
+		models.TaskStatusQuarantined,
+		crashCount,
+		errorMessage,
+		task.ID,
+		models.TaskStatusRunning,
+		lock.WorkerID,
+		lock.Token,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return s.lockLostOrNotFound(ctx, task.ID)
+	}
+
+	slog.Warn("Task quarantined", "task_id", task.ID, "task_type", task.Type, "crash_count", crashCount)
+
+	// Best-effort history logging
+	message := fmt.Sprintf("quarantined after %d crashes: %s", crashCount, errorMessage)
+	history := models.TaskHistory{
+		TaskID:       task.ID,
+		Status:       models.TaskStatusQuarantined,
+		EventType:    models.EventTaskQuarantined,
+		RetryCount:   &task.RetryCount,
+		MaxRetries:   &task.MaxRetries,
+		ErrorMessage: &message,
+		WorkerID:     &lock.WorkerID,
+	}
+
+	if err := s.InsertHistory(ctx, history); err != nil {
+		slog.Error("Failed to insert quarantine history", "task_id", task.ID, "error", err)
+	}
+
+	return nil
+}
+
+// ListQuarantinedTasks returns quarantined tasks, most recently quarantined first
+func (s *Store) ListQuarantinedTasks(ctx context.Context, limit int) ([]*models.Task, error) {
+	ctx, cancel := s.longQuery(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE status = $1
+		ORDER BY quarantined_at DESC
+		LIMIT $2
+	`
+
+	rows, err := s.pool.Query(ctx, query, models.TaskStatusQuarantined, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []*models.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// ReleaseTask returns a quarantined task to the queue for immediate execution
+// The crash count is reset; the retry budget is left as it was
+// With a version, the task is only released while it is still at that version
+func (s *Store) ReleaseTask(ctx context.Context, taskID int64, version *int64) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE tasks
+		SET 
+			status = $1,
+			crash_count = 0,
+			quarantined_at = NULL,
+			next_run_at = NOW(),
+			updated_at = NOW()
+		WHERE id = $2
+		  AND status = $3
+		  AND ($4::bigint IS NULL OR version = $4)
+		RETURNING retry_count, max_retries, next_run_at
+	`
+
+	var (
+		retryCount int
+		maxRetries int
+		history    = models.TaskHistory{
+			TaskID:    taskID,
+			Status:    models.TaskStatusQueued,
+			EventType: models.EventTaskReleased,
+		}
+	)
+	err := s.pool.QueryRow(ctx, query, models.TaskStatusQueued, taskID, models.TaskStatusQuarantined, version).
+		Scan(&retryCount, &maxRetries, &history.NextRunAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			task, getErr := s.GetTask(ctx, taskID)
+			if getErr != nil {
+				return getErr
+			}
+			if version != nil && task.Version != *version {
+				return storage.ErrVersionConflict
+			}
+			return storage.ErrNotQuarantined
+		}
+		return err
+	}
+
+	// Best-effort history logging
+	history.RetryCount = &retryCount
+	history.MaxRetries = &maxRetries
+	if err := s.InsertHistory(ctx, history); err != nil {
+		slog.Error("Failed to insert release history", "task_id", taskID, "error", err)
+	}
+
+	// Wake up listening workers
+	s.notifyTaskCreated(ctx, taskID)
+
+	return nil
+}
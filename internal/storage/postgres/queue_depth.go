@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// GetQueueDepth returns the queued and claimable task counts of every task type with queued tasks
+// A task is claimable once its next_run_at has passed; its age is counted from then
+func (s *Store) GetQueueDepth(ctx context.Context) ([]models.QueueDepth, error) {
+	ctx, cancel := s.longQuery(ctx)
+	defer cancel()
+
+	now := time.Now()
+	rows, err := s.pool.Query(ctx, `
+		SELECT
+			type,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE next_run_at <= $2),
+			MIN(next_run_at) FILTER (WHERE next_run_at <= $2)
+		FROM tasks
+		WHERE status = $1
+		GROUP BY type
+		ORDER BY type
+	`, models.TaskStatusQueued, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	depths := []models.QueueDepth{}
+	for rows.Next() {
+		var d models.QueueDepth
+		if err := rows.Scan(&d.TaskType, &d.Queued, &d.Claimable, &d.OldestClaimableAt); err != nil {
+			return nil, err
+		}
+		if d.OldestClaimableAt != nil {
+			d.OldestAgeSeconds = max(0, now.Sub(*d.OldestClaimableAt).Seconds())
+		}
+		depths = append(depths, d)
+	}
+
+	return depths, rows.Err()
+}
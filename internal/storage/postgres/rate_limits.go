@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+)
+
+// ListRateLimits returns all configured per-type rate limits
+func (s *Store) ListRateLimits(ctx context.Context) ([]models.RateLimit, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		SELECT task_type, key_field, max_per_window, window_seconds, updated_at
+		FROM rate_limits
+		ORDER BY task_type ASC
+	`
+
+	rows, err := s.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	limits := []models.RateLimit{}
+	for rows.Next() {
+		var l models.RateLimit
+		if err := rows.Scan(&l.TaskType, &l.KeyField, &l.MaxPerWindow, &l.WindowSeconds, &l.UpdatedAt); err != nil {
+			return nil, err
+		}
+		limits = append(limits, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return limits, nil
+}
+
+// SetRateLimit creates or updates the rate limit for a task type
+// Only tasks created afterwards get a key derived from the new key field
+func (s *Store) SetRateLimit(ctx context.Context, taskType string, req models.SetRateLimitRequest) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO rate_limits (task_type, key_field, max_per_window, window_seconds, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (task_type) DO UPDATE
+		SET key_field = EXCLUDED.key_field,
+		    max_per_window = EXCLUDED.max_per_window,
+		    window_seconds = EXCLUDED.window_seconds,
+		    updated_at = NOW()
+	`
+
+	_, err := s.pool.Exec(ctx, query, strings.ToLower(taskType), req.KeyField, req.MaxPerWindow, req.WindowSeconds)
+	return err
+}
+
+// DeleteRateLimit removes the rate limit for a task type
+func (s *Store) DeleteRateLimit(ctx context.Context, taskType string) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	result, err := s.pool.Exec(ctx, `DELETE FROM rate_limits WHERE task_type = $1`, strings.ToLower(taskType))
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return storage.ErrRateLimitNotFound
+	}
+
+	return nil
+}
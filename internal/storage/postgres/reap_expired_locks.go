@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// lockExpiredError is recorded as last_error on tasks recovered by the reaper
+const lockExpiredError = "worker lock expired"
+
+// ReapExpiredLocks recovers running tasks whose lock has expired
+// Tasks with retries left are requeued immediately, the rest are marked failed
+// An expired lock counts as a crash, so tasks that keep killing their worker are quarantined
+// Each recovered task gets a worker_lock_expired (or task_quarantined) history event
+func (s *Store) ReapExpiredLocks(ctx context.Context) (int, error) {
+	ctx, cancel := s.longQuery(ctx)
+	defer cancel()
+
+	now := time.Now()
+
+	query := `
+		UPDATE tasks
+		SET 
+			status = CASE
+				WHEN $6 > 0 AND crash_count + 1 >= $6 THEN $7::task_status
+				WHEN retry_count < max_retries THEN $1::task_status
+				ELSE $2::task_status
+			END,
+			retry_count = CASE
+				WHEN $6 > 0 AND crash_count + 1 >= $6 THEN retry_count
+				WHEN retry_count < max_retries THEN retry_count + 1
+				ELSE retry_count
+			END,
+			crash_count = crash_count + 1,
+			quarantined_at = CASE WHEN $6 > 0 AND crash_count + 1 >= $6 THEN $4 END,
+			last_error = $3,
+			next_run_at = $4,
+			locked_at = NULL,
+			lock_expires_at = NULL,
+			locked_by = NULL,
+			updated_at = $4
+		WHERE id IN (
+			SELECT id
+			FROM tasks
+			WHERE status = $5
+			  AND lock_expires_at <= $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, status, retry_count, max_retries
+	`
+
+	rows, err := s.pool.Query(ctx, query,
+		models.TaskStatusQueued,
+		models.TaskStatusFailed,
+		lockExpiredError,
+		now,
+		models.TaskStatusRunning,
+		s.quarantineThreshold,
+		models.TaskStatusQuarantined,
+	)
+	if err != nil {
+		return 0, s.unavailable(err)
+	}
+	defer rows.Close()
+
+	var reaped []models.TaskHistory
+	for rows.Next() {
+		var (
+			h          models.TaskHistory
+			retryCount int
+			maxRetries int
+		)
+		if err := rows.Scan(&h.TaskID, &h.Status, &retryCount, &maxRetries); err != nil {
+			return 0, err
+		}
+		h.RetryCount = &retryCount
+		h.MaxRetries = &maxRetries
+		reaped = append(reaped, h)
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// Best-effort history logging
+	errorMessage := lockExpiredError
+	for _, h := range reaped {
+		h.EventType = models.EventWorkerLockExpired
+		h.ErrorMessage = &errorMessage
+		switch h.Status {
+		case models.TaskStatusQueued:
+			h.NextRunAt = &now
+		case models.TaskStatusQuarantined:
+			h.EventType = models.EventTaskQuarantined
+		}
+
+		if err := s.InsertHistory(ctx, h); err != nil {
+			slog.Error("Failed to insert lock expired history", "task_id", h.TaskID, "error", err)
+		}
+	}
+
+	return len(reaped), nil
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// RecordTimeout handles a task whose execution exceeded its timeout
+// Increments timeout_count and records a timeout_occurred event instead of a generic failure
+// If the task sets max_timeouts, timeouts use that budget and leave retry_count untouched;
+// otherwise they consume retries like any other error
+// Timeouts also count as crashes, so a task that keeps hanging its handler is quarantined
+// Only applies if the task is still held by the given lock
+func (s *Store) RecordTimeout(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	// Get current task state
+	task, err := s.GetTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+
+	timeoutCount := task.TimeoutCount + 1
+	crashCount := task.CrashCount + 1
+	if s.shouldQuarantine(crashCount) {
+		return s.quarantineTask(ctx, task, lock, crashCount, errorMessage)
+	}
+
+	retryCount := task.RetryCount
+	attempt := timeoutCount
+
+	var exhausted string
+	if task.MaxTimeouts != nil {
+		if task.TimeoutCount >= *task.MaxTimeouts {
+			exhausted = "max timeouts exceeded"
+		}
+	} else {
+		if task.RetryCount >= task.MaxRetries {
+			exhausted = "max retries exceeded"
+		}
+		retryCount++
+		attempt = retryCount
+	}
+
+	if exhausted != "" {
+		errorMessage = fmt.Sprintf("%s: %s", exhausted, errorMessage)
+
+		// Best-effort history logging of the timeout itself before the final failure
+		history := models.TaskHistory{
+			TaskID:       taskID,
+			Status:       models.TaskStatusFailed,
+			EventType:    models.EventTimeoutOccurred,
+			RetryCount:   &task.RetryCount,
+			MaxRetries:   &task.MaxRetries,
+			ErrorMessage: &errorMessage,
+			WorkerID:     &lock.WorkerID,
+		}
+		if err := s.InsertHistory(ctx, history); err != nil {
+			slog.Error("Failed to insert timeout history", "task_id", taskID, "error", err)
+		}
+
+		return s.MarkTaskFailed(ctx, taskID, lock, errorMessage)
+	}
+
+	backoffDuration := s.calculateBackoff(task, attempt)
+
+	return s.requeueForRetry(ctx, task, lock, retryCount, timeoutCount, crashCount, errorMessage, backoffDuration, models.EventTimeoutOccurred)
+}
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+// redactSet scrubs a task's personal data
+const redactSet = `
+	payload = $1::jsonb,
+	last_error = CASE WHEN last_error IS NULL THEN NULL ELSE $2 END,
+	rate_limit_key = NULL,
+	payload_signature = '',
+	redacted_at = NOW(),
+	updated_at = NOW()`
+
+// RedactTask irreversibly scrubs the payload, result, rate limit key and error messages of a finished task
+// History keeps its events with their error messages replaced, plus a task_redacted event
+// Redacting a task again only repeats the history scrub, in case it failed the first time
+// With a version, the task is only redacted while it is still at that version
+func (s *Store) RedactTask(ctx context.Context, taskID int64, version *int64) (*models.Task, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE tasks
+		SET ` + redactSet + `
+		WHERE id = $3
+		  AND status IN ($4, $5)
+		  AND redacted_at IS NULL
+		  AND ($6::bigint IS NULL OR version = $6)
+		RETURNING ` + taskColumns
+
+	task, err := scanTask(s.pool.QueryRow(ctx, query,
+		models.RedactedPayload,
+		models.RedactedMessage,
+		taskID,
+		models.TaskStatusSucceeded,
+		models.TaskStatusFailed,
+		version,
+	))
+	first := true
+	if errors.Is(err, pgx.ErrNoRows) {
+		task, err = s.GetTask(ctx, taskID)
+		if err == nil && version != nil && task.Version != *version {
+			return nil, storage.ErrVersionConflict
+		}
+		if err == nil && task.RedactedAt == nil {
+			return nil, storage.ErrTaskNotFinished
+		}
+		first = false
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.redactHistory(ctx, []*models.Task{task}, first); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// RedactTasks redacts every finished task matching req that is not redacted yet
+func (s *Store) RedactTasks(ctx context.Context, req models.RedactTasksRequest) (int, error) {
+	ctx, cancel := s.longQuery(ctx)
+	defer cancel()
+
+	metadata := req.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	query := `
+		UPDATE tasks
+		SET ` + redactSet + `
+		WHERE redacted_at IS NULL
+		  AND status IN ($3, $4)
+		  AND ($5 = '' OR tenant = $5)
+		  AND metadata @> $6::jsonb
+		RETURNING ` + taskColumns
+
+	rows, err := s.pool.Query(ctx, query,
+		models.RedactedPayload,
+		models.RedactedMessage,
+		models.TaskStatusSucceeded,
+		models.TaskStatusFailed,
+		req.Tenant,
+		jsonArg(metadata),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var tasks []*models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		tasks = append(tasks, task)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if err := s.redactHistory(ctx, tasks, true); err != nil {
+		return 0, err
+	}
+
+	return len(tasks), nil
+}
+
+// redactHistory drops the results and replaces the error messages in the history of redacted tasks,
+// recording a task_redacted event unless they had been redacted before
+// Unlike other history writes it is not best-effort: a failure is returned so the caller retries,
+// which is safe because redaction is idempotent
+func (s *Store) redactHistory(ctx context.Context, tasks []*models.Task, record bool) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(tasks))
+	history := make([]models.TaskHistory, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+		history[i] = models.TaskHistory{
+			TaskID:     task.ID,
+			Status:     task.Status,
+			EventType:  models.EventTaskRedacted,
+			RetryCount: &task.RetryCount,
+			MaxRetries: &task.MaxRetries,
+		}
+	}
+
+	if _, err := s.pool.Exec(ctx, `DELETE FROM task_results WHERE task_id = ANY($1)`, ids); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE task_history
+		SET error_message = $1
+		WHERE task_id = ANY($2)
+		  AND error_message IS NOT NULL
+		  AND error_message <> $1
+	`
+	if _, err := s.history.Exec(ctx, query, models.RedactedMessage, ids); err != nil {
+		return err
+	}
+
+	if !record {
+		return nil
+	}
+	return s.insertHistoryBatch(ctx, history)
+}
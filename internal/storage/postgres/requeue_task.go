@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// RequeueTask returns a running task to the queue without consuming a retry
+// Used when execution was interrupted by worker shutdown rather than a task failure
+// Only applies if the task is still held by the given lock
+func (s *Store) RequeueTask(ctx context.Context, taskID int64, lock models.TaskLock) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	now := time.Now()
+
+	query := `
+		UPDATE tasks
+		SET 
+			status = $1,
+			next_run_at = $2,
+			locked_at = NULL,
+			lock_expires_at = NULL,
+			locked_by = NULL,
+			updated_at = $2
+		WHERE id = $3
+		  AND status = $4
+		  AND locked_by = $5
+		  AND lock_token = $6
+	`
+
+	result, err := s.pool.Exec(ctx, query,
+		models.TaskStatusQueued,
+		now,
+		taskID,
+		models.TaskStatusRunning,
+		lock.WorkerID,
+		lock.Token,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return s.lockLostOrNotFound(ctx, taskID)
+	}
+
+	// Best-effort history logging
+	history := models.TaskHistory{
+		TaskID:    taskID,
+		Status:    models.TaskStatusQueued,
+		EventType: models.EventTaskQueued,
+		NextRunAt: &now,
+		WorkerID:  &lock.WorkerID,
+	}
+
+	if err := s.InsertHistory(ctx, history); err != nil {
+		slog.Error("Failed to insert requeue history", "task_id", taskID, "error", err)
+	}
+
+	return nil
+}
@@ -4,8 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"math"
-	"math/rand"
 	"time"
 
 	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
@@ -13,7 +11,12 @@ import (
 )
 
 // ScheduleRetry marks a task for retry with exponential backoff
-func (s *Store) ScheduleRetry(ctx context.Context, taskID int64, errorMessage string) error {
+// A positive retryAfter (suggested by the handler) is used instead of the computed backoff, up to the same cap
+// Only applies if the task is still held by the given lock
+func (s *Store) ScheduleRetry(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string, retryAfter time.Duration) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
 	// Get current task state
 	task, err := s.GetTask(ctx, taskID)
 	if err != nil {
@@ -22,12 +25,29 @@ func (s *Store) ScheduleRetry(ctx context.Context, taskID int64, errorMessage st
 
 	// Check if retries are exhausted
 	if task.RetryCount >= task.MaxRetries {
-		return s.MarkTaskFailed(ctx, taskID, fmt.Sprintf("max retries exceeded: %s", errorMessage))
+		return s.MarkTaskFailed(ctx, taskID, lock, fmt.Sprintf("max retries exceeded: %s", errorMessage))
 	}
 
-	// Calculate exponential backoff with jitter
+	// Calculate exponential backoff with jitter, unless the handler asked for a specific delay
 	retryCount := task.RetryCount + 1
-	backoffDuration := calculateBackoff(task.BackoffSeconds, retryCount)
+	backoffDuration := storage.RetryDelay(task, retryCount, retryAfter, s.jitterMode, s.maxBackoff)
+
+	return s.requeueForRetry(ctx, task, lock, retryCount, task.TimeoutCount, task.CrashCount, errorMessage, backoffDuration, models.EventRetryScheduled)
+}
+
+// requeueForRetry puts a failed task back in the queue after the given delay
+// Shared by error retries and timeout retries, which differ in the counters they bump and the event they record
+func (s *Store) requeueForRetry(
+	ctx context.Context,
+	task *models.Task,
+	lock models.TaskLock,
+	retryCount int,
+	timeoutCount int,
+	crashCount int,
+	errorMessage string,
+	backoffDuration time.Duration,
+	eventType models.EventType,
+) error {
 	nextRunAt := time.Now().Add(backoffDuration)
 
 	query := `
@@ -35,20 +55,33 @@ func (s *Store) ScheduleRetry(ctx context.Context, taskID int64, errorMessage st
 		SET 
 			status = $1,
 			retry_count = $2,
-			last_error = $3,
-			next_run_at = $4,
+			timeout_count = $3,
+			crash_count = $4,
+			last_error = $5,
+			next_run_at = $6,
+			last_backoff_seconds = $11,
 			locked_at = NULL,
 			lock_expires_at = NULL,
+			locked_by = NULL,
 			updated_at = NOW()
-		WHERE id = $5
+		WHERE id = $7
+		  AND status = $8
+		  AND locked_by = $9
+		  AND lock_token = $10
 	`
 
 	result, err := s.pool.Exec(ctx, query,
 		models.TaskStatusQueued,
 		retryCount,
+		timeoutCount,
+		crashCount,
 		errorMessage,
 		nextRunAt,
-		taskID,
+		task.ID,
+		models.TaskStatusRunning,
+		lock.WorkerID,
+		lock.Token,
+		int(backoffDuration/time.Second),
 	)
 
 	if err != nil {
@@ -56,56 +89,31 @@ func (s *Store) ScheduleRetry(ctx context.Context, taskID int64, errorMessage st
 	}
 
 	if result.RowsAffected() == 0 {
-		return storage.ErrTaskNotFound
+		return s.lockLostOrNotFound(ctx, task.ID)
 	}
 
 	// Best-effort history logging
 	history := models.TaskHistory{
-		TaskID:         taskID,
+		TaskID:         task.ID,
 		Status:         models.TaskStatusQueued,
-		EventType:      models.EventRetryScheduled,
+		EventType:      eventType,
 		RetryCount:     &retryCount,
 		MaxRetries:     &task.MaxRetries,
 		BackoffSeconds: &task.BackoffSeconds,
 		NextRunAt:      &nextRunAt,
 		ErrorMessage:   &errorMessage,
+		WorkerID:       &lock.WorkerID,
 	}
 
 	if err := s.InsertHistory(ctx, history); err != nil {
-		slog.Error("Failed to insert retry history", "task_id", taskID, "error", err)
+		slog.Error("Failed to insert retry history", "task_id", task.ID, "error", err)
 	}
 
 	return nil
 }
 
-// calculateBackoff computes exponential backoff with jitter
-// Formula: backoff_seconds * (2 ^ retry_count) with random jitter
-func calculateBackoff(baseSeconds int, retryCount int) time.Duration {
-	// Exponential backoff: base * 2^(retry_count-1)
-	// Cap the exponent to prevent overflow (2^20 = ~1M seconds = 11 days)
-	exponent := retryCount - 1
-	if exponent > 20 {
-		exponent = 20
-	}
-
-	exponential := float64(baseSeconds) * math.Pow(2, float64(exponent))
-
-	// Hard cap at 1 hour to prevent runaway delays
-	if exponential > 3600 {
-		exponential = 3600
-	}
-
-	// Add proper uniform jitter (±25%)
-	// Using math/rand is sufficient for backoff jitter (crypto/rand is overkill)
-	jitterPercent := (rand.Float64() * 0.5) - 0.25 // Range: -0.25 to +0.25
-	jitter := exponential * jitterPercent
-
-	backoff := exponential + jitter
-
-	// Ensure minimum backoff of 1 second
-	if backoff < 1 {
-		backoff = 1
-	}
-
-	return time.Duration(backoff) * time.Second
+// calculateBackoff computes the delay before a retry attempt using the task's retry strategy
+// with the configured jitter applied, capped at the task's max_backoff_seconds (or the store default)
+func (s *Store) calculateBackoff(task *models.Task, retryCount int) time.Duration {
+	return storage.CalculateBackoff(task, retryCount, s.jitterMode, s.maxBackoff)
 }
@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// GetRetryDistribution returns how many tasks of each type finished within r after each retry count,
+// split by final status, ordered by type and retry count
+// Reads idx_tasks_finished; a finished task's updated_at is when its last attempt ended
+func (s *Store) GetRetryDistribution(ctx context.Context, r models.TimeRange) ([]models.RetryDistribution, error) {
+	ctx, cancel := s.longQuery(ctx)
+	defer cancel()
+
+	finished, args := rangeFilter("updated_at", r, nil)
+	if finished != "" {
+		finished = "AND " + finished
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT
+			type,
+			retry_count,
+			COUNT(*) FILTER (WHERE status = 'succeeded'),
+			COUNT(*) FILTER (WHERE status = 'failed')
+		FROM tasks
+		WHERE status IN ('succeeded', 'failed')
+		  `+finished+`
+		GROUP BY type, retry_count
+		ORDER BY type, retry_count
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	distributions := []models.RetryDistribution{}
+	for rows.Next() {
+		var taskType string
+		var b models.RetryBucket
+		if err := rows.Scan(&taskType, &b.Retries, &b.Succeeded, &b.Failed); err != nil {
+			return nil, err
+		}
+
+		if n := len(distributions); n == 0 || distributions[n-1].TaskType != taskType {
+			distributions = append(distributions, models.RetryDistribution{TaskType: taskType})
+		}
+		last := &distributions[len(distributions)-1]
+		last.Buckets = append(last.Buckets, b)
+	}
+
+	return distributions, rows.Err()
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// taskColumns is the column list matching scanTask, shared by SELECT and RETURNING clauses
+const taskColumns = `id, public_id, name, type, payload, status, priority, tenant, required_labels, metadata,
+		          retry_count, max_retries, last_error, 
+		          next_run_at, backoff_seconds, retry_strategy, retry_schedule, max_backoff_seconds, last_backoff_seconds,
+		          timeout_seconds, timeout_count, max_timeouts, locked_at, lock_expires_at, locked_by, lock_token,
+		          rate_limit_key, last_started_at, crash_count, quarantined_at, redacted_at, payload_signature, version,
+		          created_at, updated_at`
+
+// scanTask scans a row selected with taskColumns into a Task
+func scanTask(row pgx.Row) (*models.Task, error) {
+	var task models.Task
+	err := row.Scan(
+		&task.ID,
+		&task.PublicID,
+		&task.Name,
+		&task.Type,
+		&task.Payload,
+		&task.Status,
+		&task.Priority,
+		&task.Tenant,
+		&task.RequiredLabels,
+		&task.Metadata,
+		&task.RetryCount,
+		&task.MaxRetries,
+		&task.LastError,
+		&task.NextRunAt,
+		&task.BackoffSeconds,
+		&task.RetryStrategy,
+		&task.RetrySchedule,
+		&task.MaxBackoffSeconds,
+		&task.LastBackoffSeconds,
+		&task.TimeoutSeconds,
+		&task.TimeoutCount,
+		&task.MaxTimeouts,
+		&task.LockedAt,
+		&task.LockExpiresAt,
+		&task.LockedBy,
+		&task.LockToken,
+		&task.RateLimitKey,
+		&task.LastStartedAt,
+		&task.CrashCount,
+		&task.QuarantinedAt,
+		&task.RedactedAt,
+		&task.PayloadSignature,
+		&task.Version,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
@@ -6,8 +6,32 @@ import (
 	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
 )
 
+// statsSampleRows is roughly how many rows the estimated stats sample
+const statsSampleRows = 10000
+
 // GetStats retrieves system statistics for dashboard
+// Tables estimated to hold more than the configured threshold get estimated stats instead of a full scan
 func (s *Store) GetStats(ctx context.Context) (*models.TaskStatsResponse, error) {
+	ctx, cancel := s.longQuery(ctx)
+	defer cancel()
+
+	if s.statsEstimateAbove > 0 {
+		var rows float64
+		if err := s.pool.QueryRow(ctx, `SELECT reltuples FROM pg_class WHERE oid = 'tasks'::regclass`).Scan(&rows); err != nil {
+			return nil, err
+		}
+
+		// reltuples is -1 until the table has been vacuumed or analyzed
+		if rows > float64(s.statsEstimateAbove) {
+			return s.estimateStats(ctx, rows)
+		}
+	}
+
+	return s.exactStats(ctx, "")
+}
+
+// exactStats counts the tasks matching where, a condition on tasks or empty for all
+func (s *Store) exactStats(ctx context.Context, where string, args ...any) (*models.TaskStatsResponse, error) {
 	query := `
 		SELECT 
 			COUNT(*) as total_tasks,
@@ -15,18 +39,20 @@ func (s *Store) GetStats(ctx context.Context) (*models.TaskStatsResponse, error)
 			COUNT(*) FILTER (WHERE status = 'running') as running_tasks,
 			COUNT(*) FILTER (WHERE status = 'succeeded') as succeeded_tasks,
 			COUNT(*) FILTER (WHERE status = 'failed') as failed_tasks,
+			COUNT(*) FILTER (WHERE status = 'quarantined') as quarantined_tasks,
 			COALESCE(AVG(retry_count), 0) as avg_retry_count,
 			COUNT(*) FILTER (WHERE retry_count > 0) as tasks_with_retries
 		FROM tasks
-	`
+	` + whereClause(where)
 
 	var stats models.TaskStatsResponse
-	err := s.pool.QueryRow(ctx, query).Scan(
+	err := s.pool.QueryRow(ctx, query, args...).Scan(
 		&stats.TotalTasks,
 		&stats.QueuedTasks,
 		&stats.RunningTasks,
 		&stats.SucceededTasks,
 		&stats.FailedTasks,
+		&stats.QuarantinedTasks,
 		&stats.AvgRetryCount,
 		&stats.TasksWithRetries,
 	)
@@ -37,3 +63,92 @@ func (s *Store) GetStats(ctx context.Context) (*models.TaskStatsResponse, error)
 
 	return &stats, nil
 }
+
+// estimateStats derives stats for a table of about rows rows without scanning it
+// Queued and running tasks are few and indexed, so they are counted exactly;
+// finished tasks and retry figures are scaled up from a block sample
+func (s *Store) estimateStats(ctx context.Context, rows float64) (*models.TaskStatsResponse, error) {
+	stats := models.TaskStatsResponse{Estimated: true}
+
+	err := s.pool.QueryRow(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'queued'),
+			COUNT(*) FILTER (WHERE status = 'running')
+		FROM tasks
+		WHERE status IN ('queued', 'running')
+	`).Scan(&stats.QueuedTasks, &stats.RunningTasks)
+	if err != nil {
+		return nil, err
+	}
+
+	percent := min(100, statsSampleRows/rows*100)
+
+	var sampled, succeeded, failed, quarantined, withRetries float64
+	err = s.pool.QueryRow(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'succeeded'),
+			COUNT(*) FILTER (WHERE status = 'failed'),
+			COUNT(*) FILTER (WHERE status = 'quarantined'),
+			COALESCE(AVG(retry_count), 0),
+			COUNT(*) FILTER (WHERE retry_count > 0)
+		FROM tasks TABLESAMPLE SYSTEM ($1)
+	`, percent).Scan(&sampled, &succeeded, &failed, &quarantined, &stats.AvgRetryCount, &withRetries)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.TotalTasks = int64(rows)
+	if sampled > 0 {
+		scale := rows / sampled
+		stats.SucceededTasks = int64(succeeded * scale)
+		stats.FailedTasks = int64(failed * scale)
+		stats.QuarantinedTasks = int64(quarantined * scale)
+		stats.TasksWithRetries = int64(withRetries * scale)
+	}
+
+	return &stats, nil
+}
+
+// GetTypeStats returns task counts, failure rate and average retries per task type, ordered by type
+func (s *Store) GetTypeStats(ctx context.Context) ([]models.TypeStats, error) {
+	ctx, cancel := s.longQuery(ctx)
+	defer cancel()
+
+	return s.typeStats(ctx, "")
+}
+
+// typeStats returns per-type stats of the tasks matching where, a condition on tasks or empty for all
+func (s *Store) typeStats(ctx context.Context, where string, args ...any) ([]models.TypeStats, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT
+			type,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'queued'),
+			COUNT(*) FILTER (WHERE status = 'running'),
+			COUNT(*) FILTER (WHERE status = 'succeeded'),
+			COUNT(*) FILTER (WHERE status = 'failed'),
+			COUNT(*) FILTER (WHERE status = 'quarantined'),
+			COALESCE(AVG(retry_count), 0)
+		FROM tasks
+	`+whereClause(where)+`
+		GROUP BY type
+		ORDER BY type
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []models.TypeStats{}
+	for rows.Next() {
+		var t models.TypeStats
+		if err := rows.Scan(&t.TaskType, &t.TotalTasks, &t.Queued, &t.Running, &t.Succeeded, &t.Failed, &t.Quarantined, &t.AvgRetryCount); err != nil {
+			return nil, err
+		}
+		t.FailureRate = models.FailureRate(t.Succeeded, t.Failed)
+		stats = append(stats, t)
+	}
+
+	return stats, rows.Err()
+}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// GetRangeStats counts the tasks created within r
+// Reads idx_tasks_created_at, so a narrow range stays cheap on a large table
+func (s *Store) GetRangeStats(ctx context.Context, r models.TimeRange) (*models.TaskStatsResponse, error) {
+	ctx, cancel := s.longQuery(ctx)
+	defer cancel()
+
+	where, args := rangeFilter("created_at", r, nil)
+	return s.exactStats(ctx, where, args...)
+}
+
+// GetRangeTypeStats returns per-type stats of the tasks created within r, ordered by type
+func (s *Store) GetRangeTypeStats(ctx context.Context, r models.TimeRange) ([]models.TypeStats, error) {
+	ctx, cancel := s.longQuery(ctx)
+	defer cancel()
+
+	where, args := rangeFilter("created_at", r, nil)
+	return s.typeStats(ctx, where, args...)
+}
+
+// rangeFilter returns a condition bounding column to r, appending its arguments to args
+// Open bounds add no condition, so the planner sees plain range predicates it can match to an index
+func rangeFilter(column string, r models.TimeRange, args []any) (string, []any) {
+	var conditions []string
+	if !r.From.IsZero() {
+		args = append(args, r.From)
+		conditions = append(conditions, column+" >= $"+strconv.Itoa(len(args)))
+	}
+	if !r.To.IsZero() {
+		args = append(args, r.To)
+		conditions = append(conditions, column+" < $"+strconv.Itoa(len(args)))
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// whereClause renders a WHERE clause for condition, nothing when it is empty
+func whereClause(condition string) string {
+	if condition == "" {
+		return ""
+	}
+	return "WHERE " + condition
+}
@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/amitbasuri/taskqueue-runner-go/db"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage/storagetest"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+)
+
+// TestStoreConformance runs the storage conformance suite against TEST_DATABASE_URL
+// The database is migrated and every table is truncated before each test
+func TestStoreConformance(t *testing.T) {
+	pool := testPool(t)
+
+	storagetest.Run(t, func(t *testing.T) storage.Store {
+		resetDatabase(t, pool)
+		return NewStore(pool, Config{QuarantineThreshold: 3})
+	})
+}
+
+// TestCreateTaskTx checks that tasks created in a transaction follow its outcome
+func TestCreateTaskTx(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+	resetDatabase(t, pool)
+	store := NewStore(pool, Config{})
+
+	for _, commit := range []bool{false, true} {
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			t.Fatalf("Begin() error = %v", err)
+		}
+
+		task, err := store.CreateTaskTx(ctx, tx, models.CreateTaskRequest{Name: "welcome", Type: "send_email"})
+		if err != nil {
+			t.Fatalf("CreateTaskTx() error = %v", err)
+		}
+
+		if commit {
+			err = tx.Commit(ctx)
+		} else {
+			err = tx.Rollback(ctx)
+		}
+		if err != nil {
+			t.Fatalf("ending transaction: %v", err)
+		}
+
+		_, err = store.GetTask(ctx, task.ID)
+		if commit && err != nil {
+			t.Errorf("GetTask() after commit error = %v", err)
+		}
+		if !commit && !errors.Is(err, storage.ErrTaskNotFound) {
+			t.Errorf("GetTask() after rollback error = %v, want ErrTaskNotFound", err)
+		}
+
+		history, err := store.GetTaskHistory(ctx, task.ID, task.CreatedAt)
+		if err != nil {
+			t.Fatalf("GetTaskHistory() error = %v", err)
+		}
+		want := 0
+		if commit {
+			want = 1
+		}
+		if len(history) != want {
+			t.Errorf("history entries = %d, want %d (commit=%v)", len(history), want, commit)
+		}
+	}
+}
+
+// testPool connects to TEST_DATABASE_URL and migrates it, skipping the test if it is not set
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	d, err := iofs.New(db.Migrations, "migrations")
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	m, err := migrate.NewWithSourceInstance("iofs", d, "pgx5://"+strings.TrimPrefix(strings.TrimPrefix(url, "postgres://"), "postgresql://"))
+	if err != nil {
+		t.Fatalf("Failed to create migrate instance: %v", err)
+	}
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	pool, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("Failed to create database pool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	return pool
+}
+
+// resetDatabase empties every table
+func resetDatabase(t *testing.T, pool *pgxpool.Pool) {
+	t.Helper()
+
+	_, err := pool.Exec(context.Background(), `
+		TRUNCATE tasks, task_history, task_results, concurrency_limits, rate_limits, circuit_breakers,
+			worker_settings, workers, task_types, task_schemas, queue_pauses, queue_pause_history,
+			alert_rules, api_keys, tenant_quotas, outbox_checkpoints RESTART IDENTITY;
+		UPDATE maintenance_mode SET enabled = FALSE, stop_claims = FALSE, reason = NULL;
+	`)
+	if err != nil {
+		t.Fatalf("Failed to reset database: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// GetSuccessRates returns per-type success rates of the tasks that finished within r
+// Reads idx_tasks_finished rather than task_history, which carries no task type
+// and may live in a separate database
+func (s *Store) GetSuccessRates(ctx context.Context, r models.TimeRange) ([]models.SuccessRate, error) {
+	ctx, cancel := s.longQuery(ctx)
+	defer cancel()
+
+	finished, args := rangeFilter("updated_at", r, nil)
+	if finished != "" {
+		finished = "AND " + finished
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT
+			type,
+			COUNT(*) FILTER (WHERE status = 'succeeded'),
+			COUNT(*) FILTER (WHERE status = 'failed')
+		FROM tasks
+		WHERE status IN ('succeeded', 'failed')
+		  `+finished+`
+		GROUP BY type
+		ORDER BY type
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []models.SuccessRate{}
+	for rows.Next() {
+		var rate models.SuccessRate
+		if err := rows.Scan(&rate.TaskType, &rate.Succeeded, &rate.Failed); err != nil {
+			return nil, err
+		}
+		rate.SuccessRate = 1 - models.FailureRate(rate.Succeeded, rate.Failed)
+		rates = append(rates, rate)
+	}
+
+	return rates, rows.Err()
+}
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+// SaveTaskResult stores the result of a task, replacing an earlier one
+// Only applies if the task is still held by the given lock
+func (s *Store) SaveTaskResult(ctx context.Context, taskID int64, lock models.TaskLock, result json.RawMessage) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO task_results (task_id, result, created_at)
+		SELECT id, $2::jsonb, NOW()
+		FROM tasks
+		WHERE id = $1
+		  AND status = $3
+		  AND locked_by = $4
+		  AND lock_token = $5
+		ON CONFLICT (task_id) DO UPDATE
+		SET result = EXCLUDED.result,
+		    created_at = EXCLUDED.created_at
+	`
+
+	tag, err := s.pool.Exec(ctx, query,
+		taskID,
+		string(result),
+		models.TaskStatusRunning,
+		lock.WorkerID,
+		lock.Token,
+	)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return s.lockLostOrNotFound(ctx, taskID)
+	}
+
+	return nil
+}
+
+// GetTaskResult returns the result of a task, storage.ErrTaskResultNotFound if it has none
+func (s *Store) GetTaskResult(ctx context.Context, taskID int64) (*models.TaskResult, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	var result models.TaskResult
+	err := s.pool.QueryRow(ctx,
+		`SELECT result, created_at FROM task_results WHERE task_id = $1`,
+		taskID,
+	).Scan(&result.Result, &result.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrTaskResultNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+)
+
+// ListTaskSchemas returns the payload schema of every task type that has one
+func (s *Store) ListTaskSchemas(ctx context.Context) ([]models.TaskSchema, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		SELECT task_type, schema, updated_at
+		FROM task_schemas
+		ORDER BY task_type ASC
+	`
+
+	rows, err := s.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schemas := []models.TaskSchema{}
+	for rows.Next() {
+		var ts models.TaskSchema
+		if err := rows.Scan(&ts.TaskType, &ts.Schema, &ts.UpdatedAt); err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, ts)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return schemas, nil
+}
+
+// SetTaskSchema creates or replaces the payload schema of a task type
+// Only tasks created afterwards are checked against it
+func (s *Store) SetTaskSchema(ctx context.Context, taskType string, schema json.RawMessage) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO task_schemas (task_type, schema, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (task_type) DO UPDATE
+		SET schema = EXCLUDED.schema,
+		    updated_at = NOW()
+	`
+
+	_, err := s.pool.Exec(ctx, query, strings.ToLower(taskType), schema)
+	return err
+}
+
+// DeleteTaskSchema removes the payload schema of a task type
+func (s *Store) DeleteTaskSchema(ctx context.Context, taskType string) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	result, err := s.pool.Exec(ctx, `DELETE FROM task_schemas WHERE task_type = $1`, strings.ToLower(taskType))
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return storage.ErrTaskSchemaNotFound
+	}
+
+	return nil
+}
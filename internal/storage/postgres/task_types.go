@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+)
+
+// ListTaskTypes returns every type handled by a worker seen within the retention period or registered by an operator
+func (s *Store) ListTaskTypes(ctx context.Context) ([]models.TaskTypeInfo, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		SELECT task_type, SUM(workers)::int, MAX(last_seen), BOOL_OR(registered)
+		FROM (
+			SELECT UNNEST(handler_types) AS task_type, 1 AS workers, last_seen, FALSE AS registered
+			FROM workers
+			WHERE last_seen >= $1
+			UNION ALL
+			SELECT task_type, 0, NULL, TRUE
+			FROM task_types
+		) known
+		GROUP BY task_type
+		ORDER BY task_type ASC
+	`
+
+	rows, err := s.pool.Query(ctx, query, time.Now().Add(-workerRetention))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types := []models.TaskTypeInfo{}
+	for rows.Next() {
+		var t models.TaskTypeInfo
+		if err := rows.Scan(&t.TaskType, &t.Workers, &t.LastSeen, &t.Registered); err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return types, nil
+}
+
+// RegisterTaskType makes a task type known whether or not a worker reports it
+func (s *Store) RegisterTaskType(ctx context.Context, taskType string) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO task_types (task_type, created_at)
+		VALUES ($1, NOW())
+		ON CONFLICT (task_type) DO NOTHING
+	`
+
+	_, err := s.pool.Exec(ctx, query, strings.ToLower(taskType))
+	return err
+}
+
+// DeregisterTaskType removes a task type registered by an operator
+func (s *Store) DeregisterTaskType(ctx context.Context, taskType string) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	result, err := s.pool.Exec(ctx, `DELETE FROM task_types WHERE task_type = $1`, strings.ToLower(taskType))
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return storage.ErrTaskTypeNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+// ListTenantQuotas returns every quota with the tenant's current usage, ordered by tenant
+// The default quota reports no usage of its own
+func (s *Store) ListTenantQuotas(ctx context.Context) ([]models.TenantQuota, error) {
+	ctx, cancel := s.longQuery(ctx)
+	defer cancel()
+
+	query := `
+		SELECT q.tenant, q.max_queued, q.max_running,
+		       COUNT(t.id) FILTER (WHERE t.status = $1),
+		       COUNT(t.id) FILTER (WHERE t.status = $2),
+		       q.updated_at
+		FROM tenant_quotas q
+		LEFT JOIN tasks t ON t.tenant = q.tenant AND t.status IN ($1, $2)
+		GROUP BY q.tenant, q.max_queued, q.max_running, q.updated_at
+		ORDER BY q.tenant ASC
+	`
+
+	rows, err := s.pool.Query(ctx, query, models.TaskStatusQueued, models.TaskStatusRunning)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	quotas := []models.TenantQuota{}
+	for rows.Next() {
+		var q models.TenantQuota
+		if err := rows.Scan(&q.Tenant, &q.MaxQueued, &q.MaxRunning, &q.Queued, &q.Running, &q.UpdatedAt); err != nil {
+			return nil, err
+		}
+		quotas = append(quotas, q)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return quotas, nil
+}
+
+// SetTenantQuota creates or replaces the quota of a tenant, models.DefaultTenantQuota for the default
+// Lowering a quota below current usage rejects new tasks and holds claims until usage drops
+func (s *Store) SetTenantQuota(ctx context.Context, tenant string, req models.SetTenantQuotaRequest) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO tenant_quotas (tenant, max_queued, max_running, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (tenant) DO UPDATE
+		SET max_queued = EXCLUDED.max_queued,
+		    max_running = EXCLUDED.max_running,
+		    updated_at = NOW()
+	`
+
+	_, err := s.pool.Exec(ctx, query, tenant, req.MaxQueued, req.MaxRunning)
+	return err
+}
+
+// DeleteTenantQuota removes the quota of a tenant
+func (s *Store) DeleteTenantQuota(ctx context.Context, tenant string) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	result, err := s.pool.Exec(ctx, `DELETE FROM tenant_quotas WHERE tenant = $1`, tenant)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return storage.ErrTenantQuotaNotFound
+	}
+
+	return nil
+}
+
+// checkTenantQuotas fails with *storage.QuotaExceededError when rows would take a tenant past its max_queued
+// Each tenant is locked for the rest of the transaction, so concurrent creates cannot overshoot together;
+// without a transaction the check is best effort
+func checkTenantQuotas(ctx context.Context, q querier, rows []taskRow) error {
+	added := map[string]int{}
+	for _, row := range rows {
+		if row.tenant != "" {
+			added[row.tenant]++
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	// Lock in a fixed order so batches spanning several tenants cannot deadlock
+	tenants := make([]string, 0, len(added))
+	for tenant := range added {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+
+	for _, tenant := range tenants {
+		if _, err := q.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('tenant_quota:' || $1))`, tenant); err != nil {
+			return err
+		}
+
+		var maxQueued *int
+		err := q.QueryRow(ctx, `
+			SELECT COALESCE(
+				(SELECT max_queued FROM tenant_quotas WHERE tenant = $1),
+				(SELECT max_queued FROM tenant_quotas WHERE tenant = $2)
+			)`, tenant, models.DefaultTenantQuota).Scan(&maxQueued)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		if maxQueued == nil {
+			continue
+		}
+
+		// Counting stops at the quota, so a tenant far over it costs no more than one at it
+		var queued int64
+		err = q.QueryRow(ctx, `
+			SELECT COUNT(*) FROM (
+				SELECT 1 FROM tasks WHERE tenant = $1 AND status = $2 LIMIT $3
+			) queued`, tenant, models.TaskStatusQueued, *maxQueued+1).Scan(&queued)
+		if err != nil {
+			return err
+		}
+
+		if queued+int64(added[tenant]) > int64(*maxQueued) {
+			return &storage.QuotaExceededError{Tenant: tenant, MaxQueued: *maxQueued, Queued: queued}
+		}
+	}
+	return nil
+}
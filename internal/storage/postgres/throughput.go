@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// truncUnits maps bucket widths to date_trunc units
+var truncUnits = map[models.StatsBucket]string{
+	models.StatsBucketMinute: "minute",
+	models.StatsBucketHour:   "hour",
+	models.StatsBucketDay:    "day",
+}
+
+// GetThroughput returns created, succeeded and failed task counts per bucket within r, oldest first
+// An open r.To means now; creations are read through idx_tasks_created_at and completions through idx_tasks_finished
+func (s *Store) GetThroughput(ctx context.Context, bucket models.StatsBucket, r models.TimeRange) ([]models.ThroughputBucket, error) {
+	ctx, cancel := s.longQuery(ctx)
+	defer cancel()
+
+	to := r.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		WITH buckets AS (
+			SELECT generate_series(date_trunc($1, $2::timestamp), date_trunc($1, $3::timestamp), ('1 ' || $1)::interval) AS start
+		),
+		created AS (
+			SELECT date_trunc($1, created_at) AS start, COUNT(*) AS n
+			FROM tasks
+			WHERE created_at >= $2 AND created_at < $3
+			GROUP BY 1
+		),
+		finished AS (
+			SELECT date_trunc($1, updated_at) AS start,
+			       COUNT(*) FILTER (WHERE status = 'succeeded') AS succeeded,
+			       COUNT(*) FILTER (WHERE status = 'failed') AS failed
+			FROM tasks
+			WHERE status IN ('succeeded', 'failed')
+			  AND updated_at >= $2 AND updated_at < $3
+			GROUP BY 1
+		)
+		SELECT b.start, COALESCE(c.n, 0), COALESCE(f.succeeded, 0), COALESCE(f.failed, 0)
+		FROM buckets b
+		LEFT JOIN created c ON c.start = b.start
+		LEFT JOIN finished f ON f.start = b.start
+		ORDER BY b.start
+	`, truncUnits[bucket], r.From, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := []models.ThroughputBucket{}
+	for rows.Next() {
+		var b models.ThroughputBucket
+		if err := rows.Scan(&b.Start, &b.Created, &b.Succeeded, &b.Failed); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, b)
+	}
+
+	return buckets, rows.Err()
+}
@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// maxLoggedArgLength truncates long arguments such as payloads in query logs
+const maxLoggedArgLength = 200
+
+// queryTracer logs query durations and arguments through slog
+// Slow queries are logged at warn level; with all set every other query is logged at info level
+type queryTracer struct {
+	all  bool
+	slow time.Duration
+}
+
+type queryTraceKey struct{}
+
+// queryTrace is carried in the query context from TraceQueryStart to TraceQueryEnd
+type queryTrace struct {
+	sql     string
+	args    []any
+	started time.Time
+}
+
+// TraceQueryStart records the query and its start time
+func (t *queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryTraceKey{}, queryTrace{
+		sql:     data.SQL,
+		args:    data.Args,
+		started: time.Now(),
+	})
+}
+
+// TraceQueryEnd logs the query if it was slow, failed, or every query is logged
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	trace, ok := ctx.Value(queryTraceKey{}).(queryTrace)
+	if !ok {
+		return
+	}
+
+	duration := time.Since(trace.started)
+	slow := t.slow > 0 && duration >= t.slow
+	if !slow && !t.all {
+		return
+	}
+
+	level := slog.LevelInfo
+	msg := "Query"
+	if slow {
+		level = slog.LevelWarn
+		msg = "Slow query"
+	}
+	if !slog.Default().Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []any{
+		"sql", compactSQL(trace.sql),
+		"args", loggedArgs(trace.args),
+		"duration", duration,
+		"pid", conn.PgConn().PID(),
+	}
+	if data.Err != nil {
+		attrs = append(attrs, "error", data.Err)
+	} else {
+		attrs = append(attrs, "rows", data.CommandTag.RowsAffected())
+	}
+	slog.Log(ctx, level, msg, attrs...)
+}
+
+// compactSQL collapses the indentation of multi-line queries onto one line
+func compactSQL(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
+
+// loggedArgs formats query arguments, truncating long values
+func loggedArgs(args []any) []string {
+	logged := make([]string, len(args))
+	for i, arg := range args {
+		// Dereference optional arguments so the value is logged rather than its address
+		if v := reflect.ValueOf(arg); v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				logged[i] = "NULL"
+				continue
+			}
+			arg = v.Elem().Interface()
+		}
+
+		var s string
+		switch v := arg.(type) {
+		case []byte:
+			s = string(v)
+		case json.RawMessage:
+			s = string(v)
+		default:
+			s = fmt.Sprintf("%v", v)
+		}
+		if len(s) > maxLoggedArgLength {
+			s = s[:maxLoggedArgLength] + "..."
+		}
+		logged[i] = s
+	}
+	return logged
+}
@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompactSQL(t *testing.T) {
+	got := compactSQL("\n\t\tSELECT id\n\t\tFROM tasks\n\t\tWHERE id = $1\n\t")
+	if want := "SELECT id FROM tasks WHERE id = $1"; got != want {
+		t.Errorf("compactSQL = %q, want %q", got, want)
+	}
+}
+
+func TestLoggedArgs(t *testing.T) {
+	priority := 5
+	var missing *int
+
+	got := loggedArgs([]any{
+		int64(42),
+		&priority,
+		missing,
+		json.RawMessage(`{"to":"a@example.com"}`),
+		strings.Repeat("x", maxLoggedArgLength+10),
+	})
+	want := []string{
+		"42",
+		"5",
+		"NULL",
+		`{"to":"a@example.com"}`,
+		strings.Repeat("x", maxLoggedArgLength) + "...",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loggedArgs = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SQLSTATEs raised while the server restarts or fails over
+// Class 08 (connection exceptions) is matched by prefix
+const (
+	sqlStateAdminShutdown   = "57P01"
+	sqlStateCrashShutdown   = "57P02"
+	sqlStateCannotConnect   = "57P03"
+	sqlStateReadOnlyTxn     = "25006"
+	sqlStateConnectionClass = "08"
+)
+
+// isUnavailable reports whether err means the connection to the database was lost or refused,
+// rather than a problem with the query itself
+// Timeouts are ordinary errors: a query that outlives its deadline under load says nothing about failover
+func isUnavailable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	// Connecting failed, even if only because the dial timed out
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) || pgconn.Timeout(err) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case sqlStateAdminShutdown, sqlStateCrashShutdown, sqlStateCannotConnect, sqlStateReadOnlyTxn:
+			return true
+		}
+		return strings.HasPrefix(pgErr.Code, sqlStateConnectionClass)
+	}
+
+	// A reset or closed socket on an established connection is lost like an EOF; a timed-out one is not
+	var netErr net.Error
+	return (errors.As(err, &netErr) && !netErr.Timeout()) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// unavailable wraps err with storage.ErrUnavailable when the database is unreachable
+// A read-only error means the pool still holds connections to a demoted primary,
+// so every connection is dropped and new ones resolve the promoted server
+func (s *Store) unavailable(err error) error {
+	if !isUnavailable(err) {
+		return err
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == sqlStateReadOnlyTxn {
+		slog.Warn("Database is read-only, reconnecting the pool", "error", err)
+		s.pool.Reset()
+	}
+	return fmt.Errorf("%w: %w", storage.ErrUnavailable, err)
+}
@@ -8,20 +8,28 @@ import (
 )
 
 // UpdateTaskStatus updates the status of a task
-func (s *Store) UpdateTaskStatus(ctx context.Context, taskID int64, status models.TaskStatus, errorMessage *string) error {
+// With a version, the update only applies while the task is still at that version
+func (s *Store) UpdateTaskStatus(ctx context.Context, taskID int64, status models.TaskStatus, errorMessage *string, version *int64) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE tasks
 		SET status = $1, last_error = $2, updated_at = NOW()
 		WHERE id = $3
+		  AND ($4::bigint IS NULL OR version = $4)
 	`
 
-	result, err := s.pool.Exec(ctx, query, status, errorMessage, taskID)
+	result, err := s.pool.Exec(ctx, query, status, errorMessage, taskID, version)
 	if err != nil {
 		return err
 	}
 
 	if result.RowsAffected() == 0 {
-		return storage.ErrTaskNotFound
+		if _, err := s.GetTask(ctx, taskID); err != nil {
+			return err
+		}
+		return storage.ErrVersionConflict
 	}
 
 	return nil
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+)
+
+// GetWorkerSettings returns the effective runtime overrides for a worker
+// Each field falls back from the worker's own row to the default row; unset fields are nil
+func (s *Store) GetWorkerSettings(ctx context.Context, workerID string) (*models.WorkerSettings, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		SELECT COALESCE(w.max_concurrency, d.max_concurrency),
+		       COALESCE(w.poll_interval_seconds, d.poll_interval_seconds)
+		FROM (SELECT 1) AS one
+		LEFT JOIN worker_settings w ON w.worker_id = $1
+		LEFT JOIN worker_settings d ON d.worker_id = $2
+	`
+
+	settings := models.WorkerSettings{WorkerID: workerID}
+	err := s.pool.QueryRow(ctx, query, workerID, models.DefaultWorkerSettings).
+		Scan(&settings.MaxConcurrency, &settings.PollIntervalSeconds)
+	if err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// ListWorkerSettings returns all runtime overrides
+func (s *Store) ListWorkerSettings(ctx context.Context) ([]models.WorkerSettings, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		SELECT worker_id, max_concurrency, poll_interval_seconds, updated_at
+		FROM worker_settings
+		ORDER BY worker_id ASC
+	`
+
+	rows, err := s.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := []models.WorkerSettings{}
+	for rows.Next() {
+		var ws models.WorkerSettings
+		if err := rows.Scan(&ws.WorkerID, &ws.MaxConcurrency, &ws.PollIntervalSeconds, &ws.UpdatedAt); err != nil {
+			return nil, err
+		}
+		settings = append(settings, ws)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// SetWorkerSettings creates or replaces the runtime overrides for a worker
+func (s *Store) SetWorkerSettings(ctx context.Context, workerID string, req models.SetWorkerSettingsRequest) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO worker_settings (worker_id, max_concurrency, poll_interval_seconds, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (worker_id) DO UPDATE
+		SET max_concurrency = EXCLUDED.max_concurrency,
+		    poll_interval_seconds = EXCLUDED.poll_interval_seconds,
+		    updated_at = NOW()
+	`
+
+	_, err := s.pool.Exec(ctx, query, workerID, req.MaxConcurrency, req.PollIntervalSeconds)
+	return err
+}
+
+// DeleteWorkerSettings removes the runtime overrides for a worker
+func (s *Store) DeleteWorkerSettings(ctx context.Context, workerID string) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	result, err := s.pool.Exec(ctx, `DELETE FROM worker_settings WHERE worker_id = $1`, workerID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return storage.ErrWorkerSettingsNotFound
+	}
+
+	return nil
+}
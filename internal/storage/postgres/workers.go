@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// workerRetention is how long workers that stopped heartbeating stay listed
+const workerRetention = 24 * time.Hour
+
+// RegisterWorker records a starting worker and prunes workers not seen within the retention period
+func (s *Store) RegisterWorker(ctx context.Context, worker models.WorkerInfo) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	if _, err := s.pool.Exec(ctx, `DELETE FROM workers WHERE last_seen < $1`, time.Now().Add(-workerRetention)); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO workers (id, hostname, version, concurrency, in_flight, labels, handler_types, started_at, last_seen, stopped_at,
+		                     tasks_succeeded, tasks_failed, busy_seconds)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8, NULL, 0, 0, 0)
+		ON CONFLICT (id) DO UPDATE
+		SET hostname = EXCLUDED.hostname,
+		    version = EXCLUDED.version,
+		    concurrency = EXCLUDED.concurrency,
+		    in_flight = EXCLUDED.in_flight,
+		    labels = EXCLUDED.labels,
+		    handler_types = EXCLUDED.handler_types,
+		    started_at = EXCLUDED.started_at,
+		    last_seen = EXCLUDED.last_seen,
+		    stopped_at = NULL,
+		    tasks_succeeded = 0,
+		    tasks_failed = 0,
+		    busy_seconds = 0
+	`
+
+	_, err := s.pool.Exec(ctx, query, worker.ID, worker.Hostname, worker.Version, worker.Concurrency, worker.InFlight, jsonArg(workerLabels(worker.Labels)), workerTaskTypes(worker.TaskTypes), time.Now())
+	return err
+}
+
+// HeartbeatWorker refreshes a worker's last_seen, current load and task totals
+func (s *Store) HeartbeatWorker(ctx context.Context, workerID string, heartbeat models.WorkerHeartbeat) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE workers
+		SET concurrency = $2, in_flight = $3, last_seen = $4,
+		    tasks_succeeded = $5, tasks_failed = $6, busy_seconds = $7
+		WHERE id = $1
+	`
+
+	_, err := s.pool.Exec(ctx, query, workerID, heartbeat.Concurrency, heartbeat.InFlight, time.Now(),
+		heartbeat.TasksSucceeded, heartbeat.TasksFailed, heartbeat.BusySeconds)
+	return err
+}
+
+// DeregisterWorker marks a worker as stopped after a graceful shutdown
+func (s *Store) DeregisterWorker(ctx context.Context, workerID string) error {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE workers
+		SET in_flight = 0, last_seen = $2, stopped_at = $2
+		WHERE id = $1
+	`
+
+	_, err := s.pool.Exec(ctx, query, workerID, time.Now())
+	return err
+}
+
+// ListWorkers returns registered workers with the number of task locks each holds
+// Workers without a heartbeat within staleAfter are reported as stale
+func (s *Store) ListWorkers(ctx context.Context, staleAfter time.Duration) ([]models.WorkerInfo, error) {
+	ctx, cancel := s.shortQuery(ctx)
+	defer cancel()
+
+	query := `
+		SELECT w.id, w.hostname, w.version, w.concurrency, w.in_flight, w.labels, w.handler_types,
+		       w.started_at, w.last_seen, w.stopped_at,
+		       w.tasks_succeeded, w.tasks_failed, w.busy_seconds,
+		       CASE
+		           WHEN w.stopped_at IS NOT NULL THEN 'stopped'
+		           WHEN w.last_seen < $1 THEN 'stale'
+		           ELSE 'active'
+		       END,
+		       COUNT(t.id)
+		FROM workers w
+		LEFT JOIN tasks t ON t.locked_by = w.id AND t.status = $2
+		GROUP BY w.id
+		ORDER BY w.stopped_at IS NOT NULL, w.last_seen DESC
+	`
+
+	rows, err := s.pool.Query(ctx, query, time.Now().Add(-staleAfter), models.TaskStatusRunning)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	workers := []models.WorkerInfo{}
+	for rows.Next() {
+		var w models.WorkerInfo
+		if err := rows.Scan(&w.ID, &w.Hostname, &w.Version, &w.Concurrency, &w.InFlight, &w.Labels, &w.TaskTypes,
+			&w.StartedAt, &w.LastSeen, &w.StoppedAt, &w.TasksSucceeded, &w.TasksFailed, &w.BusySeconds,
+			&w.Status, &w.LockedTasks); err != nil {
+			return nil, err
+		}
+		workers = append(workers, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return workers, nil
+}
+
+// workerLabels stores missing labels as an empty object
+func workerLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return map[string]string{}
+	}
+	return labels
+}
+
+// workerTaskTypes stores the handled types lowercase, missing ones as an empty array
+func workerTaskTypes(taskTypes []string) []string {
+	lower := make([]string, len(taskTypes))
+	for i, taskType := range taskTypes {
+		lower[i] = strings.ToLower(taskType)
+	}
+	return lower
+}
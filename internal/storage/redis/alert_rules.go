@@ -0,0 +1,150 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// recordAlertScript stores an evaluation and reports whether it changed the rule's firing state
+// Running it atomically makes exactly one of several API servers see the change
+//
+// KEYS: alert rules hash, alert state hash; ARGV: rule name, firing (0 or 1), value, now (ms)
+var recordAlertScript = goredis.NewScript(`
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+
+local previous = redis.call('HGET', KEYS[2], 'firing') or '0'
+redis.call('HSET', KEYS[2], 'firing', ARGV[2], 'last_value', ARGV[3], 'last_evaluated_at', ARGV[4])
+if previous == ARGV[2] then
+	return 0
+end
+
+redis.call('HSET', KEYS[2], 'last_transition_at', ARGV[4])
+return 1
+`)
+
+// alertRuleEntry is the stored form of a rule, keeping the signing secret the API never returns
+type alertRuleEntry struct {
+	models.AlertRule
+	SigningSecret string `json:"signing_secret,omitempty"`
+}
+
+// ListAlertRules returns all alert rules with their last evaluation, ordered by name
+func (s *Store) ListAlertRules(ctx context.Context) ([]models.AlertRule, error) {
+	entries, err := s.client.HGetAll(ctx, s.key("alert_rules")).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]models.AlertRule, 0, len(entries))
+	for _, entry := range entries {
+		var r alertRuleEntry
+		if err := json.Unmarshal([]byte(entry), &r); err != nil {
+			return nil, err
+		}
+		r.AlertRule.SigningSecret = r.SigningSecret
+		rules = append(rules, r.AlertRule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Name < rules[j].Name })
+
+	// Evaluation state lives apart from the definition so replacing a rule keeps it
+	cmds := make([]*goredis.MapStringStringCmd, len(rules))
+	_, err = s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for i, r := range rules {
+			cmds[i] = pipe.HGetAll(ctx, s.alertStateKey(r.Name))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rules {
+		fields := fieldReader{fields: cmds[i].Val()}
+		rules[i].Firing = fields.string("firing") == "1"
+		rules[i].LastEvaluatedAt = fields.optionalTime("last_evaluated_at")
+		rules[i].LastTransitionAt = fields.optionalTime("last_transition_at")
+		if raw := fields.string("last_value"); raw != "" {
+			value, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, err
+			}
+			rules[i].LastValue = &value
+		}
+		if fields.err != nil {
+			return nil, fields.err
+		}
+	}
+
+	return rules, nil
+}
+
+// SetAlertRule creates or replaces an alert rule, keeping its firing state
+func (s *Store) SetAlertRule(ctx context.Context, name string, req models.SetAlertRuleRequest) error {
+	entry, err := json.Marshal(alertRuleEntry{
+		AlertRule: models.AlertRule{
+			Name:          name,
+			Metric:        req.Metric,
+			TaskType:      strings.ToLower(req.TaskType),
+			Operator:      req.Operator,
+			Threshold:     *req.Threshold,
+			WindowSeconds: req.WindowSeconds,
+			WebhookURL:    req.WebhookURL,
+			Channel:       req.Channel,
+			UpdatedAt:     time.Now(),
+		},
+		SigningSecret: req.SigningSecret,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.client.HSet(ctx, s.key("alert_rules"), name, entry).Err()
+}
+
+// DeleteAlertRule removes an alert rule and its evaluation state
+func (s *Store) DeleteAlertRule(ctx context.Context, name string) error {
+	var removed *goredis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		removed = pipe.HDel(ctx, s.key("alert_rules"), name)
+		pipe.Del(ctx, s.alertStateKey(name))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if removed.Val() == 0 {
+		return storage.ErrAlertRuleNotFound
+	}
+
+	return nil
+}
+
+// RecordAlertEvaluation stores the outcome of evaluating a rule
+// Returns true if this call changed its firing state; unknown rules are ignored
+func (s *Store) RecordAlertEvaluation(ctx context.Context, name string, firing bool, value float64) (bool, error) {
+	state := "0"
+	if firing {
+		state = "1"
+	}
+
+	changed, err := recordAlertScript.Run(ctx, s.client,
+		[]string{s.key("alert_rules"), s.alertStateKey(name)},
+		name, state, strconv.FormatFloat(value, 'g', -1, 64), time.Now().UnixMilli(),
+	).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return changed == 1, nil
+}
@@ -0,0 +1,118 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// CreateAPIKey stores a new API key given its display prefix and hash
+// Keys live as JSON in the api_keys hash by ID, with api_key_hashes mapping each hash to its ID
+func (s *Store) CreateAPIKey(ctx context.Context, req models.CreateAPIKeyRequest, prefix, hash string) (*models.APIKey, error) {
+	id, err := s.client.Incr(ctx, s.key("api_keys:seq")).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	key := &models.APIKey{
+		ID:        id,
+		Name:      req.Name,
+		Prefix:    prefix,
+		Role:      req.Role,
+		Scopes:    req.Scopes,
+		CreatedAt: time.Now(),
+		ExpiresAt: req.ExpiresAt,
+	}
+	entry, err := json.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.HSet(ctx, s.key("api_keys"), strconv.FormatInt(id, 10), entry)
+		pipe.HSet(ctx, s.key("api_key_hashes"), hash, id)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// GetAPIKeyByHash returns the key with the given hash, including revoked and expired keys
+func (s *Store) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	id, err := s.client.HGet(ctx, s.key("api_key_hashes"), hash).Result()
+	if errors.Is(err, goredis.Nil) {
+		return nil, storage.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getAPIKey(ctx, id)
+}
+
+// ListAPIKeys returns all API keys, including revoked ones, ordered by ID
+func (s *Store) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	entries, err := s.client.HGetAll(ctx, s.key("api_keys")).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]models.APIKey, 0, len(entries))
+	for _, entry := range entries {
+		var key models.APIKey
+		if err := json.Unmarshal([]byte(entry), &key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+
+	return keys, nil
+}
+
+// RevokeAPIKey marks a key revoked; revoking it again keeps the first revocation time
+func (s *Store) RevokeAPIKey(ctx context.Context, id int64) error {
+	key, err := s.getAPIKey(ctx, strconv.FormatInt(id, 10))
+	if err != nil {
+		return err
+	}
+	if key.RevokedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	key.RevokedAt = &now
+	entry, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	return s.client.HSet(ctx, s.key("api_keys"), strconv.FormatInt(id, 10), entry).Err()
+}
+
+// getAPIKey reads the key with the given ID from the api_keys hash
+func (s *Store) getAPIKey(ctx context.Context, id string) (*models.APIKey, error) {
+	entry, err := s.client.HGet(ctx, s.key("api_keys"), id).Result()
+	if errors.Is(err, goredis.Nil) {
+		return nil, storage.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var key models.APIKey
+	if err := json.Unmarshal([]byte(entry), &key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
@@ -0,0 +1,140 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// recordFailureScript counts a failure and opens the breaker once the threshold is reached
+// Only one failure trips the breaker per cooldown, even with many workers failing at once
+//
+// KEYS: breaker hash, breaker set; ARGV: task type, error, now (ms), threshold, cooldown (ms)
+var recordFailureScript = goredis.NewScript(`
+redis.call('SADD', KEYS[2], ARGV[1])
+local failures = redis.call('HINCRBY', KEYS[1], 'consecutive_failures', 1)
+redis.call('HSET', KEYS[1], 'last_error', ARGV[2], 'updated_at', ARGV[3])
+if failures < tonumber(ARGV[4]) then
+	return 0
+end
+
+local now = tonumber(ARGV[3])
+local opened_until = tonumber(redis.call('HGET', KEYS[1], 'opened_until') or '')
+if opened_until ~= nil and opened_until > now then
+	return 0
+end
+
+redis.call('HSET', KEYS[1], 'opened_until', string.format('%.0f', now + tonumber(ARGV[5])))
+redis.call('HINCRBY', KEYS[1], 'trip_count', 1)
+return 1
+`)
+
+// RecordTypeFailure counts a failure against the task type's circuit breaker
+// Opens the breaker once threshold consecutive failures are reached and it is not already open
+// After the cooldown the count is kept, so the first failure of a probing task reopens it
+func (s *Store) RecordTypeFailure(ctx context.Context, taskType string, errorMessage string, threshold int, cooldown time.Duration) (bool, error) {
+	taskType = strings.ToLower(taskType)
+
+	opened, err := recordFailureScript.Run(ctx, s.client,
+		[]string{s.breakerKey(taskType), s.key("breakers")},
+		taskType, errorMessage, time.Now().UnixMilli(), threshold, cooldown.Milliseconds(),
+	).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return opened == 1, nil
+}
+
+// RecordTypeSuccess resets the consecutive failure count of the task type
+// Writes nothing for types that have not failed
+func (s *Store) RecordTypeSuccess(ctx context.Context, taskType string) error {
+	key := s.breakerKey(strings.ToLower(taskType))
+
+	failures, err := s.client.HGet(ctx, key, "consecutive_failures").Int()
+	if errors.Is(err, goredis.Nil) || (err == nil && failures == 0) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.client.HSet(ctx, key, "consecutive_failures", 0, "updated_at", time.Now().UnixMilli()).Err()
+}
+
+// ListCircuitBreakers returns the circuit breaker state of every task type that has failed
+func (s *Store) ListCircuitBreakers(ctx context.Context) ([]models.CircuitBreaker, error) {
+	types, err := s.client.SMembers(ctx, s.key("breakers")).Result()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(types)
+
+	now := time.Now()
+	breakers := make([]models.CircuitBreaker, 0, len(types))
+	for _, taskType := range types {
+		fields, err := s.client.HGetAll(ctx, s.breakerKey(taskType)).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		r := fieldReader{fields: fields}
+		b := models.CircuitBreaker{
+			TaskType:            taskType,
+			State:               "closed",
+			ConsecutiveFailures: r.int("consecutive_failures"),
+			OpenedUntil:         r.optionalTime("opened_until"),
+			TripCount:           r.int("trip_count"),
+			LastError:           r.optionalString("last_error"),
+			UpdatedAt:           r.time("updated_at"),
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		if b.OpenedUntil != nil && b.OpenedUntil.After(now) {
+			b.State = "open"
+		}
+
+		breakers = append(breakers, b)
+	}
+
+	return breakers, nil
+}
+
+// OpenCircuitBreaker pauses claiming of a task type for cooldown
+func (s *Store) OpenCircuitBreaker(ctx context.Context, taskType string, cooldown time.Duration) error {
+	taskType = strings.ToLower(taskType)
+	key := s.breakerKey(taskType)
+	now := time.Now()
+
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.SAdd(ctx, s.key("breakers"), taskType)
+		pipe.HSetNX(ctx, key, "consecutive_failures", 0)
+		pipe.HSet(ctx, key, "opened_until", now.Add(cooldown).UnixMilli(), "updated_at", now.UnixMilli())
+		pipe.HIncrBy(ctx, key, "trip_count", 1)
+		return nil
+	})
+	return err
+}
+
+// CloseCircuitBreaker resumes claiming of a task type and resets its failure count
+func (s *Store) CloseCircuitBreaker(ctx context.Context, taskType string) error {
+	key := s.breakerKey(strings.ToLower(taskType))
+
+	exists, err := s.client.Exists(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+
+	if exists == 0 {
+		return storage.ErrCircuitBreakerNotFound
+	}
+
+	return s.client.HSet(ctx, key, "opened_until", "", "consecutive_failures", 0, "updated_at", time.Now().UnixMilli()).Err()
+}
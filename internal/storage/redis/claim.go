@@ -0,0 +1,267 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// claimScanLimit bounds how many ready tasks one claim inspects
+// Tasks skipped by limits or filters stay ready; a long run of them can delay tasks queued behind
+const claimScanLimit = 1000
+
+// claimScript promotes due tasks to the ready set and claims up to n of them atomically
+// Ready tasks are scored by negated priority; members sort FIFO within a priority
+// Applies the Postgres claim's maintenance mode, pauses, circuit breakers, cluster-wide concurrency limits,
+// per-key rate limits, minimum priority, labels and type slots
+// Tenant max_running quotas are not enforced; the store does not implement storage.TenantQuotas
+//
+// ARGV: prefix, now (ms), worker id, n, min priority ("" = none), worker labels, type slots, scan limit
+var claimScript = goredis.NewScript(`
+local p = ARGV[1]
+local now = tonumber(ARGV[2])
+local worker = ARGV[3]
+local n = tonumber(ARGV[4])
+local min_priority = tonumber(ARGV[5])
+local labels = cjson.decode(ARGV[6])
+local type_slots = cjson.decode(ARGV[7])
+local scan_limit = tonumber(ARGV[8])
+
+local function ms(value)
+	return string.format('%.0f', value)
+end
+
+local maintenance = redis.call('GET', p .. 'maintenance')
+if maintenance then
+	local mode = cjson.decode(maintenance)
+	if mode.enabled and mode.stop_claims then
+		return {}
+	end
+end
+if redis.call('HEXISTS', p .. 'pauses', '*') == 1 then
+	return {}
+end
+
+-- Promote tasks whose next_run_at has passed
+local due = redis.call('ZRANGEBYSCORE', p .. 'scheduled', '-inf', ARGV[2], 'LIMIT', 0, scan_limit)
+for _, id in ipairs(due) do
+	redis.call('ZREM', p .. 'scheduled', id)
+	local f = redis.call('HMGET', p .. 'task:' .. id, 'priority', 'rank', 'status')
+	if f[1] and f[3] == 'queued' then
+		redis.call('ZADD', p .. 'ready', -tonumber(f[1]), f[2])
+	end
+end
+
+local paused, breakers, limits, rates = {}, {}, {}, {}
+local function type_open(task_type)
+	if paused[task_type] == nil then
+		paused[task_type] = redis.call('HEXISTS', p .. 'pauses', task_type) == 1
+		local opened_until = tonumber(redis.call('HGET', p .. 'breaker:' .. task_type, 'opened_until') or '')
+		breakers[task_type] = opened_until ~= nil and opened_until > now
+		local limit = redis.call('HGET', p .. 'concurrency_limits', task_type)
+		if limit then
+			limits[task_type] = cjson.decode(limit).max_running
+		end
+		local rate = redis.call('HGET', p .. 'rate_limits', task_type)
+		if rate then
+			rates[task_type] = cjson.decode(rate)
+		end
+	end
+	if paused[task_type] or breakers[task_type] then
+		return false
+	end
+	local limit = limits[task_type]
+	if limit then
+		local running = tonumber(redis.call('HGET', p .. 'running_by_type', task_type) or '0')
+		if running >= limit then
+			return false
+		end
+	end
+	return true
+end
+
+local function labels_match(required)
+	for k, v in pairs(cjson.decode(required)) do
+		if labels[k] ~= v then
+			return false
+		end
+	end
+	return true
+end
+
+local claimed, per_type = {}, {}
+local offset, scanned = 0, 0
+while #claimed < n and scanned < scan_limit do
+	local batch = redis.call('ZRANGE', p .. 'ready', offset, offset + 99)
+	if #batch == 0 then
+		break
+	end
+	scanned = scanned + #batch
+
+	local removed = 0
+	for _, member in ipairs(batch) do
+		if #claimed >= n then
+			break
+		end
+
+		local id = string.match(member, ':(%d+)$')
+		local key = p .. 'task:' .. id
+		local f = redis.call('HMGET', key, 'status', 'type', 'priority', 'required_labels', 'rate_limit_key', 'timeout_seconds')
+
+		if f[1] ~= 'queued' then
+			-- Stale entry of an expired or already moved task
+			redis.call('ZREM', p .. 'ready', member)
+			removed = removed + 1
+		else
+			local task_type = f[2]
+			local eligible = (min_priority == nil or tonumber(f[3]) >= min_priority)
+				and (type_slots[task_type] == nil or (per_type[task_type] or 0) < type_slots[task_type])
+				and labels_match(f[4])
+				and type_open(task_type)
+
+			local rate_key, rate = f[5], rates[task_type]
+			if eligible and rate_key ~= '' and rate then
+				local window = p .. 'rate:' .. rate_key
+				redis.call('ZREMRANGEBYSCORE', window, '-inf', ms(now - rate.window_seconds * 1000))
+				eligible = redis.call('ZCARD', window) < rate.max_per_window
+			end
+
+			if eligible then
+				local token = redis.call('HINCRBY', key, 'lock_token', 1)
+				redis.call('HINCRBY', key, 'version', 1)
+				local expires = now + tonumber(f[6]) * 1000
+				redis.call('HSET', key,
+					'status', 'running',
+					'locked_at', ARGV[2],
+					'lock_expires_at', ms(expires),
+					'locked_by', worker,
+					'last_started_at', ARGV[2],
+					'updated_at', ARGV[2])
+
+				redis.call('ZREM', p .. 'ready', member)
+				redis.call('ZADD', p .. 'running', ms(expires), id)
+				redis.call('HINCRBY', p .. 'running_by_type', task_type, 1)
+				redis.call('HINCRBY', p .. 'counts', 'queued', -1)
+				redis.call('HINCRBY', p .. 'counts', 'running', 1)
+				redis.call('HINCRBY', p .. 'counts:type:' .. task_type, 'queued', -1)
+				redis.call('HINCRBY', p .. 'counts:type:' .. task_type, 'running', 1)
+
+				if rate_key ~= '' and rate then
+					local window = p .. 'rate:' .. rate_key
+					redis.call('ZADD', window, ARGV[2], id .. ':' .. token)
+					redis.call('PEXPIRE', window, rate.window_seconds * 1000)
+				end
+
+				removed = removed + 1
+				per_type[task_type] = (per_type[task_type] or 0) + 1
+				table.insert(claimed, id)
+			end
+		end
+	end
+
+	offset = offset + #batch - removed
+end
+
+return claimed
+`)
+
+// ClaimNextTask atomically claims the next available task for processing
+// Returns nil if no tasks are available
+func (s *Store) ClaimNextTask(ctx context.Context, workerID string) (*models.Task, error) {
+	tasks, err := s.ClaimNextTasks(ctx, workerID, 1, models.ClaimFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tasks) == 0 {
+		return nil, nil // No tasks available
+	}
+
+	return tasks[0], nil
+}
+
+// ClaimNextTasks atomically claims up to n available tasks with a single script call
+// Respects next_run_at scheduling, limits, pauses and filter like the Postgres store, but not tenant quotas
+// Tasks with expired locks are recovered by ReapExpiredLocks rather than claimed directly
+// Records a worker_lock_acquired history entry for every claimed task
+func (s *Store) ClaimNextTasks(ctx context.Context, workerID string, n int, filter models.ClaimFilter) ([]*models.Task, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	// A worker satisfies a task when the task's required labels are a subset of its own
+	workerLabels := filter.Labels
+	if workerLabels == nil {
+		workerLabels = map[string]string{}
+	}
+	labels, err := json.Marshal(workerLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	// Types without an entry are only bounded by n
+	typeSlots := filter.TypeSlots
+	if typeSlots == nil {
+		typeSlots = map[string]int{}
+	}
+	slots, err := json.Marshal(typeSlots)
+	if err != nil {
+		return nil, err
+	}
+
+	var minPriority string
+	if filter.MinPriority != nil {
+		minPriority = strconv.Itoa(*filter.MinPriority)
+	}
+
+	ids, err := claimScript.Run(ctx, s.client, nil,
+		s.prefix,
+		time.Now().UnixMilli(),
+		workerID,
+		n,
+		minPriority,
+		string(labels),
+		string(slots),
+		claimScanLimit,
+	).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.loadTasks(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// Best-effort history logging, pipelined for the whole batch
+	history := make([]models.TaskHistory, len(tasks))
+	for i, task := range tasks {
+		history[i] = models.TaskHistory{
+			TaskID:     task.ID,
+			Status:     models.TaskStatusRunning,
+			EventType:  models.EventWorkerLockAcquired,
+			RetryCount: &task.RetryCount,
+			MaxRetries: &task.MaxRetries,
+			WorkerID:   &workerID,
+		}
+	}
+	if err := s.insertHistory(ctx, history); err != nil {
+		slog.Error("Failed to insert lock acquired history", "tasks", len(tasks), "error", err)
+	}
+
+	// Restore dispatch order
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Priority != tasks[j].Priority {
+			return tasks[i].Priority > tasks[j].Priority
+		}
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+	})
+
+	return tasks, nil
+}
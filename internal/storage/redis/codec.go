@@ -0,0 +1,238 @@
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// taskFields encodes a task as the fields of its hash
+// Times are stored as Unix milliseconds and unset optional fields as empty strings,
+// so the claim script can compare and update them in place
+func taskFields(t *models.Task) (map[string]any, error) {
+	labels := t.RequiredLabels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	encodedLabels, err := json.Marshal(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := t.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	encodedMetadata, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	var schedule string
+	if len(t.RetrySchedule) > 0 {
+		encoded, err := json.Marshal(t.RetrySchedule)
+		if err != nil {
+			return nil, err
+		}
+		schedule = string(encoded)
+	}
+
+	return map[string]any{
+		"id":                   t.ID,
+		"public_id":            t.PublicID.String(),
+		"name":                 t.Name,
+		"type":                 t.Type,
+		"payload":              string(t.Payload),
+		"status":               string(t.Status),
+		"priority":             t.Priority,
+		"tenant":               t.Tenant,
+		"required_labels":      string(encodedLabels),
+		"metadata":             string(encodedMetadata),
+		"retry_count":          t.RetryCount,
+		"max_retries":          t.MaxRetries,
+		"last_error":           optionalString(t.LastError),
+		"next_run_at":          t.NextRunAt.UnixMilli(),
+		"backoff_seconds":      t.BackoffSeconds,
+		"retry_strategy":       string(t.RetryStrategy),
+		"retry_schedule":       schedule,
+		"max_backoff_seconds":  optionalInt(t.MaxBackoffSeconds),
+		"last_backoff_seconds": optionalInt(t.LastBackoffSeconds),
+		"timeout_seconds":      t.TimeoutSeconds,
+		"timeout_count":        t.TimeoutCount,
+		"max_timeouts":         optionalInt(t.MaxTimeouts),
+		"locked_at":            optionalTime(t.LockedAt),
+		"lock_expires_at":      optionalTime(t.LockExpiresAt),
+		"locked_by":            optionalString(t.LockedBy),
+		"lock_token":           t.LockToken,
+		"crash_count":          t.CrashCount,
+		"quarantined_at":       optionalTime(t.QuarantinedAt),
+		"redacted_at":          optionalTime(t.RedactedAt),
+		"payload_signature":    t.PayloadSignature,
+		"rate_limit_key":       optionalString(t.RateLimitKey),
+		"version":              t.Version,
+		"last_started_at":      optionalTime(t.LastStartedAt),
+		"created_at":           t.CreatedAt.UnixMilli(),
+		"updated_at":           t.UpdatedAt.UnixMilli(),
+		"rank":                 rankMember(t),
+	}, nil
+}
+
+// rankMember is the task's member in the ready set
+// Ready tasks share a score per priority, so the zero-padded creation time keeps them FIFO
+func rankMember(t *models.Task) string {
+	return fmt.Sprintf("%015d:%d", t.CreatedAt.UnixMilli(), t.ID)
+}
+
+// decodeTask decodes the fields of a task hash
+func decodeTask(fields map[string]string) (*models.Task, error) {
+	r := fieldReader{fields: fields}
+
+	t := &models.Task{
+		ID:                 r.int64("id"),
+		PublicID:           r.uuid("public_id"),
+		Name:               r.string("name"),
+		Type:               r.string("type"),
+		Payload:            json.RawMessage(r.string("payload")),
+		Status:             models.TaskStatus(r.string("status")),
+		Priority:           r.int("priority"),
+		Tenant:             r.string("tenant"),
+		RetryCount:         r.int("retry_count"),
+		MaxRetries:         r.int("max_retries"),
+		LastError:          r.optionalString("last_error"),
+		NextRunAt:          r.time("next_run_at"),
+		BackoffSeconds:     r.int("backoff_seconds"),
+		RetryStrategy:      models.RetryStrategy(r.string("retry_strategy")),
+		MaxBackoffSeconds:  r.optionalInt("max_backoff_seconds"),
+		LastBackoffSeconds: r.optionalInt("last_backoff_seconds"),
+		TimeoutSeconds:     r.int("timeout_seconds"),
+		TimeoutCount:       r.int("timeout_count"),
+		MaxTimeouts:        r.optionalInt("max_timeouts"),
+		LockedAt:           r.optionalTime("locked_at"),
+		LockExpiresAt:      r.optionalTime("lock_expires_at"),
+		LockedBy:           r.optionalString("locked_by"),
+		LockToken:          r.int64("lock_token"),
+		CrashCount:         r.int("crash_count"),
+		QuarantinedAt:      r.optionalTime("quarantined_at"),
+		RedactedAt:         r.optionalTime("redacted_at"),
+		PayloadSignature:   r.string("payload_signature"),
+		RateLimitKey:       r.optionalString("rate_limit_key"),
+		Version:            r.int64("version"),
+		LastStartedAt:      r.optionalTime("last_started_at"),
+		CreatedAt:          r.time("created_at"),
+		UpdatedAt:          r.time("updated_at"),
+	}
+
+	if labels := r.string("required_labels"); labels != "" && r.err == nil {
+		r.err = json.Unmarshal([]byte(labels), &t.RequiredLabels)
+	}
+	if metadata := r.string("metadata"); metadata != "" && r.err == nil {
+		r.err = json.Unmarshal([]byte(metadata), &t.Metadata)
+	}
+	if schedule := r.string("retry_schedule"); schedule != "" && r.err == nil {
+		r.err = json.Unmarshal([]byte(schedule), &t.RetrySchedule)
+	}
+
+	if r.err != nil {
+		return nil, fmt.Errorf("decode task %s: %w", fields["id"], r.err)
+	}
+
+	return t, nil
+}
+
+// fieldReader parses hash fields, keeping the first error
+type fieldReader struct {
+	fields map[string]string
+	err    error
+}
+
+func (r *fieldReader) string(name string) string {
+	return r.fields[name]
+}
+
+func (r *fieldReader) optionalString(name string) *string {
+	value := r.fields[name]
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// int64 reads a missing or empty field as 0
+func (r *fieldReader) int64(name string) int64 {
+	raw := r.fields[name]
+	if raw == "" {
+		return 0
+	}
+
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("field %s: %w", name, err)
+	}
+	return value
+}
+
+func (r *fieldReader) int(name string) int {
+	return int(r.int64(name))
+}
+
+func (r *fieldReader) optionalInt(name string) *int {
+	if r.fields[name] == "" {
+		return nil
+	}
+	value := r.int(name)
+	return &value
+}
+
+// uuid reads a missing or empty field as uuid.Nil (tasks created before public ids existed)
+func (r *fieldReader) uuid(name string) uuid.UUID {
+	raw := r.fields[name]
+	if raw == "" {
+		return uuid.Nil
+	}
+
+	value, err := uuid.Parse(raw)
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("field %s: %w", name, err)
+	}
+	return value
+}
+
+func (r *fieldReader) time(name string) time.Time {
+	return time.UnixMilli(r.int64(name))
+}
+
+func (r *fieldReader) optionalTime(name string) *time.Time {
+	if r.fields[name] == "" {
+		return nil
+	}
+	value := r.time(name)
+	return &value
+}
+
+// optionalString encodes a nil string as an empty field
+func optionalString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// optionalInt encodes a nil int as an empty field
+func optionalInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+// optionalTime encodes a nil time as an empty field
+func optionalTime(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatInt(value.UnixMilli(), 10)
+}
@@ -0,0 +1,70 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+)
+
+// ListConcurrencyLimits returns all configured per-type concurrency limits with current usage
+func (s *Store) ListConcurrencyLimits(ctx context.Context) ([]models.ConcurrencyLimit, error) {
+	entries, err := s.client.HGetAll(ctx, s.key("concurrency_limits")).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	running, err := s.client.HGetAll(ctx, s.key("running_by_type")).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	limits := make([]models.ConcurrencyLimit, 0, len(entries))
+	for _, entry := range entries {
+		var l models.ConcurrencyLimit
+		if err := json.Unmarshal([]byte(entry), &l); err != nil {
+			return nil, err
+		}
+		l.Running, _ = strconv.ParseInt(running[l.TaskType], 10, 64)
+		limits = append(limits, l)
+	}
+
+	sort.Slice(limits, func(i, j int) bool { return limits[i].TaskType < limits[j].TaskType })
+
+	return limits, nil
+}
+
+// SetConcurrencyLimit creates or updates the concurrency limit for a task type
+func (s *Store) SetConcurrencyLimit(ctx context.Context, taskType string, maxRunning int) error {
+	taskType = strings.ToLower(taskType)
+
+	entry, err := json.Marshal(models.ConcurrencyLimit{
+		TaskType:   taskType,
+		MaxRunning: maxRunning,
+		UpdatedAt:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.client.HSet(ctx, s.key("concurrency_limits"), taskType, entry).Err()
+}
+
+// DeleteConcurrencyLimit removes the concurrency limit for a task type
+func (s *Store) DeleteConcurrencyLimit(ctx context.Context, taskType string) error {
+	removed, err := s.client.HDel(ctx, s.key("concurrency_limits"), strings.ToLower(taskType)).Result()
+	if err != nil {
+		return err
+	}
+
+	if removed == 0 {
+		return storage.ErrConcurrencyLimitNotFound
+	}
+
+	return nil
+}
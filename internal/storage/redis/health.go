@@ -0,0 +1,40 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// CheckHealth reports Redis connectivity, latency and connection pool use
+// A pool with every connection in use is reported as degraded
+func (s *Store) CheckHealth(ctx context.Context) []models.ComponentHealth {
+	c := models.ComponentHealth{Name: "redis", Status: models.HealthStatusOK}
+
+	started := time.Now()
+	err := s.client.Ping(ctx).Err()
+	c.LatencySeconds = time.Since(started).Seconds()
+	if err != nil {
+		c.Status = models.HealthStatusDown
+		c.Error = err.Error()
+		return []models.ComponentHealth{c}
+	}
+
+	stats := s.client.PoolStats()
+	c.Pool = &models.PoolHealth{
+		MaxConns:      s.client.Options().PoolSize,
+		TotalConns:    int(stats.TotalConns),
+		AcquiredConns: int(stats.TotalConns - stats.IdleConns),
+		IdleConns:     int(stats.IdleConns),
+	}
+	if c.Pool.MaxConns > 0 {
+		c.Pool.Utilization = float64(c.Pool.AcquiredConns) / float64(c.Pool.MaxConns)
+		if c.Pool.AcquiredConns >= c.Pool.MaxConns {
+			c.Status = models.HealthStatusDegraded
+			c.Error = "connection pool exhausted"
+		}
+	}
+
+	return []models.ComponentHealth{c}
+}
@@ -0,0 +1,73 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// InsertHistory adds a new detailed event entry to task history
+// History of finished tasks expires with the task
+func (s *Store) InsertHistory(ctx context.Context, history models.TaskHistory) error {
+	return s.insertHistory(ctx, []models.TaskHistory{history})
+}
+
+// insertHistory appends history entries, possibly of several tasks, in two round-trips
+func (s *Store) insertHistory(ctx context.Context, history []models.TaskHistory) error {
+	if len(history) == 0 {
+		return nil
+	}
+
+	lastID, err := s.client.IncrBy(ctx, s.key("history:seq"), int64(len(history))).Result()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	entries := make([][]byte, len(history))
+	for i, h := range history {
+		h.ID = lastID - int64(len(history)-1-i)
+		h.CreatedAt = now
+
+		entry, err := json.Marshal(h)
+		if err != nil {
+			return err
+		}
+		entries[i] = entry
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for i, h := range history {
+			key := s.historyKey(h.TaskID)
+			pipe.RPush(ctx, key, entries[i])
+			if s.finishedTTL > 0 && isFinished(h.Status) {
+				pipe.Expire(ctx, key, s.finishedTTL)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// GetTaskHistory retrieves the history of status changes for a task, oldest first
+func (s *Store) GetTaskHistory(ctx context.Context, taskID int64, since time.Time) ([]models.TaskHistory, error) {
+	entries, err := s.client.LRange(ctx, s.historyKey(taskID), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]models.TaskHistory, 0, len(entries))
+	for _, entry := range entries {
+		var h models.TaskHistory
+		if err := json.Unmarshal([]byte(entry), &h); err != nil {
+			return nil, err
+		}
+		h.TaskID = taskID // not part of the encoded entry
+		history = append(history, h)
+	}
+
+	return history, nil
+}
@@ -0,0 +1,117 @@
+package redis
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// clearLock releases the worker lock held on a task
+func clearLock(task *models.Task) {
+	task.LockedAt = nil
+	task.LockExpiresAt = nil
+	task.LockedBy = nil
+}
+
+// CompleteTask marks a task as successfully completed
+// Only applies if the task is still held by the given lock
+func (s *Store) CompleteTask(ctx context.Context, taskID int64, lock models.TaskLock) error {
+	_, err := s.update(ctx, taskID, &lock, func(task *models.Task) error {
+		task.Status = models.TaskStatusSucceeded
+		task.LastError = nil
+		clearLock(task)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Best-effort history logging
+	history := models.TaskHistory{
+		TaskID:    taskID,
+		Status:    models.TaskStatusSucceeded,
+		EventType: models.EventTaskSucceeded,
+		WorkerID:  &lock.WorkerID,
+	}
+
+	if err := s.InsertHistory(ctx, history); err != nil {
+		slog.Error("Failed to insert success history", "task_id", taskID, "error", err)
+	}
+
+	return nil
+}
+
+// MarkTaskFailed permanently marks a task as failed (no more retries)
+// Only applies if the task is still held by the given lock
+func (s *Store) MarkTaskFailed(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string) error {
+	_, err := s.update(ctx, taskID, &lock, func(task *models.Task) error {
+		task.Status = models.TaskStatusFailed
+		task.LastError = &errorMessage
+		clearLock(task)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Best-effort history logging
+	history := models.TaskHistory{
+		TaskID:       taskID,
+		Status:       models.TaskStatusFailed,
+		EventType:    models.EventTaskFailedFinal,
+		ErrorMessage: &errorMessage,
+		WorkerID:     &lock.WorkerID,
+	}
+
+	if err := s.InsertHistory(ctx, history); err != nil {
+		slog.Error("Failed to insert failure history", "task_id", taskID, "error", err)
+	}
+
+	return nil
+}
+
+// ExtendLock pushes lock_expires_at forward for a running task
+// Only applies if the task is still held by the given lock
+func (s *Store) ExtendLock(ctx context.Context, taskID int64, lock models.TaskLock, extendBy time.Duration) error {
+	expiresAt := time.Now().Add(extendBy)
+
+	// Heartbeats keep the version, so clients holding a running task's ETag can still write with it
+	_, err := s.write(ctx, taskID, &lock, false, func(task *models.Task) error {
+		task.LockExpiresAt = &expiresAt
+		return nil
+	})
+	return err
+}
+
+// RequeueTask returns a running task to the queue without consuming a retry
+// Only applies if the task is still held by the given lock
+func (s *Store) RequeueTask(ctx context.Context, taskID int64, lock models.TaskLock) error {
+	now := time.Now()
+
+	_, err := s.update(ctx, taskID, &lock, func(task *models.Task) error {
+		task.Status = models.TaskStatusQueued
+		task.NextRunAt = now
+		clearLock(task)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Best-effort history logging
+	history := models.TaskHistory{
+		TaskID:    taskID,
+		Status:    models.TaskStatusQueued,
+		EventType: models.EventTaskQueued,
+		NextRunAt: &now,
+		WorkerID:  &lock.WorkerID,
+	}
+
+	if err := s.InsertHistory(ctx, history); err != nil {
+		slog.Error("Failed to insert requeue history", "task_id", taskID, "error", err)
+	}
+
+	return nil
+}
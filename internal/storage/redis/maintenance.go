@@ -0,0 +1,53 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// GetMaintenance returns the queue-wide maintenance mode
+func (s *Store) GetMaintenance(ctx context.Context) (*models.MaintenanceMode, error) {
+	entry, err := s.client.Get(ctx, s.key("maintenance")).Result()
+	if errors.Is(err, goredis.Nil) {
+		return &models.MaintenanceMode{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m models.MaintenanceMode
+	if err := json.Unmarshal([]byte(entry), &m); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// SetMaintenance toggles maintenance mode and returns the new state
+// Claims only stop while maintenance mode is enabled
+func (s *Store) SetMaintenance(ctx context.Context, req models.SetMaintenanceRequest) (*models.MaintenanceMode, error) {
+	m := models.MaintenanceMode{
+		Enabled:    *req.Enabled,
+		StopClaims: *req.Enabled && req.StopClaims,
+		UpdatedAt:  time.Now(),
+	}
+	if m.Enabled {
+		m.Reason = req.Reason
+	}
+
+	entry, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.client.Set(ctx, s.key("maintenance"), entry, 0).Err(); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
@@ -0,0 +1,48 @@
+package redis
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+)
+
+// notifyTaskCreated announces a new task to listening workers
+// Best-effort: workers still pick the task up on their next poll if this fails
+func (s *Store) notifyTaskCreated(ctx context.Context, taskID int64) {
+	if err := s.client.Publish(ctx, s.key("task_created"), strconv.FormatInt(taskID, 10)).Err(); err != nil {
+		slog.Error("Failed to notify task creation", "task_id", taskID, "error", err)
+	}
+}
+
+// ListenTaskCreated subscribes to task_created and signals for every message
+// The client resubscribes after connection errors until ctx is done
+func (s *Store) ListenTaskCreated(ctx context.Context) <-chan struct{} {
+	signals := make(chan struct{}, 1)
+
+	go func() {
+		defer close(signals)
+
+		sub := s.client.Subscribe(ctx, s.key("task_created"))
+		defer func() { _ = sub.Close() }()
+
+		messages := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				// Coalesce: one pending signal is enough to wake the dispatcher
+				select {
+				case signals <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	return signals
+}
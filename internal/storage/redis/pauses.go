@@ -0,0 +1,146 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// pauseHistoryLength is how many pause and resume actions the audit trail keeps
+const pauseHistoryLength = 10000
+
+// Pause stops claiming of a task type, or of every type for models.PauseScopeQueue
+// The pause and its audit entry are written in one transaction
+func (s *Store) Pause(ctx context.Context, scope string, reason *string) error {
+	scope = strings.ToLower(scope)
+
+	// Pausing an already paused scope only replaces its reason
+	pause := models.QueuePause{Scope: scope, Reason: reason, PausedAt: time.Now()}
+	existing, err := s.client.HGet(ctx, s.key("pauses"), scope).Result()
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return err
+	}
+	if err == nil {
+		var previous models.QueuePause
+		if err := json.Unmarshal([]byte(existing), &previous); err != nil {
+			return err
+		}
+		pause.PausedAt = previous.PausedAt
+	}
+
+	entry, err := json.Marshal(pause)
+	if err != nil {
+		return err
+	}
+
+	event, err := s.pauseEvent(ctx, scope, models.PauseActionPaused, reason)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.HSet(ctx, s.key("pauses"), scope, entry)
+		s.pushPauseEvent(ctx, pipe, event)
+		return nil
+	})
+	return err
+}
+
+// Resume lifts a pause and records it in the audit trail
+func (s *Store) Resume(ctx context.Context, scope string, reason *string) error {
+	scope = strings.ToLower(scope)
+
+	removed, err := s.client.HDel(ctx, s.key("pauses"), scope).Result()
+	if err != nil {
+		return err
+	}
+
+	if removed == 0 {
+		return storage.ErrNotPaused
+	}
+
+	event, err := s.pauseEvent(ctx, scope, models.PauseActionResumed, reason)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		s.pushPauseEvent(ctx, pipe, event)
+		return nil
+	})
+	return err
+}
+
+// pauseEvent builds an audit entry with a new id
+func (s *Store) pauseEvent(ctx context.Context, scope string, action models.PauseAction, reason *string) ([]byte, error) {
+	id, err := s.client.Incr(ctx, s.key("pause_history:seq")).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(models.PauseEvent{
+		ID:        id,
+		Scope:     scope,
+		Action:    action,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	})
+}
+
+// pushPauseEvent prepends an entry to the audit trail, dropping the oldest beyond pauseHistoryLength
+func (s *Store) pushPauseEvent(ctx context.Context, pipe goredis.Pipeliner, event []byte) {
+	pipe.LPush(ctx, s.key("pause_history"), event)
+	pipe.LTrim(ctx, s.key("pause_history"), 0, pauseHistoryLength-1)
+}
+
+// ListPauses returns the active pauses, the whole-queue pause first
+func (s *Store) ListPauses(ctx context.Context) ([]models.QueuePause, error) {
+	entries, err := s.client.HGetAll(ctx, s.key("pauses")).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	pauses := make([]models.QueuePause, 0, len(entries))
+	for _, entry := range entries {
+		var p models.QueuePause
+		if err := json.Unmarshal([]byte(entry), &p); err != nil {
+			return nil, err
+		}
+		pauses = append(pauses, p)
+	}
+
+	sort.Slice(pauses, func(i, j int) bool {
+		if (pauses[i].Scope == models.PauseScopeQueue) != (pauses[j].Scope == models.PauseScopeQueue) {
+			return pauses[i].Scope == models.PauseScopeQueue
+		}
+		return pauses[i].Scope < pauses[j].Scope
+	})
+
+	return pauses, nil
+}
+
+// ListPauseHistory returns the most recent pause and resume actions, newest first
+func (s *Store) ListPauseHistory(ctx context.Context, limit int) ([]models.PauseEvent, error) {
+	entries, err := s.client.LRange(ctx, s.key("pause_history"), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]models.PauseEvent, 0, len(entries))
+	for _, entry := range entries {
+		var e models.PauseEvent
+		if err := json.Unmarshal([]byte(entry), &e); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,70 @@
+package redis
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+)
+
+// ListQuarantinedTasks returns quarantined tasks, most recently quarantined first
+func (s *Store) ListQuarantinedTasks(ctx context.Context, limit int) ([]*models.Task, error) {
+	ids, err := s.client.ZRevRange(ctx, s.key("quarantined"), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.loadTasks(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if tasks == nil {
+		tasks = []*models.Task{}
+	}
+
+	return tasks, nil
+}
+
+// ReleaseTask returns a quarantined task to the queue for immediate execution
+// The crash count is reset; the retry budget is left as it was
+// With a version, the task is only released while it is still at that version
+func (s *Store) ReleaseTask(ctx context.Context, taskID int64, version *int64) error {
+	task, err := s.update(ctx, taskID, nil, func(task *models.Task) error {
+		if version != nil && task.Version != *version {
+			return storage.ErrVersionConflict
+		}
+		if task.Status != models.TaskStatusQuarantined {
+			return storage.ErrNotQuarantined
+		}
+
+		task.Status = models.TaskStatusQueued
+		task.CrashCount = 0
+		task.QuarantinedAt = nil
+		task.NextRunAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Best-effort history logging
+	history := models.TaskHistory{
+		TaskID:     taskID,
+		Status:     models.TaskStatusQueued,
+		EventType:  models.EventTaskReleased,
+		RetryCount: &task.RetryCount,
+		MaxRetries: &task.MaxRetries,
+		NextRunAt:  &task.NextRunAt,
+	}
+	if err := s.InsertHistory(ctx, history); err != nil {
+		slog.Error("Failed to insert release history", "task_id", taskID, "error", err)
+	}
+
+	// Wake up listening workers
+	s.notifyTaskCreated(ctx, taskID)
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package redis
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// GetQueueDepth returns the queued and claimable task counts of every task type with queued tasks
+// Reads every queued task, scheduled or already promoted to the ready set
+func (s *Store) GetQueueDepth(ctx context.Context) ([]models.QueueDepth, error) {
+	scheduled, err := s.client.ZRange(ctx, s.key("scheduled"), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	ready, err := s.client.ZRange(ctx, s.key("ready"), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	// Ready members are "<created_at>:<id>"
+	ids := scheduled
+	for _, member := range ready {
+		if _, id, ok := strings.Cut(member, ":"); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	tasks, err := s.loadTasks(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	byType := map[string]*models.QueueDepth{}
+	seen := map[int64]bool{}
+	for _, task := range tasks {
+		// Ready entries can be stale or duplicate a scheduled one until the next claim cleans them up
+		if task.Status != models.TaskStatusQueued || seen[task.ID] {
+			continue
+		}
+		seen[task.ID] = true
+
+		d, ok := byType[task.Type]
+		if !ok {
+			d = &models.QueueDepth{TaskType: task.Type}
+			byType[task.Type] = d
+		}
+		d.Queued++
+		if task.NextRunAt.After(now) {
+			continue
+		}
+		d.Claimable++
+		if d.OldestClaimableAt == nil || task.NextRunAt.Before(*d.OldestClaimableAt) {
+			nextRunAt := task.NextRunAt
+			d.OldestClaimableAt = &nextRunAt
+		}
+	}
+
+	depths := make([]models.QueueDepth, 0, len(byType))
+	for _, d := range byType {
+		if d.OldestClaimableAt != nil {
+			d.OldestAgeSeconds = max(0, now.Sub(*d.OldestClaimableAt).Seconds())
+		}
+		depths = append(depths, *d)
+	}
+	sort.Slice(depths, func(i, j int) bool { return depths[i].TaskType < depths[j].TaskType })
+
+	return depths, nil
+}
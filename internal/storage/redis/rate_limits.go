@@ -0,0 +1,86 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// ListRateLimits returns all configured per-type rate limits
+func (s *Store) ListRateLimits(ctx context.Context) ([]models.RateLimit, error) {
+	entries, err := s.client.HGetAll(ctx, s.key("rate_limits")).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	limits := make([]models.RateLimit, 0, len(entries))
+	for _, entry := range entries {
+		var l models.RateLimit
+		if err := json.Unmarshal([]byte(entry), &l); err != nil {
+			return nil, err
+		}
+		limits = append(limits, l)
+	}
+
+	sort.Slice(limits, func(i, j int) bool { return limits[i].TaskType < limits[j].TaskType })
+
+	return limits, nil
+}
+
+// getRateLimit returns the rate limit of a task type, or nil if it has none
+func (s *Store) getRateLimit(ctx context.Context, taskType string) (*models.RateLimit, error) {
+	entry, err := s.client.HGet(ctx, s.key("rate_limits"), taskType).Result()
+	if errors.Is(err, goredis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var l models.RateLimit
+	if err := json.Unmarshal([]byte(entry), &l); err != nil {
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+// SetRateLimit creates or updates the rate limit for a task type
+// Only tasks created afterwards get a key derived from the new key field
+func (s *Store) SetRateLimit(ctx context.Context, taskType string, req models.SetRateLimitRequest) error {
+	taskType = strings.ToLower(taskType)
+
+	entry, err := json.Marshal(models.RateLimit{
+		TaskType:      taskType,
+		KeyField:      req.KeyField,
+		MaxPerWindow:  req.MaxPerWindow,
+		WindowSeconds: req.WindowSeconds,
+		UpdatedAt:     time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.client.HSet(ctx, s.key("rate_limits"), taskType, entry).Err()
+}
+
+// DeleteRateLimit removes the rate limit for a task type
+func (s *Store) DeleteRateLimit(ctx context.Context, taskType string) error {
+	removed, err := s.client.HDel(ctx, s.key("rate_limits"), strings.ToLower(taskType)).Result()
+	if err != nil {
+		return err
+	}
+
+	if removed == 0 {
+		return storage.ErrRateLimitNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// lockExpiredError is recorded as last_error on tasks recovered by the reaper
+const lockExpiredError = "worker lock expired"
+
+// ReapExpiredLocks recovers running tasks whose lock has expired
+// Tasks with retries left are requeued immediately, the rest are marked failed
+// An expired lock counts as a crash, so tasks that keep killing their worker are quarantined
+// Each recovered task gets a worker_lock_expired (or task_quarantined) history event
+func (s *Store) ReapExpiredLocks(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	ids, err := s.client.ZRangeByScore(ctx, s.key("running"), &goredis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	reaped := 0
+	for _, member := range ids {
+		taskID, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		task, err := s.update(ctx, taskID, nil, func(task *models.Task) error {
+			// Claimed again or finished since the scan
+			if task.Status != models.TaskStatusRunning || task.LockExpiresAt == nil || task.LockExpiresAt.After(now) {
+				return errSkipUpdate
+			}
+
+			task.CrashCount++
+			switch {
+			case s.shouldQuarantine(task.CrashCount):
+				task.Status = models.TaskStatusQuarantined
+				task.QuarantinedAt = &now
+			case task.RetryCount < task.MaxRetries:
+				task.Status = models.TaskStatusQueued
+				task.RetryCount++
+			default:
+				task.Status = models.TaskStatusFailed
+			}
+
+			message := lockExpiredError
+			task.LastError = &message
+			task.NextRunAt = now
+			clearLock(task)
+			return nil
+		})
+		if errors.Is(err, storage.ErrTaskNotFound) {
+			// Stale entry of a task that no longer exists
+			s.client.ZRem(ctx, s.key("running"), member)
+			continue
+		}
+		if err != nil {
+			return reaped, err
+		}
+		if task == nil {
+			continue
+		}
+		reaped++
+
+		// Best-effort history logging
+		errorMessage := lockExpiredError
+		h := models.TaskHistory{
+			TaskID:       task.ID,
+			Status:       task.Status,
+			EventType:    models.EventWorkerLockExpired,
+			RetryCount:   &task.RetryCount,
+			MaxRetries:   &task.MaxRetries,
+			ErrorMessage: &errorMessage,
+		}
+		switch task.Status {
+		case models.TaskStatusQueued:
+			h.NextRunAt = &now
+		case models.TaskStatusQuarantined:
+			h.EventType = models.EventTaskQuarantined
+		}
+
+		if err := s.InsertHistory(ctx, h); err != nil {
+			slog.Error("Failed to insert lock expired history", "task_id", task.ID, "error", err)
+		}
+	}
+
+	return reaped, nil
+}
@@ -0,0 +1,170 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// redactScanCount is the SCAN batch size while looking for tasks to redact
+const redactScanCount = 500
+
+// RedactTask irreversibly scrubs the payload, rate limit key and error messages of a finished task
+// History keeps its entries with their error messages replaced, plus a task_redacted entry
+// Redacting a task again only repeats the history scrub, in case it failed the first time
+// With a version, the task is only redacted while it is still at that version
+func (s *Store) RedactTask(ctx context.Context, taskID int64, version *int64) (*models.Task, error) {
+	task, err := s.update(ctx, taskID, nil, func(task *models.Task) error {
+		if version != nil && task.Version != *version {
+			return storage.ErrVersionConflict
+		}
+		if !isFinished(task.Status) {
+			return storage.ErrTaskNotFinished
+		}
+		if task.RedactedAt != nil {
+			return errSkipUpdate
+		}
+
+		redact(task)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	first := task != nil
+	if !first {
+		if task, err = s.loadTask(ctx, s.client, taskID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.redactHistory(ctx, task, first); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// RedactTasks redacts every finished task matching req that is not redacted yet
+// Redis keeps no index by tenant or metadata, so this scans every task hash
+func (s *Store) RedactTasks(ctx context.Context, req models.RedactTasksRequest) (int, error) {
+	redacted := 0
+	iter := s.client.Scan(ctx, 0, s.prefix+"task:[0-9]*", redactScanCount).Iterator()
+
+	var ids []string
+	flush := func() error {
+		tasks, err := s.loadTasks(ctx, ids)
+		ids = ids[:0]
+		if err != nil {
+			return err
+		}
+
+		for _, task := range tasks {
+			if task.RedactedAt != nil || !isFinished(task.Status) || !matchesRedaction(task, req) {
+				continue
+			}
+			if _, err := s.RedactTask(ctx, task.ID, nil); err != nil {
+				if errors.Is(err, storage.ErrTaskNotFound) {
+					continue // expired meanwhile
+				}
+				return err
+			}
+			redacted++
+		}
+		return nil
+	}
+
+	for iter.Next(ctx) {
+		ids = append(ids, strings.TrimPrefix(iter.Val(), s.prefix+"task:"))
+		if len(ids) == redactScanCount {
+			if err := flush(); err != nil {
+				return redacted, err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return redacted, err
+	}
+
+	return redacted, flush()
+}
+
+// matchesRedaction reports whether a task matches every criterion of a bulk redaction
+func matchesRedaction(task *models.Task, req models.RedactTasksRequest) bool {
+	if req.Tenant != "" && task.Tenant != req.Tenant {
+		return false
+	}
+	for key, value := range req.Metadata {
+		if v, ok := task.Metadata[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// redact scrubs a task's personal data
+func redact(task *models.Task) {
+	task.Payload = json.RawMessage(models.RedactedPayload)
+	if task.LastError != nil {
+		message := models.RedactedMessage
+		task.LastError = &message
+	}
+	task.RateLimitKey = nil
+	task.PayloadSignature = ""
+	now := time.Now()
+	task.RedactedAt = &now
+}
+
+// redactHistory replaces the error messages in a redacted task's history in place, keeping its expiry,
+// recording a task_redacted entry the first time
+// Unlike other history writes it is not best-effort, so the caller can retry a failed redaction
+func (s *Store) redactHistory(ctx context.Context, task *models.Task, first bool) error {
+	key := s.historyKey(task.ID)
+	entries, err := s.client.LRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for i, entry := range entries {
+			var h models.TaskHistory
+			if err := json.Unmarshal([]byte(entry), &h); err != nil {
+				return fmt.Errorf("decode history of task %d: %w", task.ID, err)
+			}
+			if h.ErrorMessage == nil || *h.ErrorMessage == models.RedactedMessage {
+				continue
+			}
+
+			message := models.RedactedMessage
+			h.ErrorMessage = &message
+			scrubbed, err := json.Marshal(h)
+			if err != nil {
+				return err
+			}
+			pipe.LSet(ctx, key, int64(i), scrubbed)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !first {
+		return nil
+	}
+	return s.InsertHistory(ctx, models.TaskHistory{
+		TaskID:     task.ID,
+		Status:     task.Status,
+		EventType:  models.EventTaskRedacted,
+		RetryCount: &task.RetryCount,
+		MaxRetries: &task.MaxRetries,
+	})
+}
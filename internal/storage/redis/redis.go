@@ -0,0 +1,127 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Store implements the storage.Store interface on a single Redis instance
+// Claims run as one Lua script, so they are atomic and take a single round-trip
+// The script derives its keys from the prefix instead of declaring them, so Redis Cluster is not supported (see CheckServer)
+// Durability is whatever the Redis persistence settings give; tasks may be lost on a crash
+// Tenant quotas are not supported, so the API answers 501 for them and tenants go unlimited
+type Store struct {
+	client     *goredis.Client
+	prefix     string
+	jitterMode models.JitterMode
+	maxBackoff time.Duration
+
+	quarantineThreshold int
+	finishedTTL         time.Duration
+}
+
+// Config holds optional store behaviour settings
+type Config struct {
+	KeyPrefix  string            // Prefix of every key written by the store (default: "taskqueue:")
+	JitterMode models.JitterMode // Jitter applied to computed retry delays
+	MaxBackoff time.Duration     // Default cap for computed retry delays (tasks may override)
+
+	QuarantineThreshold int           // Crashes (panics, timeouts, expired locks) that quarantine a task (0 = disabled)
+	FinishedTTL         time.Duration // How long succeeded and failed tasks are kept (0 = forever)
+}
+
+// NewStore creates a new Redis store
+func NewStore(client *goredis.Client, config Config) *Store {
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = "taskqueue:"
+	}
+	if !config.JitterMode.IsValid() {
+		config.JitterMode = models.JitterProportional
+	}
+	if config.MaxBackoff == 0 {
+		config.MaxBackoff = 1 * time.Hour
+	}
+
+	return &Store{
+		client:     client,
+		prefix:     config.KeyPrefix,
+		jitterMode: config.JitterMode,
+		maxBackoff: config.MaxBackoff,
+
+		quarantineThreshold: config.QuarantineThreshold,
+		finishedTTL:         config.FinishedTTL,
+	}
+}
+
+// ErrClusterUnsupported is returned by CheckServer for a server running in cluster mode
+var ErrClusterUnsupported = errors.New("redis cluster is not supported: the claim script reads keys from every slot; use a single Redis server")
+
+// CheckServer verifies that client talks to a server the store can run on
+// Lua scripts must see the whole keyspace, which a cluster node does not have
+func CheckServer(ctx context.Context, client *goredis.Client) error {
+	info, err := client.Info(ctx, "cluster").Result()
+	if err != nil {
+		return err
+	}
+	if clusterEnabled(info) {
+		return ErrClusterUnsupported
+	}
+	return nil
+}
+
+// clusterEnabled reports whether the cluster section of INFO says cluster mode is on
+func clusterEnabled(info string) bool {
+	for _, line := range strings.Split(info, "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "cluster_enabled:"); ok {
+			return value == "1"
+		}
+	}
+	return false
+}
+
+// Ping checks that Redis is reachable
+func (s *Store) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+// key returns the prefixed name of a key
+func (s *Store) key(name string) string {
+	return s.prefix + name
+}
+
+// taskKey returns the name of the hash holding a task
+func (s *Store) taskKey(taskID int64) string {
+	return s.prefix + "task:" + strconv.FormatInt(taskID, 10)
+}
+
+// historyKey returns the name of the list holding a task's history
+func (s *Store) historyKey(taskID int64) string {
+	return s.prefix + "history:" + strconv.FormatInt(taskID, 10)
+}
+
+// publicIDKey is the key mapping a task's public UUID to its id
+func (s *Store) publicIDKey(publicID uuid.UUID) string {
+	return s.prefix + "task:public:" + publicID.String()
+}
+
+// typeCountsKey returns the name of the hash holding a task type's share of the counts hash
+func (s *Store) typeCountsKey(taskType string) string {
+	return s.prefix + "counts:type:" + taskType
+}
+
+// breakerKey returns the name of the hash holding a task type's circuit breaker
+func (s *Store) breakerKey(taskType string) string {
+	return s.prefix + "breaker:" + taskType
+}
+
+// alertStateKey returns the name of the hash holding an alert rule's evaluation state
+func (s *Store) alertStateKey(name string) string {
+	return s.prefix + "alert:" + name
+}
@@ -0,0 +1,217 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+)
+
+// failure is the outcome of a failed attempt, decided on the current task state
+type failure struct {
+	retryCount   int
+	timeoutCount int
+	crashCount   int
+	backoff      time.Duration
+	eventType    models.EventType
+	// exhausted is set when the task must fail permanently instead of being retried
+	exhausted string
+	// quarantine is set when the task crashed too often
+	quarantine bool
+}
+
+// ScheduleRetry marks a task for retry with exponential backoff
+// A positive retryAfter (suggested by the handler) is used instead of the computed backoff, up to the same cap
+// Only applies if the task is still held by the given lock
+func (s *Store) ScheduleRetry(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string, retryAfter time.Duration) error {
+	return s.fail(ctx, taskID, lock, errorMessage, func(task *models.Task) failure {
+		if task.RetryCount >= task.MaxRetries {
+			return failure{exhausted: "max retries exceeded"}
+		}
+
+		retryCount := task.RetryCount + 1
+		return failure{
+			retryCount:   retryCount,
+			timeoutCount: task.TimeoutCount,
+			crashCount:   task.CrashCount,
+			backoff:      storage.RetryDelay(task, retryCount, retryAfter, s.jitterMode, s.maxBackoff),
+			eventType:    models.EventRetryScheduled,
+		}
+	})
+}
+
+// RecordTimeout handles a task whose execution exceeded its timeout
+// If the task sets max_timeouts, timeouts use that budget and leave retry_count untouched;
+// otherwise they consume retries like any other error
+// Timeouts also count as crashes, so a task that keeps hanging its handler is quarantined
+// Only applies if the task is still held by the given lock
+func (s *Store) RecordTimeout(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string) error {
+	return s.fail(ctx, taskID, lock, errorMessage, func(task *models.Task) failure {
+		f := failure{
+			retryCount:   task.RetryCount,
+			timeoutCount: task.TimeoutCount + 1,
+			crashCount:   task.CrashCount + 1,
+			eventType:    models.EventTimeoutOccurred,
+		}
+		if s.shouldQuarantine(f.crashCount) {
+			f.quarantine = true
+			return f
+		}
+
+		attempt := f.timeoutCount
+		if task.MaxTimeouts != nil {
+			if task.TimeoutCount >= *task.MaxTimeouts {
+				f.exhausted = "max timeouts exceeded"
+			}
+		} else {
+			if task.RetryCount >= task.MaxRetries {
+				f.exhausted = "max retries exceeded"
+			}
+			f.retryCount++
+			attempt = f.retryCount
+		}
+
+		f.backoff = s.calculateBackoff(task, attempt)
+		return f
+	})
+}
+
+// RecordCrash handles a task whose handler panicked
+// Quarantines the task once crash_count reaches the quarantine threshold,
+// otherwise it is retried like any other failure
+// Only applies if the task is still held by the given lock
+func (s *Store) RecordCrash(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string) error {
+	return s.fail(ctx, taskID, lock, errorMessage, func(task *models.Task) failure {
+		f := failure{
+			retryCount:   task.RetryCount + 1,
+			timeoutCount: task.TimeoutCount,
+			crashCount:   task.CrashCount + 1,
+			eventType:    models.EventRetryScheduled,
+		}
+		if s.shouldQuarantine(f.crashCount) {
+			f.quarantine = true
+			return f
+		}
+
+		if task.RetryCount >= task.MaxRetries {
+			f.exhausted = "max retries exceeded"
+			return f
+		}
+
+		f.backoff = s.calculateBackoff(task, f.retryCount)
+		return f
+	})
+}
+
+// fail applies the failure decided by decide to a running task in a single update:
+// a retry after the backoff, quarantine, or a permanent failure once the budget is exhausted
+func (s *Store) fail(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string, decide func(task *models.Task) failure) error {
+	var (
+		outcome failure
+		before  models.Task
+	)
+	task, err := s.update(ctx, taskID, &lock, func(task *models.Task) error {
+		before = *task
+		outcome = decide(task)
+
+		clearLock(task)
+		switch {
+		case outcome.quarantine:
+			now := time.Now()
+			task.Status = models.TaskStatusQuarantined
+			task.CrashCount = outcome.crashCount
+			task.QuarantinedAt = &now
+			task.LastError = &errorMessage
+
+		case outcome.exhausted != "":
+			message := fmt.Sprintf("%s: %s", outcome.exhausted, errorMessage)
+			task.Status = models.TaskStatusFailed
+			task.LastError = &message
+
+		default:
+			task.Status = models.TaskStatusQueued
+			task.RetryCount = outcome.retryCount
+			task.TimeoutCount = outcome.timeoutCount
+			task.CrashCount = outcome.crashCount
+			task.LastError = &errorMessage
+			task.NextRunAt = time.Now().Add(outcome.backoff)
+			backoffSeconds := int(outcome.backoff / time.Second)
+			task.LastBackoffSeconds = &backoffSeconds
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Best-effort history logging, matching the events of the Postgres store
+	var history []models.TaskHistory
+	switch {
+	case outcome.quarantine:
+		slog.Warn("Task quarantined", "task_id", taskID, "task_type", task.Type, "crash_count", outcome.crashCount)
+		message := fmt.Sprintf("quarantined after %d crashes: %s", outcome.crashCount, errorMessage)
+		history = append(history, models.TaskHistory{
+			TaskID:       taskID,
+			Status:       models.TaskStatusQuarantined,
+			EventType:    models.EventTaskQuarantined,
+			RetryCount:   &before.RetryCount,
+			MaxRetries:   &before.MaxRetries,
+			ErrorMessage: &message,
+			WorkerID:     &lock.WorkerID,
+		})
+
+	case outcome.exhausted != "":
+		if outcome.eventType == models.EventTimeoutOccurred {
+			history = append(history, models.TaskHistory{
+				TaskID:       taskID,
+				Status:       models.TaskStatusFailed,
+				EventType:    models.EventTimeoutOccurred,
+				RetryCount:   &before.RetryCount,
+				MaxRetries:   &before.MaxRetries,
+				ErrorMessage: task.LastError,
+				WorkerID:     &lock.WorkerID,
+			})
+		}
+		history = append(history, models.TaskHistory{
+			TaskID:       taskID,
+			Status:       models.TaskStatusFailed,
+			EventType:    models.EventTaskFailedFinal,
+			ErrorMessage: task.LastError,
+			WorkerID:     &lock.WorkerID,
+		})
+
+	default:
+		history = append(history, models.TaskHistory{
+			TaskID:         taskID,
+			Status:         models.TaskStatusQueued,
+			EventType:      outcome.eventType,
+			RetryCount:     &task.RetryCount,
+			MaxRetries:     &task.MaxRetries,
+			BackoffSeconds: &task.BackoffSeconds,
+			NextRunAt:      &task.NextRunAt,
+			ErrorMessage:   &errorMessage,
+			WorkerID:       &lock.WorkerID,
+		})
+	}
+
+	for _, h := range history {
+		if err := s.InsertHistory(ctx, h); err != nil {
+			slog.Error("Failed to insert failure history", "task_id", taskID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// calculateBackoff computes the delay before a retry attempt with the configured jitter and cap
+func (s *Store) calculateBackoff(task *models.Task, retryCount int) time.Duration {
+	return storage.CalculateBackoff(task, retryCount, s.jitterMode, s.maxBackoff)
+}
+
+// shouldQuarantine reports whether a task with the given crash count must be quarantined
+func (s *Store) shouldQuarantine(crashCount int) bool {
+	return s.quarantineThreshold > 0 && crashCount >= s.quarantineThreshold
+}
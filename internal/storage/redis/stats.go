@@ -0,0 +1,86 @@
+package redis
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// GetStats retrieves system statistics for dashboard
+// Counters are maintained on every status change, so finished tasks still count after they expire
+func (s *Store) GetStats(ctx context.Context) (*models.TaskStatsResponse, error) {
+	counts, err := s.client.HGetAll(ctx, s.key("counts")).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	count := countReader(counts)
+
+	stats := models.TaskStatsResponse{
+		TotalTasks:       count("total"),
+		QueuedTasks:      count(string(models.TaskStatusQueued)),
+		RunningTasks:     count(string(models.TaskStatusRunning)),
+		SucceededTasks:   count(string(models.TaskStatusSucceeded)),
+		FailedTasks:      count(string(models.TaskStatusFailed)),
+		QuarantinedTasks: count(string(models.TaskStatusQuarantined)),
+		TasksWithRetries: count("with_retries"),
+	}
+	if stats.TotalTasks > 0 {
+		stats.AvgRetryCount = float64(count("retry_sum")) / float64(stats.TotalTasks)
+	}
+
+	return &stats, nil
+}
+
+// GetTypeStats returns task counts, failure rate and average retries per task type, ordered by type
+// Read from per-type counters, which only cover tasks created since they were introduced
+func (s *Store) GetTypeStats(ctx context.Context) ([]models.TypeStats, error) {
+	types, err := s.client.SMembers(ctx, s.key("types")).Result()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(types)
+
+	cmds := make([]*goredis.MapStringStringCmd, len(types))
+	_, err = s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for i, taskType := range types {
+			cmds[i] = pipe.HGetAll(ctx, s.typeCountsKey(taskType))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]models.TypeStats, 0, len(types))
+	for i, taskType := range types {
+		count := countReader(cmds[i].Val())
+		t := models.TypeStats{
+			TaskType:    taskType,
+			TotalTasks:  count("total"),
+			Queued:      count(string(models.TaskStatusQueued)),
+			Running:     count(string(models.TaskStatusRunning)),
+			Succeeded:   count(string(models.TaskStatusSucceeded)),
+			Failed:      count(string(models.TaskStatusFailed)),
+			Quarantined: count(string(models.TaskStatusQuarantined)),
+		}
+		t.FailureRate = models.FailureRate(t.Succeeded, t.Failed)
+		if t.TotalTasks > 0 {
+			t.AvgRetryCount = float64(count("retry_sum")) / float64(t.TotalTasks)
+		}
+		stats = append(stats, t)
+	}
+
+	return stats, nil
+}
+
+// countReader reads fields of a counts hash, missing fields as 0
+func countReader(counts map[string]string) func(field string) int64 {
+	return func(field string) int64 {
+		value, _ := strconv.ParseInt(counts[field], 10, 64)
+		return value
+	}
+}
@@ -0,0 +1,74 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage/storagetest"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// TestStoreConformance runs the storage conformance suite against TEST_REDIS_URL
+// Every test gets its own key prefix, which is deleted afterwards
+func TestStoreConformance(t *testing.T) {
+	url := os.Getenv("TEST_REDIS_URL")
+	if url == "" {
+		t.Skip("TEST_REDIS_URL not set")
+	}
+
+	opts, err := goredis.ParseURL(url)
+	if err != nil {
+		t.Fatalf("Invalid TEST_REDIS_URL: %v", err)
+	}
+	client := goredis.NewClient(opts)
+	t.Cleanup(func() { _ = client.Close() })
+	if err := CheckServer(context.Background(), client); err != nil {
+		t.Fatalf("CheckServer() error = %v", err)
+	}
+
+	storagetest.Run(t, func(t *testing.T) storage.Store {
+		prefix := fmt.Sprintf("taskqueue-test:%d:", time.Now().UnixNano())
+		t.Cleanup(func() {
+			ctx := context.Background()
+			iter := client.Scan(ctx, 0, prefix+"*", 100).Iterator()
+			for iter.Next(ctx) {
+				client.Del(ctx, iter.Val())
+			}
+		})
+
+		return NewStore(client, Config{KeyPrefix: prefix, QuarantineThreshold: 3})
+	})
+}
+
+func TestClusterEnabled(t *testing.T) {
+	tests := []struct {
+		name string
+		info string
+		want bool
+	}{
+		{"standalone", "# Cluster\r\ncluster_enabled:0\r\n", false},
+		{"cluster", "# Cluster\r\ncluster_enabled:1\r\n", true},
+		{"no cluster section", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clusterEnabled(tt.info); got != tt.want {
+				t.Errorf("clusterEnabled(%q) = %v, want %v", tt.info, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestStoreRejectsTenantQuotas keeps quota configuration answering 501 on Redis
+// The claim script has no fair-share step, so accepting quotas would leave max_running silently unenforced
+func TestStoreRejectsTenantQuotas(t *testing.T) {
+	var s storage.Store = NewStore(nil, Config{})
+	if _, ok := s.(storage.TenantQuotas); ok {
+		t.Fatal("Store implements storage.TenantQuotas, but the claim script does not enforce max_running")
+	}
+}
@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+)
+
+// ListTaskTypes returns every type handled by a worker seen within the retention period or registered by an operator
+func (s *Store) ListTaskTypes(ctx context.Context) ([]models.TaskTypeInfo, error) {
+	workers, err := s.loadWorkers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	registered, err := s.client.SMembers(ctx, s.key("task_types")).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	known := map[string]*models.TaskTypeInfo{}
+	lookup := func(taskType string) *models.TaskTypeInfo {
+		taskType = strings.ToLower(taskType)
+		if known[taskType] == nil {
+			known[taskType] = &models.TaskTypeInfo{TaskType: taskType}
+		}
+		return known[taskType]
+	}
+
+	cutoff := time.Now().Add(-workerRetention)
+	for _, w := range workers {
+		if w.LastSeen.Before(cutoff) {
+			continue
+		}
+		for _, taskType := range w.TaskTypes {
+			t := lookup(taskType)
+			t.Workers++
+			if t.LastSeen == nil || w.LastSeen.After(*t.LastSeen) {
+				lastSeen := w.LastSeen
+				t.LastSeen = &lastSeen
+			}
+		}
+	}
+	for _, taskType := range registered {
+		lookup(taskType).Registered = true
+	}
+
+	types := make([]models.TaskTypeInfo, 0, len(known))
+	for _, t := range known {
+		types = append(types, *t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].TaskType < types[j].TaskType })
+
+	return types, nil
+}
+
+// RegisterTaskType makes a task type known whether or not a worker reports it
+func (s *Store) RegisterTaskType(ctx context.Context, taskType string) error {
+	return s.client.SAdd(ctx, s.key("task_types"), strings.ToLower(taskType)).Err()
+}
+
+// DeregisterTaskType removes a task type registered by an operator
+func (s *Store) DeregisterTaskType(ctx context.Context, taskType string) error {
+	removed, err := s.client.SRem(ctx, s.key("task_types"), strings.ToLower(taskType)).Result()
+	if err != nil {
+		return err
+	}
+
+	if removed == 0 {
+		return storage.ErrTaskTypeNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,249 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// CreateTask creates a new task and schedules it for immediate execution
+func (s *Store) CreateTask(ctx context.Context, req models.CreateTaskRequest) (*models.Task, error) {
+	// Normalize task type to lowercase for consistent handling
+	req.Type = strings.ToLower(req.Type)
+
+	// Set defaults
+	maxRetries := 3
+	if req.MaxRetries != nil {
+		maxRetries = *req.MaxRetries
+	}
+
+	timeoutSeconds := 30
+	if req.TimeoutSeconds != nil {
+		timeoutSeconds = *req.TimeoutSeconds
+	}
+
+	backoffSeconds := 5
+	if req.BackoffSeconds != nil {
+		backoffSeconds = *req.BackoffSeconds
+	}
+
+	retryStrategy := models.RetryStrategyExponential
+	if req.RetryStrategy != "" {
+		retryStrategy = req.RetryStrategy
+	}
+
+	retrySchedule, err := models.ParseRetrySchedule(req.RetrySchedule)
+	if err != nil {
+		return nil, err
+	}
+
+	// Default payload to empty JSON object if not provided
+	payload := req.Payload
+	if len(payload) == 0 {
+		payload = []byte("{}")
+	}
+
+	// Explicit rate limit keys are scoped to the task type, like derived ones
+	rateLimitKey, err := s.rateLimitKey(ctx, req.Type, req.RateLimitKey, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.client.Incr(ctx, s.key("task:seq")).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	// Signed requests carry the id their signature covers
+	publicID := req.PublicID
+	if publicID == uuid.Nil {
+		if publicID, err = uuid.NewV7(); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	task := &models.Task{
+		ID:                id,
+		PublicID:          publicID,
+		Name:              req.Name,
+		Type:              req.Type,
+		Payload:           payload,
+		Status:            models.TaskStatusQueued,
+		Priority:          req.Priority,
+		Tenant:            req.Tenant,
+		RequiredLabels:    req.RequiredLabels,
+		Metadata:          req.Metadata,
+		MaxRetries:        maxRetries,
+		NextRunAt:         now, // available immediately
+		BackoffSeconds:    backoffSeconds,
+		RetryStrategy:     retryStrategy,
+		RetrySchedule:     retrySchedule,
+		MaxBackoffSeconds: req.MaxBackoffSeconds,
+		TimeoutSeconds:    timeoutSeconds,
+		MaxTimeouts:       req.MaxTimeouts,
+		RateLimitKey:      rateLimitKey,
+		PayloadSignature:  req.PayloadSignature,
+		Version:           1,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	if task.RequiredLabels == nil {
+		task.RequiredLabels = map[string]string{}
+	}
+	if task.Metadata == nil {
+		task.Metadata = map[string]string{}
+	}
+
+	fields, err := taskFields(task)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.HSet(ctx, s.taskKey(id), fields)
+		pipe.Set(ctx, s.publicIDKey(publicID), id, 0)
+		pipe.ZAdd(ctx, s.key("scheduled"), goredis.Z{Score: float64(now.UnixMilli()), Member: strconv.FormatInt(id, 10)})
+		pipe.HIncrBy(ctx, s.key("counts"), "total", 1)
+		pipe.HIncrBy(ctx, s.key("counts"), string(models.TaskStatusQueued), 1)
+		pipe.SAdd(ctx, s.key("types"), task.Type)
+		pipe.HIncrBy(ctx, s.typeCountsKey(task.Type), "total", 1)
+		pipe.HIncrBy(ctx, s.typeCountsKey(task.Type), string(models.TaskStatusQueued), 1)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Best-effort history logging - don't fail task creation if history insert fails
+	history := models.TaskHistory{
+		TaskID:         task.ID,
+		Status:         models.TaskStatusQueued,
+		EventType:      models.EventTaskQueued,
+		RetryCount:     &task.RetryCount,
+		MaxRetries:     &task.MaxRetries,
+		BackoffSeconds: &task.BackoffSeconds,
+		NextRunAt:      &task.NextRunAt,
+	}
+
+	if err := s.InsertHistory(ctx, history); err != nil {
+		slog.Error("Failed to insert task creation history", "task_id", task.ID, "error", err)
+	}
+
+	// Wake up listening workers
+	s.notifyTaskCreated(ctx, task.ID)
+
+	return task, nil
+}
+
+// rateLimitKey returns the explicit key, or derives one from the payload field configured for the type's rate limit
+func (s *Store) rateLimitKey(ctx context.Context, taskType string, explicit *string, payload json.RawMessage) (*string, error) {
+	if explicit != nil && *explicit != "" {
+		key := taskType + ":" + *explicit
+		return &key, nil
+	}
+
+	limit, err := s.getRateLimit(ctx, taskType)
+	if err != nil || limit == nil {
+		return nil, err
+	}
+
+	value, ok := storage.PayloadKey(payload, limit.KeyField)
+	if !ok {
+		return nil, nil
+	}
+
+	key := taskType + ":" + value
+	return &key, nil
+}
+
+// CreateTasks creates many tasks one after another, stopping at the first failure
+// Tasks created before a failure are kept
+func (s *Store) CreateTasks(ctx context.Context, reqs []models.CreateTaskRequest) ([]*models.Task, error) {
+	tasks := make([]*models.Task, 0, len(reqs))
+	for _, req := range reqs {
+		task, err := s.CreateTask(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// GetTask retrieves a task by its ID
+func (s *Store) GetTask(ctx context.Context, id int64) (*models.Task, error) {
+	return s.loadTask(ctx, s.client, id)
+}
+
+// GetTaskByPublicID retrieves a task by its public UUID
+func (s *Store) GetTaskByPublicID(ctx context.Context, publicID uuid.UUID) (*models.Task, error) {
+	id, err := s.client.Get(ctx, s.publicIDKey(publicID)).Int64()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return nil, storage.ErrTaskNotFound
+		}
+		return nil, err
+	}
+	return s.loadTask(ctx, s.client, id)
+}
+
+// loadTasks reads several task hashes in one round-trip, skipping tasks that no longer exist
+func (s *Store) loadTasks(ctx context.Context, ids []string) ([]*models.Task, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	cmds := make([]*goredis.MapStringStringCmd, len(ids))
+	_, err := s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for i, id := range ids {
+			cmds[i] = pipe.HGetAll(ctx, s.prefix+"task:"+id)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return nil, err
+	}
+
+	tasks := make([]*models.Task, 0, len(ids))
+	for _, cmd := range cmds {
+		fields, err := cmd.Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) == 0 {
+			continue // expired
+		}
+
+		task, err := decodeTask(fields)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// UpdateTaskStatus updates the status of a task
+// With a version, the update only applies while the task is still at that version
+func (s *Store) UpdateTaskStatus(ctx context.Context, taskID int64, status models.TaskStatus, errorMessage *string, version *int64) error {
+	_, err := s.update(ctx, taskID, nil, func(task *models.Task) error {
+		if version != nil && task.Version != *version {
+			return storage.ErrVersionConflict
+		}
+		task.Status = status
+		task.LastError = errorMessage
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,176 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// maxUpdateAttempts bounds optimistic retries when a task changes while it is being updated
+const maxUpdateAttempts = 10
+
+// errSkipUpdate tells update to leave the task unchanged without reporting an error
+var errSkipUpdate = errors.New("skip update")
+
+// update applies fn to a task and writes it back together with its index entries, bumping its version
+// The task is watched, so a concurrent claim or update makes the write retry with fresh state
+// With a lock, only the holder of that lock may update the task (ErrLockLost otherwise)
+// Returns nil without writing if fn returns errSkipUpdate
+func (s *Store) update(ctx context.Context, taskID int64, lock *models.TaskLock, fn func(task *models.Task) error) (*models.Task, error) {
+	return s.write(ctx, taskID, lock, true, fn)
+}
+
+// write is update with the version bump optional, for lock extensions that must not make versions stale
+func (s *Store) write(ctx context.Context, taskID int64, lock *models.TaskLock, bumpVersion bool, fn func(task *models.Task) error) (*models.Task, error) {
+	key := s.taskKey(taskID)
+
+	var updated *models.Task
+	txf := func(tx *goredis.Tx) error {
+		updated = nil
+
+		task, err := s.loadTask(ctx, tx, taskID)
+		if err != nil {
+			return err
+		}
+
+		if lock != nil && !holdsLock(task, *lock) {
+			return storage.ErrLockLost
+		}
+
+		previous := *task
+		if err := fn(task); err != nil {
+			return err
+		}
+		task.UpdatedAt = time.Now()
+		if bumpVersion {
+			task.Version++
+		}
+
+		fields, err := taskFields(task)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+			pipe.HSet(ctx, key, fields)
+			s.reindex(ctx, pipe, &previous, task)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		updated = task
+		return nil
+	}
+
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		err := s.client.Watch(ctx, txf, key)
+		if errors.Is(err, goredis.TxFailedErr) {
+			continue
+		}
+		if errors.Is(err, errSkipUpdate) {
+			return nil, nil
+		}
+		return updated, err
+	}
+
+	return nil, goredis.TxFailedErr
+}
+
+// loadTask reads a task hash
+func (s *Store) loadTask(ctx context.Context, client goredis.Cmdable, taskID int64) (*models.Task, error) {
+	fields, err := client.HGetAll(ctx, s.taskKey(taskID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fields) == 0 {
+		return nil, storage.ErrTaskNotFound
+	}
+
+	return decodeTask(fields)
+}
+
+// holdsLock reports whether the task is running under the given lock
+func holdsLock(task *models.Task, lock models.TaskLock) bool {
+	return task.Status == models.TaskStatusRunning &&
+		task.LockedBy != nil && *task.LockedBy == lock.WorkerID &&
+		task.LockToken == lock.Token
+}
+
+// reindex moves a task between the status indexes after it changed from previous to task
+// The claim script performs the same bookkeeping for the queued to running transition
+func (s *Store) reindex(ctx context.Context, pipe goredis.Pipeliner, previous, task *models.Task) {
+	id := strconv.FormatInt(task.ID, 10)
+
+	// Every count is kept overall and per task type
+	for _, counts := range []string{s.key("counts"), s.typeCountsKey(task.Type)} {
+		if previous.Status != task.Status {
+			pipe.HIncrBy(ctx, counts, string(previous.Status), -1)
+			pipe.HIncrBy(ctx, counts, string(task.Status), 1)
+		}
+		if delta := task.RetryCount - previous.RetryCount; delta != 0 {
+			pipe.HIncrBy(ctx, counts, "retry_sum", int64(delta))
+		}
+		if previous.RetryCount == 0 && task.RetryCount > 0 {
+			pipe.HIncrBy(ctx, counts, "with_retries", 1)
+		} else if previous.RetryCount > 0 && task.RetryCount == 0 {
+			pipe.HIncrBy(ctx, counts, "with_retries", -1)
+		}
+	}
+
+	// Leave the indexes of the previous status
+	switch previous.Status {
+	case models.TaskStatusQueued:
+		pipe.ZRem(ctx, s.key("scheduled"), id)
+		pipe.ZRem(ctx, s.key("ready"), rankMember(previous))
+	case models.TaskStatusRunning:
+		pipe.ZRem(ctx, s.key("running"), id)
+		pipe.HIncrBy(ctx, s.key("running_by_type"), previous.Type, -1)
+	case models.TaskStatusQuarantined:
+		pipe.ZRem(ctx, s.key("quarantined"), id)
+	}
+
+	// Enter the indexes of the new status
+	switch task.Status {
+	case models.TaskStatusQueued:
+		pipe.ZAdd(ctx, s.key("scheduled"), goredis.Z{Score: float64(task.NextRunAt.UnixMilli()), Member: id})
+	case models.TaskStatusRunning:
+		var expiresAt int64
+		if task.LockExpiresAt != nil {
+			expiresAt = task.LockExpiresAt.UnixMilli()
+		}
+		pipe.ZAdd(ctx, s.key("running"), goredis.Z{Score: float64(expiresAt), Member: id})
+		pipe.HIncrBy(ctx, s.key("running_by_type"), task.Type, 1)
+	case models.TaskStatusQuarantined:
+		var quarantinedAt int64
+		if task.QuarantinedAt != nil {
+			quarantinedAt = task.QuarantinedAt.UnixMilli()
+		}
+		pipe.ZAdd(ctx, s.key("quarantined"), goredis.Z{Score: float64(quarantinedAt), Member: id})
+	}
+
+	// Finished tasks expire; a task that leaves a final status is kept again
+	if previous.Status != task.Status && s.finishedTTL > 0 {
+		if isFinished(task.Status) {
+			pipe.Expire(ctx, s.taskKey(task.ID), s.finishedTTL)
+			pipe.Expire(ctx, s.historyKey(task.ID), s.finishedTTL)
+			pipe.Expire(ctx, s.publicIDKey(task.PublicID), s.finishedTTL)
+		} else if isFinished(previous.Status) {
+			pipe.Persist(ctx, s.taskKey(task.ID))
+			pipe.Persist(ctx, s.historyKey(task.ID))
+			pipe.Persist(ctx, s.publicIDKey(task.PublicID))
+		}
+	}
+}
+
+// isFinished reports whether a status is final
+func isFinished(status models.TaskStatus) bool {
+	return status == models.TaskStatusSucceeded || status == models.TaskStatusFailed
+}
@@ -0,0 +1,91 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+)
+
+// GetWorkerSettings returns the effective runtime overrides for a worker
+// Each field falls back from the worker's own entry to the default entry; unset fields are nil
+func (s *Store) GetWorkerSettings(ctx context.Context, workerID string) (*models.WorkerSettings, error) {
+	entries, err := s.client.HMGet(ctx, s.key("worker_settings"), workerID, models.DefaultWorkerSettings).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	settings := models.WorkerSettings{WorkerID: workerID}
+	for _, entry := range entries {
+		raw, ok := entry.(string)
+		if !ok {
+			continue
+		}
+
+		var ws models.WorkerSettings
+		if err := json.Unmarshal([]byte(raw), &ws); err != nil {
+			return nil, err
+		}
+		if settings.MaxConcurrency == nil {
+			settings.MaxConcurrency = ws.MaxConcurrency
+		}
+		if settings.PollIntervalSeconds == nil {
+			settings.PollIntervalSeconds = ws.PollIntervalSeconds
+		}
+	}
+
+	return &settings, nil
+}
+
+// ListWorkerSettings returns all runtime overrides
+func (s *Store) ListWorkerSettings(ctx context.Context) ([]models.WorkerSettings, error) {
+	entries, err := s.client.HGetAll(ctx, s.key("worker_settings")).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make([]models.WorkerSettings, 0, len(entries))
+	for _, entry := range entries {
+		var ws models.WorkerSettings
+		if err := json.Unmarshal([]byte(entry), &ws); err != nil {
+			return nil, err
+		}
+		settings = append(settings, ws)
+	}
+
+	sort.Slice(settings, func(i, j int) bool { return settings[i].WorkerID < settings[j].WorkerID })
+
+	return settings, nil
+}
+
+// SetWorkerSettings creates or replaces the runtime overrides for a worker
+func (s *Store) SetWorkerSettings(ctx context.Context, workerID string, req models.SetWorkerSettingsRequest) error {
+	entry, err := json.Marshal(models.WorkerSettings{
+		WorkerID:            workerID,
+		MaxConcurrency:      req.MaxConcurrency,
+		PollIntervalSeconds: req.PollIntervalSeconds,
+		UpdatedAt:           time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.client.HSet(ctx, s.key("worker_settings"), workerID, entry).Err()
+}
+
+// DeleteWorkerSettings removes the runtime overrides for a worker
+func (s *Store) DeleteWorkerSettings(ctx context.Context, workerID string) error {
+	removed, err := s.client.HDel(ctx, s.key("worker_settings"), workerID).Result()
+	if err != nil {
+		return err
+	}
+
+	if removed == 0 {
+		return storage.ErrWorkerSettingsNotFound
+	}
+
+	return nil
+}
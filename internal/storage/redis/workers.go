@@ -0,0 +1,162 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// workerRetention is how long workers that stopped heartbeating stay listed
+const workerRetention = 24 * time.Hour
+
+// RegisterWorker records a starting worker and prunes workers not seen within the retention period
+func (s *Store) RegisterWorker(ctx context.Context, worker models.WorkerInfo) error {
+	workers, err := s.loadWorkers(ctx)
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-workerRetention)
+	for _, w := range workers {
+		if w.LastSeen.Before(cutoff) {
+			if err := s.client.HDel(ctx, s.key("workers"), w.ID).Err(); err != nil {
+				return err
+			}
+		}
+	}
+
+	now := time.Now()
+	worker.StartedAt = now
+	worker.LastSeen = now
+	worker.StoppedAt = nil
+
+	return s.saveWorker(ctx, worker)
+}
+
+// HeartbeatWorker refreshes a worker's last_seen, current load and task totals
+func (s *Store) HeartbeatWorker(ctx context.Context, workerID string, heartbeat models.WorkerHeartbeat) error {
+	return s.updateWorker(ctx, workerID, func(w *models.WorkerInfo) {
+		w.Concurrency = heartbeat.Concurrency
+		w.InFlight = heartbeat.InFlight
+		w.WorkerCounters = heartbeat.WorkerCounters
+		w.LastSeen = time.Now()
+	})
+}
+
+// DeregisterWorker marks a worker as stopped after a graceful shutdown
+func (s *Store) DeregisterWorker(ctx context.Context, workerID string) error {
+	return s.updateWorker(ctx, workerID, func(w *models.WorkerInfo) {
+		now := time.Now()
+		w.InFlight = 0
+		w.LastSeen = now
+		w.StoppedAt = &now
+	})
+}
+
+// updateWorker applies fn to a registered worker; unknown workers are ignored
+func (s *Store) updateWorker(ctx context.Context, workerID string, fn func(w *models.WorkerInfo)) error {
+	entry, err := s.client.HGet(ctx, s.key("workers"), workerID).Result()
+	if errors.Is(err, goredis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var w models.WorkerInfo
+	if err := json.Unmarshal([]byte(entry), &w); err != nil {
+		return err
+	}
+	fn(&w)
+
+	return s.saveWorker(ctx, w)
+}
+
+// saveWorker writes a worker entry
+func (s *Store) saveWorker(ctx context.Context, w models.WorkerInfo) error {
+	entry, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+
+	return s.client.HSet(ctx, s.key("workers"), w.ID, entry).Err()
+}
+
+// loadWorkers reads every worker entry
+func (s *Store) loadWorkers(ctx context.Context) ([]models.WorkerInfo, error) {
+	entries, err := s.client.HGetAll(ctx, s.key("workers")).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	workers := make([]models.WorkerInfo, 0, len(entries))
+	for _, entry := range entries {
+		var w models.WorkerInfo
+		if err := json.Unmarshal([]byte(entry), &w); err != nil {
+			return nil, err
+		}
+		workers = append(workers, w)
+	}
+
+	return workers, nil
+}
+
+// ListWorkers returns registered workers with the number of task locks each holds
+// Workers without a heartbeat within staleAfter are reported as stale
+func (s *Store) ListWorkers(ctx context.Context, staleAfter time.Duration) ([]models.WorkerInfo, error) {
+	workers, err := s.loadWorkers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Count locks from the running tasks
+	ids, err := s.client.ZRange(ctx, s.key("running"), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	cmds := make([]*goredis.StringCmd, len(ids))
+	_, err = s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for i, id := range ids {
+			cmds[i] = pipe.HGet(ctx, s.prefix+"task:"+id, "locked_by")
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return nil, err
+	}
+	locked := map[string]int64{}
+	for _, cmd := range cmds {
+		if owner, err := cmd.Result(); err == nil && owner != "" {
+			locked[owner]++
+		}
+	}
+
+	staleBefore := time.Now().Add(-staleAfter)
+	for i := range workers {
+		w := &workers[i]
+		switch {
+		case w.StoppedAt != nil:
+			w.Status = models.WorkerStatusStopped
+		case w.LastSeen.Before(staleBefore):
+			w.Status = models.WorkerStatusStale
+		default:
+			w.Status = models.WorkerStatusActive
+		}
+		w.LockedTasks = locked[w.ID]
+	}
+
+	// Running workers first, most recently seen first
+	sort.Slice(workers, func(i, j int) bool {
+		if (workers[i].StoppedAt == nil) != (workers[j].StoppedAt == nil) {
+			return workers[i].StoppedAt == nil
+		}
+		return workers[i].LastSeen.After(workers[j].LastSeen)
+	})
+
+	return workers, nil
+}
@@ -2,48 +2,390 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/google/uuid"
 )
 
 // Common errors
 var (
-	ErrTaskNotFound = errors.New("task not found")
+	ErrTaskNotFound    = errors.New("task not found")
+	ErrLockLost        = errors.New("task lock lost")
+	ErrNotQuarantined  = errors.New("task is not quarantined")
+	ErrTaskNotFinished = errors.New("task is not finished")
+
+	// ErrVersionConflict is returned when a task changed since the version the caller read
+	ErrVersionConflict = errors.New("task version conflict")
+
+	ErrConcurrencyLimitNotFound = errors.New("concurrency limit not found")
+	ErrRateLimitNotFound        = errors.New("rate limit not found")
+	ErrCircuitBreakerNotFound   = errors.New("circuit breaker not found")
+	ErrWorkerSettingsNotFound   = errors.New("worker settings not found")
+	ErrAlertRuleNotFound        = errors.New("alert rule not found")
+	ErrAPIKeyNotFound           = errors.New("api key not found")
+	ErrTenantQuotaNotFound      = errors.New("tenant quota not found")
+	ErrTaskResultNotFound       = errors.New("task result not found")
+	ErrTaskSchemaNotFound       = errors.New("task schema not found")
+	ErrTaskTypeNotFound         = errors.New("task type not found")
+	ErrNotPaused                = errors.New("not paused")
+
+	// ErrUnavailable wraps errors caused by the backend being unreachable, restarting or failing over
+	ErrUnavailable = errors.New("storage unavailable")
 )
 
+// QuotaExceededError is returned when creating tasks would take a tenant past its max_queued quota
+type QuotaExceededError struct {
+	Tenant    string
+	MaxQueued int
+	Queued    int64 // tasks the tenant has queued already
+}
+
+// Error describes the exceeded quota
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("tenant %s quota exceeded: %d of %d tasks queued", e.Tenant, e.Queued, e.MaxQueued)
+}
+
 // Store defines the interface for task storage operations
 // This allows for different implementations (PostgreSQL, in-memory, etc.)
 type Store interface {
 	// CreateTask creates a new task and returns it
 	CreateTask(ctx context.Context, req models.CreateTaskRequest) (*models.Task, error)
 
+	// CreateTasks creates many tasks at once and returns them in request order
+	// PostgreSQL creates all or none; Redis stops at the first failure
+	CreateTasks(ctx context.Context, reqs []models.CreateTaskRequest) ([]*models.Task, error)
+
 	// GetTask retrieves a task by its ID
 	GetTask(ctx context.Context, id int64) (*models.Task, error)
 
+	// GetTaskByPublicID retrieves a task by the UUID the API exposes as its id
+	GetTaskByPublicID(ctx context.Context, publicID uuid.UUID) (*models.Task, error)
+
 	// GetTaskHistory retrieves the status change history for a task
-	GetTaskHistory(ctx context.Context, taskID int64) ([]models.TaskHistory, error)
+	// since bounds the search and should be the task's creation time; the zero time searches everything
+	GetTaskHistory(ctx context.Context, taskID int64, since time.Time) ([]models.TaskHistory, error)
 
 	// InsertHistory adds a new detailed event entry to task history
 	InsertHistory(ctx context.Context, history models.TaskHistory) error
 
 	// UpdateTaskStatus updates the status of a task
-	UpdateTaskStatus(ctx context.Context, taskID int64, status models.TaskStatus, errorMessage *string) error
+	// With a version, returns ErrVersionConflict if the task was updated since that version was read
+	UpdateTaskStatus(ctx context.Context, taskID int64, status models.TaskStatus, errorMessage *string, version *int64) error
 
 	// ClaimNextTask atomically claims the next available task for processing
 	// Handles timeout recovery and respects next_run_at scheduling
 	// Returns nil if no tasks are available
 	ClaimNextTask(ctx context.Context, workerID string) (*models.Task, error)
 
+	// ClaimNextTasks atomically claims up to n available tasks matching filter in a single query
+	// Records a worker_lock_acquired history entry for every claimed task
+	// Returns an empty slice if no tasks are available
+	ClaimNextTasks(ctx context.Context, workerID string, n int, filter models.ClaimFilter) ([]*models.Task, error)
+
+	// ExtendLock extends the lock of a running task by the given duration
+	// Used by worker heartbeats to keep long-running tasks from being re-claimed
+	// Leaves the task's version unchanged, so a heartbeat does not turn a client's If-Match stale
+	// Returns ErrLockLost if the task is no longer held by the given lock
+	ExtendLock(ctx context.Context, taskID int64, lock models.TaskLock, extendBy time.Duration) error
+
+	// ReapExpiredLocks recovers running tasks whose lock has expired
+	// Returns the number of tasks that were requeued or failed
+	ReapExpiredLocks(ctx context.Context) (int, error)
+
 	// ScheduleRetry marks a task for retry with exponential backoff
-	ScheduleRetry(ctx context.Context, taskID int64, errorMessage string) error
+	// A positive retryAfter replaces the computed backoff for this attempt
+	// Returns ErrLockLost if the task is no longer held by the given lock
+	ScheduleRetry(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string, retryAfter time.Duration) error
+
+	// RecordTimeout handles a task that exceeded its timeout
+	// Retries it under the timeout budget (or the retry budget) and records a timeout_occurred event
+	// Returns ErrLockLost if the task is no longer held by the given lock
+	RecordTimeout(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string) error
+
+	// RequeueTask returns a running task to the queue without incrementing retry_count
+	// Returns ErrLockLost if the task is no longer held by the given lock
+	RequeueTask(ctx context.Context, taskID int64, lock models.TaskLock) error
+
+	// RecordCrash handles a task whose handler panicked
+	// Quarantines the task once it has crashed too often, otherwise schedules a retry
+	// Returns ErrLockLost if the task is no longer held by the given lock
+	RecordCrash(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string) error
+
+	// ListQuarantinedTasks returns quarantined tasks, most recently quarantined first
+	ListQuarantinedTasks(ctx context.Context, limit int) ([]*models.Task, error)
+
+	// ReleaseTask returns a quarantined task to the queue with its crash count reset
+	// Returns ErrNotQuarantined if the task exists but is not quarantined
+	// With a version, returns ErrVersionConflict if the task was updated since that version was read
+	ReleaseTask(ctx context.Context, taskID int64, version *int64) error
+
+	// RedactTask irreversibly scrubs the payload, rate limit key and error messages of a finished task
+	// and of its history, recording a task_redacted event; redacting a task again changes nothing
+	// Returns ErrTaskNotFinished if the task exists but has not succeeded or failed
+	// With a version, returns ErrVersionConflict if the task was updated since that version was read
+	RedactTask(ctx context.Context, taskID int64, version *int64) (*models.Task, error)
+
+	// RedactTasks redacts every finished task matching req that is not redacted yet
+	// Returns the number of tasks redacted
+	RedactTasks(ctx context.Context, req models.RedactTasksRequest) (int, error)
 
 	// MarkTaskFailed permanently marks a task as failed (no more retries)
-	MarkTaskFailed(ctx context.Context, taskID int64, errorMessage string) error
+	// Returns ErrLockLost if the task is no longer held by the given lock
+	MarkTaskFailed(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string) error
 
 	// CompleteTask marks a task as succeeded
-	CompleteTask(ctx context.Context, taskID int64) error
+	// Returns ErrLockLost if the task is no longer held by the given lock
+	CompleteTask(ctx context.Context, taskID int64, lock models.TaskLock) error
+
+	// Pause stops claiming of a task type, or of every type for models.PauseScopeQueue
+	// Pausing an already paused scope updates its reason; both are recorded in the audit trail
+	Pause(ctx context.Context, scope string, reason *string) error
+
+	// Resume lifts a pause and records it in the audit trail
+	// Returns ErrNotPaused if the scope is not paused
+	Resume(ctx context.Context, scope string, reason *string) error
+
+	// ListPauses returns the active pauses
+	ListPauses(ctx context.Context) ([]models.QueuePause, error)
+
+	// ListPauseHistory returns the most recent pause and resume actions, newest first
+	ListPauseHistory(ctx context.Context, limit int) ([]models.PauseEvent, error)
+
+	// GetMaintenance returns the queue-wide maintenance mode
+	GetMaintenance(ctx context.Context) (*models.MaintenanceMode, error)
+
+	// SetMaintenance toggles maintenance mode and returns the new state
+	SetMaintenance(ctx context.Context, req models.SetMaintenanceRequest) (*models.MaintenanceMode, error)
+
+	// RegisterWorker records a starting worker
+	RegisterWorker(ctx context.Context, worker models.WorkerInfo) error
+
+	// HeartbeatWorker refreshes a worker's last_seen, current load and task totals
+	HeartbeatWorker(ctx context.Context, workerID string, heartbeat models.WorkerHeartbeat) error
+
+	// DeregisterWorker marks a worker as stopped after a graceful shutdown
+	DeregisterWorker(ctx context.Context, workerID string) error
+
+	// ListWorkers returns registered workers; those silent for staleAfter are reported as stale
+	ListWorkers(ctx context.Context, staleAfter time.Duration) ([]models.WorkerInfo, error)
+
+	// Ping checks that the underlying database is reachable
+	Ping(ctx context.Context) error
 
 	// GetStats retrieves system statistics for dashboard
 	GetStats(ctx context.Context) (*models.TaskStatsResponse, error)
+
+	// GetTypeStats returns task counts, failure rate and average retries per task type, ordered by type
+	GetTypeStats(ctx context.Context) ([]models.TypeStats, error)
+
+	// GetQueueDepth returns the queued and claimable task counts of every task type with queued tasks
+	GetQueueDepth(ctx context.Context) ([]models.QueueDepth, error)
+
+	// ListConcurrencyLimits returns all per-type concurrency limits with current usage
+	ListConcurrencyLimits(ctx context.Context) ([]models.ConcurrencyLimit, error)
+
+	// SetConcurrencyLimit caps the number of running tasks of a type across all workers
+	SetConcurrencyLimit(ctx context.Context, taskType string, maxRunning int) error
+
+	// DeleteConcurrencyLimit removes the cap for a task type
+	DeleteConcurrencyLimit(ctx context.Context, taskType string) error
+
+	// ListRateLimits returns all per-type rate limits
+	ListRateLimits(ctx context.Context) ([]models.RateLimit, error)
+
+	// SetRateLimit limits how many tasks sharing a payload-derived key may start per window
+	SetRateLimit(ctx context.Context, taskType string, req models.SetRateLimitRequest) error
+
+	// DeleteRateLimit removes the rate limit for a task type
+	DeleteRateLimit(ctx context.Context, taskType string) error
+
+	// RecordTypeFailure counts a failure against the task type's circuit breaker
+	// Opens the breaker for cooldown once threshold consecutive failures are reached
+	// Returns true if this failure opened the breaker
+	RecordTypeFailure(ctx context.Context, taskType string, errorMessage string, threshold int, cooldown time.Duration) (bool, error)
+
+	// RecordTypeSuccess resets the consecutive failure count of the task type
+	RecordTypeSuccess(ctx context.Context, taskType string) error
+
+	// ListCircuitBreakers returns the circuit breaker state of every task type that has failed
+	ListCircuitBreakers(ctx context.Context) ([]models.CircuitBreaker, error)
+
+	// OpenCircuitBreaker pauses claiming of a task type for cooldown
+	OpenCircuitBreaker(ctx context.Context, taskType string, cooldown time.Duration) error
+
+	// CloseCircuitBreaker resumes claiming of a task type and resets its failure count
+	CloseCircuitBreaker(ctx context.Context, taskType string) error
+
+	// GetWorkerSettings returns the effective runtime overrides for a worker
+	// Falls back to the default row per field; fields without an override are nil
+	GetWorkerSettings(ctx context.Context, workerID string) (*models.WorkerSettings, error)
+
+	// ListWorkerSettings returns all runtime overrides
+	ListWorkerSettings(ctx context.Context) ([]models.WorkerSettings, error)
+
+	// SetWorkerSettings creates or replaces the runtime overrides for a worker
+	SetWorkerSettings(ctx context.Context, workerID string, req models.SetWorkerSettingsRequest) error
+
+	// DeleteWorkerSettings removes the runtime overrides for a worker
+	DeleteWorkerSettings(ctx context.Context, workerID string) error
+
+	// ListAlertRules returns all alert rules with their last evaluation, ordered by name
+	ListAlertRules(ctx context.Context) ([]models.AlertRule, error)
+
+	// SetAlertRule creates or replaces an alert rule, keeping its firing state
+	SetAlertRule(ctx context.Context, name string, req models.SetAlertRuleRequest) error
+
+	// DeleteAlertRule removes an alert rule
+	DeleteAlertRule(ctx context.Context, name string) error
+
+	// RecordAlertEvaluation stores the outcome of evaluating a rule
+	// Returns true if this call changed its firing state, so only one evaluator notifies; unknown rules are ignored
+	RecordAlertEvaluation(ctx context.Context, name string, firing bool, value float64) (bool, error)
+
+	// CreateAPIKey stores a new API key given its display prefix and hash
+	CreateAPIKey(ctx context.Context, req models.CreateAPIKeyRequest, prefix, hash string) (*models.APIKey, error)
+
+	// GetAPIKeyByHash returns the key with the given hash, including revoked and expired keys
+	GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error)
+
+	// ListAPIKeys returns all API keys, including revoked ones, ordered by ID
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+
+	// RevokeAPIKey marks a key revoked; revoking it again keeps the first revocation time
+	RevokeAPIKey(ctx context.Context, id int64) error
+}
+
+// TaskNotifier is implemented by stores that can push task-created notifications
+// Workers use it to dispatch new tasks immediately instead of waiting for the next poll
+type TaskNotifier interface {
+	// ListenTaskCreated signals on the returned channel whenever a task is created
+	// Signals may be coalesced; the channel is closed when ctx is done
+	ListenTaskCreated(ctx context.Context) <-chan struct{}
+}
+
+// HistoryPartitioner is implemented by stores that keep task history in time-based partitions
+// The API server's maintenance job uses it to create upcoming partitions and drop expired ones
+type HistoryPartitioner interface {
+	// MaintainHistoryPartitions creates upcoming partitions and drops those older than retention (0 = keep all)
+	// Returns the names of the created and dropped partitions
+	MaintainHistoryPartitions(ctx context.Context, retention time.Duration) (created []string, dropped []string, err error)
+}
+
+// TaskAnalytics is implemented by stores that can aggregate over finished tasks
+// The API's analytic stats endpoints answer 501 on stores without it
+type TaskAnalytics interface {
+	// GetLatencyStats returns queue wait and execution time percentiles per type
+	// over the last attempt of tasks that finished within r
+	GetLatencyStats(ctx context.Context, r models.TimeRange) ([]models.LatencyStats, error)
+
+	// GetThroughput returns created, succeeded and failed task counts per bucket within r, oldest first
+	// r.From must be set and an open r.To means now; every bucket in the range is returned, including empty ones
+	GetThroughput(ctx context.Context, bucket models.StatsBucket, r models.TimeRange) ([]models.ThroughputBucket, error)
+
+	// GetSuccessRates returns per-type success rates of the tasks that finished within r, ordered by type
+	// Only types with finished tasks are included
+	GetSuccessRates(ctx context.Context, r models.TimeRange) ([]models.SuccessRate, error)
+
+	// GetRetryDistribution returns per-type retry count histograms of the tasks that finished within r, ordered by type
+	GetRetryDistribution(ctx context.Context, r models.TimeRange) ([]models.RetryDistribution, error)
+
+	// GetRangeStats counts the tasks created within r
+	GetRangeStats(ctx context.Context, r models.TimeRange) (*models.TaskStatsResponse, error)
+
+	// GetRangeTypeStats returns per-type stats of the tasks created within r, ordered by type
+	GetRangeTypeStats(ctx context.Context, r models.TimeRange) ([]models.TypeStats, error)
+}
+
+// Pinger is anything with a reachability check, like every Store
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// HealthChecker is implemented by stores that report more than reachability for detailed health checks
+type HealthChecker interface {
+	// CheckHealth reports connectivity, latency and pool use of every backend the store uses
+	CheckHealth(ctx context.Context) []models.ComponentHealth
+}
+
+// TenantQuotas is implemented by stores that enforce per-tenant quotas
+// Such stores reject task creation past max_queued with *QuotaExceededError and claim
+// no more than max_running tasks of a tenant at once, so one tenant cannot take all workers
+type TenantQuotas interface {
+	// ListTenantQuotas returns every quota with the tenant's current usage, ordered by tenant
+	ListTenantQuotas(ctx context.Context) ([]models.TenantQuota, error)
+
+	// SetTenantQuota creates or replaces the quota of a tenant, models.DefaultTenantQuota for the default
+	SetTenantQuota(ctx context.Context, tenant string, req models.SetTenantQuotaRequest) error
+
+	// DeleteTenantQuota removes the quota of a tenant
+	DeleteTenantQuota(ctx context.Context, tenant string) error
+}
+
+// TaskResults is implemented by stores that keep the results handlers set with models.SetResult
+type TaskResults interface {
+	// SaveTaskResult stores the result of a task, replacing an earlier one
+	// Only applies if the task is still held by the given lock
+	SaveTaskResult(ctx context.Context, taskID int64, lock models.TaskLock, result json.RawMessage) error
+
+	// GetTaskResult returns the result of a task, ErrTaskResultNotFound if it has none
+	GetTaskResult(ctx context.Context, taskID int64) (*models.TaskResult, error)
+}
+
+// TaskSchemas is implemented by stores that keep a payload JSON Schema per task type
+type TaskSchemas interface {
+	// ListTaskSchemas returns every task schema, ordered by task type
+	ListTaskSchemas(ctx context.Context) ([]models.TaskSchema, error)
+
+	// SetTaskSchema creates or replaces the schema of a task type
+	SetTaskSchema(ctx context.Context, taskType string, schema json.RawMessage) error
+
+	// DeleteTaskSchema removes the schema of a task type
+	DeleteTaskSchema(ctx context.Context, taskType string) error
+}
+
+// TaskTypes is implemented by stores that keep a registry of the task types workers handle
+// Types come from the registrations of workers within their retention period and from operators
+type TaskTypes interface {
+	// ListTaskTypes returns every known task type, ordered by type
+	ListTaskTypes(ctx context.Context) ([]models.TaskTypeInfo, error)
+
+	// RegisterTaskType makes a type known without a worker reporting it, e.g. for workers not deployed yet
+	RegisterTaskType(ctx context.Context, taskType string) error
+
+	// DeregisterTaskType removes a type registered with RegisterTaskType; types reported by workers stay known
+	DeregisterTaskType(ctx context.Context, taskType string) error
+}
+
+// SchemaVersioner is implemented by stores whose schema is managed by versioned migrations
+type SchemaVersioner interface {
+	// SchemaVersion returns the migration version applied to the queue database
+	SchemaVersion(ctx context.Context) (models.MigrationHealth, error)
+}
+
+// CheckHealth reports the health of a store's backends, falling back to a timed Ping for stores without HealthChecker
+func CheckHealth(ctx context.Context, s Pinger) []models.ComponentHealth {
+	if h, ok := s.(HealthChecker); ok {
+		return h.CheckHealth(ctx)
+	}
+
+	c := models.ComponentHealth{Name: "database", Status: models.HealthStatusOK}
+	started := time.Now()
+	err := s.Ping(ctx)
+	c.LatencySeconds = time.Since(started).Seconds()
+	if err != nil {
+		c.Status = models.HealthStatusDown
+		c.Error = err.Error()
+	}
+	return []models.ComponentHealth{c}
+}
+
+// TableAnalyzer is implemented by stores whose tables rely on vacuum and planner statistics
+// The API server's opt-in analyze job uses it to refresh statistics and watch dead tuple bloat
+type TableAnalyzer interface {
+	// AnalyzeTables refreshes planner statistics of tasks and task_history and returns their tuple counts
+	AnalyzeTables(ctx context.Context) ([]models.TableStats, error)
 }
@@ -0,0 +1,1110 @@
+// Package storagetest is a behavioral test suite for storage.Store implementations
+// Every backend runs it so they stay semantically identical
+package storagetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/google/uuid"
+)
+
+// NewStore returns an empty store for one test; cleanup is registered on t
+type NewStore func(t *testing.T) storage.Store
+
+// Run runs the conformance suite against stores created by newStore
+// Stores should use a quarantine threshold above 1 so a single expired lock is retried
+func Run(t *testing.T, newStore NewStore) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, s storage.Store)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"PublicID", testPublicID},
+		{"Version", testVersion},
+		{"CreateTasks", testCreateTasks},
+		{"ClaimOrder", testClaimOrder},
+		{"ClaimAtomicity", testClaimAtomicity},
+		{"ClaimFilter", testClaimFilter},
+		{"LockFencing", testLockFencing},
+		{"RetryExhaustion", testRetryExhaustion},
+		{"LockExpiry", testLockExpiry},
+		{"Quarantine", testQuarantine},
+		{"HistoryOrdering", testHistoryOrdering},
+		{"Pause", testPause},
+		{"QueueDepth", testQueueDepth},
+		{"TypeStats", testTypeStats},
+		{"AlertRules", testAlertRules},
+		{"APIKeys", testAPIKeys},
+		{"TenantQuotas", testTenantQuotas},
+		{"TenantFairShare", testTenantFairShare},
+		{"TaskResults", testTaskResults},
+		{"TaskSchemas", testTaskSchemas},
+		{"TaskTypes", testTaskTypes},
+		{"Redaction", testRedaction},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newStore(t))
+		})
+	}
+}
+
+// createTask creates a task, failing the test on error
+func createTask(t *testing.T, s storage.Store, req models.CreateTaskRequest) *models.Task {
+	t.Helper()
+
+	if req.Name == "" {
+		req.Name = "task"
+	}
+	if req.Type == "" {
+		req.Type = "send_email"
+	}
+
+	task, err := s.CreateTask(context.Background(), req)
+	if err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+	return task
+}
+
+// claim claims up to n tasks, failing the test on error
+func claim(t *testing.T, s storage.Store, workerID string, n int, filter models.ClaimFilter) []*models.Task {
+	t.Helper()
+
+	tasks, err := s.ClaimNextTasks(context.Background(), workerID, n, filter)
+	if err != nil {
+		t.Fatalf("ClaimNextTasks() error = %v", err)
+	}
+	return tasks
+}
+
+// claimOne claims exactly one task, failing the test otherwise
+func claimOne(t *testing.T, s storage.Store, workerID string) *models.Task {
+	t.Helper()
+
+	tasks := claim(t, s, workerID, 1, models.ClaimFilter{})
+	if len(tasks) != 1 {
+		t.Fatalf("ClaimNextTasks() claimed %d tasks, want 1", len(tasks))
+	}
+	return tasks[0]
+}
+
+// getTask reads a task, failing the test on error
+func getTask(t *testing.T, s storage.Store, id int64) *models.Task {
+	t.Helper()
+
+	task, err := s.GetTask(context.Background(), id)
+	if err != nil {
+		t.Fatalf("GetTask(%d) error = %v", id, err)
+	}
+	return task
+}
+
+// intPtr returns a pointer to v
+func intPtr(v int) *int {
+	return &v
+}
+
+func testCreateAndGet(t *testing.T, s storage.Store) {
+	created := createTask(t, s, models.CreateTaskRequest{
+		Name:     "welcome",
+		Type:     "Send_Email",
+		Payload:  []byte(`{"to":"a@example.com"}`),
+		Priority: 3,
+	})
+
+	got := getTask(t, s, created.ID)
+	if got.Type != "send_email" {
+		t.Errorf("Type = %q, want normalized %q", got.Type, "send_email")
+	}
+	if got.Status != models.TaskStatusQueued {
+		t.Errorf("Status = %q, want %q", got.Status, models.TaskStatusQueued)
+	}
+	if got.Priority != 3 || got.MaxRetries != 3 || got.TimeoutSeconds != 30 || got.RetryCount != 0 {
+		t.Errorf("got priority=%d max_retries=%d timeout=%d retry_count=%d, want 3, 3, 30, 0",
+			got.Priority, got.MaxRetries, got.TimeoutSeconds, got.RetryCount)
+	}
+	if !strings.Contains(string(got.Payload), `"a@example.com"`) {
+		t.Errorf("Payload = %s, want the created payload", got.Payload)
+	}
+
+	if _, err := s.GetTask(context.Background(), created.ID+1000); !errors.Is(err, storage.ErrTaskNotFound) {
+		t.Errorf("GetTask(missing) error = %v, want ErrTaskNotFound", err)
+	}
+
+	traceParent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	traced := createTask(t, s, models.CreateTaskRequest{Metadata: map[string]string{models.MetadataTraceParent: traceParent}})
+	if got := getTask(t, s, traced.ID).Metadata[models.MetadataTraceParent]; got != traceParent {
+		t.Errorf("Metadata[traceparent] = %q, want %q", got, traceParent)
+	}
+
+	owned := createTask(t, s, models.CreateTaskRequest{Tenant: "acme"})
+	if got := getTask(t, s, owned.ID).Tenant; got != "acme" {
+		t.Errorf("Tenant = %q, want %q", got, "acme")
+	}
+
+	signed := createTask(t, s, models.CreateTaskRequest{PayloadSignature: "3f9a"})
+	if got := getTask(t, s, signed.ID).PayloadSignature; got != "3f9a" {
+		t.Errorf("PayloadSignature = %q, want %q", got, "3f9a")
+	}
+}
+
+func testPublicID(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	created := createTask(t, s, models.CreateTaskRequest{})
+	if created.PublicID.Version() != 7 {
+		t.Fatalf("PublicID = %s, want a version 7 UUID", created.PublicID)
+	}
+
+	tasks, err := s.CreateTasks(ctx, []models.CreateTaskRequest{{Name: "a", Type: "send_email"}, {Name: "b", Type: "send_email"}})
+	if err != nil {
+		t.Fatalf("CreateTasks() error = %v", err)
+	}
+	if tasks[0].PublicID == tasks[1].PublicID || tasks[0].PublicID.Version() != 7 {
+		t.Errorf("batch public ids = %s, %s, want distinct version 7 UUIDs", tasks[0].PublicID, tasks[1].PublicID)
+	}
+
+	got, err := s.GetTaskByPublicID(ctx, created.PublicID)
+	if err != nil {
+		t.Fatalf("GetTaskByPublicID() error = %v", err)
+	}
+	if got.ID != created.ID || got.PublicID != created.PublicID {
+		t.Errorf("GetTaskByPublicID() = task %d (%s), want %d (%s)", got.ID, got.PublicID, created.ID, created.PublicID)
+	}
+
+	if _, err := s.GetTaskByPublicID(ctx, uuid.Nil); !errors.Is(err, storage.ErrTaskNotFound) {
+		t.Errorf("GetTaskByPublicID(nil) error = %v, want ErrTaskNotFound", err)
+	}
+
+	// A signed request brings the id its signature covers
+	chosen := uuid.Must(uuid.NewV7())
+	if got := createTask(t, s, models.CreateTaskRequest{PublicID: chosen}); got.PublicID != chosen {
+		t.Errorf("PublicID = %s, want the requested %s", got.PublicID, chosen)
+	}
+	batchID := uuid.Must(uuid.NewV7())
+	tasks, err = s.CreateTasks(ctx, []models.CreateTaskRequest{{Name: "c", Type: "send_email", PublicID: batchID}})
+	if err != nil {
+		t.Fatalf("CreateTasks() error = %v", err)
+	}
+	if tasks[0].PublicID != batchID {
+		t.Errorf("batch PublicID = %s, want the requested %s", tasks[0].PublicID, batchID)
+	}
+}
+
+func testVersion(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	created := createTask(t, s, models.CreateTaskRequest{})
+	if created.Version != 1 {
+		t.Fatalf("Version = %d, want 1", created.Version)
+	}
+
+	stale := created.Version
+	claimed := claimOne(t, s, "worker-1")
+	if claimed.Version <= stale {
+		t.Fatalf("Version after claim = %d, want greater than %d", claimed.Version, stale)
+	}
+
+	// Heartbeats leave the version alone, so a running task's ETag stays usable
+	if err := s.ExtendLock(ctx, claimed.ID, claimed.Lock(), time.Minute); err != nil {
+		t.Fatalf("ExtendLock() error = %v", err)
+	}
+	if got := getTask(t, s, created.ID); got.Version != claimed.Version {
+		t.Errorf("Version after ExtendLock = %d, want %d", got.Version, claimed.Version)
+	}
+
+	if err := s.UpdateTaskStatus(ctx, created.ID, models.TaskStatusFailed, nil, &stale); !errors.Is(err, storage.ErrVersionConflict) {
+		t.Errorf("UpdateTaskStatus(stale version) error = %v, want ErrVersionConflict", err)
+	}
+	if got := getTask(t, s, created.ID); got.Status != models.TaskStatusRunning || got.Version != claimed.Version {
+		t.Errorf("after stale update = status %q version %d, want running at %d", got.Status, got.Version, claimed.Version)
+	}
+
+	if err := s.UpdateTaskStatus(ctx, created.ID, models.TaskStatusFailed, nil, &claimed.Version); err != nil {
+		t.Fatalf("UpdateTaskStatus(current version) error = %v", err)
+	}
+	if got := getTask(t, s, created.ID); got.Status != models.TaskStatusFailed || got.Version != claimed.Version+1 {
+		t.Errorf("after update = status %q version %d, want failed at %d", got.Status, got.Version, claimed.Version+1)
+	}
+
+	if err := s.UpdateTaskStatus(ctx, created.ID+1000, models.TaskStatusFailed, nil, &stale); !errors.Is(err, storage.ErrTaskNotFound) {
+		t.Errorf("UpdateTaskStatus(missing) error = %v, want ErrTaskNotFound", err)
+	}
+}
+
+func testCreateTasks(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	reqs := []models.CreateTaskRequest{
+		{Name: "first", Type: "send_email", Priority: 1},
+		{Name: "second", Type: "Run_Query", MaxRetries: intPtr(0)},
+		{Name: "third", Type: "send_email", Payload: []byte(`{"n":3}`)},
+	}
+
+	tasks, err := s.CreateTasks(ctx, reqs)
+	if err != nil {
+		t.Fatalf("CreateTasks() error = %v", err)
+	}
+	if len(tasks) != len(reqs) {
+		t.Fatalf("CreateTasks() returned %d tasks, want %d", len(tasks), len(reqs))
+	}
+
+	for i, task := range tasks {
+		if task.Name != reqs[i].Name || task.Status != models.TaskStatusQueued {
+			t.Errorf("tasks[%d] = %q %s, want %q queued", i, task.Name, task.Status, reqs[i].Name)
+		}
+		if i > 0 && task.ID <= tasks[i-1].ID {
+			t.Errorf("tasks[%d].ID = %d, want greater than %d", i, task.ID, tasks[i-1].ID)
+		}
+
+		history, err := s.GetTaskHistory(ctx, task.ID, task.CreatedAt)
+		if err != nil || len(history) != 1 || history[0].EventType != models.EventTaskQueued {
+			t.Errorf("history of tasks[%d] = %+v, %v, want one task_queued event", i, history, err)
+		}
+	}
+	if tasks[1].Type != "run_query" || tasks[1].MaxRetries != 0 || tasks[0].MaxRetries != 3 {
+		t.Errorf("defaults not applied per task: %+v", tasks)
+	}
+
+	claimed := claim(t, s, "worker-1", 10, models.ClaimFilter{})
+	if len(claimed) != len(reqs) {
+		t.Errorf("claimed %d tasks, want %d", len(claimed), len(reqs))
+	}
+
+	if tasks, err := s.CreateTasks(ctx, nil); err != nil || len(tasks) != 0 {
+		t.Errorf("CreateTasks(nil) = %v, %v, want none", tasks, err)
+	}
+}
+
+func testClaimOrder(t *testing.T, s storage.Store) {
+	low := createTask(t, s, models.CreateTaskRequest{Priority: 1})
+	high := createTask(t, s, models.CreateTaskRequest{Priority: 9})
+	lowLater := createTask(t, s, models.CreateTaskRequest{Priority: 1})
+
+	tasks := claim(t, s, "worker-1", 10, models.ClaimFilter{})
+	if len(tasks) != 3 {
+		t.Fatalf("claimed %d tasks, want 3", len(tasks))
+	}
+
+	want := []int64{high.ID, low.ID, lowLater.ID}
+	for i, task := range tasks {
+		if task.ID != want[i] {
+			t.Fatalf("claim order = %d at %d, want %v", task.ID, i, want)
+		}
+		if task.Status != models.TaskStatusRunning || task.LockedBy == nil || *task.LockedBy != "worker-1" {
+			t.Errorf("claimed task %d not running under worker-1: %+v", task.ID, task)
+		}
+	}
+
+	if tasks := claim(t, s, "worker-2", 10, models.ClaimFilter{}); len(tasks) != 0 {
+		t.Errorf("second claim got %d tasks, want 0", len(tasks))
+	}
+}
+
+func testClaimAtomicity(t *testing.T, s storage.Store) {
+	const taskCount = 30
+	for i := 0; i < taskCount; i++ {
+		createTask(t, s, models.CreateTaskRequest{})
+	}
+
+	var (
+		mu      sync.Mutex
+		claimed = map[int64]string{}
+		wg      sync.WaitGroup
+		errs    = make(chan error, 8)
+	)
+	for w := 0; w < 8; w++ {
+		workerID := "worker-" + string(rune('a'+w))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				tasks, err := s.ClaimNextTasks(context.Background(), workerID, 3, models.ClaimFilter{})
+				if err != nil {
+					errs <- err
+					return
+				}
+				if len(tasks) == 0 {
+					return
+				}
+
+				mu.Lock()
+				for _, task := range tasks {
+					if owner, ok := claimed[task.ID]; ok {
+						errs <- errors.New("task claimed by " + owner + " and " + workerID)
+					}
+					claimed[task.ID] = workerID
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	if len(claimed) != taskCount {
+		t.Errorf("claimed %d distinct tasks, want %d", len(claimed), taskCount)
+	}
+}
+
+func testClaimFilter(t *testing.T, s storage.Store) {
+	gpu := createTask(t, s, models.CreateTaskRequest{RequiredLabels: map[string]string{"gpu": "true"}})
+	low := createTask(t, s, models.CreateTaskRequest{Priority: 1})
+
+	high := 5
+	if tasks := claim(t, s, "worker-1", 10, models.ClaimFilter{MinPriority: &high}); len(tasks) != 0 {
+		t.Errorf("claim with min priority got %d tasks, want 0", len(tasks))
+	}
+
+	tasks := claim(t, s, "worker-1", 10, models.ClaimFilter{})
+	if len(tasks) != 1 || tasks[0].ID != low.ID {
+		t.Fatalf("claim without labels = %v, want only task %d", taskIDs(tasks), low.ID)
+	}
+
+	tasks = claim(t, s, "worker-2", 10, models.ClaimFilter{Labels: map[string]string{"gpu": "true", "region": "eu"}})
+	if len(tasks) != 1 || tasks[0].ID != gpu.ID {
+		t.Fatalf("claim with labels = %v, want task %d", taskIDs(tasks), gpu.ID)
+	}
+}
+
+func testLockFencing(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	createTask(t, s, models.CreateTaskRequest{})
+	task := claimOne(t, s, "worker-1")
+
+	stale := models.TaskLock{WorkerID: "worker-1", Token: task.LockToken - 1}
+	if err := s.CompleteTask(ctx, task.ID, stale); !errors.Is(err, storage.ErrLockLost) {
+		t.Errorf("CompleteTask(stale token) error = %v, want ErrLockLost", err)
+	}
+
+	other := models.TaskLock{WorkerID: "worker-2", Token: task.LockToken}
+	if err := s.MarkTaskFailed(ctx, task.ID, other, "boom"); !errors.Is(err, storage.ErrLockLost) {
+		t.Errorf("MarkTaskFailed(other worker) error = %v, want ErrLockLost", err)
+	}
+
+	if err := s.CompleteTask(ctx, task.ID+1000, task.Lock()); !errors.Is(err, storage.ErrTaskNotFound) {
+		t.Errorf("CompleteTask(missing) error = %v, want ErrTaskNotFound", err)
+	}
+
+	if err := s.CompleteTask(ctx, task.ID, task.Lock()); err != nil {
+		t.Fatalf("CompleteTask() error = %v", err)
+	}
+	if got := getTask(t, s, task.ID); got.Status != models.TaskStatusSucceeded || got.LockedBy != nil {
+		t.Errorf("completed task = status %q locked_by %v, want succeeded and unlocked", got.Status, got.LockedBy)
+	}
+
+	// Finishing twice is a lost lock, not a second success
+	if err := s.CompleteTask(ctx, task.ID, task.Lock()); !errors.Is(err, storage.ErrLockLost) {
+		t.Errorf("CompleteTask(twice) error = %v, want ErrLockLost", err)
+	}
+}
+
+func testRetryExhaustion(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	created := createTask(t, s, models.CreateTaskRequest{MaxRetries: intPtr(1), BackoffSeconds: intPtr(0)})
+
+	task := claimOne(t, s, "worker-1")
+	if err := s.ScheduleRetry(ctx, task.ID, task.Lock(), "first failure", time.Millisecond); err != nil {
+		t.Fatalf("ScheduleRetry() error = %v", err)
+	}
+
+	got := getTask(t, s, created.ID)
+	if got.Status != models.TaskStatusQueued || got.RetryCount != 1 || got.LastError == nil || *got.LastError != "first failure" {
+		t.Fatalf("after retry = status %q retry_count %d last_error %v, want queued, 1, first failure",
+			got.Status, got.RetryCount, got.LastError)
+	}
+	if got.LastBackoffSeconds == nil {
+		t.Error("last_backoff_seconds was not recorded")
+	}
+
+	task = waitForClaim(t, s, "worker-1")
+	if err := s.ScheduleRetry(ctx, task.ID, task.Lock(), "second failure", time.Millisecond); err != nil {
+		t.Fatalf("ScheduleRetry() error = %v", err)
+	}
+
+	got = getTask(t, s, created.ID)
+	if got.Status != models.TaskStatusFailed {
+		t.Fatalf("after exhausting retries status = %q, want failed", got.Status)
+	}
+	if got.LastError == nil || !strings.HasPrefix(*got.LastError, "max retries exceeded") {
+		t.Errorf("last_error = %v, want max retries exceeded", got.LastError)
+	}
+
+	if tasks := claim(t, s, "worker-1", 10, models.ClaimFilter{}); len(tasks) != 0 {
+		t.Errorf("failed task was claimed again")
+	}
+}
+
+func testLockExpiry(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	created := createTask(t, s, models.CreateTaskRequest{})
+	task := claimOne(t, s, "worker-1")
+
+	// Nothing to reap while the lock is held
+	if reaped, err := s.ReapExpiredLocks(ctx); err != nil || reaped != 0 {
+		t.Fatalf("ReapExpiredLocks() = %d, %v, want 0", reaped, err)
+	}
+
+	// A negative extension expires the lock immediately
+	if err := s.ExtendLock(ctx, task.ID, task.Lock(), -time.Second); err != nil {
+		t.Fatalf("ExtendLock() error = %v", err)
+	}
+
+	reaped, err := s.ReapExpiredLocks(ctx)
+	if err != nil || reaped != 1 {
+		t.Fatalf("ReapExpiredLocks() = %d, %v, want 1", reaped, err)
+	}
+
+	got := getTask(t, s, created.ID)
+	if got.Status != models.TaskStatusQueued || got.RetryCount != 1 || got.CrashCount != 1 || got.LockedBy != nil {
+		t.Errorf("reaped task = status %q retry_count %d crash_count %d locked_by %v, want queued, 1, 1, unlocked",
+			got.Status, got.RetryCount, got.CrashCount, got.LockedBy)
+	}
+
+	// The expired owner can no longer finish the task, the new owner can
+	reclaimed := claimOne(t, s, "worker-2")
+	if reclaimed.LockToken <= task.LockToken {
+		t.Errorf("reclaimed lock_token = %d, want greater than %d", reclaimed.LockToken, task.LockToken)
+	}
+	if err := s.CompleteTask(ctx, task.ID, task.Lock()); !errors.Is(err, storage.ErrLockLost) {
+		t.Errorf("CompleteTask(expired lock) error = %v, want ErrLockLost", err)
+	}
+	if err := s.CompleteTask(ctx, reclaimed.ID, reclaimed.Lock()); err != nil {
+		t.Errorf("CompleteTask(new lock) error = %v", err)
+	}
+}
+
+func testQuarantine(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	created := createTask(t, s, models.CreateTaskRequest{MaxRetries: intPtr(10), BackoffSeconds: intPtr(0)})
+	task := claimOne(t, s, "worker-1")
+
+	if err := s.ReleaseTask(ctx, created.ID, nil); !errors.Is(err, storage.ErrNotQuarantined) {
+		t.Errorf("ReleaseTask(running) error = %v, want ErrNotQuarantined", err)
+	}
+
+	// Crash until the store's quarantine threshold is reached, or retries run out without one
+	for {
+		if err := s.RecordCrash(ctx, task.ID, task.Lock(), "panic"); err != nil {
+			t.Fatalf("RecordCrash() error = %v", err)
+		}
+		if got := getTask(t, s, created.ID); got.Status != models.TaskStatusQueued {
+			break
+		}
+		task = waitForClaim(t, s, "worker-1")
+	}
+
+	got := getTask(t, s, created.ID)
+	if got.Status != models.TaskStatusQuarantined {
+		t.Skipf("store has no quarantine threshold (status %q)", got.Status)
+	}
+
+	quarantined, err := s.ListQuarantinedTasks(ctx, 10)
+	if err != nil || len(quarantined) != 1 || quarantined[0].ID != created.ID {
+		t.Fatalf("ListQuarantinedTasks() = %v, %v, want task %d", taskIDs(quarantined), err, created.ID)
+	}
+	if tasks := claim(t, s, "worker-1", 10, models.ClaimFilter{}); len(tasks) != 0 {
+		t.Errorf("quarantined task was claimed")
+	}
+
+	if err := s.ReleaseTask(ctx, created.ID, &created.Version); !errors.Is(err, storage.ErrVersionConflict) {
+		t.Errorf("ReleaseTask(stale version) error = %v, want ErrVersionConflict", err)
+	}
+	if err := s.ReleaseTask(ctx, created.ID, &got.Version); err != nil {
+		t.Fatalf("ReleaseTask() error = %v", err)
+	}
+	if got := getTask(t, s, created.ID); got.Status != models.TaskStatusQueued || got.CrashCount != 0 {
+		t.Errorf("released task = status %q crash_count %d, want queued, 0", got.Status, got.CrashCount)
+	}
+	claimOne(t, s, "worker-1")
+}
+
+func testHistoryOrdering(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	created := createTask(t, s, models.CreateTaskRequest{})
+	task := claimOne(t, s, "worker-1")
+
+	if err := s.ScheduleRetry(ctx, task.ID, task.Lock(), "boom", time.Millisecond); err != nil {
+		t.Fatalf("ScheduleRetry() error = %v", err)
+	}
+	task = waitForClaim(t, s, "worker-1")
+	if err := s.CompleteTask(ctx, task.ID, task.Lock()); err != nil {
+		t.Fatalf("CompleteTask() error = %v", err)
+	}
+
+	history, err := s.GetTaskHistory(ctx, created.ID, created.CreatedAt)
+	if err != nil {
+		t.Fatalf("GetTaskHistory() error = %v", err)
+	}
+
+	want := []models.EventType{
+		models.EventTaskQueued,
+		models.EventWorkerLockAcquired, models.EventRetryScheduled,
+		models.EventWorkerLockAcquired, models.EventTaskSucceeded,
+	}
+	if len(history) != len(want) {
+		t.Fatalf("history has %d entries, want %d: %+v", len(history), len(want), history)
+	}
+	for i, h := range history {
+		if h.EventType != want[i] || h.TaskID != created.ID {
+			t.Errorf("history[%d] = %s for task %d, want %s for task %d", i, h.EventType, h.TaskID, want[i], created.ID)
+		}
+		if i > 0 && h.CreatedAt.Before(history[i-1].CreatedAt) {
+			t.Errorf("history[%d] created before history[%d]", i, i-1)
+		}
+	}
+
+	if history, err := s.GetTaskHistory(ctx, created.ID+1000, time.Time{}); err != nil || len(history) != 0 {
+		t.Errorf("GetTaskHistory(missing) = %v, %v, want empty", history, err)
+	}
+
+	actor := "billing-service"
+	access := models.TaskHistory{TaskID: created.ID, Status: models.TaskStatusSucceeded, EventType: models.EventPayloadAccessed, Actor: &actor}
+	if err := s.InsertHistory(ctx, access); err != nil {
+		t.Fatalf("InsertHistory() error = %v", err)
+	}
+	history, err = s.GetTaskHistory(ctx, created.ID, created.CreatedAt)
+	if err != nil {
+		t.Fatalf("GetTaskHistory() error = %v", err)
+	}
+	if last := history[len(history)-1]; last.EventType != models.EventPayloadAccessed || last.Actor == nil || *last.Actor != actor {
+		t.Errorf("last history entry = %+v, want payload_accessed by %s", last, actor)
+	}
+}
+
+func testPause(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	createTask(t, s, models.CreateTaskRequest{Type: "send_email"})
+	query := createTask(t, s, models.CreateTaskRequest{Type: "run_query"})
+
+	if err := s.Pause(ctx, "send_email", nil); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	tasks := claim(t, s, "worker-1", 10, models.ClaimFilter{})
+	if len(tasks) != 1 || tasks[0].ID != query.ID {
+		t.Fatalf("claim with paused type = %v, want task %d", taskIDs(tasks), query.ID)
+	}
+
+	if err := s.Pause(ctx, models.PauseScopeQueue, nil); err != nil {
+		t.Fatalf("Pause(queue) error = %v", err)
+	}
+	if err := s.Resume(ctx, "send_email", nil); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if tasks := claim(t, s, "worker-1", 10, models.ClaimFilter{}); len(tasks) != 0 {
+		t.Errorf("claim with paused queue got %d tasks, want 0", len(tasks))
+	}
+
+	if err := s.Resume(ctx, models.PauseScopeQueue, nil); err != nil {
+		t.Fatalf("Resume(queue) error = %v", err)
+	}
+	claimOne(t, s, "worker-1")
+
+	if err := s.Resume(ctx, "send_email", nil); !errors.Is(err, storage.ErrNotPaused) {
+		t.Errorf("Resume(not paused) error = %v, want ErrNotPaused", err)
+	}
+
+	events, err := s.ListPauseHistory(ctx, 10)
+	if err != nil || len(events) != 4 {
+		t.Fatalf("ListPauseHistory() = %d events, %v, want 4", len(events), err)
+	}
+	if events[0].Action != models.PauseActionResumed || events[0].Scope != models.PauseScopeQueue {
+		t.Errorf("latest pause event = %s %s, want resumed %s", events[0].Action, events[0].Scope, models.PauseScopeQueue)
+	}
+}
+
+// waitForClaim claims one task, waiting for a just-scheduled retry to become due
+func waitForClaim(t *testing.T, s storage.Store, workerID string) *models.Task {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if tasks := claim(t, s, workerID, 1, models.ClaimFilter{}); len(tasks) == 1 {
+			return tasks[0]
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("no task became claimable")
+	return nil
+}
+
+// taskIDs returns the IDs of tasks for error messages
+func taskIDs(tasks []*models.Task) []int64 {
+	ids := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func testQueueDepth(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	createTask(t, s, models.CreateTaskRequest{Type: "send_email"})
+	createTask(t, s, models.CreateTaskRequest{Type: "send_email"})
+	createTask(t, s, models.CreateTaskRequest{Type: "run_query"})
+
+	// A retry scheduled for later is queued but not claimable
+	task := claimOne(t, s, "worker-1")
+	if err := s.ScheduleRetry(ctx, task.ID, task.Lock(), "boom", time.Hour); err != nil {
+		t.Fatalf("ScheduleRetry() error = %v", err)
+	}
+
+	depths, err := s.GetQueueDepth(ctx)
+	if err != nil {
+		t.Fatalf("GetQueueDepth() error = %v", err)
+	}
+	if len(depths) != 2 || depths[0].TaskType != "run_query" || depths[1].TaskType != "send_email" {
+		t.Fatalf("GetQueueDepth() = %+v, want run_query and send_email", depths)
+	}
+
+	email := depths[1]
+	if email.Queued != 2 || email.Claimable != 1 || email.OldestClaimableAt == nil {
+		t.Errorf("send_email depth = %+v, want 2 queued, 1 claimable with an oldest task", email)
+	}
+	if depths[0].Queued != 1 || depths[0].Claimable != 1 {
+		t.Errorf("run_query depth = %+v, want 1 queued and claimable", depths[0])
+	}
+}
+
+func testTypeStats(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	createTask(t, s, models.CreateTaskRequest{Type: "send_email"})
+	createTask(t, s, models.CreateTaskRequest{Type: "run_query"})
+
+	task := claimOne(t, s, "worker-1")
+	if err := s.CompleteTask(ctx, task.ID, task.Lock()); err != nil {
+		t.Fatalf("CompleteTask() error = %v", err)
+	}
+
+	stats, err := s.GetTypeStats(ctx)
+	if err != nil {
+		t.Fatalf("GetTypeStats() error = %v", err)
+	}
+	if len(stats) != 2 || stats[0].TaskType != "run_query" || stats[1].TaskType != "send_email" {
+		t.Fatalf("GetTypeStats() = %+v, want run_query and send_email", stats)
+	}
+	if email := stats[1]; email.TotalTasks != 1 || email.Succeeded != 1 || email.Queued != 0 || email.FailureRate != 0 {
+		t.Errorf("send_email stats = %+v, want 1 total and succeeded", email)
+	}
+	if query := stats[0]; query.TotalTasks != 1 || query.Queued != 1 {
+		t.Errorf("run_query stats = %+v, want 1 total and queued", query)
+	}
+}
+
+// testAlertRules checks that rules keep their state when replaced and only transitions are reported
+func testAlertRules(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	threshold := 50.0
+	req := models.SetAlertRuleRequest{
+		Metric:        models.AlertMetricFailedTasks,
+		TaskType:      "send_email",
+		Operator:      models.AlertOperatorAbove,
+		Threshold:     &threshold,
+		WindowSeconds: 600,
+		WebhookURL:    "https://hooks.example.com/alerts",
+		Channel:       models.AlertChannelWebhook,
+	}
+	if err := s.SetAlertRule(ctx, "email-failures", req); err != nil {
+		t.Fatalf("SetAlertRule() error = %v", err)
+	}
+
+	for i, want := range []bool{true, false} {
+		changed, err := s.RecordAlertEvaluation(ctx, "email-failures", true, 51)
+		if err != nil {
+			t.Fatalf("RecordAlertEvaluation() error = %v", err)
+		}
+		if changed != want {
+			t.Errorf("RecordAlertEvaluation() #%d changed = %v, want %v", i+1, changed, want)
+		}
+	}
+
+	// Replacing the rule keeps it firing and replaces its signing secret
+	threshold = 60
+	req.SigningSecret = "0123456789abcdef"
+	if err := s.SetAlertRule(ctx, "email-failures", req); err != nil {
+		t.Fatalf("SetAlertRule() error = %v", err)
+	}
+	rules, err := s.ListAlertRules(ctx)
+	if err != nil {
+		t.Fatalf("ListAlertRules() error = %v", err)
+	}
+	if len(rules) != 1 {
+		t.Fatalf("ListAlertRules() = %+v, want one rule", rules)
+	}
+	if r := rules[0]; !r.Firing || r.Threshold != 60 || r.LastValue == nil || *r.LastValue != 51 || r.LastTransitionAt == nil {
+		t.Errorf("rule = %+v, want firing with threshold 60 and last value 51", r)
+	}
+	if r := rules[0]; r.SigningSecret != "0123456789abcdef" {
+		t.Errorf("rule signing secret = %q, want the one set", r.SigningSecret)
+	}
+
+	if changed, err := s.RecordAlertEvaluation(ctx, "email-failures", false, 3); err != nil || !changed {
+		t.Errorf("RecordAlertEvaluation() resolving = %v, %v, want a change", changed, err)
+	}
+
+	if err := s.DeleteAlertRule(ctx, "email-failures"); err != nil {
+		t.Fatalf("DeleteAlertRule() error = %v", err)
+	}
+	if err := s.DeleteAlertRule(ctx, "email-failures"); !errors.Is(err, storage.ErrAlertRuleNotFound) {
+		t.Errorf("DeleteAlertRule() of a deleted rule error = %v, want ErrAlertRuleNotFound", err)
+	}
+	if changed, err := s.RecordAlertEvaluation(ctx, "email-failures", true, 51); err != nil || changed {
+		t.Errorf("RecordAlertEvaluation() of a deleted rule = %v, %v, want no change", changed, err)
+	}
+}
+
+func testAPIKeys(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	req := models.CreateAPIKeyRequest{Name: "producer", Role: models.RoleViewer, Scopes: []models.APIKeyScope{models.APIKeyScopeWrite}}
+	created, err := s.CreateAPIKey(ctx, req, "tq_12345678", models.HashAPIKey("tq_12345678abc"))
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+	if created.ID == 0 || created.Name != "producer" || created.Prefix != "tq_12345678" || !created.Allows(models.APIKeyScopeWrite) {
+		t.Errorf("CreateAPIKey() = %+v, want producer with read and write", created)
+	}
+
+	got, err := s.GetAPIKeyByHash(ctx, models.HashAPIKey("tq_12345678abc"))
+	if err != nil {
+		t.Fatalf("GetAPIKeyByHash() error = %v", err)
+	}
+	if got.ID != created.ID || got.Role != models.RoleViewer || len(got.Scopes) != 1 || got.RevokedAt != nil {
+		t.Errorf("GetAPIKeyByHash() = %+v, want %+v", got, created)
+	}
+	if _, err := s.GetAPIKeyByHash(ctx, models.HashAPIKey("tq_other")); !errors.Is(err, storage.ErrAPIKeyNotFound) {
+		t.Errorf("GetAPIKeyByHash() of an unknown key error = %v, want ErrAPIKeyNotFound", err)
+	}
+
+	if err := s.RevokeAPIKey(ctx, created.ID); err != nil {
+		t.Fatalf("RevokeAPIKey() error = %v", err)
+	}
+	got, err = s.GetAPIKeyByHash(ctx, models.HashAPIKey("tq_12345678abc"))
+	if err != nil {
+		t.Fatalf("GetAPIKeyByHash() after revoking error = %v", err)
+	}
+	if got.RevokedAt == nil || got.Active(time.Now()) {
+		t.Errorf("GetAPIKeyByHash() after revoking = %+v, want revoked", got)
+	}
+	if err := s.RevokeAPIKey(ctx, created.ID+1000); !errors.Is(err, storage.ErrAPIKeyNotFound) {
+		t.Errorf("RevokeAPIKey() of an unknown key error = %v, want ErrAPIKeyNotFound", err)
+	}
+
+	keys, err := s.ListAPIKeys(ctx)
+	if err != nil {
+		t.Fatalf("ListAPIKeys() error = %v", err)
+	}
+	if len(keys) != 1 || keys[0].ID != created.ID || keys[0].RevokedAt == nil {
+		t.Errorf("ListAPIKeys() = %+v, want the revoked key", keys)
+	}
+}
+
+func testTaskResults(t *testing.T, s storage.Store) {
+	results, ok := s.(storage.TaskResults)
+	if !ok {
+		t.Skip("store does not keep task results")
+	}
+
+	ctx := context.Background()
+	task := createTask(t, s, models.CreateTaskRequest{})
+	if _, err := results.GetTaskResult(ctx, task.ID); !errors.Is(err, storage.ErrTaskResultNotFound) {
+		t.Fatalf("GetTaskResult() before saving error = %v, want ErrTaskResultNotFound", err)
+	}
+
+	claimed := claimOne(t, s, "worker-1")
+	if err := results.SaveTaskResult(ctx, claimed.ID, models.TaskLock{WorkerID: "worker-1", Token: claimed.LockToken + 1}, json.RawMessage(`{"rows":1}`)); !errors.Is(err, storage.ErrLockLost) {
+		t.Fatalf("SaveTaskResult() with a stale lock error = %v, want ErrLockLost", err)
+	}
+	if err := results.SaveTaskResult(ctx, claimed.ID, claimed.Lock(), json.RawMessage(`{"rows":1}`)); err != nil {
+		t.Fatalf("SaveTaskResult() error = %v", err)
+	}
+	if err := results.SaveTaskResult(ctx, claimed.ID, claimed.Lock(), json.RawMessage(`{"rows":2}`)); err != nil {
+		t.Fatalf("SaveTaskResult() replacing error = %v", err)
+	}
+	if err := s.CompleteTask(ctx, claimed.ID, claimed.Lock()); err != nil {
+		t.Fatalf("CompleteTask() error = %v", err)
+	}
+
+	got, err := results.GetTaskResult(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetTaskResult() error = %v", err)
+	}
+	var decoded struct{ Rows int }
+	if err := json.Unmarshal(got.Result, &decoded); err != nil || decoded.Rows != 2 {
+		t.Errorf("GetTaskResult() = %s, want the replacing result", got.Result)
+	}
+	if got.CreatedAt.IsZero() {
+		t.Error("GetTaskResult() CreatedAt is zero")
+	}
+}
+
+func testTaskSchemas(t *testing.T, s storage.Store) {
+	schemas, ok := s.(storage.TaskSchemas)
+	if !ok {
+		t.Skip("store does not keep task schemas")
+	}
+
+	ctx := context.Background()
+	if err := schemas.SetTaskSchema(ctx, "Send_Email", json.RawMessage(`{"required": ["to"]}`)); err != nil {
+		t.Fatalf("SetTaskSchema() error = %v", err)
+	}
+	if err := schemas.SetTaskSchema(ctx, "send_email", json.RawMessage(`{"required": ["to", "subject"]}`)); err != nil {
+		t.Fatalf("SetTaskSchema() replacing error = %v", err)
+	}
+
+	list, err := schemas.ListTaskSchemas(ctx)
+	if err != nil {
+		t.Fatalf("ListTaskSchemas() error = %v", err)
+	}
+	if len(list) != 1 || list[0].TaskType != "send_email" || list[0].UpdatedAt.IsZero() {
+		t.Fatalf("ListTaskSchemas() = %+v, want one send_email schema", list)
+	}
+	var decoded struct{ Required []string }
+	if err := json.Unmarshal(list[0].Schema, &decoded); err != nil || len(decoded.Required) != 2 {
+		t.Errorf("ListTaskSchemas() schema = %s, want the replacing schema", list[0].Schema)
+	}
+
+	if err := schemas.DeleteTaskSchema(ctx, "SEND_EMAIL"); err != nil {
+		t.Fatalf("DeleteTaskSchema() error = %v", err)
+	}
+	if err := schemas.DeleteTaskSchema(ctx, "send_email"); !errors.Is(err, storage.ErrTaskSchemaNotFound) {
+		t.Errorf("DeleteTaskSchema() again error = %v, want ErrTaskSchemaNotFound", err)
+	}
+}
+
+func testTaskTypes(t *testing.T, s storage.Store) {
+	types, ok := s.(storage.TaskTypes)
+	if !ok {
+		t.Skip("store does not keep a task type registry")
+	}
+
+	ctx := context.Background()
+	worker := models.WorkerInfo{ID: "worker-types", Hostname: "host", Version: "test", Concurrency: 1, TaskTypes: []string{"send_email", "run_query"}}
+	if err := s.RegisterWorker(ctx, worker); err != nil {
+		t.Fatalf("RegisterWorker() error = %v", err)
+	}
+	if err := types.RegisterTaskType(ctx, "Resize_Image"); err != nil {
+		t.Fatalf("RegisterTaskType() error = %v", err)
+	}
+	if err := types.RegisterTaskType(ctx, "send_email"); err != nil {
+		t.Fatalf("RegisterTaskType() of a reported type error = %v", err)
+	}
+
+	list, err := types.ListTaskTypes(ctx)
+	if err != nil {
+		t.Fatalf("ListTaskTypes() error = %v", err)
+	}
+	byType := map[string]models.TaskTypeInfo{}
+	for _, info := range list {
+		byType[info.TaskType] = info
+	}
+	if got := byType["send_email"]; got.Workers != 1 || !got.Registered || got.LastSeen == nil {
+		t.Errorf("send_email = %+v, want one worker and registered", got)
+	}
+	if got := byType["run_query"]; got.Workers != 1 || got.Registered {
+		t.Errorf("run_query = %+v, want one worker only", got)
+	}
+	if got, ok := byType["resize_image"]; !ok || got.Workers != 0 || !got.Registered {
+		t.Errorf("resize_image = %+v, want registered without workers", got)
+	}
+
+	if err := types.DeregisterTaskType(ctx, "resize_image"); err != nil {
+		t.Fatalf("DeregisterTaskType() error = %v", err)
+	}
+	if err := types.DeregisterTaskType(ctx, "run_query"); !errors.Is(err, storage.ErrTaskTypeNotFound) {
+		t.Errorf("DeregisterTaskType() of a reported type error = %v, want ErrTaskTypeNotFound", err)
+	}
+	if list, err = types.ListTaskTypes(ctx); err != nil {
+		t.Fatalf("ListTaskTypes() after deregistering error = %v", err)
+	}
+	for _, info := range list {
+		if info.TaskType == "resize_image" {
+			t.Error("ListTaskTypes() still lists the deregistered type")
+		}
+	}
+}
+
+func testTenantFairShare(t *testing.T, s storage.Store) {
+	quotas, ok := s.(storage.TenantQuotas)
+	if !ok {
+		t.Skip("store does not enforce tenant quotas")
+	}
+
+	// The noisy tenant queued first, so plain FIFO would hand it the whole batch
+	for i := 0; i < 6; i++ {
+		createTask(t, s, models.CreateTaskRequest{Tenant: "noisy"})
+	}
+	for i := 0; i < 3; i++ {
+		createTask(t, s, models.CreateTaskRequest{Tenant: "quiet"})
+	}
+
+	countTenants := func(tasks []*models.Task) map[string]int {
+		tenants := map[string]int{}
+		for _, task := range tasks {
+			tenants[task.Tenant]++
+		}
+		return tenants
+	}
+
+	if tenants := countTenants(claim(t, s, "worker-1", 4, models.ClaimFilter{})); tenants["noisy"] != 2 || tenants["quiet"] != 2 {
+		t.Errorf("ClaimNextTasks() claimed tenants %v, want two noisy and two quiet tasks", tenants)
+	}
+
+	// At its max_running the noisy tenant leaves the batch to the others instead of shrinking it
+	two := 2
+	if err := quotas.SetTenantQuota(context.Background(), "noisy", models.SetTenantQuotaRequest{MaxRunning: &two}); err != nil {
+		t.Fatalf("SetTenantQuota() error = %v", err)
+	}
+	if tenants := countTenants(claim(t, s, "worker-1", 4, models.ClaimFilter{})); tenants["noisy"] != 0 || tenants["quiet"] != 1 {
+		t.Errorf("ClaimNextTasks() at the noisy quota claimed tenants %v, want the last quiet task only", tenants)
+	}
+}
+
+func testTenantQuotas(t *testing.T, s storage.Store) {
+	quotas, ok := s.(storage.TenantQuotas)
+	if !ok {
+		t.Skip("store does not enforce tenant quotas")
+	}
+
+	ctx := context.Background()
+	one := 1
+	if err := quotas.SetTenantQuota(ctx, "acme", models.SetTenantQuotaRequest{MaxQueued: &one}); err != nil {
+		t.Fatalf("SetTenantQuota() error = %v", err)
+	}
+	if err := quotas.SetTenantQuota(ctx, models.DefaultTenantQuota, models.SetTenantQuotaRequest{MaxRunning: &one}); err != nil {
+		t.Fatalf("SetTenantQuota(default) error = %v", err)
+	}
+
+	createTask(t, s, models.CreateTaskRequest{Tenant: "acme"})
+	var exceeded *storage.QuotaExceededError
+	if _, err := s.CreateTask(ctx, models.CreateTaskRequest{Name: "task", Type: "test", Tenant: "acme"}); !errors.As(err, &exceeded) {
+		t.Fatalf("CreateTask() past max_queued error = %v, want QuotaExceededError", err)
+	}
+	if exceeded.Tenant != "acme" || exceeded.MaxQueued != 1 || exceeded.Queued != 1 {
+		t.Errorf("QuotaExceededError = %+v, want acme at 1 of 1", exceeded)
+	}
+
+	// The default max_running lets one task of each tenant run at a time
+	createTask(t, s, models.CreateTaskRequest{Tenant: "globex"})
+	createTask(t, s, models.CreateTaskRequest{Tenant: "globex"})
+	claimed, err := s.ClaimNextTasks(ctx, "worker-1", 10, models.ClaimFilter{})
+	if err != nil {
+		t.Fatalf("ClaimNextTasks() error = %v", err)
+	}
+	tenants := map[string]int{}
+	for _, task := range claimed {
+		tenants[task.Tenant]++
+	}
+	if len(claimed) != 2 || tenants["acme"] != 1 || tenants["globex"] != 1 {
+		t.Errorf("ClaimNextTasks() claimed tenants %v, want one acme and one globex task", tenants)
+	}
+
+	list, err := quotas.ListTenantQuotas(ctx)
+	if err != nil {
+		t.Fatalf("ListTenantQuotas() error = %v", err)
+	}
+	if len(list) != 2 || list[1].Tenant != "acme" || list[1].Running != 1 || list[1].Queued != 0 {
+		t.Errorf("ListTenantQuotas() = %+v, want the default and acme with one running task", list)
+	}
+
+	if err := quotas.DeleteTenantQuota(ctx, "acme"); err != nil {
+		t.Fatalf("DeleteTenantQuota() error = %v", err)
+	}
+	if err := quotas.DeleteTenantQuota(ctx, "acme"); !errors.Is(err, storage.ErrTenantQuotaNotFound) {
+		t.Errorf("DeleteTenantQuota() of a deleted quota error = %v, want ErrTenantQuotaNotFound", err)
+	}
+}
+
+func testRedaction(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	customer := map[string]string{"customer_id": "42"}
+
+	failed := createTask(t, s, models.CreateTaskRequest{
+		Payload:    []byte(`{"to":"jane@example.com"}`),
+		Metadata:   customer,
+		MaxRetries: intPtr(0),
+	})
+	task := claimOne(t, s, "worker-1")
+	if err := s.MarkTaskFailed(ctx, task.ID, task.Lock(), "bounced: jane@example.com"); err != nil {
+		t.Fatalf("MarkTaskFailed() error = %v", err)
+	}
+
+	queued := createTask(t, s, models.CreateTaskRequest{Payload: []byte(`{"to":"joe@example.com"}`), Metadata: customer})
+	if _, err := s.RedactTask(ctx, queued.ID, nil); !errors.Is(err, storage.ErrTaskNotFinished) {
+		t.Errorf("RedactTask(queued) error = %v, want ErrTaskNotFinished", err)
+	}
+	if _, err := s.RedactTask(ctx, queued.ID+1000, nil); !errors.Is(err, storage.ErrTaskNotFound) {
+		t.Errorf("RedactTask(missing) error = %v, want ErrTaskNotFound", err)
+	}
+
+	// The version read at creation went stale when the task was claimed and failed
+	if _, err := s.RedactTask(ctx, failed.ID, &failed.Version); !errors.Is(err, storage.ErrVersionConflict) {
+		t.Errorf("RedactTask(stale version) error = %v, want ErrVersionConflict", err)
+	}
+
+	current := getTask(t, s, failed.ID)
+	redacted, err := s.RedactTask(ctx, failed.ID, &current.Version)
+	if err != nil {
+		t.Fatalf("RedactTask() error = %v", err)
+	}
+	got := getTask(t, s, failed.ID)
+	if string(got.Payload) != models.RedactedPayload || got.LastError == nil || *got.LastError != models.RedactedMessage || got.RedactedAt == nil {
+		t.Errorf("redacted task = payload %s last_error %v redacted_at %v", got.Payload, got.LastError, got.RedactedAt)
+	}
+	if redacted.RedactedAt == nil || got.Status != models.TaskStatusFailed {
+		t.Errorf("RedactTask() = redacted_at %v status %q, want redacted and still failed", redacted.RedactedAt, got.Status)
+	}
+
+	// Redacting again records nothing new
+	if _, err := s.RedactTask(ctx, failed.ID, nil); err != nil {
+		t.Fatalf("RedactTask(again) error = %v", err)
+	}
+	history, err := s.GetTaskHistory(ctx, failed.ID, failed.CreatedAt)
+	if err != nil {
+		t.Fatalf("GetTaskHistory() error = %v", err)
+	}
+	redactions := 0
+	for _, h := range history {
+		if h.ErrorMessage != nil && strings.Contains(*h.ErrorMessage, "jane") {
+			t.Errorf("history %s kept error message %q", h.EventType, *h.ErrorMessage)
+		}
+		if h.EventType == models.EventTaskRedacted {
+			redactions++
+		}
+	}
+	if redactions != 1 {
+		t.Errorf("history has %d task_redacted events, want 1", redactions)
+	}
+
+	// Bulk redaction only scrubs finished, matching tasks not redacted yet
+	task = claimOne(t, s, "worker-1")
+	if err := s.CompleteTask(ctx, task.ID, task.Lock()); err != nil {
+		t.Fatalf("CompleteTask() error = %v", err)
+	}
+	other := createTask(t, s, models.CreateTaskRequest{Metadata: map[string]string{"customer_id": "43"}})
+	task = claimOne(t, s, "worker-1")
+	if err := s.CompleteTask(ctx, task.ID, task.Lock()); err != nil {
+		t.Fatalf("CompleteTask() error = %v", err)
+	}
+
+	n, err := s.RedactTasks(ctx, models.RedactTasksRequest{Metadata: customer})
+	if err != nil || n != 1 {
+		t.Fatalf("RedactTasks() = %d, %v, want 1", n, err)
+	}
+	if got := getTask(t, s, queued.ID); got.RedactedAt == nil || string(got.Payload) != models.RedactedPayload {
+		t.Errorf("matching task not redacted: payload %s", got.Payload)
+	}
+	if got := getTask(t, s, other.ID); got.RedactedAt != nil {
+		t.Errorf("task of another customer was redacted")
+	}
+}
@@ -0,0 +1,220 @@
+// Package tlsconfig builds TLS configurations whose certificates are read from files and can be rotated
+// without a restart; the API server uses it for native HTTPS and the remote worker listener, and remote
+// workers use it to present their client certificate
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Files locates a PEM certificate chain, its private key and an optional CA bundle
+// With CAFile set, servers require client certificates signed by it and clients only trust servers it signed
+type Files struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
+}
+
+// Reloader serves the certificate and CA bundle of its files, swapping them in when the files change
+// Handshakes always use the last pair that loaded successfully, so a half-written rotation is never served
+type Reloader struct {
+	files Files
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	pool    *x509.CertPool // nil without a CA bundle
+	modTime time.Time      // latest modification time of the loaded files
+}
+
+// NewReloader loads files, failing if they are missing or do not form a valid pair
+func NewReloader(files Files) (*Reloader, error) {
+	if files.CertFile == "" || files.KeyFile == "" {
+		return nil, errors.New("certificate and key files are required")
+	}
+
+	r := &Reloader{files: files}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the files again, e.g. on SIGHUP; on error the current certificate stays in use
+func (r *Reloader) Reload() error {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.files.CertFile, r.files.KeyFile)
+	if err != nil {
+		return fmt.Errorf("load certificate: %w", err)
+	}
+	if cert.Leaf == nil {
+		// Only left unset with GODEBUG=x509keypairleaf=0
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return fmt.Errorf("parse certificate: %w", err)
+		}
+	}
+
+	var pool *x509.CertPool
+	if r.files.CAFile != "" {
+		pem, err := os.ReadFile(r.files.CAFile)
+		if err != nil {
+			return fmt.Errorf("read CA bundle: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("CA bundle %s contains no certificates", r.files.CAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert, r.pool, r.modTime = &cert, pool, modTime
+	r.mu.Unlock()
+	return nil
+}
+
+// Watch reloads the files every interval in which one of them was modified, until ctx is done
+// Failed reloads are logged and retried on the next change
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		modTime, err := r.latestModTime()
+		if err != nil {
+			slog.Error("Failed to check TLS certificate files", "error", err)
+			continue
+		}
+
+		r.mu.RLock()
+		changed := modTime.After(r.modTime)
+		r.mu.RUnlock()
+		if !changed {
+			continue
+		}
+
+		if err := r.Reload(); err != nil {
+			slog.Error("Failed to reload TLS certificate", "cert_file", r.files.CertFile, "error", err)
+			continue
+		}
+		slog.Info("TLS certificate reloaded", "cert_file", r.files.CertFile)
+	}
+}
+
+// ServerConfig returns a server configuration serving the current certificate
+// With a CA bundle, clients must present a certificate it signed (mutual TLS)
+func (r *Reloader) ServerConfig() *tls.Config {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return r.certificate(), nil
+		},
+	}
+
+	if r.files.CAFile != "" {
+		// ClientCAs is fixed, so the chain is verified against the current bundle instead, on every connection
+		// including resumed ones; unlike a per-connection config this keeps the ALPN protocols http.Server
+		// sets on its own copy of the config, so HTTP/2 still works
+		config.ClientAuth = tls.RequireAnyClientCert
+		config.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyPeer(cs, r.caPool(), x509.ExtKeyUsageClientAuth, "")
+		}
+	}
+
+	return config
+}
+
+// ClientConfig returns a client configuration presenting the current certificate to servers that ask for one
+// With a CA bundle, only servers it signed are trusted, otherwise the system roots; serverName is checked
+// against the server certificate and overrides the host of the dialed address
+func (r *Reloader) ClientConfig(serverName string) *tls.Config {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.certificate(), nil
+		},
+	}
+
+	if r.files.CAFile != "" {
+		// RootCAs is fixed as well, so the built-in verification is replaced by one against the current bundle
+		config.InsecureSkipVerify = true
+		config.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyPeer(cs, r.caPool(), x509.ExtKeyUsageServerAuth, cs.ServerName)
+		}
+	}
+
+	return config
+}
+
+// CommonName returns the subject common name of the current certificate
+func (r *Reloader) CommonName() string {
+	return r.certificate().Leaf.Subject.CommonName
+}
+
+// verifyPeer performs the verification crypto/tls would do with roots as ClientCAs or RootCAs
+// A non-empty dnsName must match the leaf certificate, as for servers
+func verifyPeer(cs tls.ConnectionState, roots *x509.CertPool, usage x509.ExtKeyUsage, dnsName string) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("peer presented no certificate")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       dnsName,
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{usage},
+	})
+	return err
+}
+
+func (r *Reloader) certificate() *tls.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert
+}
+
+func (r *Reloader) caPool() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.pool
+}
+
+// latestModTime is the most recent modification time among the files
+func (r *Reloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{r.files.CertFile, r.files.KeyFile, r.files.CAFile} {
+		if name == "" {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,281 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// authority is a throwaway CA issuing server and client certificates
+type authority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newAuthority(t *testing.T, name string) *authority {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &authority{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue writes a certificate for name signed by the authority, with the CA bundle, into dir
+func (a *authority) issue(t *testing.T, dir, name string, usage x509.ExtKeyUsage) Files {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, a.cert, &key.PublicKey, a.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	files := Files{
+		CertFile: filepath.Join(dir, name+".crt"),
+		KeyFile:  filepath.Join(dir, name+".key"),
+		CAFile:   filepath.Join(dir, name+"-ca.crt"),
+	}
+	for file, data := range map[string][]byte{
+		files.CertFile: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		files.KeyFile:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		files.CAFile:   a.pem,
+	} {
+		if err := os.WriteFile(file, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return files
+}
+
+// clientConfig presents the certificate of files and trusts servers signed by roots
+func clientConfig(t *testing.T, files Files, roots ...*authority) *tls.Config {
+	t.Helper()
+	cert, err := tls.LoadX509KeyPair(files.CertFile, files.KeyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	for _, root := range roots {
+		pool.AddCert(root.cert)
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}, RootCAs: pool, ServerName: "queue.internal"}
+}
+
+// handshake connects a client to a server over loopback and returns the errors of both sides
+// With TLS 1.3 the client finishes before the server has checked its certificate, so both are needed;
+// unlike an unbuffered pipe, loopback lets a client that rejects the server send its alert mid-flight
+func handshake(server, client *tls.Config) error {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	clientConn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		return err
+	}
+	defer clientConn.Close()
+	serverConn, err := ln.Accept()
+	if err != nil {
+		return err
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- tls.Server(serverConn, server).Handshake()
+		serverConn.Close()
+	}()
+
+	client = client.Clone()
+	client.ServerName = "queue.internal"
+	conn := tls.Client(clientConn, client)
+	clientErr := conn.Handshake()
+	if clientErr != nil {
+		clientConn.Close()
+	} else {
+		// Drain the pipe so the server can write its alert or close
+		go func() { _, _ = io.Copy(io.Discard, conn) }()
+	}
+	return errors.Join(clientErr, <-serverErr)
+}
+
+func TestMutualTLSWithRotation(t *testing.T) {
+	dir := t.TempDir()
+	ca := newAuthority(t, "ca")
+
+	server, err := NewReloader(ca.issue(t, dir, "queue.internal", x509.ExtKeyUsageServerAuth))
+	if err != nil {
+		t.Fatalf("NewReloader(server) error = %v", err)
+	}
+	clientFiles := ca.issue(t, dir, "worker-1", x509.ExtKeyUsageClientAuth)
+
+	if err := handshake(server.ServerConfig(), clientConfig(t, clientFiles, ca)); err != nil {
+		t.Fatalf("handshake error = %v", err)
+	}
+
+	// A client without a certificate is refused
+	anonymous := &tls.Config{RootCAs: x509.NewCertPool()}
+	anonymous.RootCAs.AddCert(ca.cert)
+	if err := handshake(server.ServerConfig(), anonymous); err == nil {
+		t.Error("handshake without a client certificate succeeded")
+	}
+
+	// Rotating the server onto a new CA takes effect on reload, for configs built before it
+	serverConfig := server.ServerConfig()
+	rotated := newAuthority(t, "ca-2")
+	rotated.issue(t, dir, "queue.internal", x509.ExtKeyUsageServerAuth)
+	if err := server.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if err := handshake(serverConfig, clientConfig(t, clientFiles, ca, rotated)); err == nil {
+		t.Error("handshake with a client of the retired CA succeeded")
+	}
+
+	rotatedClient := rotated.issue(t, dir, "worker-2", x509.ExtKeyUsageClientAuth)
+	if err := handshake(serverConfig, clientConfig(t, rotatedClient, rotated)); err != nil {
+		t.Errorf("handshake after rotation error = %v", err)
+	}
+}
+
+func TestClientConfigWithRotation(t *testing.T) {
+	dir := t.TempDir()
+	ca := newAuthority(t, "ca")
+
+	server, err := NewReloader(ca.issue(t, dir, "queue.internal", x509.ExtKeyUsageServerAuth))
+	if err != nil {
+		t.Fatalf("NewReloader(server) error = %v", err)
+	}
+	client, err := NewReloader(ca.issue(t, dir, "worker-1", x509.ExtKeyUsageClientAuth))
+	if err != nil {
+		t.Fatalf("NewReloader(client) error = %v", err)
+	}
+	if got := client.CommonName(); got != "worker-1" {
+		t.Errorf("CommonName() = %q, want worker-1", got)
+	}
+
+	clientConfig := client.ClientConfig("queue.internal")
+	if err := handshake(server.ServerConfig(), clientConfig); err != nil {
+		t.Fatalf("handshake error = %v", err)
+	}
+
+	// A server of another CA is not trusted
+	rotated := newAuthority(t, "ca-2")
+	rotated.issue(t, dir, "queue.internal", x509.ExtKeyUsageServerAuth)
+	if err := server.Reload(); err != nil {
+		t.Fatalf("Reload(server) error = %v", err)
+	}
+	if err := handshake(server.ServerConfig(), clientConfig); err == nil {
+		t.Error("handshake with a server of an untrusted CA succeeded")
+	}
+
+	// Rotating the client certificate and CA bundle takes effect on reload, for configs built before it
+	rotated.issue(t, dir, "worker-1", x509.ExtKeyUsageClientAuth)
+	if err := client.Reload(); err != nil {
+		t.Fatalf("Reload(client) error = %v", err)
+	}
+	if err := handshake(server.ServerConfig(), clientConfig); err != nil {
+		t.Errorf("handshake after rotation error = %v", err)
+	}
+}
+
+func TestServerConfigKeepsHTTPProtocols(t *testing.T) {
+	dir := t.TempDir()
+	ca := newAuthority(t, "ca")
+	server, err := NewReloader(ca.issue(t, dir, "queue.internal", x509.ExtKeyUsageServerAuth))
+	if err != nil {
+		t.Fatalf("NewReloader(server) error = %v", err)
+	}
+	clientFiles := ca.issue(t, dir, "worker-1", x509.ExtKeyUsageClientAuth)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{
+		TLSConfig: server.ServerConfig(),
+		Handler:   http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { _, _ = io.WriteString(w, r.Proto) }),
+	}
+	go func() { _ = srv.ServeTLS(ln, "", "") }()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	tests := []struct {
+		name      string
+		http2     bool
+		wantProto string
+	}{
+		{"http/2", true, "HTTP/2.0"},
+		{"http/1.1 only", false, "HTTP/1.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &http.Transport{TLSClientConfig: clientConfig(t, clientFiles, ca), ForceAttemptHTTP2: tt.http2}
+			defer transport.CloseIdleConnections()
+
+			resp, err := (&http.Client{Transport: transport}).Get("https://" + ln.Addr().String())
+			if err != nil {
+				t.Fatalf("GET error = %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.Proto != tt.wantProto {
+				t.Errorf("protocol = %s, want %s", resp.Proto, tt.wantProto)
+			}
+		})
+	}
+}
+
+func TestReloadKeepsCertificateOnError(t *testing.T) {
+	dir := t.TempDir()
+	files := newAuthority(t, "ca").issue(t, dir, "queue.internal", x509.ExtKeyUsageServerAuth)
+	r, err := NewReloader(files)
+	if err != nil {
+		t.Fatalf("NewReloader() error = %v", err)
+	}
+	before := r.certificate()
+
+	if err := os.WriteFile(files.CertFile, []byte("half-written"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reload(); err == nil {
+		t.Fatal("Reload() of a broken certificate error = nil")
+	}
+	if r.certificate() != before {
+		t.Error("Reload() replaced the certificate despite failing")
+	}
+}
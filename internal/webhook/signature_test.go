@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	body := []byte(`{"rule":"email-failures","status":"firing"}`)
+
+	req, _ := http.NewRequest(http.MethodPost, "https://hooks.example.com", nil)
+	Sign(req, "s3cret", body, now)
+
+	if err := Verify(req.Header, "s3cret", body, DefaultTolerance, now.Add(time.Minute)); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		body   []byte
+		at     time.Time
+		want   error
+	}{
+		{"wrong secret", "other", body, now, ErrInvalidSignature},
+		{"tampered body", "s3cret", []byte(`{"rule":"x"}`), now, ErrInvalidSignature},
+		{"replayed later", "s3cret", body, now.Add(10 * time.Minute), ErrExpired},
+	}
+	for _, tt := range tests {
+		if err := Verify(req.Header, tt.secret, tt.body, DefaultTolerance, tt.at); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Verify() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// A changed delivery id invalidates the signature
+	req.Header.Set(IDHeader, "another-id")
+	if err := Verify(req.Header, "s3cret", body, DefaultTolerance, now); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify(changed id) error = %v, want ErrInvalidSignature", err)
+	}
+
+	if err := Verify(http.Header{}, "s3cret", body, DefaultTolerance, now); !errors.Is(err, ErrMissingSignature) {
+		t.Errorf("Verify(unsigned) error = %v, want ErrMissingSignature", err)
+	}
+}
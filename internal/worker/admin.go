@@ -0,0 +1,225 @@
+package worker
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// runningTask is a task currently executing on one of the worker's goroutines
+type runningTask struct {
+	task      *models.Task
+	workerNum int
+	startedAt time.Time
+}
+
+// InFlightTask describes an executing task for the admin API
+type InFlightTask struct {
+	TaskID        int64      `json:"task_id"`
+	TaskName      string     `json:"task_name"`
+	TaskType      string     `json:"task_type"`
+	WorkerNum     int        `json:"worker_num"`
+	StartedAt     time.Time  `json:"started_at"`
+	RunningFor    float64    `json:"running_for_seconds"`
+	LockExpiresAt *time.Time `json:"lock_expires_at,omitempty"`
+}
+
+// trackRunning records that a goroutine started executing task
+func (w *Worker) trackRunning(workerNum int, task *models.Task) {
+	w.runningMu.Lock()
+	defer w.runningMu.Unlock()
+	w.running[task.ID] = runningTask{task: task, workerNum: workerNum, startedAt: time.Now()}
+}
+
+// untrackRunning removes a finished task from the running set
+func (w *Worker) untrackRunning(task *models.Task) {
+	w.runningMu.Lock()
+	defer w.runningMu.Unlock()
+	delete(w.running, task.ID)
+}
+
+// runningCount returns the number of tasks currently executing
+func (w *Worker) runningCount() int {
+	w.runningMu.Lock()
+	defer w.runningMu.Unlock()
+	return len(w.running)
+}
+
+// InFlightTasks returns the executing tasks, longest running first
+func (w *Worker) InFlightTasks() []InFlightTask {
+	w.runningMu.Lock()
+	tasks := make([]InFlightTask, 0, len(w.running))
+	for _, r := range w.running {
+		tasks = append(tasks, InFlightTask{
+			TaskID:        r.task.ID,
+			TaskName:      r.task.Name,
+			TaskType:      r.task.Type,
+			WorkerNum:     r.workerNum,
+			StartedAt:     r.startedAt,
+			RunningFor:    time.Since(r.startedAt).Seconds(),
+			LockExpiresAt: r.task.LockExpiresAt,
+		})
+	}
+	w.runningMu.Unlock()
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].StartedAt.Before(tasks[j].StartedAt)
+	})
+	return tasks
+}
+
+// Drain stops the worker from claiming new tasks; in-flight tasks run to completion
+// The worker keeps running and reports not ready, so it can be removed from rotation safely
+func (w *Worker) Drain() {
+	if !w.draining.Swap(true) {
+		slog.Info("Worker draining, no new tasks will be claimed", "in_flight", w.inFlight.Load())
+	}
+}
+
+// Draining reports whether Drain was called
+func (w *Worker) Draining() bool {
+	return w.draining.Load()
+}
+
+// RegisterAdminRoutes registers the worker admin endpoints on the given router
+// Probes and metrics are open; the operator routes require token as a bearer token and are not registered without one
+// It returns the operator group for further routes, or nil when token is empty
+func (w *Worker) RegisterAdminRoutes(r *gin.Engine, token string) *gin.RouterGroup {
+	r.GET("/healthz", w.handleHealthz)
+	r.GET("/health", w.handleHealth)
+	r.GET("/readiness", w.handleReadiness)
+	r.GET("/metrics", gin.WrapH(w.metrics.Handler()))
+
+	if token == "" {
+		return nil
+	}
+	ops := r.Group("/", requireAdminToken(token))
+	ops.GET("/tasks", w.handleInFlightTasks)
+	ops.POST("/drain", w.handleDrain)
+	return ops
+}
+
+// requireAdminToken answers 401 unless the request carries token as its bearer token
+func requireAdminToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(raw)), []byte(token)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="taskqueue-worker"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing or invalid admin token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// handleHealthz handles GET /healthz
+// The worker process is alive as long as it can answer
+func (w *Worker) handleHealthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "alive", "worker_id": w.workerID})
+}
+
+// handleHealth handles GET /health
+// Reports the store's components and dispatcher liveness, with 503 when any of them is degraded
+func (w *Worker) handleHealth(c *gin.Context) {
+	components := storage.CheckHealth(c.Request.Context(), w.store)
+	components = append(components, w.dispatcherHealth(time.Now()))
+
+	resp := models.NewHealthResponse(components)
+	status := http.StatusOK
+	if resp.Status != models.HealthHealthy {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, resp)
+}
+
+// dispatcherHealth reports whether the dispatcher loop is still iterating
+// The loop wakes at least every poll interval, so a much older tick means it is stuck
+func (w *Worker) dispatcherHealth(now time.Time) models.ComponentHealth {
+	c := models.ComponentHealth{
+		Name:   "dispatcher",
+		Status: models.HealthStatusOK,
+		Details: map[string]any{
+			"in_flight":       w.inFlight.Load(),
+			"running":         w.runningCount(),
+			"max_concurrency": w.maxConcurrency(),
+			"draining":        w.Draining(),
+		},
+	}
+
+	tick := w.dispatcherTick.Load()
+	if tick == 0 {
+		c.Status = models.HealthStatusDown
+		c.Error = "dispatcher not running"
+		return c
+	}
+
+	age := now.Sub(time.Unix(0, tick))
+	c.Details["last_tick_seconds_ago"] = age.Seconds()
+	if age > w.dispatcherStallAfter() {
+		c.Status = models.HealthStatusDegraded
+		c.Error = "dispatcher has not run for " + age.Truncate(time.Second).String()
+	}
+	return c
+}
+
+// dispatcherStallAfter is how long the dispatcher may go without an iteration before it counts as stalled
+func (w *Worker) dispatcherStallAfter() time.Duration {
+	return max(3*max(w.maxPollInterval, w.currentPollInterval()), time.Minute)
+}
+
+// handleReadiness handles GET /readiness
+// Not ready while draining, when the database is unreachable or while claims fail over
+// Reports maintenance mode when it stops claims
+func (w *Worker) handleReadiness(c *gin.Context) {
+	if w.Draining() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "draining"})
+		return
+	}
+
+	if !w.StoreAvailable() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "error": "database unavailable"})
+		return
+	}
+
+	if err := w.store.Ping(c.Request.Context()); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "error": "database unavailable"})
+		return
+	}
+
+	if mode, err := w.store.GetMaintenance(c.Request.Context()); err == nil && mode.Enabled && mode.StopClaims {
+		c.JSON(http.StatusOK, gin.H{"status": "maintenance", "maintenance": mode})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ready"})
+}
+
+// handleInFlightTasks handles GET /tasks
+// Lists the tasks this worker is executing right now
+func (w *Worker) handleInFlightTasks(c *gin.Context) {
+	tasks := w.InFlightTasks()
+	c.JSON(http.StatusOK, gin.H{
+		"worker_id":       w.workerID,
+		"max_concurrency": w.maxConcurrency(),
+		"in_flight":       w.inFlight.Load(),
+		"draining":        w.Draining(),
+		"tasks":           tasks,
+	})
+}
+
+// handleDrain handles POST /drain
+// Stops claiming; in-flight tasks finish normally
+func (w *Worker) handleDrain(c *gin.Context) {
+	w.Drain()
+	c.JSON(http.StatusAccepted, gin.H{
+		"status":    "draining",
+		"in_flight": w.inFlight.Load(),
+	})
+}
@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAdminOperatorRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		token  string
+		header string
+		want   int
+	}{
+		{"no token configured", "", "Bearer secret", http.StatusNotFound},
+		{"missing header", "secret", "", http.StatusUnauthorized},
+		{"wrong token", "secret", "Bearer other", http.StatusUnauthorized},
+		{"not bearer", "secret", "secret", http.StatusUnauthorized},
+		{"valid token", "secret", "Bearer secret", http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewWorker(nil, nil, Config{MaxConcurrency: 1})
+			r := gin.New()
+			w.RegisterAdminRoutes(r, tt.token)
+
+			req := httptest.NewRequest(http.MethodPost, "/drain", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if drained := rec.Code == http.StatusAccepted; w.Draining() != drained {
+				t.Errorf("Draining() = %v, want %v", w.Draining(), drained)
+			}
+		})
+	}
+}
+
+func TestAdminProbesNeedNoToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := NewWorker(nil, nil, Config{MaxConcurrency: 1})
+	r := gin.New()
+	w.RegisterAdminRoutes(r, "secret")
+
+	for _, path := range []string{"/healthz", "/metrics"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("GET %s = %d, want 200", path, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// recordTypeFailure counts a failed execution against the task type's circuit breaker
+// Records a circuit_opened event on the task whose failure opened the breaker
+func (w *Worker) recordTypeFailure(ctx context.Context, task *models.Task, execErr error) {
+	if w.breakerThreshold <= 0 {
+		return
+	}
+
+	opened, err := w.store.RecordTypeFailure(ctx, task.Type, execErr.Error(), w.breakerThreshold, w.breakerCooldown)
+	if err != nil {
+		slog.Error("Failed to record task type failure", "task_type", task.Type, "error", err)
+		return
+	}
+	if !opened {
+		return
+	}
+
+	slog.Warn("Circuit breaker opened, pausing task type",
+		"task_type", task.Type,
+		"threshold", w.breakerThreshold,
+		"cooldown", w.breakerCooldown,
+	)
+
+	errorMsg := fmt.Sprintf("circuit breaker opened for %s after %d consecutive failures: %s",
+		task.Type, w.breakerThreshold, execErr.Error())
+	history := models.TaskHistory{
+		TaskID:       task.ID,
+		Status:       models.TaskStatusRunning,
+		EventType:    models.EventCircuitOpened,
+		ErrorMessage: &errorMsg,
+		WorkerID:     &w.workerID,
+	}
+	if err := w.store.InsertHistory(ctx, history); err != nil {
+		slog.Error("Failed to insert circuit opened history", "task_id", task.ID, "error", err)
+	}
+}
+
+// recordTypeSuccess resets the consecutive failure count of the task type
+func (w *Worker) recordTypeSuccess(ctx context.Context, task *models.Task) {
+	if w.breakerThreshold <= 0 {
+		return
+	}
+
+	if err := w.store.RecordTypeSuccess(ctx, task.Type); err != nil {
+		slog.Error("Failed to record task type success", "task_type", task.Type, "error", err)
+	}
+}
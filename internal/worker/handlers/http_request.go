@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/webhook"
+)
+
+// httpResponseBodyLimit caps how much of a response body is kept in the task's result
+// Even fully escaped it stays under models.MaxTaskResultBytes
+const httpResponseBodyLimit = 8 << 10
+
+// httpMethods are the methods an http_request payload may use
+var httpMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// errRedirectNotAllowed stops redirects to hosts payloads may not call themselves
+var errRedirectNotAllowed = errors.New("redirect to a host that is not allowed")
+
+// errAddressNotAllowed stops connections to internal addresses outside the allowed networks
+var errAddressNotAllowed = errors.New("address is not allowed")
+
+// sharedAddressSpace is the carrier-grade NAT range, internal like the private ranges
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// HTTPRequestHandler handles http_request tasks: one call to a URL from the payload
+// Unexpected answers that retrying may fix (408, 429 and 5xx) are retried, honoring Retry-After;
+// any other unexpected status fails the task at once
+type HTTPRequestHandler struct {
+	client          *http.Client
+	allowedHosts    []string
+	allowedNetworks []netip.Prefix
+}
+
+// HTTPRequestConfig configures HTTPRequestHandler
+type HTTPRequestConfig struct {
+	AllowedHosts    []string       // hosts payloads may call, matching a host or any of its subdomains; empty = any host
+	AllowedNetworks []netip.Prefix // loopback, link-local and private addresses payloads may reach; empty = none
+}
+
+// HTTPResult is the result of an http_request task
+type HTTPResult struct {
+	StatusCode    int    `json:"status_code"`
+	Body          string `json:"body,omitempty"`
+	BodyTruncated bool   `json:"body_truncated,omitempty"` // only the first 8 KiB of the body are kept
+	DurationMs    int64  `json:"duration_ms"`
+}
+
+// NewHTTPRequestHandler creates an HTTP request handler
+// Every connection is checked against the address it actually dials, so neither DNS answers nor redirects
+// can reach an internal address outside config.AllowedNetworks
+func NewHTTPRequestHandler(config HTTPRequestConfig) *HTTPRequestHandler {
+	hosts := make([]string, 0, len(config.AllowedHosts))
+	for _, host := range config.AllowedHosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+
+	h := &HTTPRequestHandler{allowedHosts: hosts, allowedNetworks: config.AllowedNetworks}
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: h.checkAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// A proxy would dial the target itself, out of reach of the address check
+	transport.Proxy = nil
+
+	h.client = &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			if !h.hostAllowed(req.URL.Hostname()) {
+				return fmt.Errorf("%w: %s", errRedirectNotAllowed, req.URL.Hostname())
+			}
+			return nil
+		},
+	}
+	return h
+}
+
+func (h *HTTPRequestHandler) Type() models.TaskType {
+	return models.TaskTypeHTTPRequest
+}
+
+func (h *HTTPRequestHandler) Execute(ctx context.Context, payload json.RawMessage) error {
+	var req struct {
+		Method         string            `json:"method"`
+		URL            string            `json:"url"`
+		Headers        map[string]string `json:"headers"`
+		Body           json.RawMessage   `json:"body"`
+		ExpectedStatus []int             `json:"expected_status"`
+		SigningSecret  string            `json:"signing_secret"`
+	}
+
+	// Retrying cannot fix a malformed payload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return models.Permanent(fmt.Errorf("invalid payload: %w", err))
+	}
+
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	if !slices.Contains(httpMethods, method) {
+		return models.Permanent(fmt.Errorf("unsupported method: %s", req.Method))
+	}
+
+	target, err := url.Parse(req.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return models.Permanent(errors.New("url must be an absolute http or https URL"))
+	}
+	if !h.hostAllowed(target.Hostname()) {
+		return models.Permanent(fmt.Errorf("host %s is not allowed", target.Hostname()))
+	}
+
+	// A JSON string is sent as is, any other JSON value as its encoding
+	body, contentType := requestBody(req.Body)
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return models.Permanent(fmt.Errorf("invalid request: %w", err))
+	}
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	for name, value := range req.Headers {
+		httpReq.Header.Set(name, value)
+	}
+
+	// Continue the trace of the request that created the task
+	metadata := models.MetadataFromContext(ctx)
+	for _, key := range []string{models.MetadataTraceParent, models.MetadataTraceState} {
+		if value := metadata[key]; value != "" {
+			httpReq.Header.Set(key, value)
+		}
+	}
+
+	if req.SigningSecret != "" {
+		webhook.Sign(httpReq, req.SigningSecret, body, time.Now())
+	}
+
+	slog.Info("Sending HTTP request", "method", method, "host", target.Host, "path", target.Path)
+	started := time.Now()
+	resp, err := h.client.Do(httpReq)
+	if err != nil {
+		if errors.Is(err, errRedirectNotAllowed) || errors.Is(err, errAddressNotAllowed) {
+			return models.Permanent(fmt.Errorf("HTTP request failed: %w", err))
+		}
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, httpResponseBodyLimit+1))
+	if err != nil {
+		return fmt.Errorf("read HTTP response: %w", err)
+	}
+	result := HTTPResult{
+		StatusCode: resp.StatusCode,
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	if len(respBody) > httpResponseBodyLimit {
+		respBody, result.BodyTruncated = respBody[:httpResponseBodyLimit], true
+	}
+	result.Body = strings.ToValidUTF8(string(respBody), "\uFFFD")
+
+	if !expectedStatus(resp.StatusCode, req.ExpectedStatus) {
+		return statusError(resp, respBody)
+	}
+
+	slog.Info("HTTP request succeeded", "method", method, "host", target.Host, "status", resp.StatusCode, "duration_ms", result.DurationMs)
+	return models.SetResult(ctx, result)
+}
+
+// hostAllowed reports whether host is one of the allowed hosts or a subdomain of one
+func (h *HTTPRequestHandler) hostAllowed(host string) bool {
+	if len(h.allowedHosts) == 0 {
+		return true
+	}
+
+	host = strings.ToLower(host)
+	for _, allowed := range h.allowedHosts {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAddress refuses to connect to loopback, link-local, private and unspecified addresses
+// unless one of the allowed networks contains them
+// It runs after name resolution, on the address of every connection attempt
+func (h *HTTPRequestHandler) checkAddress(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errAddressNotAllowed, address)
+	}
+
+	addr := addrPort.Addr().Unmap()
+	if !internalAddress(addr) {
+		return nil
+	}
+	for _, network := range h.allowedNetworks {
+		if network.Contains(addr) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", errAddressNotAllowed, addr)
+}
+
+// internalAddress reports whether addr belongs to the host or its private networks rather than the internet
+func internalAddress(addr netip.Addr) bool {
+	return addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsUnspecified() ||
+		sharedAddressSpace.Contains(addr)
+}
+
+// requestBody returns the bytes to send for a payload body and their default content type
+func requestBody(raw json.RawMessage) ([]byte, string) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, ""
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return []byte(text), "text/plain; charset=utf-8"
+	}
+	return raw, "application/json"
+}
+
+// expectedStatus reports whether status is one of expected, or any 2xx status when none are listed
+func expectedStatus(status int, expected []int) bool {
+	if len(expected) == 0 {
+		return status >= 200 && status < 300
+	}
+	return slices.Contains(expected, status)
+}
+
+// statusError classifies an unexpected response status
+// 408, 429 and 5xx are retried, after Retry-After seconds when the server sent one; anything else is permanent
+func statusError(resp *http.Response, body []byte) error {
+	detail := body
+	if len(detail) > 1024 {
+		detail = detail[:1024]
+	}
+	err := fmt.Errorf("unexpected HTTP status %d: %s", resp.StatusCode, detail)
+
+	switch {
+	case resp.StatusCode == http.StatusRequestTimeout,
+		resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode >= 500:
+		if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && seconds > 0 {
+			return models.RetryAfter(err, time.Duration(seconds)*time.Second)
+		}
+		return err
+	default:
+		return models.Permanent(err)
+	}
+}
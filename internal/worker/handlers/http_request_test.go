@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// loopback lets tests reach their httptest servers
+var loopback = []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}
+
+func TestHTTPRequestTargets(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer target.Close()
+
+	// Redirects to the same server under another name
+	targetURL, _ := url.Parse(target.URL)
+	byName := "http://localhost:" + targetURL.Port() + "/"
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, byName, http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	// Redirects to another loopback address, refused before it is dialed
+	redirectInternal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://127.0.0.2:"+targetURL.Port()+"/", http.StatusFound)
+	}))
+	defer redirectInternal.Close()
+
+	tests := []struct {
+		name      string
+		config    HTTPRequestConfig
+		url       string
+		wantErr   error
+		permanent bool
+	}{
+		{"allowed network", HTTPRequestConfig{AllowedNetworks: loopback}, target.URL, nil, false},
+		{"loopback by default", HTTPRequestConfig{}, target.URL, errAddressNotAllowed, true},
+		{"name resolving to loopback", HTTPRequestConfig{}, byName, errAddressNotAllowed, true},
+		{"host outside the allowlist", HTTPRequestConfig{AllowedHosts: []string{"example.com"}, AllowedNetworks: loopback}, target.URL, nil, true},
+		{"redirect outside the allowlist", HTTPRequestConfig{AllowedHosts: []string{"127.0.0.1"}, AllowedNetworks: loopback}, redirect.URL, errRedirectNotAllowed, true},
+		{"redirect to an internal address", HTTPRequestConfig{AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")}}, redirectInternal.URL, errAddressNotAllowed, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, result := models.ContextWithResult(context.Background())
+			h := NewHTTPRequestHandler(tt.config)
+			payload, _ := json.Marshal(map[string]string{"url": tt.url})
+
+			err := h.Execute(ctx, payload)
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Execute() error = %v, want %v", err, tt.wantErr)
+			}
+			if models.IsPermanent(err) != tt.permanent {
+				t.Fatalf("Execute() error = %v, want permanent %v", err, tt.permanent)
+			}
+			if tt.permanent {
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			var got HTTPResult
+			if err := json.Unmarshal(result(), &got); err != nil || got.StatusCode != http.StatusOK || got.Body != "ok" {
+				t.Errorf("result = %s, want status 200 and body ok", result())
+			}
+		})
+	}
+}
+
+func TestHTTPRequestCheckAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		allowed []netip.Prefix
+		want    bool
+	}{
+		{"93.184.216.34:443", nil, true},
+		{"[2606:2800:220:1:248:1893:25c8:1946]:443", nil, true},
+		{"127.0.0.1:80", nil, false},
+		{"[::1]:80", nil, false},
+		{"[::ffff:127.0.0.1]:80", nil, false},
+		{"0.0.0.0:80", nil, false},
+		{"10.1.2.3:80", nil, false},
+		{"172.16.0.1:80", nil, false},
+		{"192.168.1.1:80", nil, false},
+		{"[fd00::1]:80", nil, false},
+		{"169.254.169.254:80", nil, false},
+		{"[fe80::1]:80", nil, false},
+		{"100.64.0.1:80", nil, false},
+		{"10.1.2.3:80", []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")}, true},
+		{"10.2.0.1:80", []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")}, false},
+	}
+
+	for _, tt := range tests {
+		h := NewHTTPRequestHandler(HTTPRequestConfig{AllowedNetworks: tt.allowed})
+		err := h.checkAddress("tcp", tt.address, nil)
+		if got := err == nil; got != tt.want {
+			t.Errorf("checkAddress(%s) with %v = %v, want allowed %v", tt.address, tt.allowed, err, tt.want)
+		}
+	}
+}
+
+func TestHTTPRequestHostAllowed(t *testing.T) {
+	h := NewHTTPRequestHandler(HTTPRequestConfig{AllowedHosts: []string{" Example.com ", "api.internal"}})
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"example.com", true},
+		{"EXAMPLE.COM", true},
+		{"hooks.example.com", true},
+		{"notexample.com", false},
+		{"example.com.evil.net", false},
+		{"api.internal", true},
+		{"internal", false},
+	}
+
+	for _, tt := range tests {
+		if got := h.hostAllowed(tt.host); got != tt.want {
+			t.Errorf("hostAllowed(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPRequestStatus(t *testing.T) {
+	tests := []struct {
+		status     int
+		retryAfter string
+		permanent  bool
+		delay      time.Duration
+	}{
+		{http.StatusServiceUnavailable, "30", false, 30 * time.Second},
+		{http.StatusTooManyRequests, "", false, 0},
+		{http.StatusNotFound, "", true, 0},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if tt.retryAfter != "" {
+				w.Header().Set("Retry-After", tt.retryAfter)
+			}
+			w.WriteHeader(tt.status)
+		}))
+
+		h := NewHTTPRequestHandler(HTTPRequestConfig{AllowedNetworks: loopback})
+		payload, _ := json.Marshal(map[string]string{"url": server.URL})
+		err := h.Execute(context.Background(), payload)
+		server.Close()
+
+		if err == nil || models.IsPermanent(err) != tt.permanent {
+			t.Errorf("status %d: Execute() error = %v, want permanent %v", tt.status, err, tt.permanent)
+		}
+		if delay, _ := models.RetryDelay(err); delay != tt.delay {
+			t.Errorf("status %d: retry delay = %v, want %v", tt.status, delay, tt.delay)
+		}
+	}
+}
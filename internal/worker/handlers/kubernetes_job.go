@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/kube"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/worker"
+)
+
+// Labels and annotations set on every Job the handler creates
+const (
+	jobManagedByLabel      = "app.kubernetes.io/managed-by"
+	jobManagedBy           = "taskqueue"
+	jobRequestIDAnnotation = "taskqueue/request-id"
+	jobContainerName       = "job"
+)
+
+// jobLogLines and jobLogBytes bound the end of the pod log kept in the task's result
+const (
+	jobLogLines = 50
+	jobLogBytes = 8 << 10
+)
+
+// jobCleanupTimeout bounds deleting the Job of a cancelled task, whose own context is already done
+const jobCleanupTimeout = 10 * time.Second
+
+// jobStartFailures are the reasons a container waits with that no retry can fix
+var jobStartFailures = []string{"ErrImagePull", "ImagePullBackOff", "InvalidImageName", "CreateContainerConfigError", "CreateContainerError"}
+
+// jobEnvName restricts the variables a payload may set
+var jobEnvName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// KubernetesJobConfig configures KubernetesJobHandler
+type KubernetesJobConfig struct {
+	AllowedImages    []string          // image prefixes payloads may run, e.g. registry.example.com/jobs/; empty allows every image
+	ServiceAccount   string            // service account of the Job pods, empty = the namespace default
+	PollInterval     time.Duration     // between Job status checks
+	TTLAfterFinished time.Duration     // how long finished Jobs are kept before Kubernetes deletes them
+	DefaultLimits    map[string]string // resource limits unless the payload sets its own cpu or memory, e.g. {"cpu": "1", "memory": "512Mi"}
+}
+
+// KubernetesJobHandler handles k8s_job tasks: it runs a payload's image and command as a Kubernetes Job and waits for it
+// The Job never retries itself, so the queue's retry policy is the only one; a cancelled task deletes its Job
+type KubernetesJobHandler struct {
+	client *kube.Client
+	config KubernetesJobConfig
+}
+
+// KubernetesJobResult is the result of a k8s_job task
+type KubernetesJobResult struct {
+	Job        string `json:"job"`
+	Namespace  string `json:"namespace"`
+	Pod        string `json:"pod,omitempty"`
+	ExitCode   int32  `json:"exit_code"`
+	LogTail    string `json:"log_tail,omitempty"` // last 50 lines, at most 8 KiB
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// NewKubernetesJobHandler creates a handler running Jobs through client
+func NewKubernetesJobHandler(client *kube.Client, config KubernetesJobConfig) *KubernetesJobHandler {
+	return &KubernetesJobHandler{client: client, config: config}
+}
+
+func (h *KubernetesJobHandler) Type() models.TaskType {
+	return models.TaskTypeKubernetesJob
+}
+
+func (h *KubernetesJobHandler) Execute(ctx context.Context, payload json.RawMessage) error {
+	req, err := worker.DecodePayload[struct {
+		Image   string            `json:"image" binding:"required"`
+		Command []string          `json:"command"`
+		Args    []string          `json:"args"`
+		Env     map[string]string `json:"env"`
+		CPU     string            `json:"cpu"`
+		Memory  string            `json:"memory"`
+	}](payload)
+	if err != nil {
+		return err
+	}
+	if !h.imageAllowed(req.Image) {
+		return models.Permanent(fmt.Errorf("image is not allowed: %s", req.Image))
+	}
+
+	job, err := h.jobFor(ctx, req.Image, req.Command, req.Args, req.Env, req.CPU, req.Memory)
+	if err != nil {
+		return models.Permanent(err)
+	}
+
+	started := time.Now()
+	created, err := h.client.CreateJob(ctx, job)
+	if err != nil {
+		return kubeError("create job", err)
+	}
+	name := created.Metadata.Name
+	slog.Info("Created Kubernetes job", "job", name, "namespace", h.client.Namespace(), "image", req.Image)
+
+	finished, err := h.wait(ctx, name)
+	if err != nil {
+		// Leave nothing running for an attempt the queue gave up on
+		h.deleteJob(name)
+		return err
+	}
+
+	result := KubernetesJobResult{
+		Job:        name,
+		Namespace:  h.client.Namespace(),
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	terminated := h.describePod(ctx, name, &result)
+
+	if failed := finished.Status.Condition(kube.JobFailed); failed != nil {
+		reason := failed.Reason
+		if terminated != nil && terminated.Reason != "" {
+			reason += ", " + terminated.Reason // e.g. OOMKilled
+		}
+		return fmt.Errorf("job %s failed (%s, exit code %d): %s", name, reason, result.ExitCode, lastLine(result.LogTail))
+	}
+
+	slog.Info("Kubernetes job succeeded", "job", name, "duration_ms", result.DurationMs)
+	return models.SetResult(ctx, result)
+}
+
+// jobFor builds the Job running image
+// Its active deadline ends with the task's own, so Kubernetes stops a Job the queue no longer waits for
+func (h *KubernetesJobHandler) jobFor(ctx context.Context, image string, command, args []string, env map[string]string, cpu, memory string) (*kube.Job, error) {
+	container := kube.Container{
+		Name:    jobContainerName,
+		Image:   image,
+		Command: command,
+		Args:    args,
+	}
+
+	for name, value := range env {
+		if !jobEnvName.MatchString(name) {
+			return nil, fmt.Errorf("invalid environment variable name: %s", name)
+		}
+		container.Env = append(container.Env, kube.EnvVar{Name: name, Value: value})
+	}
+	slices.SortFunc(container.Env, func(a, b kube.EnvVar) int { return strings.Compare(a.Name, b.Name) })
+
+	limits := map[string]string{}
+	for resource, quantity := range h.config.DefaultLimits {
+		limits[resource] = quantity
+	}
+	if cpu != "" {
+		limits["cpu"] = cpu
+	}
+	if memory != "" {
+		limits["memory"] = memory
+	}
+	if len(limits) > 0 {
+		// Requests default to the limits, so the Job is scheduled where it fits
+		container.Resources = &kube.ResourceRequirements{Limits: limits}
+	}
+
+	labels := map[string]string{jobManagedByLabel: jobManagedBy}
+	annotations := map[string]string{}
+	if requestID := models.MetadataFromContext(ctx)[models.MetadataRequestID]; requestID != "" {
+		annotations[jobRequestIDAnnotation] = requestID
+	}
+
+	backoffLimit := int32(0)
+	ttl := int32(h.config.TTLAfterFinished.Seconds())
+	job := &kube.Job{
+		APIVersion: "batch/v1",
+		Kind:       "Job",
+		Metadata: kube.ObjectMeta{
+			GenerateName: "taskqueue-",
+			Labels:       labels,
+			Annotations:  annotations,
+		},
+		Spec: kube.JobSpec{
+			BackoffLimit:            &backoffLimit,
+			TTLSecondsAfterFinished: &ttl,
+			Template: kube.PodTemplateSpec{
+				Metadata: kube.ObjectMeta{Labels: labels},
+				Spec: kube.PodSpec{
+					RestartPolicy:      "Never",
+					ServiceAccountName: h.config.ServiceAccount,
+					Containers:         []kube.Container{container},
+				},
+			},
+		},
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		seconds := max(int64(time.Until(deadline).Seconds()), 1)
+		job.Spec.ActiveDeadlineSeconds = &seconds
+	}
+
+	return job, nil
+}
+
+// wait polls the Job until it completes or fails
+// A pod that cannot start, e.g. because its image does not exist, fails the task at once
+func (h *KubernetesJobHandler) wait(ctx context.Context, name string) (*kube.Job, error) {
+	ticker := time.NewTicker(h.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		job, err := h.client.GetJob(ctx, name)
+		if err != nil {
+			if kube.IsNotFound(err) {
+				return nil, fmt.Errorf("job %s was deleted before it finished", name)
+			}
+			// One failed check does not fail the task; the deadline bounds how long this goes on
+			slog.Warn("Failed to check Kubernetes job", "job", name, "error", err)
+			continue
+		}
+		if job.Status.Condition(kube.JobComplete) != nil || job.Status.Condition(kube.JobFailed) != nil {
+			return job, nil
+		}
+
+		if waiting := h.startFailure(ctx, name); waiting != nil {
+			return nil, models.Permanent(fmt.Errorf("job %s cannot start (%s): %s", name, waiting.Reason, waiting.Message))
+		}
+	}
+}
+
+// startFailure returns why the Job's container cannot start, nil while it can
+func (h *KubernetesJobHandler) startFailure(ctx context.Context, name string) *kube.ContainerStateWaiting {
+	pods, err := h.client.JobPods(ctx, name)
+	if err != nil {
+		return nil
+	}
+	for _, pod := range pods {
+		for _, status := range pod.Status.ContainerStatuses {
+			if waiting := status.State.Waiting; waiting != nil && slices.Contains(jobStartFailures, waiting.Reason) {
+				return waiting
+			}
+		}
+	}
+	return nil
+}
+
+// describePod fills in the pod, exit code and log tail of a finished Job, best-effort,
+// and returns how its container terminated
+func (h *KubernetesJobHandler) describePod(ctx context.Context, name string, result *KubernetesJobResult) *kube.ContainerStateTerminated {
+	pods, err := h.client.JobPods(ctx, name)
+	if err != nil || len(pods) == 0 {
+		slog.Warn("Failed to read Kubernetes job pod", "job", name, "error", err)
+		return nil
+	}
+
+	pod := pods[len(pods)-1]
+	result.Pod = pod.Metadata.Name
+	if logs, err := h.client.PodLogs(ctx, pod.Metadata.Name, jobLogLines, jobLogBytes); err == nil {
+		result.LogTail = strings.ToValidUTF8(logs, "\uFFFD")
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == jobContainerName && status.State.Terminated != nil {
+			result.ExitCode = status.State.Terminated.ExitCode
+			return status.State.Terminated
+		}
+	}
+	return nil
+}
+
+// deleteJob deletes a Job whose task stopped waiting for it, with a context of its own
+func (h *KubernetesJobHandler) deleteJob(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobCleanupTimeout)
+	defer cancel()
+
+	if err := h.client.DeleteJob(ctx, name); err != nil && !kube.IsNotFound(err) {
+		slog.Error("Failed to delete Kubernetes job", "job", name, "error", err)
+	}
+}
+
+// imageAllowed reports whether image starts with one of the allowed prefixes
+// A prefix must end at a reference boundary (/, : or @), so registry.example.com does not allow
+// registry.example.com.evil.io/x or registry.example.com-attacker/x
+func (h *KubernetesJobHandler) imageAllowed(image string) bool {
+	if len(h.config.AllowedImages) == 0 {
+		return true
+	}
+	for _, prefix := range h.config.AllowedImages {
+		if prefix == "" || !strings.HasPrefix(image, prefix) {
+			continue
+		}
+		if len(image) == len(prefix) || strings.ContainsRune("/:@", rune(prefix[len(prefix)-1])) ||
+			strings.ContainsRune("/:@", rune(image[len(prefix)])) {
+			return true
+		}
+	}
+	return false
+}
+
+// kubeError classifies a failed Kubernetes API call
+// A Job the API server rejects as invalid fails the task at once; anything else is retried
+func kubeError(action string, err error) error {
+	var status *kube.StatusError
+	if errors.As(err, &status) && (status.Code == http.StatusUnprocessableEntity || status.Code == http.StatusBadRequest) {
+		return models.Permanent(fmt.Errorf("%s: %w", action, err))
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import "testing"
+
+func TestImageAllowed(t *testing.T) {
+	h := &KubernetesJobHandler{config: KubernetesJobConfig{
+		AllowedImages: []string{"registry.example.com", "ghcr.io/acme/", "docker.io/library/busybox"},
+	}}
+
+	tests := []struct {
+		image string
+		want  bool
+	}{
+		{"registry.example.com/jobs/report:1.2", true},
+		{"registry.example.com:5000/jobs/report", true},
+		{"registry.example.com.evil.io/x", false},
+		{"registry.example.com-attacker/x", false},
+		{"registry.example.comx/x", false},
+		{"ghcr.io/acme/worker:latest", true},
+		{"ghcr.io/acme-evil/worker", false},
+		{"docker.io/library/busybox", true},
+		{"docker.io/library/busybox:1.36", true},
+		{"docker.io/library/busybox@sha256:abc", true},
+		{"docker.io/library/busybox-evil", false},
+		{"quay.io/other/image", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if got := h.imageAllowed(tt.image); got != tt.want {
+				t.Errorf("imageAllowed(%q) = %v, want %v", tt.image, got, tt.want)
+			}
+		})
+	}
+
+	if !(&KubernetesJobHandler{}).imageAllowed("anything/at:all") {
+		t.Error("without an allowlist every image must be allowed")
+	}
+}
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/blobstore"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// fileProgressInterval is how often a running process_file task logs how far it got
+const fileProgressInterval = 10 * time.Second
+
+// errFileTooLarge stops reading an input beyond the configured maximum
+var errFileTooLarge = errors.New("input exceeds the maximum size")
+
+// fileTransform writes the transformation of src to dst
+type fileTransform struct {
+	apply       func(dst io.Writer, src io.Reader) error
+	contentType string // of the output, empty = the input's
+}
+
+// fileTransforms are the transformations a process_file payload can name
+var fileTransforms = map[string]fileTransform{
+	"copy":         {apply: copyFile},
+	"gzip":         {apply: gzipFile, contentType: "application/gzip"},
+	"gunzip":       {apply: gunzipFile, contentType: "application/octet-stream"},
+	"csv_to_jsonl": {apply: csvToJSONL, contentType: "application/x-ndjson"},
+}
+
+// FileTransforms returns the names of the transformations process_file tasks can apply
+func FileTransforms() []string {
+	names := make([]string, 0, len(fileTransforms))
+	for name := range fileTransforms {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ProcessFileConfig configures ProcessFileHandler
+type ProcessFileConfig struct {
+	MaxBytes int64  // largest input and output, 0 = unlimited
+	TempDir  string // where outputs are staged before upload, empty = the system default
+}
+
+// ProcessFileHandler handles process_file tasks: it downloads an input object, transforms it and uploads the result
+// The output is staged in a temporary file first, so a failed transformation never leaves a partial object behind
+type ProcessFileHandler struct {
+	store  blobstore.Store
+	config ProcessFileConfig
+}
+
+// ProcessFileResult is the result of a process_file task
+type ProcessFileResult struct {
+	Input      string `json:"input"`
+	Output     string `json:"output"`
+	Transform  string `json:"transform"`
+	BytesIn    int64  `json:"bytes_in"`
+	BytesOut   int64  `json:"bytes_out"`
+	SHA256     string `json:"sha256"` // of the output
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// NewProcessFileHandler creates a handler reading and writing objects in store
+func NewProcessFileHandler(store blobstore.Store, config ProcessFileConfig) *ProcessFileHandler {
+	return &ProcessFileHandler{store: store, config: config}
+}
+
+func (h *ProcessFileHandler) Type() models.TaskType {
+	return models.TaskTypeProcessFile
+}
+
+func (h *ProcessFileHandler) Execute(ctx context.Context, payload json.RawMessage) error {
+	var req struct {
+		Input     string `json:"input"`
+		Output    string `json:"output"`
+		Transform string `json:"transform"`
+	}
+
+	// Retrying cannot fix a malformed payload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return models.Permanent(fmt.Errorf("invalid payload: %w", err))
+	}
+	if err := blobstore.ValidateKey(req.Input); err != nil {
+		return models.Permanent(fmt.Errorf("input: %w", err))
+	}
+	if err := blobstore.ValidateKey(req.Output); err != nil {
+		return models.Permanent(fmt.Errorf("output: %w", err))
+	}
+	if req.Input == req.Output {
+		return models.Permanent(errors.New("output must differ from input"))
+	}
+	if req.Transform == "" {
+		req.Transform = "copy"
+	}
+	transform, ok := fileTransforms[req.Transform]
+	if !ok {
+		return models.Permanent(fmt.Errorf("unknown transform: %s", req.Transform))
+	}
+
+	started := time.Now()
+	object, err := h.store.Get(ctx, req.Input)
+	if err != nil {
+		if errors.Is(err, blobstore.ErrNotFound) {
+			return models.Permanent(err)
+		}
+		return err
+	}
+	defer func() { _ = object.Body.Close() }()
+	if h.config.MaxBytes > 0 && object.Size > h.config.MaxBytes {
+		return models.Permanent(fmt.Errorf("%w: %s is %d bytes, at most %d are processed", errFileTooLarge, req.Input, object.Size, h.config.MaxBytes))
+	}
+
+	staged, err := os.CreateTemp(h.config.TempDir, "process-file-*")
+	if err != nil {
+		return fmt.Errorf("stage output: %w", err)
+	}
+	defer func() {
+		_ = staged.Close()
+		_ = os.Remove(staged.Name())
+	}()
+
+	input := &progressReader{reader: object.Body, limit: h.config.MaxBytes}
+	stopProgress := input.logEvery(fileProgressInterval, req.Input, object.Size)
+	hash := sha256.New()
+	output := &countingWriter{writer: io.MultiWriter(staged, hash)}
+
+	slog.Info("Processing file", "input", req.Input, "output", req.Output, "transform", req.Transform, "size", object.Size)
+	err = transform.apply(output, input)
+	stopProgress()
+	if err != nil {
+		return fileError(ctx, req.Input, err)
+	}
+	if h.config.MaxBytes > 0 && output.n > h.config.MaxBytes {
+		return models.Permanent(fmt.Errorf("output of %s exceeds %d bytes", req.Input, h.config.MaxBytes))
+	}
+
+	if _, err := staged.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("stage output: %w", err)
+	}
+	contentType := transform.contentType
+	if contentType == "" {
+		contentType = object.ContentType
+	}
+	if err := h.store.Put(ctx, req.Output, staged, output.n, contentType); err != nil {
+		return err
+	}
+
+	result := ProcessFileResult{
+		Input:      req.Input,
+		Output:     req.Output,
+		Transform:  req.Transform,
+		BytesIn:    input.n.Load(),
+		BytesOut:   output.n,
+		SHA256:     hex.EncodeToString(hash.Sum(nil)),
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	slog.Info("File processed", "input", req.Input, "output", req.Output, "bytes_in", result.BytesIn, "bytes_out", result.BytesOut, "duration_ms", result.DurationMs)
+	return models.SetResult(ctx, result)
+}
+
+// fileError classifies a failed transformation
+// An input the transformation cannot parse, or one too large, fails the task at once; a broken download is retried
+func fileError(ctx context.Context, input string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("process %s: %w", input, ctxErr)
+	}
+
+	var parseErr *csv.ParseError
+	if errors.Is(err, errFileTooLarge) || errors.Is(err, gzip.ErrHeader) || errors.Is(err, gzip.ErrChecksum) || errors.As(err, &parseErr) {
+		return models.Permanent(fmt.Errorf("process %s: %w", input, err))
+	}
+	return fmt.Errorf("process %s: %w", input, err)
+}
+
+func copyFile(dst io.Writer, src io.Reader) error {
+	_, err := io.Copy(dst, src)
+	return err
+}
+
+func gzipFile(dst io.Writer, src io.Reader) error {
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func gunzipFile(dst io.Writer, src io.Reader) error {
+	zr, err := gzip.NewReader(src)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, zr); err != nil {
+		return err
+	}
+	return zr.Close()
+}
+
+// csvToJSONL turns a CSV file with a header row into one JSON object per row, keyed by the header
+func csvToJSONL(dst io.Writer, src io.Reader) error {
+	reader := csv.NewReader(src)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	header = slices.Clone(header)
+
+	out := bufio.NewWriter(dst)
+	encoder := json.NewEncoder(out)
+	row := make(map[string]string, len(header))
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		for i, name := range header {
+			row[name] = record[i]
+		}
+		if err := encoder.Encode(row); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+// progressReader counts the bytes read through it and refuses to read beyond limit
+type progressReader struct {
+	reader io.Reader
+	limit  int64 // 0 = unlimited
+	n      atomic.Int64
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.reader.Read(b)
+	if total := p.n.Add(int64(n)); p.limit > 0 && total > p.limit {
+		return n, fmt.Errorf("%w of %d bytes", errFileTooLarge, p.limit)
+	}
+	return n, err
+}
+
+// logEvery logs how much of an input of size bytes (-1 = unknown) was read, every interval, until stopped
+func (p *progressReader) logEvery(interval time.Duration, key string, size int64) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				read := p.n.Load()
+				if size > 0 {
+					slog.Info("File processing progress", "input", key, "bytes_read", read, "size", size, "percent", read*100/size)
+				} else {
+					slog.Info("File processing progress", "input", key, "bytes_read", read)
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	writer io.Writer
+	n      int64
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.writer.Write(b)
+	c.n += int64(n)
+	return n, err
+}
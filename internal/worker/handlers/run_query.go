@@ -3,21 +3,54 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math/rand"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// RunQueryConfig configures how RunQueryHandler runs queries
+type RunQueryConfig struct {
+	StatementTimeout time.Duration // per query, on top of the task's own timeout
+	MaxRows          int           // rows kept in the result summary; the query stops being read after them
+}
+
 // RunQueryHandler handles database query execution tasks
+// Queries run in a read-only transaction on their own pool, meant for a replica or a
+// reporting database, never the queue's own; the summary is stored as the task's result
 type RunQueryHandler struct {
-	rng *rand.Rand
+	pool   *pgxpool.Pool
+	config RunQueryConfig
+	rng    *rand.Rand // simulation only
+}
+
+// QueryResult is the result summary of a run_query task
+type QueryResult struct {
+	Columns     []string `json:"columns"`
+	Rows        [][]any  `json:"rows,omitempty"`
+	RowCount    int      `json:"row_count"`              // rows read, at most MaxRows
+	Truncated   bool     `json:"truncated"`              // the query returned more than MaxRows rows
+	RowsOmitted bool     `json:"rows_omitted,omitempty"` // rows left out to keep the result under models.MaxTaskResultBytes
+	DurationMs  int64    `json:"duration_ms"`
+}
+
+// NewRunQueryHandler creates a query handler running queries against pool
+func NewRunQueryHandler(pool *pgxpool.Pool, config RunQueryConfig) *RunQueryHandler {
+	return &RunQueryHandler{pool: pool, config: config}
 }
 
-// NewRunQueryHandler creates a new query handler
-func NewRunQueryHandler() *RunQueryHandler {
+// NewSimulatedRunQueryHandler creates a query handler that only sleeps and fails at random,
+// for local development and the integration tests
+func NewSimulatedRunQueryHandler() *RunQueryHandler {
 	return &RunQueryHandler{
 		rng: rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
@@ -29,16 +62,18 @@ func (h *RunQueryHandler) Type() models.TaskType {
 
 func (h *RunQueryHandler) Execute(ctx context.Context, payload json.RawMessage) error {
 	var req struct {
-		Query string `json:"query"`
+		Query  string `json:"query"`
+		Params []any  `json:"params"`
 	}
 
+	// Retrying cannot fix a malformed payload
 	if err := json.Unmarshal(payload, &req); err != nil {
-		return fmt.Errorf("invalid payload: %w", err)
+		return models.Permanent(fmt.Errorf("invalid payload: %w", err))
 	}
 
 	// Validate required fields
-	if req.Query == "" {
-		return fmt.Errorf("missing required field: query")
+	if strings.TrimSpace(req.Query) == "" {
+		return models.Permanent(errors.New("missing required field: query"))
 	}
 
 	// Check for cancellation before starting work
@@ -48,12 +83,135 @@ func (h *RunQueryHandler) Execute(ctx context.Context, payload json.RawMessage)
 	default:
 	}
 
-	// TODO: Integrate with actual database query execution
 	slog.Info("Running query",
 		"query", req.Query,
 		"query_length", len(req.Query),
+		"params", len(req.Params),
+	)
+
+	if h.pool == nil {
+		return h.simulate(ctx, req.Query)
+	}
+
+	result, err := h.run(ctx, req.Query, req.Params)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Query executed successfully",
+		"rows", result.RowCount,
+		"truncated", result.Truncated,
+		"duration_ms", result.DurationMs,
 	)
+	return models.SetResult(ctx, result)
+}
+
+// run executes query, reading at most MaxRows rows
+func (h *RunQueryHandler) run(ctx context.Context, query string, params []any) (*QueryResult, error) {
+	started := time.Now()
+	result := &QueryResult{}
+	columns, err := queryRows(ctx, h.pool, h.config.StatementTimeout, query, params, func(values []any) bool {
+		if result.RowCount == h.config.MaxRows {
+			result.Truncated = true
+			return false
+		}
+		for i, v := range values {
+			values[i] = resultValue(v)
+		}
+		result.Rows = append(result.Rows, values)
+		result.RowCount++
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Columns = columns
+	result.DurationMs = time.Since(started).Milliseconds()
+
+	// Keep the summary when wide rows would not fit in a stored result
+	if encoded, err := json.Marshal(result); err != nil || len(encoded) > models.MaxTaskResultBytes {
+		result.Rows = nil
+		result.RowsOmitted = true
+	}
+
+	return result, nil
+}
+
+// queryRows executes query in a read-only transaction under timeout and passes every row to next until it returns false
+// It returns the column names; errors are classified by queryError
+func queryRows(ctx context.Context, pool *pgxpool.Pool, timeout time.Duration, query string, params []any, next func(values []any) bool) ([]string, error) {
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("query database unavailable: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Local to the transaction, so pooled connections keep their defaults
+	milliseconds := strconv.FormatInt(timeout.Milliseconds(), 10)
+	if _, err := tx.Exec(ctx, `SELECT set_config('statement_timeout', $1, true)`, milliseconds); err != nil {
+		return nil, fmt.Errorf("set statement timeout: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, query, params...)
+	if err != nil {
+		return nil, queryError(err)
+	}
+	defer rows.Close()
+
+	columns := []string{}
+	for _, field := range rows.FieldDescriptions() {
+		columns = append(columns, field.Name)
+	}
+
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return nil, queryError(err)
+		}
+		if !next(values) {
+			break
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, queryError(err)
+	}
+	return columns, nil
+}
+
+// resultValue converts a decoded column value into one that encodes readably as JSON
+func resultValue(v any) any {
+	switch v := v.(type) {
+	case [16]byte:
+		return uuid.UUID(v).String()
+	}
+	return v
+}
+
+// queryError classifies a query failure
+// Errors in the query itself fail the task at once since every retry would fail the same way;
+// timeouts, cancellations and connection errors are retried like any other failure
+func queryError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return fmt.Errorf("query execution failed: %w", err)
+	}
+
+	switch {
+	case pgErr.Code == "57014": // query_canceled, raised by statement_timeout
+		return fmt.Errorf("query execution failed: statement timeout: %w", err)
+	case pgErr.Code == "25006": // read_only_sql_transaction
+		return models.Permanent(fmt.Errorf("query execution failed: queries must be read-only: %w", err))
+	case strings.HasPrefix(pgErr.Code, "42"), // syntax errors, unknown objects, missing privileges
+		strings.HasPrefix(pgErr.Code, "22"), // data exceptions, e.g. invalid parameters
+		strings.HasPrefix(pgErr.Code, "0A"): // feature not supported
+		return models.Permanent(fmt.Errorf("query execution failed: %w", err))
+	}
+	return fmt.Errorf("query execution failed: %w", err)
+}
 
+// simulate sleeps and fails at random in place of running the query
+func (h *RunQueryHandler) simulate(ctx context.Context, query string) error {
 	// Simulate different failure scenarios for testing:
 	// - 20% regular failures (1-2 out of 10)
 	// - 20% timeouts (3-4 out of 10) - exceeds worker's 30s timeout
@@ -63,17 +221,17 @@ func (h *RunQueryHandler) Execute(ctx context.Context, payload json.RawMessage)
 	switch {
 	case scenario <= 2:
 		// Regular failure (20%)
-		slog.Warn("Query execution failed (simulated)", "query", req.Query, "scenario", "regular_failure")
+		slog.Warn("Query execution failed (simulated)", "query", query, "scenario", "regular_failure")
 		return fmt.Errorf("query execution failed: database connection error")
 
 	case scenario <= 4:
 		// Timeout scenario (20%) - use context-aware sleep
-		slog.Warn("Query execution timing out (simulated)", "query", req.Query, "scenario", "timeout", "sleep_duration", "5s")
+		slog.Warn("Query execution timing out (simulated)", "query", query, "scenario", "timeout", "sleep_duration", "5s")
 		select {
 		case <-time.After(5 * time.Second):
 			return fmt.Errorf("query execution failed: database timeout")
 		case <-ctx.Done():
-			slog.Warn("Query cancelled during timeout simulation", "query", req.Query)
+			slog.Warn("Query cancelled during timeout simulation", "query", query)
 			return ctx.Err()
 		}
 
@@ -81,10 +239,10 @@ func (h *RunQueryHandler) Execute(ctx context.Context, payload json.RawMessage)
 		// Success (60%) - with context-aware sleep
 		select {
 		case <-time.After(3 * time.Second):
-			slog.Info("Query executed successfully", "query", req.Query, "scenario", "success")
+			slog.Info("Query executed successfully", "query", query, "scenario", "success")
 			return nil
 		case <-ctx.Done():
-			slog.Warn("Query cancelled during execution", "query", req.Query)
+			slog.Warn("Query cancelled during execution", "query", query)
 			return ctx.Err()
 		}
 	}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestRunQueryInvalidPayload(t *testing.T) {
+	h := NewRunQueryHandler(nil, RunQueryConfig{})
+
+	for _, payload := range []string{`{"query": 1}`, `{}`, `{"query": "  "}`} {
+		if err := h.Execute(context.Background(), json.RawMessage(payload)); !models.IsPermanent(err) {
+			t.Errorf("Execute(%s) error = %v, want a permanent error", payload, err)
+		}
+	}
+}
+
+func TestQueryError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		permanent bool
+	}{
+		{"statement timeout", &pgconn.PgError{Code: "57014"}, false},
+		{"write in read-only transaction", &pgconn.PgError{Code: "25006"}, true},
+		{"syntax error", &pgconn.PgError{Code: "42601"}, true},
+		{"unknown table", &pgconn.PgError{Code: "42P01"}, true},
+		{"invalid parameter", &pgconn.PgError{Code: "22P02"}, true},
+		{"not supported", &pgconn.PgError{Code: "0A000"}, true},
+		{"admin shutdown", &pgconn.PgError{Code: "57P01"}, false},
+		{"connection error", errors.New("connection reset by peer"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := queryError(tt.err)
+			if models.IsPermanent(err) != tt.permanent {
+				t.Errorf("queryError() = %v, want permanent %v", err, tt.permanent)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("queryError() = %v, want it to wrap %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestResultValue(t *testing.T) {
+	id := uuid.MustParse("01928f3a-7c2e-7d45-9b1a-3f0e5c8d2a61")
+	if got := resultValue([16]byte(id)); got != id.String() {
+		t.Errorf("resultValue(uuid) = %v, want %s", got, id)
+	}
+	if got := resultValue(int64(7)); got != int64(7) {
+		t.Errorf("resultValue(7) = %v, want 7", got)
+	}
+}
+
+// TestRunQueryDatabase runs queries against TEST_DATABASE_URL
+func TestRunQueryDatabase(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	pool, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	h := NewRunQueryHandler(pool, RunQueryConfig{StatementTimeout: time.Second, MaxRows: 3})
+
+	tests := []struct {
+		name      string
+		query     string
+		params    []any
+		permanent bool
+		retried   bool
+		rows      int
+		truncated bool
+		columns   []string
+	}{
+		{name: "rows", query: "SELECT n, n * 2 AS double FROM generate_series(1, $1::int) n", params: []any{2}, rows: 2, columns: []string{"n", "double"}},
+		{name: "truncated", query: "SELECT n FROM generate_series(1, 10) n", rows: 3, truncated: true},
+		{name: "write", query: "CREATE TEMP TABLE run_query_test (n int)", permanent: true},
+		{name: "syntax error", query: "SELEC 1", permanent: true},
+		{name: "statement timeout", query: "SELECT pg_sleep(5)", retried: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, _ := json.Marshal(map[string]any{"query": tt.query, "params": tt.params})
+			ctx, result := models.ContextWithResult(context.Background())
+
+			err := h.Execute(ctx, payload)
+			switch {
+			case tt.permanent:
+				if !models.IsPermanent(err) {
+					t.Fatalf("Execute() error = %v, want a permanent error", err)
+				}
+				return
+			case tt.retried:
+				if err == nil || models.IsPermanent(err) {
+					t.Fatalf("Execute() error = %v, want a retryable error", err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			var got QueryResult
+			if err := json.Unmarshal(result(), &got); err != nil {
+				t.Fatalf("result = %s: %v", result(), err)
+			}
+			if got.RowCount != tt.rows || got.Truncated != tt.truncated || len(got.Rows) != tt.rows {
+				t.Errorf("result = %s, want %d rows, truncated %v", result(), tt.rows, tt.truncated)
+			}
+			if tt.columns != nil && !slices.Equal(got.Columns, tt.columns) {
+				t.Errorf("columns = %v, want %v", got.Columns, tt.columns)
+			}
+		})
+	}
+}
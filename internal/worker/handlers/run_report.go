@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/blobstore"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/report"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/worker"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// reportContentTypes are the content types of the formats a report renders in
+var reportContentTypes = map[string]string{
+	report.FormatCSV: "text/csv; charset=utf-8",
+	report.FormatPDF: "application/pdf",
+}
+
+// RunReportConfig configures RunReportHandler
+type RunReportConfig struct {
+	StatementTimeout time.Duration // per query, on top of the task's own timeout
+	MaxRows          int           // rows a report holds; the query stops being read after them
+}
+
+// RunReportHandler handles run_report tasks: it runs a named report query with the payload's parameters,
+// renders the rows as CSV or PDF and uploads the file to the object store
+// Queries run like run_query's, in a read-only transaction on the query database
+type RunReportHandler struct {
+	pool    *pgxpool.Pool
+	queries *report.Queries
+	store   blobstore.Store
+	config  RunReportConfig
+}
+
+// ReportResult is the result of a run_report task
+type ReportResult struct {
+	Report     string `json:"report"`
+	Format     string `json:"format"`
+	URL        string `json:"url"` // e.g. s3://bucket/reports/monthly_orders/...
+	Key        string `json:"key"`
+	Rows       int    `json:"rows"`
+	Truncated  bool   `json:"truncated"` // the query returned more than MaxRows rows
+	Bytes      int    `json:"bytes"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// NewRunReportHandler creates a report handler running queries against pool and uploading to store
+func NewRunReportHandler(pool *pgxpool.Pool, queries *report.Queries, store blobstore.Store, config RunReportConfig) *RunReportHandler {
+	return &RunReportHandler{pool: pool, queries: queries, store: store, config: config}
+}
+
+func (h *RunReportHandler) Type() models.TaskType {
+	return models.TaskTypeRunReport
+}
+
+func (h *RunReportHandler) Execute(ctx context.Context, payload json.RawMessage) error {
+	req, err := worker.DecodePayload[struct {
+		Report string `json:"report" binding:"required"`
+		Params []any  `json:"params"`
+		Format string `json:"format" binding:"omitempty,oneof=csv pdf"`
+		Title  string `json:"title"`
+		Output string `json:"output"`
+	}](payload)
+	if err != nil {
+		return err
+	}
+	query, err := h.queries.Query(req.Report)
+	if err != nil {
+		return models.Permanent(err)
+	}
+
+	if req.Format == "" {
+		req.Format = report.FormatCSV
+	}
+	contentType := reportContentTypes[req.Format]
+
+	// Without an output key every run gets a file of its own
+	key := req.Output
+	if key == "" {
+		key = fmt.Sprintf("reports/%s/%s-%s.%s", req.Report, time.Now().UTC().Format("20060102T150405Z"), uuid.NewString()[:8], req.Format)
+	}
+	if err := blobstore.ValidateKey(key); err != nil {
+		return models.Permanent(fmt.Errorf("output: %w", err))
+	}
+
+	title := req.Title
+	if title == "" {
+		title = req.Report
+	}
+
+	slog.Info("Running report", "report", req.Report, "format", req.Format, "params", len(req.Params))
+	started := time.Now()
+	table := report.Table{Title: title}
+	truncated := false
+	table.Columns, err = queryRows(ctx, h.pool, h.config.StatementTimeout, query, req.Params, func(values []any) bool {
+		if len(table.Rows) == h.config.MaxRows {
+			truncated = true
+			return false
+		}
+		row := make([]string, len(values))
+		for i, v := range values {
+			row[i] = reportValue(v)
+		}
+		table.Rows = append(table.Rows, row)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if truncated {
+		table.Note = fmt.Sprintf("Only the first %d rows are included.", h.config.MaxRows)
+	}
+
+	var rendered bytes.Buffer
+	switch req.Format {
+	case report.FormatPDF:
+		err = report.WritePDF(&rendered, table)
+	default:
+		err = report.WriteCSV(&rendered, table)
+	}
+	if err != nil {
+		return fmt.Errorf("render report: %w", err)
+	}
+
+	if err := h.store.Put(ctx, key, bytes.NewReader(rendered.Bytes()), int64(rendered.Len()), contentType); err != nil {
+		return err
+	}
+
+	result := ReportResult{
+		Report:     req.Report,
+		Format:     req.Format,
+		URL:        h.store.URL(key),
+		Key:        key,
+		Rows:       len(table.Rows),
+		Truncated:  truncated,
+		Bytes:      rendered.Len(),
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	slog.Info("Report uploaded", "report", req.Report, "url", result.URL, "rows", result.Rows, "bytes", result.Bytes, "duration_ms", result.DurationMs)
+	return models.SetResult(ctx, result)
+}
+
+// reportValue formats a decoded column value as the text of a report cell
+func reportValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case []byte:
+		return `\x` + hex.EncodeToString(v)
+	case [16]byte:
+		return uuid.UUID(v).String()
+	case driver.Valuer:
+		// pgtype values such as Numeric and Interval know their text form
+		if value, err := v.Value(); err == nil {
+			return reportValue(value)
+		}
+	case map[string]any, []any:
+		if encoded, err := json.Marshal(v); err == nil {
+			return string(encoded)
+		}
+	}
+	return fmt.Sprint(v)
+}
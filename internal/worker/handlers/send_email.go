@@ -3,24 +3,27 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
-	"math/rand"
-	"time"
 
+	"github.com/amitbasuri/taskqueue-runner-go/internal/email"
 	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
 )
 
 // SendEmailHandler handles email sending tasks
+// Delivery goes through the configured provider: SMTP, SES, SendGrid, Mailgun or the simulation
+// Payloads either carry the subject and bodies inline or name one of templates plus its variables
 type SendEmailHandler struct {
-	rng *rand.Rand
+	sender    email.Sender
+	from      string
+	templates *email.Templates
 }
 
-// NewSendEmailHandler creates a new email handler
-func NewSendEmailHandler() *SendEmailHandler {
-	return &SendEmailHandler{
-		rng: rand.New(rand.NewSource(time.Now().UnixNano())),
-	}
+// NewSendEmailHandler creates an email handler that delivers through sender, sending from from
+// templates may be nil, in which case payloads naming a template fail
+func NewSendEmailHandler(sender email.Sender, from string, templates *email.Templates) *SendEmailHandler {
+	return &SendEmailHandler{sender: sender, from: from, templates: templates}
 }
 
 func (h *SendEmailHandler) Type() models.TaskType {
@@ -29,21 +32,45 @@ func (h *SendEmailHandler) Type() models.TaskType {
 
 func (h *SendEmailHandler) Execute(ctx context.Context, payload json.RawMessage) error {
 	var req struct {
-		To      string `json:"to"`
-		Subject string `json:"subject"`
-		Body    string `json:"body"`
+		To        string         `json:"to"`
+		Subject   string         `json:"subject"`
+		Body      string         `json:"body"`
+		HTML      string         `json:"html"`
+		Template  string         `json:"template"`
+		Variables map[string]any `json:"variables"`
 	}
 
+	// Retrying cannot fix a malformed payload
 	if err := json.Unmarshal(payload, &req); err != nil {
-		return fmt.Errorf("invalid payload: %w", err)
+		return models.Permanent(fmt.Errorf("invalid payload: %w", err))
 	}
 
 	// Validate required fields
 	if req.To == "" {
-		return fmt.Errorf("missing required field: to")
+		return models.Permanent(errors.New("missing required field: to"))
+	}
+
+	msg := email.Message{Subject: req.Subject, Text: req.Body, HTML: req.HTML}
+	if req.Template != "" {
+		if req.Body != "" || req.HTML != "" {
+			return models.Permanent(errors.New("template cannot be combined with body or html"))
+		}
+
+		// Unknown templates and missing variables fail the same way on every attempt
+		rendered, err := h.templates.Render(req.Template, req.Variables)
+		if err != nil {
+			return models.Permanent(err)
+		}
+
+		// A subject in the payload overrides the template's
+		msg.Text, msg.HTML = rendered.Text, rendered.HTML
+		if msg.Subject == "" {
+			msg.Subject = rendered.Subject
+		}
 	}
-	if req.Subject == "" {
-		return fmt.Errorf("missing required field: subject")
+
+	if msg.Subject == "" {
+		return models.Permanent(errors.New("missing required field: subject"))
 	}
 
 	// Check for cancellation before starting work
@@ -53,26 +80,14 @@ func (h *SendEmailHandler) Execute(ctx context.Context, payload json.RawMessage)
 	default:
 	}
 
-	// TODO: Integrate with actual email service (SendGrid, AWS SES, etc.)
-	slog.Info("Sending email",
-		"to", req.To,
-		"subject", req.Subject,
-		"body_length", len(req.Body),
-	)
-
-	// Simulate 25% failure rate
-	if h.rng.Intn(4) == 0 {
-		slog.Warn("Email sending failed (simulated)", "to", req.To)
-		return fmt.Errorf("email delivery failed: SMTP connection timeout")
-	}
+	msg.From = h.from
+	msg.To = []string{req.To}
 
-	// Simulate email sending with cancellation support
-	select {
-	case <-time.After(3 * time.Second):
-		slog.Info("Email sent successfully", "to", req.To)
-		return nil
-	case <-ctx.Done():
-		slog.Warn("Email sending cancelled", "to", req.To, "error", ctx.Err())
-		return ctx.Err()
+	slog.Info("Sending email", "to", req.To, "subject", msg.Subject, "template", req.Template)
+	if err := h.sender.Send(ctx, msg); err != nil {
+		return err
 	}
+
+	slog.Info("Email sent successfully", "to", req.To)
+	return nil
 }
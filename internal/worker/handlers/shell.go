@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// shellOutputTail is how much of each output stream is kept in the task's result
+const shellOutputTail = 4 << 10
+
+// shellLineLimit caps a logged output line; longer lines are split
+const shellLineLimit = 4 << 10
+
+// shellWaitDelay is how long a killed command's output is still read before Execute returns
+const shellWaitDelay = 5 * time.Second
+
+// shellDefaultPath is the PATH commands run with; the worker's own environment is never passed on
+const shellDefaultPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+// shellEnvName restricts the variables operators may allow
+var shellEnvName = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+// shellLimits applies the CPU and memory limits, then replaces itself with the command,
+// which receives its arguments untouched as positional parameters
+const shellLimits = `ulimit -t "$1" && ulimit -v "$2" && shift 2 && exec "$@"`
+
+// ShellConfig configures ShellHandler
+type ShellConfig struct {
+	Commands   map[string]string // allowlisted command names and the executables they run
+	Env        map[string]bool   // variables payloads may set, empty = none
+	WorkDir    string            // working directory of every command, empty = the worker's
+	CPUSeconds int               // CPU time limit per command, 0 = unlimited
+	MemoryMB   int               // virtual memory limit per command, 0 = unlimited
+	Timeout    time.Duration     // wall-clock limit per command, on top of the task's own timeout
+}
+
+// ShellHandler handles shell tasks: one run of an allowlisted command with arguments from the payload
+// Commands get a clean environment and CPU, memory and time limits; their output goes to the worker's log,
+// line by line, and the end of it into the task's result
+// A non-zero exit is retried like any other failure
+type ShellHandler struct {
+	config ShellConfig
+}
+
+// ShellResult is the result of a shell task
+type ShellResult struct {
+	ExitCode   int    `json:"exit_code"`
+	Stdout     string `json:"stdout,omitempty"` // last 4 KiB
+	Stderr     string `json:"stderr,omitempty"` // last 4 KiB
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// ResolveShellCommands maps every allowlisted command to its executable
+// Entries are executable paths or names looked up in PATH once, at startup; payloads name them by base name
+func ResolveShellCommands(commands []string) (map[string]string, error) {
+	resolved := map[string]string{}
+	for _, command := range commands {
+		command = strings.TrimSpace(command)
+		if command == "" {
+			continue
+		}
+
+		path, err := exec.LookPath(command)
+		if err != nil {
+			return nil, fmt.Errorf("shell command %s: %w", command, err)
+		}
+		if path, err = filepath.Abs(path); err != nil {
+			return nil, fmt.Errorf("shell command %s: %w", command, err)
+		}
+
+		name := filepath.Base(command)
+		if existing, ok := resolved[name]; ok && existing != path {
+			return nil, fmt.Errorf("shell commands %s and %s share the name %s", existing, path, name)
+		}
+		resolved[name] = path
+	}
+	return resolved, nil
+}
+
+// ResolveShellEnv builds the set of variables payloads may set
+// PATH is always the worker's, so it cannot be allowed
+func ResolveShellEnv(names []string) (map[string]bool, error) {
+	allowed := map[string]bool{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !shellEnvName.MatchString(name) || name == "PATH" {
+			return nil, fmt.Errorf("environment variable cannot be allowed: %s", name)
+		}
+		allowed[name] = true
+	}
+	return allowed, nil
+}
+
+// NewShellHandler creates a shell handler running the commands of config
+func NewShellHandler(config ShellConfig) *ShellHandler {
+	return &ShellHandler{config: config}
+}
+
+func (h *ShellHandler) Type() models.TaskType {
+	return models.TaskTypeShell
+}
+
+func (h *ShellHandler) Execute(ctx context.Context, payload json.RawMessage) error {
+	var req struct {
+		Command string            `json:"command"`
+		Args    []string          `json:"args"`
+		Env     map[string]string `json:"env"`
+	}
+
+	// Retrying cannot fix a malformed payload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return models.Permanent(fmt.Errorf("invalid payload: %w", err))
+	}
+
+	path, ok := h.config.Commands[req.Command]
+	if !ok {
+		return models.Permanent(fmt.Errorf("command is not allowed: %q", req.Command))
+	}
+
+	env := []string{"PATH=" + shellDefaultPath}
+	for name, value := range req.Env {
+		if !h.config.Env[name] {
+			return models.Permanent(fmt.Errorf("environment variable cannot be set: %s", name))
+		}
+		env = append(env, name+"="+value)
+	}
+
+	if h.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.config.Timeout)
+		defer cancel()
+	}
+
+	args := append([]string{"-c", shellLimits, "sh",
+		ulimitValue(h.config.CPUSeconds),
+		ulimitValue(h.config.MemoryMB * 1024), // ulimit -v counts KiB
+		path,
+	}, req.Args...)
+	cmd := exec.CommandContext(ctx, "/bin/sh", args...)
+	cmd.Env = env
+	cmd.Dir = h.config.WorkDir
+	cmd.WaitDelay = shellWaitDelay
+	killProcessGroup(cmd)
+
+	stdout := newOutputLog(req.Command, "stdout")
+	stderr := newOutputLog(req.Command, "stderr")
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	slog.Info("Running command", "command", req.Command, "path", path, "args", len(req.Args))
+	started := time.Now()
+	runErr := cmd.Run()
+	stdout.flush()
+	stderr.flush()
+
+	result := ShellResult{
+		ExitCode:   cmd.ProcessState.ExitCode(),
+		Stdout:     stdout.tail(),
+		Stderr:     stderr.tail(),
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+
+	if runErr != nil {
+		// The task's deadline or shutdown killed the command
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("command %s killed: %w", req.Command, ctxErr)
+		}
+
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			return fmt.Errorf("command %s failed with %s: %s", req.Command, exitErr.ProcessState, lastLine(result.Stderr))
+		}
+		return fmt.Errorf("command %s could not run: %w", req.Command, runErr)
+	}
+
+	slog.Info("Command succeeded", "command", req.Command, "duration_ms", result.DurationMs)
+	return models.SetResult(ctx, result)
+}
+
+// ulimitValue formats a limit for ulimit, 0 being unlimited
+func ulimitValue(limit int) string {
+	if limit <= 0 {
+		return "unlimited"
+	}
+	return strconv.Itoa(limit)
+}
+
+// lastLine returns the last non-empty line of output
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	return lines[len(lines)-1]
+}
+
+// outputLog logs every line written to it and keeps the end of the output
+type outputLog struct {
+	command string
+	stream  string
+
+	mu   sync.Mutex
+	line []byte
+	last []byte
+}
+
+func newOutputLog(command, stream string) *outputLog {
+	return &outputLog{command: command, stream: stream}
+}
+
+func (o *outputLog) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.last = append(o.last, p...)
+	if len(o.last) > shellOutputTail {
+		o.last = o.last[len(o.last)-shellOutputTail:]
+	}
+
+	for _, b := range p {
+		if b == '\n' {
+			o.logLine()
+			continue
+		}
+		o.line = append(o.line, b)
+		if len(o.line) == shellLineLimit {
+			o.logLine()
+		}
+	}
+	return len(p), nil
+}
+
+// flush logs a trailing line without a newline
+func (o *outputLog) flush() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if len(o.line) > 0 {
+		o.logLine()
+	}
+}
+
+func (o *outputLog) logLine() {
+	slog.Info("Command output", "command", o.command, "stream", o.stream, "line", strings.ToValidUTF8(string(o.line), "\uFFFD"))
+	o.line = o.line[:0]
+}
+
+// tail returns the end of the output
+func (o *outputLog) tail() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return strings.ToValidUTF8(string(o.last), "\uFFFD")
+}
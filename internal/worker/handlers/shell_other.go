@@ -0,0 +1,8 @@
+//go:build !unix
+
+package handlers
+
+import "os/exec"
+
+// killProcessGroup is a no-op where process groups are not available: only the command itself is killed
+func killProcessGroup(cmd *exec.Cmd) {}
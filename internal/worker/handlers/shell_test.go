@@ -0,0 +1,79 @@
+//go:build unix
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+func TestShellAllowlists(t *testing.T) {
+	commands, err := ResolveShellCommands([]string{"env"})
+	if err != nil {
+		t.Skipf("env is not available: %v", err)
+	}
+	allowedEnv, err := ResolveShellEnv([]string{"BATCH_SIZE", " ", "SHARD"})
+	if err != nil {
+		t.Fatalf("ResolveShellEnv() error = %v", err)
+	}
+	h := NewShellHandler(ShellConfig{Commands: commands, Env: allowedEnv})
+	t.Setenv("DATABASE_PASSWORD", "hunter2")
+
+	tests := []struct {
+		name      string
+		payload   string
+		permanent bool
+		stdout    []string
+	}{
+		{"allowed command and env", `{"command": "env", "env": {"BATCH_SIZE": "500"}}`, false, []string{"BATCH_SIZE=500", "PATH=" + shellDefaultPath}},
+		{"command outside the allowlist", `{"command": "sh", "args": ["-c", "id"]}`, true, nil},
+		{"command by path", `{"command": "/usr/bin/env"}`, true, nil},
+		{"interpreter startup file", `{"command": "env", "env": {"BASH_ENV": "/tmp/x"}}`, true, nil},
+		{"loader variable", `{"command": "env", "env": {"LD_PRELOAD": "/tmp/x.so"}}`, true, nil},
+		{"runtime options", `{"command": "env", "env": {"NODE_OPTIONS": "--require /tmp/x.js"}}`, true, nil},
+		{"path", `{"command": "env", "env": {"PATH": "/tmp"}}`, true, nil},
+		{"lowercase name", `{"command": "env", "env": {"batch_size": "1"}}`, true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, result := models.ContextWithResult(context.Background())
+			err := h.Execute(ctx, json.RawMessage(tt.payload))
+			if tt.permanent {
+				if !models.IsPermanent(err) {
+					t.Fatalf("Execute() error = %v, want a permanent error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			var got ShellResult
+			if err := json.Unmarshal(result(), &got); err != nil {
+				t.Fatalf("result = %s: %v", result(), err)
+			}
+			for _, line := range tt.stdout {
+				if !strings.Contains(got.Stdout, line+"\n") {
+					t.Errorf("stdout = %q, want a line %q", got.Stdout, line)
+				}
+			}
+			// Nothing from the worker's own environment leaks through
+			if strings.Contains(got.Stdout, "DATABASE_PASSWORD") {
+				t.Errorf("stdout = %q, want no worker variables", got.Stdout)
+			}
+		})
+	}
+}
+
+func TestResolveShellEnv(t *testing.T) {
+	for _, names := range [][]string{{"PATH"}, {"lower"}, {"BAD-NAME"}, {"1ST"}} {
+		if _, err := ResolveShellEnv(names); err == nil {
+			t.Errorf("ResolveShellEnv(%q) error = nil, want one", names)
+		}
+	}
+}
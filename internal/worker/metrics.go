@@ -0,0 +1,131 @@
+package worker
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/metrics"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// Task outcomes counted by the tasks processed metric
+const (
+	outcomeSucceeded   = "succeeded"
+	outcomeFailed      = "failed"
+	outcomeTimeout     = "timeout"
+	outcomeCrashed     = "crashed"
+	outcomeInterrupted = "interrupted"
+	outcomeLockLost    = "lock_lost"
+)
+
+// Claim results labelling the claim duration metric
+const (
+	claimResultClaimed = "claimed"
+	claimResultEmpty   = "empty"
+	claimResultError   = "error"
+)
+
+// claimBuckets resolve the claim query from sub-millisecond to lock-contended
+var claimBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// workerMetrics holds the metrics served on the admin /metrics endpoint
+type workerMetrics struct {
+	registry          *metrics.Registry
+	tasksProcessed    *metrics.Counter
+	executionDuration *metrics.Histogram
+
+	claimDuration  *metrics.Histogram
+	tasksClaimed   *metrics.Counter
+	claimShortfall *metrics.Counter
+	emptyPolls     *metrics.Counter
+
+	// totals are reported with every registry heartbeat
+	totalsMu sync.Mutex
+	totals   models.WorkerCounters
+}
+
+// newWorkerMetrics registers the worker metrics; gauges read the worker's state at scrape time
+func newWorkerMetrics(w *Worker) *workerMetrics {
+	r := metrics.NewRegistry()
+	m := &workerMetrics{
+		registry:       r,
+		tasksProcessed: r.NewCounter("taskqueue_worker_tasks_processed_total", "Tasks executed by this worker, by type and outcome.", "type", "outcome"),
+		executionDuration: r.NewHistogram("taskqueue_worker_task_duration_seconds", "Handler execution time of tasks run by this worker, by type and outcome.",
+			metrics.DurationBuckets, "type", "outcome"),
+
+		claimDuration: r.NewHistogram("taskqueue_worker_claim_duration_seconds", "Time spent in the claim query, by result (claimed, empty, error).",
+			claimBuckets, "result"),
+		tasksClaimed:   r.NewCounter("taskqueue_worker_tasks_claimed_total", "Tasks claimed by this worker."),
+		claimShortfall: r.NewCounter("taskqueue_worker_claim_shortfall_total", "Slots a claim asked for but did not fill, because tasks were locked by other workers, capped or not there."),
+		emptyPolls:     r.NewCounter("taskqueue_worker_empty_polls_total", "Claims that returned no task."),
+	}
+
+	r.NewGaugeFunc("taskqueue_worker_in_flight_tasks", "Claimed tasks waiting for or holding a worker slot.", func() float64 {
+		return float64(w.inFlight.Load())
+	})
+	r.NewGaugeFunc("taskqueue_worker_running_tasks", "Tasks currently executing.", func() float64 {
+		return float64(w.runningCount())
+	})
+	r.NewGaugeFunc("taskqueue_worker_max_concurrency", "Current worker concurrency.", func() float64 {
+		return float64(w.maxConcurrency())
+	})
+	r.NewGaugeFunc("taskqueue_worker_draining", "1 while the worker is draining.", func() float64 {
+		if w.Draining() {
+			return 1
+		}
+		return 0
+	})
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *workerMetrics) Handler() http.Handler {
+	return m.registry.Handler()
+}
+
+// observeClaim records a claim that asked for requested tasks and got claimed of them
+func (m *workerMetrics) observeClaim(elapsed time.Duration, requested, claimed int, err error) {
+	result := claimResultClaimed
+	switch {
+	case err != nil:
+		result = claimResultError
+	case claimed == 0:
+		result = claimResultEmpty
+		m.emptyPolls.Inc()
+	}
+	m.claimDuration.Observe(elapsed.Seconds(), result)
+
+	if err != nil {
+		return
+	}
+	m.tasksClaimed.Add(float64(claimed))
+	m.claimShortfall.Add(float64(requested - claimed))
+}
+
+// countOutcome records how a task execution ended and how long the handler ran
+func (m *workerMetrics) countOutcome(task *models.Task, outcome string, elapsed time.Duration) {
+	m.tasksProcessed.Inc(task.Type, outcome)
+	m.executionDuration.Observe(elapsed.Seconds(), task.Type, outcome)
+
+	// Neither says anything about the task: its next run is counted wherever it happens
+	if outcome == outcomeInterrupted || outcome == outcomeLockLost {
+		return
+	}
+	m.totalsMu.Lock()
+	defer m.totalsMu.Unlock()
+	if outcome == outcomeSucceeded {
+		m.totals.TasksSucceeded++
+	} else {
+		m.totals.TasksFailed++
+	}
+	m.totals.BusySeconds += elapsed.Seconds()
+}
+
+// counters returns the task totals since the worker started
+func (m *workerMetrics) counters() models.WorkerCounters {
+	m.totalsMu.Lock()
+	defer m.totalsMu.Unlock()
+	return m.totals
+}
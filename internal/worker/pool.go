@@ -0,0 +1,125 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// workerPool tracks the goroutines processing tasks so the pool can be resized at runtime
+// It is only used from the goroutine running Start
+type workerPool struct {
+	wg    *sync.WaitGroup
+	run   func(workerNum int, quit <-chan struct{})
+	quits []chan struct{}
+	next  int
+}
+
+// newWorkerPool creates an empty pool whose goroutines execute run
+func newWorkerPool(wg *sync.WaitGroup, run func(workerNum int, quit <-chan struct{})) *workerPool {
+	return &workerPool{wg: wg, run: run}
+}
+
+// resize starts or stops goroutines until n are running
+// Stopped goroutines finish their current task before exiting
+func (p *workerPool) resize(n int) {
+	for len(p.quits) < n {
+		quit := make(chan struct{})
+		p.quits = append(p.quits, quit)
+		p.next++
+		workerNum := p.next
+
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.run(workerNum, quit)
+		}()
+	}
+
+	for len(p.quits) > n {
+		last := len(p.quits) - 1
+		close(p.quits[last])
+		p.quits = p.quits[:last]
+	}
+}
+
+// maxConcurrency returns the current number of tasks the worker processes at once
+func (w *Worker) maxConcurrency() int {
+	return int(w.concurrency.Load())
+}
+
+// currentPollInterval returns the current base poll interval
+func (w *Worker) currentPollInterval() time.Duration {
+	return time.Duration(w.pollInterval.Load())
+}
+
+// SetConcurrency changes how many tasks the worker processes at once without a restart
+// Shrinking never interrupts work: surplus goroutines exit once their current task is done
+func (w *Worker) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	old := w.concurrency.Swap(int64(n))
+	if old == int64(n) {
+		return
+	}
+	slog.Info("Worker concurrency changed", "from", old, "to", n)
+
+	select {
+	case w.resized <- struct{}{}:
+	default:
+	}
+
+	// New slots can be filled right away
+	select {
+	case w.slotFreed <- struct{}{}:
+	default:
+	}
+}
+
+// SetPollInterval changes the base poll interval without a restart
+// Takes effect from the next poll
+func (w *Worker) SetPollInterval(d time.Duration) {
+	if d <= 0 {
+		d = w.defaultPollInterval
+	}
+	old := time.Duration(w.pollInterval.Swap(int64(d)))
+	if old != d {
+		slog.Info("Worker poll interval changed", "from", old, "to", d)
+	}
+}
+
+// settingsLoop periodically applies runtime overrides from the store
+// Removing an override restores the worker's configured value
+func (w *Worker) settingsLoop(ctx context.Context) {
+	ticker := time.NewTicker(w.settingsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			settings, err := w.store.GetWorkerSettings(ctx, w.workerID)
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.Error("Failed to load worker settings", "error", err)
+				}
+				continue
+			}
+
+			concurrency := w.defaultConcurrency
+			if settings.MaxConcurrency != nil {
+				concurrency = *settings.MaxConcurrency
+			}
+			w.SetConcurrency(concurrency)
+
+			pollInterval := w.defaultPollInterval
+			if settings.PollIntervalSeconds != nil {
+				pollInterval = time.Duration(*settings.PollIntervalSeconds) * time.Second
+			}
+			w.SetPollInterval(pollInterval)
+		}
+	}
+}
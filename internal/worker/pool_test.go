@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolResize(t *testing.T) {
+	var wg sync.WaitGroup
+	var running atomic.Int64
+	pool := newWorkerPool(&wg, func(workerNum int, quit <-chan struct{}) {
+		running.Add(1)
+		defer running.Add(-1)
+		<-quit
+	})
+
+	steps := []int{3, 1, 4, 4, 0}
+	for _, n := range steps {
+		pool.resize(n)
+		waitFor(t, func() bool { return running.Load() == int64(n) }, "%d goroutines running after resize(%d)", n, n)
+	}
+	wg.Wait()
+
+	// Goroutine numbers keep counting up, so log lines of a removed goroutine are never reused
+	if pool.next != 6 {
+		t.Errorf("goroutines started = %d, want 6", pool.next)
+	}
+}
+
+func TestSetConcurrency(t *testing.T) {
+	w := NewWorker(nil, NewHandlerRegistry(), Config{MaxConcurrency: 2})
+
+	tests := []struct {
+		name    string
+		n       int
+		want    int
+		resized bool
+	}{
+		{"grow", 5, 5, true},
+		{"unchanged", 5, 5, false},
+		{"shrink", 3, 3, true},
+		{"below one", 0, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w.SetConcurrency(tt.n)
+			if got := w.maxConcurrency(); got != tt.want {
+				t.Errorf("maxConcurrency() = %d, want %d", got, tt.want)
+			}
+
+			var resized bool
+			select {
+			case <-w.resized:
+				resized = true
+			default:
+			}
+			if resized != tt.resized {
+				t.Errorf("resize signalled = %v, want %v", resized, tt.resized)
+			}
+		})
+	}
+}
+
+// waitFor polls cond until it holds, failing the test after a second
+func waitFor(t *testing.T, cond func() bool, format string, args ...any) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for "+format, args...)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// register records the worker in the store so operators can see it
+// Failures are logged only: an unregistered worker still processes tasks
+func (w *Worker) register(ctx context.Context) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	info := models.WorkerInfo{
+		ID:          w.workerID,
+		Hostname:    hostname,
+		Version:     w.version,
+		Concurrency: w.maxConcurrency(),
+		Labels:      w.labels,
+		TaskTypes:   w.handlerRegistry.List(),
+	}
+	if err := w.store.RegisterWorker(ctx, info); err != nil {
+		slog.Error("Failed to register worker", "worker_id", w.workerID, "error", err)
+	}
+}
+
+// registrationLoop refreshes the worker's registration every heartbeat interval
+func (w *Worker) registrationLoop(ctx context.Context) {
+	ticker := time.NewTicker(w.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			heartbeat := models.WorkerHeartbeat{
+				Concurrency:    w.maxConcurrency(),
+				InFlight:       int(w.inFlight.Load()),
+				WorkerCounters: w.metrics.counters(),
+			}
+			if err := w.store.HeartbeatWorker(ctx, w.workerID, heartbeat); err != nil {
+				if ctx.Err() == nil {
+					slog.Error("Failed to record worker heartbeat", "worker_id", w.workerID, "error", err)
+				}
+			}
+		}
+	}
+}
+
+// deregister marks the worker as stopped once it has drained
+func (w *Worker) deregister() {
+	// The worker context is already cancelled, so use a fresh one for the write
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownWriteTimeout)
+	defer cancel()
+
+	if err := w.store.DeregisterWorker(ctx, w.workerID); err != nil {
+		slog.Error("Failed to deregister worker", "worker_id", w.workerID, "error", err)
+	}
+}
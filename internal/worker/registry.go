@@ -2,6 +2,7 @@ package worker
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
@@ -45,11 +46,12 @@ func (r *HandlerRegistry) Has(taskType string) bool {
 	return ok
 }
 
-// List returns all registered task types
+// List returns all registered task types, sorted
 func (r *HandlerRegistry) List() []string {
 	types := make([]string, 0, len(r.handlers))
 	for taskType := range r.handlers {
 		types = append(types, string(taskType))
 	}
+	slices.Sort(types)
 	return types
 }
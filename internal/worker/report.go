@@ -0,0 +1,35 @@
+package worker
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// Error kinds tagged on reported errors
+const (
+	reportPanic        = "handler_panic"
+	reportFinalFailure = "final_failure"
+	reportStoreError   = "store_error"
+	reportTampered     = "payload_tampered"
+)
+
+// reportError sends err to the error reporter, tagged with the worker and, when task is not nil, the task
+func (w *Worker) reportError(ctx context.Context, kind string, task *models.Task, err error) {
+	tags := map[string]string{
+		"kind":      kind,
+		"worker_id": w.workerID,
+	}
+	if task != nil {
+		tags["task_id"] = strconv.FormatInt(task.ID, 10)
+		tags["task_type"] = task.Type
+		tags["task_name"] = task.Name
+		tags["retry_count"] = strconv.Itoa(task.RetryCount)
+		if requestID := task.Metadata[models.MetadataRequestID]; requestID != "" {
+			tags["request_id"] = requestID
+		}
+	}
+
+	w.errorReporter.Report(ctx, err, tags)
+}
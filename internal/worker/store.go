@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+)
+
+// Store is the part of storage.Store a worker uses
+// Besides the task stores, remote.Client implements it for workers that reach the queue through the API server
+type Store interface {
+	ClaimNextTasks(ctx context.Context, workerID string, n int, filter models.ClaimFilter) ([]*models.Task, error)
+	ExtendLock(ctx context.Context, taskID int64, lock models.TaskLock, extendBy time.Duration) error
+	ReapExpiredLocks(ctx context.Context) (int, error)
+
+	CompleteTask(ctx context.Context, taskID int64, lock models.TaskLock) error
+	RequeueTask(ctx context.Context, taskID int64, lock models.TaskLock) error
+	ScheduleRetry(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string, retryAfter time.Duration) error
+	RecordTimeout(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string) error
+	RecordCrash(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string) error
+	MarkTaskFailed(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string) error
+	InsertHistory(ctx context.Context, history models.TaskHistory) error
+
+	RegisterWorker(ctx context.Context, worker models.WorkerInfo) error
+	HeartbeatWorker(ctx context.Context, workerID string, heartbeat models.WorkerHeartbeat) error
+	DeregisterWorker(ctx context.Context, workerID string) error
+	GetWorkerSettings(ctx context.Context, workerID string) (*models.WorkerSettings, error)
+
+	RecordTypeFailure(ctx context.Context, taskType string, errorMessage string, threshold int, cooldown time.Duration) (bool, error)
+	RecordTypeSuccess(ctx context.Context, taskType string) error
+
+	GetMaintenance(ctx context.Context) (*models.MaintenanceMode, error)
+	Ping(ctx context.Context) error
+}
+
+// resultSaver is the write half of storage.TaskResults, implemented by stores that keep task results
+type resultSaver interface {
+	SaveTaskResult(ctx context.Context, taskID int64, lock models.TaskLock, result json.RawMessage) error
+}
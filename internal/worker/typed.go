@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/go-playground/validator/v10"
+)
+
+// payloadValidator checks the binding tags of payload structs, the same tags the API's request models use,
+// e.g. `json:"image" binding:"required"` or `json:"format" binding:"omitempty,oneof=csv pdf"`
+var payloadValidator = newPayloadValidator()
+
+func newPayloadValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.SetTagName("binding")
+	// Name fields in errors as payloads spell them
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			return ""
+		case "":
+			return field.Name
+		}
+		return name
+	})
+	return v
+}
+
+// PayloadValidator is implemented by payloads with rules binding tags cannot express
+// DecodePayload calls Validate after the tags pass
+type PayloadValidator interface {
+	Validate() error
+}
+
+// DecodePayload unmarshals payload into a T and validates it by its binding tags and, if it implements
+// PayloadValidator, its Validate method
+// Every error is marked with models.Permanent, since retrying cannot fix a malformed payload
+func DecodePayload[T any](payload json.RawMessage) (T, error) {
+	var req T
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return req, models.Permanent(fmt.Errorf("invalid payload: %w", err))
+	}
+
+	if value := reflect.Indirect(reflect.ValueOf(req)); value.Kind() == reflect.Struct {
+		if err := payloadValidator.Struct(req); err != nil {
+			return req, models.Permanent(validationError(value.Type().Name(), err))
+		}
+	}
+	if v, ok := any(&req).(PayloadValidator); ok {
+		if err := v.Validate(); err != nil {
+			return req, models.Permanent(err)
+		}
+	}
+	return req, nil
+}
+
+// validationError turns the failed binding tags of a payload into one message naming every field
+func validationError(structName string, err error) error {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+
+	problems := make([]string, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		// Drop the struct's own name: inner.format, not request.inner.format
+		field := strings.TrimPrefix(fe.Namespace(), structName+".")
+		switch fe.Tag() {
+		case "required":
+			problems = append(problems, "missing required field: "+field)
+		case "oneof":
+			problems = append(problems, fmt.Sprintf("invalid field %s: must be one of %s", field, fe.Param()))
+		case "min", "gte":
+			problems = append(problems, fmt.Sprintf("invalid field %s: must be at least %s", field, fe.Param()))
+		case "max", "lte":
+			problems = append(problems, fmt.Sprintf("invalid field %s: must be at most %s", field, fe.Param()))
+		default:
+			problems = append(problems, fmt.Sprintf("invalid field %s: fails %s", field, strings.TrimSuffix(fe.Tag()+"="+fe.Param(), "=")))
+		}
+	}
+	return errors.New(strings.Join(problems, "; "))
+}
+
+// TypedHandler is a task handler whose payload is decoded into a T by DecodePayload before fn runs,
+// so fn only sees payloads that passed validation
+type TypedHandler[T any] struct {
+	taskType models.TaskType
+	fn       func(ctx context.Context, req T) error
+}
+
+// NewTypedHandler creates a handler of taskType running fn with the decoded payload
+func NewTypedHandler[T any](taskType models.TaskType, fn func(ctx context.Context, req T) error) *TypedHandler[T] {
+	return &TypedHandler[T]{taskType: taskType, fn: fn}
+}
+
+func (h *TypedHandler[T]) Type() models.TaskType {
+	return h.taskType
+}
+
+func (h *TypedHandler[T]) Execute(ctx context.Context, payload json.RawMessage) error {
+	req, err := DecodePayload[T](payload)
+	if err != nil {
+		return err
+	}
+	return h.fn(ctx, req)
+}
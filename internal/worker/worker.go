@@ -2,149 +2,619 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/amitbasuri/taskqueue-runner-go/internal/errorreport"
 	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/payloadsig"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/secrets"
 	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
 )
 
+// lockLeaseMultiplier is the number of heartbeat intervals a lock renewal is valid for
+const lockLeaseMultiplier = 3
+
+// shutdownWriteTimeout bounds the store write that hands an interrupted task back to the queue
+const shutdownWriteTimeout = 5 * time.Second
+
+// errHandlerPanic marks executions that ended in a recovered handler panic
+var errHandlerPanic = errors.New("handler panicked")
+
+// errLockLost cancels a handler whose task lock was taken over, e.g. by the reaper after missed heartbeats
+var errLockLost = errors.New("task lock lost")
+
+// errPayloadTampered marks tasks whose payload fails signature verification; they never reach a handler
+var errPayloadTampered = errors.New("payload failed signature verification")
+
 // Worker processes tasks from the queue
 type Worker struct {
-	store             storage.Store
-	handlerRegistry   *HandlerRegistry
-	pollInterval      time.Duration
-	taskTimeout       time.Duration
-	simulatedTaskTime time.Duration
-	maxConcurrency    int
-	workerID          string
+	store               Store
+	handlerRegistry     *HandlerRegistry
+	defaultPollInterval time.Duration
+	maxPollInterval     time.Duration
+	taskTimeout         time.Duration
+	maxTaskTimeout      time.Duration
+	heartbeatInterval   time.Duration
+	reaperInterval      time.Duration
+	shutdownTimeout     time.Duration
+	claimBatchSize      int
+	prefetch            int
+	simulatedTaskTime   time.Duration
+	defaultConcurrency  int
+	settingsInterval    time.Duration
+	reservedSlots       int
+	reservedPriority    int
+	breakerThreshold    int
+	breakerCooldown     time.Duration
+	labels              map[string]string
+	typeConcurrency     map[string]int
+	workerID            string
+	version             string
+	errorReporter       errorreport.Reporter
+	secrets             *secrets.Resolver
+	payloadSigner       *payloadsig.Signer
+	allowUnsigned       bool
+
+	// concurrency and pollInterval may be changed at runtime, see SetConcurrency and SetPollInterval
+	concurrency  atomic.Int64
+	pollInterval atomic.Int64
+	// resized wakes Start to grow or shrink the worker goroutine pool
+	resized chan struct{}
+
+	// inFlight counts claimed tasks that are queued in the channel or executing
+	inFlight atomic.Int64
+	// lowPriorityInFlight counts the in-flight tasks that may not use reserved slots
+	lowPriorityInFlight atomic.Int64
+	// running holds the tasks currently executing, for the admin API
+	running   map[int64]runningTask
+	runningMu sync.Mutex
+	// draining stops the dispatcher from claiming; set through Drain
+	draining atomic.Bool
+	// storeDown is set while the store reports storage.ErrUnavailable; claims back off until one succeeds
+	storeDown atomic.Bool
+	// dispatcherTick is the UnixNano time of the dispatcher's last loop iteration, 0 while it is not running
+	dispatcherTick atomic.Int64
+	metrics        *workerMetrics
+
+	// typeInFlight counts in-flight tasks of the types listed in typeConcurrency
+	typeInFlight   map[string]int
+	typeInFlightMu sync.Mutex
+	// slotFreed wakes the dispatcher when a worker goroutine finishes a task
+	slotFreed chan struct{}
 }
 
 // Config holds worker configuration
 type Config struct {
-	PollInterval      time.Duration // How often to check for new tasks
-	TaskTimeout       time.Duration // Maximum time for a task to execute
-	SimulatedTaskTime time.Duration // Simulated task processing time
-	MaxConcurrency    int           // Maximum number of concurrent tasks
+	PollInterval      time.Duration     // How often to check for new tasks
+	MaxPollInterval   time.Duration     // Upper bound for the poll interval while the queue is idle
+	TaskTimeout       time.Duration     // Default execution time for tasks without their own timeout
+	MaxTaskTimeout    time.Duration     // Upper bound applied to per-task timeouts
+	HeartbeatInterval time.Duration     // How often to extend the lock of in-flight tasks
+	ReaperInterval    time.Duration     // How often to recover tasks with expired locks
+	ShutdownTimeout   time.Duration     // How long to wait for in-flight tasks on shutdown before interrupting them
+	ClaimBatchSize    int               // Maximum number of tasks claimed per query
+	Prefetch          int               // Extra tasks claimed beyond free worker slots (0 = claim only what can start)
+	SimulatedTaskTime time.Duration     // Simulated task processing time
+	MaxConcurrency    int               // Maximum number of concurrent tasks
+	SettingsInterval  time.Duration     // How often to reload runtime settings overrides from the store
+	ReservedSlots     int               // Slots that only take tasks with priority >= ReservedPriority
+	ReservedPriority  int               // Minimum priority for tasks using a reserved slot
+	BreakerThreshold  int               // Consecutive failures of a type that pause it cluster-wide (0 = disabled)
+	BreakerCooldown   time.Duration     // How long a tripped type stays paused
+	Labels            map[string]string // Labels advertised to tasks with required_labels
+	TypeConcurrency   map[string]int    // Per-type caps on concurrent tasks within this worker
+	WorkerID          string            // Identity recorded on locks and history (default: hostname-pid-timestamp)
+	Version           string            // Build version reported in the workers registry
+
+	// ErrorReporter receives handler panics, final task failures and unexpected store errors (default: none)
+	ErrorReporter errorreport.Reporter
+
+	// Secrets resolves {"$secret": ...} references in payloads just before execution (default: passed on as stored)
+	Secrets *secrets.Resolver
+
+	// PayloadSigner verifies payload signatures before execution; a task that fails is failed for good (default: not verified)
+	// AllowUnsignedPayloads runs tasks without a signature, e.g. those queued before signing was enabled
+	PayloadSigner         *payloadsig.Signer
+	AllowUnsignedPayloads bool
 }
 
 // NewWorker creates a new worker instance
-func NewWorker(store storage.Store, handlerRegistry *HandlerRegistry, config Config) *Worker {
+func NewWorker(store Store, handlerRegistry *HandlerRegistry, config Config) *Worker {
 	if config.PollInterval == 0 {
 		config.PollInterval = 1 * time.Second
 	}
+	if config.MaxPollInterval < config.PollInterval {
+		config.MaxPollInterval = config.PollInterval
+	}
 	if config.TaskTimeout == 0 {
 		config.TaskTimeout = 30 * time.Second
 	}
+	if config.MaxTaskTimeout == 0 {
+		config.MaxTaskTimeout = 1 * time.Hour
+	}
+	if config.HeartbeatInterval == 0 {
+		config.HeartbeatInterval = 10 * time.Second
+	}
+	if config.ReaperInterval == 0 {
+		config.ReaperInterval = 30 * time.Second
+	}
+	if config.ShutdownTimeout == 0 {
+		config.ShutdownTimeout = 25 * time.Second // Below the default Kubernetes 30s grace period
+	}
 	if config.SimulatedTaskTime == 0 {
 		config.SimulatedTaskTime = 3 * time.Second // Default 3 second task processing time
 	}
 	if config.MaxConcurrency == 0 {
 		config.MaxConcurrency = 5 // Default 5 concurrent tasks
 	}
+	if config.Version == "" {
+		config.Version = "dev"
+	}
+	if config.SettingsInterval == 0 {
+		config.SettingsInterval = 15 * time.Second
+	}
+	if config.ClaimBatchSize == 0 {
+		config.ClaimBatchSize = config.MaxConcurrency // Fill every free slot in one round-trip
+	}
+	if config.Prefetch < 0 {
+		config.Prefetch = 0
+	}
+	if config.ErrorReporter == nil {
+		config.ErrorReporter = errorreport.Nop()
+	}
+	if config.BreakerCooldown == 0 {
+		config.BreakerCooldown = 1 * time.Minute
+	}
+	// Keep at least one slot open to every priority
+	if config.ReservedSlots >= config.MaxConcurrency {
+		config.ReservedSlots = config.MaxConcurrency - 1
+	}
+	if config.ReservedSlots < 0 {
+		config.ReservedSlots = 0
+	}
+
+	// A configured ID survives restarts; lock tokens still fence off a previous incarnation's writes
+	workerID := config.WorkerID
+	if workerID == "" {
+		workerID = generateWorkerID()
+	}
+
+	w := &Worker{
+		store:               store,
+		handlerRegistry:     handlerRegistry,
+		defaultPollInterval: config.PollInterval,
+		maxPollInterval:     config.MaxPollInterval,
+		taskTimeout:         config.TaskTimeout,
+		maxTaskTimeout:      config.MaxTaskTimeout,
+		heartbeatInterval:   config.HeartbeatInterval,
+		reaperInterval:      config.ReaperInterval,
+		shutdownTimeout:     config.ShutdownTimeout,
+		claimBatchSize:      config.ClaimBatchSize,
+		prefetch:            config.Prefetch,
+		simulatedTaskTime:   config.SimulatedTaskTime,
+		defaultConcurrency:  config.MaxConcurrency,
+		settingsInterval:    config.SettingsInterval,
+		reservedSlots:       config.ReservedSlots,
+		reservedPriority:    config.ReservedPriority,
+		breakerThreshold:    config.BreakerThreshold,
+		breakerCooldown:     config.BreakerCooldown,
+		labels:              config.Labels,
+		typeConcurrency:     config.TypeConcurrency,
+		typeInFlight:        map[string]int{},
+		running:             map[int64]runningTask{},
+		workerID:            workerID,
+		version:             config.Version,
+		errorReporter:       config.ErrorReporter,
+		secrets:             config.Secrets,
+		payloadSigner:       config.PayloadSigner,
+		allowUnsigned:       config.AllowUnsignedPayloads,
+		slotFreed:           make(chan struct{}, 1),
+		resized:             make(chan struct{}, 1),
+	}
+	w.metrics = newWorkerMetrics(w)
+	w.concurrency.Store(int64(config.MaxConcurrency))
+	w.pollInterval.Store(int64(config.PollInterval))
+
+	return w
+}
 
-	// Generate stable worker ID: hostname + PID + timestamp
-	// In Kubernetes, all pods have PID=1, so we add timestamp for uniqueness
+// generateWorkerID builds a unique worker ID: hostname + PID + timestamp
+// In Kubernetes, all pods have PID=1, so we add timestamp for uniqueness
+func generateWorkerID() string {
 	hostname, err := os.Hostname()
 	if err != nil {
 		hostname = "unknown"
 	}
-	workerID := fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano())
+	return fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano())
+}
 
-	return &Worker{
-		store:             store,
-		handlerRegistry:   handlerRegistry,
-		pollInterval:      config.PollInterval,
-		taskTimeout:       config.TaskTimeout,
-		simulatedTaskTime: config.SimulatedTaskTime,
-		maxConcurrency:    config.MaxConcurrency,
-		workerID:          workerID,
-	}
+// ID returns the identity this worker records on locks and history
+func (w *Worker) ID() string {
+	return w.workerID
 }
 
 // Start begins the worker with a dispatcher model to prevent DB thundering herd
 func (w *Worker) Start(ctx context.Context) error {
 	slog.Info("Worker started",
-		"poll_interval", w.pollInterval,
+		"worker_id", w.workerID,
+		"version", w.version,
+		"poll_interval", w.currentPollInterval(),
+		"max_poll_interval", w.maxPollInterval,
 		"task_timeout", w.taskTimeout,
+		"max_task_timeout", w.maxTaskTimeout,
+		"heartbeat_interval", w.heartbeatInterval,
+		"reaper_interval", w.reaperInterval,
+		"shutdown_timeout", w.shutdownTimeout,
 		"simulated_task_time", w.simulatedTaskTime,
-		"max_concurrency", w.maxConcurrency,
+		"max_concurrency", w.maxConcurrency(),
+		"settings_interval", w.settingsInterval,
+		"claim_batch_size", w.claimBatchSize,
+		"prefetch", w.prefetch,
+		"reserved_slots", w.reservedSlots,
+		"reserved_priority", w.reservedPriority,
+		"breaker_threshold", w.breakerThreshold,
+		"breaker_cooldown", w.breakerCooldown,
+		"labels", w.labels,
+		"type_concurrency", w.typeConcurrency,
 	)
 
 	// Task channel acts as a buffer between fetcher and workers
-	taskChan := make(chan *models.Task, w.maxConcurrency)
+	// It holds at most the prefetched tasks since the dispatcher only claims into free slots
+	taskChan := make(chan *models.Task, w.maxConcurrency()+w.prefetch)
+
+	// Handlers run on a context that outlives ctx so in-flight tasks can finish during shutdown
+	execCtx, cancelExec := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelExec()
 
 	// Start a single dispatcher goroutine that fetches tasks
-	go w.dispatcherLoop(ctx, taskChan)
+	dispatcherDone := make(chan struct{})
+	go func() {
+		defer close(dispatcherDone)
+		w.dispatcherLoop(ctx, taskChan)
+	}()
+
+	// Start the reaper that recovers tasks abandoned by crashed workers
+	go w.reaperLoop(ctx)
+
+	// Make the worker visible in GET /api/workers
+	w.register(ctx)
+	go w.registrationLoop(ctx)
+
+	// Pick up concurrency and poll interval overrides set through the API
+	go w.settingsLoop(ctx)
 
 	// Start worker pool to process tasks from channel
-	for i := 0; i < w.maxConcurrency; i++ {
-		workerNum := i + 1
-		go w.workerLoop(ctx, workerNum, taskChan)
+	// The pool is resized on this goroutine too, so every wg.Add happens before the final Wait
+	var wg sync.WaitGroup
+	pool := newWorkerPool(&wg, func(workerNum int, quit <-chan struct{}) {
+		w.workerLoop(ctx, execCtx, workerNum, taskChan, quit)
+	})
+	pool.resize(w.maxConcurrency())
+
+	// Wait for context cancellation, resizing the pool on request
+	for running := true; running; {
+		select {
+		case <-ctx.Done():
+			running = false
+		case <-w.resized:
+			pool.resize(w.maxConcurrency())
+		}
 	}
+	slog.Info("Worker stopping, draining in-flight tasks", "shutdown_timeout", w.shutdownTimeout)
 
-	// Wait for context cancellation
-	<-ctx.Done()
-	slog.Info("Worker stopping due to context cancellation")
-	close(taskChan)
+	// Stop claiming before anything is requeued
+	<-dispatcherDone
+
+	if !waitTimeout(&wg, w.shutdownTimeout) {
+		slog.Warn("Shutdown timeout reached, interrupting in-flight tasks")
+		cancelExec()
+		if !waitTimeout(&wg, shutdownWriteTimeout) {
+			slog.Error("In-flight tasks did not stop after interruption; their locks will expire")
+		}
+	}
+
+	// Hand back tasks that were claimed but never started
+	w.requeueUnstarted(taskChan)
+
+	w.deregister()
+	slog.Info("Worker drained")
 	return ctx.Err()
 }
 
+// waitTimeout waits for wg until timeout; returns false if the timeout was reached first
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// requeueUnstarted returns prefetched tasks still sitting in the channel to the queue
+func (w *Worker) requeueUnstarted(taskChan chan *models.Task) {
+	for {
+		select {
+		case task := <-taskChan:
+			if err := w.handleTaskInterrupted(task); err != nil {
+				w.taskLogger(task).Error("Failed to requeue unstarted task", "error", err)
+			}
+		default:
+			return
+		}
+	}
+}
+
 // dispatcherLoop continuously fetches tasks and sends them to worker pool
 // This prevents the DB thundering herd problem
+// If the store supports task notifications, new tasks are dispatched as soon as they are
+// created and polling only acts as a fallback
+// While the queue stays empty the poll interval doubles up to maxPollInterval, and resets on the next hit
 func (w *Worker) dispatcherLoop(ctx context.Context, taskChan chan<- *models.Task) {
 	slog.Info("Dispatcher started")
-	ticker := time.NewTicker(w.pollInterval)
-	defer ticker.Stop()
+	w.dispatcherTick.Store(time.Now().UnixNano())
+	defer w.dispatcherTick.Store(0)
+
+	interval := w.currentPollInterval()
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	// A nil channel never fires, leaving pure polling
+	var taskCreated <-chan struct{}
+	if notifier, ok := w.store.(storage.TaskNotifier); ok {
+		taskCreated = notifier.ListenTaskCreated(ctx)
+		slog.Info("Dispatcher listening for task notifications")
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
 			slog.Info("Dispatcher stopping")
 			return
-		case <-ticker.C:
-			// Try to claim a task
-			task, err := w.store.ClaimNextTask(ctx, w.workerID)
-			if err != nil {
-				slog.Error("Error claiming task", "error", err)
-				continue
+		case <-timer.C:
+			w.dispatcherTick.Store(time.Now().UnixNano())
+			// With every slot busy the queue is not idle, so keep the current interval
+			if w.freeSlots() > 0 {
+				if w.claimAndDispatch(ctx, taskChan) > 0 {
+					interval = w.currentPollInterval()
+				} else {
+					interval = nextPollInterval(interval, max(w.maxPollInterval, w.currentPollInterval()))
+				}
 			}
-
-			// No task available
-			if task == nil {
+			timer.Reset(interval)
+		case <-w.slotFreed:
+			w.dispatcherTick.Store(time.Now().UnixNano())
+			// A worker just finished, so refill its slot without waiting for the next poll
+			w.claimAndDispatch(ctx, taskChan)
+		case _, ok := <-taskCreated:
+			if !ok {
+				taskCreated = nil
 				continue
 			}
-
-			// Log lock acquisition event
-			// Task status is now 'running' (ClaimNextTask already updated it in the database)
-			lockHistory := models.TaskHistory{
-				TaskID:    task.ID,
-				Status:    models.TaskStatusRunning,
-				EventType: models.EventWorkerLockAcquired,
-				WorkerID:  &w.workerID,
-			}
-			if err := w.store.InsertHistory(ctx, lockHistory); err != nil {
-				slog.Error("Failed to insert lock acquired history", "task_id", task.ID, "error", err)
+			w.dispatcherTick.Store(time.Now().UnixNano())
+			// Notifications coalesce, so keep claiming until the queue is drained
+			for w.claimAndDispatch(ctx, taskChan) > 0 {
 			}
+			interval = w.currentPollInterval()
+			timer.Reset(interval)
+		}
+	}
+}
 
-			// Send task to worker pool (blocking)
-			// This ensures tasks are never silently dropped
-			// Backpressure naturally slows down polling when workers are busy
-			select {
-			case taskChan <- task:
-				// Task sent successfully
-			case <-ctx.Done():
-				// Context cancelled while trying to send task
-				return
+// nextPollInterval doubles the poll interval after an empty claim, capped at max
+func nextPollInterval(current, max time.Duration) time.Duration {
+	next := current * 2
+	if next > max {
+		return max
+	}
+	return next
+}
+
+// freeSlots returns how many more tasks may be claimed right now
+// Tasks are only claimed when a worker goroutine can start them, plus the configured prefetch
+func (w *Worker) freeSlots() int {
+	return w.maxConcurrency() + w.prefetch - int(w.inFlight.Load())
+}
+
+// generalSlots returns how many tasks of any priority may be claimed right now
+// Low-priority tasks never occupy the reserved slots, so those stay free for urgent work
+func (w *Worker) generalSlots() int {
+	// Concurrency may shrink at runtime below the reservation; one slot always stays general
+	reserved := min(w.reservedSlots, w.maxConcurrency()-1)
+	slots := w.maxConcurrency() - reserved + w.prefetch - int(w.lowPriorityInFlight.Load())
+	if free := w.freeSlots(); free < slots {
+		slots = free
+	}
+	return slots
+}
+
+// typeSlots returns how many more tasks of each per-type capped type may be claimed
+func (w *Worker) typeSlots() map[string]int {
+	if len(w.typeConcurrency) == 0 {
+		return nil
+	}
+
+	w.typeInFlightMu.Lock()
+	defer w.typeInFlightMu.Unlock()
+
+	slots := make(map[string]int, len(w.typeConcurrency))
+	for taskType, limit := range w.typeConcurrency {
+		slots[taskType] = max(limit-w.typeInFlight[taskType], 0)
+	}
+	return slots
+}
+
+// trackType adjusts the in-flight count of a per-type capped task type
+func (w *Worker) trackType(task *models.Task, delta int) {
+	if _, ok := w.typeConcurrency[task.Type]; !ok {
+		return
+	}
+
+	w.typeInFlightMu.Lock()
+	w.typeInFlight[task.Type] += delta
+	w.typeInFlightMu.Unlock()
+}
+
+// isLowPriority reports whether a task is barred from the reserved slots
+func (w *Worker) isLowPriority(task *models.Task) bool {
+	return w.reservedSlots > 0 && task.Priority < w.reservedPriority
+}
+
+// releaseSlot marks a claimed task as finished and wakes the dispatcher
+func (w *Worker) releaseSlot(task *models.Task) {
+	w.inFlight.Add(-1)
+	if w.isLowPriority(task) {
+		w.lowPriorityInFlight.Add(-1)
+	}
+	w.trackType(task, -1)
+
+	select {
+	case w.slotFreed <- struct{}{}:
+	default:
+	}
+}
+
+// claimAndDispatch claims tasks into the free worker slots and hands them to the worker pool
+// General slots are filled first; reserved slots are then filled with high-priority tasks only
+// Returns the number of tasks dispatched; 0 if none were available, no slot was free or ctx was cancelled
+func (w *Worker) claimAndDispatch(ctx context.Context, taskChan chan<- *models.Task) int {
+	if w.Draining() {
+		return 0
+	}
+
+	dispatched, filled := w.claimInto(ctx, taskChan, w.generalSlots(), models.ClaimFilter{
+		Labels:    w.labels,
+		TypeSlots: w.typeSlots(),
+	})
+
+	// A short general claim means the queue has nothing else to offer right now
+	if w.reservedSlots == 0 || !filled || ctx.Err() != nil {
+		return dispatched
+	}
+
+	minPriority := w.reservedPriority
+	reserved, _ := w.claimInto(ctx, taskChan, w.freeSlots(), models.ClaimFilter{
+		MinPriority: &minPriority,
+		Labels:      w.labels,
+		TypeSlots:   w.typeSlots(),
+	})
+	return dispatched + reserved
+}
+
+// claimInto claims up to slots tasks matching filter, bounded by claimBatchSize, and dispatches them
+// Returns the number dispatched and whether the claim returned as many tasks as it asked for
+func (w *Worker) claimInto(ctx context.Context, taskChan chan<- *models.Task, slots int, filter models.ClaimFilter) (int, bool) {
+	batchSize := slots
+	if batchSize > w.claimBatchSize {
+		batchSize = w.claimBatchSize
+	}
+	if batchSize < 1 {
+		return 0, true
+	}
+
+	// Try to claim tasks
+	started := time.Now()
+	tasks, err := w.store.ClaimNextTasks(ctx, w.workerID, batchSize, filter)
+	w.metrics.observeClaim(time.Since(started), batchSize, len(tasks), err)
+	if err != nil {
+		if !w.markStoreDown(err) {
+			slog.Error("Error claiming tasks", "error", err)
+			w.reportError(ctx, reportStoreError, nil, err)
+		}
+		return 0, false
+	}
+	w.markStoreUp()
+	w.inFlight.Add(int64(len(tasks)))
+	for _, task := range tasks {
+		if w.isLowPriority(task) {
+			w.lowPriorityInFlight.Add(1)
+		}
+		w.trackType(task, 1)
+	}
+
+	// ClaimNextTasks already set the tasks running and recorded their worker_lock_acquired history
+	for i, task := range tasks {
+		// Send task to worker pool
+		// Never blocks in practice: the batch was sized to the free slots
+		select {
+		case taskChan <- task:
+			// Task sent successfully
+		case <-ctx.Done():
+			// Context cancelled while trying to send task
+			return i, false
+		}
+	}
+
+	return len(tasks), len(tasks) == batchSize
+}
+
+// markStoreDown records an unavailable store and reports whether err was one
+// Only the first failure is logged, so an outage does not log an error on every poll
+// While the store is down idle polling backs off to maxPollInterval and readiness fails
+func (w *Worker) markStoreDown(err error) bool {
+	if !errors.Is(err, storage.ErrUnavailable) {
+		return false
+	}
+	if !w.storeDown.Swap(true) {
+		slog.Error("Store unavailable, pausing claims until it recovers", "error", err)
+	}
+	return true
+}
+
+// markStoreUp clears the unavailable flag after a successful claim
+func (w *Worker) markStoreUp() {
+	if w.storeDown.Swap(false) {
+		slog.Info("Store available again, resuming claims")
+	}
+}
+
+// StoreAvailable reports whether the last claim reached the store
+func (w *Worker) StoreAvailable() bool {
+	return !w.storeDown.Load()
+}
+
+// reaperLoop periodically recovers running tasks whose lock has expired
+// Every worker runs one; SKIP LOCKED keeps concurrent reapers from colliding
+func (w *Worker) reaperLoop(ctx context.Context) {
+	ticker := time.NewTicker(w.reaperInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reaped, err := w.store.ReapExpiredLocks(ctx)
+			if err != nil {
+				if !w.markStoreDown(err) {
+					slog.Error("Error reaping expired locks", "error", err)
+				}
+				continue
+			}
+			if reaped > 0 {
+				slog.Warn("Recovered tasks with expired locks", "count", reaped)
 			}
 		}
 	}
 }
 
 // workerLoop processes tasks from the task channel
-func (w *Worker) workerLoop(ctx context.Context, workerNum int, taskChan <-chan *models.Task) {
+// Stops taking new tasks once ctx is cancelled or quit is closed by a pool shrink;
+// the task in progress keeps running on execCtx
+func (w *Worker) workerLoop(ctx, execCtx context.Context, workerNum int, taskChan chan *models.Task, quit <-chan struct{}) {
 	slog.Info("Worker goroutine started", "worker_num", workerNum)
 
 	for {
@@ -152,6 +622,9 @@ func (w *Worker) workerLoop(ctx context.Context, workerNum int, taskChan <-chan
 		case <-ctx.Done():
 			slog.Info("Worker goroutine stopping", "worker_num", workerNum)
 			return
+		case <-quit:
+			slog.Info("Worker goroutine removed from pool", "worker_num", workerNum)
+			return
 		case task, ok := <-taskChan:
 			if !ok {
 				// Channel closed
@@ -159,22 +632,39 @@ func (w *Worker) workerLoop(ctx context.Context, workerNum int, taskChan <-chan
 				return
 			}
 
+			// Shutdown raced with the receive: leave the task for requeueUnstarted
+			if ctx.Err() != nil {
+				taskChan <- task
+				slog.Info("Worker goroutine stopping", "worker_num", workerNum)
+				return
+			}
+
 			// Process the task
-			if err := w.processTask(ctx, workerNum, task); err != nil {
-				slog.Error("Error processing task",
+			if err := w.processTask(execCtx, workerNum, task); err != nil {
+				w.taskLogger(task).Error("Error processing task",
 					"worker_num", workerNum,
-					"task_id", task.ID,
 					"error", err)
+				w.reportError(execCtx, reportStoreError, task, err)
 			}
+			w.releaseSlot(task)
 		}
 	}
 }
 
+// taskLogger returns the logger for lines about task
+// Every line carries the worker and task IDs and the ID of the API request that created the task, if any
+func (w *Worker) taskLogger(task *models.Task) *slog.Logger {
+	logger := slog.With("worker_id", w.workerID, "task_id", task.ID)
+	if requestID := task.Metadata[models.MetadataRequestID]; requestID != "" {
+		logger = logger.With("request_id", requestID)
+	}
+	return logger
+}
+
 // processTask processes a single claimed task
 func (w *Worker) processTask(ctx context.Context, workerNum int, task *models.Task) error {
-	slog.Info("Claimed task",
+	w.taskLogger(task).Info("Claimed task",
 		"worker_num", workerNum,
-		"task_id", task.ID,
 		"task_name", task.Name,
 		"task_type", task.Type,
 		"retry_count", task.RetryCount,
@@ -189,73 +679,363 @@ func (w *Worker) processTask(ctx context.Context, workerNum int, task *models.Ta
 		WorkerID:  &w.workerID,
 	}
 	if err := w.store.InsertHistory(ctx, history); err != nil {
-		slog.Error("Failed to insert task_started history", "task_id", task.ID, "error", err)
+		w.taskLogger(task).Error("Failed to insert task_started history", "error", err)
 	}
 
-	// Execute the task
-	if err := w.executeTask(ctx, task); err != nil {
+	w.trackRunning(workerNum, task)
+	defer w.untrackRunning(task)
+
+	// Keep the lock alive while the handler runs, stopping the handler once the lock is gone
+	handlerCtx, cancelHandler := context.WithCancelCause(ctx)
+	defer cancelHandler(nil)
+	heartbeatCtx, stopHeartbeat := context.WithCancel(ctx)
+	go w.heartbeatLoop(heartbeatCtx, task, func() { cancelHandler(errLockLost) })
+
+	// Execute the task, collecting the result it sets with models.SetResult
+	execCtx, result := models.ContextWithResult(handlerCtx)
+	started := time.Now()
+	err := w.executeTask(execCtx, task)
+	elapsed := time.Since(started)
+	stopHeartbeat()
+
+	// Another worker may be running the task by now, so whatever happened is not ours to record
+	if errors.Is(context.Cause(handlerCtx), errLockLost) {
+		w.metrics.countOutcome(task, outcomeLockLost, elapsed)
+		w.taskLogger(task).Warn("Task lock lost while running, handler stopped and outcome discarded",
+			"task_name", task.Name,
+			"handler_error", err,
+		)
+		return nil
+	}
+	if err != nil {
+		// Retrying cannot help, and the failure says nothing about the handler's health
+		if errors.Is(err, errPayloadTampered) {
+			w.metrics.countOutcome(task, outcomeFailed, elapsed)
+			return w.handleTamperedPayload(ctx, task, err)
+		}
+		// Interrupted by shutdown, not a task failure
+		if ctx.Err() != nil {
+			w.metrics.countOutcome(task, outcomeInterrupted, elapsed)
+			return w.handleTaskInterrupted(task)
+		}
+		// The handler rejected the task itself, so it fails for good; like tampering it says nothing about the handler's health
+		if models.IsPermanent(err) {
+			w.metrics.countOutcome(task, outcomeFailed, elapsed)
+			return w.handlePermanentFailure(ctx, task, err)
+		}
+		w.recordTypeFailure(ctx, task, err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			w.metrics.countOutcome(task, outcomeTimeout, elapsed)
+			return w.handleTaskTimeout(ctx, task, err)
+		}
+		if errors.Is(err, errHandlerPanic) {
+			w.metrics.countOutcome(task, outcomeCrashed, elapsed)
+			return w.handleTaskCrash(ctx, task, err)
+		}
+		w.metrics.countOutcome(task, outcomeFailed, elapsed)
 		return w.handleTaskFailure(ctx, task, err)
 	}
 
-	return w.handleTaskSuccess(ctx, task)
+	w.metrics.countOutcome(task, outcomeSucceeded, elapsed)
+	w.recordTypeSuccess(ctx, task)
+	return w.handleTaskSuccess(ctx, task, result())
+}
+
+// heartbeatLoop periodically extends the lock of an in-flight task until ctx is cancelled
+// Each renewal grants a lease of lockLeaseMultiplier heartbeat intervals, so a crashed
+// worker's task becomes claimable again shortly after its heartbeats stop
+// Once the lock is no longer held it calls lockLost, which stops the handler
+func (w *Worker) heartbeatLoop(ctx context.Context, task *models.Task, lockLost func()) {
+	ticker := time.NewTicker(w.heartbeatInterval)
+	defer ticker.Stop()
+
+	lease := w.heartbeatInterval * lockLeaseMultiplier
+	lock := task.Lock()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.store.ExtendLock(ctx, task.ID, lock, lease); err != nil {
+				if errors.Is(err, storage.ErrLockLost) || errors.Is(err, storage.ErrTaskNotFound) {
+					w.taskLogger(task).Warn("Task lock no longer held, stopping handler", "error", err)
+					lockLost()
+					return
+				}
+				if ctx.Err() != nil {
+					return
+				}
+				w.taskLogger(task).Error("Failed to extend task lock", "error", err)
+			}
+		}
+	}
 }
 
 // executeTask executes the task handler with timeout
-func (w *Worker) executeTask(ctx context.Context, task *models.Task) error {
+// A panicking handler is recovered so one poison payload cannot take the whole worker down
+func (w *Worker) executeTask(ctx context.Context, task *models.Task) (err error) {
+	if err := w.verifyPayload(task); err != nil {
+		return err
+	}
+
 	// Get the handler for this task type
 	h, err := w.handlerRegistry.Get(task.Type)
 	if err != nil {
 		return fmt.Errorf("handler not found for type %s: %w", task.Type, err)
 	}
 
-	// Create context with timeout
-	taskCtx, cancel := context.WithTimeout(ctx, w.taskTimeout)
+	// Create context with the task's own timeout
+	timeout := w.executionTimeout(task)
+	taskCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	// Hand the handler the creating request's trace context
+	taskCtx = models.ContextWithMetadata(taskCtx, task.Metadata)
+
 	// Execute the handler
-	slog.Info("Executing task",
-		"task_id", task.ID,
+	w.taskLogger(task).Info("Executing task",
 		"task_type", task.Type,
 		"handler_type", h.Type(),
+		"timeout", timeout,
 	)
 
-	if err := h.Execute(taskCtx, task.Payload); err != nil {
+	defer func() {
+		if r := recover(); r != nil {
+			w.taskLogger(task).Error("Task handler panicked", "task_type", task.Type, "panic", r)
+			err = fmt.Errorf("%w: %v", errHandlerPanic, r)
+			// Reported while the panicking frames are still on the stack
+			w.reportError(ctx, reportPanic, task, err)
+		}
+	}()
+
+	// Secret values only ever exist in this worker's memory, never in the store
+	payload := task.Payload
+	if w.secrets != nil {
+		if payload, err = w.secrets.Resolve(taskCtx, task.Payload); err != nil {
+			return fmt.Errorf("task execution failed: %w", err)
+		}
+	}
+
+	if err := h.Execute(taskCtx, payload); err != nil {
 		return fmt.Errorf("task execution failed: %w", err)
 	}
 
 	return nil
 }
 
-// handleTaskSuccess handles successful task completion
-func (w *Worker) handleTaskSuccess(ctx context.Context, task *models.Task) error {
-	slog.Info("Task succeeded",
-		"task_id", task.ID,
+// verifyPayload checks the payload signature taken at enqueue time, when verification is enabled
+func (w *Worker) verifyPayload(task *models.Task) error {
+	if w.payloadSigner == nil {
+		return nil
+	}
+
+	err := w.payloadSigner.Verify(task.PublicID, task.Type, task.Payload, task.PayloadSignature)
+	if err == nil || (w.allowUnsigned && errors.Is(err, payloadsig.ErrUnsigned)) {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", errPayloadTampered, err)
+}
+
+// executionTimeout returns the execution timeout for a task
+// Uses the task's timeout_seconds, falling back to the worker default, capped at maxTaskTimeout
+func (w *Worker) executionTimeout(task *models.Task) time.Duration {
+	timeout := w.taskTimeout
+	if task.TimeoutSeconds > 0 {
+		timeout = time.Duration(task.TimeoutSeconds) * time.Second
+	}
+
+	if timeout > w.maxTaskTimeout {
+		timeout = w.maxTaskTimeout
+	}
+
+	return timeout
+}
+
+// handleTaskSuccess handles successful task completion, saving the handler's result first if it set one
+// A result that cannot be saved leaves the task running, so it is retried once its lock expires
+func (w *Worker) handleTaskSuccess(ctx context.Context, task *models.Task, result json.RawMessage) error {
+	w.taskLogger(task).Info("Task succeeded",
 		"task_name", task.Name,
 		"retry_count", task.RetryCount,
+		"result_bytes", len(result),
 	)
 
+	if result != nil {
+		if results, ok := w.store.(resultSaver); !ok {
+			w.taskLogger(task).Warn("Storage backend does not keep task results, discarding result")
+		} else if err := results.SaveTaskResult(ctx, task.ID, task.Lock(), result); err != nil {
+			if errors.Is(err, storage.ErrLockLost) {
+				w.taskLogger(task).Warn("Lock lost before saving result, discarding result")
+				return nil
+			}
+			return fmt.Errorf("failed to save task result: %w", err)
+		}
+	}
+
 	// Mark task as completed
-	if err := w.store.CompleteTask(ctx, task.ID); err != nil {
+	if err := w.store.CompleteTask(ctx, task.ID, task.Lock()); err != nil {
+		if errors.Is(err, storage.ErrLockLost) {
+			w.taskLogger(task).Warn("Lock lost before completion, discarding result")
+			return nil
+		}
 		return fmt.Errorf("failed to complete task: %w", err)
 	}
 
 	return nil
 }
 
+// handleTaskInterrupted requeues a task whose execution was cut short by worker shutdown
+// The retry budget is left untouched since the task itself did not fail
+func (w *Worker) handleTaskInterrupted(task *models.Task) error {
+	w.taskLogger(task).Info("Task interrupted by shutdown, requeueing",
+		"task_name", task.Name,
+		"retry_count", task.RetryCount,
+	)
+
+	// The worker context is already cancelled, so use a fresh one for the write
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownWriteTimeout)
+	defer cancel()
+
+	if err := w.store.RequeueTask(ctx, task.ID, task.Lock()); err != nil {
+		if errors.Is(err, storage.ErrLockLost) {
+			w.taskLogger(task).Warn("Lock lost before requeue, discarding result")
+			return nil
+		}
+		return fmt.Errorf("failed to requeue task: %w", err)
+	}
+
+	return nil
+}
+
+// handleTaskTimeout handles a task that exceeded its execution timeout
+func (w *Worker) handleTaskTimeout(ctx context.Context, task *models.Task, execErr error) error {
+	errorMsg := execErr.Error()
+
+	w.taskLogger(task).Warn("Task timed out",
+		"task_name", task.Name,
+		"timeout_count", task.TimeoutCount,
+		"timeout", w.executionTimeout(task),
+		"error", errorMsg,
+	)
+
+	// Storage layer applies the timeout retry policy
+	if err := w.store.RecordTimeout(ctx, task.ID, task.Lock(), errorMsg); err != nil {
+		if errors.Is(err, storage.ErrLockLost) {
+			w.taskLogger(task).Warn("Lock lost before timeout handling, discarding result")
+			return nil
+		}
+		return fmt.Errorf("failed to record timeout: %w", err)
+	}
+
+	return nil
+}
+
+// handleTaskCrash handles a task whose handler panicked
+// Storage layer quarantines tasks that crash repeatedly
+func (w *Worker) handleTaskCrash(ctx context.Context, task *models.Task, execErr error) error {
+	errorMsg := execErr.Error()
+
+	w.taskLogger(task).Warn("Task crashed",
+		"task_name", task.Name,
+		"crash_count", task.CrashCount,
+		"error", errorMsg,
+	)
+
+	if err := w.store.RecordCrash(ctx, task.ID, task.Lock(), errorMsg); err != nil {
+		if errors.Is(err, storage.ErrLockLost) {
+			w.taskLogger(task).Warn("Lock lost before crash handling, discarding result")
+			return nil
+		}
+		return fmt.Errorf("failed to record crash: %w", err)
+	}
+
+	return nil
+}
+
+// handleTamperedPayload fails a task whose payload does not match its signature, without retries
+// It is logged, recorded in the task's history and reported as a security event, since the payload
+// was either modified after enqueue or written without going through the API
+func (w *Worker) handleTamperedPayload(ctx context.Context, task *models.Task, execErr error) error {
+	errorMsg := execErr.Error()
+
+	w.taskLogger(task).Error("Task payload failed signature verification",
+		"security_event", models.EventPayloadTampered,
+		"task_name", task.Name,
+		"task_type", task.Type,
+		"error", errorMsg,
+	)
+	w.reportError(ctx, reportTampered, task, execErr)
+
+	history := models.TaskHistory{
+		TaskID:       task.ID,
+		Status:       models.TaskStatusRunning,
+		EventType:    models.EventPayloadTampered,
+		ErrorMessage: &errorMsg,
+		WorkerID:     &w.workerID,
+	}
+	if err := w.store.InsertHistory(ctx, history); err != nil {
+		w.taskLogger(task).Error("Failed to insert payload_tampered history", "error", err)
+	}
+
+	if err := w.store.MarkTaskFailed(ctx, task.ID, task.Lock(), errorMsg); err != nil {
+		if errors.Is(err, storage.ErrLockLost) {
+			w.taskLogger(task).Warn("Lock lost before failing tampered task, discarding result")
+			return nil
+		}
+		return fmt.Errorf("failed to mark task failed: %w", err)
+	}
+
+	return nil
+}
+
+// handlePermanentFailure fails a task whose handler reported an error retrying cannot fix
+func (w *Worker) handlePermanentFailure(ctx context.Context, task *models.Task, execErr error) error {
+	errorMsg := execErr.Error()
+
+	w.taskLogger(task).Warn("Task failed permanently",
+		"task_name", task.Name,
+		"retry_count", task.RetryCount,
+		"error", errorMsg,
+	)
+	w.reportError(ctx, reportFinalFailure, task, execErr)
+
+	if err := w.store.MarkTaskFailed(ctx, task.ID, task.Lock(), errorMsg); err != nil {
+		if errors.Is(err, storage.ErrLockLost) {
+			w.taskLogger(task).Warn("Lock lost before failing task, discarding result")
+			return nil
+		}
+		return fmt.Errorf("failed to mark task failed: %w", err)
+	}
+
+	return nil
+}
+
 // handleTaskFailure handles task execution failure with retry logic
 func (w *Worker) handleTaskFailure(ctx context.Context, task *models.Task, execErr error) error {
 	errorMsg := execErr.Error()
 
-	slog.Warn("Task failed",
-		"task_id", task.ID,
+	w.taskLogger(task).Warn("Task failed",
 		"task_name", task.Name,
 		"retry_count", task.RetryCount,
 		"max_retries", task.MaxRetries,
 		"error", errorMsg,
 	)
 
+	// The store fails the task for good once its retries are used up
+	if task.RetryCount >= task.MaxRetries {
+		w.reportError(ctx, reportFinalFailure, task, execErr)
+	}
+
+	// Honor a retry delay suggested by the handler, if any
+	retryAfter, _ := models.RetryDelay(execErr)
+
 	// Schedule retry (storage layer handles retry exhaustion logic)
-	if err := w.store.ScheduleRetry(ctx, task.ID, errorMsg); err != nil {
+	if err := w.store.ScheduleRetry(ctx, task.ID, task.Lock(), errorMsg, retryAfter); err != nil {
+		if errors.Is(err, storage.ErrLockLost) {
+			w.taskLogger(task).Warn("Lock lost before retry scheduling, discarding result")
+			return nil
+		}
 		return fmt.Errorf("failed to schedule retry: %w", err)
 	}
 
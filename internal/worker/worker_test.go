@@ -0,0 +1,231 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amitbasuri/taskqueue-runner-go/internal/models"
+	"github.com/amitbasuri/taskqueue-runner-go/internal/storage"
+)
+
+// outcomeStore records how the worker settled a task
+// Methods the tests do not reach panic through the embedded nil interface
+type outcomeStore struct {
+	storage.Store
+
+	extendErr error
+
+	mu         sync.Mutex
+	outcome    string
+	retryAfter time.Duration
+	extends    int
+}
+
+func (s *outcomeStore) record(outcome string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outcome = outcome
+	return nil
+}
+
+func (s *outcomeStore) InsertHistory(ctx context.Context, history models.TaskHistory) error {
+	return nil
+}
+
+func (s *outcomeStore) ExtendLock(ctx context.Context, taskID int64, lock models.TaskLock, extendBy time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.extends++
+	return s.extendErr
+}
+
+func (s *outcomeStore) CompleteTask(ctx context.Context, taskID int64, lock models.TaskLock) error {
+	return s.record("completed")
+}
+
+func (s *outcomeStore) RequeueTask(ctx context.Context, taskID int64, lock models.TaskLock) error {
+	return s.record("requeued")
+}
+
+func (s *outcomeStore) RecordTimeout(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string) error {
+	return s.record("timeout")
+}
+
+func (s *outcomeStore) RecordCrash(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string) error {
+	return s.record("crashed")
+}
+
+func (s *outcomeStore) MarkTaskFailed(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string) error {
+	return s.record("failed")
+}
+
+func (s *outcomeStore) ScheduleRetry(ctx context.Context, taskID int64, lock models.TaskLock, errorMessage string, retryAfter time.Duration) error {
+	s.mu.Lock()
+	s.retryAfter = retryAfter
+	s.mu.Unlock()
+	return s.record("retry")
+}
+
+// funcHandler runs execute for tasks of its type
+type funcHandler struct {
+	execute func(ctx context.Context) error
+}
+
+func (h funcHandler) Type() models.TaskType {
+	return "test_task"
+}
+
+func (h funcHandler) Execute(ctx context.Context, payload json.RawMessage) error {
+	return h.execute(ctx)
+}
+
+// newTestWorker returns a worker running execute for test_task tasks against store
+func newTestWorker(store storage.Store, config Config, execute func(ctx context.Context) error) *Worker {
+	registry := NewHandlerRegistry()
+	registry.Register(funcHandler{execute: execute})
+	return NewWorker(store, registry, config)
+}
+
+func TestProcessTaskOutcome(t *testing.T) {
+	tests := []struct {
+		name       string
+		execute    func(ctx context.Context, shutdown context.CancelFunc) error
+		want       string
+		retryAfter time.Duration
+	}{
+		{
+			name:    "success",
+			execute: func(ctx context.Context, shutdown context.CancelFunc) error { return nil },
+			want:    "completed",
+		},
+		{
+			name: "interrupted by shutdown",
+			execute: func(ctx context.Context, shutdown context.CancelFunc) error {
+				shutdown()
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			want: "requeued",
+		},
+		{
+			name: "timeout",
+			execute: func(ctx context.Context, shutdown context.CancelFunc) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			want: "timeout",
+		},
+		{
+			name: "permanent",
+			execute: func(ctx context.Context, shutdown context.CancelFunc) error {
+				return models.Permanent(errors.New("invalid payload"))
+			},
+			want: "failed",
+		},
+		{
+			name: "panic",
+			execute: func(ctx context.Context, shutdown context.CancelFunc) error {
+				panic("poison payload")
+			},
+			want: "crashed",
+		},
+		{
+			name: "ordinary error",
+			execute: func(ctx context.Context, shutdown context.CancelFunc) error {
+				return errors.New("connection refused")
+			},
+			want: "retry",
+		},
+		{
+			name: "retry after",
+			execute: func(ctx context.Context, shutdown context.CancelFunc) error {
+				return models.RetryAfter(errors.New("rate limited"), 30*time.Second)
+			},
+			want:       "retry",
+			retryAfter: 30 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, shutdown := context.WithCancel(context.Background())
+			defer shutdown()
+
+			store := &outcomeStore{}
+			w := newTestWorker(store, Config{TaskTimeout: 50 * time.Millisecond}, func(execCtx context.Context) error {
+				return tt.execute(execCtx, shutdown)
+			})
+
+			if err := w.processTask(ctx, 1, &models.Task{ID: 1, Type: "test_task"}); err != nil {
+				t.Fatalf("processTask() error = %v", err)
+			}
+			if store.outcome != tt.want {
+				t.Errorf("outcome = %q, want %q", store.outcome, tt.want)
+			}
+			if store.retryAfter != tt.retryAfter {
+				t.Errorf("retryAfter = %v, want %v", store.retryAfter, tt.retryAfter)
+			}
+		})
+	}
+}
+
+func TestProcessTaskLockLost(t *testing.T) {
+	tests := []struct {
+		name      string
+		extendErr error
+	}{
+		{"lock taken over", storage.ErrLockLost},
+		{"task deleted", storage.ErrTaskNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &outcomeStore{extendErr: tt.extendErr}
+			var cause error
+			w := newTestWorker(store, Config{HeartbeatInterval: 10 * time.Millisecond}, func(ctx context.Context) error {
+				<-ctx.Done()
+				cause = context.Cause(ctx)
+				return errors.New("upload aborted")
+			})
+
+			if err := w.processTask(context.Background(), 1, &models.Task{ID: 1, Type: "test_task"}); err != nil {
+				t.Fatalf("processTask() error = %v", err)
+			}
+			if !errors.Is(cause, errLockLost) {
+				t.Errorf("handler context cause = %v, want errLockLost", cause)
+			}
+			// Another worker owns the task now, so neither the failure nor a retry is recorded
+			if store.outcome != "" {
+				t.Errorf("outcome = %q, want none", store.outcome)
+			}
+		})
+	}
+}
+
+func TestProcessTaskKeepsRunningOnExtendError(t *testing.T) {
+	store := &outcomeStore{extendErr: errors.New("connection reset")}
+	w := newTestWorker(store, Config{HeartbeatInterval: 10 * time.Millisecond}, func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+			return nil
+		}
+	})
+
+	if err := w.processTask(context.Background(), 1, &models.Task{ID: 1, Type: "test_task"}); err != nil {
+		t.Fatalf("processTask() error = %v", err)
+	}
+	if store.outcome != "completed" {
+		t.Errorf("outcome = %q, want completed", store.outcome)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.extends == 0 {
+		t.Error("lock was never extended")
+	}
+}
@@ -9,6 +9,7 @@ kubectl apply -f k8s/manifests/configmap.yaml
 kubectl apply -f k8s/manifests/secret.yaml
 kubectl apply -f k8s/manifests/server-deployment.yaml
 kubectl apply -f k8s/manifests/server-service.yaml
+kubectl apply -f k8s/manifests/worker-rbac.yaml
 kubectl apply -f k8s/manifests/worker-deployment.yaml
 
 kubectl wait --for=condition=available \
@@ -1,12 +1,13 @@
 let eventSource = null;
 
 function updateStats(stats) {
-    // Update stat cards
-    document.getElementById('total-tasks').textContent = stats.total_tasks;
+    // Update stat cards; sampled estimates are marked with ~
+    const approx = stats.estimated ? '~' : '';
+    document.getElementById('total-tasks').textContent = approx + stats.total_tasks;
     document.getElementById('queued-tasks').textContent = stats.queued_tasks;
     document.getElementById('running-tasks').textContent = stats.running_tasks;
-    document.getElementById('succeeded-tasks').textContent = stats.succeeded_tasks;
-    document.getElementById('failed-tasks').textContent = stats.failed_tasks;
+    document.getElementById('succeeded-tasks').textContent = approx + stats.succeeded_tasks;
+    document.getElementById('failed-tasks').textContent = approx + stats.failed_tasks;
     
     // Calculate success rate
     const completedTasks = stats.succeeded_tasks + stats.failed_tasks;
@@ -19,7 +20,7 @@ function updateStats(stats) {
     
     // Update additional metrics
     document.getElementById('avg-retry').textContent = stats.avg_retry_count.toFixed(2);
-    document.getElementById('tasks-with-retries').textContent = stats.tasks_with_retries;
+    document.getElementById('tasks-with-retries').textContent = approx + stats.tasks_with_retries;
     
     // Update timestamp
     const now = new Date();